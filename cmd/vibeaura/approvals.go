@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	approvalsClear bool
+	approvalsYes   bool
+)
+
+var sysApprovalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "List the decisions saved with \"Approve Forever\" or denied for good",
+	Long: `List the enclave's persisted decisions, oldest first. The numbers shown can
+be passed to 'vibeaura sys approvals revoke' in place of the key.
+
+--clear removes every saved decision after asking, or straight away with --yes.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		enclave, err := openEnclave()
+		if err != nil {
+			return err
+		}
+		list := enclave.ListApprovals()
+
+		if approvalsClear {
+			if len(list) == 0 {
+				printInfo("No saved approvals to clear.")
+				return nil
+			}
+			if err := confirmClearApprovals(len(list)); err != nil {
+				return err
+			}
+			if err := enclave.ClearApprovals(); err != nil {
+				return fmt.Errorf("clearing approvals: %w", err)
+			}
+			printSuccess(fmt.Sprintf("Cleared %d saved approval(s)", len(list)))
+			return nil
+		}
+
+		if jsonOutput() {
+			printJSON(list)
+			return nil
+		}
+		if len(list) == 0 {
+			printInfo("No saved approvals.")
+			return nil
+		}
+		printTitle("🔐", "SAVED APPROVALS")
+		for i, a := range list {
+			printBulletWithMeta(fmt.Sprintf("%d. [%s] %s  %s", i+1, a.Decision, a.Tool, a.Summary), approvalMeta(a))
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var sysApprovalsRevokeCmd = &cobra.Command{
+	Use:   "revoke <index|key>",
+	Short: "Forget one saved decision so the action asks again",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		enclave, err := openEnclave()
+		if err != nil {
+			return err
+		}
+		a, err := findApproval(enclave.ListApprovals(), args[0])
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		if err := enclave.Revoke(a.Key); err != nil {
+			return err
+		}
+		printSuccess(fmt.Sprintf("Revoked %s for %s: %s", a.Decision, a.Tool, a.Summary))
+		return nil
+	}),
+}
+
+func openEnclave() (*tooling.Enclave, error) {
+	enclave := brain.New().Enclave()
+	if enclave == nil {
+		return nil, fmt.Errorf("the enclave is unavailable; check the data directory permissions")
+	}
+	return enclave, nil
+}
+
+// findApproval resolves a 1-based position in list, or an exact key.
+func findApproval(list []tooling.Approval, arg string) (tooling.Approval, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(list) {
+			return tooling.Approval{}, fmt.Errorf("no approval #%d (there are %d)", n, len(list))
+		}
+		return list[n-1], nil
+	}
+	for _, a := range list {
+		if a.Key == arg {
+			return a, nil
+		}
+	}
+	return tooling.Approval{}, fmt.Errorf("no saved approval with key %q", arg)
+}
+
+func approvalMeta(a tooling.Approval) string {
+	if a.RecordedAt.IsZero() {
+		return "date unknown"
+	}
+	return "recorded " + a.RecordedAt.Local().Format("2006-01-02 15:04")
+}
+
+// confirmClearApprovals asks on the terminal before --clear, unless --yes.
+func confirmClearApprovals(n int) error {
+	if approvalsYes {
+		return nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return usageErrorf("refusing to clear %d approval(s) without a terminal; re-run with --yes", n)
+	}
+	fmt.Fprintf(cliErr, "Clear all %d saved approval(s)? [y/N] ", n)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("clear cancelled")
+}
+
+func init() {
+	sysApprovalsCmd.Flags().BoolVar(&approvalsClear, "clear", false, "Remove every saved decision")
+	sysApprovalsCmd.Flags().BoolVarP(&approvalsYes, "yes", "y", false, "Clear without asking")
+	sysApprovalsCmd.AddCommand(sysApprovalsRevokeCmd)
+	sysCmd.AddCommand(sysApprovalsCmd)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
@@ -157,32 +158,53 @@ func getLocalCommit() string {
 
 type UpdateNoUpdateMsg struct{}
 
-// DownloadUpdateCmd downloads the update in background
+// UpdateFailedMsg reports a background download or install that failed.
+type UpdateFailedMsg struct {
+	Err error
+}
+
+// DownloadUpdateCmd downloads the update in background, streaming its
+// progress to the TUI as "download" status events.
 func (chk *AsyncUpdateManager) DownloadUpdateCmd(latest *releaseInfo) tea.Cmd {
 	return func() tea.Msg {
 		// For hot-swap, on Linux/Mac, we can overwrite the binary while running.
 		// performBinaryUpdate is defined in update.go (package main)
-		err := performBinaryUpdate(latest)
+		err := performBinaryUpdate(latest, reportDownload(latest.TagName))
 		if err != nil {
-			return nil
+			return UpdateFailedMsg{Err: err}
 		}
 		return UpdateReadyMsg{Target: latest.ActualSHA}
 	}
 }
 
-// PerformHotSwap saves state and execs the new binary
-func PerformHotSwap(headers []string, input string) {
-	state := map[string]interface{}{
-		"messages": headers,
-		"input":    input,
+// reportDownload sends a "download" StatusEvent each time the percentage
+// changes.
+func reportDownload(tag string) func(read, total int64) {
+	last := -2
+	return func(read, total int64) {
+		pct := downloadPercent(read, total)
+		if pct == last && pct >= 0 {
+			return
+		}
+		last = pct
+		select {
+		case StatusStream <- StatusEvent{Icon: "⬇️", Step: "download", Message: fmt.Sprintf("Downloading %s (%s)", tag, formatMB(read)), Percent: pct}:
+		default:
+			// Drop if buffer full; the next change redraws the bar
+		}
 	}
+}
 
-	bytes, _ := json.Marshal(state)
-	tmpState, _ := os.CreateTemp("", "vibeaura-state-*.json")
-	tmpState.Write(bytes)
-	tmpState.Close()
+// PerformHotSwap saves state to a temp file and execs the new binary with
+// --resume-state pointing at it; initialModel restores it and deletes it.
+// It only returns if the state could not be saved or the exec failed.
+func PerformHotSwap(state chatState) error {
+	tmpState, err := writeResumeState(state)
+	if err != nil {
+		return err
+	}
 
-	// 2. Restart
+	// Restart with the same arguments, minus any earlier --resume-state.
 	exe, _ := os.Executable()
 
 	// We need to construct args. We can't just use os.Args because we need to strip previous restart flags if any
@@ -204,8 +226,32 @@ func PerformHotSwap(headers []string, input string) {
 		}
 		newArgs = append(newArgs, os.Args[i])
 	}
-	newArgs = append(newArgs, "--resume-state", tmpState.Name())
+	newArgs = append(newArgs, "--resume-state", tmpState)
 
 	// Exec replaces the process
-	syscall.Exec(exe, newArgs, os.Environ())
+	err = syscall.Exec(exe, newArgs, os.Environ())
+	os.Remove(tmpState)
+	return err
+}
+
+// writeResumeState saves state to a temp file only the user can read, and
+// returns its path.
+func writeResumeState(state chatState) (string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "vibeaura-state-*.json")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
 }
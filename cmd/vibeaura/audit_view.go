@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/spf13/cobra"
+)
+
+// auditPaneSize is how many audit entries the /sys /audit pane keeps.
+const auditPaneSize = 100
+
+// auditEntryMsg carries one entry from the audit tail. ch identifies the
+// tail it came from, so entries from a closed pane are ignored.
+type auditEntryMsg struct {
+	entry tooling.AuditEntry
+	ok    bool
+	ch    <-chan tooling.AuditEntry
+}
+
+func waitForAudit(ch <-chan tooling.AuditEntry) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		e, ok := <-ch
+		return auditEntryMsg{entry: e, ok: ok, ch: ch}
+	}
+}
+
+// toggleAudit opens the audit pane beside the chat, or closes it if open.
+func (m *model) toggleAudit() (tea.Model, tea.Cmd) {
+	if m.showAudit {
+		m.closeAudit()
+		if m.isFileOpen {
+			m.openFile(m.currentPath) // Restore the file view
+		}
+		m.updatePerusalContent()
+		return m, nil
+	}
+
+	audit := m.brain.AuditLog()
+	if audit == nil {
+		m.messages = append(m.messages, errorStyle.Render(" AUDIT ")+" The enclave is not running, so there is no audit log.")
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+
+	m.showAudit = true
+	m.auditEntries = nil
+	m.auditCh = audit.TailChan(auditPaneSize)
+	m.updatePerusalContent()
+
+	cmds := []tea.Cmd{waitForAudit(m.auditCh)}
+	if !m.showTree {
+		m.showTree = true
+		cmds = append(cmds, func() tea.Msg { return tea.WindowSizeMsg{Width: m.width, Height: m.height} })
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *model) closeAudit() {
+	if audit := m.brain.AuditLog(); audit != nil {
+		audit.StopTail()
+	}
+	m.showAudit = false
+	m.auditCh = nil
+	m.auditEntries = nil
+}
+
+// renderAudit lays the tailed entries out as a table, newest last.
+func (m *model) renderAudit() string {
+	var sb strings.Builder
+	sb.WriteString(systemStyle.Render(" AUDIT LOG ") + " " + subtleStyle.Render("/sys /audit to close") + "\n\n")
+	if len(m.auditEntries) == 0 {
+		sb.WriteString(subtleStyle.Render("No audited actions yet. New entries appear here as tools run."))
+		return sb.String()
+	}
+
+	sb.WriteString(auditTable(m.auditEntries, m.perusalVp.Width-2))
+	return sb.String()
+}
+
+// auditTable lays entries out as a table, with denials highlighted. A
+// width of 10 or less leaves the table its natural width.
+func auditTable(entries []tooling.AuditEntry, width int) string {
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(subtleStyle).
+		Headers("TIME", "TOOL", "RISK", "DECISION", "SCOPE").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle().Padding(0, 1)
+			if row == table.HeaderRow {
+				return style.Bold(true)
+			}
+			if col == 3 && row < len(entries) && strings.HasPrefix(entries[row].Decision, "Denied") {
+				return style.Inherit(errorStyle)
+			}
+			return style
+		})
+	if width > 10 {
+		t.Width(width)
+	}
+	for _, e := range entries {
+		t.Row(auditTime(e.Timestamp), e.Tool, e.Risk, e.Decision, e.Scope)
+	}
+	return t.Render()
+}
+
+// auditChatLimit is how many entries /sys /audit /last shows.
+const auditChatLimit = 20
+
+// showAuditEntries prints the newest entries matching args, flags as for
+// `vibeaura sys audit`, into the chat.
+func (m *model) showAuditEntries(args []string) (tea.Model, tea.Cmd) {
+	filter, err := parseAuditArgs(args)
+	if err == nil {
+		filter.Limit = auditChatLimit
+		var entries []tooling.AuditEntry
+		var corrupt int
+		entries, corrupt, err = tooling.QueryAudit(m.brain.Config().DataDir, filter)
+		if err == nil {
+			var sb strings.Builder
+			sb.WriteString(systemStyle.Render(" AUDIT LOG ") + "\n")
+			if len(entries) == 0 {
+				sb.WriteString(subtleStyle.Render("No matching audit entries."))
+			} else {
+				sb.WriteString(auditTable(entries, m.viewport.Width-4))
+			}
+			if corrupt > 0 {
+				sb.WriteString("\n" + subtleStyle.Render(fmt.Sprintf("%d unreadable line(s) skipped", corrupt)))
+			}
+			m.messages = append(m.messages, sb.String())
+		}
+	}
+	if err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" AUDIT ")+" "+err.Error())
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// parseAuditArgs reads --tool, --decision, --risk, --since and --until
+// from slash command arguments.
+func parseAuditArgs(args []string) (tooling.AuditFilter, error) {
+	var f tooling.AuditFilter
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		value, hasValue := "", false
+		if k, v, ok := strings.Cut(name, "="); ok {
+			name, value, hasValue = k, v, true
+		} else if i+1 < len(args) {
+			value, hasValue = args[i+1], true
+			i++
+		}
+		if !hasValue {
+			return f, fmt.Errorf("missing value for --%s", name)
+		}
+		var err error
+		switch name {
+		case "tool":
+			f.Tool = value
+		case "decision":
+			f.Decision = value
+		case "risk":
+			f.Risk = value
+		case "since":
+			f.Since, err = parseAuditTime(value)
+		case "until":
+			f.Until, err = parseAuditTime(value)
+		default:
+			return f, fmt.Errorf("unknown filter --%s (use --tool, --decision, --risk, --since or --until)", name)
+		}
+		if err != nil {
+			return f, fmt.Errorf("--%s: %w", name, err)
+		}
+	}
+	return f, nil
+}
+
+// parseAuditTime accepts a duration before now, such as 24h, or a time as
+// for enclave export.
+func parseAuditTime(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return parseExportTime(s)
+}
+
+var (
+	auditTool     string
+	auditDecision string
+	auditRisk     string
+	auditSince    string
+	auditUntil    string
+	auditLimit    int
+)
+
+var sysAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the enclave audit log",
+	Long: `Show the actions the enclave audited, oldest first, across rotated logs.
+
+--since and --until take a duration back from now (24h, 30m) or a time
+(RFC3339 or YYYY-MM-DD). --decision matches by prefix, so "denied" covers
+every kind of denial. Unreadable lines are skipped and counted.`,
+	Example: "  vibeaura sys audit --tool sys_shell_exec --since 24h --decision denied",
+	Args:    cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		filter := tooling.AuditFilter{Tool: auditTool, Decision: auditDecision, Risk: auditRisk, Limit: auditLimit}
+		var err error
+		if filter.Since, err = parseAuditTime(auditSince); auditSince != "" && err != nil {
+			return usageErrorf("--since: %v", err)
+		}
+		if filter.Until, err = parseAuditTime(auditUntil); auditUntil != "" && err != nil {
+			return usageErrorf("--until: %v", err)
+		}
+
+		cm, err := sys.NewConfigManager()
+		if err != nil {
+			return err
+		}
+		cfg, err := cm.Load()
+		if err != nil {
+			return err
+		}
+		entries, corrupt, err := tooling.QueryAudit(cfg.DataDir, filter)
+		if err != nil {
+			return fmt.Errorf("reading audit log: %w", err)
+		}
+
+		if jsonOutput() {
+			printJSON(entries)
+		} else if len(entries) == 0 {
+			printInfo("No matching audit entries.")
+		} else {
+			printTitle("🛡️", "AUDIT LOG")
+			fmt.Fprintln(cliOut, auditTable(entries, 0))
+		}
+		if corrupt > 0 {
+			printWarning(fmt.Sprintf("%d unreadable line(s) skipped", corrupt))
+		}
+		return nil
+	}),
+}
+
+func init() {
+	sysAuditCmd.Flags().StringVar(&auditTool, "tool", "", "Only entries for this tool")
+	sysAuditCmd.Flags().StringVar(&auditDecision, "decision", "", "Only entries whose decision starts with this, e.g. denied")
+	sysAuditCmd.Flags().StringVar(&auditRisk, "risk", "", "Only entries with this risk level")
+	sysAuditCmd.Flags().StringVar(&auditSince, "since", "", "Only entries at or after this time or duration ago")
+	sysAuditCmd.Flags().StringVar(&auditUntil, "until", "", "Only entries at or before this time or duration ago")
+	sysAuditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 0, "Show only the newest n entries")
+	sysCmd.AddCommand(sysAuditCmd)
+}
+
+// auditTime shortens an RFC 3339 timestamp, keeping the date only for
+// entries from another day.
+func auditTime(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	t = t.Local()
+	if y, mo, d := t.Date(); y == time.Now().Year() && mo == time.Now().Month() && d == time.Now().Day() {
+		return t.Format("15:04:05")
+	}
+	return t.Format("01-02 15:04")
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/spf13/cobra"
+)
+
+var benchOut string
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark models against your own prompts",
+}
+
+var benchRunCmd = &cobra.Command{
+	Use:   "run <suite.yaml>",
+	Short: "Run a benchmark suite and save the results as JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		suite, err := brain.LoadBenchSuite(args[0])
+		if err != nil {
+			return fmt.Errorf("loading suite: %w", err)
+		}
+
+		out := benchOut
+		if out == "" {
+			base := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+			out = fmt.Sprintf("%s-%s.json", base, time.Now().Format("20060102-150405"))
+		}
+		checkpoint := strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".checkpoint.json"
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		b := brain.New()
+		if _, err := os.Stat(checkpoint); err == nil {
+			printInfo("Resuming from checkpoint " + checkpoint)
+		}
+		printTitle("🏁", "BENCHMARK: "+suite.Name)
+		if !suite.Sandbox {
+			printInfo("Tool calls are dry-run (set sandbox: true in the suite to execute them)")
+		}
+
+		report, err := b.RunBench(ctx, suite, brain.BenchOptions{
+			Checkpoint: checkpoint,
+			Progress: func(done, total int, r brain.BenchResult) {
+				mark := cliSuccess.Render("✓")
+				if !r.Success {
+					mark = cliError.Render("✗")
+				}
+				line := fmt.Sprintf("%s [%d/%d] %s · %s #%d (%dms)", mark, done, total, r.Profile, r.Case, r.Rep, r.LatencyMS)
+				if jsonOutput() {
+					printProgress("%s", line)
+					return
+				}
+				printProgress("\r%s\033[K", line)
+			},
+		})
+		printProgress("\n")
+		if err != nil {
+			printWarning("Run interrupted; re-run the same command to resume.")
+			return err
+		}
+
+		if err := brain.SaveBenchReport(out, report); err != nil {
+			return fmt.Errorf("saving report: %w", err)
+		}
+		os.Remove(checkpoint)
+
+		if jsonOutput() {
+			printSuccessData("Results saved to "+out, report.Summary)
+			return nil
+		}
+		printBenchSummary(report.Summary)
+		printSuccess("Results saved to " + out)
+		return nil
+	}),
+}
+
+var benchCompareCmd = &cobra.Command{
+	Use:   "compare <a.json> <b.json>",
+	Short: "Compare two benchmark reports",
+	Args:  cobra.ExactArgs(2),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		a, err := brain.LoadBenchReport(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := brain.LoadBenchReport(args[1])
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			printJSON(compareBench(a, b))
+			return nil
+		}
+		printTitle("⚖️", "BENCHMARK COMPARISON")
+		fmt.Fprintf(cliOut, "%-28s %16s %20s %16s\n", "PROFILE", "SUCCESS", "MEDIAN LATENCY", "TOKENS")
+		prev := make(map[string]brain.BenchSummary)
+		for _, s := range a.Summary {
+			prev[s.Profile] = s
+		}
+		for _, s := range b.Summary {
+			old, ok := prev[s.Profile]
+			if !ok {
+				fmt.Fprintf(cliOut, "%-28s %16s %20s %16s\n", s.Profile,
+					fmt.Sprintf("%.0f%% (new)", s.SuccessRate*100),
+					fmt.Sprintf("%dms", s.MedianLatencyMS),
+					benchTokens(s))
+				continue
+			}
+			fmt.Fprintf(cliOut, "%-28s %16s %20s %16s\n", s.Profile,
+				fmt.Sprintf("%.0f%% (%+.0f)", s.SuccessRate*100, (s.SuccessRate-old.SuccessRate)*100),
+				fmt.Sprintf("%dms (%+d)", s.MedianLatencyMS, s.MedianLatencyMS-old.MedianLatencyMS),
+				fmt.Sprintf("%s (%+d)", benchTokens(s), s.Tokens-old.Tokens))
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+// benchDelta is one profile in `bench compare --output json`. The deltas
+// are against the first report and absent for a profile new in the second.
+type benchDelta struct {
+	brain.BenchSummary
+	New                bool     `json:"new,omitempty"`
+	SuccessRateDelta   *float64 `json:"success_rate_delta,omitempty"`
+	MedianLatencyDelta *int64   `json:"median_latency_ms_delta,omitempty"`
+	TokensDelta        *int     `json:"tokens_delta,omitempty"`
+}
+
+func compareBench(a, b *brain.BenchReport) []benchDelta {
+	prev := make(map[string]brain.BenchSummary)
+	for _, s := range a.Summary {
+		prev[s.Profile] = s
+	}
+	out := []benchDelta{}
+	for _, s := range b.Summary {
+		d := benchDelta{BenchSummary: s}
+		if old, ok := prev[s.Profile]; ok {
+			success := s.SuccessRate - old.SuccessRate
+			latency := s.MedianLatencyMS - old.MedianLatencyMS
+			tokens := s.Tokens - old.Tokens
+			d.SuccessRateDelta, d.MedianLatencyDelta, d.TokensDelta = &success, &latency, &tokens
+		} else {
+			d.New = true
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func printBenchSummary(summary []brain.BenchSummary) {
+	printNewline()
+	fmt.Fprintln(cliOut, cliLabel.Render(fmt.Sprintf("%-28s %8s %10s %8s %10s", "PROFILE", "SUCCESS", "MEDIAN", "TOKENS", "COST")))
+	estimated := false
+	for _, s := range summary {
+		fmt.Fprintf(cliOut, "%-28s %7.0f%% %8dms %8s %10s\n", s.Profile, s.SuccessRate*100, s.MedianLatencyMS, benchTokens(s), fmt.Sprintf("$%.4f", s.EstimatedCost))
+		estimated = estimated || s.TokensEstimated
+	}
+	if estimated {
+		fmt.Fprintln(cliOut, cliMuted.Render("~ token counts are estimated (4 characters per token) where the provider reported no usage"))
+	}
+	printNewline()
+}
+
+// benchTokens formats a profile's tokens, marking estimates with "~".
+func benchTokens(s brain.BenchSummary) string {
+	if s.TokensEstimated {
+		return fmt.Sprintf("~%d", s.Tokens)
+	}
+	return fmt.Sprint(s.Tokens)
+}
+
+func init() {
+	benchRunCmd.Flags().StringVarP(&benchOut, "out", "o", "", "Where to write the JSON report")
+	benchCmd.AddCommand(benchRunCmd)
+	benchCmd.AddCommand(benchCompareCmd)
+	rootCmd.AddCommand(benchCmd)
+}
@@ -8,17 +8,25 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/google/uuid"
 	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/prompt"
+	"github.com/nathfavour/vibeauracle/sys"
 	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/nathfavour/vibeauracle/vibes"
 )
 
 type focus int
@@ -46,6 +54,13 @@ type model struct {
 	treeCursor    int
 	currentPath   string
 	isFileOpen    bool
+	showPlan      bool // Side panel shows the agent's plan instead of the explorer
+	showAudit     bool // Side panel tails the enclave audit log (/sys /audit)
+	auditEntries  []tooling.AuditEntry
+	auditCh       <-chan tooling.AuditEntry
+	planCursor    int
+	hasPlan       bool
+	editFormat    sys.TextFormat // Encoding/EOL of the open file, restored on save
 	banner        string
 	suggestions   []string
 	suggestionIdx int
@@ -54,127 +69,129 @@ type model struct {
 
 	// Model selection & filtering
 	allModelDiscoveries []brain.ModelDiscovery
+	modelsCached        bool // allModelDiscoveries came from disk; a refresh is in flight
 	suggestionFilter    string
-	isFilteringModels   bool
+	filterMode          filterMode
+	pullCandidates      []brain.PullCandidate // Loaded when /models /pull is typed
 
 	// Thinking / Agentic Process State
-	thinkingLog []StatusEvent
-	isThinking  bool
+	thinkingLog  []StatusEvent
+	isThinking   bool
+	contextUsage prompt.Usage // Of the last prompt built, shown in the header
 
 	// Updater
 	updater       *AsyncUpdateManager
 	updateReady   bool
 	updateVersion string
+	download      *StatusEvent // Latest update download progress, while one runs
 
 	// Action Confirmation / Intervention
 	pendingIntervention *interventionState
-}
-
-// interventionState holds data for a pending user confirmation.
-type interventionState struct {
-	title     string
-	choices   []string
-	selected  int
-	resume    func(choice string) (interface{}, error)
-	requestID string // To track the original request
-}
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#7D56F4")).
-			Padding(0, 1)
+	// /clear confirmation and session-scoped undo
+	pendingClear bool
+	hasArchived  bool
+	clearHeader  int // Messages clearMessages put ahead of anything typed since
 
-	userStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#EE6FF8")).
-			Bold(true)
+	// Notification center overlay
+	showNotifications bool
 
-	aiStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#04D9FF")).
-		Bold(true)
+	// Streaming output of the request in flight
+	streamReqID   string
+	streamIdx     int // Index of the in-progress AI message, -1 if none
+	streamTurn    int
+	streamText    string
+	cancelRequest context.CancelFunc
 
-	systemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			Padding(0, 1).
-			Bold(true)
+	md *markdownRenderer // Glamour renderer for AI replies (ui.markdown_render)
+	hl *fileHighlighter  // Chroma highlighter for opened files (ui.syntax_highlight)
 
-	subtleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262"))
+	currentSession string      // Name of the chat session being shown
+	times          []time.Time // When each message first appeared; see stampMessages
 
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
-			Bold(true)
+	search *searchState // Open /search result list, nil when closed
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262"))
+	vibes *vibes.Runtime // Opened on first /skill use
 
-	highlight = lipgloss.Color("#7D56F4")
+	explorer *explorerPrompt // Open name/confirmation prompt in the explorer, nil when closed
 
-	tagStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFD700")).
-			Bold(true).
-			Italic(true)
+	mcpWizard *mcpWizard // Open /mcp /add wizard, nil when closed
 
-	suggestionStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
-			Background(lipgloss.Color("#222222"))
+	history inputHistory // Sent inputs, recalled with up/down
+}
 
-	selectedSuggestionStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FAFAFA")).
-				Background(lipgloss.Color("#7D56F4")).
-				Bold(true)
+// interventionState holds data for a pending user confirmation.
+type interventionState struct {
+	title     string
+	choices   []string
+	selected  int
+	requestID string // The parked request; resumed output streams under this ID
 
-	treeStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), false, false, false, true).
-			BorderForeground(lipgloss.Color("#444444")).
-			PaddingLeft(2)
+	// resume answers an intervention raised outside the agent loop, such
+	// as a vibe asking for a permission; retry then runs the action again.
+	resume func(choice string) (*tooling.ToolResult, error)
+	retry  tea.Cmd
+}
 
-	activeBorder = lipgloss.NewStyle().
-			Border(lipgloss.ThickBorder(), true).
-			BorderForeground(lipgloss.Color("#7D56F4"))
+// vibeApprovalMsg asks the user to let a vibe use a sensitive permission
+// before retry runs its action again.
+type vibeApprovalMsg struct {
+	ie    *tooling.InterventionError
+	retry tea.Cmd
+}
 
-	inactiveBorder = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), true).
-			BorderForeground(lipgloss.Color("#444444"))
+// TUI styles, built from the current Theme by applyTheme.
+var (
+	titleStyle, userStyle, aiStyle, systemStyle         lipgloss.Style
+	subtleStyle, errorStyle, successStyle, warningStyle lipgloss.Style
+	helpStyle, ruleStyle, tagStyle                      lipgloss.Style
+	suggestionStyle, selectedSuggestionStyle            lipgloss.Style
+	treeStyle, activeBorder, inactiveBorder             lipgloss.Style
+	calcStyle                                           lipgloss.Style
+	highlight                                           lipgloss.Color
 
 	// Intervention/Approval selector styles
-	interventionBoxStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("#FF8C00")).
-				Padding(1, 2).
-				MarginTop(1)
-
-	interventionTitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FF8C00")).
-				Bold(true)
-
-	interventionChoiceStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#AAAAAA")).
-				PaddingLeft(2)
-
-	interventionSelectedStyle = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#FAFAFA")).
-					Background(lipgloss.Color("#FF8C00")).
-					Bold(true).
-					PaddingLeft(2)
+	interventionBoxStyle, interventionTitleStyle       lipgloss.Style
+	interventionChoiceStyle, interventionSelectedStyle lipgloss.Style
 )
 
 type chatState struct {
-	Messages []string `json:"messages"`
-	Input    string   `json:"input"`
+	Messages []string     `json:"messages"`
+	Times    []time.Time  `json:"times,omitempty"` // When each message first appeared
+	Input    string       `json:"input"`
+	Notices  []Notice     `json:"notices,omitempty"`
+	Pins     []string     `json:"pins,omitempty"`
+	Plan     *prompt.Plan `json:"plan,omitempty"`
+	View     *viewState   `json:"view,omitempty"` // Only saved for a hot swap
+}
+
+// viewState is where the UI was, so a hot swap restarts looking the same.
+type viewState struct {
+	Focus       focus  `json:"focus"`
+	ShowTree    bool   `json:"show_tree"`
+	CurrentPath string `json:"current_path"`
+	YOffset     int    `json:"y_offset"`
 }
 
 var allCommands = []string{
-	"/help", "/status", "/cwd", "/version", "/clear", "/exit", "/show-tree", "/shot", "/auth", "/mcp", "/sys", "/skill", "/models", "/update", "/restart",
+	"/help", "/status", "/cwd", "/version", "/clear", "/exit", "/show-tree", "/shot", "/auth", "/mcp", "/sys", "/skill", "/models", "/update", "/restart", "/notifications", "/pin", "/unpin", "/plan", "/debug", "/session", "/context", "/history", "/search", "/undo", "/export", "/copy", "/theme", "/stop",
 }
 
 var subCommands = map[string][]string{
-	"/auth":   {"/ollama", "/github-models", "/github-copilot", "/openai", "/anthropic"},
-	"/mcp":    {"/list", "/add", "/logs", "/call"},
-	"/sys":    {"/stats", "/env", "/update", "/logs"},
-	"/skill":  {"/list", "/info", "/load", "/disable"},
-	"/models": {"/list", "/use", "/pull"},
+	"/auth":          {"/ollama", "/github-models", "/github-copilot", "/openai", "/azure-openai", "/anthropic", "/gemini"},
+	"/mcp":           {"/list", "/add", "/logs", "/call"},
+	"/sys":           {"/stats", "/env", "/update", "/logs", "/audit", "/approvals", "/doctor"},
+	"/export":        {"/markdown", "/json", "/html"},
+	"/copy":          {"/code"},
+	"/skill":         {"/list", "/info", "/load", "/enable", "/disable", "/run", "/logs"},
+	"/models":        {"/list", "/use", "/pull", "/usage"},
+	"/notifications": {"/show", "/dismiss", "/clear"},
+	"/pin":           {"/list"},
+	"/plan":          {"/show", "/clear"},
+	"/debug":         {"/failures"},
+	"/session":       {"/list", "/new", "/switch", "/delete"},
+	"/context":       {"/show"},
+	"/theme":         {"/show", "/reload"},
 }
 
 func buildBanner(width int) string {
@@ -266,7 +283,7 @@ func ensureBanner(messages *[]string, banner string) {
 
 func initialModel(b *brain.Brain) *model {
 	ta := textarea.New()
-	ta.Placeholder = "Send a message or type / for commands..."
+	ta.Placeholder = chatPlaceholder
 	ta.Focus()
 	ta.Prompt = "┃ "
 	ta.CharLimit = 2000
@@ -303,82 +320,199 @@ func initialModel(b *brain.Brain) *model {
 		// Thinking / Agentic Process State
 		thinkingLog: []StatusEvent{},
 		isThinking:  false,
+		streamIdx:   -1,
+		md:          &markdownRenderer{},
+		hl:          &fileHighlighter{},
+
+		currentSession: b.Session(),
 
 		updater: NewAsyncUpdateManager(),
 	}
 
+	themeWarnings := m.loadTheme()
+	m.loadHistory()
+
 	// Load initial tree
 	// Load initial tree
 	m.loadTree(cwd)
 
-	// Attempt to restore state
-	// Priority 1: Hot-Swap State (explicit file path)
+	// The last discovery fills the model selector until Init's refresh lands.
+	if cached, _, ok := b.CachedModels(); ok {
+		m.allModelDiscoveries = cached
+		m.modelsCached = true
+	}
+
+	// A hot swap hands over its state in a file, which wins over the
+	// session's saved state.
 	if resumeStateFile != "" {
-		content, err := os.ReadFile(resumeStateFile)
+		err := m.resumeFrom(resumeStateFile)
 		if err == nil {
-			var state chatState
-			if json.Unmarshal(content, &state) == nil {
-				m.messages = state.Messages
-				m.textarea.SetValue(state.Input)
-				// Clean up the temp file
-				os.Remove(resumeStateFile)
+			m.noticeThemeWarnings(themeWarnings)
+			return m
+		}
+		m.loadSession()
+		m.messages = append(m.messages, errorStyle.Render(" RESUME ")+" "+helpStyle.Render("Could not restore the state from before the restart: "+err.Error()))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		m.noticeThemeWarnings(themeWarnings)
+		return m
+	}
 
-				// Append a system note about the update
-				ensureBanner(&m.messages, banner)
+	// Priority 2: Persistent Session State (Brain Memory)
+	m.loadSession()
+	m.noticeThemeWarnings(themeWarnings)
+	return m
+}
+
+// resumeFrom restores the state PerformHotSwap left in path, and deletes
+// the file.
+func (m *model) resumeFrom(path string) error {
+	content, err := os.ReadFile(path)
+	os.Remove(path)
+	if err != nil {
+		return err
+	}
+	var state chatState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return fmt.Errorf("%s is corrupt: %w", filepath.Base(path), err)
+	}
 
-				updateMsg := lipgloss.NewStyle().
-					Border(lipgloss.RoundedBorder()).
-					BorderForeground(lipgloss.Color("62")).
-					Padding(0, 1).
-					Foreground(lipgloss.Color("10")).
-					Render(fmt.Sprintf("⚡ UPDATED TO %s", "LATEST")) // We don't have the hash here easily unless we passed it.
+	m.messages = state.Messages
+	m.times = state.Times
+	ensureBanner(&m.messages, m.banner)
+	m.textarea.SetValue(state.Input)
+	m.textarea.CursorEnd()
+	notifications.Restore(state.Notices)
+	m.brain.RestorePins(state.Pins)
+	m.brain.SetPlan(state.Plan)
 
-				// Better: We can pass the new version in the state file too!
+	updateMsg := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("10")).
+		Render("⚡ UPDATED TO " + Version)
+	m.messages = append(m.messages, updateMsg)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
 
-				m.messages = append(m.messages, updateMsg)
+	if v := state.View; v != nil {
+		m.restoreView(*v)
+	}
+	return nil
+}
 
-				m.viewport.SetContent(m.renderMessages())
-				m.viewport.GotoBottom()
-				return m
-			}
+// restoreView puts back the pane layout, explorer location and scroll
+// position saved by hotSwapState. A path that has gone is skipped. An open
+// editor comes back as the file being viewed, since edits are not saved.
+func (m *model) restoreView(v viewState) {
+	m.showTree = v.ShowTree
+	if info, err := os.Stat(v.CurrentPath); err == nil {
+		if info.IsDir() {
+			m.currentPath = v.CurrentPath
+			m.treeCursor = 0
+			m.loadTree(v.CurrentPath)
+		} else {
+			m.currentPath = filepath.Dir(v.CurrentPath)
+			m.loadTree(m.currentPath)
+			m.openFile(v.CurrentPath)
 		}
 	}
+	m.focus = v.Focus
+	if m.focus == focusEdit {
+		m.focus = focusPerusal
+	}
+	if m.focus != focusChat {
+		m.textarea.Blur()
+	}
+	m.viewport.SetYOffset(v.YOffset)
+}
 
-	// Priority 2: Persistent Session State (Brain Memory)
+// hotSwapState is the saved session state plus where the UI is, for
+// PerformHotSwap.
+func (m *model) hotSwapState() chatState {
+	m.stampMessages()
+	return chatState{
+		Messages: m.messages,
+		Times:    m.times,
+		Input:    m.textarea.Value(),
+		Notices:  notifications.List(),
+		Pins:     m.brain.PinnedPaths(),
+		Plan:     m.brain.Plan(),
+		View: &viewState{
+			Focus:       m.focus,
+			ShowTree:    m.showTree,
+			CurrentPath: m.currentPath,
+			YOffset:     m.viewport.YOffset,
+		},
+	}
+}
+
+// loadSession replaces the transcript, input, pins and plan with the active
+// session's saved state, or a fresh transcript if it has none.
+func (m *model) loadSession() {
 	var state chatState
-	if err := b.RecallState("chat_session", &state); err == nil && len(state.Messages) > 0 {
+	if err := m.brain.RecallSession(&state); err == nil && len(state.Messages) > 0 {
 		m.messages = state.Messages
-		ensureBanner(&m.messages, banner)
+		m.times = state.Times
+		ensureBanner(&m.messages, m.banner)
 		m.textarea.SetValue(state.Input)
+		notifications.Restore(state.Notices)
+		m.brain.RestorePins(state.Pins)
+		m.brain.SetPlan(state.Plan)
 		m.viewport.SetContent(m.renderMessages())
 		if m.viewport.TotalLineCount() <= m.viewport.Height {
 			m.viewport.GotoTop()
 		} else {
 			m.viewport.GotoBottom()
 		}
-	} else {
-		m.messages = append(m.messages, banner)
-		m.messages = append(m.messages, "Type "+systemStyle.Render("/help")+" to see available commands.")
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoTop()
+		return
 	}
 
-	return m
+	m.messages = m.freshMessages()
+	m.times = nil
+	m.textarea.Reset()
+	m.brain.RestorePins(nil)
+	m.brain.SetPlan(nil)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoTop()
 }
 
 func (m *model) Init() tea.Cmd {
 	return tea.Batch(
 		textarea.Blink,
 		m.updater.CheckUpdateCmd(false), // Background check
+		waitForNotice(),
+		waitForChunk(),
+		waitForThemeChange(),
+		m.refreshModels(),
 	)
 }
 
+// stampMessages records the time of messages added since the last call.
+// Messages are only ever appended or replaced in place, except by /clear
+// and session switches, which reset the times themselves.
+func (m *model) stampMessages() {
+	if len(m.times) > len(m.messages) {
+		m.times = m.times[:len(m.messages)]
+	}
+	now := time.Now()
+	for len(m.times) < len(m.messages) {
+		m.times = append(m.times, now)
+	}
+}
+
 func (m *model) saveState() {
+	m.stampMessages()
 	state := chatState{
 		Messages: m.messages,
+		Times:    m.times,
 		Input:    m.textarea.Value(),
+		Notices:  notifications.List(),
+		Pins:     m.brain.PinnedPaths(),
+		Plan:     m.brain.Plan(),
 	}
-	m.brain.StoreState("chat_session", state)
+	m.brain.StoreSession(state)
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -449,7 +583,39 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if msg.String() == "ctrl+n" {
+			m.showNotifications = !m.showNotifications
+			return m, nil
+		}
+
+		if msg.String() == "ctrl+y" && m.focus != focusEdit {
+			m.messages = append(m.messages, m.copyReply(1, false))
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+
 		if msg.String() == "esc" {
+			if m.showNotifications {
+				m.showNotifications = false
+				return m, nil
+			}
+			if m.search != nil {
+				m.closeSearch()
+				return m, nil
+			}
+			if m.mcpWizard != nil {
+				m.closeMCPWizard(subtleStyle.Render("MCP server not added."))
+				return m, nil
+			}
+			if m.isThinking && m.cancelRequest != nil {
+				m.cancelInFlight()
+				return m, nil
+			}
+			if m.focus == focusPerusal && m.explorer != nil {
+				m.explorer = nil
+				return m, nil
+			}
 			if m.focus == focusEdit {
 				m.focus = focusPerusal
 				return m, nil
@@ -467,6 +633,18 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.pendingIntervention != nil {
 				return m.handleInterventionKey(msg)
 			}
+			// The /mcp /add wizard takes each line typed as an answer.
+			if m.mcpWizard != nil && msg.Type == tea.KeyEnter {
+				v := m.textarea.Value()
+				m.textarea.Reset()
+				m.suggestions = nil
+				return m, func() tea.Msg { return mcpWizardMsg{value: v} }
+			}
+			if m.search != nil {
+				if handled, cmd := m.handleSearchKey(msg); handled {
+					return m, cmd
+				}
+			}
 			return m.handleChatKey(msg)
 		case focusPerusal:
 			return m.handlePerusalKey(msg)
@@ -474,21 +652,37 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleEditKey(msg)
 		}
 
+	case StreamChunkMsg:
+		m.appendChunk(brain.StreamChunk(msg))
+		return m, waitForChunk()
+
+	case mcpWizardMsg:
+		if m.mcpWizard == nil {
+			return m, nil
+		}
+		cmd := m.answerMCPWizard(msg.value)
+		if m.mcpWizard != nil {
+			m.drawMCPWizard()
+		}
+		return m, cmd
+
 	case brain.Response:
 		m.isThinking = false
-		if msg.Error != nil {
+		reqID := m.streamReqID
+		streamIdx := m.endStream()
+		if msg.Error != nil && errors.Is(msg.Error, context.Canceled) {
+			// Keep whatever was streamed before the user stopped it.
+			m.messages = append(m.messages, systemStyle.Render(cancelledNotice))
+		} else if msg.Error != nil {
 			// Check if this is an intervention request
 			var interventionErr *tooling.InterventionError
 			if errors.As(msg.Error, &interventionErr) {
-				// Set up the intervention state
+				// The brain has parked the agent loop until the user answers.
 				m.pendingIntervention = &interventionState{
-					title:    interventionErr.Title,
-					choices:  interventionErr.Choices,
-					selected: 0,
-					resume: func(choice string) (interface{}, error) {
-						return interventionErr.Resume(choice)
-					},
-					requestID: uuid.NewString(),
+					title:     interventionErr.Title,
+					choices:   interventionErr.Choices,
+					selected:  0,
+					requestID: reqID,
 				}
 				m.messages = append(m.messages, m.renderInterventionSelector())
 				m.viewport.SetContent(m.renderMessages())
@@ -496,25 +690,69 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil // Wait for user input
 			}
 			m.messages = append(m.messages, errorStyle.Render(" BRAIN ERROR ")+"\n"+msg.Error.Error())
+		} else if streamIdx >= 0 {
+			m.messages[streamIdx] = aiPrefix + msg.Content
 		} else {
-			m.messages = append(m.messages, aiStyle.Render("Brain: ")+m.styleMessage(msg.Content))
+			m.messages = append(m.messages, aiPrefix+msg.Content)
 		}
+		m.refreshPlan()
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
 		m.saveState()
 
+	case vibeApprovalMsg:
+		m.isThinking = false
+		m.pendingIntervention = &interventionState{
+			title:   msg.ie.Title,
+			choices: msg.ie.Choices,
+			resume:  msg.ie.Resume,
+			retry:   msg.retry,
+		}
+		m.messages = append(m.messages, m.renderInterventionSelector())
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+
 	case statusMsg:
+		if msg.Step == "download" {
+			// Drawn as a progress bar, not a thinking line
+			d := StatusEvent(msg)
+			m.download = &d
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, waitForStatus()
+		}
+		if msg.Step == "diff" {
+			// A file the agent wrote; shown in full rather than as a log line
+			m.messages = append(m.messages, systemStyle.Render(" DIFF ")+"\n"+msg.Message)
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, waitForStatus()
+		}
+		if msg.Step == "context_usage" {
+			m.contextUsage = m.brain.ContextUsage()
+		}
 		m.thinkingLog = append(m.thinkingLog, StatusEvent(msg))
 		if len(m.thinkingLog) > 12 { // Keep last 12 lines for context
 			m.thinkingLog = m.thinkingLog[1:]
 		}
+		if msg.Step == "response" || msg.Step == "tool" {
+			m.refreshPlan()
+		}
 		// Re-render viewport to show thinking progress
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
 		return m, waitForStatus()
 
+	case pullCandidatesMsg:
+		m.pullCandidates = msg
+		if val := m.textarea.Value(); strings.Contains(val, "/models /pull") {
+			m.updateSuggestions(val)
+		}
+
 	case []brain.ModelDiscovery:
 		m.allModelDiscoveries = msg
+		m.modelsCached = false
 		// If we are currently typing /models /use, refresh suggestions
 		val := m.textarea.Value()
 		if strings.Contains(val, "/models /use") {
@@ -524,40 +762,44 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case UpdateAvailableMsg:
 		// Start download immediately
 		m.updateVersion = msg.Latest.TagName
-		m.messages = append(m.messages, subtleStyle.Render("⬇️  New version found. Downloading..."))
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.pushNotice(notifications.Add(NoticeInfo, "update", "New version found. Downloading..."))
 		return m, m.updater.DownloadUpdateCmd(msg.Latest)
 
 	case UpdateReadyMsg:
 		m.updateReady = true
-		m.messages = append(m.messages, systemStyle.Render(" UPDATE READY ")+"\n"+helpStyle.Render("A new version has been downloaded. Please restart vibeaura to apply."))
+		m.download = nil
 		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.pushNotice(notifications.Add(NoticeInfo, "update", "A new version has been downloaded. Run /restart to apply."))
 
-	case UpdateNoUpdateMsg:
-		m.messages = append(m.messages, subtleStyle.Render("✅  Vibeauracle is already up to date."))
+	case UpdateFailedMsg:
+		m.download = nil
 		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.pushNotice(notifications.Add(NoticeWarning, "update", "Update failed: "+msg.Err.Error()))
 
-	case interventionResultMsg:
-		m.isThinking = false
-		if msg.err != nil {
-			m.messages = append(m.messages, errorStyle.Render(" ACTION ERROR ")+"\n"+msg.err.Error())
-		} else if result, ok := msg.result.(*tooling.ToolResult); ok {
-			if result.Error != nil {
-				m.messages = append(m.messages, errorStyle.Render(" TOOL ERROR ")+"\n"+result.Error.Error())
-			} else {
-				m.messages = append(m.messages, aiStyle.Render("Tool: ")+m.styleMessage(result.Content))
-			}
-		} else if msg.result != nil {
-			m.messages = append(m.messages, aiStyle.Render("Result: ")+fmt.Sprintf("%v", msg.result))
-		} else {
-			m.messages = append(m.messages, subtleStyle.Render("✓ Action completed"))
+	case noticeMsg:
+		m.pushNotice(Notice(msg))
+		return m, waitForNotice()
+
+	case themeChangedMsg:
+		m.reloadTheme(false)
+		return m, waitForThemeChange()
+
+	case auditEntryMsg:
+		if !msg.ok || msg.ch != m.auditCh {
+			return m, nil // A tail from an earlier /sys /audit that was closed
+		}
+		m.auditEntries = append(m.auditEntries, msg.entry)
+		if len(m.auditEntries) > auditPaneSize {
+			m.auditEntries = m.auditEntries[len(m.auditEntries)-auditPaneSize:]
 		}
+		m.updatePerusalContent()
+		return m, waitForAudit(m.auditCh)
+
+	case UpdateNoUpdateMsg:
+		m.messages = append(m.messages, subtleStyle.Render("✅  Vibeauracle is already up to date."))
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
-		m.saveState()
+
 	}
 
 	// 5. Check for Hot-Swap Opportunity
@@ -593,6 +835,14 @@ func (m *model) handleChatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// or empty, though textarea handles internal navigation.
 	// To be safer and match user request perfectly: if focus is Chat,
 	// and they aren't nav-ing suggestions, arrows should at least scroll if empty.
+	if k := msg.String(); (k == "up" || k == "down") && m.recallHistory(k) {
+		if strings.HasPrefix(m.textarea.Value(), "/") {
+			m.textarea.FocusedStyle.Text = systemStyle
+		} else {
+			m.textarea.FocusedStyle.Text = lipgloss.NewStyle()
+		}
+		return m, nil
+	}
 	if m.textarea.Value() == "" {
 		switch msg.String() {
 		case "up":
@@ -616,17 +866,41 @@ func (m *model) handleChatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.String() {
 	case "ctrl+c":
+		// While a request is running, Ctrl+C stops it instead of quitting.
+		if m.isThinking && m.cancelRequest != nil {
+			m.cancelInFlight()
+			return m, nil
+		}
 		m.saveState()
-		return m, tea.Quit
+		return m.quit()
 	case "enter":
 		v := m.textarea.Value()
+		if m.pendingClear {
+			return m.confirmClear(v)
+		}
 		if strings.TrimSpace(v) == "" {
 			return m, nil
 		}
+		m.recordHistory(v)
 		if strings.HasPrefix(strings.TrimSpace(v), "/") {
 			return m.handleSlashCommand(v)
 		}
+		if m.brain.Config().UI.Calc {
+			if strings.HasPrefix(strings.TrimSpace(v), "=") {
+				return m.handleCalc(v)
+			}
+			// Expand $(...) locally so the transcript shows exactly what the
+			// model receives. Text that does not evaluate, such as a pasted
+			// shell $(ls), is sent as typed.
+			if expanded, err := sys.ExpandSubstitutions(v); err == nil {
+				v = expanded
+			}
+		}
 		m.messages = append(m.messages, userStyle.Render("You: ")+m.styleMessage(v))
+		tags := m.brain.ResolveTags(v)
+		if notice := tagNotice(tags); notice != "" {
+			m.messages = append(m.messages, notice)
+		}
 		m.textarea.Reset()
 		m.textarea.FocusedStyle.Text = lipgloss.NewStyle()
 		m.suggestions = nil
@@ -634,7 +908,7 @@ func (m *model) handleChatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.viewport.GotoBottom()
 		m.saveState()
 		m.isThinking = true
-		return m, m.processRequest(v)
+		return m, m.processRequest(v, tags)
 	default:
 		val := m.textarea.Value()
 		m.updateSuggestions(val)
@@ -643,6 +917,9 @@ func (m *model) handleChatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if strings.HasSuffix(val, "/models /use ") && len(m.allModelDiscoveries) == 0 {
 			return m, m.discoverModels()
 		}
+		if strings.HasSuffix(val, "/models /pull ") && len(m.pullCandidates) == 0 {
+			return m, m.loadPullCandidates()
+		}
 
 		if strings.HasPrefix(val, "/") {
 			m.textarea.FocusedStyle.Text = systemStyle
@@ -653,6 +930,23 @@ func (m *model) handleChatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleCalc answers "=" expressions locally without touching the provider.
+// On a parse error the input is kept so it can be fixed.
+func (m *model) handleCalc(v string) (tea.Model, tea.Cmd) {
+	expr := strings.TrimPrefix(strings.TrimSpace(v), "=")
+	result, err := sys.Eval(expr)
+	if err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" CALC ")+" "+helpStyle.Render(strings.TrimSpace(expr)+": "+err.Error()))
+	} else {
+		m.messages = append(m.messages, calcStyle.Render(" = ")+" "+helpStyle.Render(strings.TrimSpace(expr))+" → "+aiStyle.Render(result))
+		m.textarea.Reset()
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveState()
+	return m, nil
+}
+
 func (m *model) styleMessage(v string) string {
 	if strings.TrimSpace(v) == "" {
 		return ""
@@ -706,7 +1000,15 @@ func (m *model) handlePerusalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if m.isFileOpen {
+	if m.showPlan {
+		return m.handlePlanKey(msg)
+	}
+
+	if m.explorer != nil {
+		return m.handleExplorerPromptKey(msg)
+	}
+
+	if m.isFileOpen || m.showAudit {
 		switch msg.String() {
 		case "up", "k":
 			m.perusalVp.LineUp(1)
@@ -753,6 +1055,32 @@ func (m *model) handlePerusalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.focus = focusEdit
 			m.editArea.Focus()
 		}
+	case "n":
+		m.startExplorerPrompt(explorerNewFile)
+	case "N":
+		m.startExplorerPrompt(explorerNewDir)
+	case "r":
+		m.startExplorerPrompt(explorerRename)
+	case "d":
+		m.startExplorerPrompt(explorerDelete)
+	case "p":
+		if m.isFileOpen || len(m.treeEntries) == 0 {
+			return m, nil
+		}
+		entry := m.treeEntries[m.treeCursor]
+		if entry.IsDir() {
+			return m, nil
+		}
+		path := filepath.Join(m.currentPath, entry.Name())
+		if m.brain.IsPinned(path) {
+			m.brain.Unpin(path)
+		} else if err := m.brain.Pin(path); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" PIN ")+"\n"+helpStyle.Render(err.Error()))
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+		}
+		m.updatePerusalContent()
+		m.saveState()
 	}
 	return m, nil
 }
@@ -760,7 +1088,13 @@ func (m *model) handlePerusalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if msg.String() == "ctrl+s" {
 		content := m.editArea.Value()
-		os.WriteFile(m.currentPath, []byte(content), 0644)
+		// Write back with the file's original encoding, EOL and BOM.
+		if err := sys.NewLocalFS("").WriteFileEncoding(m.currentPath, []byte(content), m.editFormat.Charset); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" SAVE FAILED ")+"\n"+err.Error())
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, nil
+		}
 		m.focus = focusPerusal
 		m.openFile(m.currentPath) // Refresh view
 		return m, nil
@@ -771,8 +1105,21 @@ func (m *model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *model) renderMessages() string {
 	var sb strings.Builder
 	for i, msg := range m.messages {
+		// AI replies are stored as raw markdown; glamour output is already
+		// wrapped to the viewport width.
+		if body, ok := strings.CutPrefix(msg, aiPrefix); ok {
+			var rendered bool
+			if msg, rendered = m.renderAIMessage(body, i == m.streamIdx); rendered {
+				sb.WriteString(msg)
+				if i < len(m.messages)-1 {
+					sb.WriteString("\n\n")
+				}
+				continue
+			}
+		}
+
 		// Use lipgloss to wrap the message to the viewport width precisely.
-		wrapped := lipgloss.NewStyle().Width(m.viewport.Width).Render(msg)
+		wrapped := lipgloss.NewStyle().Width(m.viewport.Width).Render(styleDiff(msg))
 		sb.WriteString(wrapped)
 		if i < len(m.messages)-1 {
 			sb.WriteString("\n\n")
@@ -785,29 +1132,98 @@ func (m *model) renderMessages() string {
 			color := subtleStyle
 			switch log.Step {
 			case "think", "perceive", "tools", "prompt":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")) // Purple for planning
+				color = lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme.Primary)) // Planning
 			case "loop":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#04D9FF")) // Cyan for loop
+				color = lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme.Secondary)) // Loop
 			case "response":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00")) // Yellow for AI response
+				color = lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme.Highlight)) // AI response
 			case "exec", "tool":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")) // Orange for action
+				color = warningStyle // Action
 			case "done", "reflect":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")) // Green for success
+				color = successStyle
 			case "error":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")) // Red for errors
+				color = lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme.Error))
 			case "intervention":
-				color = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8C00")) // Dark orange for interventions
+				color = warningStyle // Interventions
 			}
 
+			if log.Step == "sys_delta" {
+				sb.WriteString(fmt.Sprintf("  %s %s\n", log.Icon, styleDelta(log.Message)))
+				continue
+			}
 			line := fmt.Sprintf("  %s %s", log.Icon, log.Message)
 			sb.WriteString(color.Render(line) + "\n")
 		}
 	}
 
+	if m.download != nil {
+		sb.WriteString("\n\n" + m.renderDownload())
+	}
+
 	return sb.String()
 }
 
+// styleDiff colours the unified diffs in msg: after a "--- "/"+++ "
+// header, added lines green and removed lines red. Text outside a diff is
+// left alone.
+func styleDiff(msg string) string {
+	if !strings.Contains(msg, "\n+++ ") {
+		return msg
+	}
+	lines := strings.Split(msg, "\n")
+	inDiff := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			inDiff = true
+			lines[i] = subtleStyle.Render(line)
+		case !inDiff:
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "@@"):
+			lines[i] = subtleStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = successStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = errorStyle.Render(line)
+		case strings.HasPrefix(line, " "), strings.HasPrefix(line, "…"):
+		default:
+			inDiff = false
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// styleDelta colours the signed values in a sys_info delta such as
+// "CPU +3.2%, memory -120 MB, goroutines +4": increases red, decreases
+// green. Unchanged values stay plain.
+func styleDelta(msg string) string {
+	words := strings.Split(msg, " ")
+	for i, w := range words {
+		if len(w) < 2 || (w[0] != '+' && w[0] != '-') || strings.Trim(w[1:], "0.%,") == "" {
+			continue
+		}
+		if w[0] == '+' {
+			words[i] = errorStyle.Render(w)
+		} else {
+			words[i] = successStyle.Render(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// renderDownload draws the update download's progress bar.
+func (m *model) renderDownload() string {
+	d := m.download
+	label := subtleStyle.Render(fmt.Sprintf("  %s %s", d.Icon, d.Message))
+	if d.Percent < 0 {
+		return label
+	}
+	width := max(min(m.viewport.Width-12, 40), 10)
+	filled := width * d.Percent / 100
+	bar := lipgloss.NewStyle().Foreground(highlight).Render(strings.Repeat("█", filled)) +
+		subtleStyle.Render(strings.Repeat("░", width-filled))
+	return label + "\n  " + bar + fmt.Sprintf(" %3d%%", d.Percent)
+}
+
 func (m *model) loadTree(path string) {
 	entries, _ := os.ReadDir(path)
 	m.treeEntries = nil
@@ -821,16 +1237,40 @@ func (m *model) loadTree(path string) {
 }
 
 func (m *model) openFile(path string) {
-	content, err := os.ReadFile(path)
+	raw, err := os.ReadFile(path)
 	if err == nil {
+		content, format := sys.DecodeText(raw)
 		m.isFileOpen = true
 		m.currentPath = path
-		m.editArea.SetValue(string(content))
-		m.perusalVp.SetContent(string(content))
+		m.editFormat = format
+		m.editArea.SetValue(content)
+		m.perusalVp.SetContent(m.highlightFile(path, content))
+	}
+}
+
+// highlightFile colours content for the perusal pane when ui.syntax_highlight
+// is on. The edit area always gets the raw text.
+func (m *model) highlightFile(path, content string) string {
+	cfg := m.brain.Config()
+	if cfg == nil || !cfg.UI.Highlight {
+		return content
+	}
+	if out, ok := m.hl.highlight(path, content, currentTheme.Base); ok {
+		return out
 	}
+	return content
 }
 
 func (m *model) updatePerusalContent() {
+	if m.showAudit {
+		m.perusalVp.SetContent(m.renderAudit())
+		m.perusalVp.GotoBottom()
+		return
+	}
+	if m.showPlan {
+		m.perusalVp.SetContent(m.renderPlan())
+		return
+	}
 	if m.isFileOpen {
 		return
 	}
@@ -846,6 +1286,9 @@ func (m *model) updatePerusalContent() {
 			icon = "📁 "
 		}
 		line := cursor + icon + entry.Name()
+		if !entry.IsDir() && m.brain.IsPinned(filepath.Join(m.currentPath, entry.Name())) {
+			line += " 📌"
+		}
 		if i == m.treeCursor {
 			sb.WriteString(suggestionStyle.Render(line) + "\n")
 		} else {
@@ -859,38 +1302,66 @@ func shortenModelName(name string) string {
 	return brain.ShortenModelName(name)
 }
 
+// filterMode is the kind of fuzzy selector the suggestion list shows.
+type filterMode int
+
+const (
+	filterNone      filterMode = iota
+	filterModelUse             // /models /use: discovered models, provider|name
+	filterModelPull            // /models /pull: Ollama pull candidates, pull|name
+)
+
+// pullCandidatesMsg carries the /models /pull candidates.
+type pullCandidatesMsg []brain.PullCandidate
+
 func (m *model) updateSuggestions(val string) {
 	m.suggestions = nil
 	m.suggestionIdx = 0
 	m.triggerChar = ""
-	m.isFilteringModels = false
+	m.filterMode = filterNone
 
 	if val == "" {
 		return
 	}
 
-	if strings.Contains(val, "/models /use") {
-		m.isFilteringModels = true
-		if len(m.allModelDiscoveries) == 0 {
-			// Trigger discovery
-			go func() {
-				// We can't return Cmd here, so we'll just wait for the next Update cycle
-				// if we were in a proper Msg flow, but here we are in a helper.
-				// Better to trigger this from handleChatKey or applySuggestion.
-			}()
+	if strings.Contains(val, "/models /pull") {
+		m.filterMode = filterModelPull
+		_, filter, _ := strings.Cut(val, "/models /pull")
+		m.suggestionFilter = strings.TrimSpace(filter)
+		for _, c := range m.pullCandidates {
+			if m.suggestionFilter == "" || strings.Contains(strings.ToLower(c.Name), strings.ToLower(m.suggestionFilter)) {
+				m.suggestions = append(m.suggestions, "pull|"+c.Name)
+			}
 		}
+		return
+	}
 
-		// Everything after "/models /use " is the filter
+	if strings.Contains(val, "/models /use") {
+		// The list is filled at startup (see refreshModels); an empty one
+		// is discovered when "/models /use " is typed.
+		m.filterMode = filterModelUse
+
+		// Everything after "/models /use " is the filter, as the
+		// provider and model of the full command ("ollama qwen") or any
+		// part of either.
 		parts := strings.Split(val, "/models /use")
 		filter := ""
 		if len(parts) > 1 {
 			filter = strings.TrimSpace(parts[1])
 		}
 		m.suggestionFilter = filter
+		words := strings.Fields(strings.ToLower(filter))
 
 		for _, d := range m.allModelDiscoveries {
-			display := fmt.Sprintf("%s (%s)", shortenModelName(d.Name), d.Provider)
-			if filter == "" || strings.Contains(strings.ToLower(display), strings.ToLower(filter)) {
+			haystack := strings.ToLower(d.Provider + " " + d.Name + " " + shortenModelName(d.Name))
+			matched := true
+			for _, w := range words {
+				if !strings.Contains(haystack, w) {
+					matched = false
+					break
+				}
+			}
+			if matched {
 				// We store the full identifier for applySuggestion, but display it nicely
 				m.suggestions = append(m.suggestions, fmt.Sprintf("%s|%s", d.Provider, d.Name))
 			}
@@ -996,7 +1467,8 @@ func (m *model) getFileSuggestions(prefix string) []string {
 type StatusEvent struct {
 	Icon    string
 	Message string
-	Step    string // "plan", "exec", "reflect"
+	Step    string // "plan", "exec", "reflect", "download"
+	Percent int    // For "download": 0-100, or -1 when the size is unknown
 }
 
 // Global channel for streaming thinking steps
@@ -1034,8 +1506,23 @@ func (m *model) applySuggestion() (tea.Model, tea.Cmd) {
 
 	suggestion := m.suggestions[m.suggestionIdx]
 
+	if m.filterMode == filterModelPull {
+		name := strings.TrimPrefix(suggestion, "pull|")
+		m.suggestions = nil
+		if c, ok := m.pullCandidate(name); ok && c.Installed {
+			m.textarea.Reset()
+			m.messages = append(m.messages, systemStyle.Render(" OLLAMA PULL ")+"\n"+helpStyle.Render(name+" is already installed. Switch to it with /models /use ollama "+name))
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+		m.textarea.SetValue("/models /pull " + name)
+		m.textarea.SetCursor(len(m.textarea.Value()))
+		return m.handleSlashCommand(m.textarea.Value())
+	}
+
 	// Handle model selection specialized format: provider|name
-	if m.isFilteringModels && strings.Contains(suggestion, "|") {
+	if m.filterMode == filterModelUse && strings.Contains(suggestion, "|") {
 		parts := strings.Split(suggestion, "|")
 		provider := parts[0]
 		modelName := parts[1]
@@ -1087,10 +1574,19 @@ func (m *model) applySuggestion() (tea.Model, tea.Cmd) {
 
 	// Auto-execute when suggestion completes a no-arg command or a no-arg subcommand.
 	noArgSubs := map[string]map[string]bool{
-		"/models": {"/list": true},
-		"/sys":    {"/stats": true, "/env": true, "/update": true, "/logs": true},
-		"/mcp":    {"/list": true, "/logs": true},
-		"/skill":  {"/list": true},
+		"/models":        {"/list": true, "/usage": true},
+		"/sys":           {"/stats": true, "/env": true, "/update": true, "/logs": true, "/audit": true, "/approvals": true, "/doctor": true},
+		"/mcp":           {"/list": true, "/add": true, "/logs": true},
+		"/skill":         {"/list": true},
+		"/notifications": {"/show": true, "/clear": true},
+		"/pin":           {"/list": true},
+		"/export":        {"/markdown": true, "/json": true, "/html": true},
+		"/copy":          {"/code": true},
+		"/plan":          {"/show": true, "/clear": true},
+		"/debug":         {"/failures": true},
+		"/session":       {"/list": true},
+		"/context":       {"/show": true},
+		"/theme":         {"/show": true, "/reload": true},
 	}
 
 	if len(parts) == 1 && m.triggerChar == "/" {
@@ -1113,14 +1609,26 @@ func (m *model) applySuggestion() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m *model) processRequest(content string) tea.Cmd {
+func (m *model) processRequest(content string, tags *brain.TagContext) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := brain.Request{
+		ID:        uuid.NewString(),
+		SessionID: m.brain.Session(),
+		Content:   content,
+		Tags:      tags,
+	}
+	m.cancelRequest = cancel
+	m.streamReqID = req.ID
+	m.streamIdx = -1
+
 	return func() tea.Msg {
-		ctx := context.Background()
-		req := brain.Request{
-			ID:      uuid.NewString(),
-			Content: content,
-		}
-		resp, err := m.brain.Process(ctx, req)
+		defer cancel()
+		resp, err := m.brain.ProcessStream(ctx, req, func(c brain.StreamChunk) {
+			select {
+			case chunkStream <- c:
+			case <-ctx.Done():
+			}
+		})
 		if err != nil {
 			resp.Error = err
 		}
@@ -1128,35 +1636,135 @@ func (m *model) processRequest(content string) tea.Cmd {
 	}
 }
 
-func (m *model) takeScreenshot() (tea.Model, tea.Cmd) {
-	config := m.brain.GetConfig()
-	dir := config.UI.ScreenshotDir
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		m.messages = append(m.messages, errorStyle.Render(" Screenshot Error: ")+err.Error())
-		return m, nil
+// tagNotice says which #tags were attached to a message, which were cut
+// short, and which matched nothing and went to the model as plain text.
+func tagNotice(tags *brain.TagContext) string {
+	var lines []string
+	if len(tags.Files) > 0 {
+		var names []string
+		for _, f := range tags.Files {
+			names = append(names, "#"+f.Tag)
+		}
+		lines = append(lines, subtleStyle.Render("📎 Attached "+strings.Join(names, ", ")))
 	}
+	if cut := tags.Truncated(); len(cut) > 0 {
+		lines = append(lines, tagStyle.Render("⚠ Truncated to fit the attachment limit: #"+strings.Join(cut, ", #")))
+	}
+	if len(tags.Missing) > 0 {
+		lines = append(lines, errorStyle.Render("⚠ No such file or directory: #"+strings.Join(tags.Missing, ", #"))+" "+helpStyle.Render("(sent as plain text)"))
+	}
+	return strings.Join(lines, "\n")
+}
 
-	timestamp := time.Now().Format("2006-01-02_150405")
-	filename := fmt.Sprintf("vibeaura_%s", timestamp)
-
-	basePath := filepath.Join(dir, filename)
-	ansiPath := basePath + ".ansi"
-	svgPath := basePath + ".svg"
-	pngPath := basePath + ".png"
+// chunkStream carries streamed model output from the brain to the TUI.
+var chunkStream = make(chan brain.StreamChunk, 256)
 
-	// Use current layout but ensure it's rendered for capture
-	m.isCapturing = true
-	rawView := m.View()
-	m.isCapturing = false
+// StreamChunkMsg delivers one streamed piece of the current AI message.
+type StreamChunkMsg brain.StreamChunk
 
-	// Tier 2: Generate SVG but don't save yet if targeting PNG
-	svgContent := convertAnsiToSVG(rawView)
-	_ = os.WriteFile(svgPath, []byte(svgContent), 0644)
+func waitForChunk() tea.Cmd {
+	return func() tea.Msg {
+		return StreamChunkMsg(<-chunkStream)
+	}
+}
 
-	// Tier 1: Try PNG
-	err := convertToPNG(svgPath, pngPath)
+// appendChunk grows the in-progress AI message. Chunks from a finished or
+// superseded request are dropped. The view follows the output only if the
+// user was already at the bottom.
+func (m *model) appendChunk(c brain.StreamChunk) {
+	if c.RequestID == "" || c.RequestID != m.streamReqID {
+		return
+	}
+	if m.streamIdx < 0 {
+		m.messages = append(m.messages, "")
+		m.streamIdx = len(m.messages) - 1
+		m.streamTurn = c.Turn
+	}
+	if c.Turn != m.streamTurn {
+		// A new agent turn replaces the previous turn's text.
+		m.streamTurn = c.Turn
+		m.streamText = ""
+	}
+	m.streamText += c.Text
+	m.messages[m.streamIdx] = aiPrefix + m.streamText
 
-	msg := systemStyle.Render(" SCREENSHOT CAPTURED ") + "\n"
+	atBottom := m.viewport.AtBottom()
+	m.viewport.SetContent(m.renderMessages())
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// cancelInFlight cancels the request being processed, if any, so its
+// model call does not outlive the TUI.
+func (m *model) cancelInFlight() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+}
+
+// cancelledNotice marks where a request stopped by the user ended.
+const cancelledNotice = "⛔ cancelled by user"
+
+// handleStopCommand cancels the request in flight, like Esc or Ctrl+C.
+func (m *model) handleStopCommand() (tea.Model, tea.Cmd) {
+	if !m.isThinking || m.cancelRequest == nil {
+		m.messages = append(m.messages, helpStyle.Render("Nothing to stop."))
+	} else {
+		m.cancelInFlight()
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// quit ends the program, cancelling the request in flight.
+func (m *model) quit() (tea.Model, tea.Cmd) {
+	m.cancelInFlight()
+	return m, tea.Quit
+}
+
+// endStream stops accepting chunks and returns the index of the streamed
+// message, or -1 if nothing was streamed.
+func (m *model) endStream() int {
+	idx := m.streamIdx
+	m.streamReqID = ""
+	m.streamIdx = -1
+	m.streamText = ""
+	m.cancelRequest = nil
+	return idx
+}
+
+func (m *model) takeScreenshot() (tea.Model, tea.Cmd) {
+	config := m.brain.GetConfig()
+	dir := config.UI.ScreenshotDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" Screenshot Error: ")+err.Error())
+		return m, nil
+	}
+
+	timestamp := time.Now().Format("2006-01-02_150405")
+	filename := fmt.Sprintf("vibeaura_%s", timestamp)
+
+	basePath := filepath.Join(dir, filename)
+	ansiPath := basePath + ".ansi"
+	svgPath := basePath + ".svg"
+	pngPath := basePath + ".png"
+
+	// Use current layout but ensure it's rendered for capture
+	m.isCapturing = true
+	rawView := m.View()
+	m.isCapturing = false
+
+	// Tier 2: Generate SVG but don't save yet if targeting PNG
+	svgContent := convertAnsiToSVG(rawView)
+	_ = os.WriteFile(svgPath, []byte(svgContent), 0644)
+
+	// Tier 1: Try PNG
+	err := convertToPNG(svgPath, pngPath)
+
+	msg := systemStyle.Render(" SCREENSHOT CAPTURED ") + "\n"
 
 	if err == nil {
 		// Highest Tier: PNG only
@@ -1178,6 +1786,58 @@ func (m *model) takeScreenshot() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// takeFlag removes every occurrence of flag from args and reports whether
+// there was one.
+func takeFlag(args []string, flag string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, a := range args {
+		if a == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, found
+}
+
+// handleExportCommand saves the session, then writes it as markdown, JSON
+// or HTML: to the path given, or next to the screenshots.
+// --include-archived adds what /clear archived.
+func (m *model) handleExportCommand(parts []string) (tea.Model, tea.Cmd) {
+	format, path := "markdown", ""
+	args, includeArchived := takeFlag(parts[1:], "--include-archived")
+	if len(args) > 0 {
+		if _, ok := brain.ExportExtension(strings.TrimPrefix(args[0], "/")); ok {
+			format = strings.TrimPrefix(args[0], "/")
+			args = args[1:]
+		}
+	}
+	if len(args) > 1 {
+		m.messages = append(m.messages, systemStyle.Render(" EXPORT ")+"\n"+helpStyle.Render("Usage: /export [md|json|html] [--include-archived] [path]"))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	m.saveState()
+	session := m.brain.Session()
+	msg := systemStyle.Render(" EXPORT ") + "\n"
+	written, size, err := exportSession(m.brain, session, format, path, includeArchived)
+	if err == nil {
+		msg += helpStyle.Render(fmt.Sprintf("📄 Saved %s (%s)", written, sys.FormatBytes(int64(size))))
+	} else {
+		msg = errorStyle.Render(" Export Error: ") + err.Error()
+	}
+	m.messages = append(m.messages, msg)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
 func (m *model) handleSlashCommand(cmd string) (tea.Model, tea.Cmd) {
 	parts := strings.Fields(cmd)
 	m.textarea.Reset()
@@ -1239,65 +1899,962 @@ func (m *model) handleSlashCommand(cmd string) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	switch parts[0] {
-	case "/help":
-		m.messages = append(m.messages, systemStyle.Render(" COMMANDS ")+"\n"+helpStyle.Render("• /help    - Show this list\n• /status  - System resource snapshot\n• /mcp     - Manage MCP tools & servers\n• /skill   - Manage agentic vibes/skills\n• /sys     - Hardware & system details\n• /auth    - Manage AI provider credentials\n• /shot    - Take a beautiful TUI screenshot\n• /cwd     - Show current directory\n• /version - Show version info\n• /update  - Check for updates immediately\n• /restart - Restart vibeauracle\n• /clear   - Clear chat history\n• /exit    - Quit vibeauracle"))
-	case "/status":
-		snapshot, _ := m.brain.GetSnapshot()
-		status := fmt.Sprintf(systemStyle.Render(" SYSTEM ")+"\n"+helpStyle.Render("CPU: %.1f%% | Mem: %.1f%%"), snapshot.CPUUsage, snapshot.MemoryUsage)
-		m.messages = append(m.messages, status)
-	case "/cwd":
-		snapshot, _ := m.brain.GetSnapshot()
-		m.messages = append(m.messages, systemStyle.Render(" CWD ")+" "+helpStyle.Render(snapshot.WorkingDir))
-	case "/version":
-		m.messages = append(m.messages, systemStyle.Render(" VERSION ")+"\n"+helpStyle.Render(fmt.Sprintf("App: %s\nCommit: %s\nCompiler: %s", Version, Commit, runtime.Version())))
-	case "/auth":
-		return m.handleAuthCommand(parts)
-	case "/models":
-		return m.handleModelsCommand(parts)
-	case "/mcp":
-		return m.handleMcpCommand(parts)
-	case "/sys":
-		return m.handleSysCommand(parts)
-	case "/skill":
-		return m.handleSkillCommand(parts)
-	case "/shot":
-		return m.takeScreenshot()
-	case "/show-tree":
-		m.showTree = !m.showTree
-		// trigger resize
-		return m, func() tea.Msg { return tea.WindowSizeMsg{Width: m.width, Height: m.height} }
-	case "/clear":
-		m.messages = []string{}
-		ensureBanner(&m.messages, m.banner)
-		m.messages = append(m.messages, "Type "+systemStyle.Render("/help")+" to see available commands.")
+	switch parts[0] {
+	case "/help":
+		m.messages = append(m.messages, systemStyle.Render(" COMMANDS ")+"\n"+helpStyle.Render("• /help    - Show this list\n• /status  - System resource snapshot\n• /mcp     - Manage MCP tools & servers\n• /skill   - Manage agentic vibes/skills\n• /sys     - Hardware & system details\n• /auth    - Manage AI provider credentials\n• /shot    - Take a beautiful TUI screenshot\n• /cwd     - Show current directory\n• /version - Show version info\n• /update  - Check for updates immediately\n• /restart - Restart vibeauracle\n• /clear   - Archive & clear chat history (--force, /unarchive)\n• /notifications - Show deferred notices (Ctrl+N)\n• /pin     - Pin files into every prompt (/list, /unpin <path>)\n• /plan    - Show the agent's plan beside the chat (/show, /clear)\n• /debug   - Agent internals (/failures, /failures /clear)\n• /session - Named transcripts (/list, /new <name>, /switch <name>, /delete <name>)\n• /context - Conversation the model sees (/show)\n• /history - This session's past requests, across restarts; /history <id> shows one\n• /search  - Find messages in every saved session (/search [--include-archived] <query>)\n• /undo    - List the agent's file writes; /undo <n> restores one\n• /export  - Save this session as a file: /export [md|json|html] [--include-archived] [path]\n• /copy    - Copy the last AI reply to the clipboard (Ctrl+Y); /copy code, /copy [n]\n• /theme   - Show the colour palette; /theme /reload picks up ui.theme and vibe changes\n• =expr    - Local calculator (=37*1.21, =14 MiB to bytes, =now + 3d); $(expr) inside prompts\n• /stop    - Stop the request in progress (Esc)\n• /exit    - Quit vibeauracle"))
+	case "/status":
+		snapshot, _ := m.brain.GetSnapshot()
+		status := fmt.Sprintf(systemStyle.Render(" SYSTEM ")+"\n"+helpStyle.Render("CPU: %.1f%% | Mem: %.1f%%"), snapshot.CPUUsage, snapshot.MemoryUsage)
+		m.messages = append(m.messages, status)
+	case "/cwd":
+		snapshot, _ := m.brain.GetSnapshot()
+		m.messages = append(m.messages, systemStyle.Render(" CWD ")+" "+helpStyle.Render(snapshot.WorkingDir))
+	case "/version":
+		m.messages = append(m.messages, systemStyle.Render(" VERSION ")+"\n"+helpStyle.Render(fmt.Sprintf("App: %s\nCommit: %s\nCompiler: %s", Version, Commit, runtime.Version())))
+	case "/auth":
+		return m.handleAuthCommand(parts)
+	case "/models":
+		return m.handleModelsCommand(parts)
+	case "/mcp":
+		return m.handleMcpCommand(parts)
+	case "/sys":
+		return m.handleSysCommand(parts)
+	case "/skill":
+		return m.handleSkillCommand(parts)
+	case "/shot":
+		return m.takeScreenshot()
+	case "/export":
+		return m.handleExportCommand(parts)
+	case "/copy":
+		return m.handleCopyCommand(parts)
+	case "/show-tree":
+		m.showTree = !m.showTree
+		// trigger resize
+		return m, func() tea.Msg { return tea.WindowSizeMsg{Width: m.width, Height: m.height} }
+	case "/clear":
+		return m.handleClearCommand(parts)
+	case "/notifications":
+		return m.handleNotificationsCommand(parts)
+	case "/pin", "/unpin":
+		return m.handlePinCommand(parts)
+	case "/plan":
+		return m.handlePlanCommand(parts)
+	case "/debug":
+		return m.handleDebugCommand(parts)
+	case "/session":
+		return m.handleSessionCommand(parts)
+	case "/context":
+		return m.handleContextCommand(parts)
+	case "/history":
+		return m.handleHistoryCommand(parts)
+	case "/theme":
+		return m.handleThemeCommand(parts)
+	case "/search":
+		return m.handleSearchCommand(parts)
+	case "/undo":
+		return m.handleUndoCommand(parts)
+	case "/stop":
+		return m.handleStopCommand()
+	case "/exit":
+		return m.quit()
+	case "/update":
+		m.messages = append(m.messages, systemStyle.Render(" UPDATE ")+"\n"+helpStyle.Render("Checking for latest release..."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, m.updater.CheckUpdateCmd(true) // Manual
+	case "/restart":
+		m.saveState()
+		m.cancelInFlight()
+		restartSelf()
+		return m.quit() // Fallback if restartSelf doesn't exec
+	default:
+		m.messages = append(m.messages, errorStyle.Render(" Unknown Command: ")+parts[0])
+	}
+
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// pushNotice surfaces a new notice. Critical notices, and every notice in
+// plain mode, are also appended to the transcript.
+func (m *model) pushNotice(n Notice) {
+	if n.Severity != NoticeCritical && !m.brain.Config().UI.Plain {
+		return
+	}
+	line := formatNotice(n)
+	if n.Severity == NoticeCritical {
+		line = errorStyle.Render(line)
+	} else {
+		line = subtleStyle.Render(line)
+	}
+	m.messages = append(m.messages, line)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+func (m *model) handleNotificationsCommand(parts []string) (tea.Model, tea.Cmd) {
+	sub := "/show"
+	if len(parts) > 1 {
+		sub = strings.ToLower(parts[1])
+	}
+
+	switch sub {
+	case "/show", "show":
+		if m.brain.Config().UI.Plain {
+			var lines []string
+			for _, n := range notifications.List() {
+				lines = append(lines, formatNotice(n))
+			}
+			if len(lines) == 0 {
+				lines = append(lines, "No notifications.")
+			}
+			m.messages = append(m.messages, systemStyle.Render(" NOTIFICATIONS ")+"\n"+helpStyle.Render(strings.Join(lines, "\n")))
+		} else {
+			m.showNotifications = true
+		}
+	case "/dismiss", "dismiss":
+		if len(parts) < 3 {
+			m.messages = append(m.messages, systemStyle.Render(" NOTIFICATIONS ")+"\n"+helpStyle.Render("Usage: /notifications /dismiss <id>"))
+			break
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(parts[2], "#"))
+		if err != nil || !notifications.Dismiss(id) {
+			m.messages = append(m.messages, errorStyle.Render(" No notification with id: ")+parts[2])
+		}
+	case "/clear", "clear":
+		notifications.Clear()
+		m.showNotifications = false
+	default:
+		m.messages = append(m.messages, errorStyle.Render(" Unknown NOTIFICATIONS subcommand: ")+sub)
+	}
+
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+func (m *model) handlePinCommand(parts []string) (tea.Model, tea.Cmd) {
+	if parts[0] == "/pin" && (len(parts) == 1 || parts[1] == "/list") {
+		m.messages = append(m.messages, systemStyle.Render(" PINNED FILES ")+"\n"+helpStyle.Render(m.renderPins()))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+	if len(parts) < 2 {
+		m.messages = append(m.messages, systemStyle.Render(" PIN ")+"\n"+helpStyle.Render("Usage: /pin <path> · /unpin <path> · /pin /list"))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+
+	path := strings.Join(parts[1:], " ")
+	if parts[0] == "/unpin" {
+		if err := m.brain.Unpin(path); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" UNPIN ")+"\n"+helpStyle.Render(err.Error()))
+		} else {
+			m.messages = append(m.messages, systemStyle.Render(" UNPINNED ")+" "+helpStyle.Render(path))
+		}
+	} else {
+		if err := m.brain.Pin(path); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" PIN ")+"\n"+helpStyle.Render(err.Error()))
+		} else {
+			m.messages = append(m.messages, systemStyle.Render(" PINNED ")+" "+helpStyle.Render("📌 "+path+" will be included in every prompt"))
+		}
+	}
+	m.updatePerusalContent()
+	m.saveState()
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// renderPins lists pinned files with size and staleness.
+func (m *model) renderPins() string {
+	pins := m.brain.Pins()
+	if len(pins) == 0 {
+		return "No pinned files. Use /pin <path> or press p in the explorer."
+	}
+	cwd, _ := os.Getwd()
+	var total int64
+	var lines []string
+	for _, p := range pins {
+		name := p.Path
+		if rel, err := filepath.Rel(cwd, p.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		state := "fresh"
+		switch {
+		case p.Missing:
+			state = "missing"
+		case p.Stale:
+			state = "changed since last turn"
+		}
+		total += p.Size
+		lines = append(lines, fmt.Sprintf("📌 %-40s %8d bytes  %s", name, p.Size, state))
+	}
+	lines = append(lines, fmt.Sprintf("Total: %d of %d bytes", total, m.brain.PinBudget()))
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) handlePlanCommand(parts []string) (tea.Model, tea.Cmd) {
+	sub := "/show"
+	if len(parts) > 1 {
+		sub = parts[1]
+	}
+	switch sub {
+	case "/show":
+		m.showPlan = !m.showPlan
+		if !m.showPlan && m.isFileOpen {
+			m.openFile(m.currentPath) // Restore the file view
+		}
+		if m.showPlan && m.brain.Plan() == nil {
+			m.messages = append(m.messages, systemStyle.Render(" PLAN ")+"\n"+helpStyle.Render("No plan yet. Ask for one (e.g. \"plan the migration\") and it will appear here."))
+		}
+		if m.showPlan && !m.showTree {
+			m.showTree = true
+			m.updatePerusalContent()
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return m, func() tea.Msg { return tea.WindowSizeMsg{Width: m.width, Height: m.height} }
+		}
+		m.updatePerusalContent()
+	case "/clear":
+		m.brain.SetPlan(nil)
+		m.hasPlan = false
+		m.planCursor = 0
+		m.updatePerusalContent()
+		m.saveState()
+		m.messages = append(m.messages, systemStyle.Render(" PLAN ")+" "+helpStyle.Render("Plan cleared"))
+	default:
+		m.messages = append(m.messages, systemStyle.Render(" PLAN ")+"\n"+helpStyle.Render("Usage: /plan /show · /plan /clear"))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// handlePlanKey moves through the plan panel; space or x toggles a step.
+func (m *model) handlePlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	plan := m.brain.Plan()
+	if plan == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "up", "k":
+		if m.planCursor > 0 {
+			m.planCursor--
+		}
+	case "down", "j":
+		if m.planCursor < len(plan.Steps)-1 {
+			m.planCursor++
+		}
+	case " ", "x":
+		m.brain.TogglePlanStep(m.planCursor)
+		m.saveState()
+	}
+	m.updatePerusalContent()
+	return m, nil
+}
+
+// refreshPlan redraws the plan panel after the brain may have updated it and
+// opens the panel the first time a plan appears.
+func (m *model) refreshPlan() {
+	plan := m.brain.Plan()
+	if plan == nil {
+		m.hasPlan = false
+		return
+	}
+	if !m.hasPlan {
+		m.hasPlan = true
+		m.planCursor = 0
+		m.showPlan = true
+	}
+	if m.planCursor >= len(plan.Steps) {
+		m.planCursor = len(plan.Steps) - 1
+	}
+	m.updatePerusalContent()
+}
+
+func (m *model) renderPlan() string {
+	var sb strings.Builder
+	plan := m.brain.Plan()
+	if plan == nil {
+		sb.WriteString(systemStyle.Render(" PLAN ") + "\n\n")
+		sb.WriteString(subtleStyle.Render("No active plan."))
+		return sb.String()
+	}
+
+	done := 0
+	for _, st := range plan.Steps {
+		if st.Done {
+			done++
+		}
+	}
+	title := plan.Title
+	if title == "" {
+		title = "PLAN"
+	}
+	sb.WriteString(systemStyle.Render(fmt.Sprintf(" %s (%d/%d) ", title, done, len(plan.Steps))) + "\n\n")
+
+	current := plan.Current()
+	width := m.perusalVp.Width
+	for i, st := range plan.Steps {
+		box := "[ ]"
+		if st.Done {
+			box = "[x]"
+		} else if i == current {
+			box = "[>]"
+		}
+		line := fmt.Sprintf("%s %d. %s", box, i+1, st.Text)
+		if width > 4 {
+			line = lipgloss.NewStyle().Width(width - 2).Render(line)
+		}
+		switch {
+		case i == m.planCursor:
+			sb.WriteString(suggestionStyle.Render(line) + "\n")
+		case st.Done:
+			sb.WriteString(subtleStyle.Render(line) + "\n")
+		case i == current:
+			sb.WriteString(aiStyle.Render(line) + "\n")
+		default:
+			sb.WriteString(line + "\n")
+		}
+	}
+	sb.WriteString("\n" + subtleStyle.Render("↑/↓ move · space toggle · /plan /show to hide"))
+	return sb.String()
+}
+
+func (m *model) handleDebugCommand(parts []string) (tea.Model, tea.Cmd) {
+	if len(parts) < 2 || parts[1] != "/failures" {
+		m.messages = append(m.messages, systemStyle.Render(" DEBUG ")+"\n"+helpStyle.Render("Usage: /debug /failures [/clear]"))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+	if len(parts) > 2 && parts[2] == "/clear" {
+		m.brain.ClearFailures()
+		m.messages = append(m.messages, subtleStyle.Render("Failure ledger cleared; blocked calls may run again."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+
+	failures := m.brain.Failures()
+	var body string
+	if len(failures) == 0 {
+		body = "No failing calls this session."
+	} else {
+		var lines []string
+		for _, f := range failures {
+			lines = append(lines, fmt.Sprintf("✗ %s ×%d [%s] %s\n  %s", f.Tool, f.Count, f.Class, f.Last.Format("15:04:05"), f.Error))
+		}
+		body = strings.Join(lines, "\n")
+	}
+	m.messages = append(m.messages, systemStyle.Render(" FAILURE LEDGER ")+"\n"+helpStyle.Render(body))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+func (m *model) handleContextCommand(parts []string) (tea.Model, tea.Cmd) {
+	if len(parts) > 1 && parts[1] != "/show" {
+		m.messages = append(m.messages, systemStyle.Render(" CONTEXT ")+"\n"+helpStyle.Render("Usage: /context /show"))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+
+	stats := m.brain.ContextStats(m.brain.Session())
+	body := fmt.Sprintf("Session: %s\nMessages: %d\nEstimated tokens: ~%d of %d", m.brain.Session(), stats.Messages, stats.Tokens, stats.Budget)
+	if stats.Messages == 0 {
+		body += "\nThe next message starts a new conversation."
+	}
+	m.messages = append(m.messages, systemStyle.Render(" CONTEXT ")+"\n"+helpStyle.Render(body))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// undoListLimit is how many writes /undo lists.
+const undoListLimit = 20
+
+// handleUndoCommand lists the agent's recent file writes, numbered newest
+// first, or reverts the one given by number or undo id.
+func (m *model) handleUndoCommand(parts []string) (tea.Model, tea.Cmd) {
+	var body string
+	entries, err := m.brain.UndoWrites()
+	switch {
+	case err != nil:
+		body = "Could not read the undo journal: " + err.Error()
+	case len(parts) == 1:
+		if len(entries) == 0 {
+			body = "No file writes to undo."
+			break
+		}
+		var lines []string
+		for i, e := range entries {
+			if i == undoListLimit {
+				break
+			}
+			action := "overwrote"
+			if e.Backup == "" {
+				action = "created"
+			}
+			lines = append(lines, fmt.Sprintf("%2d. %s %s", i+1, action, e.Path)+
+				subtleStyle.Render("  "+e.CreatedAt.Local().Format("2006-01-02 15:04:05")))
+		}
+		body = strings.Join(lines, "\n") + "\n\nRestore one with /undo <n>."
+	default:
+		id := parts[1]
+		if n, err := strconv.Atoi(id); err == nil {
+			if n < 1 || n > len(entries) {
+				body = fmt.Sprintf("No write #%d; /undo lists them.", n)
+				break
+			}
+			id = entries[n-1].ID
+		}
+		entry, err := m.brain.UndoWrite(id)
+		if err != nil {
+			body = err.Error()
+			break
+		}
+		body = "Restored " + entry.Path + "."
+		if entry.Backup == "" {
+			body = "Removed " + entry.Path + ", which the write had created."
+		}
+		if m.isFileOpen && m.currentPath == entry.Path {
+			if entry.Backup == "" {
+				m.loadTree(filepath.Dir(entry.Path))
+			} else {
+				m.openFile(entry.Path)
+			}
+		}
+	}
+
+	m.messages = append(m.messages, systemStyle.Render(" UNDO ")+"\n"+helpStyle.Render(body))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// SearchResult is one message matched by /search.
+type SearchResult struct {
+	SessionName  string
+	MessageIndex int       // Index into the session's saved messages, or its archive batch
+	Snippet      string    // Plain text around the first match
+	UpdatedAt    time.Time // When the session was last saved, or the batch cleared; zero if never
+	Archived     bool      // Found in a batch /clear archived, not the live transcript
+}
+
+// searchState is the /search result list. It is rendered as the message at
+// msgIdx and re-rendered in place as the selection moves.
+type searchState struct {
+	query    string
+	results  []SearchResult
+	selected int
+	msgIdx   int
+}
+
+const (
+	searchResultLimit = 50
+	snippetRadius     = 40 // Characters of context either side of a match
+)
+
+var (
+	searchHeader     = systemStyle.Render(" SEARCH ")
+	searchMatchStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// searchHistory scans every saved session for messages containing query,
+// ignoring case, and with includeArchived the batches /clear archived. The
+// active session is searched as shown, including messages not yet saved.
+// Earlier result lists are skipped so a search does not find itself.
+func (m *model) searchHistory(query string, includeArchived bool) []SearchResult {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return nil
+	}
+	sessions, err := m.brain.ListSessions()
+	if err != nil {
+		return nil
+	}
+
+	var results []SearchResult
+	scan := func(base SearchResult, messages []string) bool {
+		for i, msg := range messages {
+			if isBannerMessage(msg) || strings.HasPrefix(msg, searchHeader) {
+				continue
+			}
+			text := strings.Join(strings.Fields(ansi.Strip(msg)), " ")
+			pos := strings.Index(strings.ToLower(text), needle)
+			if pos < 0 {
+				continue
+			}
+			r := base
+			r.MessageIndex, r.Snippet = i, searchSnippet(text, pos, len(needle))
+			results = append(results, r)
+			if len(results) == searchResultLimit {
+				return false
+			}
+		}
+		return true
+	}
+	for _, s := range sessions {
+		messages := m.messages
+		if !s.Active {
+			var state chatState
+			if err := m.brain.LoadSession(s.Name, &state); err != nil {
+				continue
+			}
+			messages = state.Messages
+		}
+		if !scan(SearchResult{SessionName: s.Name, UpdatedAt: s.UpdatedAt}, messages) {
+			return results
+		}
+	}
+	if !includeArchived {
+		return results
+	}
+	for _, s := range sessions {
+		archives, err := m.brain.SessionArchives(s.Name)
+		if err != nil {
+			continue
+		}
+		for _, a := range archives {
+			if !scan(SearchResult{SessionName: s.Name, UpdatedAt: a.CreatedAt, Archived: true}, a.Messages) {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// searchSnippet cuts the text around a match at pos, marking elisions.
+func searchSnippet(text string, pos, n int) string {
+	start := max(pos-snippetRadius, 0)
+	end := min(pos+n+snippetRadius, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	snippet := text[start:end]
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// highlightMatches renders every occurrence of query in text bold.
+func highlightMatches(text, query string) string {
+	lower, needle := strings.ToLower(text), strings.ToLower(query)
+	if needle == "" || len(lower) != len(text) {
+		return text
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(lower, needle)
+		if i < 0 {
+			sb.WriteString(text)
+			return sb.String()
+		}
+		sb.WriteString(text[:i])
+		sb.WriteString(searchMatchStyle.Render(text[i : i+len(needle)]))
+		text, lower = text[i+len(needle):], lower[i+len(needle):]
+	}
+}
+
+func (m *model) handleSearchCommand(parts []string) (tea.Model, tea.Cmd) {
+	m.closeSearch()
+	args, includeArchived := takeFlag(parts[1:], "--include-archived")
+	if len(args) == 0 {
+		m.messages = append(m.messages, searchHeader+"\n"+helpStyle.Render("Usage: /search [--include-archived] <query>"))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+
+	query := strings.Join(args, " ")
+	m.search = &searchState{query: query, results: m.searchHistory(query, includeArchived), msgIdx: len(m.messages)}
+	m.messages = append(m.messages, renderSearchResults(m.search, true))
+	if len(m.search.results) == 0 {
+		m.search = nil
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// renderSearchResults draws the result list, with the selection marker and
+// key hints while it is open.
+func renderSearchResults(s *searchState, open bool) string {
+	if len(s.results) == 0 {
+		return searchHeader + "\n" + helpStyle.Render("No messages match "+strconv.Quote(s.query)+".")
+	}
+	var lines []string
+	for i, r := range s.results {
+		cursor := "  "
+		if open && i == s.selected {
+			cursor = "> "
+		}
+		line := cursor + r.SessionName
+		if r.Archived {
+			line += subtleStyle.Render(" (archived)")
+		}
+		if !r.UpdatedAt.IsZero() {
+			line += subtleStyle.Render("  " + r.UpdatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		lines = append(lines, line+"\n    "+highlightMatches(r.Snippet, s.query))
+	}
+	out := searchHeader + "\n" + strings.Join(lines, "\n")
+	if open {
+		out += "\n" + subtleStyle.Render("↑/↓ select · enter open · esc close")
+	}
+	return out
+}
+
+// handleSearchKey moves through an open result list while the input is
+// empty. Any other key closes the list and is handled as usual.
+func (m *model) handleSearchKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.textarea.Value() == "" {
+		n := len(m.search.results)
+		switch msg.String() {
+		case "up":
+			m.search.selected = (m.search.selected - 1 + n) % n
+			m.updateSearchDisplay()
+			return true, nil
+		case "down":
+			m.search.selected = (m.search.selected + 1) % n
+			m.updateSearchDisplay()
+			return true, nil
+		case "enter":
+			m.openSearchResult()
+			return true, nil
+		}
+	}
+	m.closeSearch()
+	return false, nil
+}
+
+// updateSearchDisplay re-renders the open result list in place.
+func (m *model) updateSearchDisplay() {
+	if m.search.msgIdx < len(m.messages) && strings.HasPrefix(m.messages[m.search.msgIdx], searchHeader) {
+		m.messages[m.search.msgIdx] = renderSearchResults(m.search, true)
+		m.viewport.SetContent(m.renderMessages())
+	}
+}
+
+// closeSearch leaves the result list in the transcript without its
+// selection marker and key hints.
+func (m *model) closeSearch() {
+	s := m.search
+	if s == nil {
+		return
+	}
+	m.search = nil
+	if s.msgIdx < len(m.messages) && strings.HasPrefix(m.messages[s.msgIdx], searchHeader) {
+		m.messages[s.msgIdx] = renderSearchResults(s, false)
+		m.viewport.SetContent(m.renderMessages())
+	}
+}
+
+// openSearchResult switches to the selected result's session and scrolls
+// to the matching message. An archived message is not in the transcript,
+// so it is shown in full instead.
+func (m *model) openSearchResult() {
+	r := m.search.results[m.search.selected]
+	m.closeSearch()
+	if r.Archived {
+		m.showArchivedResult(r)
+		return
+	}
+	if r.SessionName != m.currentSession {
+		if err := m.switchSession(r.SessionName, false); err != nil {
+			m.messages = append(m.messages, searchHeader+"\n"+helpStyle.Render(err.Error()))
+			m.viewport.SetContent(m.renderMessages())
+			m.viewport.GotoBottom()
+			return
+		}
+	}
+	m.scrollToMessage(r.MessageIndex)
+}
+
+// showArchivedResult prints an archived message found by /search.
+func (m *model) showArchivedResult(r SearchResult) {
+	body := helpStyle.Render(fmt.Sprintf("Archived from %s, cleared %s:", r.SessionName, r.UpdatedAt.Local().Format("2006-01-02 15:04")))
+	if archives, err := m.brain.SessionArchives(r.SessionName); err == nil {
+		for _, a := range archives {
+			if a.CreatedAt.Equal(r.UpdatedAt) && r.MessageIndex < len(a.Messages) {
+				body += "\n" + a.Messages[r.MessageIndex]
+				break
+			}
+		}
+	}
+	m.messages = append(m.messages, searchHeader+"\n"+body)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// scrollToMessage scrolls the chat so message idx is at the top.
+func (m *model) scrollToMessage(idx int) {
+	if idx <= 0 || idx >= len(m.messages) {
+		return
+	}
+	all, log := m.messages, m.thinkingLog
+	m.messages, m.thinkingLog = all[:idx], nil
+	offset := lipgloss.Height(m.renderMessages()) + 1 // Plus the blank line between messages
+	m.messages, m.thinkingLog = all, log
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.SetYOffset(offset)
+}
+
+// switchSession saves the current transcript and loads name's, or starts
+// name as a blank session when fresh is set.
+func (m *model) switchSession(name string, fresh bool) error {
+	if err := brain.ValidateSessionName(name); err != nil {
+		return err
+	}
+	if m.isThinking {
+		return fmt.Errorf("wait for the current request to finish (or Ctrl+C) before switching")
+	}
+	exists, err := m.brain.SessionExists(name)
+	if err != nil {
+		return err
+	}
+	if fresh && exists {
+		return fmt.Errorf("session %q already exists; use /session /switch %s", name, name)
+	}
+
+	m.saveState()
+	if err := m.brain.SetSession(name); err != nil {
+		return err
+	}
+	m.currentSession = name
+	m.hasArchived = false
+	m.loadSession()
+	m.refreshPlan()
+
+	note := "Switched to session " + name + "."
+	if !exists {
+		note = "Started session " + name + "."
+	}
+	m.messages = append(m.messages, subtleStyle.Render(note))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveState()
+	return nil
+}
+
+func (m *model) handleSessionCommand(parts []string) (tea.Model, tea.Cmd) {
+	usage := "Usage: /session /list · /session /new <name> · /session /switch <name> · /session /delete <name>"
+	sub := ""
+	if len(parts) > 1 {
+		sub = "/" + strings.TrimPrefix(parts[1], "/")
+	}
+
+	var body string
+	switch {
+	case sub == "" || sub == "/list":
+		sessions, err := m.brain.ListSessions()
+		if err != nil {
+			body = "Could not list sessions: " + err.Error()
+			break
+		}
+		var lines []string
+		for _, s := range sessions {
+			marker := "  "
+			if s.Active {
+				marker = "● "
+			}
+			line := marker + s.Name
+			if !s.UpdatedAt.IsZero() {
+				line += subtleStyle.Render(fmt.Sprintf("  %s · %d KB", s.UpdatedAt.Local().Format("2006-01-02 15:04"), (s.Size+1023)/1024))
+			}
+			lines = append(lines, line)
+		}
+		body = strings.Join(lines, "\n")
+	case (sub == "/switch" || sub == "/new") && len(parts) == 3:
+		name := parts[2]
+		if name == m.currentSession {
+			body = "Already in session " + name + "."
+			break
+		}
+		if err := m.switchSession(name, sub == "/new"); err != nil {
+			body = err.Error()
+			break
+		}
+		return m, nil
+	case sub == "/delete" && len(parts) == 3:
+		if err := m.brain.DeleteSession(parts[2]); err != nil {
+			body = err.Error()
+		} else {
+			body = "Deleted session " + parts[2] + "."
+		}
+	default:
+		body = usage
+	}
+
+	m.messages = append(m.messages, systemStyle.Render(" SESSIONS ")+"\n"+helpStyle.Render(body))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+func (m *model) handleClearCommand(parts []string) (tea.Model, tea.Cmd) {
+	// The stream writes into m.messages by index, so the transcript must
+	// stay put until the request is done.
+	if m.isThinking {
+		m.messages = append(m.messages, subtleStyle.Render("Wait for the current request to finish (or Esc) before clearing."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+	}
+	if len(parts) > 1 {
+		switch parts[1] {
+		case "--force", "-f":
+			m.clearMessages()
+			return m, nil
+		case "/unarchive", "unarchive":
+			m.unarchiveMessages()
+			return m, nil
+		}
+	}
+
+	if m.clearableCount() == 0 {
+		m.messages = append(m.messages, subtleStyle.Render("Nothing to clear."))
+	} else {
+		m.pendingClear = true
+		m.messages = append(m.messages, systemStyle.Render(" CLEAR ")+"\n"+helpStyle.Render(fmt.Sprintf("Clear %d messages? They will be archived. [y/N]", m.clearableCount())))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// confirmClear resolves the inline y/N prompt raised by /clear.
+func (m *model) confirmClear(answer string) (tea.Model, tea.Cmd) {
+	m.pendingClear = false
+	m.textarea.Reset()
+	// Drop the prompt itself so it isn't archived with the conversation.
+	if n := len(m.messages); n > 0 {
+		m.messages = m.messages[:n-1]
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		m.clearMessages()
+	default:
+		m.messages = append(m.messages, subtleStyle.Render("Clear cancelled."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+	}
+	return m, nil
+}
+
+// clearableCount is the number of messages after the header.
+func (m *model) clearableCount() int {
+	return len(m.messages) - m.headerCount()
+}
+
+// headerCount is the number of leading messages that aren't conversation:
+// the banner and hint, plus the notice left by the last /clear.
+func (m *model) headerCount() int {
+	if m.hasArchived {
+		return min(m.clearHeader, len(m.messages))
+	}
+	n := 0
+	if n < len(m.messages) && isBannerMessage(m.messages[n]) {
+		n++
+	}
+	if n < len(m.messages) && m.messages[n] == helpHint() {
+		n++
+	}
+	return n
+}
+
+func helpHint() string {
+	return "Type " + systemStyle.Render("/help") + " to see available commands."
+}
+
+func (m *model) freshMessages() []string {
+	msgs := []string{}
+	ensureBanner(&msgs, m.banner)
+	return append(msgs, helpHint())
+}
+
+// clearMessages archives the transcript before wiping it. The archive is
+// committed first so a failed state write never loses both copies.
+func (m *model) clearMessages() {
+	archived := m.messages[m.headerCount():]
+	count := len(archived)
+	if count == 0 {
+		m.messages = append(m.messages, subtleStyle.Render("Nothing to clear."))
 		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoTop()
-		m.saveState()
-		return m, nil
-	case "/exit":
-		return m, tea.Quit
-	case "/update":
-		m.messages = append(m.messages, systemStyle.Render(" UPDATE ")+"\n"+helpStyle.Render("Checking for latest release..."))
+		m.viewport.GotoBottom()
+		return
+	}
+
+	next := m.freshMessages()
+	if err := m.brain.ArchiveState(m.brain.SessionKey(), archived, chatState{Messages: next}); err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" CLEAR FAILED ")+"\n"+err.Error())
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
-		return m, m.updater.CheckUpdateCmd(true) // Manual
-	case "/restart":
-		m.saveState()
-		restartSelf()
-		return m, tea.Quit // Fallback if restartSelf doesn't exec
-	default:
-		m.messages = append(m.messages, errorStyle.Render(" Unknown Command: ")+parts[0])
+		return
 	}
 
+	m.brain.ResetConversation(m.brain.Session())
+	m.hasArchived = true
+	m.clearHeader = len(next) + 1
+	m.times = nil
+	m.messages = append(next, subtleStyle.Render(fmt.Sprintf("Cleared %d messages (archived). Use /clear /unarchive to restore.", count)))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoTop()
+}
+
+// unarchiveMessages restores the most recent /clear batch in front of
+// anything typed since.
+func (m *model) unarchiveMessages() {
+	if !m.hasArchived {
+		m.messages = append(m.messages, subtleStyle.Render("Nothing was cleared in this session."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return
+	}
+
+	restored, err := m.brain.UnarchiveState(m.brain.SessionKey())
+	if err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" UNARCHIVE FAILED ")+"\n"+err.Error())
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return
+	}
+
+	// Skip the header added by clearMessages. Restored messages lost their
+	// times in the archive.
+	m.stampMessages()
+	header := m.headerCount()
+	since := m.messages[header:]
+	sinceTimes := m.times[header:]
+	m.hasArchived = false
+	m.clearHeader = 0
+	head := []string{}
+	ensureBanner(&head, m.banner)
+	m.times = append(make([]time.Time, len(head)+len(restored)), sinceTimes...)
+	m.messages = append(head, restored...)
+	m.messages = append(m.messages, since...)
+	m.messages = append(m.messages, subtleStyle.Render(fmt.Sprintf("Restored %d archived messages.", len(restored))))
 	m.viewport.SetContent(m.renderMessages())
 	m.viewport.GotoBottom()
-	return m, nil
+	m.saveState()
 }
 
 func (m *model) handleAuthCommand(parts []string) (tea.Model, tea.Cmd) {
 	if len(parts) < 2 {
-		m.messages = append(m.messages, systemStyle.Render(" AUTH ")+"\n"+helpStyle.Render("Manage your AI provider credentials.\n\nUsage: /auth <provider> [key/endpoint]\nProviders: /ollama, /github-models, /github-copilot, /openai, /anthropic"))
+		m.messages = append(m.messages, systemStyle.Render(" AUTH ")+"\n"+helpStyle.Render("Manage your AI provider credentials.\n\nUsage: /auth <provider> [key/endpoint]\nProviders: /ollama, /github-models, /github-copilot, /openai, /azure-openai, /anthropic, /gemini"))
 		return m, nil
 	}
 
@@ -1306,8 +2863,10 @@ func (m *model) handleAuthCommand(parts []string) (tea.Model, tea.Cmd) {
 	case "/ollama", "ollama":
 		if len(parts) > 2 {
 			endpoint := parts[2]
-			cfg := m.brain.Config()
-			cfg.Model.Endpoint = endpoint
+			cfg := m.brain.Config().Clone()
+			pc := cfg.Providers["ollama"]
+			pc.Endpoint = endpoint
+			cfg.SetProvider("ollama", pc)
 			if err := m.brain.UpdateConfig(cfg); err != nil {
 				m.messages = append(m.messages, errorStyle.Render(" CONFIG ERROR ")+"\n"+err.Error())
 			} else {
@@ -1329,7 +2888,28 @@ func (m *model) handleAuthCommand(parts []string) (tea.Model, tea.Cmd) {
 		}
 	case "/github-copilot", "github-copilot":
 		m.messages = append(m.messages, systemStyle.Render(" GITHUB COPILOT ")+"\n"+errorStyle.Render(" Not yet integrated "))
-	case "/openai", "openai", "/anthropic", "anthropic":
+	case "/azure-openai", "azure-openai":
+		if len(parts) < 4 {
+			m.messages = append(m.messages, systemStyle.Render(" AZURE OPENAI ")+"\n"+helpStyle.Render("Usage: /auth /azure-openai <api-key> <endpoint> [deployment]\nThe endpoint looks like https://my-resource.openai.azure.com; use deployment names as models."))
+			break
+		}
+		if err := m.brain.StoreSecret("azure_openai_api_key", parts[2]); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" VAULT ERROR ")+"\n"+err.Error())
+			break
+		}
+		cfg := m.brain.Config().Clone()
+		pc := cfg.Providers["azure-openai"]
+		pc.Endpoint = parts[3]
+		if len(parts) > 4 && !slices.Contains(pc.Deployments, parts[4]) {
+			pc.Deployments = append(pc.Deployments, parts[4])
+		}
+		cfg.SetProvider("azure-openai", pc)
+		if err := m.brain.UpdateConfig(cfg); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" CONFIG ERROR ")+"\n"+err.Error())
+			break
+		}
+		m.messages = append(m.messages, systemStyle.Render(" AZURE OPENAI ")+"\n"+helpStyle.Render("API key stored securely. Endpoint set to: "+parts[3]))
+	case "/openai", "openai", "/anthropic", "anthropic", "/gemini", "gemini":
 		if len(parts) > 2 {
 			providerName := strings.TrimPrefix(provider, "/")
 			err := m.brain.StoreSecret(providerName+"_api_key", parts[2])
@@ -1342,8 +2922,10 @@ func (m *model) handleAuthCommand(parts []string) (tea.Model, tea.Cmd) {
 			// Optional: set custom endpoint if provided as 3rd arg
 			if len(parts) > 3 {
 				endpoint := parts[3]
-				cfg := m.brain.Config()
-				cfg.Model.Endpoint = endpoint
+				cfg := m.brain.Config().Clone()
+				pc := cfg.Providers[providerName]
+				pc.BaseURL = endpoint
+				cfg.SetProvider(providerName, pc)
 				if err := m.brain.UpdateConfig(cfg); err == nil {
 					m.messages = append(m.messages, helpStyle.Render("Endpoint set to: "+endpoint))
 				}
@@ -1408,6 +2990,12 @@ func (m *model) handleModelsCommand(parts []string) (tea.Model, tea.Cmd) {
 			return m, m.pullOllamaModel(modelName)
 		}
 		m.messages = append(m.messages, systemStyle.Render(" MODELS ")+"\n"+helpStyle.Render("Usage: /models /pull <model_name>")+"\n"+subtleStyle.Render("Example: /models /pull llama3.2"))
+	} else if sub == "/usage" || sub == "usage" {
+		since := ""
+		if len(parts) >= 3 {
+			since = parts[2]
+		}
+		m.messages = append(m.messages, m.renderModelUsage(since))
 	} else {
 		m.messages = append(m.messages, errorStyle.Render(" Unknown MODELS subcommand: ")+sub)
 	}
@@ -1417,6 +3005,34 @@ func (m *model) handleModelsCommand(parts []string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// renderModelUsage formats /models /usage [since], the table of
+// `vibeaura models usage`.
+func (m *model) renderModelUsage(since string) string {
+	var from time.Time
+	title := " MODEL USAGE "
+	if since != "" {
+		age, err := parseAge(since)
+		if err != nil {
+			return errorStyle.Render(" USAGE ") + "\n" + helpStyle.Render("Usage: /models /usage [since], e.g. /models /usage 7d")
+		}
+		from = time.Now().Add(-age)
+		title = " MODEL USAGE (last " + since + ") "
+	}
+	store, err := m.brain.UsageStore()
+	if err != nil {
+		return errorStyle.Render(" USAGE ") + "\n" + err.Error()
+	}
+	list, err := store.Summary(from)
+	if err != nil {
+		return errorStyle.Render(" USAGE ") + "\n" + err.Error()
+	}
+	if len(list) == 0 {
+		return systemStyle.Render(title) + "\n" + helpStyle.Render("No model requests recorded yet.")
+	}
+	lines := usageTable(list)
+	return systemStyle.Render(title) + "\n" + subtleStyle.Render(lines[0]) + "\n" + helpStyle.Render(strings.Join(lines[1:], "\n"))
+}
+
 func (m *model) handleMcpCommand(parts []string) (tea.Model, tea.Cmd) {
 	if len(parts) < 2 {
 		m.messages = append(m.messages, systemStyle.Render(" MCP ")+"\n"+helpStyle.Render("Manage Model Context Protocol servers.\n\nUsage: /mcp <subcommand>\nSubcommands: /list, /add, /logs, /call"))
@@ -1426,9 +3042,26 @@ func (m *model) handleMcpCommand(parts []string) (tea.Model, tea.Cmd) {
 	sub := strings.ToLower(parts[1])
 	switch sub {
 	case "/list", "list":
-		m.messages = append(m.messages, systemStyle.Render(" MCP SERVERS ")+"\n"+helpStyle.Render("• github (stdio) - tools: github_query\n• postgres (stdio) - tools: postgres_exec"))
+		m.messages = append(m.messages, systemStyle.Render(" MCP SERVERS ")+"\n"+subtleStyle.Render("Asking servers for their tools..."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, func() tea.Msg {
+			return brain.Response{Content: renderMCPServers(m.brain.MCPServers(context.Background()))}
+		}
 	case "/add", "add":
-		m.messages = append(m.messages, systemStyle.Render(" MCP ")+"\n"+helpStyle.Render("Usage: /mcp /add <name> <command> [args...]"))
+		// Without a command, ask for the rest step by step.
+		if len(parts) < 4 {
+			name := ""
+			if len(parts) == 3 {
+				name = parts[2]
+			}
+			return m.startMCPWizard(name)
+		}
+		srv := sys.MCPServer{Name: parts[2], Command: parts[3], Args: parts[4:]}
+		m.messages = append(m.messages, systemStyle.Render(" MCP ")+"\n"+subtleStyle.Render("Starting "+srv.Name+"..."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, m.addMCPServer(srv)
 	case "/logs", "logs":
 		m.messages = append(m.messages, systemStyle.Render(" MCP LOGS ")+"\n"+subtleStyle.Render("Waiting for MCP traffic..."))
 	case "/call", "call":
@@ -1442,9 +3075,39 @@ func (m *model) handleMcpCommand(parts []string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// renderMCPServers formats the /mcp /list result.
+func renderMCPServers(servers []brain.MCPServerStatus) string {
+	var sb strings.Builder
+	sb.WriteString(systemStyle.Render(" MCP SERVERS ") + "\n")
+	if len(servers) == 0 {
+		sb.WriteString(helpStyle.Render("No MCP servers configured. Add one with /mcp /add."))
+		return sb.String()
+	}
+	for _, s := range servers {
+		cmdline := strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+		restarts := ""
+		if s.Restarts > 0 {
+			restarts = fmt.Sprintf(" · restarted %d×", s.Restarts)
+		}
+		if s.Err != nil || !s.Healthy {
+			reason := "not answering health checks"
+			if s.Err != nil {
+				reason = s.Err.Error()
+			}
+			sb.WriteString(fmt.Sprintf("%s %s\n  %s\n", errorStyle.Render("✗ "+s.Name+" · down"+restarts), subtleStyle.Render("("+cmdline+")"), reason))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s %s\n", aiStyle.Render(fmt.Sprintf("• %s · healthy · %d tools%s", s.Name, len(s.Tools), restarts)), subtleStyle.Render("("+cmdline+")")))
+		if len(s.Tools) > 0 {
+			sb.WriteString(helpStyle.Render("  "+strings.Join(s.Tools, ", ")) + "\n")
+		}
+	}
+	return sb.String()
+}
+
 func (m *model) handleSysCommand(parts []string) (tea.Model, tea.Cmd) {
 	if len(parts) < 2 {
-		m.messages = append(m.messages, systemStyle.Render(" SYS ")+"\n"+helpStyle.Render("System and hardware intimacy controls.\n\nUsage: /sys <subcommand>\nSubcommands: /stats, /env, /update, /logs"))
+		m.messages = append(m.messages, systemStyle.Render(" SYS ")+"\n"+helpStyle.Render("System and hardware intimacy controls.\n\nUsage: /sys <subcommand>\nSubcommands: /stats, /env, /update, /logs, /audit, /approvals, /doctor\n\n/audit toggles a live audit pane; /audit /last [--tool X] [--decision denied] [--since 24h] lists the last 20 matches here."))
 		return m, nil
 	}
 
@@ -1452,18 +3115,43 @@ func (m *model) handleSysCommand(parts []string) (tea.Model, tea.Cmd) {
 	switch sub {
 	case "/stats", "stats":
 		snapshot, _ := m.brain.GetSnapshot()
-		stats := fmt.Sprintf(systemStyle.Render(" POWER SNAPSHOT ")+"\n"+
-			helpStyle.Render("OS: %s | Arch: %s\nCPU: %.1f%% | Mem: %.1f%%\nGoroutines: %d"),
-			runtime.GOOS, runtime.GOARCH, snapshot.CPUUsage, snapshot.MemoryUsage, runtime.NumGoroutine())
-		m.messages = append(m.messages, stats)
+		lines := []string{
+			fmt.Sprintf("OS: %s | Arch: %s", runtime.GOOS, runtime.GOARCH),
+			fmt.Sprintf("CPU: %.1f%% | Mem: %.1f%%", snapshot.CPUUsage, snapshot.MemoryUsage),
+		}
+		if snapshot.Disk != nil {
+			lines = append(lines, "Disk: "+snapshot.Disk.String())
+		}
+		if snapshot.Load != nil {
+			lines = append(lines, "Load: "+snapshot.Load.String())
+		}
+		if snapshot.Battery != nil {
+			lines = append(lines, "Battery: "+snapshot.Battery.String())
+		}
+		if snapshot.Network != nil {
+			lines = append(lines, "Network: "+snapshot.Network.String())
+		}
+		lines = append(lines, fmt.Sprintf("Goroutines: %d", runtime.NumGoroutine()))
+		m.messages = append(m.messages, systemStyle.Render(" POWER SNAPSHOT ")+"\n"+helpStyle.Render(strings.Join(lines, "\n")))
 	case "/env", "env":
-		m.messages = append(m.messages, systemStyle.Render(" ENVIRONMENT ")+"\n"+helpStyle.Render("Limited view (Filtered for security)\nSHELL: %s\nPATH: %s..."), os.Getenv("SHELL"), os.Getenv("PATH")[:30])
+		m.messages = append(m.messages, m.renderEnv(parts[2:]))
 	case "/update", "update":
 		// This uses the logic from update.go
 		m.messages = append(m.messages, systemStyle.Render(" UPDATE ")+"\n"+helpStyle.Render("Checking for latest release on GitHub..."))
-		// In a real implementation, we would return a Cmd here to run the update check
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, m.updater.CheckUpdateCmd(true)
 	case "/logs", "logs":
 		m.messages = append(m.messages, systemStyle.Render(" SYSTEM LOGS ")+"\n"+subtleStyle.Render("Streaming vibeauracle.log..."))
+	case "/audit", "audit":
+		if len(parts) > 2 && strings.TrimPrefix(parts[2], "/") == "last" {
+			return m.showAuditEntries(parts[3:])
+		}
+		return m.toggleAudit()
+	case "/approvals", "approvals":
+		m.messages = append(m.messages, m.renderApprovals())
+	case "/doctor", "doctor":
+		m.messages = append(m.messages, renderDoctor(doctor.Summarize(doctorCueLimit)))
 	default:
 		m.messages = append(m.messages, errorStyle.Render(" Unknown SYS subcommand: ")+sub)
 	}
@@ -1473,22 +3161,153 @@ func (m *model) handleSysCommand(parts []string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// renderEnv renders the curated environment as a table grouped by category.
+// An argument narrows it like grep; "/probe bin..." checks binaries on PATH.
+func (m *model) renderEnv(args []string) string {
+	header := systemStyle.Render(" ENVIRONMENT ")
+	if len(args) > 0 && (args[0] == "/probe" || args[0] == "probe") {
+		names := args[1:]
+		if len(names) == 0 {
+			names = sys.DefaultProbes
+		}
+		var rows []string
+		for _, name := range names {
+			p := sys.ProbeBinary(context.Background(), name)
+			if p.Found {
+				rows = append(rows, fmt.Sprintf("%-10s %s  %s", p.Name, p.Path, subtleStyle.Render(p.Version)))
+			} else {
+				rows = append(rows, fmt.Sprintf("%-10s %s", p.Name, errorStyle.Render("not found")))
+			}
+		}
+		return header + "\n" + helpStyle.Render(strings.Join(rows, "\n"))
+	}
+
+	filter := strings.Join(args, " ")
+	width := m.viewport.Width - 28
+	if width < 20 {
+		width = 20
+	}
+	report := sys.QueryEnv(filter)
+	var rows []string
+	var last sys.EnvCategory
+	for _, e := range report.Entries {
+		if e.Category != last {
+			rows = append(rows, tagStyle.Render(strings.ToUpper(string(e.Category))))
+			last = e.Category
+		}
+		value := sys.TruncateValue(e.Value, width)
+		if e.Masked {
+			value = subtleStyle.Render(value)
+		}
+		rows = append(rows, fmt.Sprintf("  %-22s %s", e.Name, value))
+	}
+	if len(rows) == 0 && filter != "" {
+		rows = append(rows, "No variables match "+strconv.Quote(filter)+".")
+	} else if len(rows) == 0 {
+		rows = append(rows, "No allowlisted variables set.")
+	}
+	return header + "\n" + helpStyle.Render(strings.Join(rows, "\n")) + "\n" +
+		subtleStyle.Render("Secrets are masked. Usage: /sys /env [filter] | /sys /env /probe [bin...]")
+}
+
 func (m *model) handleSkillCommand(parts []string) (tea.Model, tea.Cmd) {
 	if len(parts) < 2 {
-		m.messages = append(m.messages, systemStyle.Render(" SKILL ")+"\n"+helpStyle.Render("Manage Brain capabilities (Vibes).\n\nUsage: /skill <subcommand>\nSubcommands: /list, /info, /load, /disable"))
+		m.messages = append(m.messages, systemStyle.Render(" SKILL ")+"\n"+helpStyle.Render("Manage Brain capabilities (Vibes).\n\nUsage: /skill <subcommand>\nSubcommands: /list, /info, /load, /enable, /disable, /run, /logs"))
+		return m, nil
+	}
+
+	rt, err := m.vibesRuntime()
+	if err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" Vibes unavailable: ")+err.Error())
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
 		return m, nil
 	}
 
 	sub := strings.ToLower(parts[1])
 	switch sub {
 	case "/list", "list":
-		m.messages = append(m.messages, systemStyle.Render(" ACTIVE SKILLS ")+"\n"+helpStyle.Render("• hello-world (vibe)\n• fs-manager (internal)\n• git-ops (internal)"))
+		m.messages = append(m.messages, renderSkillList(rt))
 	case "/info", "info":
-		m.messages = append(m.messages, systemStyle.Render(" SKILL INFO ")+"\n"+helpStyle.Render("Usage: /skill /info <skill_id>"))
+		if len(parts) < 3 {
+			m.messages = append(m.messages, systemStyle.Render(" SKILL INFO ")+"\n"+helpStyle.Render("Usage: /skill /info <skill_id>"))
+			break
+		}
+		v, ok := rt.Registry.Get(parts[2])
+		if !ok {
+			m.messages = append(m.messages, errorStyle.Render(" Unknown skill: ")+parts[2])
+			break
+		}
+		m.messages = append(m.messages, renderSkillInfo(v))
 	case "/load", "load":
-		m.messages = append(m.messages, systemStyle.Render(" LOAD SKILL ")+"\n"+helpStyle.Render("Usage: /skill /load <path_or_url>"))
-	case "/disable", "disable":
-		m.messages = append(m.messages, systemStyle.Render(" DISABLE SKILL ")+"\n"+helpStyle.Render("Usage: /skill /disable <skill_id>"))
+		if len(parts) < 3 {
+			m.messages = append(m.messages, systemStyle.Render(" LOAD SKILL ")+"\n"+helpStyle.Render("Usage: /skill /load <path_or_url>"))
+			break
+		}
+		src := parts[2]
+		m.messages = append(m.messages, systemStyle.Render(" LOAD SKILL ")+"\n"+subtleStyle.Render("Validating "+src+"..."))
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, func() tea.Msg {
+			vibe, result, err := installVibe(rt, src)
+			var warnings []string
+			if result != nil {
+				warnings = validationLines(result)
+			}
+			if err != nil {
+				if len(warnings) > 0 {
+					err = fmt.Errorf("%w\n%s", err, strings.Join(warnings, "\n"))
+				}
+				return brain.Response{Error: err}
+			}
+			body := fmt.Sprintf("Installed %s %s to %s", vibe.Spec.Name, vibe.Spec.Version, vibe.FilePath)
+			if len(warnings) > 0 {
+				body += "\n" + strings.Join(warnings, "\n")
+			}
+			return brain.Response{Content: systemStyle.Render(" SKILL LOADED ") + "\n" + helpStyle.Render(body)}
+		}
+	case "/enable", "enable", "/disable", "disable":
+		enable := strings.TrimPrefix(sub, "/") == "enable"
+		if len(parts) < 3 {
+			m.messages = append(m.messages, systemStyle.Render(" SKILL ")+"\n"+helpStyle.Render("Usage: /skill "+sub+" <skill_id>"))
+			break
+		}
+		if err := rt.SetEnabled(parts[2], enable); err != nil {
+			m.messages = append(m.messages, errorStyle.Render(" Skill error: ")+err.Error())
+			break
+		}
+		state := "disabled"
+		if enable {
+			state = "enabled"
+		}
+		m.messages = append(m.messages, systemStyle.Render(" SKILL ")+"\n"+helpStyle.Render(parts[2]+" "+state+"."))
+	case "/run", "run":
+		if len(parts) < 4 {
+			m.messages = append(m.messages, systemStyle.Render(" RUN SKILL TOOL ")+"\n"+helpStyle.Render("Usage: /skill /run <skill_id> <tool> [param=value ...]"))
+			break
+		}
+		params := map[string]string{}
+		for _, kv := range parts[4:] {
+			k, v, _ := strings.Cut(kv, "=")
+			params[k] = v
+		}
+		m.isThinking = true
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, runVibeTool(rt, parts[2], parts[3], params)
+	case "/logs", "logs":
+		if len(parts) < 3 {
+			m.messages = append(m.messages, systemStyle.Render(" SKILL LOGS ")+"\n"+helpStyle.Render("Usage: /skill /logs <skill_id>"))
+			break
+		}
+		var lines []string
+		for _, e := range rt.Logger.EntriesForVibe(parts[2], 20) {
+			lines = append(lines, formatVibeLogEntry(e))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No log entries for "+parts[2]+".")
+		}
+		m.messages = append(m.messages, systemStyle.Render(" SKILL LOGS ")+"\n"+helpStyle.Render(strings.Join(lines, "\n")))
 	default:
 		m.messages = append(m.messages, errorStyle.Render(" Unknown SKILL subcommand: ")+sub)
 	}
@@ -1498,8 +3317,106 @@ func (m *model) handleSkillCommand(parts []string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// runVibeTool runs a skill's tool off the UI goroutine. A sensitive
+// permission the user has not granted yet comes back as a vibeApprovalMsg
+// that runs the tool again once answered.
+func runVibeTool(rt *vibes.Runtime, name, tool string, params map[string]string) tea.Cmd {
+	var run tea.Cmd
+	run = func() tea.Msg {
+		out, err := rt.RunTool(name, tool, params)
+		var pe *vibes.PermissionError
+		if errors.As(err, &pe) {
+			return vibeApprovalMsg{ie: vibePermissionIntervention(rt, pe), retry: run}
+		}
+		if err != nil {
+			if out = strings.TrimSpace(out); out != "" {
+				err = fmt.Errorf("%w\n%s", err, out)
+			}
+			return brain.Response{Error: err}
+		}
+		return brain.Response{Content: systemStyle.Render(" SKILL RUN ") + " " + name + " " + tool + "\n" + strings.TrimRight(out, "\n")}
+	}
+	return run
+}
+
+// vibesRuntime opens the vibes runtime on first use and rescans the vibes
+// directory on every call, so files added outside the TUI show up.
+func (m *model) vibesRuntime() (*vibes.Runtime, error) {
+	if m.vibes == nil {
+		rt, err := openVibes(m.brain.Config().DataDir)
+		if err != nil {
+			return nil, err
+		}
+		m.vibes = rt
+		watchTheme(rt)
+		return rt, nil
+	}
+	return m.vibes, m.vibes.Scan()
+}
+
+func renderSkillList(rt *vibes.Runtime) string {
+	var rows []string
+	for _, v := range sortedVibes(rt) {
+		rows = append(rows, fmt.Sprintf("• %s (%s)", v.Spec.Name, vibeMeta(v)))
+		for _, line := range validationLines(vibes.Validate(v)) {
+			rows = append(rows, "    "+line)
+		}
+	}
+	rows = append(rows, vibeFailureLines(rt)...)
+	if len(rows) == 0 {
+		rows = append(rows, "No vibes installed. Load one with /skill /load <path_or_url>.")
+	}
+	return systemStyle.Render(" SKILLS ") + "\n" + helpStyle.Render(strings.Join(rows, "\n"))
+}
+
+func renderSkillInfo(v *vibes.Vibe) string {
+	rows := []string{
+		"Name:    " + v.Spec.Name,
+		"Status:  " + vibeMeta(v),
+		"File:    " + v.FilePath,
+	}
+	if v.Spec.Author != "" {
+		rows = append(rows, "Author:  "+v.Spec.Author)
+	}
+	if v.Spec.Description != "" {
+		rows = append(rows, "", v.Spec.Description)
+	}
+	if lines := validationLines(vibes.Validate(v)); len(lines) > 0 {
+		rows = append(rows, "")
+		rows = append(rows, lines...)
+	}
+	return systemStyle.Render(" SKILL INFO ") + "\n" + helpStyle.Render(strings.Join(rows, "\n"))
+}
+
+// renderContextUsage is the header's indicator of how much of the model's
+// context window the last prompt filled: green below 60%, yellow below 85%,
+// red above. Empty before the first prompt or without a limit.
+func renderContextUsage(u prompt.Usage) string {
+	pct := u.Percent()
+	if pct < 0 || u.Tokens == 0 {
+		return ""
+	}
+	label := fmt.Sprintf("ctx %d%%", pct)
+	switch {
+	case pct < 60:
+		return successStyle.Render(label)
+	case pct < 85:
+		return warningStyle.Render(label)
+	default:
+		return errorStyle.Render(label)
+	}
+}
+
 func (m *model) View() string {
-	header := titleStyle.Render(" vibeauracle ") + " " + helpStyle.Render("v"+Version)
+	header := titleStyle.Render(" vibeauracle ") + " " + helpStyle.Render("v"+Version+" · "+m.currentSession)
+	if usage := renderContextUsage(m.contextUsage); usage != "" {
+		header += " " + usage
+	}
+	if !m.brain.Config().UI.Plain {
+		if badge := renderNotificationBadge(); badge != "" {
+			header += " " + badge
+		}
+	}
 	borderWidth := m.width
 	if borderWidth > 20 {
 		borderWidth--
@@ -1519,7 +3436,14 @@ func (m *model) View() string {
 		if m.focus == focusEdit {
 			perusalContent = activeBorder.Width(m.perusalVp.Width).Render(m.editArea.View())
 		} else if m.focus == focusPerusal {
-			perusalContent = activeBorder.Width(m.perusalVp.Width).Render(m.perusalVp.View())
+			pv := m.perusalVp.View()
+			if m.explorer != nil {
+				// The prompt takes the pane's last line.
+				lines := strings.Split(pv, "\n")
+				lines[len(lines)-1] = ansi.Truncate(m.explorer.view(), m.perusalVp.Width, "…")
+				pv = strings.Join(lines, "\n")
+			}
+			perusalContent = activeBorder.Width(m.perusalVp.Width).Render(pv)
 		} else {
 			perusalContent = inactiveBorder.Width(m.perusalVp.Width).Render(m.perusalVp.View())
 		}
@@ -1530,12 +3454,16 @@ func (m *model) View() string {
 		)
 	}
 
+	if m.showNotifications {
+		mainContent = renderNotificationOverlay(m.width)
+	}
+
 	view := fmt.Sprintf(
 		"%s\n%s\n%s\n%s\n%s",
 		header,
-		lipgloss.NewStyle().Foreground(lipgloss.Color("#444444")).Render(border),
+		ruleStyle.Render(border),
 		mainContent,
-		lipgloss.NewStyle().Foreground(lipgloss.Color("#444444")).Render(border),
+		ruleStyle.Render(border),
 		m.textarea.View(),
 	)
 
@@ -1567,17 +3495,23 @@ func (m *model) renderSuggestions() string {
 	var rows []string
 
 	// Header/Filter input for model selector
-	if m.isFilteringModels {
+	if m.filterMode != filterNone {
 		filterHeader := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
+			Foreground(highlight).
 			Bold(true).
 			Padding(0, 1).
 			Render("🔍 Filter: " + m.suggestionFilter + "█")
 		rows = append(rows, filterHeader)
-		rows = append(rows, lipgloss.NewStyle().Foreground(lipgloss.Color("#444444")).Render(strings.Repeat("─", width)))
+		rows = append(rows, ruleStyle.Render(strings.Repeat("─", width)))
 
-		if len(m.allModelDiscoveries) == 0 {
+		if m.filterMode == filterModelPull {
+			if len(m.pullCandidates) == 0 {
+				rows = append(rows, subtleStyle.Width(width).Render("  Loading the Ollama library..."))
+			}
+		} else if len(m.allModelDiscoveries) == 0 {
 			rows = append(rows, subtleStyle.Width(width).Render("  Discovering models..."))
+		} else if m.modelsCached {
+			rows = append(rows, subtleStyle.Width(width).Render("  (cached) Refreshing models..."))
 		}
 	}
 
@@ -1592,7 +3526,15 @@ func (m *model) renderSuggestions() string {
 		name := filepath.Base(s)
 		dir := filepath.Dir(s)
 
-		if strings.Contains(s, "|") && m.isFilteringModels {
+		if m.filterMode == filterModelPull {
+			// Right column: size hints, or the install state
+			name = strings.TrimPrefix(s, "pull|")
+			c, _ := m.pullCandidate(name)
+			dir = strings.Trim(c.Params+" · "+c.Size, " ·")
+			if c.Installed {
+				dir = "✓ installed"
+			}
+		} else if strings.Contains(s, "|") && m.filterMode == filterModelUse {
 			parts := strings.Split(s, "|")
 			provider := parts[0]
 			modelName := parts[1]
@@ -1650,6 +3592,34 @@ func (m *model) discoverModels() tea.Cmd {
 	}
 }
 
+// refreshModels rediscovers models in the background at startup. Failures
+// are quiet: the selector keeps showing the cached list.
+func (m *model) refreshModels() tea.Cmd {
+	return func() tea.Msg {
+		discoveries, err := m.brain.DiscoverModels(context.Background())
+		if err != nil {
+			return nil
+		}
+		return discoveries
+	}
+}
+
+// loadPullCandidates fetches the /models /pull list with install state.
+func (m *model) loadPullCandidates() tea.Cmd {
+	return func() tea.Msg {
+		return pullCandidatesMsg(m.brain.PullCandidates(context.Background()))
+	}
+}
+
+func (m *model) pullCandidate(name string) (brain.PullCandidate, bool) {
+	for _, c := range m.pullCandidates {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return brain.PullCandidate{}, false
+}
+
 func (m *model) pullOllamaModel(name string) tea.Cmd {
 	return func() tea.Msg {
 		err := m.brain.PullModel(context.Background(), name)
@@ -1662,12 +3632,6 @@ func (m *model) pullOllamaModel(name string) tea.Cmd {
 
 // --- Intervention / Action Confirmation UI ---
 
-// interventionResultMsg is sent after the user makes a choice in an intervention.
-type interventionResultMsg struct {
-	result interface{}
-	err    error
-}
-
 // handleInterventionKey handles arrow key navigation and selection for the intervention UI.
 func (m *model) handleInterventionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.pendingIntervention == nil {
@@ -1695,13 +3659,21 @@ func (m *model) handleInterventionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		// User confirmed their choice
 		choice := m.pendingIntervention.choices[m.pendingIntervention.selected]
-		resumeFn := m.pendingIntervention.resume
+		reqID := m.pendingIntervention.requestID
+		pending := m.pendingIntervention
 		m.pendingIntervention = nil
 
 		// Remove the intervention UI from messages
 		if len(m.messages) > 0 {
 			m.messages = m.messages[:len(m.messages)-1]
 		}
+		if pending.resume != nil {
+			return m, m.answerVibeApproval(pending, choice)
+		}
+		if choice == tooling.ChoiceDeny {
+			m.denyIntervention()
+			return m, nil
+		}
 
 		// Show what the user chose
 		m.messages = append(m.messages, subtleStyle.Render("→ "+choice))
@@ -1710,17 +3682,19 @@ func (m *model) handleInterventionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		// Resume the agent loop
 		m.isThinking = true
-		return m, m.resumeIntervention(resumeFn, choice)
+		return m, m.resumeIntervention(choice, reqID)
 
 	case "esc":
-		// User cancelled
+		// Dismissing the prompt is a denial.
+		pending := m.pendingIntervention
 		m.pendingIntervention = nil
 		if len(m.messages) > 0 {
 			m.messages = m.messages[:len(m.messages)-1]
 		}
-		m.messages = append(m.messages, subtleStyle.Render("→ Action cancelled"))
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		if pending.resume != nil {
+			return m, m.answerVibeApproval(pending, tooling.ChoiceDeny)
+		}
+		m.denyIntervention()
 		return m, nil
 	}
 
@@ -1745,7 +3719,7 @@ func (m *model) renderInterventionSelector() string {
 	var lines []string
 	lines = append(lines, interventionTitleStyle.Render("⚠️  "+m.pendingIntervention.title))
 	lines = append(lines, "")
-	lines = append(lines, helpStyle.Render("Use ↑/↓ to navigate, Enter to confirm, Esc to cancel"))
+	lines = append(lines, helpStyle.Render("Use ↑/↓ to navigate, Enter to confirm, Esc to deny"))
 	lines = append(lines, "")
 
 	for i, choice := range m.pendingIntervention.choices {
@@ -1761,12 +3735,117 @@ func (m *model) renderInterventionSelector() string {
 	return interventionBoxStyle.Render(strings.Join(lines, "\n"))
 }
 
-// resumeIntervention resumes the agent loop after the user makes a choice.
-func (m *model) resumeIntervention(resumeFn func(string) (interface{}, error), choice string) tea.Cmd {
+// resumeIntervention runs the approved action and continues the parked agent
+// loop, streaming into a new message like processRequest.
+func (m *model) resumeIntervention(choice, reqID string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRequest = cancel
+	m.streamReqID = reqID
+	m.streamIdx = -1
+
 	return func() tea.Msg {
-		result, err := resumeFn(choice)
-		return interventionResultMsg{result: result, err: err}
+		defer cancel()
+		resp, err := m.brain.ResumeIntervention(ctx, choice, func(c brain.StreamChunk) {
+			select {
+			case chunkStream <- c:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil {
+			resp.Error = err
+		}
+		return resp
 	}
 }
 
+// answerVibeApproval passes the user's choice to a vibe's permission
+// request and, if it was granted, runs the vibe's action again.
+func (m *model) answerVibeApproval(pending *interventionState, choice string) tea.Cmd {
+	if _, err := pending.resume(choice); err != nil {
+		m.messages = append(m.messages, systemStyle.Render(" DENIED ")+"\n"+helpStyle.Render(err.Error()))
+		m.focus = focusChat
+		m.textarea.Focus()
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return nil
+	}
+	m.messages = append(m.messages, subtleStyle.Render("→ "+choice))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.isThinking = true
+	return pending.retry
+}
+
+// denyIntervention refuses the parked action, ends its agent loop and hands
+// the keyboard back to the chat input.
+func (m *model) denyIntervention() {
+	if err := m.brain.DenyIntervention(); err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" ACTION ERROR ")+"\n"+err.Error())
+	} else {
+		m.messages = append(m.messages, systemStyle.Render(" DENIED ")+"\n"+helpStyle.Render("The action was not run and the agent stopped. Tell it what to do instead."))
+	}
+	m.focus = focusChat
+	m.textarea.Focus()
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveState()
+}
+
 // End of file
+
+// renderApprovals lists the enclave's saved decisions for /sys /approvals.
+func (m *model) renderApprovals() string {
+	enclave := m.brain.Enclave()
+	if enclave == nil {
+		return errorStyle.Render(" APPROVALS ") + " The enclave is unavailable."
+	}
+	list := enclave.ListApprovals()
+	if len(list) == 0 {
+		return systemStyle.Render(" APPROVALS ") + "\n" + subtleStyle.Render("No saved approvals.")
+	}
+	var sb strings.Builder
+	sb.WriteString(systemStyle.Render(" APPROVALS ") + "\n")
+	for i, a := range list {
+		decision := a.Decision
+		if decision == "deny" {
+			decision = errorStyle.Render(decision)
+		}
+		fmt.Fprintf(&sb, "%d. [%s] %s  %s %s\n", i+1, decision, a.Tool, a.Summary, subtleStyle.Render("("+approvalMeta(a)+")"))
+	}
+	sb.WriteString(helpStyle.Render("Revoke with: vibeaura sys approvals revoke <number>"))
+	return sb.String()
+}
+
+// renderDoctor shows the doctor summary for /sys /doctor.
+func renderDoctor(s doctor.Summary) string {
+	var sb strings.Builder
+	sb.WriteString(systemStyle.Render(" DOCTOR ") + "\n")
+	health := s.Health
+	if s.Score >= doctor.HealthDegraded {
+		health = errorStyle.Render(health)
+	}
+	fmt.Fprintf(&sb, "Health: %s | Recent crashes: %d | Last crash: %s\n", health, s.CrashCount, formatCrashTime(s.LastCrash))
+
+	if len(s.Cues) == 0 {
+		sb.WriteString(subtleStyle.Render("No cues reported yet.") + "\n")
+	}
+	for _, g := range groupCues(s.Cues) {
+		sb.WriteString(tagStyle.Render(strings.ToUpper(g.source)) + "\n")
+		for _, c := range g.cues {
+			msg := c.Message
+			if c.Type.Severe() {
+				msg = errorStyle.Render(msg)
+			}
+			fmt.Fprintf(&sb, "  %s %s\n", subtleStyle.Render(c.Timestamp.Format("15:04:05")+" "+string(c.Type)), msg)
+		}
+	}
+
+	if len(s.CrashLogs) > 0 {
+		sb.WriteString(tagStyle.Render("CRASH LOGS") + "\n")
+		for _, l := range s.CrashLogs {
+			fmt.Fprintf(&sb, "  %s %s\n", l.Name, subtleStyle.Render("("+sys.FormatBytes(l.Size)+")"))
+		}
+	}
+	sb.WriteString(helpStyle.Render("Details: vibeaura doctor report <crashfile>"))
+	return sb.String()
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/prompt"
+)
+
+func TestQuit_CancelsRequestInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &model{cancelRequest: cancel}
+
+	_, cmd := m.quit()
+	if ctx.Err() == nil {
+		t.Error("expected quitting to cancel the request in flight")
+	}
+	if cmd == nil {
+		t.Fatal("expected a quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected quit to return tea.Quit")
+	}
+}
+
+func TestEsc_StopsRequestInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &model{brain: brain.New(), textarea: textarea.New(), viewport: viewport.New(80, 20), streamIdx: -1, isThinking: true, cancelRequest: cancel}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if ctx.Err() == nil {
+		t.Fatal("expected Esc to cancel the request in flight")
+	}
+
+	m.Update(brain.Response{Error: context.Canceled})
+	if m.isThinking || len(m.messages) == 0 || !strings.Contains(m.messages[len(m.messages)-1], cancelledNotice) {
+		t.Errorf("expected the transcript to end with %q, got %q", cancelledNotice, m.messages)
+	}
+}
+
+func TestClear_BlockedWhileStreaming(t *testing.T) {
+	scratchHome(t)
+	m := initialModel(brain.New())
+	m.isThinking, m.streamReqID = true, "req"
+	for i := 0; i < 5; i++ {
+		m.messages = append(m.messages, fmt.Sprint("message ", i))
+	}
+	m.appendChunk(brain.StreamChunk{RequestID: "req", Text: "partial"})
+
+	for _, args := range [][]string{{"/clear", "--force"}, {"/clear"}, {"/clear", "/unarchive"}} {
+		m.handleClearCommand(args)
+		if m.pendingClear {
+			t.Fatalf("%v: expected no confirmation prompt while streaming", args)
+		}
+	}
+	// Would index past the end of a cleared transcript.
+	m.appendChunk(brain.StreamChunk{RequestID: "req", Text: " reply"})
+	if got := m.messages[m.streamIdx]; !strings.Contains(got, "partial reply") {
+		t.Errorf("expected the stream to keep its message, got %q", got)
+	}
+}
+
+func TestClear_UnarchiveKeepsMessagesSinceClear(t *testing.T) {
+	scratchHome(t)
+	m := initialModel(brain.New())
+	m.messages = m.freshMessages()
+
+	m.handleClearCommand([]string{"/clear", "--force"})
+	if m.hasArchived {
+		t.Fatal("expected /clear --force with nothing to clear not to archive")
+	}
+
+	m.messages = append(m.messages, "old 1", "old 2")
+	m.handleClearCommand([]string{"/clear", "--force"})
+	if !m.hasArchived {
+		t.Fatal("expected /clear --force to archive the transcript")
+	}
+	m.messages = append(m.messages, "new 1")
+	m.handleClearCommand([]string{"/clear", "/unarchive"})
+
+	got := strings.Join(m.messages, "\n")
+	for _, want := range []string{"old 1", "old 2", "new 1"} {
+		if strings.Count(got, want) != 1 {
+			t.Errorf("expected %q once after unarchive, got %q", want, m.messages)
+		}
+	}
+	if strings.Index(got, "old 2") > strings.Index(got, "new 1") {
+		t.Errorf("expected restored messages ahead of newer ones, got %q", m.messages)
+	}
+	if len(m.times) != len(m.messages) {
+		t.Errorf("expected %d message times, got %d", len(m.messages), len(m.times))
+	}
+}
+
+func TestInputHistory_RecallAndPersist(t *testing.T) {
+	scratchHome(t)
+	b := brain.New()
+	m := &model{brain: b, textarea: textarea.New(), viewport: viewport.New(80, 20), streamIdx: -1}
+	m.loadHistory()
+	for _, v := range []string{"first", "second", "second"} {
+		m.recordHistory(v)
+	}
+
+	key := func(k tea.KeyType) string {
+		m.handleChatKey(tea.KeyMsg{Type: k})
+		return m.textarea.Value()
+	}
+	if got := key(tea.KeyUp); got != "second" {
+		t.Fatalf("expected up to recall the newest entry, got %q", got)
+	}
+	if got := key(tea.KeyUp); got != "first" {
+		t.Fatalf("expected a repeated entry to be recorded once, got %q", got)
+	}
+	if got := key(tea.KeyUp); got != "first" {
+		t.Errorf("expected up to stop at the oldest entry, got %q", got)
+	}
+	if got := key(tea.KeyDown); got != "second" {
+		t.Errorf("expected down to step forward, got %q", got)
+	}
+	if got := key(tea.KeyDown); got != "" {
+		t.Errorf("expected down past the newest entry to clear the input, got %q", got)
+	}
+
+	m.textarea.SetValue("draft")
+	if got := key(tea.KeyUp); got != "draft" {
+		t.Errorf("expected up not to replace typed text, got %q", got)
+	}
+
+	restored := &model{brain: b, textarea: textarea.New()}
+	restored.loadHistory()
+	if len(restored.history.entries) != 2 || restored.history.entries[1] != "second" {
+		t.Errorf("expected the history to be persisted, got %q", restored.history.entries)
+	}
+}
+
+func TestCalc_ShellSubstitutionSentAsTyped(t *testing.T) {
+	scratchHome(t)
+	m := &model{brain: brain.New(), textarea: textarea.New(), viewport: viewport.New(80, 20), streamIdx: -1}
+	if !m.brain.Config().UI.Calc {
+		t.Skip("calc is off by default")
+	}
+
+	m.textarea.SetValue("what does $(ls) print, and is it $(2 + 2)?")
+	_, cmd := m.handleChatKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.isThinking {
+		t.Fatal("expected the prompt to be sent")
+	}
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+	}
+	got := strings.Join(m.messages, "\n")
+	if !strings.Contains(got, "$(ls)") || !strings.Contains(got, "$(2 + 2)") || strings.Contains(got, "CALC") {
+		t.Errorf("expected the raw text in the transcript, got %q", m.messages)
+	}
+}
+
+func TestInputHistory_Capped(t *testing.T) {
+	scratchHome(t)
+	m := &model{brain: brain.New(), textarea: textarea.New()}
+	for i := 0; i <= maxHistory; i++ {
+		m.recordHistory(fmt.Sprint("cmd ", i))
+	}
+	if len(m.history.entries) != maxHistory || m.history.entries[0] != "cmd 1" {
+		t.Errorf("expected the oldest entry dropped past %d, got %d starting %q", maxHistory, len(m.history.entries), m.history.entries[0])
+	}
+}
+
+func TestInputHistory_DropsAuthKeys(t *testing.T) {
+	scratchHome(t)
+	b := brain.New()
+	b.StoreState(historyStateID, []string{"/auth /anthropic sk-ant-old"})
+	m := &model{brain: b, textarea: textarea.New()}
+	m.loadHistory()
+	var saved []string
+	if b.RecallState(historyStateID, &saved); len(saved) != 1 || saved[0] != "/auth /anthropic" {
+		t.Errorf("expected the saved key scrubbed on load, got %q", saved)
+	}
+	m.recordHistory("/auth /openai sk-secret")
+	m.recordHistory("/auth")
+
+	want := []string{"/auth /anthropic", "/auth /openai", "/auth"}
+	restored := &model{brain: b, textarea: textarea.New()}
+	restored.loadHistory()
+	for _, h := range [][]string{m.history.entries, restored.history.entries} {
+		if strings.Join(h, "|") != strings.Join(want, "|") {
+			t.Errorf("expected %q without keys, got %q", want, h)
+		}
+	}
+}
+
+func TestMCPWizard_StepsAndCancel(t *testing.T) {
+	scratchHome(t)
+	m := &model{brain: brain.New(), textarea: textarea.New(), viewport: viewport.New(80, 20), streamIdx: -1}
+	answer := func(v string) tea.Cmd {
+		t.Helper()
+		m.textarea.SetValue(v)
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatalf("expected Enter to send %q to the wizard", v)
+		}
+		_, cmd = m.Update(cmd())
+		return cmd
+	}
+	wizard := func() string { return m.messages[len(m.messages)-1] }
+
+	m.handleSlashCommand("/mcp /add")
+	if m.mcpWizard == nil || !strings.Contains(wizard(), "▸ Name") {
+		t.Fatalf("expected the wizard to ask for a name, got %q", wizard())
+	}
+
+	answer("../bad")
+	if m.mcpWizard.step != mcpStepName || !strings.Contains(wizard(), "invalid MCP server name") {
+		t.Fatalf("expected a bad name to be refused, got %q", wizard())
+	}
+	answer("files")
+	answer("npx")
+	answer("-y  @modelcontextprotocol/server-filesystem /tmp")
+	if m.mcpWizard.step != mcpStepConfirm || len(m.mcpWizard.srv.Args) != 3 {
+		t.Fatalf("expected the confirmation step with 3 args, got %+v", m.mcpWizard)
+	}
+	for _, want := range []string{"✓ Name", "✓ Command", "✓ Args", "▸ Confirm", "files.json"} {
+		if !strings.Contains(wizard(), want) {
+			t.Errorf("expected %q in the wizard, got %q", want, wizard())
+		}
+	}
+	if n := len(m.messages); n != 1 {
+		t.Errorf("expected the wizard to redraw in place, got %d messages", n)
+	}
+
+	if cmd := answer("n"); cmd != nil || m.mcpWizard != nil {
+		t.Fatalf("expected n to close the wizard without adding the server")
+	}
+	if !strings.Contains(wizard(), "not added") || m.textarea.Placeholder != chatPlaceholder {
+		t.Errorf("expected the wizard replaced by a note, got %q", wizard())
+	}
+
+	m.handleSlashCommand("/mcp /add files")
+	if m.mcpWizard == nil || m.mcpWizard.step != mcpStepCommand {
+		t.Fatalf("expected a name on the command line to skip the name step")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mcpWizard != nil {
+		t.Errorf("expected Esc to cancel the wizard")
+	}
+}
+
+func TestResumeState_RoundTrip(t *testing.T) {
+	scratchHome(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	os.WriteFile(file, []byte("package main\n"), 0644)
+
+	b := brain.New()
+	m := initialModel(b)
+	for i := 0; i < 30; i++ {
+		m.messages = append(m.messages, fmt.Sprint("filler ", i))
+	}
+	m.messages = append(m.messages, "you: hello", "ai: hi there")
+	m.viewport.SetContent(m.renderMessages())
+	m.textarea.SetValue("half-typed\nsecond line")
+	m.focus = focusEdit
+	m.showTree = false
+	m.openFile(file)
+	m.viewport.SetYOffset(5)
+
+	path, err := writeResumeState(m.hotSwapState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumeStateFile = path
+	t.Cleanup(func() { resumeStateFile = "" })
+
+	r := initialModel(b)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the state file to be deleted, got %v", err)
+	}
+	if got := strings.Join(r.messages, "\n"); !strings.Contains(got, "ai: hi there") || !strings.Contains(got, "UPDATED TO") {
+		t.Errorf("expected the transcript and an update note, got %q", r.messages)
+	}
+	if r.textarea.Value() != "half-typed\nsecond line" || r.textarea.Line() != 1 || r.textarea.LineInfo().ColumnOffset != len("second line") {
+		t.Errorf("expected the input restored with the cursor at its end, got %q at line %d col %d",
+			r.textarea.Value(), r.textarea.Line(), r.textarea.LineInfo().ColumnOffset)
+	}
+	if r.focus != focusPerusal || r.showTree || !r.isFileOpen || r.currentPath != file {
+		t.Errorf("expected the open file shown in the perusal pane, got focus=%d tree=%v open=%v path=%s", r.focus, r.showTree, r.isFileOpen, r.currentPath)
+	}
+	if r.viewport.YOffset != 5 {
+		t.Errorf("expected scroll offset 5, got %d", r.viewport.YOffset)
+	}
+
+	// A missing or corrupt file leaves one line saying so.
+	os.WriteFile(path, []byte("{not json"), 0600)
+	r = initialModel(b)
+	last := r.messages[len(r.messages)-1]
+	if !strings.Contains(last, "RESUME") || !strings.Contains(last, "corrupt") || strings.Contains(last, "\n") {
+		t.Errorf("expected a one-line note about the corrupt state, got %q", last)
+	}
+}
+
+func TestRenderContextUsage_Thresholds(t *testing.T) {
+	cases := []struct {
+		usage prompt.Usage
+		want  string
+	}{
+		{prompt.Usage{}, ""},
+		{prompt.Usage{Tokens: 500}, ""},
+		{prompt.Usage{Tokens: 500, Limit: 1000}, successStyle.Render("ctx 50%")},
+		{prompt.Usage{Tokens: 600, Limit: 1000}, warningStyle.Render("ctx 60%")},
+		{prompt.Usage{Tokens: 849, Limit: 1000}, warningStyle.Render("ctx 84%")},
+		{prompt.Usage{Tokens: 850, Limit: 1000}, errorStyle.Render("ctx 85%")},
+		{prompt.Usage{Tokens: 1200, Limit: 1000}, errorStyle.Render("ctx 120%")},
+	}
+	for _, c := range cases {
+		if got := renderContextUsage(c.usage); got != c.want {
+			t.Errorf("renderContextUsage(%+v) = %q, want %q", c.usage, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// codeSeparator goes between code blocks copied with /copy code.
+const codeSeparator = "\n\n---\n\n"
+
+// clipboardTerminal receives the OSC 52 sequence; the TUI owns stdout.
+var clipboardTerminal io.Writer = os.Stdout
+
+// clipboardTool is a local program that sets the system clipboard.
+type clipboardTool struct {
+	name string
+	args []string
+}
+
+// detectClipboardTool finds a local clipboard program, or returns false.
+// Over SSH a local program would fill the remote machine's clipboard, so
+// only OSC 52 is used there.
+var detectClipboardTool = func() (clipboardTool, bool) {
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return clipboardTool{}, false
+	}
+	var candidates []clipboardTool
+	switch {
+	case os.Getenv("TERMUX_VERSION") != "":
+		candidates = append(candidates, clipboardTool{"termux-clipboard-set", nil})
+	case runtime.GOOS == "darwin":
+		candidates = append(candidates, clipboardTool{"pbcopy", nil})
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, clipboardTool{"wl-copy", nil})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		candidates = append(candidates, clipboardTool{"xclip", []string{"-selection", "clipboard"}})
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c.name); err == nil {
+			return c, true
+		}
+	}
+	return clipboardTool{}, false
+}
+
+// osc52 wraps text in the OSC 52 "set clipboard" sequence, passed through
+// to the outer terminal when running inside tmux.
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// copyToClipboard sends text to the terminal's clipboard with OSC 52,
+// which works over SSH and in Termux, and also to a local clipboard
+// program when one is found, since not every terminal honours OSC 52. It
+// returns how the text was copied.
+func copyToClipboard(text string) (string, error) {
+	var via []string
+	var errs []error
+	if _, err := io.WriteString(clipboardTerminal, osc52(text)); err == nil {
+		via = append(via, "OSC 52")
+	} else {
+		errs = append(errs, err)
+	}
+	if tool, ok := detectClipboardTool(); ok {
+		cmd := exec.Command(tool.name, tool.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			via = append(via, tool.name)
+		} else {
+			errs = append(errs, fmt.Errorf("%s: %w", tool.name, err))
+		}
+	}
+	if len(via) == 0 {
+		return "", errors.Join(errs...)
+	}
+	return strings.Join(via, " + "), nil
+}
+
+// extractCodeBlocks returns the contents of the fenced code blocks in a
+// markdown text. An unclosed fence runs to the end, as in CommonMark.
+func extractCodeBlocks(markdown string) []string {
+	var blocks []string
+	var fence string
+	var body []string
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence == "" {
+			if f := openingFence(trimmed); f != "" {
+				fence, body = f, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+			blocks = append(blocks, strings.Join(body, "\n"))
+			fence = ""
+			continue
+		}
+		body = append(body, line)
+	}
+	if fence != "" {
+		blocks = append(blocks, strings.Join(body, "\n"))
+	}
+	return blocks
+}
+
+// openingFence returns the run of ``` or ~~~ (three or more) starting line.
+func openingFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// aiReply returns the raw markdown of the nth AI reply from the end, 1
+// being the latest.
+func (m *model) aiReply(n int) (string, bool) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if body, ok := strings.CutPrefix(m.messages[i], aiPrefix); ok {
+			if n--; n == 0 {
+				return body, true
+			}
+		}
+	}
+	return "", false
+}
+
+// handleCopyCommand copies an AI reply, or just its code blocks, to the
+// clipboard: /copy [code] [n].
+func (m *model) handleCopyCommand(parts []string) (tea.Model, tea.Cmd) {
+	codeOnly, n, usage := false, 1, false
+	for _, arg := range parts[1:] {
+		a := strings.TrimPrefix(arg, "/")
+		if a == "code" {
+			codeOnly = true
+			continue
+		}
+		v, err := strconv.Atoi(a)
+		if err != nil || v < 1 {
+			usage = true
+		}
+		n = v
+	}
+	if usage {
+		m.messages = append(m.messages, systemStyle.Render(" COPY ")+"\n"+helpStyle.Render("Usage: /copy [code] [n] — the nth reply from the end, 1 being the latest (Ctrl+Y)"))
+	} else {
+		m.messages = append(m.messages, m.copyReply(n, codeOnly))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// copyReply copies the nth AI reply from the end and describes the result.
+func (m *model) copyReply(n int, codeOnly bool) string {
+	body, ok := m.aiReply(n)
+	if !ok {
+		return errorStyle.Render(" COPY ") + " No AI reply to copy."
+	}
+	what := "reply"
+	if codeOnly {
+		blocks := extractCodeBlocks(body)
+		if len(blocks) == 0 {
+			return errorStyle.Render(" COPY ") + " That reply has no code blocks."
+		}
+		body = strings.Join(blocks, codeSeparator)
+		what = fmt.Sprintf("%d code block(s)", len(blocks))
+	}
+	via, err := copyToClipboard(body)
+	if err != nil {
+		return errorStyle.Render(" COPY ") + " " + err.Error()
+	}
+	return subtleStyle.Render(fmt.Sprintf("📋 Copied %s (%s) via %s", what, sys.FormatBytes(int64(len(body))), via))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	reply := "Try this:\n```go\nfmt.Println(1)\n```\nthen\n~~~~sh\ngo run .\n```\nstill shell\n~~~~\nand\n```\nunclosed"
+	got := extractCodeBlocks(reply)
+	want := []string{"fmt.Println(1)", "go run .\n```\nstill shell", "unclosed"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d blocks, got %q", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestCopyReply(t *testing.T) {
+	t.Setenv("TMUX", "")
+	var term bytes.Buffer
+	clipboardTerminal = &term
+	detect := detectClipboardTool
+	detectClipboardTool = func() (clipboardTool, bool) { return clipboardTool{}, false }
+	defer func() { clipboardTerminal, detectClipboardTool = os.Stdout, detect }()
+
+	m := &model{messages: []string{
+		aiPrefix + "First:\n```py\nprint(1)\n```\n```py\nprint(2)\n```",
+		"You: thanks",
+		aiPrefix + "Plain **answer**",
+	}}
+
+	decoded := func() string {
+		t.Helper()
+		seq := term.String()
+		term.Reset()
+		payload, ok := strings.CutPrefix(strings.TrimSuffix(seq, "\a"), "\x1b]52;c;")
+		if !ok {
+			t.Fatalf("expected an OSC 52 sequence, got %q", seq)
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if out := m.copyReply(1, false); !strings.Contains(out, "OSC 52") {
+		t.Errorf("unexpected result %q", out)
+	}
+	if got := decoded(); got != "Plain **answer**" {
+		t.Errorf("expected the raw markdown without styling, got %q", got)
+	}
+
+	m.copyReply(2, true)
+	if got := decoded(); got != "print(1)"+codeSeparator+"print(2)" {
+		t.Errorf("expected both code blocks, got %q", got)
+	}
+
+	if out := m.copyReply(1, true); !strings.Contains(out, "no code blocks") {
+		t.Errorf("expected a note about missing code, got %q", out)
+	}
+	if out := m.copyReply(3, false); !strings.Contains(out, "No AI reply") || term.Len() != 0 {
+		t.Errorf("expected nothing copied for a missing reply, got %q", out)
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"regexp"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Vibeauracle Color Palette - A vibrant, modern theme
@@ -153,57 +156,200 @@ func colorizeLine(line string) string {
 // MODULAR OUTPUT FUNCTIONS - Use these for explicit colorful output
 // ============================================================================
 
+// Primary results go to cliOut (stdout); titles, status lines and other
+// decoration go to cliErr (stderr) and are dropped under --quiet. Errors are
+// always written.
+//
+// With --output json every helper instead writes one JSON envelope per line
+// to stdout, and commands with structured results print them via printJSON.
+var (
+	cliOut       io.Writer = os.Stdout
+	cliErr       io.Writer = os.Stderr
+	quiet        bool
+	outputFormat = "text"
+)
+
+// jsonOutput reports whether --output json is in effect.
+func jsonOutput() bool {
+	return outputFormat == "json"
+}
+
+// applyOutputFormat validates --output and, for json, turns off ANSI
+// styling so stray rendered text stays plain.
+func applyOutputFormat() error {
+	switch outputFormat {
+	case "text":
+		lipgloss.SetColorProfile(defaultColorProfile)
+	case "json":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		return usageErrorf("invalid --output %q: expected text or json", outputFormat)
+	}
+	return nil
+}
+
+var defaultColorProfile = lipgloss.ColorProfile()
+
+// outputEnvelope is one line of --output json.
+type outputEnvelope struct {
+	Level   string      `json:"level"`
+	Message string      `json:"message,omitempty"`
+	Key     string      `json:"key,omitempty"`
+	Value   string      `json:"value,omitempty"`
+	Meta    string      `json:"meta,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// printEnvelope writes e to stdout. Decorative levels are dropped under
+// --quiet like their text counterparts.
+func printEnvelope(e outputEnvelope, decorative bool) {
+	if decorative && quiet {
+		return
+	}
+	printJSON(e)
+}
+
+// printJSON writes v as a single line of JSON to stdout.
+func printJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(outputEnvelope{Level: "error", Message: err.Error()})
+	}
+	fmt.Fprintln(cliOut, string(data))
+}
+
+// chatter returns the writer for decorative output.
+func chatter() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return cliErr
+}
+
 func printTitle(emoji, title string) {
-	fmt.Println()
-	fmt.Println(cliTitle.Render(emoji + " " + title))
-	fmt.Println(cliMuted.Render("─────────────────────────────────────────────"))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "title", Message: title}, true)
+		return
+	}
+	w := chatter()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, cliTitle.Render(emoji+" "+title))
+	fmt.Fprintln(w, cliMuted.Render("─────────────────────────────────────────────"))
 }
 
 func printKeyValue(key, value string) {
-	fmt.Printf("%s %s\n", cliLabel.Render(key+":"), cliValue.Render(value))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "data", Key: strings.TrimSpace(key), Value: value}, false)
+		return
+	}
+	fmt.Fprintf(cliOut, "%s %s\n", cliLabel.Render(key+":"), cliValue.Render(value))
 }
 
 func printKeyValueHighlight(key, value string) {
-	fmt.Printf("%s %s\n", cliLabel.Render(key+":"), cliHighlight.Render(value))
+	if jsonOutput() {
+		printKeyValue(key, value)
+		return
+	}
+	fmt.Fprintf(cliOut, "%s %s\n", cliLabel.Render(key+":"), cliHighlight.Render(value))
 }
 
 func printSuccess(message string) {
-	fmt.Println(cliBadgeSuccess.Render("SUCCESS") + " " + cliSuccess.Render(message))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "success", Message: message}, true)
+		return
+	}
+	fmt.Fprintln(chatter(), cliBadgeSuccess.Render("SUCCESS")+" "+cliSuccess.Render(message))
+}
+
+// printSuccessData reports success with a structured result. The result is
+// only shown with --output json.
+func printSuccessData(message string, data interface{}) {
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "success", Message: message, Data: data}, false)
+		return
+	}
+	printSuccess(message)
 }
 
 func printError(message string) {
-	fmt.Println(cliBadgeError.Render("ERROR") + " " + cliError.Render(message))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "error", Message: message}, false)
+		return
+	}
+	fmt.Fprintln(cliErr, cliBadgeError.Render("ERROR")+" "+cliError.Render(message))
 }
 
 func printInfo(message string) {
-	fmt.Println(cliInfo.Render("ℹ️  " + message))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "info", Message: message}, true)
+		return
+	}
+	fmt.Fprintln(chatter(), cliInfo.Render("ℹ️  "+message))
 }
 
 func printWarning(message string) {
-	fmt.Println(cliWarning.Render("⚠️  " + message))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "warning", Message: message}, true)
+		return
+	}
+	fmt.Fprintln(chatter(), cliWarning.Render("⚠️  "+message))
+}
+
+// printProgress writes a plain status line such as "Checking for updates...".
+func printProgress(format string, a ...interface{}) {
+	if jsonOutput() {
+		if msg := strings.TrimSpace(fmt.Sprintf(format, a...)); msg != "" {
+			printEnvelope(outputEnvelope{Level: "progress", Message: msg}, true)
+		}
+		return
+	}
+	fmt.Fprintf(chatter(), format, a...)
 }
 
 func printBullet(text string) {
-	fmt.Println(cliBullet.Render("●") + " " + cliValue.Render(text))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "item", Message: text}, false)
+		return
+	}
+	fmt.Fprintln(cliOut, cliBullet.Render("●")+" "+cliValue.Render(text))
 }
 
 func printBulletWithMeta(text, meta string) {
-	fmt.Printf("%s %s %s\n", cliBullet.Render("●"), cliValue.Render(text), cliMuted.Render("("+meta+")"))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "item", Message: strings.TrimSpace(text), Meta: meta}, false)
+		return
+	}
+	fmt.Fprintf(cliOut, "%s %s %s\n", cliBullet.Render("●"), cliValue.Render(text), cliMuted.Render("("+meta+")"))
 }
 
 func printCommand(prefix, cmd, suffix string) {
-	fmt.Println(cliInfo.Render(prefix) + " " + cliCommand.Render(cmd) + " " + cliInfo.Render(suffix))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "hint", Message: strings.TrimSpace(prefix + " " + cmd + " " + suffix)}, true)
+		return
+	}
+	fmt.Fprintln(chatter(), cliInfo.Render(prefix)+" "+cliCommand.Render(cmd)+" "+cliInfo.Render(suffix))
 }
 
 func printStatus(badge, message string) {
-	fmt.Println(cliBadgeInfo.Render(badge) + " " + cliValue.Render(message))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "status", Key: badge, Message: message}, true)
+		return
+	}
+	fmt.Fprintln(chatter(), cliBadgeInfo.Render(badge)+" "+cliValue.Render(message))
 }
 
 func printDone() {
-	fmt.Println()
-	fmt.Println(cliSuccess.Render("✓ Done"))
+	if jsonOutput() {
+		printEnvelope(outputEnvelope{Level: "success", Message: "Done"}, true)
+		return
+	}
+	fmt.Fprintln(chatter())
+	fmt.Fprintln(chatter(), cliSuccess.Render("✓ Done"))
 }
 
 func printNewline() {
-	fmt.Println()
+	if jsonOutput() {
+		return
+	}
+	fmt.Fprintln(chatter())
 }
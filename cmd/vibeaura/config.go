@@ -2,9 +2,15 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/nathfavour/vibeauracle/vibes"
 	"github.com/spf13/cobra"
 )
 
@@ -16,15 +22,73 @@ If no arguments are provided, it lists all current settings.
 If only a key is provided, it shows the current value for that key.
 If both key and value are provided, it updates the setting.
 
+Settings in a .vibeaura.yaml (or .vibeaura/config.yaml) in the current
+directory or a parent are merged over the global config and marked
+"(project)" in the listing. Use --project to write a setting there.
+The update.*, storage.*, mcp.*, providers.* and security.* keys are
+global-only and ignored in project files, except that a project's
+security.tool_policy entries apply when stricter (ask or deny).
+
 Keys:
   update.beta             Enable/disable beta updates (build from master)
   update.build_from_source  Enable/disable building from source for all updates
   update.auto_update      Enable/disable automatic updates (default: true)
   update.verbose          Show detailed output during updates (default: false)
-  model.provider          AI provider (ollama, openai)
+  model.provider          AI provider (ollama, openai, azure-openai, anthropic, gemini, github-models)
   model.name              AI model name
-  model.endpoint          AI provider endpoint`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+  model.fallbacks         Providers to try in order when the active one fails,
+                          comma-separated, each "provider" or "provider:model"
+  model.discovery_timeout How long each provider may take to list models (default: 5s)
+  model.cache_enabled     Reuse responses to identical requests (default: false)
+  model.cache_ttl         How long cached responses are reused (default: 1h)
+  model.request_timeout   How long one request to the provider may take (default: 120s)
+  model.max_retries       Retries of a request failing with a network or 5xx error (default: 3)
+  model.max_context_tokens
+                          The model's context window in estimated tokens, shown
+                          as a percentage in the chat header (default: 32768)
+  model.rate_limit.<provider>
+                          Most requests per minute sent to the provider, 0 for no
+                          limit; see "vibeaura models usage"
+  providers.<name>.endpoint Server URL of a self-hosted provider
+                          (default for ollama: http://localhost:11434)
+  providers.<name>.base_url API base URL override for a gateway or proxy
+  providers.<name>.enabled  Include the provider in model discovery (default: true)
+  ui.theme                dark, light, or a JSON theme file (default: dark)
+  ui.plain                Accessible/plain mode: notices as plain lines (default: false)
+  ui.calc                 "=" calculator and $(...) substitution in chat (default: true)
+  ui.markdown_render      Render AI replies as markdown in chat (default: true)
+  ui.syntax_highlight     Highlight files opened in the side panel (default: true)
+  ui.show_file_diff       Show a diff when the agent writes a file (default: true)
+  prompt.project_instructions
+                          Instructions added to every system prompt; best set
+                          per project with --project
+  prompt.pin_budget       Total bytes of pinned file content per prompt (default: 24576)
+  prompt.context_tokens   Estimated-token budget of the rolling context window (default: 8192)
+  prompt.include_tree     Add a tree of the working directory to each prompt (default: true)
+  prompt.tree_max_entries Files and directories listed in that tree (default: 150)
+  prompt.include_recent_threads
+                          Summarise the session's last requests from earlier runs
+                          in each prompt (default: true); see "vibeaura history"
+  memory.embed_model      Ollama embedding model for semantic recall, empty to disable
+                          (default: nomic-embed-text)
+  memory.semantic_threshold Minimum similarity (-1..1) for recalled memories (default: 0.5)
+  memory.semantic_top_k   Most memories recalled per prompt (default: 5)
+  security.audit_max_mb   Rotate the enclave audit log past this many MB (default: 10)
+  security.audit_keep     Rotated audit logs kept (default: 5)
+  security.enable_tool_cache
+                          Reuse identical file reads for 60s and fetches for 30s
+                          within a session (default: false)
+  security.fs_roots       Directories the file tools may reach, comma-separated
+                          (default: the working directory); the data dir is
+                          always allowed and anything else needs
+                          sys_request_access. "~" allows your whole home
+  security.totp.secret    Base32 TOTP secret needed with the password to unlock
+                          vibes; "vibeaura security totp-setup" keeps one in the
+                          vault instead
+  security.tool_policy.<tool>
+                          Per-tool approval: allow, deny, ask, or default to remove`,
+	ValidArgsFunction: completeConfigKey,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		cm, err := sys.NewConfigManager()
 		if err != nil {
 			return fmt.Errorf("initializing config: %w", err)
@@ -36,90 +100,464 @@ Keys:
 
 		if len(args) == 0 {
 			printTitle("⚙️", "CONFIGURATION")
-			printKeyValue("update.beta            ", fmt.Sprintf("%v", cfg.Update.Beta))
-			printKeyValue("update.build_from_source", fmt.Sprintf("%v", cfg.Update.BuildFromSource))
-			printKeyValueHighlight("update.auto_update     ", fmt.Sprintf("%v", cfg.Update.AutoUpdate))
-			printKeyValue("update.verbose         ", fmt.Sprintf("%v", cfg.Update.Verbose))
-			printKeyValue("model.provider         ", cfg.Model.Provider)
-			printKeyValueHighlight("model.name             ", cfg.Model.Name)
-			printKeyValue("model.endpoint         ", cfg.Model.Endpoint)
-			printKeyValue("ui.theme               ", cfg.UI.Theme)
+			if path := cm.ProjectPath(); path != "" {
+				printInfo("Project settings from " + path)
+			}
+			// Values the project file overrides are marked.
+			fromProject := func(key, value string) string {
+				if cm.FromProject(key) {
+					return value + " " + cliMuted.Render("(project)")
+				}
+				return value
+			}
+			for _, s := range configSettings {
+				show := printKeyValue
+				if s.highlight {
+					show = printKeyValueHighlight
+				}
+				show(fmt.Sprintf("%-23s", s.key), fromProject(s.key, s.get(cfg)))
+			}
+			for _, kv := range keyFamilyValues(cfg) {
+				printKeyValue(kv[0], fromProject(kv[0], kv[1]))
+			}
 			printNewline()
 			return nil
 		}
 
 		key := args[0]
 		if len(args) == 1 {
-			switch key {
-			case "update.beta":
-				fmt.Println(cfg.Update.Beta)
-			case "update.build_from_source":
-				fmt.Println(cfg.Update.BuildFromSource)
-			case "update.auto_update":
-				fmt.Println(cfg.Update.AutoUpdate)
-			case "update.verbose":
-				fmt.Println(cfg.Update.Verbose)
-			case "model.provider":
-				fmt.Println(cfg.Model.Provider)
-			case "model.name":
-				fmt.Println(cfg.Model.Name)
-			case "model.endpoint":
-				fmt.Println(cfg.Model.Endpoint)
-			case "ui.theme":
-				fmt.Println(cfg.UI.Theme)
-			default:
-				return fmt.Errorf("unknown config key: %s", key)
+			if s, ok := configKeys[key]; ok {
+				fmt.Fprintln(cliOut, s.get(cfg))
+				return nil
+			}
+			value, err := getKeyFamily(cfg, key)
+			if err != nil {
+				return err
 			}
+			fmt.Fprintln(cliOut, value)
 			return nil
 		}
 
 		value := args[1]
-		switch key {
-		case "update.beta":
-			b, err := strconv.ParseBool(value)
-			if err != nil {
-				return fmt.Errorf("invalid boolean value for %s: %s", key, value)
+		if configProject && !sys.ProjectScoped(key) {
+			return usageErrorf("%s is global-only and cannot be set per project", key)
+		}
+		if s, ok := configKeys[key]; ok {
+			err = s.set(cfg, value)
+		} else {
+			err = setKeyFamily(cfg, key, value)
+		}
+		if err != nil {
+			return err
+		}
+
+		if configProject {
+			if err := cm.SaveProject(cfg, key); err != nil {
+				return fmt.Errorf("saving project config: %w", err)
 			}
-			cfg.Update.Beta = b
-		case "update.build_from_source":
-			b, err := strconv.ParseBool(value)
-			if err != nil {
-				return fmt.Errorf("invalid boolean value for %s: %s", key, value)
+			printStatus("SET", key+" → "+value+" in "+cm.ProjectPath())
+			return nil
+		}
+		if err := cm.Save(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+
+		printStatus("SET", key+" → "+value)
+		if cm.FromProject(key) {
+			printWarning(key + " is overridden here by " + cm.ProjectPath() + "; use --project to change it for this project")
+		}
+		return nil
+	}),
+}
+
+var configProject bool
+
+func init() {
+	configCmd.Flags().BoolVar(&configProject, "project", false, "Write the setting to the project's .vibeaura.yaml instead of the global config")
+	rootCmd.AddCommand(configCmd)
+}
+
+// configSetting is one fixed config key: how `vibeaura config` shows, reads
+// and changes it. Keys with a name in them (providers.<name>.endpoint and
+// the like) are key families, handled by getKeyFamily and setKeyFamily.
+type configSetting struct {
+	key       string
+	highlight bool // Emphasised in the listing
+	get       func(cfg *sys.Config) string
+	set       func(cfg *sys.Config, value string) error
+}
+
+// configSettings are the fixed keys in listing order.
+var configSettings = []configSetting{
+	boolSetting("update.beta", func(c *sys.Config) *bool { return &c.Update.Beta }),
+	boolSetting("update.build_from_source", func(c *sys.Config) *bool { return &c.Update.BuildFromSource }),
+	highlighted(boolSetting("update.auto_update", func(c *sys.Config) *bool { return &c.Update.AutoUpdate })),
+	boolSetting("update.verbose", func(c *sys.Config) *bool { return &c.Update.Verbose }),
+	stringSetting("model.provider", func(c *sys.Config) *string { return &c.Model.Provider }),
+	highlighted(stringSetting("model.name", func(c *sys.Config) *string { return &c.Model.Name })),
+	{
+		key: "model.fallbacks",
+		get: func(c *sys.Config) string { return strings.Join(c.Model.Fallbacks, ",") },
+		set: func(c *sys.Config, value string) error {
+			c.Model.Fallbacks = nil
+			for _, f := range strings.Split(value, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					c.Model.Fallbacks = append(c.Model.Fallbacks, f)
+				}
+			}
+			return nil
+		},
+	},
+	durationSetting("model.discovery_timeout", "5s", func(c *sys.Config) *time.Duration { return &c.Model.DiscoveryTimeout }),
+	boolSetting("model.cache_enabled", func(c *sys.Config) *bool { return &c.Model.CacheEnabled }),
+	durationSetting("model.cache_ttl", "1h", func(c *sys.Config) *time.Duration { return &c.Model.CacheTTL }),
+	durationSetting("model.request_timeout", "120s", func(c *sys.Config) *time.Duration { return &c.Model.RequestTimeout }),
+	intSetting("model.max_retries", "retry count", 0, func(c *sys.Config) *int { return &c.Model.MaxRetries }),
+	intSetting("model.max_context_tokens", "token count", 1, func(c *sys.Config) *int { return &c.Model.MaxContextTokens }),
+	stringSetting("ui.theme", func(c *sys.Config) *string { return &c.UI.Theme }),
+	boolSetting("ui.plain", func(c *sys.Config) *bool { return &c.UI.Plain }),
+	boolSetting("ui.calc", func(c *sys.Config) *bool { return &c.UI.Calc }),
+	boolSetting("ui.markdown_render", func(c *sys.Config) *bool { return &c.UI.Markdown }),
+	boolSetting("ui.syntax_highlight", func(c *sys.Config) *bool { return &c.UI.Highlight }),
+	boolSetting("ui.show_file_diff", func(c *sys.Config) *bool { return &c.UI.ShowFileDiff }),
+	stringSetting("prompt.project_instructions", func(c *sys.Config) *string { return &c.Prompt.ProjectInstructions }),
+	intSetting("prompt.pin_budget", "byte count", 1, func(c *sys.Config) *int { return &c.Prompt.PinBudget }),
+	intSetting("prompt.context_tokens", "token count", 1, func(c *sys.Config) *int { return &c.Prompt.ContextTokens }),
+	boolSetting("prompt.include_tree", func(c *sys.Config) *bool { return &c.Prompt.IncludeTree }),
+	intSetting("prompt.tree_max_entries", "count", 1, func(c *sys.Config) *int { return &c.Prompt.TreeMaxEntries }),
+	boolSetting("prompt.include_recent_threads", func(c *sys.Config) *bool { return &c.Prompt.IncludeRecentThreads }),
+	stringSetting("memory.embed_model", func(c *sys.Config) *string { return &c.Memory.EmbedModel }),
+	{
+		key: "memory.semantic_threshold",
+		get: func(c *sys.Config) string { return strconv.FormatFloat(c.Memory.SemanticThreshold, 'f', -1, 64) },
+		set: func(c *sys.Config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f < -1 || f > 1 {
+				return usageErrorf("invalid similarity for memory.semantic_threshold: %s (expected -1..1)", value)
 			}
-			cfg.Update.BuildFromSource = b
-		case "update.auto_update":
+			c.Memory.SemanticThreshold = f
+			return nil
+		},
+	},
+	intSetting("memory.semantic_top_k", "count", 1, func(c *sys.Config) *int { return &c.Memory.SemanticTopK }),
+	boolSetting("security.enable_tool_cache", func(c *sys.Config) *bool { return &c.Security.EnableToolCache }),
+	{
+		key: "security.audit_max_mb",
+		get: func(c *sys.Config) string { return strconv.Itoa(c.Security.AuditMaxMB) },
+		set: func(c *sys.Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return usageErrorf("invalid size for security.audit_max_mb: %s (MB, 0 disables rotation)", value)
+			}
+			c.Security.AuditMaxMB = n
+			return nil
+		},
+	},
+	intSetting("security.audit_keep", "count", 1, func(c *sys.Config) *int { return &c.Security.AuditKeep }),
+	{
+		key: "security.fs_roots",
+		get: func(c *sys.Config) string { return strings.Join(c.Security.FSRoots, ",") },
+		set: func(c *sys.Config, value string) error {
+			c.Security.FSRoots = nil
+			for _, r := range strings.Split(value, ",") {
+				if r = strings.TrimSpace(r); r == "" {
+					continue
+				}
+				if sys.IsHome(r) {
+					printWarning(r + " lets the file tools reach every file in your home directory")
+				}
+				c.Security.FSRoots = append(c.Security.FSRoots, r)
+			}
+			return nil
+		},
+	},
+	{
+		key: "security.totp.secret",
+		get: func(c *sys.Config) string { return maskedSetting(c.Security.TOTP.Secret) },
+		set: func(c *sys.Config, value string) error {
+			if err := vibes.NewSecurityManager().SetTOTPSecret(value); err != nil {
+				return usageErrorf("%v", err)
+			}
+			c.Security.TOTP.Secret = value
+			return nil
+		},
+	},
+}
+
+// configKeys indexes configSettings by key.
+var configKeys = func() map[string]*configSetting {
+	keys := make(map[string]*configSetting, len(configSettings))
+	for i := range configSettings {
+		keys[configSettings[i].key] = &configSettings[i]
+	}
+	return keys
+}()
+
+func highlighted(s configSetting) configSetting {
+	s.highlight = true
+	return s
+}
+
+func stringSetting(key string, field func(*sys.Config) *string) configSetting {
+	return configSetting{
+		key: key,
+		get: func(c *sys.Config) string { return *field(c) },
+		set: func(c *sys.Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func boolSetting(key string, field func(*sys.Config) *bool) configSetting {
+	return configSetting{
+		key: key,
+		get: func(c *sys.Config) string { return strconv.FormatBool(*field(c)) },
+		set: func(c *sys.Config, value string) error {
 			b, err := strconv.ParseBool(value)
 			if err != nil {
-				return fmt.Errorf("invalid boolean value for %s: %s", key, value)
+				return usageErrorf("invalid boolean value for %s: %s", key, value)
+			}
+			*field(c) = b
+			return nil
+		},
+	}
+}
+
+// intSetting accepts integers of at least min; what names the value in
+// the error for anything else.
+func intSetting(key, what string, min int, field func(*sys.Config) *int) configSetting {
+	return configSetting{
+		key: key,
+		get: func(c *sys.Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *sys.Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < min {
+				return usageErrorf("invalid %s for %s: %s", what, key, value)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+// durationSetting accepts positive durations; example shows one in the
+// error for anything else.
+func durationSetting(key, example string, field func(*sys.Config) *time.Duration) configSetting {
+	return configSetting{
+		key: key,
+		get: func(c *sys.Config) string { return field(c).String() },
+		set: func(c *sys.Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return usageErrorf("invalid duration for %s: %s (e.g. %s)", key, value, example)
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+// keyFamilyValues lists the set keys of every key family as key/value
+// pairs, sorted within each family.
+func keyFamilyValues(cfg *sys.Config) [][2]string {
+	var out [][2]string
+	limited := make([]string, 0, len(cfg.Model.RateLimit))
+	for name := range cfg.Model.RateLimit {
+		limited = append(limited, name)
+	}
+	sort.Strings(limited)
+	for _, name := range limited {
+		out = append(out, [2]string{rateLimitPrefix + name, strconv.Itoa(cfg.Model.RateLimit[name])})
+	}
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pc := cfg.Providers[name]
+		if pc.Endpoint != "" {
+			out = append(out, [2]string{providerPrefix + name + ".endpoint", pc.Endpoint})
+		}
+		if pc.BaseURL != "" {
+			out = append(out, [2]string{providerPrefix + name + ".base_url", pc.BaseURL})
+		}
+		out = append(out, [2]string{providerPrefix + name + ".enabled", strconv.FormatBool(pc.Enabled)})
+	}
+	tools := make([]string, 0, len(cfg.Security.ToolPolicy))
+	for name := range cfg.Security.ToolPolicy {
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+	for _, name := range tools {
+		out = append(out, [2]string{toolPolicyPrefix + name, cfg.Security.ToolPolicy[name]})
+	}
+	return out
+}
+
+// getKeyFamily reads a model.rate_limit.*, providers.* or
+// security.tool_policy.* key.
+func getKeyFamily(cfg *sys.Config, key string) (string, error) {
+	if name, ok := rateLimitKey(key); ok {
+		return strconv.Itoa(cfg.Model.RateLimit[name]), nil
+	}
+	if name, field, ok := providerKey(key); ok {
+		pc := cfg.Providers[name]
+		switch field {
+		case "endpoint":
+			return pc.Endpoint, nil
+		case "base_url":
+			return pc.BaseURL, nil
+		}
+		return strconv.FormatBool(pc.Enabled), nil
+	}
+	tool, ok := toolPolicyKey(key)
+	if !ok {
+		return "", usageErrorf("unknown config key: %s", key)
+	}
+	if policy := cfg.Security.ToolPolicy[tool]; policy != "" {
+		return policy, nil
+	}
+	return "default", nil
+}
+
+// setKeyFamily changes a model.rate_limit.*, providers.* or
+// security.tool_policy.* key.
+func setKeyFamily(cfg *sys.Config, key, value string) error {
+	if name, ok := rateLimitKey(key); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return usageErrorf("invalid requests per minute for %s: %s", key, value)
+		}
+		if n == 0 {
+			delete(cfg.Model.RateLimit, name)
+			return nil
+		}
+		if cfg.Model.RateLimit == nil {
+			cfg.Model.RateLimit = make(map[string]int)
+		}
+		cfg.Model.RateLimit[name] = n
+		return nil
+	}
+	if name, field, ok := providerKey(key); ok {
+		pc, known := cfg.Providers[name]
+		if !known {
+			pc.Enabled = true
+		}
+		switch field {
+		case "endpoint", "base_url":
+			if value != "" {
+				if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+					return usageErrorf("invalid URL for %s: %s", key, value)
+				}
+			}
+			if field == "endpoint" {
+				pc.Endpoint = value
+			} else {
+				pc.BaseURL = value
 			}
-			cfg.Update.AutoUpdate = b
-		case "update.verbose":
+		case "enabled":
 			b, err := strconv.ParseBool(value)
 			if err != nil {
-				return fmt.Errorf("invalid boolean value for %s: %s", key, value)
+				return usageErrorf("invalid boolean value for %s: %s", key, value)
 			}
-			cfg.Update.Verbose = b
-		case "model.provider":
-			cfg.Model.Provider = value
-		case "model.name":
-			cfg.Model.Name = value
-		case "model.endpoint":
-			cfg.Model.Endpoint = value
-		case "ui.theme":
-			cfg.UI.Theme = value
-		default:
-			return fmt.Errorf("unknown config key: %s", key)
+			pc.Enabled = b
 		}
+		cfg.SetProvider(name, pc)
+		return nil
+	}
+	tool, ok := toolPolicyKey(key)
+	if !ok {
+		return usageErrorf("unknown config key: %s", key)
+	}
+	policy := strings.ToLower(value)
+	if policy == "default" {
+		delete(cfg.Security.ToolPolicy, tool)
+		return nil
+	}
+	if !tooling.ValidToolPolicy(policy) {
+		return usageErrorf("invalid policy for %s: %s (expected allow, deny, ask or default)", key, value)
+	}
+	if cfg.Security.ToolPolicy == nil {
+		cfg.Security.ToolPolicy = make(map[string]string)
+	}
+	cfg.Security.ToolPolicy[tool] = policy
+	return nil
+}
 
-		if err := cm.Save(cfg); err != nil {
-			return fmt.Errorf("saving config: %w", err)
+// completeConfigKey completes the key of `config` from configSettings and
+// the key families of the built-in providers. security.tool_policy. is
+// offered as a prefix, since tool names are only known once tools load.
+func completeConfigKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	candidates := make([]string, 0, len(configSettings)+4*len(sys.ProviderNames)+1)
+	for _, s := range configSettings {
+		candidates = append(candidates, s.key)
+	}
+	for _, name := range sys.ProviderNames {
+		candidates = append(candidates, rateLimitPrefix+name,
+			providerPrefix+name+".endpoint", providerPrefix+name+".base_url", providerPrefix+name+".enabled")
+	}
+	candidates = append(candidates, toolPolicyPrefix)
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			out = append(out, c)
 		}
+	}
+	if len(out) == 1 && strings.HasSuffix(out[0], ".") {
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
 
-		printStatus("SET", key+" → "+value)
-		return nil
-	},
+// providerPrefix namespaces per-provider keys.
+const providerPrefix = "providers."
+
+// providerKey splits a providers.<name>.<field> key. Provider names may
+// contain dashes but not dots.
+func providerKey(key string) (name, field string, ok bool) {
+	rest, ok := strings.CutPrefix(key, providerPrefix)
+	if !ok {
+		return "", "", false
+	}
+	name, field, ok = strings.Cut(rest, ".")
+	if !ok || name == "" {
+		return "", "", false
+	}
+	switch field {
+	case "endpoint", "base_url", "enabled":
+		return name, field, true
+	}
+	return "", "", false
 }
 
-func init() {
-	rootCmd.AddCommand(configCmd)
+// toolPolicyPrefix namespaces per-tool policy keys.
+// maskedSetting hides a secret setting's value, showing only whether it
+// is set.
+func maskedSetting(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "(set)"
+}
+
+const rateLimitPrefix = "model.rate_limit."
+
+// rateLimitKey extracts the provider name from a model.rate_limit.<provider> key.
+func rateLimitKey(key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, rateLimitPrefix)
+	return name, ok && name != "" && !strings.Contains(name, ".")
+}
+
+const toolPolicyPrefix = "security.tool_policy."
+
+// toolPolicyKey extracts the tool name from a security.tool_policy.<tool> key.
+func toolPolicyKey(key string) (string, bool) {
+	tool, ok := strings.CutPrefix(key, toolPolicyPrefix)
+	return tool, ok && tool != ""
 }
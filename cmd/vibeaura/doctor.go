@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/spf13/cobra"
+)
+
+// doctorCueLimit is how many recent cues the doctor summary shows.
+const doctorCueLimit = 50
+
+var doctorOlderThan string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Show health, crash history and recent module cues",
+	Long: `Show the health score, the crash counters, the latest cues that modules
+reported to the doctor, grouped by source, and the crash reports on disk.
+This process has only just started, so when it has no cues of its own the
+cues saved with the most recent crash report are shown instead.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		s := doctor.Summarize(doctorCueLimit)
+		cuesFrom := ""
+		if len(s.Cues) == 0 && len(s.CrashLogs) > 0 {
+			if r, err := doctor.ReadCrashReport(s.CrashLogs[0].Path); err == nil {
+				s.Cues = lastCues(r.Cues, doctorCueLimit)
+				cuesFrom = s.CrashLogs[0].Name
+			}
+		}
+		if jsonOutput() {
+			printJSON(s)
+			return nil
+		}
+
+		printTitle("🩺", "DOCTOR")
+		printKeyValueHighlight("Health        ", s.Health)
+		printKeyValue("Recent crashes", strconv.Itoa(s.CrashCount))
+		printKeyValue("Last crash    ", formatCrashTime(s.LastCrash))
+		printNewline()
+
+		title := "CUES"
+		if cuesFrom != "" {
+			title = "CUES FROM " + cuesFrom
+		}
+		printTitle("📡", title)
+		if len(s.Cues) == 0 {
+			printInfo("No cues reported yet.")
+		}
+		for _, g := range groupCues(s.Cues) {
+			fmt.Fprintln(cliOut, cliLabel.Render(g.source))
+			for _, c := range g.cues {
+				printBulletWithMeta(c.Message, fmt.Sprintf("%s · %s", c.Type, c.Timestamp.Local().Format("15:04:05")))
+			}
+		}
+		printNewline()
+
+		printTitle("💥", "CRASH LOGS: "+doctor.CrashDir())
+		if len(s.CrashLogs) == 0 {
+			printSuccess("No crash reports.")
+		}
+		for _, l := range s.CrashLogs {
+			printBulletWithMeta(l.Name, fmt.Sprintf("%s · %s", sys.FormatBytes(l.Size), l.ModTime.Local().Format("2006-01-02 15:04")))
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var doctorReportCmd = &cobra.Command{
+	Use:   "report <crashfile>",
+	Short: "Pretty-print a crash report",
+	Long: `Print a crash report: the error, when it happened, the health at the time,
+the cues leading up to it and the stack trace. A bare file name is looked up
+in the crash_logs directory.`,
+	Example: "  vibeaura doctor report crash_20240102_150405.json",
+	Args:    cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		r, err := doctor.ReadCrashReport(args[0])
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			printJSON(r)
+			return nil
+		}
+
+		printTitle("💥", "CRASH REPORT")
+		printKeyValueHighlight("Error ", r.Error)
+		printKeyValue("When  ", r.Timestamp.Local().Format("2006-01-02 15:04:05"))
+		printKeyValue("Health", r.Health.String())
+		printNewline()
+		if len(r.Cues) > 0 {
+			printTitle("📡", "CUES BEFORE THE CRASH")
+			for _, g := range groupCues(lastCues(r.Cues, doctorCueLimit)) {
+				fmt.Fprintln(cliOut, cliLabel.Render(g.source))
+				for _, c := range g.cues {
+					printBulletWithMeta(c.Message, fmt.Sprintf("%s · %s", c.Type, c.Timestamp.Local().Format("15:04:05")))
+				}
+			}
+			printNewline()
+		}
+		printTitle("🧵", "STACK")
+		fmt.Fprintln(cliOut, cliMuted.Render(strings.TrimRight(r.Stack, "\n")))
+		printNewline()
+		return nil
+	}),
+}
+
+var doctorCleanCmd = &cobra.Command{
+	Use:     "clean",
+	Short:   "Delete old crash reports",
+	Example: "  vibeaura doctor clean --older-than 30d",
+	Args:    cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		age, err := parseAge(doctorOlderThan)
+		if err != nil {
+			return usageErrorf("--older-than: %v", err)
+		}
+		removed, freed, err := doctor.CleanCrashLogs(age)
+		if err != nil {
+			return err
+		}
+		printSuccessData(fmt.Sprintf("Removed %d crash report(s), freeing %s.", removed, sys.FormatBytes(freed)),
+			map[string]interface{}{"removed": removed, "bytes": freed})
+		return nil
+	}),
+}
+
+// doctorCheckTimeout bounds the whole self-check, including the wait for
+// MCP servers to start.
+const doctorCheckTimeout = 30 * time.Second
+
+var doctorCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Run self-diagnosis checks",
+	Long: `Check that this installation works: the active provider answers, the
+vault can store and read back a secret, the data directory is writable, the
+Go toolchain is on PATH when updates are built from source, every MCP server
+is running and the memory database passes SQLite's integrity check.
+
+Each check prints PASS, WARN or FAIL, followed by the health score. The exit
+code is 1 when any check fails.`,
+	Example: "  vibeaura doctor check",
+	Args:    cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+		defer cancel()
+		checks := b.Diagnose(ctx)
+		checks = append(checks, checkGoToolchain(b.Config()))
+		health := doctor.AnalyzeHealth()
+
+		if jsonOutput() {
+			printJSON(map[string]interface{}{"health": health.String(), "checks": checks})
+		} else {
+			printTitle("🩺", "SELF-CHECK")
+			for _, c := range checks {
+				printCheck(c)
+			}
+			printNewline()
+			printKeyValueHighlight("Health", health.String())
+		}
+
+		failed := 0
+		for _, c := range checks {
+			if c.Status == doctor.CheckFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return withExit(ExitFailure, fmt.Errorf("%d of %d checks failed", failed, len(checks)))
+		}
+		return nil
+	}),
+}
+
+// checkGoToolchain looks for the go command, which updates need when they
+// are built from source.
+func checkGoToolchain(cfg *sys.Config) doctor.Check {
+	const name = "go toolchain"
+	fromSource := Version == "release" || Version == "master" || cfg.Update.BuildFromSource
+	path, err := exec.LookPath("go")
+	switch {
+	case err == nil:
+		return doctor.Pass(name, path)
+	case fromSource:
+		return doctor.Fail(name, "go not found in PATH; updates are built from source and need it")
+	}
+	return doctor.Pass(name, "not needed; updates install release binaries")
+}
+
+// printCheck writes one check as a coloured PASS, WARN or FAIL line.
+func printCheck(c doctor.Check) {
+	badge := cliBadgeSuccess.Render("PASS")
+	switch c.Status {
+	case doctor.CheckWarn:
+		badge = cliBadgeWarning.Render("WARN")
+	case doctor.CheckFail:
+		badge = cliBadgeError.Render("FAIL")
+	}
+	line := badge + " " + cliValue.Render(fmt.Sprintf("%-18s", c.Name))
+	if c.Detail != "" {
+		line += " " + cliMuted.Render(c.Detail)
+	}
+	fmt.Fprintln(cliOut, line)
+}
+
+// parseAge reads an age such as 30d, 12h or 90m.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+	}
+	return d, nil
+}
+
+// cueGroup is the cues from one source, oldest first.
+type cueGroup struct {
+	source string
+	cues   []doctor.Cue
+}
+
+// groupCues groups cues by source, in the order each source first appears.
+func groupCues(cues []doctor.Cue) []cueGroup {
+	var groups []cueGroup
+	index := make(map[string]int)
+	for _, c := range cues {
+		i, ok := index[c.Source]
+		if !ok {
+			i = len(groups)
+			index[c.Source] = i
+			groups = append(groups, cueGroup{source: c.Source})
+		}
+		groups[i].cues = append(groups[i].cues, c)
+	}
+	return groups
+}
+
+func lastCues(cues []doctor.Cue, n int) []doctor.Cue {
+	if len(cues) > n {
+		return cues[len(cues)-n:]
+	}
+	return cues
+}
+
+func formatCrashTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func init() {
+	doctorCleanCmd.Flags().StringVar(&doctorOlderThan, "older-than", "30d", "Delete reports older than this (e.g. 30d, 12h)")
+	doctorCmd.AddCommand(doctorCheckCmd)
+	doctorCmd.AddCommand(doctorReportCmd)
+	doctorCmd.AddCommand(doctorCleanCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// crashGuard runs the TUI's commands under doctor.Recover, so a panic in
+// one of Bubble Tea's command goroutines is logged like any other crash.
+type crashGuard struct {
+	tea.Model
+}
+
+func (g crashGuard) Init() tea.Cmd {
+	return guardCmd(g.Model.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := g.Model.Update(msg)
+	return crashGuard{next}, guardCmd(cmd)
+}
+
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer doctor.Recover()
+		msg := cmd()
+		// Batched commands run in goroutines of their own.
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i := range batch {
+				batch[i] = guardCmd(batch[i])
+			}
+		}
+		return msg
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enclaveSince string
+	enclaveUntil string
+)
+
+var enclaveCmd = &cobra.Command{
+	Use:   "enclave",
+	Short: "Inspect the Enclave approval store and audit trail",
+}
+
+var enclaveExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export approvals and the audit log as a signed, hash-chained JSON document",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		since, err := parseExportTime(enclaveSince)
+		if err != nil {
+			return usageErrorf("--since: %w", err)
+		}
+		until, err := parseExportTime(enclaveUntil)
+		if err != nil {
+			return usageErrorf("--until: %w", err)
+		}
+
+		b := brain.New()
+		key, err := auditKey(b, true)
+		if err != nil {
+			return err
+		}
+
+		doc, err := tooling.ExportAudit(b.Config().DataDir, key, since, until)
+		if err != nil {
+			return fmt.Errorf("exporting audit trail: %w", err)
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(args[0], data, 0600); err != nil {
+			return err
+		}
+
+		printSuccess(fmt.Sprintf("Exported %d audit entries and %d approvals to %s", len(doc.Entries), len(doc.Approvals), args[0]))
+		if doc.Unchained > 0 {
+			printWarning(fmt.Sprintf("%d older entries predate the hash chain and were not exported", doc.Unchained))
+		}
+		return nil
+	}),
+}
+
+var enclaveVerifyCmd = &cobra.Command{
+	Use:   "verify <file>",
+	Short: "Verify the signature and hash chain of an exported audit trail",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var doc tooling.AuditExport
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parsing export: %w", err)
+		}
+
+		key, err := auditKey(brain.New(), false)
+		if err != nil {
+			return err
+		}
+
+		problems := tooling.VerifyAuditExport(&doc, key)
+		if len(problems) == 0 {
+			printSuccess(fmt.Sprintf("Verified %d entries: chain intact, signature valid", len(doc.Entries)))
+			return nil
+		}
+
+		printTitle("🚨", "VERIFICATION FAILED")
+		for _, p := range problems {
+			printBulletWithMeta(p.Reason, fmt.Sprintf("%s → %s", p.From, p.To))
+		}
+		printNewline()
+		return fmt.Errorf("%d problem(s) found in %s", len(problems), args[0])
+	}),
+}
+
+// auditKey loads the export signing secret from the vault, generating it
+// on first export.
+func auditKey(b *brain.Brain, create bool) ([]byte, error) {
+	if secret, err := b.GetSecret(tooling.AuditHMACSecret); err == nil && secret != "" {
+		return hex.DecodeString(secret)
+	}
+	if !create {
+		return nil, fmt.Errorf("no audit signing key in the vault; run 'vibeaura enclave export' on this machine first")
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := b.StoreSecret(tooling.AuditHMACSecret, hex.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("storing audit signing key: %w", err)
+	}
+	return key, nil
+}
+
+// parseExportTime accepts RFC3339 or a plain YYYY-MM-DD date.
+func parseExportTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func init() {
+	enclaveExportCmd.Flags().StringVar(&enclaveSince, "since", "", "Only export entries at or after this time (RFC3339 or YYYY-MM-DD)")
+	enclaveExportCmd.Flags().StringVar(&enclaveUntil, "until", "", "Only export entries at or before this time (RFC3339 or YYYY-MM-DD)")
+	enclaveCmd.AddCommand(enclaveExportCmd)
+	enclaveCmd.AddCommand(enclaveVerifyCmd)
+	rootCmd.AddCommand(enclaveCmd)
+}
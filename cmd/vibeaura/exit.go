@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+
+	aimodel "github.com/nathfavour/vibeauracle/model"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes. Every command maps its error through exitCode, so scripts can
+// rely on these regardless of which subcommand failed.
+const (
+	ExitOK          = 0 // Success
+	ExitFailure     = 1 // Generic failure
+	ExitUsage       = 2 // Bad flags, arguments or input
+	ExitUnreachable = 3 // Network or provider unreachable
+	ExitAuth        = 4 // Credentials missing or rejected
+	ExitQuota       = 5 // Rate limit or quota exceeded
+)
+
+// exitError pins an explicit exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExit attaches an exit code to err. A nil err stays nil.
+func withExit(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// usageErrorf reports invalid input (exit code 2).
+func usageErrorf(format string, a ...interface{}) error {
+	return withExit(ExitUsage, fmt.Errorf(format, a...))
+}
+
+// cobra reports argument and command errors as plain strings.
+var cobraUsagePrefixes = []string{
+	"unknown command", "unknown flag", "unknown shorthand flag", "flag needs an argument",
+	"invalid argument", "accepts ", "requires at least", "requires at most", "required flag",
+}
+
+// exitCode maps an error to the exit-code contract.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+
+	switch {
+	case errors.Is(err, aimodel.ErrUnauthorized):
+		return ExitAuth
+	case errors.Is(err, aimodel.ErrRateLimited):
+		return ExitQuota
+	case isNetworkError(err):
+		return ExitUnreachable
+	}
+
+	msg := err.Error()
+	for _, p := range cobraUsagePrefixes {
+		if strings.HasPrefix(msg, p) {
+			return ExitUsage
+		}
+	}
+	return ExitFailure
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	var urlErr *url.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.As(err, &dnsErr) || errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// cliRun is the shared RunE wrapper. Once a command is running, its errors
+// are runtime failures, so cobra's usage dump is suppressed for them.
+func cliRun(fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return fn(cmd, args)
+	}
+}
+
+// isCompletion reports whether args ask for a completion script or for
+// completion candidates.
+func isCompletion(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return false
+}
+
+// execute runs the CLI with the given arguments and streams and returns the
+// process exit code. Primary results go to stdout; everything else, including
+// the error report, goes to stderr.
+func execute(args []string, stdout, stderr io.Writer) int {
+	cliOut, cliErr = stdout, stderr
+	defer func() { cliOut, cliErr = os.Stdout, os.Stderr }()
+
+	// Colorized help and usage; errors are reported below instead. Shell
+	// completion scripts and candidates are read by the shell, so they are
+	// written as they are.
+	rootCmd.SetArgs(args)
+	if isCompletion(args) {
+		rootCmd.SetOut(stdout)
+		rootCmd.SetErr(stderr)
+	} else {
+		rootCmd.SetOut(NewColorWriter(stdout))
+		rootCmd.SetErr(NewColorWriter(stderr))
+	}
+	rootCmd.SilenceErrors = true
+
+	err := rootCmd.Execute()
+	if err != nil {
+		printError(err.Error())
+	}
+	return exitCode(err)
+}
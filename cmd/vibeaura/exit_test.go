@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	aimodel "github.com/nathfavour/vibeauracle/model"
+)
+
+// TestMain routes all outbound traffic through a proxy that refuses
+// connections, so network-dependent commands fail fast and deterministically.
+// The proxy environment must be set before the first HTTP request, since
+// net/http reads it once per process.
+func TestMain(m *testing.M) {
+	for _, k := range []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy", "ALL_PROXY"} {
+		os.Setenv(k, "http://127.0.0.1:1")
+	}
+	os.Setenv("GIT_TERMINAL_PROMPT", "0")
+	os.Exit(m.Run())
+}
+
+// runCLI executes the CLI in-process against a scratch home directory.
+func runCLI(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	quiet = false
+	outputFormat = "text"
+	var out, errOut bytes.Buffer
+	code = execute(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func scratchHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitFailure},
+		{usageErrorf("bad value"), ExitUsage},
+		{errors.New(`unknown command "nope" for "vibeaura"`), ExitUsage},
+		{errors.New("accepts 1 arg(s), received 0"), ExitUsage},
+		{fmt.Errorf("listing: %w", aimodel.ErrUnauthorized), ExitAuth},
+		{fmt.Errorf("listing: %w", aimodel.ErrRateLimited), ExitQuota},
+		{withExit(ExitAuth, errors.New("no key")), ExitAuth},
+	}
+	for _, c := range cases {
+		if got := exitCode(c.err); got != c.want {
+			t.Errorf("exitCode(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+}
+
+func TestCLI_ModelsListNoProviders(t *testing.T) {
+	scratchHome(t)
+	if code, _, stderr := runCLI(t, "config", "providers.ollama.endpoint", "http://127.0.0.1:1"); code != ExitOK {
+		t.Fatalf("config set failed (%d): %s", code, stderr)
+	}
+
+	code, stdout, stderr := runCLI(t, "models", "list")
+	if code != ExitUnreachable {
+		t.Errorf("expected exit %d, got %d (stderr: %s)", ExitUnreachable, code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "no provider responded") {
+		t.Errorf("expected the error on stderr, got %q", stderr)
+	}
+}
+
+func TestCLI_AuthBadKey(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, stderr := runCLI(t, "auth", "openai", "not a key")
+	if code != ExitAuth {
+		t.Errorf("expected exit %d, got %d", ExitAuth, code)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "OpenAI API key") {
+		t.Errorf("expected the error on stderr, got %q", stderr)
+	}
+
+	if code, _, _ := runCLI(t, "auth", "ollama", "localhost"); code != ExitUsage {
+		t.Errorf("expected exit %d for a malformed endpoint, got %d", ExitUsage, code)
+	}
+}
+
+func TestCLI_UpdateOffline(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, stderr := runCLI(t, "update")
+	if code != ExitUnreachable {
+		t.Errorf("expected exit %d, got %d (stderr: %s)", ExitUnreachable, code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "Checking for updates") || !strings.Contains(stderr, "checking for updates:") {
+		t.Errorf("expected progress and error on stderr, got %q", stderr)
+	}
+}
+
+func TestCLI_UsageError(t *testing.T) {
+	scratchHome(t)
+
+	if code, _, _ := runCLI(t, "models", "use", "ollama"); code != ExitUsage {
+		t.Errorf("expected exit %d for a missing argument, got %d", ExitUsage, code)
+	}
+	if code, _, _ := runCLI(t, "models", "list", "--no-such-flag"); code != ExitUsage {
+		t.Errorf("expected exit %d for an unknown flag, got %d", ExitUsage, code)
+	}
+	if code, _, _ := runCLI(t, "config", "ui.calc", "maybe"); code != ExitUsage {
+		t.Errorf("expected exit %d for an invalid value, got %d", ExitUsage, code)
+	}
+}
+
+func TestCLI_Quiet(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, stderr := runCLI(t, "config", "ui.calc")
+	if code != ExitOK || strings.TrimSpace(stdout) != "true" {
+		t.Fatalf("expected \"true\" on stdout, got %d %q", code, stdout)
+	}
+
+	code, stdout, stderr = runCLI(t, "version")
+	if code != ExitOK || !strings.Contains(stdout, "Version") || !strings.Contains(stderr, "VIBE AURACLE") {
+		t.Errorf("expected result on stdout and title on stderr, got stdout %q stderr %q", stdout, stderr)
+	}
+
+	code, stdout, stderr = runCLI(t, "--quiet", "version")
+	if code != ExitOK || !strings.Contains(stdout, "Version") {
+		t.Errorf("expected result on stdout, got %d %q", code, stdout)
+	}
+	if stderr != "" {
+		t.Errorf("expected no stderr chatter with --quiet, got %q", stderr)
+	}
+}
+
+func TestModelsUseCompletion(t *testing.T) {
+	scratchHome(t)
+	dataDir := filepath.Join(os.Getenv("HOME"), ".vibeauracle")
+	os.MkdirAll(dataDir, 0755)
+	cache := fmt.Sprintf(`{"updated_at": %q, "models": [{"name": "llama3:latest", "provider": "ollama"}, {"name": "qwen2.5-coder:7b", "provider": "ollama"}, {"name": "gpt-4o", "provider": "openai"}]}`, time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(dataDir, "models_cache.json"), []byte(cache), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, out, _ := runCLI(t, "__complete", "models", "use", "o")
+	if !strings.Contains(out, "ollama\nopenai\n") || strings.Contains(out, "anthropic") || strings.Contains(out, "\x1b[") {
+		t.Errorf("unexpected provider completions:\n%q", out)
+	}
+
+	_, out, _ = runCLI(t, "__complete", "models", "use", "ollama", "q")
+	if !strings.HasPrefix(out, "qwen2.5-coder:7b\n") || strings.Contains(out, "llama3") || strings.Contains(out, "gpt-4o") {
+		t.Errorf("unexpected model completions:\n%q", out)
+	}
+
+	// A stale cache still completes; completion never goes to the network.
+	stale := strings.Replace(cache, time.Now().Format(time.RFC3339), "2001-01-01T00:00:00Z", 1)
+	os.WriteFile(filepath.Join(dataDir, "models_cache.json"), []byte(stale), 0644)
+	if _, out, _ = runCLI(t, "__complete", "models", "use", "openai", ""); !strings.HasPrefix(out, "gpt-4o\n") {
+		t.Errorf("expected a stale cache to be used, got:\n%q", out)
+	}
+	os.Remove(filepath.Join(dataDir, "models_cache.json"))
+	if _, out, _ = runCLI(t, "__complete", "models", "use", "openai", ""); !strings.HasPrefix(out, ":") {
+		t.Errorf("expected no completions without a cache, got:\n%q", out)
+	}
+}
+
+func TestConfigKeyCompletion(t *testing.T) {
+	scratchHome(t)
+	_, out, _ := runCLI(t, "__complete", "config", "ui.s")
+	if !strings.HasPrefix(out, "ui.syntax_highlight\nui.show_file_diff\n") {
+		t.Errorf("unexpected key completions:\n%q", out)
+	}
+	_, out, _ = runCLI(t, "__complete", "config", "providers.gem")
+	if !strings.Contains(out, "providers.gemini.endpoint\n") || !strings.Contains(out, "providers.gemini.enabled\n") {
+		t.Errorf("expected per-provider keys, got:\n%q", out)
+	}
+	_, out, _ = runCLI(t, "__complete", "config", "security.tool")
+	if !strings.HasPrefix(out, "security.tool_policy.\n:6\n") {
+		t.Errorf("expected the tool policy prefix without a trailing space, got:\n%q", out)
+	}
+
+	// Every listed key can be read back.
+	for _, s := range configSettings {
+		if code, _, stderr := runCLI(t, "config", s.key); code != ExitOK {
+			t.Errorf("config %s: exit %d (%s)", s.key, code, stderr)
+		}
+	}
+}
+
+func TestRollbackVersionCompletion(t *testing.T) {
+	scratchHome(t)
+	if _, out, _ := runCLI(t, "__complete", "rollback", "--version", ""); !strings.HasPrefix(out, ":") {
+		t.Errorf("expected no completions before releases are cached, got:\n%q", out)
+	}
+	cacheReleaseTags([]releaseInfo{{TagName: "v1.2.0"}, {TagName: "v1.1.0"}, {TagName: "nightly"}})
+	_, out, _ := runCLI(t, "__complete", "rollback", "--version", "v1")
+	if !strings.HasPrefix(out, "v1.2.0\nv1.1.0\n") || strings.Contains(out, "nightly") {
+		t.Errorf("unexpected tag completions:\n%q", out)
+	}
+}
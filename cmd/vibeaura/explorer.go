@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// explorerAction is a change the explorer prompts for before making it.
+type explorerAction int
+
+const (
+	explorerNewFile explorerAction = iota
+	explorerNewDir
+	explorerRename
+	explorerDelete
+)
+
+// explorerPrompt is the input line shown at the bottom of the perusal pane
+// while the explorer asks for a name or a confirmation.
+type explorerPrompt struct {
+	action explorerAction
+	dir    string // Where new entries go
+	target string // Entry being renamed or deleted
+	isDir  bool
+	input  string
+}
+
+// explorerDir is the directory the explorer is showing; with a file open,
+// the directory holding it.
+func (m *model) explorerDir() string {
+	if m.isFileOpen {
+		return filepath.Dir(m.currentPath)
+	}
+	return m.currentPath
+}
+
+// startExplorerPrompt opens the prompt for n, N, r and d. Rename and delete
+// act on the open file, or else the highlighted entry.
+func (m *model) startExplorerPrompt(action explorerAction) {
+	if m.showAudit {
+		return
+	}
+	p := &explorerPrompt{action: action, dir: m.explorerDir()}
+	if action == explorerRename || action == explorerDelete {
+		switch {
+		case m.isFileOpen:
+			p.target = m.currentPath
+		case len(m.treeEntries) > 0:
+			entry := m.treeEntries[m.treeCursor]
+			p.target = filepath.Join(m.currentPath, entry.Name())
+			p.isDir = entry.IsDir()
+		default:
+			return
+		}
+		if action == explorerRename {
+			p.input = filepath.Base(p.target)
+		}
+	}
+	m.explorer = p
+}
+
+// view renders the prompt as a single line.
+func (p *explorerPrompt) view() string {
+	name := filepath.Base(p.target)
+	var label string
+	switch p.action {
+	case explorerNewFile:
+		label = "New file: "
+	case explorerNewDir:
+		label = "New directory: "
+	case explorerRename:
+		label = "Rename " + name + " to: "
+	case explorerDelete:
+		if !p.isDir {
+			return errorStyle.Render(" DELETE ") + " " + name + "? (y/n)"
+		}
+		label = "Type " + name + " to delete it and its contents: "
+	}
+	return systemStyle.Render(" "+strings.TrimSuffix(label, " ")+" ") + " " + p.input + "█"
+}
+
+// handleExplorerPromptKey edits the prompt's input; Enter applies it and Esc
+// (handled with the other Esc bindings) cancels.
+func (m *model) handleExplorerPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.explorer
+	if p.action == explorerDelete && !p.isDir {
+		m.explorer = nil
+		if msg.String() == "y" || msg.String() == "Y" {
+			m.applyExplorerPrompt(p)
+		}
+		return m, nil
+	}
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.explorer = nil
+		m.applyExplorerPrompt(p)
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.input += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// applyExplorerPrompt makes the change through sys.FS, refreshes the tree
+// and reports the outcome in the chat.
+func (m *model) applyExplorerPrompt(p *explorerPrompt) {
+	fs := sys.NewLocalFS("")
+	name := strings.TrimSpace(p.input)
+	var note, selectName string
+	var err error
+
+	switch p.action {
+	case explorerNewFile, explorerNewDir:
+		if err = validEntryName(name); err != nil {
+			break
+		}
+		path := filepath.Join(p.dir, name)
+		if p.action == explorerNewDir {
+			err = fs.MakeDir(path)
+			note = "📁 Created directory " + displayPath(path)
+		} else if _, statErr := os.Lstat(path); statErr == nil {
+			err = fmt.Errorf("%s already exists", name)
+		} else {
+			err = fs.WriteFile(path, nil)
+			note = "📄 Created " + displayPath(path)
+		}
+		selectName = name
+	case explorerRename:
+		if err = validEntryName(name); err != nil || name == filepath.Base(p.target) {
+			break
+		}
+		path := filepath.Join(filepath.Dir(p.target), name)
+		if err = fs.Rename(p.target, path); err != nil {
+			break
+		}
+		if m.brain.IsPinned(p.target) {
+			_ = m.brain.Unpin(p.target)
+			_ = m.brain.Pin(path)
+		}
+		note = "✏️ Renamed " + displayPath(p.target) + " to " + name
+		if m.isFileOpen && m.currentPath == p.target {
+			m.openFile(path)
+			m.reportExplorer(note, nil)
+			return
+		}
+		selectName = name
+	case explorerDelete:
+		if p.isDir {
+			if name != filepath.Base(p.target) {
+				m.reportExplorer("", fmt.Errorf("name did not match; %s was not deleted", displayPath(p.target)))
+				return
+			}
+			err = fs.DeleteDir(p.target)
+		} else {
+			err = fs.DeleteFile(p.target)
+		}
+		if err != nil {
+			break
+		}
+		if m.brain.IsPinned(p.target) {
+			_ = m.brain.Unpin(p.target)
+		}
+		note = "🗑️ Deleted " + displayPath(p.target)
+		if m.isFileOpen && (m.currentPath == p.target || strings.HasPrefix(m.currentPath, p.target+string(filepath.Separator))) {
+			m.closeFile()
+		}
+	}
+
+	if note == "" && err == nil {
+		return // Renamed to the same name
+	}
+	if err == nil {
+		m.refreshTree(p.dir, selectName)
+	}
+	m.reportExplorer(note, err)
+}
+
+// closeFile leaves the open file, dropping whatever the editor held.
+func (m *model) closeFile() {
+	m.currentPath = filepath.Dir(m.currentPath)
+	m.isFileOpen = false
+	m.editArea.Reset()
+	m.editFormat = sys.TextFormat{}
+	if m.focus == focusEdit {
+		m.focus = focusPerusal
+	}
+}
+
+// refreshTree reloads dir, moving the cursor to selectName when given and
+// keeping it in range otherwise.
+func (m *model) refreshTree(dir, selectName string) {
+	m.currentPath = dir
+	m.loadTree(dir)
+	for i, e := range m.treeEntries {
+		if e.Name() == selectName {
+			m.treeCursor = i
+		}
+	}
+	if m.treeCursor >= len(m.treeEntries) {
+		m.treeCursor = max(len(m.treeEntries)-1, 0)
+	}
+	m.updatePerusalContent()
+}
+
+// reportExplorer appends the outcome of an explorer change to the chat.
+func (m *model) reportExplorer(note string, err error) {
+	if err != nil {
+		m.messages = append(m.messages, errorStyle.Render(" EXPLORER ")+" "+err.Error())
+	} else {
+		m.messages = append(m.messages, subtleStyle.Render(note))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveState()
+}
+
+// validEntryName accepts a single path element.
+func validEntryName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("a name is required")
+	case name == "." || name == "..":
+		return fmt.Errorf("%q is not a usable name", name)
+	case strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("%q: names cannot contain path separators", name)
+	}
+	return nil
+}
+
+// displayPath shortens path to be relative to the working directory when it
+// is inside it.
+func displayPath(path string) string {
+	cwd, _ := os.Getwd()
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/brain"
+)
+
+func TestExplorerPrompts(t *testing.T) {
+	scratchHome(t)
+	dir := t.TempDir()
+	m := &model{brain: brain.New(), editArea: textarea.New(), hl: &fileHighlighter{}, focus: focusPerusal, currentPath: dir}
+	m.loadTree(dir)
+
+	press := func(keys ...string) {
+		t.Helper()
+		for _, k := range keys {
+			var msg tea.KeyMsg
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "backspace":
+				msg = tea.KeyMsg{Type: tea.KeyBackspace}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+			m.handlePerusalKey(msg)
+		}
+	}
+	lastMessage := func() string { return m.messages[len(m.messages)-1] }
+
+	press("n", "a.txt", "enter")
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatalf("expected a.txt to be created: %v", err)
+	}
+	if !strings.Contains(lastMessage(), "Created") {
+		t.Errorf("expected the creation in the transcript, got %q", lastMessage())
+	}
+
+	press("N", "sub", "enter")
+	if info, err := os.Stat(filepath.Join(dir, "sub")); err != nil || !info.IsDir() {
+		t.Fatalf("expected sub/ to be created: %v", err)
+	}
+	if name := m.treeEntries[m.treeCursor].Name(); name != "sub" {
+		t.Errorf("expected the cursor on the new directory, got %s", name)
+	}
+
+	press("n", "a.txt", "enter")
+	if !strings.Contains(lastMessage(), "already exists") {
+		t.Errorf("expected an existing name to be refused, got %q", lastMessage())
+	}
+
+	// Directories are only deleted when their name is typed back.
+	press("d", "nope", "enter")
+	if _, err := os.Stat(filepath.Join(dir, "sub")); err != nil {
+		t.Fatal("expected a mismatched name to keep the directory")
+	}
+	press("d", "sub", "enter")
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Fatalf("expected sub/ to be deleted: %v", err)
+	}
+
+	// The rename prompt starts with the current name.
+	m.treeCursor = 0
+	press("r", "backspace", "backspace", "backspace", "md", "enter")
+	if _, err := os.Stat(filepath.Join(dir, "a.md")); err != nil {
+		t.Fatalf("expected a.txt to be renamed to a.md: %v", err)
+	}
+
+	m.openFile(filepath.Join(dir, "a.md"))
+	press("d", "n")
+	if !m.isFileOpen {
+		t.Fatal("expected n to cancel the deletion")
+	}
+	press("d", "y")
+	if _, err := os.Stat(filepath.Join(dir, "a.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected a.md to be deleted: %v", err)
+	}
+	if m.isFileOpen || m.editArea.Value() != "" || m.currentPath != dir {
+		t.Errorf("expected deleting the open file to close it, got open=%v path=%s", m.isFileOpen, m.currentPath)
+	}
+}
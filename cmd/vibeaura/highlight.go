@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// hlCacheLimit bounds the highlighted-file cache; like the markdown cache
+// it is simply reset when full.
+const hlCacheLimit = 32
+
+// highlightedFile is a cached rendering, kept only while the file's content
+// is unchanged.
+type highlightedFile struct {
+	content  string
+	rendered string
+}
+
+// fileHighlighter syntax-highlights files for the perusal pane with chroma,
+// caching by path so reopening a file does not re-render it.
+type fileHighlighter struct {
+	style string
+	cache map[string]highlightedFile
+}
+
+// highlight returns content coloured for path's language. ok is false if no
+// lexer matches or rendering fails, so the caller can show the raw text.
+func (h *fileHighlighter) highlight(path, content, theme string) (out string, ok bool) {
+	style := "monokai"
+	if theme == "light" {
+		style = "github"
+	}
+	if h.cache == nil || h.style != style {
+		h.style = style
+		h.cache = make(map[string]highlightedFile)
+	}
+	if cached, hit := h.cache[path]; hit && cached.content == content {
+		return cached.rendered, true
+	}
+
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil {
+		lexer = lexers.Analyse(content)
+	}
+	if lexer == nil {
+		return "", false
+	}
+	iter, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	if err := formatters.TTY256.Format(&sb, styles.Get(style), iter); err != nil {
+		return "", false
+	}
+
+	if len(h.cache) >= hlCacheLimit {
+		h.cache = make(map[string]highlightedFile)
+	}
+	h.cache[path] = highlightedFile{content: content, rendered: sb.String()}
+	return sb.String(), true
+}
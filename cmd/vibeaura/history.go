@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/spf13/cobra"
+)
+
+const (
+	historyStateID = "command_history"
+	maxHistory     = 200 // Oldest entries are dropped beyond this
+)
+
+// inputHistory is what was sent from the chat input, oldest first, shared
+// by every session.
+type inputHistory struct {
+	entries []string
+	pos     int // Entry shown while browsing; len(entries) when not browsing
+}
+
+// loadHistory restores the input history saved by earlier runs. Keys
+// saved by older versions are scrubbed from it and from disk.
+func (m *model) loadHistory() {
+	var entries []string
+	if err := m.brain.RecallState(historyStateID, &entries); err == nil {
+		scrubbed := false
+		for i, e := range entries {
+			if v := historyEntry(e); v != e {
+				entries[i], scrubbed = v, true
+			}
+		}
+		if scrubbed {
+			m.brain.StoreState(historyStateID, entries)
+		}
+		m.history.entries = entries
+	}
+	m.history.pos = len(m.history.entries)
+}
+
+// historyEntry is how an input is kept in the history: /auth lines keep
+// only the provider, since their arguments are keys.
+func historyEntry(v string) string {
+	fields := strings.Fields(v)
+	if len(fields) > 2 && strings.EqualFold(fields[0], "/auth") {
+		return strings.Join(fields[:2], " ")
+	}
+	return v
+}
+
+// recordHistory appends a sent input and persists the history. Repeating
+// the previous entry is not recorded twice.
+func (m *model) recordHistory(v string) {
+	h := &m.history
+	h.pos = len(h.entries)
+	v = historyEntry(v)
+	if strings.TrimSpace(v) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == v) {
+		return
+	}
+	h.entries = append(h.entries, v)
+	if len(h.entries) > maxHistory {
+		h.entries = append([]string(nil), h.entries[len(h.entries)-maxHistory:]...)
+	}
+	h.pos = len(h.entries)
+	m.brain.StoreState(historyStateID, h.entries)
+}
+
+// recallHistory handles up/down in the chat input: on an empty input, or
+// one still showing a recalled entry, it steps to the older (up) or newer
+// (down) entry. Stepping past the newest clears the input. It reports
+// false when there is nothing to recall, leaving the keys to scroll.
+func (m *model) recallHistory(key string) bool {
+	h := &m.history
+	v := m.textarea.Value()
+	if v == "" {
+		h.pos = len(h.entries)
+	} else if h.pos >= len(h.entries) || h.entries[h.pos] != v {
+		return false
+	}
+	switch key {
+	case "up":
+		if h.pos == 0 {
+			return len(h.entries) > 0
+		}
+		h.pos--
+	case "down":
+		if h.pos == len(h.entries) {
+			return false
+		}
+		h.pos++
+	default:
+		return false
+	}
+	if h.pos == len(h.entries) {
+		m.textarea.Reset()
+	} else {
+		m.textarea.SetValue(h.entries[h.pos])
+	}
+	return true
+}
+
+// threadListLimit is how many threads /history and `vibeaura history` list
+// by default.
+const threadListLimit = 20
+
+// shortThreadID is the part of a thread id shown in listings; any unique
+// prefix finds the thread.
+func shortThreadID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// threadSummary is a thread's prompt on one line, cut to fit a listing.
+func threadSummary(prompt string) string {
+	line := strings.Join(strings.Fields(prompt), " ")
+	if r := []rune(line); len(r) > 60 {
+		line = string(r[:59]) + "…"
+	}
+	return line
+}
+
+// handleHistoryCommand lists the active session's threads, newest first,
+// or shows the one whose id starts with parts[1].
+func (m *model) handleHistoryCommand(parts []string) (tea.Model, tea.Cmd) {
+	var body string
+	if len(parts) > 1 {
+		t, err := m.brain.Thread(parts[1])
+		if err != nil {
+			body = err.Error()
+		} else {
+			body = fmt.Sprintf("%s · %s · %s\n\nYou: %s\n\n%s", t.ThreadID, t.SessionID,
+				t.CreatedAt.Local().Format("2006-01-02 15:04:05"), t.Prompt, t.Response)
+		}
+	} else {
+		threads, err := m.brain.Threads("", threadListLimit)
+		switch {
+		case err != nil:
+			body = "Could not read the history: " + err.Error()
+		case len(threads) == 0:
+			body = "No requests recorded for this session yet."
+		default:
+			var lines []string
+			for _, t := range threads {
+				lines = append(lines, shortThreadID(t.ThreadID)+"  "+threadSummary(t.Prompt)+
+					subtleStyle.Render("  "+t.CreatedAt.Local().Format("2006-01-02 15:04")))
+			}
+			body = strings.Join(lines, "\n") + "\n\nShow one with /history <id>."
+		}
+	}
+	m.messages = append(m.messages, systemStyle.Render(" HISTORY ")+"\n"+helpStyle.Render(body))
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+var (
+	historySession string
+	historyLimit   int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [thread-id]",
+	Short: "List past requests of a chat session, or show one in full",
+	Long: `List the requests the agent answered in a chat session, newest first,
+with the id of each. Given an id, or any unique prefix of one, print that
+thread's prompt and final answer in full.
+
+The session defaults to the one for the current directory.`,
+	Example: `  vibeaura history
+  vibeaura history --session api-3f9a1c --limit 5
+  vibeaura history 1b9d6bcd`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		if len(args) == 1 {
+			t, err := b.Thread(args[0])
+			if err != nil {
+				return err
+			}
+			if jsonOutput() {
+				printJSON(t)
+				return nil
+			}
+			printTitle("🧵", "THREAD "+t.ThreadID)
+			printKeyValue("Session", t.SessionID)
+			printKeyValue("When   ", t.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+			printNewline()
+			fmt.Fprintln(cliOut, cliLabel.Render("Prompt:"))
+			fmt.Fprintln(cliOut, t.Prompt)
+			printNewline()
+			fmt.Fprintln(cliOut, cliLabel.Render("Response:"))
+			fmt.Fprintln(cliOut, t.Response)
+			return nil
+		}
+
+		if historySession != "" {
+			if err := brain.ValidateSessionName(historySession); err != nil {
+				return usageErrorf("%v", err)
+			}
+		}
+		if historyLimit < 0 {
+			return usageErrorf("--limit must not be negative")
+		}
+		threads, err := b.Threads(historySession, historyLimit)
+		if err != nil {
+			return fmt.Errorf("reading history: %w", err)
+		}
+		if jsonOutput() {
+			printJSON(threads)
+			return nil
+		}
+		session := historySession
+		if session == "" {
+			session = b.Session()
+		}
+		printTitle("🧵", "HISTORY · "+session)
+		if len(threads) == 0 {
+			printInfo("No requests recorded for this session yet.")
+			return nil
+		}
+		for _, t := range threads {
+			printBulletWithMeta(shortThreadID(t.ThreadID)+"  "+threadSummary(t.Prompt), t.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		printNewline()
+		printCommand("💡 Run", "vibeaura history <id>", "to see a thread in full.")
+		return nil
+	}),
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historySession, "session", "", "Session to list (default: the one for the current directory)")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", threadListLimit, "Most threads to list, 0 for all")
+	rootCmd.AddCommand(historyCmd)
+}
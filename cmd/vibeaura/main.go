@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/nathfavour/vibeauracle/internal/doctor"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nathfavour/vibeauracle/brain"
+	aimodel "github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
 	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/nathfavour/vibeauracle/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +28,7 @@ var (
 	Commit          = "none"
 	BuildDate       = "unknown"
 	resumeStateFile string // For hot-swap restoration
+	sessionName     string // Chat session override; defaults to one per working directory
 )
 
 func init() {
@@ -61,8 +70,20 @@ var rootCmd = &cobra.Command{
 	Version: Version,
 	Short:   "vibe auracle - Distributed, System-Intimate AI Engineering Ecosystem",
 	Long: `vibe auracle is a keyboard-centric interface that unifies the terminal, 
-the IDE, and the AI assistant into a single system-aware experience.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+the IDE, and the AI assistant into a single system-aware experience.
+
+Exit codes:
+  0  success
+  1  generic failure
+  2  usage error (bad flags, arguments or input)
+  3  network or provider unreachable
+  4  credentials missing or rejected
+  5  rate limit or quota exceeded`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyOutputFormat(); err != nil {
+			return err
+		}
+
 		// Ensure the tool is installed in a standard system directory
 		ensureInstalled()
 
@@ -71,9 +92,15 @@ the IDE, and the AI assistant into a single system-aware experience.`,
 		if cmd.CommandPath() != "vibeaura update" && cmd.CommandPath() != "vibeaura completion" && cmd.CommandPath() != "vibeaura rollback" {
 			checkUpdateSilent()
 		}
+		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		b := brain.New()
+		if sessionName != "" {
+			if err := b.SetSession(sessionName); err != nil {
+				return withExit(ExitUsage, err)
+			}
+		}
 
 		// Inject Status Reporting into Tooling
 		tooling.StatusReporter = func(icon, step, msg string) {
@@ -85,69 +112,242 @@ the IDE, and the AI assistant into a single system-aware experience.`,
 			}
 		}
 
-		// Ensure we are in an interactive terminal
-		p := tea.NewProgram(initialModel(b), tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
+		// Doctor cues above warning level land in the notification center
+		doctor.OnCue = func(c doctor.Cue) {
+			severity := NoticeWarning
+			if c.Type == doctor.SignalPanic || c.Type == doctor.SignalCrash {
+				severity = NoticeCritical
+			}
+			Notify(severity, c.Source, c.Message)
+		}
+		brain.OnPinDropped = func(path string) {
+			Notify(NoticeWarning, "pin", "Unpinned "+path+": file no longer exists")
+		}
+
+		// The TUI owns the terminal from here, so a passphrase cannot be
+		// asked for later.
+		if err := b.UnlockVault(); err != nil {
+			fmt.Fprintf(cliErr, "Vault locked, stored credentials unavailable: %v\n", err)
+		}
+
+		// Ensure we are in an interactive terminal. Panics are left to
+		// doctor.Recover, which gives the terminal back before reporting.
+		p := tea.NewProgram(crashGuard{initialModel(b)}, tea.WithAltScreen(), tea.WithoutCatchPanics())
+		doctor.BeforeCrash = func() { _ = p.ReleaseTerminal() }
+		_, err := p.Run()
+		doctor.BeforeCrash = nil
+		if err != nil {
 			doctor.Send("tui", doctor.SignalError, err.Error(), nil)
-			fmt.Printf("Alas, there's been an error: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("alas, there's been an error: %w", err)
 		}
-	},
+		return nil
+	}),
 }
 
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Manage AI provider credentials",
-	Long:  "Securely store and manage API keys for providers like GitHub Models, OpenAI, and Ollama.",
+	Long:  "Securely store and manage API keys for providers like GitHub Models, OpenAI, Anthropic, and Ollama.",
+}
+
+// authResult is the --output json payload of the auth commands.
+type authResult struct {
+	Provider string `json:"provider"`
+	Stored   string `json:"stored"` // "vault" or "config"
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// validateCredential rejects values that cannot be a token or API key, such
+// as an empty string or a pasted line with spaces in it.
+func validateCredential(name, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return withExit(ExitAuth, fmt.Errorf("%s is empty", name))
+	}
+	if len(value) < 8 || strings.IndexFunc(value, unicode.IsSpace) >= 0 || strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return withExit(ExitAuth, fmt.Errorf("%s does not look valid (too short or contains whitespace)", name))
+	}
+	return nil
 }
 
 var authGithubCmd = &cobra.Command{
 	Use:   "github-models <token>",
 	Short: "Configure GitHub Models PAT",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		token := args[0]
+		if err := validateCredential("GitHub Models PAT", token); err != nil {
+			return err
+		}
 		b := brain.New()
-		err := b.StoreSecret("github_models_pat", token)
-		if err != nil {
-			printError(err.Error())
-			os.Exit(1)
+		if err := b.StoreSecret("github_models_pat", token); err != nil {
+			return err
 		}
-		printSuccess("GitHub Models PAT stored in secure vault.")
-	},
+		printSuccessData("GitHub Models PAT stored in secure vault.", authResult{Provider: "github-models", Stored: "vault"})
+		return nil
+	}),
 }
 
 var authOllamaCmd = &cobra.Command{
 	Use:   "ollama <endpoint>",
 	Short: "Configure Ollama endpoint",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		endpoint := args[0]
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return usageErrorf("invalid endpoint %q: expected a URL like http://localhost:11434", endpoint)
+		}
 		b := brain.New()
-		cfg := b.Config()
-		cfg.Model.Endpoint = endpoint
+		cfg := b.Config().Clone()
+		pc := cfg.Providers["ollama"]
+		pc.Endpoint = endpoint
+		cfg.SetProvider("ollama", pc)
 		if err := b.UpdateConfig(cfg); err != nil {
-			printError(err.Error())
-			os.Exit(1)
+			return err
 		}
-		printSuccess("Ollama endpoint set to: " + endpoint)
-	},
+		printSuccessData("Ollama endpoint set to: "+endpoint, authResult{Provider: "ollama", Stored: "config", Endpoint: endpoint})
+		return nil
+	}),
 }
 
 var authOpenAICmd = &cobra.Command{
 	Use:   "openai <api-key>",
 	Short: "Configure OpenAI API key",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if err := validateCredential("OpenAI API key", key); err != nil {
+			return err
+		}
+		b := brain.New()
+		if err := b.StoreSecret("openai_api_key", key); err != nil {
+			return err
+		}
+		printSuccessData("OpenAI API key stored in secure vault.", authResult{Provider: "openai", Stored: "vault"})
+		return nil
+	}),
+}
+
+var (
+	azureDeployment string
+	azureAPIVersion string
+)
+
+var authAzureCmd = &cobra.Command{
+	Use:   "azure-openai <api-key> <endpoint>",
+	Short: "Configure an Azure OpenAI resource",
+	Long: `Store the API key of an Azure OpenAI resource and its endpoint, such as
+https://my-resource.openai.azure.com. Models are chosen by deployment name:
+--deployment adds one to the list offered by model discovery, for resources
+where Azure will not list them.`,
+	Example: "  vibeaura auth azure-openai $AZURE_OPENAI_KEY https://my-resource.openai.azure.com --deployment gpt4o-prod",
+	Args:    cobra.ExactArgs(2),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		key, endpoint := args[0], args[1]
+		if err := validateCredential("Azure OpenAI API key", key); err != nil {
+			return err
+		}
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+			return usageErrorf("invalid endpoint %q: expected a URL like https://my-resource.openai.azure.com", endpoint)
+		}
+		b := brain.New()
+		if err := b.StoreSecret("azure_openai_api_key", key); err != nil {
+			return err
+		}
+		cfg := b.Config().Clone()
+		pc := cfg.Providers["azure-openai"]
+		pc.Endpoint = endpoint
+		if azureAPIVersion != "" {
+			pc.APIVersion = azureAPIVersion
+		}
+		if azureDeployment != "" && !slices.Contains(pc.Deployments, azureDeployment) {
+			pc.Deployments = append(pc.Deployments, azureDeployment)
+		}
+		cfg.SetProvider("azure-openai", pc)
+		if err := b.UpdateConfig(cfg); err != nil {
+			return err
+		}
+		printSuccessData("Azure OpenAI key stored in secure vault; endpoint set to "+endpoint, authResult{Provider: "azure-openai", Stored: "vault", Endpoint: endpoint})
+		return nil
+	}),
+}
+
+var authAnthropicCmd = &cobra.Command{
+	Use:   "anthropic <api-key>",
+	Short: "Configure Anthropic API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if err := validateCredential("Anthropic API key", key); err != nil {
+			return err
+		}
+		b := brain.New()
+		if err := b.StoreSecret("anthropic_api_key", key); err != nil {
+			return err
+		}
+		printSuccessData("Anthropic API key stored in secure vault.", authResult{Provider: "anthropic", Stored: "vault"})
+		return nil
+	}),
+}
+
+var authGeminiCmd = &cobra.Command{
+	Use:   "gemini <api-key>",
+	Short: "Configure Google Gemini API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		key := args[0]
+		if err := validateCredential("Gemini API key", key); err != nil {
+			return err
+		}
+		b := brain.New()
+		if err := b.StoreSecret("gemini_api_key", key); err != nil {
+			return err
+		}
+		printSuccessData("Gemini API key stored in secure vault.", authResult{Provider: "gemini", Stored: "vault"})
+		return nil
+	}),
+}
+
+var vaultPassphrase bool
+
+var authMigrateVaultCmd = &cobra.Command{
+	Use:   "migrate-vault",
+	Short: "Encrypt the fallback secrets file",
+	Long: `Encrypt the secrets file used when no OS keyring is available.
+
+A plaintext secrets.json from older releases is moved into the encrypted
+vault.json and deleted; this also happens automatically the first time a
+secret is read. The vault is then re-encrypted with a fresh key derived
+from this machine, or from a passphrase with --passphrase. A passphrase is
+asked for on use, or read from ` + vault.PassphraseEnv + `.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		var passphrase string
+		if vaultPassphrase {
+			p, err := vault.ReadPassphrase(cliErr, "New vault passphrase: ")
+			if err != nil {
+				return err
+			}
+			confirm, err := vault.ReadPassphrase(cliErr, "Repeat passphrase: ")
+			if err != nil {
+				return err
+			}
+			if p == "" || p != confirm {
+				return usageErrorf("passphrases are empty or do not match")
+			}
+			passphrase = p
+		}
+
 		b := brain.New()
-		err := b.StoreSecret("openai_api_key", key)
+		moved, total, err := b.MigrateVault(passphrase)
 		if err != nil {
-			printError(err.Error())
-			os.Exit(1)
+			return err
 		}
-		printSuccess("OpenAI API key stored in secure vault.")
-	},
+		if moved > 0 {
+			printSuccess(fmt.Sprintf("Moved %d plaintext secret(s) into the encrypted vault.", moved))
+		}
+		printSuccess(fmt.Sprintf("Vault re-encrypted (%d secret(s)).", total))
+		return nil
+	}),
 }
 
 var modelsCmd = &cobra.Command{
@@ -155,48 +355,96 @@ var modelsCmd = &cobra.Command{
 	Short: "Discover and manage AI models",
 }
 
+var modelsRefresh bool
+
+// modelsCacheMaxAge is how long `models list` trusts the last discovery.
+const modelsCacheMaxAge = 10 * time.Minute
+
 var modelsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all models from active providers",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `List all models from active providers. A discovery from the last
+10 minutes is reused; --refresh queries the providers again.`,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		b := brain.New()
-		printInfo("Discovering models...")
-		discoveries, err := b.DiscoverModels(cmd.Context())
+		maxAge := modelsCacheMaxAge
+		if modelsRefresh {
+			maxAge = 0
+		} else if _, updated, ok := b.CachedModels(); !ok || time.Since(updated) >= maxAge {
+			printInfo("Discovering models...")
+		}
+		discoveries, cached, err := b.DiscoverModelsCached(cmd.Context(), maxAge)
 		if err != nil {
-			printError(err.Error())
-			os.Exit(1)
+			return err
 		}
 
 		if len(discoveries) == 0 {
-			printWarning("No models found. Use 'vibeaura auth' to configure providers.")
-			return
+			return fmt.Errorf("no models found; use 'vibeaura auth' to configure providers")
+		}
+		if jsonOutput() {
+			printJSON(discoveries)
+			return nil
 		}
 
-		printTitle("✨", "AVAILABLE MODELS")
+		title := "AVAILABLE MODELS"
+		if cached {
+			title += " (cached)"
+		}
+		printTitle("✨", title)
 		for _, d := range discoveries {
 			displayName := brain.ShortenModelName(d.Name)
 			printBulletWithMeta(fmt.Sprintf("%-30s", displayName), fmt.Sprintf("%s: %s", d.Provider, d.Name))
 		}
 		printNewline()
 		printCommand("💡 Use", "vibeaura models use <provider> <model>", "to switch.")
-	},
+		return nil
+	}),
 }
 
 var modelsUseCmd = &cobra.Command{
 	Use:   "use <provider> <model>",
 	Short: "Switch the active model",
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Switch the active model.
+
+Providers: ollama, openai, azure-openai, anthropic, gemini, github-models.
+For azure-openai the model is a deployment name.`,
+	Example:           "  vibeaura models use anthropic claude-3-5-sonnet",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeModelsUse,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		provider := args[0]
 		modelName := args[1]
 		b := brain.New()
-		err := b.SetModel(provider, modelName)
-		if err != nil {
-			printError(err.Error())
-			os.Exit(1)
+		if err := b.SetModel(provider, modelName); err != nil {
+			return err
 		}
 		printStatus("SWITCHED", modelName+" via "+provider)
-	},
+		return nil
+	}),
+}
+
+// completeModelsUse completes the provider of `models use` from the
+// built-in providers, and the model from the last discovery that found
+// models for that provider. It never queries a provider: completion has to
+// be instant, so with no cached discovery there is nothing to offer.
+func completeModelsUse(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	switch len(args) {
+	case 0:
+		for _, name := range sys.ProviderNames {
+			if strings.HasPrefix(name, toComplete) {
+				out = append(out, name)
+			}
+		}
+	case 1:
+		discoveries, _, _ := brain.CachedModelsIn(defaultDataDir())
+		for _, d := range discoveries {
+			if d.Provider == args[0] && strings.HasPrefix(d.Name, toComplete) {
+				out = append(out, d.Name)
+			}
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
 }
 
 var sysCmd = &cobra.Command{
@@ -207,50 +455,179 @@ var sysCmd = &cobra.Command{
 var sysStatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show system resource usage",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		b := brain.New()
-		snapshot, _ := b.GetSnapshot()
+		snapshot, err := b.GetSnapshot()
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			printJSON(snapshot)
+			return nil
+		}
 		printTitle("⚡", "POWER SNAPSHOT")
 		printKeyValueHighlight("CPU Usage", fmt.Sprintf("%.1f%%", snapshot.CPUUsage))
 		printKeyValueHighlight("Mem Usage", fmt.Sprintf("%.1f%%", snapshot.MemoryUsage))
 		printKeyValue("CWD      ", snapshot.WorkingDir)
+		if snapshot.Disk != nil {
+			printKeyValue("Disk     ", snapshot.Disk.String())
+		}
+		if snapshot.Load != nil {
+			printKeyValue("Load     ", snapshot.Load.String())
+		}
+		if snapshot.Battery != nil {
+			printKeyValue("Battery  ", snapshot.Battery.String())
+		}
+		if snapshot.Network != nil {
+			printKeyValue("Network  ", snapshot.Network.String())
+		}
 		printNewline()
-	},
+		return nil
+	}),
+}
+
+var sysEnvCmd = &cobra.Command{
+	Use:   "env [filter]",
+	Short: "Show the curated environment, grouped and with secrets masked",
+	Long: `Show the environment variables relevant to vibeaura and your toolchains,
+grouped by category. Values of variables that look like secrets (TOKEN, KEY,
+SECRET, PASSWORD...) are masked. A filter keeps the variables whose name,
+value or group contains it, ignoring case.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		filter := ""
+		if len(args) == 1 {
+			filter = args[0]
+		}
+		report := sys.QueryEnv(filter)
+		if len(report.Entries) == 0 {
+			if filter != "" {
+				return fmt.Errorf("no variables match %q", filter)
+			}
+			printInfo("No allowlisted variables set.")
+			return nil
+		}
+
+		printTitle("🌱", "ENVIRONMENT")
+		var last sys.EnvCategory
+		for _, e := range report.Entries {
+			if e.Category != last {
+				fmt.Fprintln(chatter(), cliMuted.Render(strings.ToUpper(string(e.Category))))
+				last = e.Category
+			}
+			printKeyValue("  "+e.Name, e.Value)
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var sysCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the model response cache",
+}
+
+var sysCacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number of cached responses and the cache size",
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		cache, err := b.ResponseCache()
+		if err != nil {
+			return err
+		}
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		cfg := b.Config()
+		enabled := "off"
+		if cfg.Model.CacheEnabled {
+			enabled = "on"
+		}
+		printTitle("💾", "RESPONSE CACHE")
+		printKeyValueHighlight("Enabled", enabled)
+		printKeyValue("TTL    ", cfg.Model.CacheTTL.String())
+		printKeyValueHighlight("Entries", fmt.Sprintf("%d", stats.Entries))
+		printKeyValue("Size   ", sys.FormatBytes(stats.Bytes))
+		printNewline()
+		return nil
+	}),
+}
+
+var sysCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete every cached model response",
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		cache, err := b.ResponseCache()
+		if err != nil {
+			return err
+		}
+		n, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+		printSuccess(fmt.Sprintf("Cleared %d cached responses", n))
+		return nil
+	}),
 }
 
 var restartCmd = &cobra.Command{
 	Use:   "restart",
 	Short: "Restart the vibeaura application",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		printInfo("Restarting vibeaura...")
 		restartSelf()
-	},
+		return nil
+	}),
 }
 
-func main() {
-	// Install colorized output for Cobra (affects --help, usage, errors)
-	rootCmd.SetOut(NewColorWriter(os.Stdout))
-	rootCmd.SetErr(NewColorWriter(os.Stderr))
-
+func init() {
 	rootCmd.PersistentFlags().StringVar(&resumeStateFile, "resume-state", "", "Internal use: resume state from file")
 	rootCmd.PersistentFlags().MarkHidden("resume-state")
+	rootCmd.Flags().StringVar(&sessionName, "session", "", "Chat session to open (default: one per working directory)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress status output; only results and errors are printed")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Output format: text or json (one JSON object per line on stdout)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExit(ExitUsage, err)
+	})
 
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(authGithubCmd)
 	authCmd.AddCommand(authOllamaCmd)
 	authCmd.AddCommand(authOpenAICmd)
+	authCmd.AddCommand(authAnthropicCmd)
+	authCmd.AddCommand(authGeminiCmd)
+	authCmd.AddCommand(authAzureCmd)
+	authAzureCmd.Flags().StringVar(&azureDeployment, "deployment", "", "Deployment name to offer as a model")
+	authAzureCmd.Flags().StringVar(&azureAPIVersion, "api-version", "", "Azure OpenAI API version (default "+aimodel.DefaultAzureAPIVersion+")")
+	authCmd.AddCommand(authMigrateVaultCmd)
+	authMigrateVaultCmd.Flags().BoolVar(&vaultPassphrase, "passphrase", false, "Protect the vault with a passphrase instead of the machine key")
 
 	rootCmd.AddCommand(modelsCmd)
 	modelsCmd.AddCommand(modelsListCmd)
+	modelsListCmd.Flags().BoolVar(&modelsRefresh, "refresh", false, "Query the providers instead of reusing a recent discovery")
 	modelsCmd.AddCommand(modelsUseCmd)
 
 	rootCmd.AddCommand(sysCmd)
 	sysCmd.AddCommand(sysStatsCmd)
+	sysCmd.AddCommand(sysEnvCmd)
+	sysCmd.AddCommand(sysCacheCmd)
+	sysCacheCmd.AddCommand(sysCacheStatsCmd)
+	sysCacheCmd.AddCommand(sysCacheClearCmd)
 
 	rootCmd.AddCommand(restartCmd)
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+func main() {
+	doctor.Start()
+	defer doctor.Recover()
+	registerPlugins(rootCmd, pluginDir())
+	code := execute(os.Args[1:], os.Stdout, os.Stderr)
+	// Export spans still buffered when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	_ = brain.ShutdownTracing(ctx)
+	cancel()
+	os.Exit(code)
 }
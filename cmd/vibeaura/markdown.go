@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+)
+
+// aiPrefix starts every AI reply in the transcript. The text after it is
+// kept as raw markdown and styled at render time.
+var aiPrefix = aiStyle.Render("Brain: ")
+
+// mdCacheLimit bounds the rendered-message cache; it is simply reset when
+// full.
+const mdCacheLimit = 256
+
+// markdownRenderer renders AI replies with glamour at the viewport width,
+// caching the output since the whole transcript is redrawn on every update.
+type markdownRenderer struct {
+	width int
+	style string
+	tr    *glamour.TermRenderer
+	cache map[string]string
+}
+
+// render returns text as styled markdown wrapped to width. ok is false if
+// the renderer could not be built or failed, so the caller can fall back to
+// plain text.
+func (md *markdownRenderer) render(text string, width int, theme string, cache bool) (out string, ok bool) {
+	if width < 20 {
+		width = 20
+	}
+	style := styles.DarkStyle
+	if theme == "light" {
+		style = styles.LightStyle
+	}
+
+	if md.tr == nil || md.width != width || md.style != style {
+		// Never use glamour's auto style here: it queries the terminal,
+		// which races with Bubble Tea for stdin.
+		tr, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+		if err != nil {
+			return "", false
+		}
+		md.tr, md.width, md.style = tr, width, style
+		md.cache = make(map[string]string)
+	}
+
+	if cached, hit := md.cache[text]; hit {
+		return cached, true
+	}
+	rendered, err := md.tr.Render(text)
+	if err != nil {
+		return "", false
+	}
+	rendered = strings.Trim(rendered, "\n")
+	if cache {
+		if len(md.cache) >= mdCacheLimit {
+			md.cache = make(map[string]string)
+		}
+		md.cache[text] = rendered
+	}
+	return rendered, true
+}
+
+// renderAIMessage styles the body of an AI reply: markdown when enabled,
+// otherwise the plain command highlighting used for other messages. The
+// streaming message changes on every chunk, so it is not cached.
+func (m *model) renderAIMessage(body string, streaming bool) (string, bool) {
+	cfg := m.brain.Config()
+	if cfg == nil || !cfg.UI.Markdown {
+		return aiPrefix + m.styleMessage(body), false
+	}
+	rendered, ok := m.md.render(body, m.viewport.Width, currentTheme.Base, !streaming)
+	if !ok {
+		return aiPrefix + m.styleMessage(body), false
+	}
+	return aiPrefix + "\n" + rendered, true
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+const chatPlaceholder = "Send a message or type / for commands..."
+
+// mcpWizardStep is one question of the /mcp /add wizard.
+type mcpWizardStep int
+
+const (
+	mcpStepName mcpWizardStep = iota
+	mcpStepCommand
+	mcpStepArgs
+	mcpStepConfirm
+)
+
+var mcpWizardSteps = []string{"Name", "Command", "Args", "Confirm"}
+
+// mcpWizard is a /mcp /add in progress: it asks for the server one field
+// at a time and redraws itself in place in the transcript.
+type mcpWizard struct {
+	step   mcpWizardStep
+	srv    sys.MCPServer
+	err    string // Why the last answer was refused
+	msgIdx int    // Transcript message the wizard is drawn in
+}
+
+// mcpWizardMsg carries an answer typed while the wizard is open.
+type mcpWizardMsg struct{ value string }
+
+// startMCPWizard opens the wizard, skipping the name step when one was
+// given on the command line.
+func (m *model) startMCPWizard(name string) (tea.Model, tea.Cmd) {
+	m.mcpWizard = &mcpWizard{msgIdx: len(m.messages)}
+	m.messages = append(m.messages, "")
+	if name != "" {
+		m.answerMCPWizard(name)
+	}
+	m.drawMCPWizard()
+	return m, nil
+}
+
+// answerMCPWizard records an answer to the current step and moves on, or
+// notes why it was refused.
+func (m *model) answerMCPWizard(value string) tea.Cmd {
+	w := m.mcpWizard
+	value = strings.TrimSpace(value)
+	w.err = ""
+	switch w.step {
+	case mcpStepName:
+		if err := brain.ValidateMCPServerName(value); err != nil {
+			w.err = err.Error()
+			return nil
+		}
+		w.srv.Name = value
+	case mcpStepCommand:
+		if value == "" {
+			w.err = "the command is required"
+			return nil
+		}
+		w.srv.Command = value
+	case mcpStepArgs:
+		w.srv.Args = strings.Fields(value)
+	case mcpStepConfirm:
+		switch strings.ToLower(value) {
+		case "", "y", "yes":
+			srv := w.srv
+			m.closeMCPWizard(subtleStyle.Render("Starting " + srv.Name + "..."))
+			return m.addMCPServer(srv)
+		case "n", "no":
+			m.closeMCPWizard(subtleStyle.Render("MCP server not added."))
+		default:
+			w.err = "answer y or n"
+		}
+		return nil
+	}
+	w.step++
+	return nil
+}
+
+// closeMCPWizard replaces the wizard with note and gives the input back to
+// the chat.
+func (m *model) closeMCPWizard(note string) {
+	w := m.mcpWizard
+	m.mcpWizard = nil
+	m.textarea.Placeholder = chatPlaceholder
+	if w.msgIdx < len(m.messages) {
+		m.messages[w.msgIdx] = systemStyle.Render(" MCP ") + "\n" + note
+	} else {
+		m.messages = append(m.messages, systemStyle.Render(" MCP ")+"\n"+note)
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// drawMCPWizard renders the wizard into its transcript message and sets the
+// input placeholder for the current step.
+func (m *model) drawMCPWizard() {
+	w := m.mcpWizard
+	var progress []string
+	for i, name := range mcpWizardSteps {
+		switch step := mcpWizardStep(i); {
+		case step < w.step:
+			progress = append(progress, successStyle.Render("✓ "+name))
+		case step == w.step:
+			progress = append(progress, systemStyle.Render("▸ "+name))
+		default:
+			progress = append(progress, subtleStyle.Render("· "+name))
+		}
+	}
+
+	var question, placeholder string
+	switch w.step {
+	case mcpStepName:
+		question = "What should the server be called? Letters, digits, '.', '_' and '-'."
+		placeholder = "Server name, e.g. github"
+	case mcpStepCommand:
+		question = "Which command starts " + w.srv.Name + "?"
+		placeholder = "Command, e.g. npx"
+	case mcpStepArgs:
+		question = "Arguments for " + w.srv.Command + ", separated by spaces. Leave empty for none."
+		placeholder = "Arguments, e.g. -y @modelcontextprotocol/server-github"
+	case mcpStepConfirm:
+		question = fmt.Sprintf("Name:    %s\nCommand: %s\nSaved to %s\n\nStart and add it? (Y/n)",
+			w.srv.Name, strings.TrimSpace(w.srv.Command+" "+strings.Join(w.srv.Args, " ")), displayPath(m.brain.MCPServerFile(w.srv.Name)))
+		placeholder = "y or n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(systemStyle.Render(" ADD MCP SERVER ") + "  " + strings.Join(progress, subtleStyle.Render("  ")) + "\n")
+	sb.WriteString(helpStyle.Render(question))
+	if w.err != "" {
+		sb.WriteString("\n" + errorStyle.Render(" "+w.err+" "))
+	}
+	sb.WriteString("\n" + subtleStyle.Render("Esc cancels."))
+	m.messages[w.msgIdx] = sb.String()
+	m.textarea.Placeholder = placeholder
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// addMCPServer starts srv and saves it, reporting the outcome as a
+// brain.Response.
+func (m *model) addMCPServer(srv sys.MCPServer) tea.Cmd {
+	return func() tea.Msg {
+		n, err := m.brain.AddMCPServer(srv)
+		if err != nil {
+			return brain.Response{Error: fmt.Errorf("adding MCP server %s: %w", srv.Name, err)}
+		}
+		return brain.Response{Content: systemStyle.Render(" MCP SERVER ADDED ") + "\n" +
+			helpStyle.Render(fmt.Sprintf("%s is running with %d tools available to the agent.", srv.Name, n))}
+	}
+}
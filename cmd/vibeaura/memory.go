@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/spf13/cobra"
+)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Manage the memory database",
+}
+
+var memoryEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt chat history and memory at rest",
+	Long: `Encrypt the memory database (chat sessions, archives and stored facts)
+with AES-256-GCM. A key is created and kept in the vault as
+db_encryption_key; from then on everything written is encrypted. Running it
+again encrypts anything still in plain text.
+
+Without the vault entry the database cannot be read: back up the vault, or
+run "vibeaura memory decrypt" before moving to another machine.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		n, err := brain.New().EncryptMemory()
+		if err != nil {
+			return fmt.Errorf("encrypting memory: %w", err)
+		}
+		printSuccessData(fmt.Sprintf("Memory encrypted (%d value(s) migrated).", n),
+			map[string]interface{}{"encrypted": true, "migrated": n})
+		printWarning("The key is in the vault; losing it makes the memory unreadable.")
+		return nil
+	}),
+}
+
+var memoryDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store chat history and memory as plain text again",
+	Long: `Decrypt the memory database with the key in the vault, then remove the
+key so that new writes are plain text.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		n, err := brain.New().DecryptMemory()
+		if err != nil {
+			return fmt.Errorf("decrypting memory: %w", err)
+		}
+		printSuccessData(fmt.Sprintf("Memory decrypted (%d value(s) migrated).", n),
+			map[string]interface{}{"encrypted": false, "migrated": n})
+		return nil
+	}),
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryEncryptCmd)
+	memoryCmd.AddCommand(memoryDecryptCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NoticeSeverity ranks notifications. Critical notices also interject into
+// the chat transcript.
+type NoticeSeverity string
+
+const (
+	NoticeInfo     NoticeSeverity = "info"
+	NoticeWarning  NoticeSeverity = "warning"
+	NoticeCritical NoticeSeverity = "critical"
+)
+
+// Notice is a deferred system message shown in the notification center.
+type Notice struct {
+	ID       int            `json:"id"`
+	Severity NoticeSeverity `json:"severity"`
+	Source   string         `json:"source"`
+	Message  string         `json:"message"`
+	Time     time.Time      `json:"time"`
+}
+
+// NotificationCenter is a bounded ring buffer of notices.
+type NotificationCenter struct {
+	mu      sync.Mutex
+	notices []Notice
+	max     int
+	nextID  int
+}
+
+func NewNotificationCenter(max int) *NotificationCenter {
+	return &NotificationCenter{max: max, nextID: 1}
+}
+
+// notifications is the single entry point subsystems use to emit notices.
+var notifications = NewNotificationCenter(50)
+
+// noticeStream wakes the TUI when a notice arrives from a background goroutine.
+var noticeStream = make(chan Notice, 20)
+
+type noticeMsg Notice
+
+func waitForNotice() tea.Cmd {
+	return func() tea.Msg {
+		return noticeMsg(<-noticeStream)
+	}
+}
+
+// Notify records a notice and wakes the TUI if it is running.
+func Notify(severity NoticeSeverity, source, message string) {
+	n := notifications.Add(severity, source, message)
+	select {
+	case noticeStream <- n:
+	default:
+	}
+}
+
+// Add appends a notice, evicting the oldest when full.
+func (c *NotificationCenter) Add(severity NoticeSeverity, source, message string) Notice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := Notice{ID: c.nextID, Severity: severity, Source: source, Message: message, Time: time.Now()}
+	c.nextID++
+	c.notices = append(c.notices, n)
+	if len(c.notices) > c.max {
+		c.notices = c.notices[len(c.notices)-c.max:]
+	}
+	return n
+}
+
+// List returns a copy of the current notices, oldest first.
+func (c *NotificationCenter) List() []Notice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Notice(nil), c.notices...)
+}
+
+// Count returns the number of pending notices.
+func (c *NotificationCenter) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.notices)
+}
+
+// Dismiss removes a notice by ID.
+func (c *NotificationCenter) Dismiss(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, n := range c.notices {
+		if n.ID == id {
+			c.notices = append(c.notices[:i], c.notices[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes every notice.
+func (c *NotificationCenter) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notices = nil
+}
+
+// Restore replaces the buffer with persisted notices (hot-swap, session restore).
+func (c *NotificationCenter) Restore(notices []Notice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notices = append([]Notice(nil), notices...)
+	for _, n := range notices {
+		if n.ID >= c.nextID {
+			c.nextID = n.ID + 1
+		}
+	}
+}
+
+// Notification styles, built from the current Theme by applyTheme.
+var noticeBadgeStyle, noticeOverlayStyle lipgloss.Style
+
+func noticeIcon(s NoticeSeverity) string {
+	switch s {
+	case NoticeCritical:
+		return "🚨"
+	case NoticeWarning:
+		return "⚠️ "
+	default:
+		return "ℹ️ "
+	}
+}
+
+// formatNotice renders a notice as a single plain line.
+func formatNotice(n Notice) string {
+	return fmt.Sprintf("%s #%d %s [%s] %s", noticeIcon(n.Severity), n.ID, n.Time.Format("15:04:05"), n.Source, n.Message)
+}
+
+// renderNotificationBadge is the status bar indicator; empty when there is nothing pending.
+func renderNotificationBadge() string {
+	count := notifications.Count()
+	if count == 0 {
+		return ""
+	}
+	return noticeBadgeStyle.Render(fmt.Sprintf("🔔 %d", count))
+}
+
+// renderNotificationOverlay lists notices newest first.
+func renderNotificationOverlay(width int) string {
+	list := notifications.List()
+	var lines []string
+	lines = append(lines, interventionTitleStyle.Render("🔔 Notifications"))
+	if len(list) == 0 {
+		lines = append(lines, subtleStyle.Render("No notifications."))
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		line := formatNotice(list[i])
+		switch list[i].Severity {
+		case NoticeCritical:
+			line = errorStyle.Render(line)
+		case NoticeWarning:
+			line = warningStyle.Render(line)
+		default:
+			line = helpStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, subtleStyle.Render("/notifications /dismiss <id> · /notifications /clear · Esc or Ctrl+N to close"))
+	if width > 4 {
+		return noticeOverlayStyle.Width(width - 4).Render(strings.Join(lines, "\n"))
+	}
+	return noticeOverlayStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// jsonLines decodes stdout produced under --output json, failing the test
+// on any line that is not a JSON object.
+func jsonLines(t *testing.T, stdout string) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.Contains(line, "\x1b[") {
+			t.Errorf("ANSI escape in JSON output: %q", line)
+		}
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("not a JSON object: %q (%v)", line, err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestOutputJSON_Version(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, stderr := runCLI(t, "--output", "json", "version")
+	if code != ExitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("expected nothing on stderr, got %q", stderr)
+	}
+	found := false
+	for _, e := range jsonLines(t, stdout) {
+		if e["level"] == "data" && e["key"] == "Version" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a Version data envelope, got %s", stdout)
+	}
+}
+
+func TestOutputJSON_AuthErrors(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, _ := runCLI(t, "--output", "json", "auth", "openai", "not a key")
+	if code != ExitAuth {
+		t.Errorf("expected exit %d, got %d", ExitAuth, code)
+	}
+	lines := jsonLines(t, stdout)
+	if len(lines) != 1 || lines[0]["level"] != "error" || !strings.Contains(lines[0]["message"].(string), "OpenAI API key") {
+		t.Errorf("expected one error envelope, got %s", stdout)
+	}
+
+	code, stdout, _ = runCLI(t, "--output", "json", "auth", "ollama", "http://127.0.0.1:1")
+	if code != ExitOK {
+		t.Fatalf("expected exit 0, got %d: %s", code, stdout)
+	}
+	lines = jsonLines(t, stdout)
+	if len(lines) != 1 || lines[0]["level"] != "success" {
+		t.Fatalf("expected one success envelope, got %s", stdout)
+	}
+	data, _ := lines[0]["data"].(map[string]interface{})
+	if data["provider"] != "ollama" || data["endpoint"] != "http://127.0.0.1:1" {
+		t.Errorf("expected structured auth data, got %v", lines[0]["data"])
+	}
+}
+
+func TestOutputJSON_ModelsListError(t *testing.T) {
+	scratchHome(t)
+	runCLI(t, "config", "providers.ollama.endpoint", "http://127.0.0.1:1")
+
+	code, stdout, _ := runCLI(t, "--output", "json", "models", "list", "--refresh")
+	if code != ExitUnreachable {
+		t.Errorf("expected exit %d, got %d", ExitUnreachable, code)
+	}
+	lines := jsonLines(t, stdout)
+	if len(lines) == 0 || lines[len(lines)-1]["level"] != "error" {
+		t.Errorf("expected the error as the last envelope, got %s", stdout)
+	}
+}
+
+func TestOutputJSON_InvalidFormat(t *testing.T) {
+	scratchHome(t)
+
+	if code, _, _ := runCLI(t, "--output", "xml", "version"); code != ExitUsage {
+		t.Errorf("expected exit %d for an unknown format, got %d", ExitUsage, code)
+	}
+}
+
+func TestOutputJSON_EmptyListsAreArrays(t *testing.T) {
+	scratchHome(t)
+	historySession, usageSince = "", "" // Flags keep their value between in-process runs
+
+	for _, args := range [][]string{{"history"}, {"plugin", "list"}, {"models", "usage"}} {
+		code, stdout, stderr := runCLI(t, append([]string{"--output", "json"}, args...)...)
+		if code != ExitOK {
+			t.Fatalf("%v: exit %d: %s", args, code, stderr)
+		}
+		if got := strings.TrimSpace(stdout); got != "[]" {
+			t.Errorf("%v: expected [], got %q", args, got)
+		}
+	}
+}
+
+func TestOutputJSON_StorageAndVibes(t *testing.T) {
+	scratchHome(t)
+
+	code, stdout, stderr := runCLI(t, "--output", "json", "storage", "report")
+	if code != ExitOK {
+		t.Fatalf("storage report: exit %d: %s", code, stderr)
+	}
+	lines := jsonLines(t, stdout)
+	if len(lines) != 1 || lines[0]["data_dir"] == nil || lines[0]["total"] == nil {
+		t.Errorf("expected one storage report object, got %s", stdout)
+	}
+
+	code, stdout, stderr = runCLI(t, "--output", "json", "vibes", "list")
+	if code != ExitOK {
+		t.Fatalf("vibes list: exit %d: %s", code, stderr)
+	}
+	lines = jsonLines(t, stdout)
+	if len(lines) != 1 {
+		t.Fatalf("expected one vibes object, got %s", stdout)
+	}
+	if _, ok := lines[0]["vibes"].([]interface{}); !ok {
+		t.Errorf("expected a vibes array, got %s", stdout)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix starts the file name of every plugin executable: a file
+// cmd-deploy in the plugins directory becomes `vibeaura deploy`.
+const pluginPrefix = "cmd-"
+
+// pluginDescriptionTag marks the comment line that gives a plugin's
+// one-line description, looked for in the first few lines of the file.
+const pluginDescriptionTag = "# description:"
+
+// pluginHeaderLines is how far into a plugin the description is looked
+// for, leaving room for a shebang and a licence line.
+const pluginHeaderLines = 5
+
+const pluginsLong = `Plugins add vibeaura subcommands without changing vibeaura itself. Any
+executable file in ~/.vibeauracle/plugins named cmd-<name> runs as
+'vibeaura <name>', receiving every argument after the name and the
+terminal's stdin, stdout and stderr. Its exit status is vibeaura's.
+
+A line starting with "# description:" near the top of the file is shown
+as the command's description.
+
+Plugins run with these extra environment variables:
+  VIBEAURA_VERSION      version of the vibeaura running the plugin
+  VIBEAURA_DATA_DIR     the data directory, ~/.vibeauracle
+  VIBEAURA_CONFIG_FILE  the global config file in the data directory
+
+A plugin whose name is taken by a built-in command is ignored.`
+
+// plugin is an executable found in the plugins directory.
+type plugin struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Shadowed    bool   `json:"shadowed,omitempty"` // A built-in command has its name
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage plugin subcommands from ~/.vibeauracle/plugins",
+	Long:  pluginsLong,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed plugins",
+	Args:  cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		dir := pluginDir()
+		list, err := findPlugins(dir)
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			list[i].Shadowed = isBuiltinCommand(rootCmd, list[i].Name)
+		}
+
+		if jsonOutput() {
+			printJSON(list)
+			return nil
+		}
+		if len(list) == 0 {
+			printInfo("No plugins installed. Add an executable named cmd-<name> to " + dir)
+			return nil
+		}
+		printTitle("🔌", "PLUGINS")
+		for _, p := range list {
+			meta := p.Path
+			if p.Description != "" {
+				meta = p.Description + " · " + meta
+			}
+			if p.Shadowed {
+				meta += " · ignored, a built-in command has this name"
+			}
+			printBulletWithMeta(p.Name, meta)
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+// defaultDataDir is the application data directory, without creating it.
+func defaultDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".vibeauracle"
+	}
+	return filepath.Join(home, ".vibeauracle")
+}
+
+// pluginDir is where plugin executables are installed.
+func pluginDir() string {
+	return filepath.Join(defaultDataDir(), "plugins")
+}
+
+// findPlugins returns the plugins in dir sorted by name. A missing
+// directory holds no plugins.
+func findPlugins(dir string) ([]plugin, error) {
+	entries, err := os.ReadDir(dir)
+	list := []plugin{} // Encodes as [] when there are none
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugins: %w", err)
+	}
+	for _, e := range entries {
+		name, ok := pluginName(e.Name())
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path) // Follows symlinks into the plugin's install
+		if err != nil || !info.Mode().IsRegular() || !isExecutable(path, info) {
+			continue
+		}
+		list = append(list, plugin{Name: name, Path: path, Description: pluginDescription(path)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// pluginName is the subcommand a plugin file provides, if it is named like one.
+func pluginName(file string) (string, bool) {
+	if !strings.HasPrefix(file, pluginPrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, pluginPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" || strings.HasPrefix(name, "-") {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether the plugin file can be run: any execute
+// bit on Unix, a runnable extension on Windows.
+func isExecutable(path string, info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// pluginDescription reads the "# description:" line near the top of a
+// plugin, or returns "" if there is none. Binaries simply have none.
+func pluginDescription(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for i := 0; i < pluginHeaderLines && sc.Scan(); i++ {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, pluginDescriptionTag) {
+			return strings.TrimSpace(strings.TrimPrefix(line, pluginDescriptionTag))
+		}
+	}
+	return ""
+}
+
+// isBuiltinCommand reports whether root already has a command called name.
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerPlugins adds a subcommand to root for every plugin in dir that
+// does not clash with a built-in command.
+func registerPlugins(root *cobra.Command, dir string) {
+	list, err := findPlugins(dir)
+	if err != nil {
+		return // A broken plugins directory must not take the CLI down
+	}
+	for _, p := range list {
+		if isBuiltinCommand(root, p.Name) {
+			continue
+		}
+		root.AddCommand(pluginCommand(p))
+	}
+}
+
+// pluginCommand runs p with the arguments after its name. Flags are left
+// for the plugin to parse, --help included.
+func pluginCommand(p plugin) *cobra.Command {
+	short := p.Description
+	if short == "" {
+		short = "Plugin " + p.Path
+	}
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+			c := exec.Command(p.Path, args...)
+			c.Stdin = os.Stdin
+			c.Stdout = cliOut
+			c.Stderr = cliErr
+			c.Env = append(os.Environ(), pluginEnv()...)
+			err := c.Run()
+			var ee *exec.ExitError
+			if errors.As(err, &ee) {
+				return withExit(ee.ExitCode(), fmt.Errorf("plugin %s exited with status %d", p.Name, ee.ExitCode()))
+			}
+			if err != nil {
+				return fmt.Errorf("running plugin %s: %w", p.Name, err)
+			}
+			return nil
+		}),
+	}
+}
+
+// pluginEnv describes this vibeaura to a plugin.
+func pluginEnv() []string {
+	dir := defaultDataDir()
+	return []string{
+		"VIBEAURA_VERSION=" + Version,
+		"VIBEAURA_DATA_DIR=" + dir,
+		"VIBEAURA_CONFIG_FILE=" + filepath.Join(dir, "config.yaml"),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
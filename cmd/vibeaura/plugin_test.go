@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writePlugin(t *testing.T, name, script string, mode os.FileMode) {
+	t.Helper()
+	dir := pluginDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts here")
+	}
+	scratchHome(t)
+	writePlugin(t, "cmd-greet", "#!/bin/sh\n# description: Say hello\necho hi\n", 0755)
+	writePlugin(t, "cmd-bare", "#!/bin/sh\necho bare\n", 0755)
+	writePlugin(t, "cmd-notes", "not executable\n", 0644)
+	writePlugin(t, "greet.sh", "#!/bin/sh\n", 0755)
+
+	list, err := findPlugins(pluginDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "bare" || list[1].Name != "greet" {
+		t.Fatalf("expected the executable cmd-* files only, got %+v", list)
+	}
+	if list[0].Description != "" || list[1].Description != "Say hello" {
+		t.Errorf("expected the description line after the shebang, got %+v", list)
+	}
+
+	if list, err := findPlugins(filepath.Join(t.TempDir(), "missing")); err != nil || len(list) != 0 {
+		t.Errorf("expected no plugins from a missing directory, got %v (%v)", list, err)
+	}
+}
+
+func TestPluginCommand_RunsWithArgsAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts here")
+	}
+	scratchHome(t)
+	writePlugin(t, "cmd-greet", "#!/bin/sh\n# description: Say hello\necho \"hi $* from $VIBEAURA_DATA_DIR\"\nexit 3\n", 0755)
+	writePlugin(t, "cmd-version", "#!/bin/sh\necho shadowed\n", 0755)
+
+	before := len(rootCmd.Commands())
+	registerPlugins(rootCmd, pluginDir())
+	greet, _, err := rootCmd.Find([]string{"greet"})
+	if err != nil || greet.Name() != "greet" {
+		t.Fatalf("expected a greet command, got %v", err)
+	}
+	t.Cleanup(func() { rootCmd.RemoveCommand(greet) })
+	if len(rootCmd.Commands()) != before+1 {
+		t.Errorf("expected the plugin named like a built-in to be ignored")
+	}
+	if greet.Short != "Say hello" {
+		t.Errorf("expected the description as Short, got %q", greet.Short)
+	}
+
+	code, stdout, _ := runCLI(t, "greet", "--loud", "world")
+	want := "hi --loud world from " + defaultDataDir()
+	if code != 3 || strings.TrimSpace(stdout) != want {
+		t.Errorf("expected %q and exit status 3, got %q (%d)", want, stdout, code)
+	}
+}
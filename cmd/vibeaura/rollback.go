@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/nathfavour/vibeauracle/sys"
 	"github.com/spf13/cobra"
@@ -17,7 +18,7 @@ var rollbackVersion string
 var rollbackCmd = &cobra.Command{
 	Use:   "rollback",
 	Short: "Roll back to a previous version",
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		cm, err := sys.NewConfigManager()
 		if err != nil {
 			return fmt.Errorf("initializing config: %w", err)
@@ -32,11 +33,11 @@ var rollbackCmd = &cobra.Command{
 			return rollbackFromSource(rollbackVersion, cm)
 		}
 		return rollbackBinary(rollbackVersion)
-	},
+	}),
 }
 
 func rollbackBinary(target string) error {
-	fmt.Println("🔍 Searching for previous versions...")
+	printProgress("🔍 Searching for previous versions...\n")
 	data, err := fetchWithFallback(fmt.Sprintf("https://api.github.com/repos/%s/releases", repo))
 	if err != nil {
 		return fmt.Errorf("fetching releases: %w", err)
@@ -46,6 +47,7 @@ func rollbackBinary(target string) error {
 	if err := json.Unmarshal(data, &releases); err != nil {
 		return fmt.Errorf("parsing releases: %w", err)
 	}
+	cacheReleaseTags(releases)
 
 	var targetRelease *releaseInfo
 	if target != "" {
@@ -83,8 +85,8 @@ func rollbackBinary(target string) error {
 	}
 
 	populateActualSHA(targetRelease)
-	fmt.Printf("⏪ Rolling back to %s...\n", targetRelease.TagName)
-	if err := performBinaryUpdate(targetRelease); err != nil {
+	printProgress("⏪ Rolling back to %s...\n", targetRelease.TagName)
+	if err := performBinaryUpdate(targetRelease, newProgressBar(chatter())); err != nil {
 		return err
 	}
 
@@ -93,7 +95,7 @@ func rollbackBinary(target string) error {
 	if cfg, err := cm.Load(); err == nil {
 		cfg.Update.AutoUpdate = false
 		cm.Save(cfg)
-		fmt.Println("ℹ️  Automatic updates disabled. Run 'vibeaura update' manually to re-enable.")
+		printProgress("ℹ️  Automatic updates disabled. Run 'vibeaura update' manually to re-enable.\n")
 	}
 
 	printSuccess("Rollback complete")
@@ -123,7 +125,7 @@ func rollbackFromSource(target string, cm *sys.ConfigManager) error {
 		target = "HEAD^"
 	}
 
-	fmt.Printf("⏪ Rolling back source to %s...\n", target)
+	printProgress("⏪ Rolling back source to %s...\n", target)
 
 	// Checkout target
 	checkoutCmd := exec.Command("git", "-C", sourceRoot, "checkout", target)
@@ -138,16 +140,16 @@ func rollbackFromSource(target string, cm *sys.ConfigManager) error {
 	}
 
 	if !updated {
-		fmt.Println("Already at requested version.")
+		printProgress("Already at requested version.\n")
 		return nil
 	}
 
 	// Disable auto-update after rollback
 	cfg.Update.AutoUpdate = false
 	cm.Save(cfg)
-	fmt.Println("ℹ️  Automatic updates disabled. Run 'vibeaura update' manually to re-enable.")
+	printProgress("ℹ️  Automatic updates disabled. Run 'vibeaura update' manually to re-enable.\n")
 
-	fmt.Println("DONE")
+	printProgress("DONE\n")
 
 	// For rollbacks, we don't hand off the 'rollback' command (to avoid recursion).
 	// Instead, we implicitly hand off a 'version' command to the newly installed binary.
@@ -157,5 +159,52 @@ func rollbackFromSource(target string, cm *sys.ConfigManager) error {
 
 func init() {
 	rollbackCmd.Flags().StringVar(&rollbackVersion, "version", "", "Specific version/commit to roll back to")
+	rollbackCmd.RegisterFlagCompletionFunc("version", completeRollbackVersion)
 	rootCmd.AddCommand(rollbackCmd)
 }
+
+// releasesCacheFile holds the tags of the last release listing in the data
+// dir, newest first, so --version can be completed offline.
+const releasesCacheFile = "releases_cache.json"
+
+// maxCachedReleases is how many recent tags are kept for completion.
+const maxCachedReleases = 20
+
+type releasesCache struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Tags      []string  `json:"tags"`
+}
+
+// cacheReleaseTags records the most recent release tags. Failing to is
+// not worth reporting: it only costs completions.
+func cacheReleaseTags(releases []releaseInfo) {
+	c := releasesCache{UpdatedAt: time.Now()}
+	for _, r := range releases {
+		if r.TagName != "" && len(c.Tags) < maxCachedReleases {
+			c.Tags = append(c.Tags, r.TagName)
+		}
+	}
+	data, err := json.Marshal(c)
+	if err != nil || os.MkdirAll(defaultDataDir(), 0755) != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(defaultDataDir(), releasesCacheFile), data, 0644)
+}
+
+// completeRollbackVersion completes --version from the cached release
+// tags, and offers nothing until a release listing has been fetched.
+func completeRollbackVersion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	data, err := os.ReadFile(filepath.Join(defaultDataDir(), releasesCacheFile))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var c releasesCache
+	json.Unmarshal(data, &c)
+	var out []string
+	for _, tag := range c.Tags {
+		if strings.HasPrefix(tag, toComplete) {
+			out = append(out, tag)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
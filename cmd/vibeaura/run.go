@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runTimeout  time.Duration
+	runModel    string
+	runProvider string
+	runJSON     bool
+	runNoTools  bool
+	runYes      bool
+)
+
+// runResult is the envelope `run --json` prints.
+type runResult struct {
+	Content    string               `json:"content"`
+	Intent     string               `json:"intent,omitempty"`
+	ToolCalls  []brain.ExecutedCall `json:"tool_calls"`
+	DurationMS int64                `json:"duration_ms"`
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run <prompt|->",
+	Short: "Answer one prompt without the TUI and print the response",
+	Long: `Answer one prompt without the TUI, for scripts and CI, and print the
+final response to stdout. With "-" the prompt is read from stdin.
+
+Actions that need approval are denied, which ends the run with an error;
+--yes approves the low-risk ones instead. Nothing waits for input.`,
+	Example: `  vibeaura run "summarize the README"
+  git diff | vibeaura run - --no-tools --model qwen2.5-coder
+  vibeaura run "list the TODOs in this repo" --json --timeout 2m`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		if runJSON {
+			outputFormat = "json"
+			if err := applyOutputFormat(); err != nil {
+				return err
+			}
+		}
+		if runProvider != "" && runModel == "" {
+			return usageErrorf("--provider needs --model")
+		}
+
+		text := args[0]
+		if text == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("reading prompt from stdin: %w", err)
+			}
+			text = string(data)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return usageErrorf("prompt is empty")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		if runTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, runTimeout)
+			defer cancel()
+		}
+
+		b := brain.New()
+		if runModel != "" {
+			b.UseModel(runProvider, runModel)
+		}
+
+		start := time.Now()
+		// Each run is a session of its own, so it neither reads nor adds
+		// to the working directory's chat history.
+		id := fmt.Sprintf("run-%d", start.UnixNano())
+		resp, err := b.Process(ctx, brain.Request{ID: id, SessionID: id, Content: text, NoTools: runNoTools})
+		var ie *tooling.InterventionError
+		for errors.As(err, &ie) {
+			if !autoApprove(ie, runYes) {
+				b.DenyIntervention()
+				hint := ""
+				if !runYes && ie.Risk == "low" {
+					hint = "; pass --yes to approve low-risk actions"
+				}
+				return fmt.Errorf("denied (%s risk): %s%s", riskOrUnknown(ie.Risk), strings.TrimPrefix(ie.Title, "Allow action? "), hint)
+			}
+			printInfo("Approved (low risk): " + strings.TrimPrefix(ie.Title, "Allow action? "))
+			resp, err = b.ResumeIntervention(ctx, tooling.ChoiceApproveOnce, nil)
+		}
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && runTimeout > 0 {
+				return fmt.Errorf("timed out after %s: %w", runTimeout, err)
+			}
+			return err
+		}
+
+		if jsonOutput() {
+			calls := resp.ToolCalls
+			if calls == nil {
+				calls = []brain.ExecutedCall{}
+			}
+			printJSON(runResult{
+				Content:    resp.Content,
+				Intent:     resp.Intent,
+				ToolCalls:  calls,
+				DurationMS: time.Since(start).Milliseconds(),
+			})
+			return nil
+		}
+		fmt.Fprintln(cliOut, resp.Content)
+		return nil
+	}),
+}
+
+// autoApprove decides an intervention in run mode: only low-risk actions,
+// and only with --yes.
+func autoApprove(ie *tooling.InterventionError, yes bool) bool {
+	return yes && ie.Risk == "low"
+}
+
+func riskOrUnknown(risk string) string {
+	if risk == "" {
+		return "unknown"
+	}
+	return risk
+}
+
+func init() {
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "Give up after this long, e.g. 90s or 5m (default: no limit)")
+	runCmd.Flags().StringVar(&runModel, "model", "", "Model to use for this run instead of the configured one")
+	runCmd.Flags().StringVar(&runProvider, "provider", "", "Provider of --model (default: the configured provider)")
+	runCmd.Flags().BoolVar(&runJSON, "json", false, "Print a JSON envelope with the content, intent, tool calls and duration")
+	runCmd.Flags().BoolVar(&runNoTools, "no-tools", false, "Answer directly, without running any tools")
+	runCmd.Flags().BoolVar(&runYes, "yes", false, "Approve low-risk actions instead of denying them")
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+func TestRunCmd_UsageErrors(t *testing.T) {
+	scratchHome(t)
+	defer func() { runProvider, runModel = "", "" }()
+
+	cases := [][]string{
+		{"run", "   "},
+		{"run", "hello", "--provider", "openai"},
+		{"run"},
+	}
+	for _, args := range cases {
+		if code, _, _ := runCLI(t, args...); code != ExitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, code, ExitUsage)
+		}
+		runProvider, runModel = "", ""
+	}
+}
+
+func TestAutoApprove(t *testing.T) {
+	for _, c := range []struct {
+		risk string
+		yes  bool
+		want bool
+	}{
+		{"low", true, true},
+		{"low", false, false},
+		{"medium", true, false},
+		{"high", true, false},
+		{"", true, false},
+	} {
+		ie := &tooling.InterventionError{Title: "Allow action? x", Risk: c.risk}
+		if got := autoApprove(ie, c.yes); got != c.want {
+			t.Errorf("autoApprove(risk=%q, yes=%v) = %v, want %v", c.risk, c.yes, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mdp/qrterminal/v3"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/vault"
+	"github.com/pquerna/otp/totp"
+	"github.com/spf13/cobra"
+)
+
+// totpVaultKey is the vault entry holding the TOTP secret for unlocking
+// vibes, used when security.totp.secret is not set.
+const totpVaultKey = "totp_secret"
+
+var securityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "Manage how the vibes runtime is locked and unlocked",
+}
+
+var securityTOTPSetupCmd = &cobra.Command{
+	Use:   "totp-setup",
+	Short: "Require an authenticator code to unlock vibes",
+	Long: `Generate a TOTP secret, show it as an otpauth:// URI and a QR code for an
+authenticator app, and save it to the vault. From then on unlocking the
+vibes runtime takes the password followed by the current 6-digit code.
+
+Running it again replaces the secret, so re-scan the new QR code.`,
+	Args: cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		cm, err := sys.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("initializing config: %w", err)
+		}
+		cfg, err := cm.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		key, err := totp.Generate(totp.GenerateOpts{Issuer: "vibeauracle", AccountName: totpAccountName()})
+		if err != nil {
+			return fmt.Errorf("generating TOTP secret: %w", err)
+		}
+		v, err := vault.New("vibeauracle", cfg.DataDir)
+		if err != nil {
+			return fmt.Errorf("opening vault: %w", err)
+		}
+		if err := v.Set(totpVaultKey, key.Secret()); err != nil {
+			return fmt.Errorf("saving TOTP secret: %w", err)
+		}
+
+		if jsonOutput() {
+			printSuccessData("TOTP secret saved to the vault.", map[string]string{"uri": key.URL(), "secret": key.Secret()})
+		} else {
+			printTitle("🔐", "TOTP SETUP")
+			printInfo("Scan this with an authenticator app:")
+			qrterminal.GenerateWithConfig(key.URL(), qrterminal.Config{
+				Level:          qrterminal.L,
+				Writer:         cliOut,
+				HalfBlocks:     true,
+				BlackChar:      qrterminal.BLACK_BLACK,
+				WhiteChar:      qrterminal.WHITE_WHITE,
+				BlackWhiteChar: qrterminal.BLACK_WHITE,
+				WhiteBlackChar: qrterminal.WHITE_BLACK,
+				QuietZone:      1,
+			})
+			printKeyValue("URI   ", key.URL())
+			printKeyValue("Secret", key.Secret())
+			printSuccess("TOTP secret saved to the vault. Unlock with your password followed by the current code.")
+		}
+		if cfg.Security.TOTP.Secret != "" {
+			printWarning("security.totp.secret is set in the config and is used instead of the vault entry.")
+		}
+		return nil
+	}),
+}
+
+// totpAccountName labels the secret in authenticator apps.
+func totpAccountName() string {
+	name := os.Getenv("USER")
+	if name == "" {
+		name = "vibeaura"
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+// totpSecret returns the TOTP secret for unlocking vibes: the config
+// setting if there is one, else the vault entry, else "".
+func totpSecret(dataDir string) string {
+	if cm, err := sys.NewConfigManager(); err == nil {
+		if cfg, err := cm.Load(); err == nil && cfg.Security.TOTP.Secret != "" {
+			return cfg.Security.TOTP.Secret
+		}
+	}
+	v, err := vault.New("vibeauracle", dataDir)
+	if err != nil {
+		return ""
+	}
+	secret, _ := v.Get(totpVaultKey)
+	return secret
+}
+
+func init() {
+	securityCmd.AddCommand(securityTOTPSetupCmd)
+	rootCmd.AddCommand(securityCmd)
+}
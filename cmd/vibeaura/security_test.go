@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigTOTPSecret(t *testing.T) {
+	scratchHome(t)
+	if code, _, stderr := runCLI(t, "config", "security.totp.secret", "bad1!"); code != ExitUsage || !strings.Contains(stderr, "invalid TOTP secret") {
+		t.Fatalf("expected an invalid secret to be a usage error, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, "config", "security.totp.secret", "JBSWY3DPEHPK3PXP"); code != ExitOK {
+		t.Fatalf("set failed: %s", stderr)
+	}
+	_, stdout, _ := runCLI(t, "config", "security.totp.secret")
+	if strings.Contains(stdout, "JBSWY3DPEHPK3PXP") || !strings.Contains(stdout, "(set)") {
+		t.Errorf("expected the secret to be masked, got %q", stdout)
+	}
+	if got := totpSecret(t.TempDir()); got != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("expected the config secret to take precedence, got %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionsExportFormat   string
+	sessionsExportArchived bool
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Work with saved chat sessions",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved chat sessions, most recently used first",
+	Args:  cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		list, err := brain.New().ListSessions()
+		if err != nil {
+			return fmt.Errorf("listing sessions: %w", err)
+		}
+		if jsonOutput() {
+			printJSON(list)
+			return nil
+		}
+		printTitle("💬", "SESSIONS")
+		for _, s := range list {
+			meta := "not saved yet"
+			if !s.UpdatedAt.IsZero() {
+				meta = fmt.Sprintf("%s · %s", sys.FormatBytes(s.Size), s.UpdatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			name := s.Name
+			if s.Active {
+				name += " (active here)"
+			}
+			printBulletWithMeta(name, meta)
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <session> <file>",
+	Short: "Save a chat session's transcript as Markdown, JSON or HTML",
+	Long: `Save the transcript of a saved chat session, as /export does in the chat.
+The format follows the file's extension (.md, .json or .html) unless
+--format is given; "-" writes to stdout. --include-archived puts the
+messages /clear archived first.`,
+	Example: `  vibeaura sessions export api-3f9a1c notes.md
+  vibeaura sessions export api-3f9a1c - --format json | jq '.[].role'`,
+	Args: cobra.ExactArgs(2),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		session, file := args[0], args[1]
+		format := sessionsExportFormat
+		if format == "" {
+			format = "markdown"
+			if ext := strings.TrimPrefix(filepath.Ext(file), "."); ext != "" {
+				format = ext
+			}
+		}
+		if _, ok := brain.ExportExtension(format); !ok {
+			return usageErrorf("unknown export format %q (use markdown, json or html)", format)
+		}
+
+		b := brain.New()
+		if file == "-" {
+			data, err := b.ExportSession(session, format, sessionsExportArchived)
+			if err != nil {
+				return err
+			}
+			_, err = cliOut.Write(data)
+			return err
+		}
+		path, size, err := exportSession(b, session, format, file, sessionsExportArchived)
+		if err != nil {
+			return err
+		}
+		printSuccessData(fmt.Sprintf("Exported %s to %s (%s)", session, path, sys.FormatBytes(int64(size))),
+			map[string]interface{}{"path": path, "bytes": size})
+		return nil
+	}),
+}
+
+// exportSession writes session in format to path, with its /clear archives
+// if includeArchived is set, and returns where it went and how many bytes
+// it took. An empty path, or one naming a directory, gets a timestamped
+// file name; an empty path is in the screenshot directory.
+func exportSession(b *brain.Brain, session, format, path string, includeArchived bool) (string, int, error) {
+	ext, ok := brain.ExportExtension(format)
+	if !ok {
+		return "", 0, fmt.Errorf("unknown export format %q (use markdown, json or html)", format)
+	}
+	data, err := b.ExportSession(session, format, includeArchived)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && (path == "~" || strings.HasPrefix(path, "~/")) {
+		path = filepath.Join(home, path[1:])
+	}
+	dir := ""
+	if path == "" {
+		dir = b.GetConfig().UI.ScreenshotDir
+	} else if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dir = path
+	}
+	if dir != "" {
+		path = filepath.Join(dir, fmt.Sprintf("vibeaura_%s_%s%s", session, time.Now().Format("2006-01-02_150405"), ext))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", 0, err
+	}
+	return path, len(data), nil
+}
+
+func init() {
+	sessionsExportCmd.Flags().StringVar(&sessionsExportFormat, "format", "", "markdown, json or html (default: from the file's extension, else markdown)")
+	sessionsExportCmd.Flags().BoolVar(&sessionsExportArchived, "include-archived", false, "Include messages archived by /clear")
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
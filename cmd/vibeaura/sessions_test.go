@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	vcontext "github.com/nathfavour/vibeauracle/context"
+)
+
+func TestSessionsExport(t *testing.T) {
+	scratchHome(t)
+	b := brain.New()
+	b.SetSession("demo")
+	if err := b.StoreSession(brain.SessionTranscript{Messages: []string{
+		userStyle.Render("You: ") + "show me a loop",
+		aiPrefix + "Here:\n```go\nfor {}\n```",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { sessionsExportFormat = "" }()
+
+	out := filepath.Join(t.TempDir(), "demo.json")
+	code, stdout, stderr := runCLI(t, "sessions", "export", "demo", out)
+	if code != ExitOK {
+		t.Fatalf("exit %d: %s%s", code, stdout, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []struct{ Role, Content string }
+	if err := json.Unmarshal(data, &msgs); err != nil || len(msgs) != 2 || msgs[1].Role != "assistant" {
+		t.Fatalf("unexpected json export (%v):\n%s", err, data)
+	}
+
+	code, stdout, _ = runCLI(t, "sessions", "export", "demo", "-", "--format", "md")
+	if code != ExitOK || !strings.Contains(stdout, "### You\n\nshow me a loop") || !strings.Contains(stdout, "```go\nfor {}\n```") {
+		t.Errorf("expected markdown with the code fence on stdout, got exit %d:\n%s", code, stdout)
+	}
+
+	if code, _, _ := runCLI(t, "sessions", "export", "demo", "x.pdf", "--format", "pdf"); code != ExitUsage {
+		t.Errorf("expected a usage error for an unknown format, got exit %d", code)
+	}
+	sessionsExportFormat = ""
+	if code, _, _ := runCLI(t, "sessions", "export", "missing", filepath.Join(t.TempDir(), "m.md")); code != ExitFailure {
+		t.Errorf("expected a failure for a missing session, got exit %d", code)
+	}
+}
+
+func TestSessionsExport_IncludeArchived(t *testing.T) {
+	scratchHome(t)
+	defer func() { sessionsExportFormat, sessionsExportArchived = "", false }()
+
+	m := initialModel(brain.New())
+	m.brain.SetSession("demo")
+	m.messages = []string{userStyle.Render("You: ") + "an old question"}
+	m.clearMessages()
+	m.messages = append(m.messages, userStyle.Render("You: ")+"a new question")
+	if err := m.brain.StoreSession(brain.SessionTranscript{Messages: m.messages}); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := runCLI(t, "sessions", "export", "demo", "-", "--format", "md")
+	if code != ExitOK || strings.Contains(stdout, "an old question") {
+		t.Errorf("expected the archive left out by default, got exit %d:\n%s", code, stdout)
+	}
+	code, stdout, _ = runCLI(t, "sessions", "export", "demo", "-", "--format", "md", "--include-archived")
+	if code != ExitOK || !strings.Contains(stdout, "an old question") || !strings.Contains(stdout, "a new question") {
+		t.Errorf("expected the archive and the transcript, got exit %d:\n%s", code, stdout)
+	}
+
+	if got := m.searchHistory("old question", false); len(got) != 0 {
+		t.Errorf("expected no live match, got %+v", got)
+	}
+	got := m.searchHistory("old question", true)
+	if len(got) != 1 || !got[0].Archived || got[0].SessionName != "demo" {
+		t.Fatalf("expected one archived match in demo, got %+v", got)
+	}
+}
+
+func TestHistory_ListsAndShowsThreads(t *testing.T) {
+	scratchHome(t)
+	defer func() { historySession, historyLimit = "", threadListLimit }()
+
+	code, _, stderr := runCLI(t, "history", "--session", "demo")
+	if code != ExitOK || !strings.Contains(stderr, "No requests recorded") {
+		t.Fatalf("expected an empty history, got exit %d:\n%s", code, stderr)
+	}
+
+	mem, err := vcontext.OpenMemory(filepath.Join(os.Getenv("HOME"), ".vibeauracle", "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem.SaveThread(vcontext.ThreadRecord{SessionID: "demo", ThreadID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed", Prompt: "rename the\nflag", Response: "Renamed --v to --verbose."})
+
+	code, stdout, _ := runCLI(t, "history", "--session", "demo")
+	if code != ExitOK || !strings.Contains(stdout, "1b9d6bcd  rename the flag") {
+		t.Errorf("expected the thread listed by short id, got exit %d:\n%s", code, stdout)
+	}
+	code, stdout, _ = runCLI(t, "history", "1b9d")
+	if code != ExitOK || !strings.Contains(stdout, "rename the\nflag") || !strings.Contains(stdout, "Renamed --v to --verbose.") {
+		t.Errorf("expected the full thread, got exit %d:\n%s", code, stdout)
+	}
+	if code, _, _ := runCLI(t, "history", "--session", "bad name"); code != ExitUsage {
+		t.Errorf("expected a usage error for an invalid session, got exit %d", code)
+	}
+}
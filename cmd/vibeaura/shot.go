@@ -238,7 +238,7 @@ func truncateAnsiLineToWidth(line string, maxCols int, reSGR *regexp.Regexp) str
 
 func parseAnsiLine(line string, re *regexp.Regexp) []ansiPart {
 	var parts []ansiPart
-	currFg := "#FAFAFA"
+	currFg := defaultFg
 	currBold := false
 
 	indices := re.FindAllStringIndex(line, -1)
@@ -248,39 +248,7 @@ func parseAnsiLine(line string, re *regexp.Regexp) []ansiPart {
 		if idx[0] > lastEnd {
 			parts = append(parts, ansiPart{text: line[lastEnd:idx[0]], fg: currFg, bold: currBold})
 		}
-
-		code := line[idx[0]:idx[1]]
-		if code == "\x1b[0m" {
-			currFg = "#FAFAFA"
-			currBold = false
-		} else {
-			// Handle TrueColor: \x1b[38;2;r;g;bm
-			if strings.Contains(code, "38;2;") {
-				clean := strings.Trim(code, "\x1b[m")
-				parts := strings.Split(clean, ";")
-				if len(parts) >= 5 {
-					r, _ := strconv.Atoi(parts[2])
-					g, _ := strconv.Atoi(parts[3])
-					b, _ := strconv.Atoi(parts[4])
-					currFg = fmt.Sprintf("#%02x%02x%02x", r, g, b)
-				}
-			} else if strings.Contains(code, "38;5;") {
-				currFg = "#7D56F4"
-			} else {
-				// Map basic colors only if not TrueColor
-				if strings.Contains(code, "35") {
-					currFg = "#EE6FF8"
-				} else if strings.Contains(code, "36") {
-					currFg = "#04D9FF"
-				} else if strings.Contains(code, "34") {
-					currFg = "#7D56F4"
-				}
-			}
-
-			if strings.Contains(code, ";1m") || strings.Contains(code, "[1;") || code == "\x1b[1m" {
-				currBold = true
-			}
-		}
+		currFg, currBold = applySGR(line[idx[0]+2:idx[1]-1], currFg, currBold)
 		lastEnd = idx[1]
 	}
 
@@ -291,6 +259,88 @@ func parseAnsiLine(line string, re *regexp.Regexp) []ansiPart {
 	return parts
 }
 
+// defaultFg is the screenshot's default text colour.
+const defaultFg = "#FAFAFA"
+
+// ansi16 is the palette for the 16 basic colours. Blue, magenta and cyan
+// use the TUI's own accents so screenshots match the app.
+var ansi16 = [16]string{
+	"#2E2E2E", "#FF5F56", "#27C93F", "#FFBD2E", "#7D56F4", "#EE6FF8", "#04D9FF", "#D0D0D0",
+	"#6C6C6C", "#FF8787", "#87FF87", "#FFFF87", "#AFA0FF", "#FFAFFF", "#87FFFF", "#FFFFFF",
+}
+
+// applySGR applies the parameters of one SGR sequence (the part between
+// "ESC[" and "m") to the current foreground and weight. Background colours
+// are parsed past but not drawn.
+func applySGR(params string, fg string, bold bool) (string, bool) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		n, err := strconv.Atoi(codes[i])
+		if err != nil {
+			n = 0 // An empty parameter means reset
+		}
+		switch {
+		case n == 0:
+			fg, bold = defaultFg, false
+		case n == 1:
+			bold = true
+		case n == 22:
+			bold = false
+		case n >= 30 && n <= 37:
+			fg = ansi16[n-30]
+		case n >= 90 && n <= 97:
+			fg = ansi16[n-90+8]
+		case n == 39:
+			fg = defaultFg
+		case n == 38 || n == 48:
+			color, used := extendedColor(codes[i+1:])
+			if n == 38 && color != "" {
+				fg = color
+			}
+			i += used
+		}
+	}
+	return fg, bold
+}
+
+// extendedColor reads the "5;n" or "2;r;g;b" that follows 38 or 48 and
+// returns the colour and how many parameters it took.
+func extendedColor(codes []string) (string, int) {
+	num := func(i int) int {
+		if i >= len(codes) {
+			return 0
+		}
+		v, _ := strconv.Atoi(codes[i])
+		return v
+	}
+	if len(codes) == 0 {
+		return "", 0
+	}
+	switch codes[0] {
+	case "5":
+		return xterm256(num(1)), 2
+	case "2":
+		return fmt.Sprintf("#%02x%02x%02x", num(1)&0xff, num(2)&0xff, num(3)&0xff), 4
+	}
+	return "", 0
+}
+
+// xterm256 returns the hex colour of an xterm 256-colour palette index.
+func xterm256(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return defaultFg
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	}
+	gray := 8 + (n-232)*10
+	return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+}
+
 // convertToPNG attempts to convert SVG to PNG using system tools
 func convertToPNG(svgPath, pngPath string) error {
 	// Try rsvg-convert (common on Linux)
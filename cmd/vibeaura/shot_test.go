@@ -0,0 +1,47 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseAnsiLine(t *testing.T) {
+	re := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	line := "plain \x1b[38;5;203;1mkeyword\x1b[22m \x1b[38;2;1;2;3mrgb\x1b[48;5;236m on bg\x1b[0m \x1b[35mmagenta\x1b[m end"
+	got := parseAnsiLine(line, re)
+	want := []ansiPart{
+		{"plain ", defaultFg, false},
+		{"keyword", "#ff5f5f", true},
+		{" ", "#ff5f5f", false},
+		{"rgb", "#010203", false},
+		{" on bg", "#010203", false},
+		{" ", defaultFg, false},
+		{"magenta", "#EE6FF8", false},
+		{" end", defaultFg, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d parts, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("part %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestScreenshotKeepsCodeHighlighting(t *testing.T) {
+	var md markdownRenderer
+	out, ok := md.render("```go\nfunc main() { return }\n```", 60, "dark", false)
+	if !ok {
+		t.Fatal("render failed")
+	}
+	svg := convertAnsiToSVG(out)
+	colors := map[string]bool{}
+	for _, m := range regexp.MustCompile(`fill:(#[0-9a-fA-F]{6})`).FindAllStringSubmatch(svg, -1) {
+		colors[strings.ToLower(m[1])] = true
+	}
+	if len(colors) < 3 {
+		t.Errorf("expected the highlighted code to keep several colours in the screenshot, got %v", colors)
+	}
+}
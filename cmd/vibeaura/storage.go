@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/daemon"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/spf13/cobra"
+)
+
+var storageApply bool
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Inspect and clean up the vibeauracle data directory",
+}
+
+var storageReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show disk usage by category",
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		report, err := b.StorageReport()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			printJSON(struct {
+				brain.StorageReport
+				Total int64 `json:"total"`
+			}{report, report.Total()})
+			return nil
+		}
+		printTitle("💾", "STORAGE: "+report.DataDir)
+		fmt.Fprintln(cliOut, cliLabel.Render(fmt.Sprintf("%-14s %12s %8s", "CATEGORY", "SIZE", "ITEMS")))
+		for _, c := range report.Categories {
+			fmt.Fprintf(cliOut, "%-14s %12s %8d\n", c.Category, sys.FormatBytes(c.Size), c.Count)
+		}
+		fmt.Fprintf(cliOut, "%-14s %12s\n", "total", sys.FormatBytes(report.Total()))
+		printNewline()
+		return nil
+	}),
+}
+
+var storageGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Apply retention policies (dry run unless --apply)",
+	Long: `Applies the retention policies in the storage: config section.
+
+Without --apply this only lists what would be deleted. The active session,
+storage.keep_sessions, and anything modified in the last few minutes are
+always kept, so it is safe to run while the TUI is open.`,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		report, err := b.StorageReport()
+		if err != nil {
+			return err
+		}
+		plan := b.PlanStorageGC(report)
+		if len(plan) == 0 {
+			printSuccess("Nothing to collect.")
+			return nil
+		}
+
+		var total int64
+		for _, it := range plan {
+			printBulletWithMeta(it.Path, fmt.Sprintf("%s · %s · %s", it.Category, sys.FormatBytes(it.Size), it.ModTime.Format("2006-01-02")))
+			total += it.Size
+		}
+		printNewline()
+
+		if !storageApply {
+			printInfo(fmt.Sprintf("Dry run: %d items, %s would be reclaimed. Re-run with --apply to delete.", len(plan), sys.FormatBytes(total)))
+			return nil
+		}
+
+		reclaimed, err := runStorageGC(b, plan)
+		if errors.Is(err, sys.ErrStorageLocked) {
+			return withExit(ExitFailure, fmt.Errorf("another storage gc is running; try again later"))
+		}
+		printSuccess("Reclaimed " + sys.FormatBytes(reclaimed))
+		return err
+	}),
+}
+
+// runStorageGC deletes the plan and reports the result to the doctor.
+func runStorageGC(b *brain.Brain, plan []sys.StorageItem) (int64, error) {
+	reclaimed, err := b.ApplyStorageGC(plan)
+	if errors.Is(err, sys.ErrStorageLocked) {
+		return 0, err
+	}
+	msg := fmt.Sprintf("Storage GC reclaimed %s from %d items", sys.FormatBytes(reclaimed), len(plan))
+	if err != nil {
+		doctor.Send("storage", doctor.SignalWarning, msg+" with errors: "+err.Error(), nil)
+	} else {
+		doctor.Send("storage", doctor.SignalInfo, msg, nil)
+	}
+	return reclaimed, err
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the background service (scheduled storage GC)",
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		b := brain.New()
+		cfg := b.Config()
+
+		sock := filepath.Join(cfg.DataDir, "daemon.sock")
+		os.Remove(sock) // Left over from an unclean shutdown
+		d := daemon.New(sock)
+		d.OnJobError = func(job string, err error) {
+			doctor.Send(job, doctor.SignalError, err.Error(), nil)
+		}
+
+		if cfg.Storage.GCIntervalHours > 0 {
+			d.Schedule(daemon.Job{
+				Name:  "storage",
+				Every: time.Duration(cfg.Storage.GCIntervalHours) * time.Hour,
+				Run: func(ctx context.Context) error {
+					report, err := b.StorageReport()
+					if err != nil {
+						return err
+					}
+					if plan := b.PlanStorageGC(report); len(plan) > 0 {
+						_, err = runStorageGC(b, plan)
+						if errors.Is(err, sys.ErrStorageLocked) {
+							return nil // A manual run beat us to it
+						}
+					}
+					return err
+				},
+			})
+		}
+		return d.Start()
+	}),
+}
+
+func init() {
+	storageGCCmd.Flags().BoolVar(&storageApply, "apply", false, "Actually delete the listed items")
+	storageCmd.AddCommand(storageReportCmd)
+	storageCmd.AddCommand(storageGCCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
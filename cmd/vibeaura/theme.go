@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nathfavour/vibeauracle/vibes"
+)
+
+// Theme is the palette every TUI style is built from. Colours are
+// #RRGGBB hex strings.
+type Theme struct {
+	Base       string `json:"base"`       // dark or light; also picks the markdown and code styles
+	Primary    string `json:"primary"`    // Titles, system badges, active borders, suggestions
+	Secondary  string `json:"secondary"`  // AI label
+	Accent     string `json:"accent"`     // User label
+	Background string `json:"background"` // Suggestion list background
+	Foreground string `json:"foreground"` // Text on coloured badges
+	Success    string `json:"success"`
+	Warning    string `json:"warning"` // Interventions and notifications
+	Error      string `json:"error"`
+	Muted      string `json:"muted"`     // Secondary text and help
+	Border     string `json:"border"`    // Inactive borders and rules
+	Highlight  string `json:"highlight"` // #tags, quoted strings
+	Contrast   string `json:"contrast"`  // Dark text on bright badges
+}
+
+var (
+	darkTheme = Theme{
+		Base:       "dark",
+		Primary:    "#7D56F4",
+		Secondary:  "#04D9FF",
+		Accent:     "#EE6FF8",
+		Background: "#222222",
+		Foreground: "#FAFAFA",
+		Success:    "#00D787",
+		Warning:    "#FF8C00",
+		Error:      "#FF0000",
+		Muted:      "#626262",
+		Border:     "#444444",
+		Highlight:  "#FFD700",
+		Contrast:   "#1A1A1A",
+	}
+
+	lightTheme = Theme{
+		Base:       "light",
+		Primary:    "#5B3CC4",
+		Secondary:  "#0077AA",
+		Accent:     "#B8329F",
+		Background: "#E4E4E4",
+		Foreground: "#FAFAFA",
+		Success:    "#008A5A",
+		Warning:    "#C25E00",
+		Error:      "#D70000",
+		Muted:      "#767676",
+		Border:     "#BBBBBB",
+		Highlight:  "#9A6B00",
+		Contrast:   "#1A1A1A",
+	}
+)
+
+// currentTheme is the palette the styles were last built from.
+var currentTheme = darkTheme
+
+// hexColor matches the colours vibes may set, as isValidColor in the vibes
+// package checks them.
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// resolveTheme builds the palette for the ui.theme setting: "dark" (the
+// default), "light", or the path of a JSON file naming a base and the
+// colours to change. Colours from active vibes go on top of the base and
+// the file's go on top of those. Values that are not #RRGGBB are skipped
+// with a warning.
+func resolveTheme(setting string, fromVibes vibes.ThemeConfig) (Theme, []string) {
+	var warnings []string
+	var file Theme
+	switch setting {
+	case "", "dark":
+	case "light":
+		file.Base = "light"
+	default:
+		data, err := os.ReadFile(setting)
+		if err == nil {
+			err = json.Unmarshal(data, &file)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui.theme: %v; using the dark theme", err))
+			file = Theme{}
+		}
+	}
+
+	t := darkTheme
+	if file.Base == "light" {
+		t = lightTheme
+	} else if file.Base != "" && file.Base != "dark" {
+		warnings = append(warnings, fmt.Sprintf("ui.theme: unknown base %q; using dark", file.Base))
+	}
+
+	overlay := func(source string, pairs ...any) {
+		for i := 0; i < len(pairs); i += 3 {
+			name, dst, value := pairs[i].(string), pairs[i+1].(*string), pairs[i+2].(string)
+			switch {
+			case value == "":
+			case hexColor.MatchString(value):
+				*dst = value
+			default:
+				warnings = append(warnings, fmt.Sprintf("%s: %s %q is not a #RRGGBB colour; ignored", source, name, value))
+			}
+		}
+	}
+	overlay("vibes theme",
+		"primary", &t.Primary, fromVibes.Primary,
+		"secondary", &t.Secondary, fromVibes.Secondary,
+		"accent", &t.Accent, fromVibes.Accent,
+		"background", &t.Background, fromVibes.Background,
+		"foreground", &t.Foreground, fromVibes.Foreground,
+		"success", &t.Success, fromVibes.Success,
+		"warning", &t.Warning, fromVibes.Warning,
+		"error", &t.Error, fromVibes.Error,
+	)
+	overlay("ui.theme",
+		"primary", &t.Primary, file.Primary,
+		"secondary", &t.Secondary, file.Secondary,
+		"accent", &t.Accent, file.Accent,
+		"background", &t.Background, file.Background,
+		"foreground", &t.Foreground, file.Foreground,
+		"success", &t.Success, file.Success,
+		"warning", &t.Warning, file.Warning,
+		"error", &t.Error, file.Error,
+		"muted", &t.Muted, file.Muted,
+		"border", &t.Border, file.Border,
+		"highlight", &t.Highlight, file.Highlight,
+		"contrast", &t.Contrast, file.Contrast,
+	)
+	return t, warnings
+}
+
+func init() {
+	applyTheme(darkTheme)
+}
+
+// applyTheme rebuilds every style from t. Text already rendered keeps the
+// colours it was rendered with.
+func applyTheme(t Theme) {
+	currentTheme = t
+	c := func(hex string) lipgloss.Color { return lipgloss.Color(hex) }
+
+	highlight = c(t.Primary)
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(c(t.Primary)).Padding(0, 1)
+	userStyle = lipgloss.NewStyle().Foreground(c(t.Accent)).Bold(true)
+	aiStyle = lipgloss.NewStyle().Foreground(c(t.Secondary)).Bold(true)
+	systemStyle = lipgloss.NewStyle().Foreground(c(t.Foreground)).Background(c(t.Primary)).Padding(0, 1).Bold(true)
+	subtleStyle = lipgloss.NewStyle().Foreground(c(t.Muted))
+	errorStyle = lipgloss.NewStyle().Foreground(c(t.Error)).Bold(true)
+	successStyle = lipgloss.NewStyle().Foreground(c(t.Success))
+	warningStyle = lipgloss.NewStyle().Foreground(c(t.Warning))
+	helpStyle = lipgloss.NewStyle().Foreground(c(t.Muted))
+	ruleStyle = lipgloss.NewStyle().Foreground(c(t.Border))
+	tagStyle = lipgloss.NewStyle().Foreground(c(t.Highlight)).Bold(true).Italic(true)
+	suggestionStyle = lipgloss.NewStyle().Foreground(c(t.Primary)).Background(c(t.Background))
+	selectedSuggestionStyle = lipgloss.NewStyle().Foreground(c(t.Foreground)).Background(c(t.Primary)).Bold(true)
+	treeStyle = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).BorderForeground(c(t.Border)).PaddingLeft(2)
+	activeBorder = lipgloss.NewStyle().Border(lipgloss.ThickBorder(), true).BorderForeground(c(t.Primary))
+	inactiveBorder = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), true).BorderForeground(c(t.Border))
+	interventionBoxStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(c(t.Warning)).Padding(1, 2).MarginTop(1)
+	calcStyle = lipgloss.NewStyle().Foreground(c(t.Contrast)).Background(c(t.Success)).Padding(0, 1).Bold(true)
+	interventionTitleStyle = lipgloss.NewStyle().Foreground(c(t.Warning)).Bold(true)
+	interventionChoiceStyle = lipgloss.NewStyle().Foreground(c(t.Muted)).PaddingLeft(2)
+	interventionSelectedStyle = lipgloss.NewStyle().Foreground(c(t.Foreground)).Background(c(t.Warning)).Bold(true).PaddingLeft(2)
+	noticeBadgeStyle = lipgloss.NewStyle().Foreground(c(t.Foreground)).Background(c(t.Warning)).Padding(0, 1).Bold(true)
+	noticeOverlayStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(c(t.Warning)).Padding(0, 1)
+}
+
+// themeChanged wakes the TUI when the vibes runtime reloads, so colours
+// from vibes installed or removed meanwhile are picked up.
+var themeChanged = make(chan struct{}, 1)
+
+type themeChangedMsg struct{}
+
+func waitForThemeChange() tea.Cmd {
+	return func() tea.Msg {
+		<-themeChanged
+		return themeChangedMsg{}
+	}
+}
+
+// watchTheme asks for a theme reload whenever rt dispatches
+// HookOnConfigChange.
+func watchTheme(rt *vibes.Runtime) {
+	rt.Dispatcher.RegisterHandler(vibes.HookOnConfigChange, func(*vibes.HookContext) {
+		select {
+		case themeChanged <- struct{}{}:
+		default: // A reload is already pending
+		}
+	})
+}
+
+// loadTheme resolves the theme from ui.theme and the active vibes and
+// applies it. It returns the problems found along the way.
+func (m *model) loadTheme() []string {
+	var warnings []string
+	var fromVibes vibes.ThemeConfig
+	if rt, err := m.vibesRuntime(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("vibes theme: %v", err))
+	} else {
+		fromVibes = rt.GetTheme()
+	}
+	setting := ""
+	if cfg := m.brain.Config(); cfg != nil {
+		setting = cfg.UI.Theme
+	}
+	t, more := resolveTheme(setting, fromVibes)
+	applyTheme(t)
+	return append(warnings, more...)
+}
+
+// reloadTheme re-merges the theme and redraws with it, noting any warnings
+// in the chat.
+func (m *model) reloadTheme(announce bool) {
+	warnings := m.loadTheme()
+	if announce {
+		m.messages = append(m.messages, systemStyle.Render(" THEME ")+" "+helpStyle.Render("Reloaded"))
+	}
+	m.addThemeWarnings(warnings)
+	m.updatePerusalContent()
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+func (m *model) addThemeWarnings(warnings []string) {
+	for _, w := range warnings {
+		m.messages = append(m.messages, warningStyle.Render("⚠️  "+w))
+	}
+}
+
+// noticeThemeWarnings defers startup warnings to the notification center,
+// where they do not end up in the saved transcript.
+func (m *model) noticeThemeWarnings(warnings []string) {
+	for _, w := range warnings {
+		m.pushNotice(notifications.Add(NoticeWarning, "theme", w))
+	}
+}
+
+func (m *model) handleThemeCommand(parts []string) (tea.Model, tea.Cmd) {
+	sub := "/show"
+	if len(parts) > 1 {
+		sub = parts[1]
+	}
+	switch sub {
+	case "/show":
+		m.messages = append(m.messages, systemStyle.Render(" THEME ")+"\n"+renderTheme(currentTheme))
+	case "/reload", "reload":
+		m.reloadTheme(true)
+		return m, nil
+	default:
+		m.messages = append(m.messages, systemStyle.Render(" THEME ")+"\n"+helpStyle.Render("Usage: /theme /show · /theme /reload"))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// renderTheme lists the palette with a swatch per colour.
+func renderTheme(t Theme) string {
+	rows := []string{helpStyle.Render("Base: " + t.Base)}
+	for _, c := range []struct{ name, hex string }{
+		{"primary", t.Primary}, {"secondary", t.Secondary}, {"accent", t.Accent},
+		{"background", t.Background}, {"foreground", t.Foreground},
+		{"success", t.Success}, {"warning", t.Warning}, {"error", t.Error},
+		{"muted", t.Muted}, {"border", t.Border}, {"highlight", t.Highlight}, {"contrast", t.Contrast},
+	} {
+		swatch := lipgloss.NewStyle().Background(lipgloss.Color(c.hex)).Render("    ")
+		rows = append(rows, fmt.Sprintf("%s %s %s", swatch, helpStyle.Render(fmt.Sprintf("%-10s", c.name)), c.hex))
+	}
+	return strings.Join(rows, "\n")
+}
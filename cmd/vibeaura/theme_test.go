@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/vibes"
+)
+
+func TestResolveTheme(t *testing.T) {
+	got, warnings := resolveTheme("", vibes.ThemeConfig{})
+	if got != darkTheme || len(warnings) != 0 {
+		t.Errorf("expected the dark defaults, got %+v %v", got, warnings)
+	}
+	if got, _ := resolveTheme("light", vibes.ThemeConfig{}); got != lightTheme {
+		t.Errorf("expected the light defaults, got %+v", got)
+	}
+
+	fromVibes := vibes.ThemeConfig{Primary: "#112233", Accent: "#445566", Error: "red"}
+	got, warnings = resolveTheme("dark", fromVibes)
+	if got.Primary != "#112233" || got.Accent != "#445566" {
+		t.Errorf("expected vibe colours over the defaults, got %+v", got)
+	}
+	if got.Error != darkTheme.Error {
+		t.Errorf("expected a non-hex colour to be ignored, got %s", got.Error)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `error "red"`) {
+		t.Errorf("expected one warning about the error colour, got %v", warnings)
+	}
+
+	// A theme file starts from its base and wins over vibes.
+	file := filepath.Join(t.TempDir(), "theme.json")
+	if err := os.WriteFile(file, []byte(`{"base": "light", "primary": "#ABCDEF", "border": "#12345"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, warnings = resolveTheme(file, fromVibes)
+	if got.Base != "light" || got.Primary != "#ABCDEF" || got.Accent != "#445566" || got.Border != lightTheme.Border {
+		t.Errorf("expected light base, file primary, vibe accent and default border, got %+v", got)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("expected warnings for the vibe error and file border colours, got %v", warnings)
+	}
+
+	got, warnings = resolveTheme(filepath.Join(t.TempDir(), "missing.json"), vibes.ThemeConfig{})
+	if got != darkTheme || len(warnings) != 1 {
+		t.Errorf("expected a missing file to fall back to dark with a warning, got %+v %v", got, warnings)
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	defer applyTheme(darkTheme)
+
+	custom := darkTheme
+	custom.Primary = "#123456"
+	applyTheme(custom)
+	if currentTheme.Primary != "#123456" || activeBorder.GetBorderTopForeground() != highlight || highlight != "#123456" {
+		t.Errorf("expected styles rebuilt from the new primary, got %v", highlight)
+	}
+}
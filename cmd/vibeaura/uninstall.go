@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -16,14 +17,13 @@ var uninstallCmd = &cobra.Command{
 	Long: `Uninstall the vibeaura binary. 
 By default, the application data directory (~/.vibeauracle) is preserved. 
 Use the --clean flag to wipe everything.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		printTitle("🗑️", "UNINSTALL VIBE AURACLE")
 
 		// 1. Get binary path
 		exePath, err := os.Executable()
 		if err != nil {
-			printError("Could not determine binary path: " + err.Error())
-			return
+			return fmt.Errorf("could not determine binary path: %w", err)
 		}
 
 		// 2. Get data directory
@@ -42,9 +42,10 @@ Use the --clean flag to wipe everything.`,
 		}
 
 		// 3. Remove binary
+		var errs []error
 		printInfo("Removing binary: " + exePath)
 		if err := os.Remove(exePath); err != nil {
-			printError("Failed to remove binary: " + err.Error())
+			errs = append(errs, fmt.Errorf("failed to remove binary: %w", err))
 			// We continue to data wiping even if binary removal fails (e.g. permission issues)
 		} else {
 			printBullet("Binary removed successfully")
@@ -55,7 +56,7 @@ Use the --clean flag to wipe everything.`,
 			if _, err := os.Stat(dataDir); err == nil {
 				printInfo("Wiping data directory: " + dataDir)
 				if err := os.RemoveAll(dataDir); err != nil {
-					printError("Failed to wipe data: " + err.Error())
+					errs = append(errs, fmt.Errorf("failed to wipe data: %w", err))
 				} else {
 					printBullet("Application data wiped successfully")
 				}
@@ -66,10 +67,15 @@ Use the --clean flag to wipe everything.`,
 			printInfo("Keeping application data at: " + dataDir)
 		}
 
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+
 		printDone()
 		printNewline()
-		fmt.Println(cliMuted.Render("Note: If you established any shells integrations manually, you may need to remove them from your shell profile."))
-	},
+		fmt.Fprintln(chatter(), cliMuted.Render("Note: If you established any shells integrations manually, you may need to remove them from your shell profile."))
+		return nil
+	}),
 }
 
 func init() {
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -17,6 +18,7 @@ import (
 	"syscall"
 
 	"github.com/charmbracelet/lipgloss"
+	aimodel "github.com/nathfavour/vibeauracle/model"
 	"github.com/nathfavour/vibeauracle/sys"
 	"golang.org/x/mod/semver"
 
@@ -72,18 +74,37 @@ func getResilientClient() *http.Client {
 	}
 }
 
+// errAssetNotFound is returned by fetchWithFallback when the server
+// answers 404.
+var errAssetNotFound = errors.New("not found")
+
 // fetchWithFallback attempts to fetch a URL using Go's http client,
 // and falls back to 'curl' if a network error occurs.
 func fetchWithFallback(url string) ([]byte, error) {
+	return fetchWithProgress(url, nil)
+}
+
+// fetchWithProgress is fetchWithFallback reporting the body's download
+// progress to onProgress, when set. The curl fallback reports nothing.
+func fetchWithProgress(url string, onProgress func(read, total int64)) ([]byte, error) {
 	client := getResilientClient()
 	resp, err := client.Get(url)
 	if err == nil {
 		defer resp.Body.Close()
 		if resp.StatusCode == http.StatusOK {
-			return io.ReadAll(resp.Body)
+			if onProgress == nil {
+				return io.ReadAll(resp.Body)
+			}
+			return io.ReadAll(&progressReader{r: resp.Body, total: resp.ContentLength, onProgress: onProgress})
 		}
 		// If it's a 404 or other error, we don't want to fallback to curl
 		// if the Go client successfully contacted the server.
+		if resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return nil, fmt.Errorf("server returned status: %d: %w", resp.StatusCode, aimodel.ErrRateLimited)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("server returned status: %d: %w", resp.StatusCode, errAssetNotFound)
+		}
 		if resp.StatusCode != http.StatusOK {
 			return nil, fmt.Errorf("server returned status: %d", resp.StatusCode)
 		}
@@ -212,6 +233,7 @@ func getLatestRelease(channel string) (*releaseInfo, error) {
 	if err := json.Unmarshal(data, &releases); err != nil {
 		return nil, err
 	}
+	cacheReleaseTags(releases)
 
 	if len(releases) == 0 {
 		return nil, fmt.Errorf("no releases found")
@@ -524,7 +546,7 @@ func checkUpdateSilent() {
 					cm.Save(cfg)
 				}
 			} else if latest != nil {
-				err := performBinaryUpdate(latest)
+				err := performBinaryUpdate(latest, nil)
 				if err == nil {
 					restartSelf()
 				} else {
@@ -551,21 +573,21 @@ func checkUpdateSilent() {
 			displayCurCommit = displayCurCommit[:7]
 		}
 
-		fmt.Println()
-		fmt.Printf("✨ %s %s %s\n",
+		printProgress("\n")
+		printProgress("✨ %s %s %s\n",
 			styleNew.Render("A new update is available on the"),
 			styleChannel.Render(channel),
 			styleNew.Render("channel!"),
 		)
-		fmt.Printf("   %s %s (%s) %s %s\n",
+		printProgress("   %s %s (%s) %s %s\n",
 			styleDim.Render("Latest:"), displayLatestSHA, latestTag,
 			styleDim.Render("Current:"), displayCurCommit,
 		)
-		fmt.Printf("   👉 Run %s %s\n",
+		printProgress("   👉 Run %s %s\n",
 			styleCmd.Render("vibeaura update"),
 			styleDim.Render("to stay on the bleeding edge."),
 		)
-		fmt.Println()
+		printProgress("\n")
 	}
 }
 
@@ -583,57 +605,122 @@ func getPlatform() (string, string) {
 	return goos, goarch
 }
 
-func performBinaryUpdate(latest *releaseInfo) error {
+// performBinaryUpdate downloads and installs latest, reporting download
+// progress to onProgress when it is set.
+func performBinaryUpdate(latest *releaseInfo, onProgress func(read, total int64)) error {
 	cm, _ := sys.NewConfigManager()
 	cfg, _ := cm.Load()
 	verbose := cfg.Update.Verbose
 
-	// Determine target asset name
-	goos, goarch := getPlatform()
-	targetAsset := fmt.Sprintf("vibeaura-%s-%s", goos, goarch)
-	if goos == "windows" {
-		targetAsset += ".exe"
+	tmp, err := downloadRelease(latest, verbose, onProgress)
+	if err != nil {
+		return err
 	}
+	defer os.Remove(tmp)
 
-	var downloadURL string
-	for _, asset := range latest.Assets {
-		if asset.Name == targetAsset {
-			downloadURL = asset.BrowserDownloadURL
-			break
-		}
-	}
+	exePath, _ := os.Executable()
+	return installBinary(tmp, exePath)
+}
 
-	// Fallback for synthesized releaseInfo (from git ls-remote) where assets are not populated
-	if downloadURL == "" && latest.TagName != "" {
-		downloadURL = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, latest.TagName, targetAsset)
+// downloadRelease fetches this platform's binary from the release and
+// verifies it, returning the path of a temporary copy for installBinary.
+// The caller removes it.
+func downloadRelease(latest *releaseInfo, verbose bool, onProgress func(read, total int64)) (string, error) {
+	goos, goarch := getPlatform()
+	base := fmt.Sprintf("vibeaura-%s-%s", goos, goarch)
+	targetAsset := base
+	if goos == "windows" {
+		targetAsset += ".exe"
 	}
 
+	downloadURL := releaseAssetURL(latest, targetAsset)
 	if downloadURL == "" {
-		return fmt.Errorf("no binary for %s/%s", goos, goarch)
+		return "", fmt.Errorf("could not find binary for %s/%s in release %s", goos, goarch, latest.TagName)
 	}
 
 	if verbose {
-		fmt.Printf("Downloading %s...\n", targetAsset)
+		printProgress("Downloading %s...\n", targetAsset)
 	}
-
-	data, err := fetchWithFallback(downloadURL)
+	data, err := fetchWithProgress(downloadURL, onProgress)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("downloading update: %w", err)
+	}
+	verified, err := verifyRelease(latest, base, targetAsset, data)
+	if err != nil {
+		if errors.Is(err, errBadDownload) {
+			recordFailedRelease(latest)
+		}
+		return "", err
+	}
+	if verbose {
+		printProgress("🔐 Verified %s: %s\n", targetAsset, verified)
 	}
 
 	tmpFile, err := os.CreateTemp("", "vibeaura-update-*")
 	if err != nil {
-		return err
+		return "", fmt.Errorf("creating temp file: %w", err)
 	}
-	defer os.Remove(tmpFile.Name())
-
 	if _, err := tmpFile.Write(data); err != nil {
-		return err
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("saving update: %w", err)
 	}
 	tmpFile.Close()
+	return tmpFile.Name(), nil
+}
 
-	exePath, _ := os.Executable()
-	return installBinary(tmpFile.Name(), exePath)
+// progressReader reports how much of a body of total bytes has been read.
+// total is -1 when the server sent no Content-Length.
+type progressReader struct {
+	r          io.Reader
+	read       int64
+	total      int64
+	onProgress func(read, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if n > 0 || err == io.EOF {
+		p.onProgress(p.read, p.total)
+	}
+	return n, err
+}
+
+// downloadPercent is read as a percentage of total, or -1 when the size is
+// unknown.
+func downloadPercent(read, total int64) int {
+	if total <= 0 {
+		return -1
+	}
+	return int(min(read*100/total, 100))
+}
+
+// newProgressBar draws a download progress bar on w, redrawing it in
+// place as the percentage changes and ending the line when done.
+func newProgressBar(w io.Writer) func(read, total int64) {
+	const width = 30
+	last := -2
+	return func(read, total int64) {
+		pct := downloadPercent(read, total)
+		if pct == last && pct >= 0 {
+			return
+		}
+		last = pct
+		if pct < 0 {
+			fmt.Fprintf(w, "\r⬇️  %s downloaded\033[K", formatMB(read))
+			return
+		}
+		filled := width * pct / 100
+		fmt.Fprintf(w, "\r⬇️  [%s%s] %3d%% %s / %s\033[K", strings.Repeat("#", filled), strings.Repeat("-", width-filled), pct, formatMB(read), formatMB(total))
+		if pct == 100 {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func formatMB(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
 }
 
 func installBinary(srcPath, dstPath string) error {
@@ -642,7 +729,7 @@ func installBinary(srcPath, dstPath string) error {
 	verbose := cfg.Update.Verbose
 
 	if verbose {
-		fmt.Printf("Installing binary to %s...\n", dstPath)
+		printProgress("Installing binary to %s...\n", dstPath)
 	}
 
 	// Ensure the destination directory exists
@@ -690,7 +777,7 @@ func installBinary(srcPath, dstPath string) error {
 
 	if needsSudo {
 		if verbose {
-			fmt.Printf("Permission denied or busy. Trying with sudo to install to %s...\n", dstPath)
+			printProgress("Permission denied or busy. Trying with sudo to install to %s...\n", dstPath)
 		}
 
 		// Use 'rm -f' first to avoid ETXTBSY (Text file busy)
@@ -699,13 +786,13 @@ func installBinary(srcPath, dstPath string) error {
 
 		sudoCp := exec.Command("sudo", "cp", srcPath, dstPath)
 		if verbose {
-			sudoCp.Stdout = os.Stdout
+			sudoCp.Stdout = os.Stderr
 			sudoCp.Stderr = os.Stderr
 		}
 		sudoCp.Stdin = os.Stdin
 		if err := sudoCp.Run(); err != nil {
 			if verbose {
-				fmt.Println("FAILED")
+				printProgress("FAILED\n")
 			}
 			return fmt.Errorf("replacing binary with sudo: %w", err)
 		}
@@ -769,8 +856,8 @@ func restartSelf() {
 // using the provided arguments.
 func restartWithArgs(args []string) {
 	if runtime.GOOS == "windows" {
-		fmt.Println("\n✅ Operation complete. Please restart vibeaura.")
-		os.Exit(0)
+		printProgress("\n✅ Operation complete. Please restart vibeaura.\n")
+		os.Exit(ExitOK)
 	}
 
 	exe, err := os.Executable()
@@ -782,15 +869,15 @@ func restartWithArgs(args []string) {
 			exe = goBinPath
 		}
 	} else {
-		fmt.Printf("Error getting executable path for restart: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(cliErr, "Error getting executable path for restart: %v\n", err)
+		os.Exit(ExitFailure)
 	}
 
 	// Hand off to the new binary while preserving environment and target arguments
 	err = syscall.Exec(exe, args, os.Environ())
 	if err != nil {
-		fmt.Printf("Error handing off to new binary: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(cliErr, "Error handing off to new binary: %v\n", err)
+		os.Exit(ExitFailure)
 	}
 }
 
@@ -877,11 +964,11 @@ func ensureInstalled() {
 		}
 
 		if runtime.GOOS == "windows" {
-			fmt.Println("\n👉 Since you are on Windows, please close this window and run 'vibeaura' from a new terminal.")
-			fmt.Println("Press Enter to exit...")
+			printProgress("\n👉 Since you are on Windows, please close this window and run 'vibeaura' from a new terminal.\n")
+			printProgress("Press Enter to exit...\n")
 			var dummy string
 			fmt.Scanln(&dummy)
-			os.Exit(0)
+			os.Exit(ExitOK)
 		}
 		restartWithArgs(os.Args)
 	}
@@ -927,13 +1014,13 @@ func ensureGoBinInPath(goBin string) bool {
 
 	if runtime.GOOS == "windows" {
 		// On Windows, we use PowerShell to update the User PATH.
-		fmt.Printf("📝 Adding %s to your Windows User PATH...\n", goBin)
+		printProgress("📝 Adding %s to your Windows User PATH...\n", goBin)
 		// We use a PowerShell snippet to safely append if not present
 		cmdStr := fmt.Sprintf(`$oldPath = [System.Environment]::GetEnvironmentVariable("Path", "User"); if ($oldPath -notlike "*%s*") { [System.Environment]::SetEnvironmentVariable("Path", "$oldPath;%s", "User") }`, goBin, goBin)
 		err := exec.Command("powershell", "-Command", cmdStr).Run()
 		if err != nil {
-			fmt.Printf("⚠️  Failed to update Windows PATH automatically: %v\n", err)
-			fmt.Printf("👉 Please manually add %s to your PATH.\n", goBin)
+			printProgress("⚠️  Failed to update Windows PATH automatically: %v\n", err)
+			printProgress("👉 Please manually add %s to your PATH.\n", goBin)
 			return false
 		}
 		return true
@@ -966,7 +1053,7 @@ func ensureGoBinInPath(goBin string) bool {
 	}
 
 	if updated {
-		fmt.Printf("📝 Added %s to PATH in shell profiles. Please restart your terminal or run: source ~/.zshrc (or your config)\n", tildaPath)
+		printProgress("📝 Added %s to PATH in shell profiles. Please restart your terminal or run: source ~/.zshrc (or your config)\n", tildaPath)
 	}
 	return updated
 }
@@ -1000,11 +1087,11 @@ func updateFromSource(branch string, cm *sys.ConfigManager) (bool, error) {
 
 	if _, err := os.Stat(filepath.Join(sourceRoot, ".git")); os.IsNotExist(err) {
 		if verbose {
-			fmt.Printf("Cloning %s branch to %s...\n", branch, sourceRoot)
+			printProgress("Cloning %s branch to %s...\n", branch, sourceRoot)
 		}
 		cloneCmd := exec.Command("git", "clone", "-b", branch, "https://github.com/"+repo+".git", sourceRoot)
 		if verbose {
-			cloneCmd.Stdout = os.Stdout
+			cloneCmd.Stdout = os.Stderr
 			cloneCmd.Stderr = os.Stderr
 		}
 		if err := cloneCmd.Run(); err != nil {
@@ -1013,7 +1100,7 @@ func updateFromSource(branch string, cm *sys.ConfigManager) (bool, error) {
 		}
 	} else {
 		if verbose {
-			fmt.Printf("Fetching updates for %s...\n", branch)
+			printProgress("Fetching updates for %s...\n", branch)
 		}
 		fetchCmd := exec.Command("git", "-C", sourceRoot, "fetch", "origin", branch)
 		if err := fetchCmd.Run(); err != nil {
@@ -1039,11 +1126,11 @@ func updateFromSource(branch string, cm *sys.ConfigManager) (bool, error) {
 		}
 
 		if verbose {
-			fmt.Printf("Updating local source in %s...\n", sourceRoot)
+			printProgress("Updating local source in %s...\n", sourceRoot)
 		}
 		pullCmd := exec.Command("git", "-C", sourceRoot, "pull", "origin", branch)
 		if verbose {
-			pullCmd.Stdout = os.Stdout
+			pullCmd.Stdout = os.Stderr
 			pullCmd.Stderr = os.Stderr
 		}
 		if err := pullCmd.Run(); err != nil {
@@ -1062,7 +1149,7 @@ func buildAndInstallFromSource(sourceRoot, branch string, cm *sys.ConfigManager)
 	verbose := cfg.Update.Verbose
 
 	if verbose {
-		fmt.Println("Building from source...")
+		printProgress("Building from source...\n")
 	}
 
 	// Get current commit SHA for the local build
@@ -1087,22 +1174,22 @@ func buildAndInstallFromSource(sourceRoot, branch string, cm *sys.ConfigManager)
 	buildCmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
 
 	if verbose {
-		buildCmd.Stdout = os.Stdout
+		buildCmd.Stdout = os.Stderr
 		buildCmd.Stderr = os.Stderr
 	}
 
 	if err := buildCmd.Run(); err != nil {
 		goos, _ := getPlatform()
 		if goos == "android" {
-			fmt.Println("\n🛠️  Build failed. Attempting to upgrade Go toolchain automatically...")
+			printProgress("\n🛠️  Build failed. Attempting to upgrade Go toolchain automatically...\n")
 			upgradeCmd := exec.Command("pkg", "upgrade", "golang", "-y")
-			upgradeCmd.Stdout = os.Stdout
+			upgradeCmd.Stdout = os.Stderr
 			upgradeCmd.Stderr = os.Stderr
 			if err := upgradeCmd.Run(); err == nil {
-				fmt.Println("✅ Go upgraded. Retrying build...")
+				printProgress("✅ Go upgraded. Retrying build...\n")
 				if err := buildCmd.Run(); err == nil {
 					if !verbose {
-						fmt.Println("DONE")
+						printProgress("DONE\n")
 					}
 					exePath, _ := os.Executable()
 					if err := installBinary(buildOut, exePath); err != nil {
@@ -1114,11 +1201,11 @@ func buildAndInstallFromSource(sourceRoot, branch string, cm *sys.ConfigManager)
 		}
 
 		if verbose {
-			fmt.Println("\n❌ Build failed! This usually happens if your installed Go version is older than the one required by the project.")
+			printProgress("\n❌ Build failed! This usually happens if your installed Go version is older than the one required by the project.\n")
 			if goos == "android" {
-				fmt.Println("👉 Try running: pkg upgrade golang (on Termux)")
+				printProgress("👉 Try running: pkg upgrade golang (on Termux)\n")
 			} else {
-				fmt.Println("👉 Try updating Go on your desktop.")
+				printProgress("👉 Try updating Go on your desktop.\n")
 			}
 		}
 		commitCmd := exec.Command("git", "-C", sourceRoot, "rev-parse", "HEAD")
@@ -1149,7 +1236,7 @@ var (
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update vibeaura to the latest version",
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		cm, err := sys.NewConfigManager()
 		if err != nil {
 			return fmt.Errorf("initializing config: %w", err)
@@ -1166,7 +1253,7 @@ var updateCmd = &cobra.Command{
 			if err := cm.Save(cfg); err != nil {
 				return fmt.Errorf("re-enabling auto-update: %w", err)
 			}
-			fmt.Println("🔄  Manual update detected. Automatic updates have been re-enabled.")
+			printProgress("🔄  Manual update detected. Automatic updates have been re-enabled.\n")
 		}
 
 		useBeta := betaFlag || cfg.Update.Beta
@@ -1178,7 +1265,7 @@ var updateCmd = &cobra.Command{
 				return fmt.Errorf("--list-assets is only supported for the pre-built update pipeline (source updates do not use assets)")
 			}
 
-			fmt.Println("Fetching latest release assets...")
+			printProgress("Fetching latest release assets...\n")
 			reqChannel := ""
 			if useBeta {
 				reqChannel = "beta"
@@ -1188,11 +1275,18 @@ var updateCmd = &cobra.Command{
 				return fmt.Errorf("checking for updates: %w", err)
 			}
 
-			fmt.Printf("\n📦 Assets for release %s:\n", latest.TagName)
+			names := make([]string, 0, len(latest.Assets))
 			for _, asset := range latest.Assets {
-				fmt.Printf("  - %s\n", asset.Name)
+				names = append(names, asset.Name)
+			}
+			if jsonOutput() {
+				printJSON(names)
+				return nil
+			}
+			printProgress("\n📦 Assets for release %s:\n", latest.TagName)
+			for _, name := range names {
+				fmt.Fprintln(cliOut, name)
 			}
-			fmt.Println()
 			return nil
 		}
 
@@ -1202,7 +1296,7 @@ var updateCmd = &cobra.Command{
 		}
 
 		if verbose {
-			fmt.Printf("Current version: %s (commit: %s)\n", Version, curCommit)
+			printProgress("Current version: %s (commit: %s)\n", Version, curCommit)
 		}
 
 		if buildFromSource {
@@ -1212,28 +1306,28 @@ var updateCmd = &cobra.Command{
 			}
 
 			if !verbose {
-				fmt.Printf("🔄  Updating to %s... ", branch)
+				printProgress("🔄  Updating to %s... ", branch)
 			} else {
 				if useBeta {
-					fmt.Println("🚀 Entering Beta Mode: Building bleeding-edge from master...")
+					printProgress("🚀 Entering Beta Mode: Building bleeding-edge from master...\n")
 				} else {
-					fmt.Println("🛠️ Building from source (release branch)...")
+					printProgress("🛠️ Building from source (release branch)...\n")
 				}
 			}
 
 			updated, err := updateFromSource(branch, cm)
 			if err != nil {
 				if !verbose {
-					fmt.Println("FAILED")
+					printProgress("FAILED\n")
 				}
 				return err
 			}
 
 			if !updated {
 				if !verbose {
-					fmt.Println("ALREADY UP TO DATE")
+					printProgress("ALREADY UP TO DATE\n")
 				} else {
-					fmt.Println("vibeaura is already up to date on this branch.")
+					printProgress("vibeaura is already up to date on this branch.\n")
 				}
 				return nil
 			}
@@ -1246,13 +1340,13 @@ var updateCmd = &cobra.Command{
 				}
 				printSuccess("Upgraded to " + displaySHA + ": " + getCommitMessage(remoteSHA))
 			} else {
-				fmt.Printf("Successfully updated to bleeding-edge %s from source!\n", branch)
+				printProgress("Successfully updated to bleeding-edge %s from source!\n", branch)
 			}
 			restartSelf()
 			return nil
 		}
 
-		fmt.Println("Checking for updates...")
+		printProgress("Checking for updates...\n")
 		reqChannel := ""
 		if useBeta {
 			reqChannel = "beta"
@@ -1264,12 +1358,12 @@ var updateCmd = &cobra.Command{
 
 		isDev := strings.HasPrefix(Version, "dev")
 		if !isUpdateAvailable(latest, false) && !isDev {
-			fmt.Println("vibeaura is already up to date!")
+			printProgress("vibeaura is already up to date!\n")
 			return nil
 		}
 
 		if isDev {
-			fmt.Printf("Dev build detected. Force-updating to latest stable binary (%s)...\n", latest.TagName)
+			printProgress("Dev build detected. Force-updating to latest stable binary (%s)...\n", latest.TagName)
 		}
 
 		remoteVer := latest.ActualSHA
@@ -1280,8 +1374,8 @@ var updateCmd = &cobra.Command{
 		// Check if this commit has previously failed
 		for _, failed := range cfg.Update.FailedCommits {
 			if failed == remoteVer {
-				fmt.Printf("\n⚠️ The latest version (%s) has previously failed to install/build and is likely unstable.\n", remoteVer[:7])
-				fmt.Println("👉 Use '--beta' or '--source' flags to force a retry if you've fixed the issue.")
+				printProgress("\n⚠️ The latest version (%s) has previously failed to install/build and is likely unstable.\n", remoteVer[:7])
+				printProgress("👉 Use '--beta' or '--source' flags to force a retry if you've fixed the issue.\n")
 				return nil
 			}
 		}
@@ -1291,72 +1385,33 @@ var updateCmd = &cobra.Command{
 			displaySHA = displaySHA[:7]
 		}
 
-		fmt.Printf("New version available: %s (commit: %s)\n", latest.TagName, displaySHA)
-
-		// Determine target asset name
-		goos, goarch := getPlatform()
-		targetAsset := fmt.Sprintf("vibeaura-%s-%s", goos, goarch)
-		if goos == "windows" {
-			targetAsset += ".exe"
-		}
-
-		var downloadURL string
-		for _, asset := range latest.Assets {
-			if asset.Name == targetAsset {
-				downloadURL = asset.BrowserDownloadURL
-				break
-			}
-		}
-
-		// Fallback for synthesized releaseInfo (from git ls-remote) where assets are not populated
-		if downloadURL == "" && latest.TagName != "" {
-			downloadURL = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, latest.TagName, targetAsset)
-		}
-
-		if downloadURL == "" {
-			return fmt.Errorf("could not find binary for %s/%s in release %s", goos, goarch, latest.TagName)
-		}
-
-		if verbose {
-			fmt.Printf("Downloading %s...\n", targetAsset)
-		}
+		printProgress("New version available: %s (commit: %s)\n", latest.TagName, displaySHA)
 
-		// Download to temp file
-		tmpFile, err := os.CreateTemp("", "vibeaura-update-*")
+		tmp, err := downloadRelease(latest, verbose, newProgressBar(chatter()))
 		if err != nil {
-			return fmt.Errorf("creating temp file: %w", err)
-		}
-		defer os.Remove(tmpFile.Name())
-
-		resp, err := http.Get(downloadURL)
-		if err != nil {
-			return fmt.Errorf("downloading update: %w", err)
-		}
-		defer resp.Body.Close()
-
-		if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-			return fmt.Errorf("saving update: %w", err)
+			return err
 		}
-		tmpFile.Close()
+		defer os.Remove(tmp)
 
 		exePath, _ := os.Executable()
-		if err := installBinary(tmpFile.Name(), exePath); err != nil {
+		if err := installBinary(tmp, exePath); err != nil {
 			return err
 		}
 
 		if verbose {
-			fmt.Printf("Successfully updated to %s!\n", latest.TagName)
+			printProgress("Successfully updated to %s!\n", latest.TagName)
 		} else {
 			printSuccess("Upgraded to " + displaySHA + ": " + getCommitMessage(remoteVer))
 		}
 
 		restartSelf()
 		return nil
-	},
+	}),
 }
 
 func init() {
 	updateCmd.Flags().BoolVar(&betaFlag, "beta", false, "Install bleeding-edge version from source (master branch)")
 	updateCmd.Flags().BoolVar(&listAssetsFlag, "list-assets", false, "List all assets available in the latest release")
+	updateCmd.Flags().BoolVar(&skipVerifyFlag, "skip-verify", false, "Install without checking the release checksum and signature")
 	rootCmd.AddCommand(updateCmd)
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestProgressBar(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	var out bytes.Buffer
+	r := &progressReader{r: iotest.OneByteReader(strings.NewReader(body)), total: int64(len(body)), onProgress: newProgressBar(&out)}
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != body {
+		t.Fatalf("body changed in transit: %v", err)
+	}
+
+	// One redraw per percentage, finished with a newline.
+	if n := strings.Count(out.String(), "\r"); n != 101 {
+		t.Errorf("expected 101 redraws, got %d", n)
+	}
+	if !strings.HasSuffix(out.String(), "] 100% 0.0 MB / 0.0 MB\033[K\n") {
+		t.Errorf("unexpected final line %q", out.String()[strings.LastIndex(out.String(), "\r"):])
+	}
+
+	if downloadPercent(10, -1) != -1 || downloadPercent(5, 10) != 50 {
+		t.Error("unexpected downloadPercent")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	aimodel "github.com/nathfavour/vibeauracle/model"
+	"github.com/spf13/cobra"
+)
+
+var usageSince string
+
+var modelsUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show requests and tokens sent to each model",
+	Long: `Show how many requests were sent to each provider and model, with the
+characters sent and received and, where the provider reports them, the
+tokens used. Usage is counted per day; --since covers the days from that
+long ago onwards.
+
+Set model.rate_limit.<provider> to cap a provider's requests per minute.`,
+	Example: "  vibeaura models usage --since 7d",
+	Args:    cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		var since time.Time
+		if usageSince != "" {
+			age, err := parseAge(usageSince)
+			if err != nil {
+				return usageErrorf("--since: %v", err)
+			}
+			since = time.Now().Add(-age)
+		}
+		store, err := brain.New().UsageStore()
+		if err != nil {
+			return err
+		}
+		list, err := store.Summary(since)
+		if err != nil {
+			return fmt.Errorf("reading usage: %w", err)
+		}
+
+		if jsonOutput() {
+			printJSON(list)
+			return nil
+		}
+		if len(list) == 0 {
+			printInfo("No model requests recorded yet.")
+			return nil
+		}
+		title := "MODEL USAGE"
+		if usageSince != "" {
+			title += " (last " + usageSince + ")"
+		}
+		printTitle("📊", title)
+		lines := usageTable(list)
+		fmt.Fprintln(cliOut, cliLabel.Render(lines[0]))
+		for _, line := range lines[1:] {
+			fmt.Fprintln(cliOut, line)
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+// usageTable lays out usage one model per line, after a header line.
+// Token counts show "-" for providers that do not report them.
+func usageTable(list []aimodel.UsageSummary) []string {
+	const row = "%-36s %8s %6s %10s %10s %10s %10s  %s"
+	lines := []string{fmt.Sprintf(row, "MODEL", "REQUESTS", "ERRORS", "SENT", "RECEIVED", "TOKENS IN", "TOKENS OUT", "LAST")}
+	for _, u := range list {
+		name := u.Provider
+		if u.Model != "" {
+			name += ": " + u.Model
+		}
+		lines = append(lines, strings.TrimRight(fmt.Sprintf(row, name,
+			fmt.Sprint(u.Requests), fmt.Sprint(u.Errors),
+			formatChars(u.PromptChars), formatChars(u.ResponseChars),
+			formatTokens(u.PromptTokens), formatTokens(u.CompletionTokens),
+			u.Last.Format("2006-01-02 15:04")), " "))
+	}
+	return lines
+}
+
+// formatChars shortens a character count, e.g. 12.3k.
+func formatChars(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprint(n)
+}
+
+func formatTokens(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return formatChars(n)
+}
+
+func init() {
+	modelsCmd.AddCommand(modelsUsageCmd)
+	modelsUsageCmd.Flags().StringVar(&usageSince, "since", "", "Only count usage from this long ago onwards (e.g. 7d, 12h)")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	aimodel "github.com/nathfavour/vibeauracle/model"
+)
+
+func TestCLI_ModelsUsage(t *testing.T) {
+	scratchHome(t)
+	dataDir := filepath.Join(os.Getenv("HOME"), ".vibeauracle")
+	os.MkdirAll(dataDir, 0755)
+	store, err := aimodel.OpenUsageStore(filepath.Join(dataDir, aimodel.UsageFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	store.Record(aimodel.UsageRecord{Provider: "openai", Model: "gpt-4o", At: now, PromptChars: 12000, PromptTokens: 3000, CompletionTokens: 250})
+	store.Record(aimodel.UsageRecord{Provider: "ollama", Model: "llama3", At: now.AddDate(0, 0, -30), Failed: true})
+	store.Close()
+
+	code, stdout, stderr := runCLI(t, "models", "usage", "--since", "7d")
+	if code != ExitOK {
+		t.Fatalf("models usage failed (%d): %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "openai: gpt-4o") || !strings.Contains(stdout, "12.0k") || !strings.Contains(stdout, "3000") {
+		t.Errorf("expected the openai row, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "llama3") {
+		t.Errorf("expected usage older than --since left out, got:\n%s", stdout)
+	}
+
+	usageSince = "" // Flags keep their value between in-process runs
+	code, stdout, _ = runCLI(t, "--output", "json", "models", "usage")
+	if code != ExitOK || !strings.Contains(stdout, `"provider":"ollama"`) || !strings.Contains(stdout, `"errors":1`) {
+		t.Errorf("expected every model as JSON without --since, got %q", stdout)
+	}
+
+	if code, _, _ := runCLI(t, "models", "usage", "--since", "soon"); code != ExitUsage {
+		t.Errorf("expected exit %d for a bad --since, got %d", ExitUsage, code)
+	}
+}
+
+func TestCLI_ConfigRateLimit(t *testing.T) {
+	scratchHome(t)
+
+	if code, _, stderr := runCLI(t, "config", "model.rate_limit.openai", "30"); code != ExitOK {
+		t.Fatalf("config set failed (%d): %s", code, stderr)
+	}
+	if _, stdout, _ := runCLI(t, "config", "model.rate_limit.openai"); strings.TrimSpace(stdout) != "30" {
+		t.Errorf("expected 30, got %q", stdout)
+	}
+	if code, _, _ := runCLI(t, "config", "model.rate_limit.openai", "-1"); code != ExitUsage {
+		t.Errorf("expected exit %d for a negative limit, got %d", ExitUsage, code)
+	}
+	runCLI(t, "config", "model.rate_limit.openai", "0")
+	if _, stdout, _ := runCLI(t, "config", "model.rate_limit.openai"); strings.TrimSpace(stdout) != "0" {
+		t.Errorf("expected the limit removed, got %q", stdout)
+	}
+}
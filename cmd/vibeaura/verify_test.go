@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("vibeaura")
+	sum := "2c4d2a5b7d4a39a0a62f7e9fb4f3bd6bc65ec2bd5a4ad6f2e3a2c63cff3e1e3d"
+
+	if err := verifyChecksum(data, []byte("not-hex  vibeaura-linux-amd64\n")); err == nil {
+		t.Error("expected a malformed checksum file to be rejected")
+	}
+	if err := verifyChecksum(data, []byte(sum+"  vibeaura-linux-amd64\n")); !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("expected a mismatch, got %v", err)
+	}
+	if err := verifyChecksum([]byte{}, []byte("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\n")); err != nil {
+		t.Errorf("expected the empty input's hash to match, got %v", err)
+	}
+}
+
+func TestVerifyRelease_ChecksumSources(t *testing.T) {
+	goos, goarch := getPlatform()
+	base := fmt.Sprintf("vibeaura-%s-%s", goos, goarch)
+	binary := append([]byte("\x7fELF"), bytes.Repeat([]byte{0}, minBinarySize)...)
+	sum := sha256.Sum256(binary)
+	line := hex.EncodeToString(sum[:]) + "  " + base + "\n"
+
+	served := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := served[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	latest := &releaseInfo{TagName: "v9.9.9"}
+	for _, name := range []string{base + ".sha256", checksumsFile} {
+		latest.Assets = append(latest.Assets, struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		}{name, srv.URL + "/" + name})
+	}
+
+	served[checksumsFile] = "0000000000000000000000000000000000000000000000000000000000000000  vibeaura-plan9-mips\n" + line
+	if got, err := verifyRelease(latest, base, base, binary); err != nil || !strings.Contains(got, checksumsFile) {
+		t.Errorf("expected checksums.txt to be used without a sidecar, got %q, %v", got, err)
+	}
+
+	served[base+".sha256"] = line
+	if _, err := verifyRelease(latest, base, base, append(binary, 0)); !errors.Is(err, errBadDownload) || !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("expected a changed binary to fail verification, got %v", err)
+	}
+
+	delete(served, base+".sha256")
+	delete(served, checksumsFile)
+	if got, err := verifyRelease(latest, base, base, binary); goos == "linux" && (err != nil || !strings.Contains(got, "no checksum published")) {
+		t.Errorf("expected an old release to pass the executable check, got %q, %v", got, err)
+	}
+	if _, err := verifyRelease(latest, base, base, []byte("<html>rate limited</html>")); !errors.Is(err, errBadDownload) {
+		t.Errorf("expected a page of HTML to be refused, got %v", err)
+	}
+}
+
+func TestCheckExecutable(t *testing.T) {
+	pad := bytes.Repeat([]byte{0}, minBinarySize)
+	cases := []struct {
+		goos  string
+		magic string
+		ok    bool
+	}{
+		{"linux", "\x7fELF", true},
+		{"android", "\x7fELF", true},
+		{"darwin", "\xcf\xfa\xed\xfe", true},
+		{"darwin", "\x7fELF", false},
+		{"windows", "MZ", true},
+		{"linux", "MZ", false},
+	}
+	for _, c := range cases {
+		if err := checkExecutable(append([]byte(c.magic), pad...), c.goos); (err == nil) != c.ok {
+			t.Errorf("%s with %q: got %v, want ok=%v", c.goos, c.magic, err, c.ok)
+		}
+	}
+	if err := checkExecutable([]byte("\x7fELF"), "linux"); err == nil {
+		t.Error("expected a truncated binary to be refused")
+	}
+}
+
+func TestDownloadRelease_RecordsFailedVerification(t *testing.T) {
+	scratchHome(t)
+	goos, goarch := getPlatform()
+	asset := fmt.Sprintf("vibeaura-%s-%s", goos, goarch)
+	if goos == "windows" {
+		asset += ".exe"
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".sha256") {
+			io.WriteString(w, strings.Repeat("ab", sha256.Size)+"\n")
+			return
+		}
+		io.WriteString(w, "truncated")
+	}))
+	defer srv.Close()
+	latest := &releaseInfo{TagName: "v9.9.9", ActualSHA: "deadbeef"}
+	for _, name := range []string{asset, fmt.Sprintf("vibeaura-%s-%s.sha256", goos, goarch)} {
+		latest.Assets = append(latest.Assets, struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		}{name, srv.URL + "/" + name})
+	}
+
+	if _, err := downloadRelease(latest, false, nil); !errors.Is(err, errBadDownload) {
+		t.Fatalf("expected the download to fail verification, got %v", err)
+	}
+	cm, _ := sys.NewConfigManager()
+	cfg, _ := cm.Load()
+	if len(cfg.Update.FailedCommits) != 1 || cfg.Update.FailedCommits[0] != "deadbeef" {
+		t.Errorf("expected the release recorded as failed once, got %v", cfg.Update.FailedCommits)
+	}
+	downloadRelease(latest, false, nil)
+	if cfg, _ := cm.Load(); len(cfg.Update.FailedCommits) != 1 {
+		t.Errorf("expected no duplicate entry, got %v", cfg.Update.FailedCommits)
+	}
+}
@@ -10,7 +10,7 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print detailed version information",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
 		printTitle("✨", "VIBE AURACLE")
 		printKeyValueHighlight("Version  ", Version)
 		printKeyValue("Commit   ", Commit)
@@ -18,7 +18,8 @@ var versionCmd = &cobra.Command{
 		printKeyValue("Platform ", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH))
 		printKeyValue("Compiler ", runtime.Version())
 		printNewline()
-	},
+		return nil
+	}),
 }
 
 func init() {
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"github.com/nathfavour/vibeauracle/vibes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	vibesLogLimit int
+	vibesRegistry string // Marketplace registry URL for search and install
+	vibesYes      bool   // Approve sensitive permissions without asking
+)
+
+var vibesCmd = &cobra.Command{
+	Use:   "vibes",
+	Short: "Manage vibes, the .vibe.md extensions in the data directory",
+}
+
+var vibesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed vibes with their validation status",
+	Args:  cobra.NoArgs,
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		rt, err := openVibesCLI()
+		if err != nil {
+			return err
+		}
+
+		list := sortedVibes(rt)
+		if jsonOutput() {
+			printJSON(vibesListing(rt, list))
+			return nil
+		}
+		printTitle("✨", "VIBES")
+		if len(list) == 0 {
+			printInfo("No vibes installed. Add one with: vibeaura vibes install <path-or-url>")
+		}
+		for _, v := range list {
+			printBulletWithMeta(v.Spec.Name, vibeMeta(v))
+			for _, line := range validationLines(vibes.Validate(v)) {
+				fmt.Fprintln(cliOut, "    "+cliWarning.Render(line))
+			}
+		}
+		for _, line := range vibeFailureLines(rt) {
+			fmt.Fprintln(cliOut, cliError.Render(line))
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var vibesSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the vibe marketplace",
+	Long: `Search the curated vibe registry by name and description. Without a
+query every listed vibe is shown. Install a result with:
+
+  vibeaura vibes install <name>`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		market, err := fetchMarketplace()
+		if err != nil {
+			return err
+		}
+		query := ""
+		if len(args) == 1 {
+			query = args[0]
+		}
+		matches := market.Search(query)
+		if len(matches) == 0 {
+			return fmt.Errorf("no vibes match %q", query)
+		}
+
+		if jsonOutput() {
+			printJSON(matches)
+			return nil
+		}
+		printTitle("🛍️", "VIBE MARKETPLACE")
+		for _, e := range matches {
+			meta := "v" + e.Version
+			if e.Author != "" {
+				meta += " · by " + e.Author
+			}
+			printBulletWithMeta(e.Name, meta)
+			if e.Description != "" {
+				fmt.Fprintln(cliOut, "    "+e.Description)
+			}
+			fmt.Fprintln(cliOut, "    "+cliMuted.Render(e.URL))
+		}
+		printNewline()
+		return nil
+	}),
+}
+
+var vibesInstallCmd = &cobra.Command{
+	Use:   "install <path-url-or-name>",
+	Short: "Validate a .vibe.md file and install it",
+	Long: `Validates a .vibe.md file and copies it into the vibes directory.
+
+The source may be a local path, an https:// URL, or the name of a vibe in
+the marketplace registry, whose download must match the listed checksum.
+Nothing is installed if the file fails validation; warnings are printed but
+do not block it. Vibes declaring sensitive permissions (system.shell,
+sandbox.escape...) are installed only once you approve them, or with --yes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		rt, err := openVibesCLI()
+		if err != nil {
+			return err
+		}
+
+		filename, data, err := fetchVibeSource(args[0])
+		if err != nil {
+			return err
+		}
+		parsed, err := vibes.ParseBytes(data, filename)
+		if err != nil {
+			return err
+		}
+		sensitive := vibes.SensitivePermissionsOf(parsed)
+		if len(sensitive) > 0 {
+			if err := approveVibePermissions(parsed.Spec.Name, sensitive); err != nil {
+				return err
+			}
+		}
+
+		vibe, result, err := rt.InstallVibeData(filename, data)
+		if result != nil {
+			for _, line := range validationLines(result) {
+				printWarning(line)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(sensitive) > 0 {
+			if err := rt.Approve(vibe); err != nil {
+				return fmt.Errorf("recording approval: %w", err)
+			}
+		}
+		printSuccess(fmt.Sprintf("Installed %s %s to %s", vibe.Spec.Name, vibe.Spec.Version, vibe.FilePath))
+		return nil
+	}),
+}
+
+var vibesEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Enable an installed vibe",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		return setVibeEnabled(args[0], true)
+	}),
+}
+
+var vibesDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Disable an installed vibe without removing it",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		return setVibeEnabled(args[0], false)
+	}),
+}
+
+var vibesLogsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "Show recent log entries for a vibe",
+	Args:  cobra.ExactArgs(1),
+	RunE: cliRun(func(cmd *cobra.Command, args []string) error {
+		rt, err := openVibesCLI()
+		if err != nil {
+			return err
+		}
+
+		entries := rt.Logger.EntriesForVibe(args[0], vibesLogLimit)
+		if len(entries) == 0 {
+			if _, ok := rt.Registry.Get(args[0]); !ok {
+				return usageErrorf("vibe not found: %s", args[0])
+			}
+		}
+		if jsonOutput() {
+			if entries == nil {
+				entries = []vibes.LogEntry{}
+			}
+			printJSON(entries)
+			return nil
+		}
+		if len(entries) == 0 {
+			printInfo("No log entries for " + args[0])
+			return nil
+		}
+		for _, e := range entries {
+			fmt.Fprintln(cliOut, formatVibeLogEntry(e))
+		}
+		return nil
+	}),
+}
+
+func setVibeEnabled(name string, enabled bool) error {
+	rt, err := openVibesCLI()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := rt.Registry.Get(name); !ok {
+		return usageErrorf("vibe not found: %s", name)
+	}
+	if err := rt.SetEnabled(name, enabled); err != nil {
+		return err
+	}
+	if enabled {
+		printSuccess("Enabled " + name)
+	} else {
+		printSuccess("Disabled " + name)
+	}
+	return nil
+}
+
+// openVibesCLI loads the vibes runtime for the configured data directory.
+func openVibesCLI() (*vibes.Runtime, error) {
+	cm, err := sys.NewConfigManager()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := cm.Load()
+	if err != nil {
+		return nil, err
+	}
+	return openVibes(cfg.DataDir)
+}
+
+// openVibes creates a runtime over dataDir and loads the installed vibes.
+func openVibes(dataDir string) (*vibes.Runtime, error) {
+	rt, err := vibes.NewRuntime(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening vibes: %w", err)
+	}
+	if err := rt.Scan(); err != nil {
+		return nil, err
+	}
+	if err := rt.Security.SetTOTPSecret(totpSecret(dataDir)); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// installVibe reads src, a local path or an https:// URL, and installs it
+// into rt after validation.
+func installVibe(rt *vibes.Runtime, src string) (*vibes.Vibe, *vibes.ValidationResult, error) {
+	filename, data, err := readVibeSource(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rt.InstallVibeData(filename, data)
+}
+
+// readVibeSource reads a .vibe.md file from a local path or an https:// URL.
+func readVibeSource(src string) (string, []byte, error) {
+	switch {
+	case strings.HasPrefix(src, "https://"):
+		u, err := url.Parse(src)
+		if err != nil {
+			return "", nil, usageErrorf("invalid URL %q: %v", src, err)
+		}
+		data, err := fetchWithFallback(src)
+		if err != nil {
+			return "", nil, fmt.Errorf("downloading %s: %w", src, err)
+		}
+		return path.Base(u.Path), data, nil
+	case strings.HasPrefix(src, "http://"):
+		return "", nil, usageErrorf("refusing to install a vibe over plain http; use https://")
+	default:
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return "", nil, err
+		}
+		return filepath.Base(src), data, nil
+	}
+}
+
+// fetchVibeSource is readVibeSource that also accepts the name of a vibe in
+// the marketplace, when src is not a URL or an existing file. The download
+// must match the registry's checksum.
+func fetchVibeSource(src string) (string, []byte, error) {
+	if strings.Contains(src, "://") || strings.ContainsAny(src, `/\`) || strings.HasSuffix(src, ".md") {
+		return readVibeSource(src)
+	}
+	if _, err := os.Stat(src); err == nil {
+		return readVibeSource(src)
+	}
+
+	market, err := fetchMarketplace()
+	if err != nil {
+		return "", nil, err
+	}
+	entry, ok := market.Lookup(src)
+	if !ok {
+		return "", nil, usageErrorf("no vibe named %q in the marketplace; try: vibeaura vibes search %s", src, src)
+	}
+	printInfo(fmt.Sprintf("Downloading %s %s from %s", entry.Name, entry.Version, entry.URL))
+	filename, data, err := readVibeSource(entry.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := entry.VerifyChecksum(data); err != nil {
+		return "", nil, err
+	}
+	return filename, data, nil
+}
+
+// fetchMarketplace downloads and parses the vibe registry.
+func fetchMarketplace() (*vibes.Marketplace, error) {
+	data, err := fetchWithFallback(vibesRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("fetching vibe registry %s: %w", vibesRegistry, err)
+	}
+	return vibes.ParseMarketplace(data)
+}
+
+// approveVibePermissions asks on the terminal before installing a vibe that
+// declares sensitive permissions. --yes approves without asking.
+func approveVibePermissions(name string, perms []vibes.Permission) error {
+	list := make([]string, len(perms))
+	for i, p := range perms {
+		list[i] = string(p)
+	}
+	printWarning(fmt.Sprintf("%s requests sensitive permissions: %s", name, strings.Join(list, ", ")))
+	if vibesYes {
+		return nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return usageErrorf("%s needs approval for sensitive permissions; re-run with --yes to grant them", name)
+	}
+	fmt.Fprint(cliErr, "Grant these permissions and install? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("installation of %s cancelled", name)
+}
+
+// Choices offered when a vibe asks to use a sensitive permission; the
+// third is tooling.ChoiceDeny.
+const (
+	choiceAllowOnce    = "Allow Once"
+	choiceAllowSession = "Allow Session"
+)
+
+// vibePermissionIntervention asks the user about a permission a vibe needs
+// but has not been granted. Its Resume grants the permission once or for
+// the rest of the session, or reports the denial as an error; the caller
+// then runs the action again.
+func vibePermissionIntervention(rt *vibes.Runtime, pe *vibes.PermissionError) *tooling.InterventionError {
+	return &tooling.InterventionError{
+		Title:   fmt.Sprintf("Allow vibe %s to use %s?", pe.Vibe, pe.Permission),
+		Choices: []string{choiceAllowOnce, choiceAllowSession, tooling.ChoiceDeny},
+		Risk:    "high",
+		Resume: func(choice string) (*tooling.ToolResult, error) {
+			switch choice {
+			case choiceAllowOnce:
+				rt.Security.ApproveOnce(pe.Vibe, pe.Permission)
+			case choiceAllowSession:
+				rt.Security.ApprovePermission(pe.Vibe, pe.Permission)
+			default:
+				rt.Logger.Log(vibes.LogWarn, pe.Vibe, "denied "+string(pe.Permission))
+				return nil, fmt.Errorf("%s was not allowed to use %s", pe.Vibe, pe.Permission)
+			}
+			rt.Logger.Log(vibes.LogInfo, pe.Vibe, fmt.Sprintf("%s approved (%s)", pe.Permission, strings.ToLower(choice)))
+			return &tooling.ToolResult{Status: "success"}, nil
+		},
+	}
+}
+
+func sortedVibes(rt *vibes.Runtime) []*vibes.Vibe {
+	list := rt.Registry.List()
+	sort.Slice(list, func(i, j int) bool { return list[i].Spec.Name < list[j].Spec.Name })
+	return list
+}
+
+func vibeMeta(v *vibes.Vibe) string {
+	parts := []string{}
+	if v.Spec.Version != "" {
+		parts = append(parts, "v"+v.Spec.Version)
+	}
+	if v.Enabled {
+		parts = append(parts, "enabled")
+	} else {
+		parts = append(parts, "disabled")
+	}
+	if len(v.Spec.Hooks) > 0 {
+		hooks := make([]string, len(v.Spec.Hooks))
+		for i, h := range v.Spec.Hooks {
+			hooks[i] = string(h)
+		}
+		parts = append(parts, "hooks: "+strings.Join(hooks, ", "))
+	}
+	if v.Enabled && v.Spec.Schedule != "" {
+		if next, err := vibes.NextCronRun(v.Spec.Schedule, time.Now()); err == nil {
+			parts = append(parts, "next run: "+next.Local().Format("2006-01-02 15:04"))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// validationLines renders errors and warnings one per line.
+func validationLines(r *vibes.ValidationResult) []string {
+	var lines []string
+	for _, e := range r.Errors {
+		lines = append(lines, "error: "+e.Error())
+	}
+	for _, w := range r.Warnings {
+		lines = append(lines, "warning: "+w.Error())
+	}
+	return lines
+}
+
+// vibesListJSON is the result of `vibes list --output json`.
+type vibesListJSON struct {
+	Vibes    []vibeJSON        `json:"vibes"`
+	Failures []vibeFailureJSON `json:"failures"`
+}
+
+type vibeJSON struct {
+	Name     string       `json:"name"`
+	Version  string       `json:"version,omitempty"`
+	Enabled  bool         `json:"enabled"`
+	Path     string       `json:"path"`
+	Hooks    []vibes.Hook `json:"hooks,omitempty"`
+	Schedule string       `json:"schedule,omitempty"`
+	Problems []string     `json:"problems,omitempty"` // Validation errors and warnings
+}
+
+// vibeFailureJSON is a file in the vibes directory that did not parse.
+type vibeFailureJSON struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+func vibesListing(rt *vibes.Runtime, list []*vibes.Vibe) vibesListJSON {
+	out := vibesListJSON{Vibes: []vibeJSON{}, Failures: []vibeFailureJSON{}}
+	for _, v := range list {
+		out.Vibes = append(out.Vibes, vibeJSON{
+			Name:     v.Spec.Name,
+			Version:  v.Spec.Version,
+			Enabled:  v.Enabled,
+			Path:     v.FilePath,
+			Hooks:    v.Spec.Hooks,
+			Schedule: v.Spec.Schedule,
+			Problems: validationLines(vibes.Validate(v)),
+		})
+	}
+	for p, err := range rt.Registry.Failures() {
+		out.Failures = append(out.Failures, vibeFailureJSON{File: filepath.Base(p), Error: err.Error()})
+	}
+	sort.Slice(out.Failures, func(i, j int) bool { return out.Failures[i].File < out.Failures[j].File })
+	return out
+}
+
+// vibeFailureLines lists files in the vibes directory that did not parse.
+func vibeFailureLines(rt *vibes.Runtime) []string {
+	var lines []string
+	for p, err := range rt.Registry.Failures() {
+		lines = append(lines, fmt.Sprintf("✗ %s: %v", filepath.Base(p), err))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func formatVibeLogEntry(e vibes.LogEntry) string {
+	line := fmt.Sprintf("%s %-5s %s", e.Timestamp.Format(time.RFC3339), e.Level, e.Message)
+	if e.Hook != "" {
+		line += " [" + string(e.Hook) + "]"
+	}
+	if e.Error != "" {
+		line += " error: " + e.Error
+	}
+	return line
+}
+
+func init() {
+	vibesLogsCmd.Flags().IntVarP(&vibesLogLimit, "limit", "n", 50, "Number of entries to show")
+	vibesCmd.PersistentFlags().StringVar(&vibesRegistry, "registry", vibes.DefaultRegistryURL, "Marketplace registry URL")
+	vibesInstallCmd.Flags().BoolVarP(&vibesYes, "yes", "y", false, "Grant sensitive permissions without asking")
+	vibesCmd.AddCommand(vibesListCmd)
+	vibesCmd.AddCommand(vibesSearchCmd)
+	vibesCmd.AddCommand(vibesInstallCmd)
+	vibesCmd.AddCommand(vibesEnableCmd)
+	vibesCmd.AddCommand(vibesDisableCmd)
+	vibesCmd.AddCommand(vibesLogsCmd)
+	rootCmd.AddCommand(vibesCmd)
+}
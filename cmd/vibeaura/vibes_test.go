@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/brain"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+const shellVibe = `---
+name: shouter
+version: 1.0.0
+permissions: [system.shell]
+tools:
+  - name: say
+    description: Echo a word
+    parameters:
+      word: {type: string, default: hi}
+    action: echo ${word}
+---
+Shout.
+`
+
+func TestRunVibeTool_AsksForShellPermission(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dataDir, "vibes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "vibes", "shouter.vibe.md"), []byte(shellVibe), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := openVibes(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := runVibeTool(rt, "shouter", "say", map[string]string{"word": "hello"})
+	ask, ok := run().(vibeApprovalMsg)
+	if !ok {
+		t.Fatal("expected the first run to ask for system.shell")
+	}
+	if !strings.Contains(ask.ie.Title, "shouter") || !strings.Contains(ask.ie.Title, "system.shell") || ask.ie.Risk != "high" {
+		t.Errorf("unexpected intervention: %+v", ask.ie)
+	}
+
+	if _, err := ask.ie.Resume(tooling.ChoiceDeny); err == nil {
+		t.Error("expected a denial to be reported as an error")
+	}
+	if _, ok := ask.retry().(vibeApprovalMsg); !ok {
+		t.Fatal("expected a denied permission to be asked for again")
+	}
+
+	if _, err := ask.ie.Resume(choiceAllowOnce); err != nil {
+		t.Fatal(err)
+	}
+	resp, ok := ask.retry().(brain.Response)
+	if !ok || resp.Error != nil || !strings.Contains(resp.Content, "hello") {
+		t.Fatalf("expected the approved run to echo, got %+v", resp)
+	}
+	if _, ok := ask.retry().(vibeApprovalMsg); !ok {
+		t.Error("expected Allow Once to cover a single run")
+	}
+
+	if _, err := ask.ie.Resume(choiceAllowSession); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if resp, ok := ask.retry().(brain.Response); !ok || resp.Error != nil {
+			t.Errorf("run %d: expected Allow Session to cover later runs, got %+v", i, resp)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nathfavour/vibeauracle/prompt"
@@ -19,6 +22,8 @@ type Goal struct {
 	Confidence  float64
 }
 
+// Milestone is one sub-task of a Goal, checked off when the model reports
+// it done.
 type Milestone struct {
 	Description string
 	Completed   bool
@@ -32,6 +37,12 @@ type LoopState struct {
 	History    []string
 	Confidence float64
 	StartTime  time.Time
+
+	// CurrentMilestone is the index of the first milestone not yet done, or
+	// -1 when there are none left.
+	CurrentMilestone int
+	// PercentComplete is the share of milestones done, from 0 to 100.
+	PercentComplete float64
 }
 
 // Model defines the minimal interface the agent needs to prompt the AI.
@@ -48,11 +59,17 @@ type Engine struct {
 }
 
 type Config struct {
-	MaxTurns       int
-	MinConfidence  float64
+	MaxTurns        int
+	MinConfidence   float64
 	LearningEnabled bool
+	// SkipDecomposition runs the goal as a single task, without first
+	// asking the model to split it into milestones.
+	SkipDecomposition bool
 }
 
+// maxMilestones caps how many sub-tasks a goal is split into.
+const maxMilestones = 10
+
 func NewEngine(m Model, r *tooling.Registry, p *prompt.System, cfg Config) *Engine {
 	if cfg.MaxTurns == 0 {
 		cfg.MaxTurns = 10
@@ -75,9 +92,23 @@ func (e *Engine) Run(ctx context.Context, initialPrompt string, onUpdate func(Lo
 			Description: initialPrompt,
 			Status:      "active",
 		},
-		MaxTurns:   e.config.MaxTurns,
-		Confidence: 1.0,
-		StartTime:  time.Now(),
+		MaxTurns:         e.config.MaxTurns,
+		Confidence:       1.0,
+		StartTime:        time.Now(),
+		CurrentMilestone: -1,
+	}
+
+	// 0. Decomposition: split the goal into milestones before working on it.
+	if !e.config.SkipDecomposition {
+		milestones, err := e.decompose(ctx, initialPrompt)
+		if err != nil {
+			return "", fmt.Errorf("agent decomposition: %w", err)
+		}
+		state.Goal.Milestones = milestones
+		state.updateProgress()
+		if onUpdate != nil {
+			onUpdate(state)
+		}
 	}
 
 	for state.Turns < state.MaxTurns {
@@ -98,7 +129,10 @@ func (e *Engine) Run(ctx context.Context, initialPrompt string, onUpdate func(Lo
 
 		// 3. Analysis: The bureaucratic manager parses the bricks.
 		parsed := prompt.ParseModelResponse(resp)
-		
+		if state.checkMilestones(resp) && onUpdate != nil {
+			onUpdate(state)
+		}
+
 		// 4. Execution Loop: Extract and run tool calls if any.
 		result, toolsCalled, err := e.executeInferredTools(ctx, parsed)
 		if err != nil {
@@ -111,7 +145,7 @@ func (e *Engine) Run(ctx context.Context, initialPrompt string, onUpdate func(Lo
 		if result != "" {
 			state.History = append(state.History, "TOOL_RESULT: "+result)
 		}
-		
+
 		state.Confidence = e.calculateConfidence(state, toolsCalled)
 
 		// Check for exit conditions.
@@ -120,7 +154,9 @@ func (e *Engine) Run(ctx context.Context, initialPrompt string, onUpdate func(Lo
 		}
 
 		// Look for completion markers in AI response.
-		if strings.Contains(strings.ToLower(resp), "goal completed") || strings.Contains(strings.ToLower(resp), "[task_done]") {
+		// Checking off the last milestone completes the goal too.
+		allDone := len(state.Goal.Milestones) > 0 && state.CurrentMilestone == -1
+		if allDone || strings.Contains(strings.ToLower(resp), "goal completed") || strings.Contains(strings.ToLower(resp), "[task_done]") {
 			state.Goal.Status = "completed"
 			return resp, nil
 		}
@@ -135,13 +171,27 @@ func (e *Engine) Run(ctx context.Context, initialPrompt string, onUpdate func(Lo
 }
 
 func (e *Engine) buildHandshakePrompt(state LoopState) string {
-	// Multi-layered handshake: Goal + History + Rules + Current State.
+	// Multi-layered handshake: Goal + Milestones + History + Rules + Current State.
+	var milestones, milestoneRule string
+	if len(state.Goal.Milestones) > 0 {
+		var sb strings.Builder
+		sb.WriteString("\n### MILESTONES:\n")
+		for i, m := range state.Goal.Milestones {
+			mark := " "
+			if m.Completed {
+				mark = "x"
+			}
+			fmt.Fprintf(&sb, "%d. [%s] %s\n", i+1, mark, m.Description)
+		}
+		milestones = sb.String()
+		milestoneRule = "\n- When a milestone is finished, include \"[MILESTONE_DONE: <number>]\" for it."
+	}
 	return fmt.Sprintf(`### AGENT WORK LOOP (Turn %d/%d)
 GOAL: %s
 CONFIDENCE: %.2f
-
+%s
 ### RULES:
-- If a task is finished, include "[TASK_DONE]" or "GOAL COMPLETED".
+- If a task is finished, include "[TASK_DONE]" or "GOAL COMPLETED".%s
 - Use tools available to perform CRUD or system tasks.
 - If you need clarification from the client, ask directly.
 
@@ -149,8 +199,92 @@ CONFIDENCE: %.2f
 %s
 
 ### CURRENT ACTION:
-Analyze history and continue working towards the goal.`, 
-		state.Turns, state.MaxTurns, state.Goal.Description, state.Confidence, strings.Join(state.History, "\n---\n"))
+Analyze history and continue working towards the goal.`,
+		state.Turns, state.MaxTurns, state.Goal.Description, state.Confidence, milestones, milestoneRule, strings.Join(state.History, "\n---\n"))
+}
+
+// decompose asks the model to split a goal into milestones. A reply that
+// holds no usable list leaves the goal as a single task.
+func (e *Engine) decompose(ctx context.Context, description string) ([]Milestone, error) {
+	resp, err := e.model.Generate(ctx, fmt.Sprintf(`### GOAL DECOMPOSITION
+GOAL: %s
+
+Split the goal into at most %d ordered, concrete sub-tasks.
+Reply with only a JSON array of strings, one sub-task each.`, description, maxMilestones))
+	if err != nil {
+		return nil, err
+	}
+	return parseMilestones(resp), nil
+}
+
+// parseMilestones reads the JSON array in a decomposition reply. Models
+// often wrap it in prose or a code fence, so the outermost brackets are
+// used; objects with a "description" field are accepted too.
+func parseMilestones(resp string) []Milestone {
+	start, end := strings.Index(resp, "["), strings.LastIndex(resp, "]")
+	if start == -1 || end < start {
+		return nil
+	}
+	raw := []byte(resp[start : end+1])
+
+	var descriptions []string
+	if err := json.Unmarshal(raw, &descriptions); err != nil {
+		var objects []struct {
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			return nil
+		}
+		for _, o := range objects {
+			descriptions = append(descriptions, o.Description)
+		}
+	}
+
+	var milestones []Milestone
+	for _, d := range descriptions {
+		if d = strings.TrimSpace(d); d != "" && len(milestones) < maxMilestones {
+			milestones = append(milestones, Milestone{Description: d})
+		}
+	}
+	return milestones
+}
+
+// milestoneDone matches the token a model emits to check off a milestone.
+var milestoneDone = regexp.MustCompile(`(?i)\[MILESTONE_DONE:\s*(\d+)\s*\]`)
+
+// checkMilestones marks the milestones resp reports done, numbered from 1
+// as in the handshake prompt, and reports whether any changed.
+func (s *LoopState) checkMilestones(resp string) bool {
+	changed := false
+	for _, m := range milestoneDone.FindAllStringSubmatch(resp, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > len(s.Goal.Milestones) || s.Goal.Milestones[n-1].Completed {
+			continue
+		}
+		s.Goal.Milestones[n-1].Completed = true
+		changed = true
+	}
+	if changed {
+		s.updateProgress()
+	}
+	return changed
+}
+
+// updateProgress recomputes CurrentMilestone and PercentComplete.
+func (s *LoopState) updateProgress() {
+	s.CurrentMilestone = -1
+	done := 0
+	for i, m := range s.Goal.Milestones {
+		if m.Completed {
+			done++
+		} else if s.CurrentMilestone == -1 {
+			s.CurrentMilestone = i
+		}
+	}
+	s.PercentComplete = 0
+	if n := len(s.Goal.Milestones); n > 0 {
+		s.PercentComplete = float64(done) * 100 / float64(n)
+	}
 }
 
 func (e *Engine) calculateConfidence(state LoopState, toolsCalled bool) float64 {
@@ -175,19 +309,21 @@ func (e *Engine) calculateConfidence(state LoopState, toolsCalled bool) float64
 		score += 0.05
 	}
 
-	if score > 1.0 { score = 1.0 }
-	if score < 0.0 { score = 0.0 }
+	if score > 1.0 {
+		score = 1.0
+	}
+	if score < 0.0 {
+		score = 0.0
+	}
 	return score
 }
 
 func (e *Engine) executeInferredTools(ctx context.Context, parsed prompt.ParsedResponse) (string, bool, error) {
 	// Simple heuristic: search for tool-like patterns in text or specifically formatted blocks.
 	// For now, we rely on standard tooling registry lookups if we find structured commands.
-	
+
 	// Implementation note: This is where we'd parse things like `USE sys_write_file {"path": "..."}`
 	// or rely on the bricks (AI) being trained to output valid tool calls.
-	
+
 	return "", false, nil
 }
-
-import "strings"
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// scriptedModel replies with each response in turn and records prompts.
+type scriptedModel struct {
+	replies []string
+	prompts []string
+}
+
+func (s *scriptedModel) Generate(ctx context.Context, prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.replies) == 0 {
+		return "still working", nil
+	}
+	r := s.replies[0]
+	s.replies = s.replies[1:]
+	return r, nil
+}
+
+func TestRun_TracksMilestones(t *testing.T) {
+	m := &scriptedModel{replies: []string{
+		"Here is the plan:\n```json\n[\"Read the config\", \"Add the flag\", \"Write a test\"]\n```",
+		"Read it. [MILESTONE_DONE: 1]",
+		"Added the flag and the test. [MILESTONE_DONE: 2] [milestone_done: 3]",
+	}}
+	var updates []LoopState
+	out, err := NewEngine(m, nil, nil, Config{}).Run(context.Background(), "add a --verbose flag", func(s LoopState) {
+		updates = append(updates, s)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Added the flag") {
+		t.Errorf("expected the final reply, got %q", out)
+	}
+
+	first := updates[0]
+	if len(first.Goal.Milestones) != 3 || first.CurrentMilestone != 0 || first.PercentComplete != 0 {
+		t.Fatalf("expected three open milestones after decomposition, got %+v", first)
+	}
+	if !strings.Contains(m.prompts[1], "1. [ ] Read the config") || !strings.Contains(m.prompts[1], "[MILESTONE_DONE: <number>]") {
+		t.Errorf("expected the milestones in the handshake prompt:\n%s", m.prompts[1])
+	}
+	if !strings.Contains(m.prompts[2], "1. [x] Read the config") {
+		t.Errorf("expected the first milestone checked off:\n%s", m.prompts[2])
+	}
+
+	var sawThird bool
+	for _, u := range updates {
+		if u.CurrentMilestone == 1 && u.PercentComplete > 33 && u.PercentComplete < 34 {
+			sawThird = true
+		}
+	}
+	last := updates[len(updates)-1]
+	if !sawThird || last.CurrentMilestone != -1 || last.PercentComplete != 100 {
+		t.Errorf("expected progress through 33%% to 100%%, got %+v", updates)
+	}
+}
+
+func TestParseMilestones(t *testing.T) {
+	if got := parseMilestones(`[{"description": "a"}, {"description": " "}, {"description": "b"}]`); len(got) != 2 || got[1].Description != "b" {
+		t.Errorf("expected objects to be accepted and blanks dropped, got %+v", got)
+	}
+	if got := parseMilestones("I can't split this goal."); got != nil {
+		t.Errorf("expected no milestones from prose, got %+v", got)
+	}
+	if got := parseMilestones(`["x", "y", "z", "x", "y", "z", "x", "y", "z", "x", "y", "z"]`); len(got) != maxMilestones {
+		t.Errorf("expected at most %d milestones, got %d", maxMilestones, len(got))
+	}
+}
+
+func TestRun_WithoutMilestones(t *testing.T) {
+	m := &scriptedModel{replies: []string{"no list here", "done [TASK_DONE]"}}
+	var last LoopState
+	if _, err := NewEngine(m, nil, nil, Config{}).Run(context.Background(), "say hi", func(s LoopState) { last = s }); err != nil {
+		t.Fatal(err)
+	}
+	if len(last.Goal.Milestones) != 0 || last.CurrentMilestone != -1 || strings.Contains(m.prompts[1], "MILESTONES") {
+		t.Errorf("expected a flat goal when decomposition yields nothing, got %+v", last)
+	}
+}
@@ -0,0 +1,373 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"gopkg.in/yaml.v3"
+)
+
+// BenchSuite describes a set of prompts to run against several models.
+type BenchSuite struct {
+	Name        string         `yaml:"name" json:"name"`
+	Repetitions int            `yaml:"repetitions" json:"repetitions"`
+	Sandbox     bool           `yaml:"sandbox" json:"sandbox"` // Execute filesystem tools in a temp workspace per case
+	Profiles    []BenchProfile `yaml:"profiles" json:"profiles"`
+	Cases       []BenchCase    `yaml:"cases" json:"cases"`
+}
+
+// BenchProfile is a model under test.
+type BenchProfile struct {
+	Name            string  `yaml:"name" json:"name"`
+	Provider        string  `yaml:"provider" json:"provider"`
+	Model           string  `yaml:"model" json:"model"`
+	CostPer1KTokens float64 `yaml:"cost_per_1k_tokens" json:"cost_per_1k_tokens"`
+}
+
+// BenchCase is a single prompt with an optional checker.
+type BenchCase struct {
+	Name     string       `yaml:"name" json:"name"`
+	Prompt   string       `yaml:"prompt" json:"prompt"`
+	Fixtures []string     `yaml:"fixtures" json:"fixtures"` // Files attached to the prompt (and copied into the sandbox)
+	Expect   BenchChecker `yaml:"expect" json:"expect"`
+}
+
+// BenchChecker validates a response. All set fields must pass.
+type BenchChecker struct {
+	Contains string `yaml:"contains" json:"contains,omitempty"`
+	Regex    string `yaml:"regex" json:"regex,omitempty"`
+	Command  string `yaml:"command" json:"command,omitempty"` // Run via sh in the case workspace; exit 0 passes
+}
+
+// BenchResult is the outcome of one repetition of one case.
+type BenchResult struct {
+	Profile   string `json:"profile"`
+	Case      string `json:"case"`
+	Rep       int    `json:"rep"`
+	Success   bool   `json:"success"`
+	LatencyMS int64  `json:"latency_ms"`
+	Tokens    int    `json:"tokens"`
+	// TokensEstimated is set when the provider reported no usage and
+	// Tokens is a 4 characters per token estimate.
+	TokensEstimated bool   `json:"tokens_estimated,omitempty"`
+	ToolCalls       int    `json:"tool_calls"`
+	Error           string `json:"error,omitempty"`
+}
+
+// BenchSummary aggregates results for one profile.
+type BenchSummary struct {
+	Profile         string  `json:"profile"`
+	Runs            int     `json:"runs"`
+	SuccessRate     float64 `json:"success_rate"`
+	MedianLatencyMS int64   `json:"median_latency_ms"`
+	Tokens          int     `json:"tokens"`
+	TokensEstimated bool    `json:"tokens_estimated,omitempty"` // Some runs' tokens are estimates
+	EstimatedCost   float64 `json:"estimated_cost"`
+}
+
+// BenchReport is the persisted output of a run.
+type BenchReport struct {
+	Suite      string         `json:"suite"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Results    []BenchResult  `json:"results"`
+	Summary    []BenchSummary `json:"summary"`
+}
+
+// BenchOptions controls a benchmark run.
+type BenchOptions struct {
+	Checkpoint string                               // Path of the resumable checkpoint file
+	Progress   func(done, total int, r BenchResult) // Called after every repetition
+}
+
+// LoadBenchSuite parses a suite file. Fixture paths are resolved relative to it.
+func LoadBenchSuite(path string) (*BenchSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s BenchSuite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing suite: %w", err)
+	}
+	if len(s.Profiles) == 0 || len(s.Cases) == 0 {
+		return nil, fmt.Errorf("suite needs at least one profile and one case")
+	}
+	if s.Repetitions <= 0 {
+		s.Repetitions = 1
+	}
+	base := filepath.Dir(path)
+	for i := range s.Cases {
+		if s.Cases[i].Name == "" {
+			s.Cases[i].Name = fmt.Sprintf("case-%d", i+1)
+		}
+		for j, f := range s.Cases[i].Fixtures {
+			if !filepath.IsAbs(f) {
+				s.Cases[i].Fixtures[j] = filepath.Join(base, f)
+			}
+		}
+	}
+	for i := range s.Profiles {
+		if s.Profiles[i].Name == "" {
+			s.Profiles[i].Name = s.Profiles[i].Provider + "/" + s.Profiles[i].Model
+		}
+	}
+	return &s, nil
+}
+
+// LoadBenchReport reads a report written by RunBench.
+func LoadBenchReport(path string) (*BenchReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r BenchReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing report %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// RunBench executes every case against every profile sequentially. Completed
+// repetitions are written to the checkpoint so an interrupted run resumes.
+func (b *Brain) RunBench(ctx context.Context, suite *BenchSuite, opts BenchOptions) (*BenchReport, error) {
+	return runBench(ctx, suite, opts, b.NewProvider)
+}
+
+func runBench(ctx context.Context, suite *BenchSuite, opts BenchOptions, newProvider func(provider, name string) (model.Provider, error)) (*BenchReport, error) {
+	report := &BenchReport{Suite: suite.Name, StartedAt: time.Now()}
+	if opts.Checkpoint != "" {
+		if prev, err := LoadBenchReport(opts.Checkpoint); err == nil && prev.Suite == suite.Name {
+			report = prev
+		}
+	}
+
+	done := make(map[string]bool)
+	for _, r := range report.Results {
+		done[benchKey(r.Profile, r.Case, r.Rep)] = true
+	}
+
+	total := len(suite.Profiles) * len(suite.Cases) * suite.Repetitions
+	for _, prof := range suite.Profiles {
+		p, perr := newProvider(prof.Provider, prof.Model)
+
+		for _, c := range suite.Cases {
+			for rep := 1; rep <= suite.Repetitions; rep++ {
+				if done[benchKey(prof.Name, c.Name, rep)] {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return report, err
+				}
+
+				var r BenchResult
+				if perr != nil {
+					r = BenchResult{Profile: prof.Name, Case: c.Name, Rep: rep, Error: perr.Error()}
+				} else {
+					r = runBenchCase(ctx, suite, prof, c, rep, p)
+				}
+
+				report.Results = append(report.Results, r)
+				if opts.Checkpoint != "" {
+					if err := writeJSONAtomic(opts.Checkpoint, report); err != nil {
+						return report, fmt.Errorf("writing checkpoint: %w", err)
+					}
+				}
+				if opts.Progress != nil {
+					opts.Progress(len(report.Results), total, r)
+				}
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	report.Summary = SummarizeBench(suite, report.Results)
+	return report, nil
+}
+
+func runBenchCase(ctx context.Context, suite *BenchSuite, prof BenchProfile, c BenchCase, rep int, p model.Provider) BenchResult {
+	r := BenchResult{Profile: prof.Name, Case: c.Name, Rep: rep}
+
+	workspace, err := os.MkdirTemp("", "vibeaura-bench-*")
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	defer os.RemoveAll(workspace)
+
+	prompt, err := benchPrompt(c, workspace)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	ctx, tokens := model.CountTokens(ctx)
+	start := time.Now()
+	resp, err := p.Generate(ctx, prompt)
+	r.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if in, out := tokens(); in+out > 0 {
+		r.Tokens = in + out
+	} else {
+		// The provider reported no usage.
+		var tok vcontext.EstimateTokens
+		r.Tokens = tok.Count(prompt) + tok.Count(resp)
+		r.TokensEstimated = true
+	}
+
+	// Tools are dry-run unless the suite opts into a sandboxed workspace,
+	// where file tools cannot reach outside it.
+	var registry *tooling.Registry
+	if suite.Sandbox {
+		fs := sys.NewLocalFS(workspace)
+		fs.SetPathPolicy(sys.NewPathPolicy(workspace))
+		registry = tooling.DefaultRegistry(fs, sys.NewMonitor(), nil)
+	}
+	for _, call := range parseToolCalls(resp) {
+		r.ToolCalls++
+		if registry == nil {
+			continue
+		}
+		t, found := registry.Get(call.Tool)
+		if !found || t.Metadata().Category != tooling.CategoryFileSystem {
+			continue
+		}
+		if _, err := t.Execute(ctx, call.Args); err != nil && r.Error == "" {
+			r.Error = fmt.Sprintf("tool %s: %v", call.Tool, err)
+		}
+	}
+
+	if err := checkBench(ctx, c.Expect, resp, workspace); err != nil {
+		if r.Error == "" {
+			r.Error = err.Error()
+		}
+		return r
+	}
+	r.Success = r.Error == ""
+	return r
+}
+
+// benchPrompt inlines fixtures into the prompt and copies them into the workspace.
+func benchPrompt(c BenchCase, workspace string) (string, error) {
+	var sb strings.Builder
+	for _, f := range c.Fixtures {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("reading fixture: %w", err)
+		}
+		name := filepath.Base(f)
+		if err := os.WriteFile(filepath.Join(workspace, name), data, 0644); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "--- FILE: %s ---\n%s\n--- END FILE ---\n\n", name, data)
+	}
+	sb.WriteString(c.Prompt)
+	return sb.String(), nil
+}
+
+func checkBench(ctx context.Context, exp BenchChecker, resp, workspace string) error {
+	if exp.Contains != "" && !strings.Contains(resp, exp.Contains) {
+		return fmt.Errorf("expected output to contain %q", exp.Contains)
+	}
+	if exp.Regex != "" {
+		re, err := regexp.Compile(exp.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		if !re.MatchString(resp) {
+			return fmt.Errorf("output did not match /%s/", exp.Regex)
+		}
+	}
+	if exp.Command != "" {
+		if err := os.WriteFile(filepath.Join(workspace, "response.txt"), []byte(resp), 0644); err != nil {
+			return err
+		}
+		cctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(cctx, "sh", "-c", exp.Command)
+		cmd.Dir = workspace
+		cmd.Env = append(os.Environ(), "VIBEAURA_OUTPUT="+filepath.Join(workspace, "response.txt"))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("verify command failed: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// SummarizeBench aggregates results per profile in suite order.
+func SummarizeBench(suite *BenchSuite, results []BenchResult) []BenchSummary {
+	var out []BenchSummary
+	for _, prof := range suite.Profiles {
+		s := BenchSummary{Profile: prof.Name}
+		var latencies []int64
+		var ok int
+		for _, r := range results {
+			if r.Profile != prof.Name {
+				continue
+			}
+			s.Runs++
+			s.Tokens += r.Tokens
+			s.TokensEstimated = s.TokensEstimated || r.TokensEstimated
+			latencies = append(latencies, r.LatencyMS)
+			if r.Success {
+				ok++
+			}
+		}
+		if s.Runs > 0 {
+			s.SuccessRate = float64(ok) / float64(s.Runs)
+		}
+		s.MedianLatencyMS = median(latencies)
+		s.EstimatedCost = float64(s.Tokens) / 1000 * prof.CostPer1KTokens
+		out = append(out, s)
+	}
+	return out
+}
+
+// SaveBenchReport writes a report as indented JSON.
+func SaveBenchReport(path string, r *BenchReport) error {
+	return writeJSONAtomic(path, r)
+}
+
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func median(vals []int64) int64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), vals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func benchKey(profile, c string, rep int) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", profile, c, rep)
+}
@@ -0,0 +1,129 @@
+package brain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/model"
+)
+
+type benchProvider struct{ reply string }
+
+func (p *benchProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	return p.reply, nil
+}
+func (p *benchProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, p, prompt, out)
+}
+func (p *benchProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+func (p *benchProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	return model.NoNativeTools(ctx, messages, tools)
+}
+func (p *benchProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *benchProvider) Name() string                                     { return "bench" }
+
+func TestRunBench_ResumesFromCheckpoint(t *testing.T) {
+	suite := &BenchSuite{
+		Name:        "smoke",
+		Repetitions: 2,
+		Profiles: []BenchProfile{
+			{Name: "good", Provider: "fake", Model: "good", CostPer1KTokens: 1},
+			{Name: "bad", Provider: "fake", Model: "bad"},
+		},
+		Cases: []BenchCase{{Name: "answer", Prompt: "What is 6*7?", Expect: BenchChecker{Contains: "42"}}},
+	}
+
+	calls := 0
+	newProvider := func(provider, name string) (model.Provider, error) {
+		calls++
+		if name == "good" {
+			return &benchProvider{reply: "It is 42."}, nil
+		}
+		return &benchProvider{reply: "No idea."}, nil
+	}
+
+	checkpoint := filepath.Join(t.TempDir(), "run.checkpoint.json")
+	report, err := runBench(context.Background(), suite, BenchOptions{Checkpoint: checkpoint}, newProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(report.Results))
+	}
+	if report.Summary[0].SuccessRate != 1 || report.Summary[1].SuccessRate != 0 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+
+	// A second run against the finished checkpoint should not redo any work.
+	var progressed int
+	report, err = runBench(context.Background(), suite, BenchOptions{
+		Checkpoint: checkpoint,
+		Progress:   func(done, total int, r BenchResult) { progressed++ },
+	}, newProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progressed != 0 || len(report.Results) != 4 {
+		t.Errorf("expected resume to skip completed cases, progressed=%d results=%d", progressed, len(report.Results))
+	}
+}
+
+func TestRunBench_SandboxRunsEveryCallInsideWorkspace(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	write := func(path string) string {
+		return "```json\n{\"tool\": \"sys_write_file\", \"parameters\": {\"path\": \"" + path + "\", \"content\": \"x\"}}\n```\n"
+	}
+	suite := &BenchSuite{
+		Name:        "sandbox",
+		Repetitions: 1,
+		Sandbox:     true,
+		Profiles:    []BenchProfile{{Name: "p", Provider: "fake", Model: "m"}},
+		Cases: []BenchCase{
+			{Name: "both", Prompt: "write", Expect: BenchChecker{Command: "test -f a.txt && test -f b.txt"}},
+			{Name: "escape", Prompt: "escape"},
+		},
+	}
+	replies := map[string]string{
+		"write":  write("a.txt") + write("b.txt"),
+		"escape": write("../escaped.txt"),
+	}
+	newProvider := func(provider, name string) (model.Provider, error) {
+		return &promptBenchProvider{replies: replies}, nil
+	}
+
+	report, err := runBench(context.Background(), suite, BenchOptions{}, newProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, escape := report.Results[0], report.Results[1]
+	if !both.Success || both.ToolCalls != 2 {
+		t.Errorf("expected both calls to run, got %+v", both)
+	}
+	if !both.TokensEstimated {
+		t.Errorf("expected tokens to be marked as estimated without provider usage, got %+v", both)
+	}
+	if escape.Success || !strings.Contains(escape.Error, "sys_write_file") {
+		t.Errorf("expected the escaping write to fail, got %+v", escape)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "escaped.txt")); !os.IsNotExist(err) {
+		t.Errorf("write escaped the workspace: %v", err)
+	}
+}
+
+// promptBenchProvider answers with the reply keyed by the prompt's last line.
+type promptBenchProvider struct {
+	benchProvider
+	replies map[string]string
+}
+
+func (p *promptBenchProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	lines := strings.Split(prompt, "\n")
+	return p.replies[lines[len(lines)-1]], nil
+}
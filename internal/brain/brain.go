@@ -3,30 +3,60 @@ package brain
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/nathfavour/vibeauracle/auth"
 	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
 	"github.com/nathfavour/vibeauracle/model"
 	"github.com/nathfavour/vibeauracle/prompt"
 	"github.com/nathfavour/vibeauracle/sys"
 	"github.com/nathfavour/vibeauracle/tooling"
 	"github.com/nathfavour/vibeauracle/vault"
+	"github.com/nathfavour/vibeauracle/watcher"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Request represents a user request or system trigger
+// Request represents a user request or system trigger. Requests with the
+// same SessionID share a conversation; an empty SessionID uses the active
+// session.
 type Request struct {
-	ID      string
-	Content string
+	ID        string
+	SessionID string
+	Content   string
+	Tags      *TagContext // #path tags already resolved from Content; nil to resolve them here
+	NoTools   bool        // Answer directly, without offering the model any tools
 }
 
 // Response represents the brain's output
 type Response struct {
-	Content string
-	Error   error
+	Content   string
+	Error     error
+	Intent    string         // The prompt system's classification of the request
+	ToolCalls []ExecutedCall // Tools run to produce Content, in order
+}
+
+// ExecutedCall is one tool call the agent loop ran for a request.
+type ExecutedCall struct {
+	Tool  string          `json:"tool"`
+	Args  json.RawMessage `json:"args,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// StreamChunk is a piece of model output emitted while a request is being
+// processed. Turn counts agent loop iterations; each turn restarts the text.
+type StreamChunk struct {
+	RequestID string
+	Turn      int
+	Text      string
 }
 
 // Brain is the cognitive orchestrator
@@ -34,6 +64,7 @@ type Brain struct {
 	model    *model.Model
 	monitor  *sys.Monitor
 	fs       sys.FS
+	undo     *sys.UndoJournal // Backups taken before sys_write_file, sys_patch_file, sys_patch and sys_bulk_write writes
 	config   *sys.Config
 	cm       *sys.ConfigManager
 	auth     *auth.Handler
@@ -42,10 +73,56 @@ type Brain struct {
 	prompts  *prompt.System
 	tools    *tooling.Registry
 	security *tooling.SecurityGuard
-	sessions map[string]*tooling.Session
+	enclave  *tooling.Enclave     // nil if it failed to start
+	audit    *tooling.AuditLogger // nil if the enclave failed to start
+	replies  *model.ResponseCache // Opened on first use when model.cache_enabled
+	usage    *model.UsageStore    // nil if it could not be opened
+	pins     *pinSet
+
+	// config and model are swapped whole, never changed in place, under
+	// configMu. A change holds switchMu throughout, and requests take both
+	// under it, so a request never pairs one config's model with another
+	// config, and keeps the model it started with.
+	configMu sync.RWMutex
+	switchMu sync.RWMutex
+
+	storesMu sync.Mutex // Guards opening usage and replies
+
+	limitersMu sync.Mutex
+	limiters   map[string]*model.RateLimiter // Per provider, kept when the provider is re-initialized
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*chatSession
+
+	contextUsageMu sync.Mutex
+	contextUsage   prompt.Usage // Of the last prompt built
+
+	planMu sync.Mutex
+	plan   *prompt.Plan
+
+	failuresMu sync.Mutex
+	failures   map[string]*failureLedger
+
+	sessionMu sync.Mutex
+	session   string
+
+	suspendedMu sync.Mutex
+	suspended   *suspendedLoop
+
+	mcpMu    sync.Mutex
+	mcp      map[string]*tooling.MCPProvider
+	mcpErrs  map[string]error
+	mcpFiles []sys.MCPServer // Servers saved in mcp_servers/, as opposed to the config
+	mcpUp    chan struct{}   // Closed once startMCPServers has tried every server
+
+	treeWatch sync.Once // Starts the watcher behind the prompt's project tree
+
+	memoryKeyErr error // Set once by New; see loadMemoryKey
 }
 
 func New() *Brain {
+	setupTracing()
+
 	// Initialize config
 	cm, _ := sys.NewConfigManager()
 	cfg, _ := cm.Load()
@@ -63,10 +140,16 @@ func New() *Brain {
 		enclaveDir = filepath.Join(home, ".vibeauracle")
 	}
 
+	var audit *tooling.AuditLogger
 	enclave, err := tooling.NewEnclave(enclaveDir)
 	if err == nil {
-		guard.SetInterceptor(enclave.Interceptor)
+		guard.SetContextInterceptor(enclave.InterceptorContext)
+		audit = enclave.Audit()
+		audit.SetRotation(int64(cfg.Security.AuditMaxMB)<<20, cfg.Security.AuditKeep)
+		guard.SetAuditLogger(audit)
 	}
+	guard.SetToolPolicy(cfg.Security.ToolPolicy)
+	tooling.SetShowFileDiff(cfg.UI.ShowFileDiff)
 
 	b := &Brain{
 		monitor:  sys.NewMonitor(),
@@ -74,172 +157,608 @@ func New() *Brain {
 		cm:       cm,
 		auth:     auth.NewHandler(),
 		vault:    v,
-		memory:   vcontext.NewMemory(),
+		memory:   vcontext.NewMemory(cfg.Prompt.ContextTokens),
 		security: guard,
-		sessions: make(map[string]*tooling.Session),
+		enclave:  enclave,
+		audit:    audit,
+		sessions: make(map[string]*chatSession),
+		pins:     newPinSet(),
+		mcp:      make(map[string]*tooling.MCPProvider),
+		mcpErrs:  make(map[string]error),
+		mcpUp:    make(chan struct{}),
 	}
 
 	// Prompt system is modular and configurable.
 	b.prompts = prompt.New(cfg, b.memory, &prompt.NoopRecommender{})
+	b.prompts.SetPins(b)
+	b.prompts.SetPlan(b)
+	b.prompts.SetFailures(b)
+	b.prompts.SetThreads(b)
+	b.prompts.SetHistory(b)
+
+	// A bad or unreadable key leaves memory locked, which surfaces on first
+	// use and in `vibeaura doctor check`.
+	b.memoryKeyErr = b.loadMemoryKey()
+
+	// Threads are sealed like the rest of memory, so load them after the key.
+	b.chatSession(b.Session())
 
 	b.initProvider()
+	b.configureRecall()
 
 	// Proactive Autofix: If the configured model is missing or it's the first run,
 	// try to autodetect what's available on the system.
 	go b.autodetectBestModel()
 
-	b.fs = sys.NewLocalFS("")
-	b.tools = tooling.Setup(b.fs, b.monitor, b.security)
+	fs := sys.NewLocalFS("")
+	b.undo = sys.NewUndoJournal(b.dataDir(), fs.BaseDir(), cfg.Storage.Undo)
+	fs.SetUndoJournal(b.undo)
+	fs.SetPathPolicy(b.pathPolicy(fs.BaseDir()))
+	b.fs = fs
+	var cache *tooling.ToolCache
+	if cfg.Security.EnableToolCache {
+		cache = tooling.NewToolCache(0)
+	}
+	b.tools = tooling.Setup(b.fs, b.monitor, b.security, cache)
+
+	// MCP servers are external processes; start them without holding up startup.
+	b.mcpFiles = loadMCPServerFiles(b.dataDir())
+	go b.startMCPServers()
 
 	return b
 }
 
-func (b *Brain) initProvider() {
-	configMap := map[string]string{
-		"endpoint": b.config.Model.Endpoint,
-		"model":    b.config.Model.Name,
-		"base_url": b.config.Model.Endpoint, // Map endpoint to base_url for OpenAI/Others
+// pathPolicy confines the file tools to security.fs_roots, or to base
+// when none are set, and to the data dir. Paths elsewhere need
+// sys_request_access.
+func (b *Brain) pathPolicy(base string) *sys.PathPolicy {
+	roots := b.cfg().Security.FSRoots
+	if len(roots) == 0 {
+		roots = []string{base}
 	}
-
-	// Fetch credentials from vault
-	if b.vault != nil {
-		if token, err := b.vault.Get("github_models_pat"); err == nil {
-			configMap["token"] = token
-		}
-		if key, err := b.vault.Get("openai_api_key"); err == nil {
-			configMap["api_key"] = key
+	for _, r := range roots {
+		if sys.IsHome(r) {
+			doctor.Send("security", doctor.SignalWarning, "security.fs_roots includes your home directory; file tools can reach every file in it", nil)
 		}
 	}
+	return sys.NewPathPolicy(append(append([]string(nil), roots...), b.dataDir())...)
+}
+
+// initProvider builds the model for the active config and swaps it in.
+// Requests already running keep the model they started with.
+func (b *Brain) initProvider() {
+	cfg := b.cfg()
+	configMap := b.providerConfig(cfg.Model.Provider)
+	configMap["model"] = cfg.Model.Name
 
-	p, err := model.GetProvider(b.config.Model.Provider, configMap)
+	p, err := model.GetProvider(cfg.Model.Provider, configMap)
 	if err != nil {
 		// Fallback or log error
-		fmt.Printf("Error initializing provider %s: %v\n", b.config.Model.Provider, err)
+		fmt.Printf("Error initializing provider %s: %v\n", cfg.Model.Provider, err)
+	} else {
+		p = b.withUsage(p, cfg.Model.Name)
+	}
+	var m *model.Model
+	if chain := b.fallbackProviders(); len(chain) > 0 {
+		fm := model.NewFallbackModel(append([]model.Provider{p}, chain...)...)
+		fm.OnFailure = func(provider string, err error) {
+			doctor.Send("model", doctor.SignalWarning, fmt.Sprintf("%s failed, trying the next provider: %v", provider, err), nil)
+		}
+		m = model.New(b.withResponseCache(fm))
+	} else if p != nil {
+		m = model.New(b.withResponseCache(p))
+	} else {
+		m = model.New(nil)
 	}
-	b.model = model.New(p)
+	m.SetRetryPolicy(b.retryPolicy())
+	m.OnRetry = func(attempt, max int, err error) {
+		tooling.ReportStatus("🔁", "retry", fmt.Sprintf("retrying (%d/%d)... %v", attempt, max, err))
+	}
+
+	b.configMu.Lock()
+	b.model = m
+	b.configMu.Unlock()
 
 	// Update the prompt system's recommender to use the newly initialized model.
 	if b.prompts != nil {
-		b.prompts.SetRecommender(prompt.NewModelRecommender(b.model))
+		b.prompts.SetRecommender(prompt.NewModelRecommender(m))
 	}
 }
 
-// ModelDiscovery represents a discovered model with its provider
-type ModelDiscovery struct {
-	Name     string
-	Provider string
+// cfg returns the active config. It is shared with running requests, so
+// it is never changed in place; see UpdateConfig.
+func (b *Brain) cfg() *sys.Config {
+	b.configMu.RLock()
+	defer b.configMu.RUnlock()
+	return b.config
 }
 
-// DiscoverModels fetches available models from all configured providers
-func (b *Brain) DiscoverModels(ctx context.Context) ([]ModelDiscovery, error) {
-	var discoveries []ModelDiscovery
+// current returns the config and model a request runs with.
+func (b *Brain) current() (*sys.Config, *model.Model) {
+	b.switchMu.RLock()
+	defer b.switchMu.RUnlock()
+	b.configMu.RLock()
+	defer b.configMu.RUnlock()
+	return b.config, b.model
+}
 
-	// List of potential providers to check
-	providersToCheck := []string{"ollama", "openai", "github-models"}
+// setConfig swaps in cfg. It runs with switchMu held, followed by
+// initProvider.
+func (b *Brain) setConfig(cfg *sys.Config) {
+	b.configMu.Lock()
+	b.config = cfg
+	b.configMu.Unlock()
+	if b.prompts != nil {
+		b.prompts.SetConfig(cfg)
+	}
+}
 
-	for _, pName := range providersToCheck {
-		configMap := map[string]string{
-			"endpoint": b.config.Model.Endpoint,
-			"base_url": b.config.Model.Endpoint,
-		}
-
-		// Hydrate with credentials
-		if b.vault != nil {
-			switch pName {
-			case "github-models":
-				if token, err := b.vault.Get("github_models_pat"); err == nil {
-					configMap["token"] = token
-				} else {
-					continue // No token, skip
-				}
-			case "openai":
-				if key, err := b.vault.Get("openai_api_key"); err == nil {
-					configMap["api_key"] = key
-				} else {
-					continue // No key, skip
-				}
-			case "ollama":
-				// Usually no auth needed for local ollama
+// watchProjectTree starts watching dir, once, so the prompt's project tree
+// is only rendered again after files come or go. The watch is set up in
+// the background; until then, or if it fails, the tree is rendered on
+// every request.
+func (b *Brain) watchProjectTree(dir string) {
+	b.treeWatch.Do(func() {
+		if watcher.Ignored(filepath.Base(dir)) {
+			return // The watcher would skip the whole directory
+		}
+		go func() {
+			w, err := watcher.New()
+			if err != nil {
+				return
 			}
+			if err := w.AddRoot(dir); err != nil {
+				w.Stop()
+				return
+			}
+			b.prompts.AttachWatcher(w, dir)
+			w.Start()
+		}()
+	})
+}
+
+// withResponseCache wraps p in the response cache when model.cache_enabled
+// is set. If the cache cannot be opened p is used as is.
+func (b *Brain) withResponseCache(p model.Provider) model.Provider {
+	if !b.cfg().Model.CacheEnabled {
+		return p
+	}
+	replies, err := b.ResponseCache()
+	if err != nil {
+		doctor.Send("model", doctor.SignalWarning, fmt.Sprintf("response cache unavailable: %v", err), nil)
+		return p
+	}
+	replies.SetTTL(b.cfg().Model.CacheTTL)
+	cm := model.NewCachingModel(p, replies, b.cfg().Model.Name)
+	cm.OnHit = func() {
+		tooling.ReportStatus("💾", "cache", "cache hit")
+	}
+	return cm
+}
+
+// withUsage wraps p so its requests are recorded in the usage store and
+// kept to the provider's model.rate_limit.
+func (b *Brain) withUsage(p model.Provider, modelName string) model.Provider {
+	store, err := b.UsageStore()
+	if err != nil {
+		doctor.Send("model", doctor.SignalWarning, fmt.Sprintf("usage tracking unavailable: %v", err), nil)
+	}
+	um := model.NewUsageModel(p, store, b.rateLimiter(p.Name()), modelName)
+	um.OnError = func(err error) {
+		doctor.Send("model", doctor.SignalWarning, fmt.Sprintf("recording usage: %v", err), nil)
+	}
+	return um
+}
+
+// rateLimiter returns the limiter for provider, or nil if model.rate_limit
+// does not limit it. A provider's limiter is reused across re-inits so a
+// model switch does not reset its window.
+func (b *Brain) rateLimiter(provider string) *model.RateLimiter {
+	b.limitersMu.Lock()
+	defer b.limitersMu.Unlock()
+	limit := b.cfg().Model.RateLimit[provider]
+	l, ok := b.limiters[provider]
+	if !ok {
+		if limit <= 0 {
+			return nil
+		}
+		if b.limiters == nil {
+			b.limiters = make(map[string]*model.RateLimiter)
 		}
+		l = model.NewRateLimiter(provider, limit)
+		b.limiters[provider] = l
+	}
+	l.SetLimit(limit)
+	return l
+}
 
-		p, err := model.GetProvider(pName, configMap)
+// UsageStore returns the model usage store, opening it on first use.
+func (b *Brain) UsageStore() (*model.UsageStore, error) {
+	b.storesMu.Lock()
+	defer b.storesMu.Unlock()
+	if b.usage != nil {
+		return b.usage, nil
+	}
+	s, err := model.OpenUsageStore(filepath.Join(b.dataDir(), model.UsageFile))
+	if err != nil {
+		return nil, err
+	}
+	b.usage = s
+	return s, nil
+}
+
+// ResponseCache returns the model response cache, opening it even when
+// model.cache_enabled is off so it can be inspected and cleared.
+func (b *Brain) ResponseCache() (*model.ResponseCache, error) {
+	b.storesMu.Lock()
+	defer b.storesMu.Unlock()
+	if b.replies != nil {
+		return b.replies, nil
+	}
+	c, err := model.OpenResponseCache(filepath.Join(b.dataDir(), model.ResponseCacheFile), b.cfg().Model.CacheTTL, 0)
+	if err != nil {
+		return nil, err
+	}
+	b.replies = c
+	return c, nil
+}
+
+// configureRecall points long-term memory at a local Ollama embedding
+// model for semantic recall, at providers.ollama.endpoint.
+func (b *Brain) configureRecall() {
+	cfg := b.cfg()
+	if b.memory == nil || cfg == nil {
+		return
+	}
+	m := cfg.Memory
+	if m.EmbedModel == "" {
+		b.memory.SetEmbedder(nil, 0, 0)
+		return
+	}
+	endpoint := b.ollamaEndpoint()
+	b.memory.SetEmbedder(vcontext.NewOllamaEmbedder(endpoint, m.EmbedModel), m.SemanticThreshold, m.SemanticTopK)
+}
+
+// fallbackProviders builds the providers listed in model.fallbacks. An
+// entry is a provider name, optionally followed by ":<model>"; without a
+// model the provider's default is used. Entries that cannot be built are
+// reported to the doctor and skipped.
+func (b *Brain) fallbackProviders() []model.Provider {
+	var chain []model.Provider
+	for _, entry := range b.cfg().Model.Fallbacks {
+		pName, modelName, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if pName == "" {
+			continue
+		}
+		p, err := b.NewProvider(pName, modelName)
 		if err != nil {
+			doctor.Send("model", doctor.SignalWarning, fmt.Sprintf("fallback %s unavailable: %v", entry, err), nil)
 			continue
 		}
+		chain = append(chain, b.withUsage(p, modelName))
+	}
+	return chain
+}
 
-		models, err := p.ListModels(ctx)
-		if err != nil {
+// retryPolicy is the model's request timeout and retries from
+// model.request_timeout and model.max_retries.
+func (b *Brain) retryPolicy() model.RetryPolicy {
+	p := model.DefaultRetryPolicy()
+	if t := b.cfg().Model.RequestTimeout; t > 0 {
+		p.Timeout = t
+	}
+	if n := b.cfg().Model.MaxRetries; n >= 0 {
+		p.MaxRetries = n
+	}
+	return p
+}
+
+// ollamaEndpoint is providers.ollama.endpoint, or Ollama's default.
+func (b *Brain) ollamaEndpoint() string {
+	if e := b.cfg().Providers["ollama"].Endpoint; e != "" {
+		return e
+	}
+	return sys.DefaultOllamaEndpoint
+}
+
+// providerConfig builds the config map for a provider from its
+// providers.<name> block, hydrated with credentials from the vault.
+func (b *Brain) providerConfig(pName string) map[string]string {
+	pc := b.cfg().Providers[pName]
+	configMap := map[string]string{
+		"endpoint": pc.Endpoint,
+		"base_url": pc.BaseURL,
+	}
+	switch pName {
+	case "ollama":
+		configMap["endpoint"] = b.ollamaEndpoint()
+	case "azure-openai":
+		configMap["api_version"] = pc.APIVersion
+		configMap["deployments"] = strings.Join(pc.Deployments, ",")
+	}
+
+	if b.vault != nil {
+		switch pName {
+		case "github-models":
+			if token, err := b.vault.Get("github_models_pat"); err == nil {
+				configMap["token"] = token
+			}
+		case "openai":
+			if key, err := b.vault.Get("openai_api_key"); err == nil {
+				configMap["api_key"] = key
+			}
+		case "azure-openai":
+			if key, err := b.vault.Get("azure_openai_api_key"); err == nil {
+				configMap["api_key"] = key
+			}
+		case "anthropic":
+			if key, err := b.vault.Get("anthropic_api_key"); err == nil {
+				configMap["api_key"] = key
+			}
+		case "gemini":
+			if key, err := b.vault.Get("gemini_api_key"); err == nil {
+				configMap["api_key"] = key
+			}
+		}
+	}
+	return configMap
+}
+
+// NewProvider builds a standalone provider for the given model without
+// touching the active configuration.
+func (b *Brain) NewProvider(pName, modelName string) (model.Provider, error) {
+	configMap := b.providerConfig(pName)
+	configMap["model"] = modelName
+	return model.GetProvider(pName, configMap)
+}
+
+// ModelDiscovery represents a discovered model with its provider
+type ModelDiscovery struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+}
+
+// discoveryProviders are queried by DiscoverModels.
+var discoveryProviders = sys.ProviderNames
+
+// defaultDiscoveryTimeout bounds each provider's model listing when the
+// config does not set model.discovery_timeout.
+const defaultDiscoveryTimeout = 5 * time.Second
+
+// DiscoverModels fetches available models from all configured providers.
+// Providers are queried concurrently, each under its own timeout, and the
+// result is sorted by provider and then name. A non-empty result is saved
+// for CachedModels.
+func (b *Brain) DiscoverModels(ctx context.Context) ([]ModelDiscovery, error) {
+	timeout := defaultDiscoveryTimeout
+	if cfg := b.cfg(); cfg != nil && cfg.Model.DiscoveryTimeout > 0 {
+		timeout = cfg.Model.DiscoveryTimeout
+	}
+
+	providersToCheck := discoveryProviders
+
+	type result struct {
+		provider string
+		models   []string
+		err      error
+	}
+	results := make(chan result, len(providersToCheck))
+	var wg sync.WaitGroup
+
+	for _, pName := range providersToCheck {
+		if pc, ok := b.cfg().Providers[pName]; ok && !pc.Enabled {
 			continue
 		}
+		configMap := b.providerConfig(pName)
+
+		// Hosted providers are skipped when no credentials are stored
+		switch pName {
+		case "github-models":
+			if configMap["token"] == "" {
+				continue
+			}
+		case "openai", "anthropic", "gemini":
+			if configMap["api_key"] == "" {
+				continue
+			}
+		case "azure-openai":
+			if configMap["api_key"] == "" || configMap["endpoint"] == "" {
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(pName string, configMap map[string]string) {
+			defer wg.Done()
+			p, err := model.GetProvider(pName, configMap)
+			if err != nil {
+				results <- result{provider: pName, err: err}
+				return
+			}
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			models, err := p.ListModels(pctx)
+			if err != nil && pctx.Err() == context.DeadlineExceeded {
+				err = fmt.Errorf("no answer within %s: %w", timeout, err)
+			}
+			results <- result{provider: pName, models: models, err: err}
+		}(pName, configMap)
+	}
+	wg.Wait()
+	close(results)
 
-		for _, m := range models {
+	var discoveries []ModelDiscovery
+	var errs []error
+	for r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.provider, r.err))
+			continue
+		}
+		for _, m := range r.models {
 			discoveries = append(discoveries, ModelDiscovery{
 				Name:     m,
-				Provider: pName,
+				Provider: r.provider,
 			})
 		}
 	}
+	sort.Slice(discoveries, func(i, j int) bool {
+		if discoveries[i].Provider != discoveries[j].Provider {
+			return discoveries[i].Provider < discoveries[j].Provider
+		}
+		return discoveries[i].Name < discoveries[j].Name
+	})
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
 
+	// Partial failures are fine; an error means no provider answered at all.
+	if len(discoveries) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("no provider responded: %w", errors.Join(errs...))
+	}
+	if len(discoveries) > 0 {
+		b.saveModelsCache(discoveries)
+	}
 	return discoveries, nil
 }
 
-// SetModel updates the active model and provider
+// SetModel updates the active model and provider. Requests already
+// running finish with the model they started with.
 func (b *Brain) SetModel(provider, name string) error {
-	b.config.Model.Provider = provider
-	b.config.Model.Name = name
-
-	// If provider is ollama, we might need to handle endpoint too,
-	// but for now we keep the existing one or reset to default if changed.
-	if provider == "ollama" && b.config.Model.Endpoint == "" {
-		b.config.Model.Endpoint = "http://localhost:11434"
-	}
-
-	if err := b.cm.Save(b.config); err != nil {
+	b.switchMu.Lock()
+	defer b.switchMu.Unlock()
+	cfg := withModel(b.cfg(), provider, name)
+	if err := b.cm.Save(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
-
+	b.setConfig(cfg)
 	b.initProvider()
 	return nil
 }
 
+// UseModel switches to name on provider for this Brain only; the saved
+// config is left alone. An empty provider keeps the configured one.
+func (b *Brain) UseModel(provider, name string) {
+	b.switchMu.Lock()
+	defer b.switchMu.Unlock()
+	if provider == "" {
+		provider = b.cfg().Model.Provider
+	}
+	b.setConfig(withModel(b.cfg(), provider, name))
+	b.initProvider()
+}
+
+// withModel returns a copy of cfg using name on provider. If provider is
+// ollama, it makes sure it has an endpoint to talk to.
+func withModel(cfg *sys.Config, provider, name string) *sys.Config {
+	cfg = cfg.Clone()
+	cfg.Model.Provider = provider
+	cfg.Model.Name = name
+	if pc := cfg.Providers["ollama"]; provider == "ollama" && pc.Endpoint == "" {
+		pc.Endpoint = sys.DefaultOllamaEndpoint
+		cfg.SetProvider("ollama", pc)
+	}
+	return cfg
+}
+
 // Process handles the "Plan-Execute-Reflect" loop
 func (b *Brain) Process(ctx context.Context, req Request) (Response, error) {
+	return b.process(ctx, req, nil)
+}
+
+// Stream is Process with each chunk of model output sent to out as it is
+// generated. Stream closes out when it returns. Headless and batch callers
+// should keep using Process.
+func (b *Brain) Stream(ctx context.Context, req Request, out chan<- string) (Response, error) {
+	defer close(out)
+	return b.process(ctx, req, func(c StreamChunk) {
+		select {
+		case out <- c.Text:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// ProcessStream is Process with model output passed to onChunk as it is
+// generated. Cancelling ctx stops generation; chunks already delivered stand.
+func (b *Brain) ProcessStream(ctx context.Context, req Request, onChunk func(StreamChunk)) (Response, error) {
+	return b.process(ctx, req, onChunk)
+}
+
+func (b *Brain) process(ctx context.Context, req Request, onChunk func(StreamChunk)) (Response, error) {
+	cfg := b.cfg()
+	ctx, span := tracer.Start(ctx, "brain.process", trace.WithAttributes(
+		attribute.String("model.provider", cfg.Model.Provider),
+		attribute.String("model.name", cfg.Model.Name),
+		attribute.Int("prompt.length", len(req.Content)),
+	))
+	resp, err := b.processRequest(ctx, req, onChunk)
+	endRequestSpan(span, resp, err)
+	return resp, err
+}
+
+func (b *Brain) processRequest(ctx context.Context, req Request, onChunk func(StreamChunk)) (Response, error) {
 	tooling.ReportStatus("🧠", "think", "Processing request...")
 
 	// Early check for model
-	if b.model == nil {
+	if _, m := b.current(); m == nil {
 		tooling.ReportStatus("❌", "error", "No AI model configured")
 		return Response{}, fmt.Errorf("no AI model configured. Run 'vibeaura auth' to set up a provider")
 	}
 
 	// 1. Session & Thread Management
-	sessionID := "default" // In a real app, this would come from the request
-	session, ok := b.sessions[sessionID]
-	if !ok {
-		session = tooling.NewSession(sessionID)
-		b.sessions[sessionID] = session
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = b.Session()
 	}
+	session := b.chatSession(sessionID)
+	b.ledger(sessionID).nextTurn()
 
 	// 2. Perceive: Receive request + SystemSnapshot
 	snapshot, _ := b.monitor.GetSnapshot()
 	tooling.ReportStatus("👁️", "perceive", fmt.Sprintf("CWD: %s", snapshot.WorkingDir))
+	b.refreshProjectConfig(snapshot.WorkingDir)
+
+	// The request runs to the end with this config and model, whatever
+	// changes meanwhile.
+	cfg, activeModel := b.current()
 
 	// 3. Tool Awareness (Smart Handshake)
-	toolDefs := b.tools.GetPromptDefinitions(tooling.CoreTools())
-	tooling.ReportStatus("🔧", "tools", fmt.Sprintf("Loaded %d core tools", len(tooling.CoreTools())))
+	toolDefs := ""
+	if !req.NoTools {
+		toolDefs = b.tools.GetPromptDefinitions(tooling.CoreTools())
+		tooling.ReportStatus("🔧", "tools", fmt.Sprintf("Loaded %d core tools", len(tooling.CoreTools())))
+	}
 
 	// 4. Update Rolling Context Window
 	b.memory.AddToWindow(req.ID, req.Content, "user_prompt")
 
+	// Files and directories named by #path tags are attached to this
+	// request and pinned in the window.
+	tags := req.Tags
+	if tags == nil {
+		tags = b.ResolveTags(req.Content)
+		for _, t := range tags.Missing {
+			tooling.ReportStatus("⚠️", "tags", "No file or directory matches #"+t)
+		}
+	}
+	for _, f := range tags.Files {
+		b.memory.PinToWindow("tag:"+f.Tag, f.Content, "file")
+	}
+	attachments := tags.Block()
+
+	if used, budget := b.memory.WindowTokens(); budget > 0 {
+		tooling.ReportStatus("🪟", "context", fmt.Sprintf("Window: ~%d/%d tokens", used, budget))
+	}
+
 	// 5. Prompt System: classify + layer instructions + inject recall + build final prompt
-	augmentedPrompt := ""
+	systemPrompt, augmentedPrompt := "", ""
 	var recs []prompt.Recommendation
 	var promptIntent prompt.Intent
 
-	if b.config.Prompt.Enabled && b.prompts != nil {
+	if cfg.Prompt.Enabled && b.prompts != nil {
 		tooling.ReportStatus("📝", "prompt", "Building augmented prompt...")
-		env, builtRecs, err := b.prompts.Build(ctx, req.Content, snapshot, toolDefs)
+		if cfg.Prompt.IncludeTree {
+			b.watchProjectTree(snapshot.WorkingDir)
+		}
+		env, builtRecs, err := b.prompts.BuildWithAttachments(prompt.WithSession(ctx, sessionID), req.Content, attachments, snapshot, toolDefs)
 		if err != nil {
 			tooling.ReportStatus("❌", "error", fmt.Sprintf("Prompt build failed: %v", err))
 			return Response{}, fmt.Errorf("building prompt: %w", err)
@@ -248,6 +767,13 @@ func (b *Brain) Process(ctx context.Context, req Request) (Response, error) {
 			tooling.ReportStatus("⏭️", "skip", "Empty/invalid prompt ignored")
 			return Response{Content: "(ignored empty/invalid prompt)"}, nil
 		}
+		b.contextUsageMu.Lock()
+		b.contextUsage = env.Usage
+		b.contextUsageMu.Unlock()
+		if pct := env.Usage.Percent(); pct >= 0 {
+			tooling.ReportStatus("📊", "context_usage", fmt.Sprintf("Prompt: ~%d/%d tokens (%d%%)", env.Usage.Tokens, env.Usage.Limit, pct))
+		}
+		systemPrompt = env.System
 		augmentedPrompt = env.Prompt
 		recs = builtRecs
 		promptIntent = env.Intent
@@ -264,22 +790,97 @@ func (b *Brain) Process(ctx context.Context, req Request) (Response, error) {
 System CWD: %s
 Available Tools (JSON-RPC 2.0 Style):
 %s
-
+%s
 User Request (Thread ID: %s):
-%s`, contextStr, snapshot.WorkingDir, toolDefs, req.ID, req.Content)
+%s`, contextStr, snapshot.WorkingDir, toolDefs, attachments, req.ID, req.Content)
 	}
 
-	// EXECUTION LOOP (Agentic)
-	maxTurns := 5
-	history := augmentedPrompt
+	// 6. Conversation: system message, earlier turns, then this request.
+	var messages []model.Message
+	if systemPrompt != "" {
+		messages = append(messages, model.Message{Role: model.RoleSystem, Content: systemPrompt})
+	}
+	messages = append(messages, b.conversation(sessionID)...)
+	messages = append(messages, model.Message{Role: model.RoleUser, Content: augmentedPrompt})
+
+	return b.runLoop(ctx, &loopState{
+		req:       req,
+		model:     activeModel,
+		sessionID: sessionID,
+		session:   session,
+		messages:  messages,
+		recs:      recs,
+		intent:    promptIntent,
+	}, onChunk)
+}
+
+// maxTurns bounds the agent loop, counted across approval pauses.
+const maxTurns = 5
+
+// cancelledMessage is the reply to a request whose context was cancelled
+// before the loop finished. The error is the context's.
+const cancelledMessage = "Request cancelled."
+
+// loopState is an agent loop in progress. It is kept while the loop waits
+// for the user to answer an intervention.
+type loopState struct {
+	req       Request
+	model     *model.Model // Kept for the whole request, even if the provider changes
+	sessionID string
+	session   *chatSession
+	messages  []model.Message
+	turn      int
+	recs      []prompt.Recommendation
+	intent    prompt.Intent
+	call      toolCall   // The call awaiting approval
+	pending   []toolCall // Calls from the same turn still to run after it
+	executed  []ExecutedCall
+	timedOut  bool   // A request has already timed out and been retried
+	reply     string // The model's latest reply, kept if the loop is cancelled
+}
 
-	for i := 0; i < maxTurns; i++ {
+// runLoop is the agentic execution loop: generate, run the requested tools,
+// feed the observations back, until the model answers without a tool call.
+func (b *Brain) runLoop(ctx context.Context, st *loopState, onChunk func(StreamChunk)) (Response, error) {
+	req := st.req
+	for ; st.turn < maxTurns; st.turn++ {
+		if ctx.Err() != nil {
+			return b.stopLoop(ctx, st)
+		}
+		i := st.turn
 		tooling.ReportStatus("🔄", "loop", fmt.Sprintf("Turn %d/%d: Generating...", i+1, maxTurns))
+		turnCtx, turnSpan := tracer.Start(ctx, "brain.turn", trace.WithAttributes(attribute.Int("turn", i+1)))
 
 		// 1. Generate
-		resp, err := b.model.Generate(ctx, history)
+		resp, calls, err := b.generate(turnCtx, st, onChunk)
+		if err != nil && errors.Is(err, model.ErrRequestTimeout) && !st.timedOut && ctx.Err() == nil {
+			// A slow answer is worth one more try, asking for a shorter
+			// one; the turn does not count against maxTurns.
+			st.timedOut = true
+			tooling.ReportStatus("⏱️", "timeout", "Model request timed out, asking again")
+			st.observe("The previous request timed out before you answered. Answer again, more briefly.")
+			st.turn--
+			endSpan(turnSpan, err)
+			continue
+		}
+		if ctx.Err() != nil {
+			// Stopped mid-turn: whatever the model sent is dropped and no
+			// tool it asked for runs.
+			endSpan(turnSpan, ctx.Err())
+			return b.stopLoop(ctx, st)
+		}
+		var limited *model.RateLimitedError
+		if errors.As(err, &limited) {
+			// Refused before reaching the API, so say when to try again
+			// rather than reporting a failure.
+			tooling.ReportStatus("🚦", "rate-limit", fmt.Sprintf("%s limit reached, next slot in %s", limited.Provider, limited.RetryAfter.Round(time.Second)))
+			endSpan(turnSpan, err)
+			return Response{}, fmt.Errorf("%s allows %d requests a minute (model.rate_limit); try again in %s: %w",
+				limited.Provider, limited.Limit, limited.RetryAfter.Round(time.Second), model.ErrRateLimited)
+		}
 		if err != nil {
 			tooling.ReportStatus("❌", "error", fmt.Sprintf("Model error: %v", err))
+			endSpan(turnSpan, err)
 			return Response{}, fmt.Errorf("generating response: %w", err)
 		}
 
@@ -289,107 +890,338 @@ User Request (Thread ID: %s):
 			preview = preview[:100] + "..."
 		}
 		tooling.ReportStatus("💬", "response", preview)
+		b.trackPlan(resp, st.intent)
 
-		// 2. Parse & Execute Tools
-		executed, resultVal, interventionErr, execErr := b.executeToolCalls(ctx, resp)
-
-		// Bubble up intervention immediately so UI can handle it
-		if interventionErr != nil {
-			tooling.ReportStatus("⚠️", "intervention", "User approval required")
-			return Response{}, interventionErr
-		}
-
-		if !executed {
+		if len(calls) == 0 {
 			tooling.ReportStatus("✅", "done", "No tool call, returning response")
 			// No tool calls? We are done.
-			session.AddThread(&tooling.Thread{
+			b.recordThread(st, &tooling.Thread{
 				ID:       req.ID,
 				Prompt:   req.Content,
 				Response: resp,
 				Metadata: map[string]interface{}{
-					"prompt_intent":    promptIntent,
-					"recommendations":  recs,
+					"prompt_intent":    st.intent,
+					"recommendations":  st.recs,
 					"response_raw_len": len(resp),
+					"tool_calls":       len(st.executed),
 				},
 			})
 			_ = b.memory.Store(req.ID, resp)
-			return Response{Content: resp}, nil
+			b.remember(st.sessionID, req.Content, resp)
+			turnSpan.End()
+			return Response{Content: resp, Intent: string(st.intent), ToolCalls: st.executed}, nil
 		}
 
-		// 3. Observation (feed back into history)
-		if execErr != nil {
-			tooling.ReportStatus("❌", "tool", fmt.Sprintf("Tool error: %v", execErr))
-			history += fmt.Sprintf("\n\nUser: Tool Execution Failed: %v\nSystem:", execErr)
-		} else {
-			resultPreview := resultVal
-			if len(resultPreview) > 80 {
-				resultPreview = resultPreview[:80] + "..."
+		st.messages = append(st.messages, assistantTurn(resp, calls))
+		st.reply = resp
+
+		// 2. Execute each call in order, feeding every result back.
+		// Bubble up intervention immediately so UI can handle it. The loop
+		// is parked until ResumeIntervention or DenyIntervention.
+		if err := b.runCalls(turnCtx, st, calls); err != nil {
+			if ctx.Err() != nil {
+				endSpan(turnSpan, err)
+				return b.stopLoop(ctx, st)
 			}
-			tooling.ReportStatus("✅", "tool", fmt.Sprintf("Result: %s", resultPreview))
-			history += fmt.Sprintf("\n\nUser: Tool Output: %s\nSystem:", resultVal)
+			tooling.ReportStatus("⚠️", "intervention", "User approval required")
+			b.suspend(st, err)
+			endSpan(turnSpan, err)
+			return Response{}, err
 		}
-
-		// 4. Record intermediate step
-		_ = b.memory.Store(req.ID+"_step_"+fmt.Sprint(i), resultVal)
+		turnSpan.End()
 	}
 
 	tooling.ReportStatus("⚠️", "limit", "Agent loop limit reached")
-	return Response{Content: "Agent loop limit reached."}, nil
+	return Response{Content: "Agent loop limit reached.", Intent: string(st.intent), ToolCalls: st.executed}, nil
+}
+
+// stopLoop ends a loop whose context was cancelled. The turns that
+// finished are still recorded, so the thread, memory and conversation
+// show how far the request got before it was stopped.
+func (b *Brain) stopLoop(ctx context.Context, st *loopState) (Response, error) {
+	tooling.ReportStatus("⏹️", "cancel", "Request cancelled")
+	partial := cancelledMessage
+	if st.reply != "" {
+		partial = st.reply + "\n\n" + cancelledMessage
+	}
+	b.recordThread(st, &tooling.Thread{
+		ID:       st.req.ID,
+		Prompt:   st.req.Content,
+		Response: partial,
+		Metadata: map[string]interface{}{
+			"prompt_intent": st.intent,
+			"cancelled":     true,
+			"tool_calls":    len(st.executed),
+		},
+	})
+	_ = b.memory.Store(st.req.ID, partial)
+	b.remember(st.sessionID, st.req.Content, partial)
+	return Response{Content: cancelledMessage, Intent: string(st.intent), ToolCalls: st.executed}, ctx.Err()
+}
+
+// generate runs one model turn and returns its text and tool calls.
+// Providers with native tool calling get the core tools as structured
+// definitions; the rest fall back to parsing a ```json block out of the
+// text. Native replies are not streamed token by token, so their text is
+// passed to onChunk whole. A NoTools request is answered as plain text.
+func (b *Brain) generate(ctx context.Context, st *loopState, onChunk func(StreamChunk)) (string, []toolCall, error) {
+	genCtx, span := tracer.Start(ctx, "brain.generate", trace.WithAttributes(
+		attribute.Int("prompt.length", messagesLength(st.messages)),
+	))
+	text, native, err := b.complete(genCtx, st, onChunk)
+	span.SetAttributes(attribute.Int("response.length", len(text)))
+	endSpan(span, err)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, span = tracer.Start(ctx, "brain.parse")
+	calls := b.parseCalls(st, text, native)
+	span.SetAttributes(attribute.Int("tool_calls", len(calls)))
+	span.End()
+	return text, calls, nil
+}
+
+// complete asks the model for the turn's reply. native is set when the
+// provider answered through native tool calling.
+func (b *Brain) complete(ctx context.Context, st *loopState, onChunk func(StreamChunk)) (text string, native *model.ToolResponse, err error) {
+	turn := st.turn
+	emit := func(text string) {
+		onChunk(StreamChunk{RequestID: st.req.ID, Turn: turn, Text: text})
+	}
+
+	err = model.ErrToolsUnsupported
+	var tr *model.ToolResponse
+	if tools := b.nativeTools(); len(tools) > 0 && !st.req.NoTools {
+		tr, err = st.model.GenerateWithTools(ctx, st.messages, tools)
+	}
+	if err == nil {
+		if onChunk != nil && tr.Text != "" {
+			emit(tr.Text)
+		}
+		return tr.Text, tr, nil
+	}
+	if !errors.Is(err, model.ErrToolsUnsupported) {
+		return "", nil, err
+	}
+
+	if onChunk != nil {
+		text, err = st.model.StreamChat(ctx, st.messages, emit)
+	} else {
+		text, err = st.model.GenerateChat(ctx, st.messages)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return text, nil, nil
+}
+
+// parseCalls returns the tool calls in a reply from complete.
+func (b *Brain) parseCalls(st *loopState, text string, native *model.ToolResponse) []toolCall {
+	if native == nil {
+		if call, ok := parseToolCall(text); ok && !st.req.NoTools {
+			return []toolCall{call}
+		}
+		return nil
+	}
+	calls := make([]toolCall, 0, len(native.Calls))
+	for _, c := range native.Calls {
+		calls = append(calls, toolCall{ID: c.ID, Tool: c.Name, Args: c.Arguments})
+	}
+	if len(calls) == 0 {
+		// The prompt still documents the fenced format, so accept it,
+		// but only for a registered tool: code samples are not calls.
+		if call, ok := parseToolCall(text); ok {
+			if _, found := b.tools.Get(call.Tool); found {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// nativeTools describes the core tools for providers with native tool
+// calling.
+func (b *Brain) nativeTools() []model.ToolMetadata {
+	var defs []model.ToolMetadata
+	for _, name := range tooling.CoreTools() {
+		t, ok := b.tools.Get(name)
+		if !ok {
+			continue
+		}
+		m := t.Metadata()
+		defs = append(defs, model.ToolMetadata{Name: m.Name, Description: m.Description, Parameters: m.Parameters})
+	}
+	return defs
 }
 
-// executeToolCalls parses the response for JSON tool invocations and executes them.
-func (b *Brain) executeToolCalls(ctx context.Context, input string) (bool, string, error, error) {
-	// Simple JSON block parser: Look for ```json { "tool": ... } ```
-	start := strings.Index(input, "```json")
-	if start == -1 {
-		return false, "", nil, nil
+// runCalls executes calls in order and observes each result. On an
+// intervention it records the call awaiting approval and the ones after
+// it, and returns the InterventionError.
+func (b *Brain) runCalls(ctx context.Context, st *loopState, calls []toolCall) error {
+	for i, call := range calls {
+		if err := ctx.Err(); err != nil {
+			// Cancelled: this call and the rest of the turn never run.
+			return err
+		}
+		tool := attribute.String("tool.name", call.Tool)
+		execCtx, span := tracer.Start(ctx, "brain.execute", trace.WithAttributes(tool))
+		resultVal, interventionErr, execErr := b.executeToolCall(execCtx, st.sessionID, call)
+		span.SetAttributes(attribute.Int("result.length", len(resultVal)))
+		if interventionErr != nil {
+			endSpan(span, interventionErr)
+			st.call = call
+			st.pending = calls[i+1:]
+			return interventionErr
+		}
+		endSpan(span, execErr)
+
+		_, span = tracer.Start(ctx, "brain.observe", trace.WithAttributes(tool))
+		b.observe(st, call, resultVal, execErr)
+		span.End()
 	}
+	st.pending = nil
+	return nil
+}
 
-	// Find closing block logic
-	// We start searching AFTER the "```json" (length 7)
-	contentStart := start + 7
-	blockContent := input[contentStart:]
+// observe appends a tool outcome to the loop's messages and records the step.
+// Results of native calls go back as tool messages answering the call ID.
+func (b *Brain) observe(st *loopState, call toolCall, resultVal string, execErr error) {
+	executed := ExecutedCall{Tool: call.Tool, Args: call.Args}
+	if execErr != nil {
+		executed.Error = execErr.Error()
+	}
+	st.executed = append(st.executed, executed)
 
-	end := strings.Index(blockContent, "```")
-	if end == -1 {
-		return false, "", nil, nil
+	var text string
+	if execErr != nil {
+		tooling.ReportStatus("❌", "tool", fmt.Sprintf("Tool error: %v", execErr))
+		text = fmt.Sprintf("Tool Execution Failed: %v", execErr)
+	} else {
+		resultPreview := resultVal
+		if len(resultPreview) > 80 {
+			resultPreview = resultPreview[:80] + "..."
+		}
+		tooling.ReportStatus("✅", "tool", fmt.Sprintf("Result: %s", resultPreview))
+		text = resultVal
+		if call.ID == "" {
+			text = "Tool Output: " + resultVal
+		}
+	}
+	if call.ID != "" {
+		st.messages = append(st.messages, model.Message{Role: model.RoleTool, ToolCallID: call.ID, Content: text})
+	} else {
+		st.observe(text)
 	}
 
-	jsonStr := strings.TrimSpace(blockContent[:end])
+	// 4. Record intermediate step
+	step := st.req.ID + "_step_" + fmt.Sprint(st.turn)
+	if call.ID != "" {
+		step += "_" + call.ID
+	}
+	_ = b.memory.Store(step, resultVal)
+}
+
+func (st *loopState) observe(text string) {
+	st.messages = append(st.messages, model.Message{Role: model.RoleUser, Content: text})
+}
 
-	// Attempt to parse tool call
-	var call struct {
-		Tool string          `json:"tool"`
-		Args json.RawMessage `json:"parameters"`
+// assistantTurn is the message for a reply that asked for calls. Native
+// calls are kept on it so the tool messages after it can answer them.
+func assistantTurn(text string, calls []toolCall) model.Message {
+	msg := model.Message{Role: model.RoleAssistant, Content: text}
+	for _, c := range calls {
+		if c.ID != "" {
+			msg.ToolCalls = append(msg.ToolCalls, model.ToolCall{ID: c.ID, Name: c.Tool, Arguments: c.Args})
+		}
 	}
-	// Try parsing. If it fails, maybe it's not a tool call.
-	if err := json.Unmarshal([]byte(jsonStr), &call); err != nil {
-		return false, "", nil, nil
+	return msg
+}
+
+// toolCall is a tool invocation emitted by the model. ID is set for
+// native tool calls only.
+type toolCall struct {
+	ID   string          `json:"-"`
+	Tool string          `json:"tool"`
+	Args json.RawMessage `json:"parameters"`
+}
+
+// parseToolCall extracts the first ```json { "tool": ... } ``` block.
+func parseToolCall(input string) (toolCall, bool) {
+	calls := parseToolCalls(input)
+	if len(calls) == 0 {
+		return toolCall{}, false
+	}
+	return calls[0], true
+}
+
+// parseToolCalls extracts every ```json { "tool": ... } ``` block in order.
+// Blocks that are not tool calls are skipped.
+func parseToolCalls(input string) []toolCall {
+	var calls []toolCall
+	for {
+		start := strings.Index(input, "```json")
+		if start == -1 {
+			return calls
+		}
+
+		// Find the closing fence after the "```json" (length 7)
+		blockContent := input[start+7:]
+		end := strings.Index(blockContent, "```")
+		if end == -1 {
+			return calls
+		}
+		input = blockContent[end+3:]
+
+		var call toolCall
+		if err := json.Unmarshal([]byte(strings.TrimSpace(blockContent[:end])), &call); err != nil || call.Tool == "" {
+			continue
+		}
+		calls = append(calls, call)
 	}
+}
 
-	if call.Tool == "" {
-		return false, "", nil, nil
+// executeToolCall runs one tool invocation. Failures, including writes
+// that fail the post-write check, go to the session's ledger; a call that
+// already failed failureBlockThreshold times is refused with a
+// RepeatedFailureError.
+func (b *Brain) executeToolCall(ctx context.Context, sessionID string, call toolCall) (string, error, error) {
+	ledger := b.ledger(sessionID)
+	key := failureKey(call.Tool, call.Args)
+	if entry, blocked := ledger.blocked(key); blocked {
+		tooling.ReportStatus("🚫", "tool", fmt.Sprintf("Blocked repeat of failing call %s", call.Tool))
+		return "", nil, &RepeatedFailureError{Entry: entry}
 	}
 
-	// Found a tool call!
+	b.trackPlanTool(call)
 	t, found := b.tools.Get(call.Tool)
 	if !found {
-		return true, "", nil, fmt.Errorf("tool '%s' not found", call.Tool)
+		err := fmt.Errorf("tool '%s' not found", call.Tool)
+		ledger.record(key, call.Tool, err)
+		return "", nil, err
 	}
 
 	res, err := t.Execute(ctx, call.Args)
+	var intervention *tooling.InterventionError
+	if errors.As(err, &intervention) {
+		return "", err, nil
+	}
+	if err == nil && res != nil && res.Error != nil {
+		err = res.Error
+	}
 	if err != nil {
-		return true, "", err, err
+		ledger.record(key, call.Tool, err)
+		return "", nil, err
 	}
 
-	return true, res.Content, nil, nil
+	return b.settle(ledger, key, call, res.ModelText()), nil, nil
 }
 
 // PullModel requests a model download (currently only supported by Ollama)
 func (b *Brain) PullModel(ctx context.Context, name string) error {
 	// Re-initialize provider to ensure we have the latest endpoint
 	configMap := map[string]string{
-		"endpoint": b.config.Model.Endpoint,
+		"endpoint": b.ollamaEndpoint(),
 		"model":    name,
 	}
 
@@ -423,26 +1255,95 @@ func (b *Brain) ClearState(id string) error {
 	return b.memory.ClearState(id)
 }
 
-// GetConfig returns the brain's configuration
+// ArchiveState archives messages under id before replacing the live state
+func (b *Brain) ArchiveState(id string, messages []string, next interface{}) error {
+	_, err := b.memory.ArchiveAndReplace(id, messages, next)
+	return err
+}
+
+// UnarchiveState pops the most recent archive for id
+func (b *Brain) UnarchiveState(id string) ([]string, error) {
+	a, err := b.memory.LatestArchive(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.memory.DeleteArchive(a.ID); err != nil {
+		return nil, err
+	}
+	return a.Messages, nil
+}
+
+// GetConfig returns the brain's configuration. It is read-only; to change
+// it, pass a changed Clone to UpdateConfig.
 func (b *Brain) GetConfig() *sys.Config {
-	return b.config
+	return b.cfg()
 }
 
 // Config is an alias for GetConfig
 func (b *Brain) Config() *sys.Config {
-	return b.config
+	return b.cfg()
+}
+
+// Enclave returns the approval enclave, or nil if it is unavailable.
+func (b *Brain) Enclave() *tooling.Enclave {
+	return b.enclave
+}
+
+// AuditLog returns the enclave's audit logger, or nil if the enclave is
+// unavailable.
+func (b *Brain) AuditLog() *tooling.AuditLogger {
+	return b.audit
 }
 
-// UpdateConfig updates the brain's configuration and persists it
+// UpdateConfig persists cfg and makes it the active config. Start from
+// Config().Clone(): the active config is shared with running requests and
+// must not be changed in place. Those requests finish with the model they
+// started with.
 func (b *Brain) UpdateConfig(cfg *sys.Config) error {
-	b.config = cfg
-	if err := b.cm.Save(b.config); err != nil {
+	b.switchMu.Lock()
+	defer b.switchMu.Unlock()
+	cfg = cfg.Clone()
+	if err := b.cm.Save(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
+	b.setConfig(cfg)
+	b.security.SetToolPolicy(cfg.Security.ToolPolicy)
+	tooling.SetShowFileDiff(cfg.UI.ShowFileDiff)
 	b.initProvider()
 	return nil
 }
 
+// refreshProjectConfig re-resolves the project config for dir, so that
+// after a change of directory that project's .vibeaura.yaml applies.
+func (b *Brain) refreshProjectConfig(dir string) {
+	if b.cm == nil || dir == "" {
+		return
+	}
+	b.switchMu.Lock()
+	defer b.switchMu.Unlock()
+	changed, err := b.cm.SetProjectDir(dir)
+	if err != nil {
+		tooling.ReportStatus("⚠️", "config", err.Error())
+		return
+	}
+	if !changed {
+		return
+	}
+	cfg, err := b.cm.Load()
+	if err != nil {
+		tooling.ReportStatus("⚠️", "config", err.Error())
+		return
+	}
+	b.setConfig(cfg)
+	b.security.SetToolPolicy(cfg.Security.ToolPolicy)
+	b.initProvider()
+	if path := b.cm.ProjectPath(); path != "" {
+		tooling.ReportStatus("⚙️", "config", "Using project config "+path)
+	} else {
+		tooling.ReportStatus("⚙️", "config", "No project config; using global settings")
+	}
+}
+
 // GetSnapshot returns a current snapshot of system resources via the monitor
 func (b *Brain) GetSnapshot() (sys.Snapshot, error) {
 	return b.monitor.GetSnapshot()
@@ -459,7 +1360,7 @@ func (b *Brain) StoreSecret(key, value string) error {
 func (b *Brain) autodetectBestModel() {
 	// Only autodetect if we are using the default "llama3" which might not exist,
 	// or if the model name is empty/none.
-	if b.config.Model.Name != "llama3" && b.config.Model.Name != "" && b.config.Model.Name != "none" {
+	if name := b.cfg().Model.Name; name != "llama3" && name != "" && name != "none" {
 		return
 	}
 
@@ -491,3 +1392,26 @@ func (b *Brain) GetSecret(key string) (string, error) {
 	}
 	return b.vault.Get(key)
 }
+
+// UnlockVault asks for the vault passphrase now, if the vault file has one,
+// and never again: later use that still needs it fails with
+// vault.ErrLocked rather than prompting. Call it before taking over the
+// terminal.
+func (b *Brain) UnlockVault() error {
+	if b.vault == nil {
+		return nil
+	}
+	err := b.vault.Unlock()
+	b.vault.SetPassphraseFunc(func() (string, error) { return "", vault.ErrLocked })
+	return err
+}
+
+// MigrateVault encrypts a leftover plaintext secrets file and re-keys the
+// vault file, with passphrase if given. Secrets held by the OS keyring are
+// not touched.
+func (b *Brain) MigrateVault(passphrase string) (moved, total int, err error) {
+	if b.vault == nil {
+		return 0, 0, fmt.Errorf("vault not initialized")
+	}
+	return b.vault.Migrate(passphrase)
+}
@@ -2,9 +2,16 @@ package brain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
 )
 
 type MockProvider struct{}
@@ -13,6 +20,18 @@ func (m *MockProvider) Generate(ctx context.Context, prompt string) (string, err
 	return "Mocked AI Response", nil
 }
 
+func (m *MockProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, m, prompt, out)
+}
+
+func (m *MockProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, m, messages)
+}
+
+func (m *MockProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	return model.NoNativeTools(ctx, messages, tools)
+}
+
 func (m *MockProvider) ListModels(ctx context.Context) ([]string, error) {
 	return []string{"mock-model"}, nil
 }
@@ -22,6 +41,7 @@ func (m *MockProvider) Name() string {
 }
 
 func TestBrain_Process(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	b := New()
 	// Inject mock provider to avoid network/ollama dependency in tests
 	b.model = model.New(&MockProvider{})
@@ -41,3 +61,317 @@ func TestBrain_Process(t *testing.T) {
 	}
 }
 
+// streamingProvider emits its response in fixed pieces.
+type streamingProvider struct {
+	MockProvider
+	chunks []string
+}
+
+func (p *streamingProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	var full string
+	for _, c := range p.chunks {
+		out <- c
+		full += c
+	}
+	return full, nil
+}
+
+func TestBrain_ProcessStream(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	b.model = model.New(&streamingProvider{chunks: []string{"Mocked ", "AI ", "Response"}})
+
+	var got []StreamChunk
+	resp, err := b.ProcessStream(context.Background(), Request{ID: "stream-1", Content: "Hello Brain"}, func(c StreamChunk) {
+		got = append(got, c)
+	})
+	if err != nil {
+		t.Fatalf("Brain processing failed: %v", err)
+	}
+	if resp.Content != "Mocked AI Response" {
+		t.Errorf("Unexpected brain response: %s", resp.Content)
+	}
+	if len(got) != 3 || got[0].RequestID != "stream-1" || got[2].Text != "Response" {
+		t.Errorf("Unexpected chunks: %+v", got)
+	}
+}
+
+func TestBrain_Stream(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	b.model = model.New(&streamingProvider{chunks: []string{"Mocked ", "AI ", "Response"}})
+
+	out := make(chan string)
+	var got string
+	done := make(chan struct{})
+	go func() {
+		for c := range out {
+			got += c
+		}
+		close(done)
+	}()
+
+	resp, err := b.Stream(context.Background(), Request{ID: "stream-2", Content: "Hello Brain"}, out)
+	<-done
+	if err != nil {
+		t.Fatalf("Brain processing failed: %v", err)
+	}
+	if got != "Mocked AI Response" || resp.Content != got {
+		t.Errorf("Expected streamed text to match the response, got %q and %q", got, resp.Content)
+	}
+}
+
+// stallingProvider never answers its first stalls requests, then replies.
+type stallingProvider struct {
+	stalls  int
+	prompts []string
+}
+
+func (p *stallingProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.prompts) <= p.stalls {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return "Short answer.", nil
+}
+
+func (p *stallingProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, p, prompt, out)
+}
+
+func (p *stallingProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+func (p *stallingProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	return model.NoNativeTools(ctx, messages, tools)
+}
+func (p *stallingProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *stallingProvider) Name() string                                     { return "stalling" }
+
+func TestProcess_RecoversFromOneTimeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	provider := &stallingProvider{stalls: 1}
+	b.model = model.New(provider)
+	b.model.SetRetryPolicy(model.RetryPolicy{Timeout: 50 * time.Millisecond})
+
+	resp, err := b.Process(context.Background(), Request{ID: "slow-1", SessionID: "slow-1", Content: "explain everything"})
+	if err != nil {
+		t.Fatalf("expected the timed-out turn to be retried, got %v", err)
+	}
+	if resp.Content != "Short answer." || len(provider.prompts) != 2 {
+		t.Fatalf("expected an answer on the second request, got %q after %d", resp.Content, len(provider.prompts))
+	}
+	if !strings.Contains(provider.prompts[1], "timed out") {
+		t.Errorf("expected the timeout fed back into the conversation, got:\n%s", provider.prompts[1])
+	}
+}
+
+func TestProcess_AbortsOnSecondTimeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	provider := &stallingProvider{stalls: 2}
+	b.model = model.New(provider)
+	b.model.SetRetryPolicy(model.RetryPolicy{Timeout: 50 * time.Millisecond})
+
+	_, err := b.Process(context.Background(), Request{ID: "slow-2", SessionID: "slow-2", Content: "explain everything"})
+	if !errors.Is(err, model.ErrRequestTimeout) || len(provider.prompts) != 2 {
+		t.Fatalf("expected ErrRequestTimeout after two requests, got %v after %d", err, len(provider.prompts))
+	}
+}
+
+func TestProcess_RateLimited(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	b.config.Model.RateLimit = map[string]int{"mock": 1}
+	b.model = model.New(b.withUsage(&MockProvider{}, "mock-model"))
+
+	if _, err := b.Process(context.Background(), Request{ID: "rl-1", SessionID: "rl", Content: "hi"}); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	_, err := b.Process(context.Background(), Request{ID: "rl-2", SessionID: "rl", Content: "again"})
+	if !errors.Is(err, model.ErrRateLimited) || !strings.Contains(err.Error(), "1 requests a minute") {
+		t.Fatalf("expected the rate limit explained, got %v", err)
+	}
+
+	store, _ := b.UsageStore()
+	usage, _ := store.Summary(time.Time{})
+	if len(usage) != 1 || usage[0].Requests != 1 || usage[0].Model != "mock-model" {
+		t.Errorf("expected only the request sent to be recorded, got %+v", usage)
+	}
+}
+
+// cancellingProvider cancels the request while answering, then answers
+// anyway, like a provider that ignores its context.
+type cancellingProvider struct {
+	scriptedProvider
+	cancel context.CancelFunc
+}
+
+func (p *cancellingProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.cancel()
+	return p.scriptedProvider.Generate(ctx, prompt)
+}
+
+func (p *cancellingProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+
+func TestProcess_StopsWhenCancelled(t *testing.T) {
+	b, scripted, tool := newGatedBrain(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	b.model = model.New(&cancellingProvider{scriptedProvider: *scripted, cancel: cancel})
+
+	resp, err := b.Process(ctx, Request{ID: "cancel-1", Content: "write notes"})
+	if !errors.Is(err, context.Canceled) || resp.Content != cancelledMessage {
+		t.Fatalf("expected a cancelled reply, got %q (%v)", resp.Content, err)
+	}
+	if tool.ran != 0 || b.takeSuspended() != nil {
+		t.Error("expected the requested tool not to run after cancellation")
+	}
+}
+
+func TestResumeIntervention_StopsBetweenTurnsWhenCancelled(t *testing.T) {
+	b, provider, tool := newGatedBrain(t)
+	req := Request{ID: "cancel-2", SessionID: "s", Content: "write notes"}
+	if _, err := b.Process(context.Background(), req); err == nil {
+		t.Fatal("expected the gated tool to ask for approval")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err := b.ResumeIntervention(ctx, tooling.ChoiceApproveOnce, nil)
+	if !errors.Is(err, context.Canceled) || resp.Content != cancelledMessage {
+		t.Fatalf("expected a cancelled reply, got %q (%v)", resp.Content, err)
+	}
+	if tool.ran != 1 || len(provider.prompts) != 1 {
+		t.Fatalf("expected the approved call to run and no further turn, ran %d, %d prompts", tool.ran, len(provider.prompts))
+	}
+
+	conv := b.conversation("s")
+	if len(conv) != 2 || !strings.HasPrefix(conv[1].Content, "Writing it.") || !strings.HasSuffix(conv[1].Content, cancelledMessage) {
+		t.Errorf("expected the partial reply kept in the conversation, got %+v", conv)
+	}
+}
+
+// namedProvider answers with the model it was built for. With a gate,
+// it signals entered and waits for release before answering.
+type namedProvider struct {
+	MockProvider
+	model string
+	gate  *providerGate
+}
+
+type providerGate struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p *namedProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	if p.gate != nil {
+		p.gate.entered <- struct{}{}
+		<-p.gate.release
+	}
+	return "answered by " + p.model, nil
+}
+
+func (p *namedProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+
+func registerNamedProvider(gate *providerGate) {
+	model.Register("test-named", func(cfg map[string]string) (model.Provider, error) {
+		return &namedProvider{model: cfg["model"], gate: gate}, nil
+	})
+}
+
+// Run with -race: requests, model switches and config updates from many
+// goroutines at once.
+func TestBrain_ConcurrentProcessAndReconfigure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	registerNamedProvider(nil)
+	b := New()
+	if err := b.SetModel("test-named", "m0"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				resp, err := b.Process(context.Background(), Request{
+					ID:        fmt.Sprintf("req-%d-%d", i, j),
+					Content:   "Hello Brain",
+					SessionID: fmt.Sprintf("session-%d", i%2),
+				})
+				if err != nil {
+					errs <- err
+				} else if !strings.HasPrefix(resp.Content, "answered by m") {
+					errs <- fmt.Errorf("unexpected response %q", resp.Content)
+				}
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if err := b.SetModel("test-named", fmt.Sprintf("m%d", i*5+j)); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				cfg := b.Config().Clone()
+				cfg.Prompt.ContextTokens = 4000 + i*100 + j
+				cfg.SetProvider("ollama", sys.ProviderConfig{Endpoint: fmt.Sprintf("http://localhost:%d", 11434+j)})
+				if err := b.UpdateConfig(cfg); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestBrain_RequestKeepsItsModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gate := &providerGate{entered: make(chan struct{}), release: make(chan struct{})}
+	registerNamedProvider(gate)
+	b := New()
+	if err := b.SetModel("test-named", "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		resp Response
+		err  error
+	}
+	done := make(chan result)
+	go func() {
+		resp, err := b.Process(context.Background(), Request{ID: "slow-1", Content: "Hello Brain"})
+		done <- result{resp, err}
+	}()
+
+	// Switch while the request is waiting on its provider.
+	<-gate.entered
+	if err := b.SetModel("test-named", "second"); err != nil {
+		t.Fatal(err)
+	}
+	close(gate.release)
+	r := <-done
+	if r.err != nil || r.resp.Content != "answered by first" {
+		t.Errorf("expected the request to finish with the model it started with, got %q (%v)", r.resp.Content, r.err)
+	}
+	if got := b.Config().Model.Name; got != "second" {
+		t.Errorf("expected the switch to apply afterwards, got %s", got)
+	}
+}
@@ -0,0 +1,109 @@
+package brain
+
+import (
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/prompt"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// chatSession is a session's threads plus the conversation replayed to the
+// model on each request: the user's messages and the final answers, without
+// the per-request context or tool traffic.
+type chatSession struct {
+	*tooling.Session
+	messages []model.Message
+	restored int // Leading Threads loaded from memory, recorded by earlier runs
+}
+
+// ContextStats describes the conversation a session's next request carries.
+type ContextStats struct {
+	Messages int // Earlier user and assistant messages
+	Tokens   int // Their estimated token count
+	Budget   int // Estimated tokens kept before the oldest turns are dropped
+}
+
+// chatSession returns the session called id, creating it if needed.
+func (b *Brain) chatSession(id string) *chatSession {
+	b.sessionsMu.Lock()
+	defer b.sessionsMu.Unlock()
+	s, ok := b.sessions[id]
+	if !ok {
+		s = &chatSession{Session: tooling.NewSession(id)}
+		b.restoreThreads(s)
+		b.sessions[id] = s
+	}
+	return s
+}
+
+// conversation returns a copy of the session's earlier messages.
+func (b *Brain) conversation(id string) []model.Message {
+	s := b.chatSession(id)
+	b.sessionsMu.Lock()
+	defer b.sessionsMu.Unlock()
+	return append([]model.Message(nil), s.messages...)
+}
+
+// remember appends a finished exchange to the session, dropping the oldest
+// exchanges once the conversation is over budget. The latest is always
+// kept.
+func (b *Brain) remember(id, userText, answer string) {
+	s := b.chatSession(id)
+	b.sessionsMu.Lock()
+	defer b.sessionsMu.Unlock()
+	s.messages = append(s.messages,
+		model.Message{Role: model.RoleUser, Content: userText},
+		model.Message{Role: model.RoleAssistant, Content: answer},
+	)
+	budget := b.conversationBudget()
+	for len(s.messages) > 2 && messageTokens(s.messages) > budget {
+		s.messages = s.messages[2:]
+	}
+}
+
+// ContextStats reports the size of the session's conversation.
+func (b *Brain) ContextStats(sessionID string) ContextStats {
+	msgs := b.conversation(sessionID)
+	return ContextStats{Messages: len(msgs), Tokens: messageTokens(msgs), Budget: b.conversationBudget()}
+}
+
+// ResetConversation forgets the session's earlier messages, so the next
+// request starts a fresh conversation.
+func (b *Brain) ResetConversation(sessionID string) {
+	s := b.chatSession(sessionID)
+	b.sessionsMu.Lock()
+	s.messages = nil
+	b.sessionsMu.Unlock()
+	b.pins.forgetSent()
+}
+
+// conversationBudget is the context window budget from the config.
+func (b *Brain) conversationBudget() int {
+	if cfg := b.cfg(); cfg != nil && cfg.Prompt.ContextTokens > 0 {
+		return cfg.Prompt.ContextTokens
+	}
+	return vcontext.DefaultContextTokens
+}
+
+// ContextUsage reports how much of the model's context window the last
+// prompt built filled.
+func (b *Brain) ContextUsage() prompt.Usage {
+	b.contextUsageMu.Lock()
+	defer b.contextUsageMu.Unlock()
+	return b.contextUsage
+}
+
+// HistoryTokens estimates the session's earlier messages, for the
+// prompt's context usage.
+func (b *Brain) HistoryTokens(sessionID string) int {
+	return messageTokens(b.conversation(sessionID))
+}
+
+func messageTokens(msgs []model.Message) int {
+	var tok vcontext.EstimateTokens
+	n := 0
+	for _, m := range msgs {
+		n += tok.Count(m.Content)
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package brain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestProcess_SharesConversationPerSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	provider := &scriptedProvider{responses: []string{"The answer is 42.", "You asked about the answer."}}
+	b.model = model.New(provider)
+	ctx := context.Background()
+
+	if _, err := b.Process(ctx, Request{ID: "c-1", SessionID: "alpha", Content: "what is the answer"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Process(ctx, Request{ID: "c-2", SessionID: "alpha", Content: "what did I ask"}); err != nil {
+		t.Fatal(err)
+	}
+	second := provider.prompts[1]
+	if !strings.Contains(second, "User: what is the answer") || !strings.Contains(second, "Assistant: The answer is 42.") {
+		t.Errorf("expected the earlier exchange in the next request, got:\n%s", second)
+	}
+	if stats := b.ContextStats("alpha"); stats.Messages != 4 || stats.Tokens == 0 {
+		t.Errorf("expected 4 messages with a token estimate, got %+v", stats)
+	}
+
+	if _, err := b.Process(ctx, Request{ID: "c-3", SessionID: "beta", Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(provider.prompts[2], "Assistant: The answer is 42.") {
+		t.Errorf("expected another session to start fresh, got:\n%s", provider.prompts[2])
+	}
+
+	b.ResetConversation("alpha")
+	if stats := b.ContextStats("alpha"); stats.Messages != 0 {
+		t.Errorf("expected no messages after a reset, got %+v", stats)
+	}
+}
+
+func TestRemember_DropsOldestOverBudget(t *testing.T) {
+	cfg := &sys.Config{}
+	cfg.Prompt.ContextTokens = 10
+	b := &Brain{config: cfg, sessions: make(map[string]*chatSession)}
+	b.remember("s", strings.Repeat("a", 40), "first")
+	b.remember("s", "second", "answer")
+
+	msgs := b.conversation("s")
+	if len(msgs) != 2 || msgs[0].Content != "second" {
+		t.Errorf("expected only the latest exchange to fit, got %+v", msgs)
+	}
+}
+
+func TestProcess_RecordsContextUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	b.model = model.New(&MockProvider{})
+	cfg := b.Config().Clone()
+	cfg.Prompt.Enabled = true
+	cfg.Model.MaxContextTokens = 100000
+	b.setConfig(cfg)
+
+	if u := b.ContextUsage(); u.Tokens != 0 {
+		t.Fatalf("expected no usage before a request, got %+v", u)
+	}
+	if _, err := b.Process(context.Background(), Request{ID: "u-1", Content: "explain the main loop"}); err != nil {
+		t.Fatal(err)
+	}
+	if u := b.ContextUsage(); u.Tokens == 0 || u.Limit != 100000 || u.Percent() < 0 {
+		t.Errorf("expected the last prompt measured against the limit, got %+v", u)
+	}
+}
@@ -0,0 +1,166 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/model"
+)
+
+// vaultProbeKey is the secret Diagnose writes, reads back and deletes.
+const vaultProbeKey = "doctor_probe"
+
+// Diagnose runs the self-diagnosis checks behind `vibeaura doctor check`:
+// the active provider answers, the vault round-trips a secret, the data
+// directory is writable, the memory database is intact and every MCP
+// server is up. Each check reports on its own; none stops the others.
+func (b *Brain) Diagnose(ctx context.Context) []doctor.Check {
+	checks := []doctor.Check{
+		b.checkProvider(ctx),
+		b.checkVault(),
+		b.checkDataDir(),
+		b.checkMemory(),
+	}
+	return append(checks, b.checkMCPServers(ctx)...)
+}
+
+// checkProvider lists the active provider's models, under the discovery
+// timeout.
+func (b *Brain) checkProvider(ctx context.Context) doctor.Check {
+	name := "provider"
+	cfg := b.cfg()
+	pName := cfg.Model.Provider
+	if pName == "" {
+		return doctor.Fail(name, "no provider configured; run vibeaura models use")
+	}
+	name += " " + pName
+	p, err := model.GetProvider(pName, b.providerConfig(pName))
+	if err != nil {
+		return doctor.Fail(name, err.Error())
+	}
+	timeout := defaultDiscoveryTimeout
+	if cfg.Model.DiscoveryTimeout > 0 {
+		timeout = cfg.Model.DiscoveryTimeout
+	}
+	pctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	models, err := p.ListModels(pctx)
+	if err != nil {
+		if pctx.Err() == context.DeadlineExceeded {
+			return doctor.Fail(name, fmt.Sprintf("no answer within %s", timeout))
+		}
+		return doctor.Fail(name, err.Error())
+	}
+	if len(models) == 0 {
+		return doctor.Warn(name, "reachable, but it lists no models")
+	}
+	return doctor.Pass(name, fmt.Sprintf("reachable, %d models", len(models)))
+}
+
+// checkVault stores a throwaway secret, reads it back and deletes it.
+func (b *Brain) checkVault() doctor.Check {
+	const name = "vault"
+	if b.vault == nil {
+		return doctor.Fail(name, "vault not initialized")
+	}
+	want := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := b.vault.Set(vaultProbeKey, want); err != nil {
+		return doctor.Fail(name, "write: "+err.Error())
+	}
+	got, err := b.vault.Get(vaultProbeKey)
+	if err != nil {
+		return doctor.Fail(name, "read: "+err.Error())
+	}
+	if got != want {
+		return doctor.Fail(name, "read back a different value than was written")
+	}
+	if err := b.vault.Delete(vaultProbeKey); err != nil {
+		return doctor.Warn(name, "round-trip works, but the probe could not be deleted: "+err.Error())
+	}
+	return doctor.Pass(name, "write, read and delete work")
+}
+
+// checkDataDir creates and removes a file in the data directory.
+func (b *Brain) checkDataDir() doctor.Check {
+	const name = "data dir"
+	dir := b.dataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return doctor.Fail(name, err.Error())
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return doctor.Fail(name, dir+" is not writable: "+err.Error())
+	}
+	_, werr := f.WriteString("ok")
+	f.Close()
+	rerr := os.Remove(f.Name())
+	switch {
+	case werr != nil:
+		return doctor.Fail(name, dir+" is not writable: "+werr.Error())
+	case rerr != nil:
+		return doctor.Fail(name, "cannot delete files in "+dir+": "+rerr.Error())
+	}
+	if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0002 != 0 {
+		return doctor.Warn(name, dir+" is writable by every user")
+	}
+	return doctor.Pass(name, dir)
+}
+
+// checkMemory runs the memory database's integrity check and reports a
+// memory key the vault would not give up.
+func (b *Brain) checkMemory() doctor.Check {
+	const name = "memory db"
+	if b.memory == nil {
+		return doctor.Fail(name, "database not initialized")
+	}
+	if err := b.memory.CheckIntegrity(); err != nil {
+		return doctor.Fail(name, err.Error())
+	}
+	if b.memoryKeyErr != nil {
+		return doctor.Fail(name, b.memoryKeyErr.Error())
+	}
+	return doctor.Pass(name, "integrity check ok")
+}
+
+// checkMCPServers reports on each configured MCP server, once they have
+// had their chance to start. A server still starting when ctx ends is a
+// warning; one that failed or stopped answering fails.
+func (b *Brain) checkMCPServers(ctx context.Context) []doctor.Check {
+	if b.mcpUp != nil {
+		select {
+		case <-b.mcpUp:
+		case <-ctx.Done():
+		}
+	}
+	servers := b.MCPServers(ctx)
+	if len(servers) == 0 {
+		return []doctor.Check{doctor.Pass("mcp", "no servers configured")}
+	}
+	var checks []doctor.Check
+	for _, s := range servers {
+		name := "mcp " + s.Name
+		b.mcpMu.Lock()
+		_, started := b.mcp[s.Name]
+		_, failed := b.mcpErrs[s.Name]
+		b.mcpMu.Unlock()
+		switch {
+		case !started && !failed:
+			checks = append(checks, doctor.Warn(name, "not started yet"))
+		case s.Err != nil:
+			checks = append(checks, doctor.Fail(name, s.Err.Error()))
+		case !s.Healthy:
+			checks = append(checks, doctor.Fail(name, "not answering health checks"))
+		default:
+			detail := fmt.Sprintf("running, %d tools", len(s.Tools))
+			if s.Restarts > 0 {
+				detail += fmt.Sprintf(", restarted %d times", s.Restarts)
+			}
+			checks = append(checks, doctor.Pass(name, detail))
+		}
+	}
+	return checks
+}
@@ -0,0 +1,58 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+type failingListProvider struct{ MockProvider }
+
+func (*failingListProvider) ListModels(context.Context) ([]string, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDiagnose(t *testing.T) {
+	model.Register("test-diag-up", func(map[string]string) (model.Provider, error) {
+		return &listProvider{models: []string{"m1", "m2"}}, nil
+	})
+	model.Register("test-diag-down", func(map[string]string) (model.Provider, error) {
+		return &failingListProvider{}, nil
+	})
+	dir := t.TempDir()
+	mem, err := vcontext.OpenMemory(filepath.Join(dir, "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &sys.Config{DataDir: dir}
+	cfg.Model.Provider = "test-diag-up"
+	b := &Brain{config: cfg, memory: mem}
+
+	got := map[string]doctor.Check{}
+	for _, c := range b.Diagnose(context.Background()) {
+		got[c.Name] = c
+	}
+	for name, want := range map[string]doctor.CheckStatus{
+		"provider test-diag-up": doctor.CheckPass,
+		"vault":                 doctor.CheckFail, // None in this Brain
+		"data dir":              doctor.CheckPass,
+		"memory db":             doctor.CheckPass,
+		"mcp":                   doctor.CheckPass,
+	} {
+		if got[name].Status != want {
+			t.Errorf("%s: got %+v, want %s", name, got[name], want)
+		}
+	}
+
+	cfg.Model.Provider = "test-diag-down"
+	checks := b.Diagnose(context.Background())
+	if c := checks[0]; c.Status != doctor.CheckFail || c.Detail != "connection refused" {
+		t.Errorf("expected an unreachable provider to fail, got %+v", c)
+	}
+}
@@ -0,0 +1,61 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// modelsCacheFile holds the last successful model discovery in the data dir.
+const modelsCacheFile = "models_cache.json"
+
+type modelsCache struct {
+	UpdatedAt time.Time        `json:"updated_at"`
+	Models    []ModelDiscovery `json:"models"`
+}
+
+func (b *Brain) modelsCachePath() string {
+	return filepath.Join(b.dataDir(), modelsCacheFile)
+}
+
+// CachedModels returns the last successful discovery and when it ran,
+// however old it is. ok is false if there is none.
+func (b *Brain) CachedModels() (models []ModelDiscovery, updated time.Time, ok bool) {
+	return CachedModelsIn(b.dataDir())
+}
+
+// CachedModelsIn is CachedModels for the data directory dataDir. It only
+// reads the cache file, so it is cheap enough for shell completion.
+func CachedModelsIn(dataDir string) (models []ModelDiscovery, updated time.Time, ok bool) {
+	data, err := os.ReadFile(filepath.Join(dataDir, modelsCacheFile))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var c modelsCache
+	if json.Unmarshal(data, &c) != nil || len(c.Models) == 0 {
+		return nil, time.Time{}, false
+	}
+	return c.Models, c.UpdatedAt, true
+}
+
+func (b *Brain) saveModelsCache(models []ModelDiscovery) error {
+	if err := os.MkdirAll(b.dataDir(), 0755); err != nil {
+		return err
+	}
+	return writeJSONAtomic(b.modelsCachePath(), modelsCache{UpdatedAt: time.Now(), Models: models})
+}
+
+// DiscoverModelsCached returns the cached discovery if it is younger than
+// maxAge, and otherwise queries the providers like DiscoverModels. cached
+// reports which happened. A maxAge of zero always queries.
+func (b *Brain) DiscoverModelsCached(ctx context.Context, maxAge time.Duration) (models []ModelDiscovery, cached bool, err error) {
+	if maxAge > 0 {
+		if models, updated, ok := b.CachedModels(); ok && time.Since(updated) < maxAge {
+			return models, true, nil
+		}
+	}
+	models, err = b.DiscoverModels(ctx)
+	return models, false, err
+}
@@ -0,0 +1,96 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// listProvider lists fixed models, or hangs until its context ends.
+type listProvider struct {
+	MockProvider
+	models []string
+	hang   bool
+}
+
+func (p *listProvider) ListModels(ctx context.Context) ([]string, error) {
+	if p.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return p.models, nil
+}
+
+func TestDiscoverModels_ParallelWithTimeout(t *testing.T) {
+	model.Register("test-slow", func(map[string]string) (model.Provider, error) {
+		return &listProvider{hang: true}, nil
+	})
+	model.Register("test-b", func(map[string]string) (model.Provider, error) {
+		return &listProvider{models: []string{"zeta", "alpha"}}, nil
+	})
+	model.Register("test-a", func(map[string]string) (model.Provider, error) {
+		return &listProvider{models: []string{"mid"}}, nil
+	})
+	saved := discoveryProviders
+	discoveryProviders = []string{"test-slow", "test-b", "test-a"}
+	defer func() { discoveryProviders = saved }()
+
+	cfg := &sys.Config{DataDir: t.TempDir()}
+	cfg.Model.DiscoveryTimeout = 50 * time.Millisecond
+	b := &Brain{config: cfg}
+
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		got, err := b.DiscoverModels(context.Background())
+		if err != nil {
+			t.Fatalf("DiscoverModels: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected the slow provider to be cut off by its timeout, took %s", elapsed)
+		}
+		var names []string
+		for _, d := range got {
+			names = append(names, fmt.Sprintf("%s/%s", d.Provider, d.Name))
+		}
+		if strings.Join(names, " ") != "test-a/mid test-b/alpha test-b/zeta" {
+			t.Fatalf("expected results sorted by provider then name, got %v", names)
+		}
+	}
+}
+
+func TestDiscoverModelsCached(t *testing.T) {
+	listed := 0
+	model.Register("test-cached", func(map[string]string) (model.Provider, error) {
+		listed++
+		return &listProvider{models: []string{"m1"}}, nil
+	})
+	saved := discoveryProviders
+	discoveryProviders = []string{"test-cached"}
+	defer func() { discoveryProviders = saved }()
+
+	b := &Brain{config: &sys.Config{DataDir: t.TempDir()}}
+	if _, _, ok := b.CachedModels(); ok {
+		t.Fatal("expected no cache before the first discovery")
+	}
+
+	got, cached, err := b.DiscoverModelsCached(context.Background(), time.Hour)
+	if err != nil || cached || len(got) != 1 {
+		t.Fatalf("first call = %v, cached=%v, %v; want a fresh discovery", got, cached, err)
+	}
+	got, cached, err = b.DiscoverModelsCached(context.Background(), time.Hour)
+	if err != nil || !cached || len(got) != 1 || got[0].Name != "m1" {
+		t.Fatalf("second call = %v, cached=%v, %v; want the cached result", got, cached, err)
+	}
+	if listed != 1 {
+		t.Errorf("providers queried %d times, want 1", listed)
+	}
+
+	if _, cached, _ := b.DiscoverModelsCached(context.Background(), 0); cached || listed != 2 {
+		t.Errorf("maxAge 0 should force a refresh, cached=%v listed=%d", cached, listed)
+	}
+}
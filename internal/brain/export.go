@@ -0,0 +1,208 @@
+package brain
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SessionTranscript is the part of a saved chat session that exports read:
+// the transcript as shown, and when each message first appeared. Times may
+// be shorter than Messages, or hold zero times, for messages saved before
+// timestamps were kept.
+type SessionTranscript struct {
+	Messages []string    `json:"messages"`
+	Times    []time.Time `json:"times,omitempty"`
+}
+
+// ExportedMessage is one transcript message with its styling removed.
+type ExportedMessage struct {
+	Speaker string    // "You", "Brain" or "System"
+	Time    time.Time // Zero when unknown
+	Content string
+}
+
+// ansiEscape matches the CSI and OSC sequences lipgloss writes.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// ExportExtension is the file extension for an export format, and false
+// for a format ExportSession does not know.
+func ExportExtension(format string) (string, bool) {
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		return ".md", true
+	case "json":
+		return ".json", true
+	case "html":
+		return ".html", true
+	}
+	return "", false
+}
+
+// ExportSession renders the saved transcript of session id as "markdown"
+// (or "md"), "json" or "html". The banner is left out. With
+// includeArchived, the batches /clear removed come first, oldest first,
+// each headed by a note of when it was cleared.
+func (b *Brain) ExportSession(id string, format string, includeArchived bool) ([]byte, error) {
+	var msgs []ExportedMessage
+	if includeArchived {
+		archives, err := b.SessionArchives(id)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(archives) - 1; i >= 0; i-- {
+			a := archives[i]
+			msgs = append(msgs, ExportedMessage{Speaker: "System", Time: a.CreatedAt, Content: "Archived: cleared with /clear"})
+			msgs = append(msgs, SessionTranscript{Messages: a.Messages}.Export()...)
+		}
+	}
+
+	var t SessionTranscript
+	if err := b.LoadSession(id, &t); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			return nil, fmt.Errorf("no saved session %q", id)
+		}
+	}
+	msgs = append(msgs, t.Export()...)
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		return []byte(exportMarkdown(id, msgs)), nil
+	case "json":
+		return exportJSON(msgs)
+	case "html":
+		return exportHTML(id, msgs)
+	}
+	return nil, fmt.Errorf("unknown export format %q (use markdown, json or html)", format)
+}
+
+// Export strips the transcript's styling and splits off speaker labels.
+func (t SessionTranscript) Export() []ExportedMessage {
+	var out []ExportedMessage
+	for i, raw := range t.Messages {
+		text := strings.TrimSpace(ansiEscape.ReplaceAllString(raw, ""))
+		if text == "" || isBanner(text) {
+			continue
+		}
+		m := ExportedMessage{Speaker: "System", Content: text}
+		if body, ok := strings.CutPrefix(text, "You: "); ok {
+			m.Speaker, m.Content = "You", body
+		} else if body, ok := strings.CutPrefix(text, "Brain: "); ok {
+			m.Speaker, m.Content = "Brain", body
+		}
+		if i < len(t.Times) {
+			m.Time = t.Times[i]
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// isBanner spots the TUI's start-up banner in either width.
+func isBanner(text string) bool {
+	return strings.Contains(text, "System-Intimate") || strings.Contains(text, "_(_) |__")
+}
+
+func exportMarkdown(id string, msgs []ExportedMessage) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# vibeauracle session %s\n\n", id)
+	fmt.Fprintf(&sb, "_Exported %s · %d messages_\n", time.Now().Format("2006-01-02 15:04"), len(msgs))
+	for _, m := range msgs {
+		sb.WriteString("\n---\n\n")
+		if m.Time.IsZero() {
+			fmt.Fprintf(&sb, "### %s\n\n", m.Speaker)
+		} else {
+			fmt.Fprintf(&sb, "### %s · %s\n\n", m.Speaker, m.Time.Local().Format("2006-01-02 15:04:05"))
+		}
+		sb.WriteString(m.Content + "\n")
+	}
+	return sb.String()
+}
+
+// exportedJSON is one message of a JSON export.
+type exportedJSON struct {
+	Role    string     `json:"role"` // "user", "assistant" or "system"
+	Time    *time.Time `json:"time,omitempty"`
+	Content string     `json:"content"`
+}
+
+var exportRoles = map[string]string{"You": "user", "Brain": "assistant", "System": "system"}
+
+func exportJSON(msgs []ExportedMessage) ([]byte, error) {
+	out := make([]exportedJSON, 0, len(msgs))
+	for _, m := range msgs {
+		e := exportedJSON{Role: exportRoles[m.Speaker], Content: m.Content}
+		if !m.Time.IsZero() {
+			t := m.Time
+			e.Time = &t
+		}
+		out = append(out, e)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// exportTemplate uses the TUI's palette: violet accents, pink for the
+// user and cyan for the brain on a dark background.
+var exportTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"lower": strings.ToLower,
+	"stamp": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>vibeauracle session {{.ID}}</title>
+<style>
+body { background: #1a1a1a; color: #fafafa; font-family: ui-monospace, "JetBrains Mono", Menlo, Consolas, monospace; margin: 0 auto; max-width: 960px; padding: 2rem; }
+h1 { color: #7d56f4; font-size: 1.4rem; margin-bottom: 0.2rem; }
+.meta { color: #626262; margin-bottom: 2rem; }
+.message { border-left: 3px solid #7d56f4; margin: 1.2rem 0; padding: 0.4rem 1rem; }
+.message.you { border-color: #ee6ff8; }
+.message.brain { border-color: #04d9ff; }
+.speaker { font-weight: bold; }
+.you .speaker { color: #ee6ff8; }
+.brain .speaker { color: #04d9ff; }
+.system .speaker { color: #7d56f4; }
+.time { color: #626262; margin-left: 0.6rem; font-size: 0.85rem; }
+.content { white-space: pre-wrap; word-wrap: break-word; margin-top: 0.4rem; line-height: 1.45; }
+</style>
+</head>
+<body>
+<h1>vibeauracle session {{.ID}}</h1>
+<div class="meta">Exported {{.Exported}} · {{len .Messages}} messages</div>
+{{range .Messages}}<div class="message {{lower .Speaker}}">
+<span class="speaker">{{.Speaker}}</span>{{with stamp .Time}}<span class="time">{{.}}</span>{{end}}
+<div class="content">{{.Content}}</div>
+</div>
+{{end}}</body>
+</html>
+`))
+
+func exportHTML(id string, msgs []ExportedMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	err := exportTemplate.Execute(&buf, struct {
+		ID       string
+		Exported string
+		Messages []ExportedMessage
+	}{id, time.Now().Format("2006-01-02 15:04"), msgs})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package brain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportSession(t *testing.T) {
+	b := newSessionBrain(t)
+	b.SetSession("demo")
+	at := time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local)
+	b.StoreSession(SessionTranscript{
+		Messages: []string{
+			"\x1b[1m System-Intimate banner\x1b[0m",
+			"\x1b[1;38;2;238;111;248mYou: \x1b[0mwhat is <b>2+2</b>?",
+			"\x1b[1;38;2;4;217;255mBrain: \x1b[0m**4**",
+			"\x1b[7m CWD \x1b[0m /tmp",
+		},
+		Times: []time.Time{at, at, at.Add(time.Second)},
+	})
+
+	md, err := b.ExportSession("demo", "markdown", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# vibeauracle session demo",
+		"### You · 2026-03-04 10:30:00\n\nwhat is <b>2+2</b>?",
+		"### Brain · 2026-03-04 10:30:01\n\n**4**",
+		"### System\n\nCWD  /tmp",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(string(md), "System-Intimate") || strings.Contains(string(md), "\x1b") {
+		t.Errorf("markdown kept the banner or escapes:\n%s", md)
+	}
+
+	page, err := b.ExportSession("demo", "html", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<div class="message you">`,
+		"what is &lt;b&gt;2&#43;2&lt;/b&gt;?",
+		"#04d9ff",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("html is missing %q:\n%s", want, page)
+		}
+	}
+
+	raw, err := b.ExportSession("demo", "json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []struct {
+		Role    string     `json:"role"`
+		Time    *time.Time `json:"time"`
+		Content string     `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &msgs); err != nil {
+		t.Fatalf("json export does not parse: %v\n%s", err, raw)
+	}
+	if len(msgs) != 3 || msgs[0].Role != "user" || msgs[1].Role != "assistant" || msgs[1].Content != "**4**" {
+		t.Errorf("unexpected json export:\n%s", raw)
+	}
+	if msgs[0].Time == nil || !msgs[0].Time.Equal(at) || msgs[2].Time != nil {
+		t.Errorf("expected times only where known:\n%s", raw)
+	}
+
+	if _, err := b.ExportSession("demo", "pdf", false); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if _, err := b.ExportSession("missing", "md", false); err == nil || !strings.Contains(err.Error(), "no saved session") {
+		t.Errorf("expected a missing-session error, got %v", err)
+	}
+}
+
+func TestExportSession_IncludeArchived(t *testing.T) {
+	b := newSessionBrain(t)
+	b.SetSession("demo")
+	b.StoreSession(SessionTranscript{Messages: []string{"You: first question"}})
+	if err := b.ArchiveState(b.SessionKey(), []string{"You: first question"}, SessionTranscript{Messages: []string{"You: second question"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := b.ExportSession("demo", "md", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(md), "first question") {
+		t.Errorf("expected cleared messages left out by default:\n%s", md)
+	}
+
+	md, err = b.ExportSession("demo", "md", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := strings.Index(string(md), "Archived: cleared with /clear")
+	first, second := strings.Index(string(md), "first question"), strings.Index(string(md), "second question")
+	if archived < 0 || first < archived || second < first {
+		t.Errorf("expected the archived batch, then the live transcript:\n%s", md)
+	}
+}
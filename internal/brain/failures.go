@@ -0,0 +1,312 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// failureBlockThreshold is how many times an identical call may fail before
+// further attempts are refused without running.
+const failureBlockThreshold = 2
+
+// failureBlockTurns is how many requests a block lasts after the last
+// failure. A blocked call never runs, so it cannot clear itself by
+// succeeding; once the user has had a chance to fix the cause it gets
+// another try.
+const failureBlockTurns = 3
+
+// FailureEntry is one failing call in the ledger.
+type FailureEntry struct {
+	Key   string    `json:"key"`
+	Tool  string    `json:"tool"`
+	Class string    `json:"class"`
+	Error string    `json:"error"`
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+
+	turn int // Ledger turn of the last failure
+}
+
+// RepeatedFailureError is returned instead of running a call that has
+// already failed failureBlockThreshold times. Its message is written as an
+// observation for the model.
+type RepeatedFailureError struct {
+	Entry FailureEntry
+}
+
+func (e *RepeatedFailureError) Error() string {
+	return fmt.Sprintf("not executed: this exact %s call already failed %d times (%s: %s). "+
+		"Repeating it will fail again. You must try a different approach: change the arguments, use another tool, or ask the user.",
+		e.Entry.Tool, e.Entry.Count, e.Entry.Class, e.Entry.Error)
+}
+
+// failureLedger tracks failing tool calls and verification failures within
+// one session. A success on the same key decays its count, and a block
+// lapses failureBlockTurns requests after the last failure.
+type failureLedger struct {
+	mu      sync.Mutex
+	entries map[string]*FailureEntry
+	turn    int // Requests seen
+}
+
+func newFailureLedger() *failureLedger {
+	return &failureLedger{entries: make(map[string]*FailureEntry)}
+}
+
+func (l *failureLedger) record(key, tool string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &FailureEntry{Key: key, Tool: tool}
+		l.entries[key] = e
+	}
+	e.Count++
+	e.Class = classifyFailure(err)
+	e.Error = truncate(err.Error(), 160)
+	e.Last = time.Now()
+	e.turn = l.turn
+}
+
+// nextTurn starts a new request.
+func (l *failureLedger) nextTurn() {
+	l.mu.Lock()
+	l.turn++
+	l.mu.Unlock()
+}
+
+// clear forgets every failure.
+func (l *failureLedger) clear() {
+	l.mu.Lock()
+	l.entries = make(map[string]*FailureEntry)
+	l.mu.Unlock()
+}
+
+func (l *failureLedger) succeeded(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok {
+		e.Count--
+		if e.Count <= 0 {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// blocked reports whether key has failed often enough to refuse it.
+func (l *failureLedger) blocked(key string) (FailureEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok && e.Count >= failureBlockThreshold && l.turn-e.turn < failureBlockTurns {
+		return *e, true
+	}
+	return FailureEntry{}, false
+}
+
+// list returns the entries, most recent first.
+func (l *failureLedger) list() []FailureEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]FailureEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Last.After(out[j].Last) })
+	return out
+}
+
+// failureKey identifies a call by its tool and arguments. Key order and
+// the JSON layout do not matter; argument values are compared exactly, so
+// a write corrected only in whitespace is a different call.
+func failureKey(tool string, args json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(args, &v); err != nil {
+		return tool + " " + string(args)
+	}
+	norm, _ := json.Marshal(v)
+	return tool + " " + string(norm)
+}
+
+// classifyFailure buckets an error so the model sees the kind of failure.
+func classifyFailure(err error) string {
+	var exitErr *exec.ExitError
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, fs.ErrNotExist), strings.Contains(msg, "no such file"), strings.Contains(msg, "not found"):
+		return "not_found"
+	case errors.Is(err, fs.ErrPermission), strings.Contains(msg, "permission denied"):
+		return "permission"
+	case errors.Is(err, context.DeadlineExceeded), strings.Contains(msg, "timeout"), strings.Contains(msg, "timed out"):
+		return "timeout"
+	case errors.As(err, &exitErr), strings.Contains(msg, "exit status"):
+		return "exit_status"
+	case strings.Contains(msg, "verification"):
+		return "verification"
+	case strings.Contains(msg, "invalid") || strings.Contains(msg, "unmarshal"):
+		return "invalid_args"
+	}
+	return "error"
+}
+
+func (b *Brain) ledger(sessionID string) *failureLedger {
+	b.failuresMu.Lock()
+	defer b.failuresMu.Unlock()
+	if b.failures == nil {
+		b.failures = make(map[string]*failureLedger)
+	}
+	l, ok := b.failures[sessionID]
+	if !ok {
+		l = newFailureLedger()
+		b.failures[sessionID] = l
+	}
+	return l
+}
+
+// Failures returns the active session's failure ledger, most recent first.
+func (b *Brain) Failures() []FailureEntry {
+	return b.ledger(b.Session()).list()
+}
+
+// ClearFailures empties the active session's failure ledger, so blocked
+// calls may run again.
+func (b *Brain) ClearFailures() {
+	b.ledger(b.Session()).clear()
+}
+
+// settle records a call that ran without error. A write whose files fail
+// the post-write check goes to the ledger under the call's own key, so
+// writing the same broken content again is caught like any failing call,
+// and the failure is appended to the observation.
+func (b *Brain) settle(l *failureLedger, key string, call toolCall, content string) string {
+	err := b.verifyWrites(call)
+	if err == nil {
+		l.succeeded(key)
+		return content
+	}
+	err = fmt.Errorf("verification failed: %w", err)
+	l.record(key, call.Tool, err)
+	tooling.ReportStatus("⚠️", "tool", fmt.Sprintf("%s wrote a file that does not parse", call.Tool))
+	return content + "\n" + err.Error() + "\nThe write went through but left the file broken; fix it before moving on."
+}
+
+// verifyWrites reads back the files a write tool was given and checks that
+// each still parses, for the formats with a cheap parser.
+func (b *Brain) verifyWrites(call toolCall) error {
+	if b.fs == nil || b.tools == nil {
+		return nil
+	}
+	t, ok := b.tools.Get(call.Tool)
+	if !ok || !slices.Contains(t.Metadata().Permissions, tooling.PermWrite) {
+		return nil
+	}
+	var errs []error
+	for _, path := range writtenPaths(call.Args) {
+		data, err := b.fs.ReadFile(path)
+		if err != nil {
+			continue // Deleted, or not ours to read: nothing to check
+		}
+		if err := checkSyntax(path, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writtenPaths lists the "path" and "files[].path" arguments of a write.
+func writtenPaths(args json.RawMessage) []string {
+	var input struct {
+		Path  string `json:"path"`
+		Files []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	if json.Unmarshal(args, &input) != nil {
+		return nil
+	}
+	var paths []string
+	if input.Path != "" {
+		paths = append(paths, input.Path)
+	}
+	for _, f := range input.Files {
+		if f.Path != "" {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// checkSyntax parses Go and JSON files; other files always pass.
+func checkSyntax(path string, data []byte) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		_, err := parser.ParseFile(token.NewFileSet(), filepath.Base(path), data, parser.SkipObjectResolution)
+		return err
+	case ".json":
+		var v interface{}
+		return json.Unmarshal(data, &v)
+	}
+	return nil
+}
+
+// FailureContext renders ledger entries relevant to userText for the
+// prompt. An entry is relevant when the text names its tool or shares a
+// word with its arguments.
+func (b *Brain) FailureContext(userText string) string {
+	entries := b.ledger(b.Session()).list()
+	if len(entries) == 0 {
+		return ""
+	}
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(userText), isKeySeparator) {
+		if len(w) >= 3 {
+			words[w] = true
+		}
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, e := range entries {
+		if n == 5 {
+			break
+		}
+		relevant := words[strings.ToLower(e.Tool)]
+		for _, w := range strings.FieldsFunc(strings.ToLower(strings.TrimPrefix(e.Key, e.Tool)), isKeySeparator) {
+			if len(w) >= 3 && words[w] {
+				relevant = true
+				break
+			}
+		}
+		if !relevant {
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s ×%d [%s]: %s\n", truncate(e.Key, 120), e.Count, e.Class, e.Error)
+		n++
+	}
+	return sb.String()
+}
+
+func isKeySeparator(r rune) bool {
+	return !(r == '.' || r == '/' || r == '_' || r == '-' ||
+		(r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
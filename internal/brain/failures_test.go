@@ -0,0 +1,225 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// loopingProvider keeps asking for the same shell command.
+type loopingProvider struct {
+	prompts []string
+}
+
+func (p *loopingProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return "Running the build.\n```json\n{\"tool\": \"fake_shell\", \"parameters\": {\"command\": \"make   build\"}}\n```", nil
+}
+
+func (p *loopingProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, p, prompt, out)
+}
+
+func (p *loopingProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+func (p *loopingProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	return model.NoNativeTools(ctx, messages, tools)
+}
+func (p *loopingProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *loopingProvider) Name() string                                     { return "looping" }
+
+// failingShell always fails like a command exiting non-zero.
+type failingShell struct {
+	calls int
+}
+
+func (s *failingShell) Metadata() tooling.ToolMetadata {
+	return tooling.ToolMetadata{Name: "fake_shell", Category: tooling.CategorySystem}
+}
+
+func (s *failingShell) Execute(ctx context.Context, args json.RawMessage) (*tooling.ToolResult, error) {
+	s.calls++
+	return nil, errors.New("exit status 2: make: *** No rule to make target 'build'")
+}
+
+func TestProcess_BlocksThirdIdenticalFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	provider := &loopingProvider{}
+	shell := &failingShell{}
+	b.model = model.New(provider)
+	b.tools.Register(shell)
+
+	if _, err := b.Process(context.Background(), Request{ID: "fail-1", Content: "build the project"}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if shell.calls != failureBlockThreshold {
+		t.Fatalf("expected the shell to run %d times, ran %d", failureBlockThreshold, shell.calls)
+	}
+	if len(provider.prompts) < 4 {
+		t.Fatalf("expected at least 4 turns, got %d", len(provider.prompts))
+	}
+	// The prompt after the third attempt carries the corrective observation.
+	if !strings.Contains(provider.prompts[3], "You must try a different approach") {
+		t.Errorf("expected corrective observation after the blocked call, got:\n%s", provider.prompts[3])
+	}
+
+	failures := b.Failures()
+	if len(failures) != 1 || failures[0].Class != "exit_status" || failures[0].Count != failureBlockThreshold {
+		t.Fatalf("unexpected ledger: %+v", failures)
+	}
+
+	// A later request about the same command sees the ledger in its prompt.
+	if ctx := b.FailureContext("try make build again"); !strings.Contains(ctx, "fake_shell") {
+		t.Errorf("expected the failing call in FailureContext, got %q", ctx)
+	}
+	if ctx := b.FailureContext("what is the weather"); ctx != "" {
+		t.Errorf("expected no failures for unrelated text, got %q", ctx)
+	}
+}
+
+func TestProcess_BlockedCallRunsAgain(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	shell := &failingShell{}
+	b.model = model.New(&loopingProvider{})
+	b.tools.Register(shell)
+	process := func(id string) {
+		t.Helper()
+		if _, err := b.Process(context.Background(), Request{ID: id, Content: "build the project"}); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	process("lapse-1")
+	for i := 1; i < failureBlockTurns; i++ {
+		process(fmt.Sprintf("lapse-%d", i+1))
+	}
+	if shell.calls != failureBlockThreshold {
+		t.Fatalf("expected the block to hold for %d requests, shell ran %d times", failureBlockTurns, shell.calls)
+	}
+	process("lapse-last")
+	if shell.calls != failureBlockThreshold+1 {
+		t.Fatalf("expected one more try once the block lapsed, shell ran %d times", shell.calls)
+	}
+
+	// The retry failed, so it is blocked again until the user clears it.
+	process("cleared-1")
+	if shell.calls != failureBlockThreshold+1 {
+		t.Fatalf("expected the failed retry to be blocked, shell ran %d times", shell.calls)
+	}
+	b.ClearFailures()
+	process("cleared-2")
+	if shell.calls <= failureBlockThreshold+1 {
+		t.Errorf("expected the call to run after ClearFailures, shell ran %d times", shell.calls)
+	}
+}
+
+// brokenWriter keeps writing the same Go file with a syntax error.
+type brokenWriter struct {
+	loopingProvider
+}
+
+func (p *brokenWriter) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return "Writing main.go.\n```json\n{\"tool\": \"fake_write\", \"parameters\": {\"path\": \"main.go\", \"content\": \"package main\\nfunc main() {\\n\"}}\n```", nil
+}
+
+func (p *brokenWriter) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, p, prompt, out)
+}
+
+func (p *brokenWriter) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+
+// fakeWrite writes through the FS like sys_write_file.
+type fakeWrite struct {
+	fs    sys.FS
+	calls int
+}
+
+func (w *fakeWrite) Metadata() tooling.ToolMetadata {
+	return tooling.ToolMetadata{Name: "fake_write", Category: tooling.CategoryFileSystem, Permissions: []tooling.Permission{tooling.PermWrite}}
+}
+
+func (w *fakeWrite) Execute(ctx context.Context, args json.RawMessage) (*tooling.ToolResult, error) {
+	w.calls++
+	var input struct{ Path, Content string }
+	json.Unmarshal(args, &input)
+	if err := w.fs.WriteFile(input.Path, []byte(input.Content)); err != nil {
+		return nil, err
+	}
+	return &tooling.ToolResult{Status: "success", Content: "wrote " + input.Path}, nil
+}
+
+func TestProcess_RecordsVerificationFailures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	b.fs = sys.NewLocalFS(t.TempDir())
+	provider := &brokenWriter{}
+	write := &fakeWrite{fs: b.fs}
+	b.model = model.New(provider)
+	b.tools.Register(write)
+
+	if _, err := b.Process(context.Background(), Request{ID: "verify-1", Content: "write main.go"}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if write.calls != failureBlockThreshold {
+		t.Fatalf("expected the broken write to run %d times, ran %d", failureBlockThreshold, write.calls)
+	}
+	if !strings.Contains(provider.prompts[1], "verification failed: main.go") {
+		t.Errorf("expected the parse error in the next prompt, got:\n%s", provider.prompts[1])
+	}
+	failures := b.Failures()
+	if len(failures) != 1 || failures[0].Class != "verification" || failures[0].Tool != "fake_write" {
+		t.Fatalf("unexpected ledger: %+v", failures)
+	}
+
+	// A good write of the same file checks out and leaves no new entry.
+	good := toolCall{Tool: "fake_write", Args: json.RawMessage(`{"path": "main.go", "content": "package main\n"}`)}
+	if _, _, err := b.executeToolCall(context.Background(), b.Session(), good); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Failures(); len(got) != 1 {
+		t.Errorf("expected a valid write not to be recorded, got %+v", got)
+	}
+}
+
+func TestFailureLedger_DecaysOnSuccess(t *testing.T) {
+	l := newFailureLedger()
+	key := failureKey("fake_shell", json.RawMessage(`{"command": "go test", "dir": "."}`))
+	if key != failureKey("fake_shell", json.RawMessage(`{"dir":".","command":"go test"}`)) {
+		t.Fatalf("expected key order not to matter")
+	}
+	if key == failureKey("fake_shell", json.RawMessage(`{"command": "go  test", "dir": "."}`)) {
+		t.Fatalf("expected whitespace inside a value to make a different call")
+	}
+	if failureKey("fake_write", json.RawMessage(`{"content": "a := 1 b := 2"}`)) == failureKey("fake_write", json.RawMessage(`{"content": "a := 1\nb := 2"}`)) {
+		t.Fatalf("expected a corrected write to make a different call")
+	}
+
+	l.record(key, "fake_shell", errors.New("exit status 1"))
+	l.record(key, "fake_shell", errors.New("exit status 1"))
+	if _, blocked := l.blocked(key); !blocked {
+		t.Fatalf("expected key to be blocked after two failures")
+	}
+	l.succeeded(key)
+	if _, blocked := l.blocked(key); blocked {
+		t.Fatalf("expected a success to lift the block")
+	}
+	l.succeeded(key)
+	if len(l.list()) != 0 {
+		t.Fatalf("expected the entry to decay away, got %+v", l.list())
+	}
+}
@@ -2,6 +2,12 @@ module github.com/nathfavour/vibeauracle/brain
 
 go 1.21
 
-require github.com/nathfavour/vibeauracle/prompt v0.0.0
+require (
+	github.com/nathfavour/vibeauracle/prompt v0.0.0
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.38.0
+	go.opentelemetry.io/otel/sdk v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
 
 replace github.com/nathfavour/vibeauracle/prompt => ../prompt
@@ -0,0 +1,109 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrNoIntervention is returned when there is no parked loop to resume.
+	ErrNoIntervention = errors.New("no action is waiting for approval")
+	// ErrInterventionDenied is returned by ResumeIntervention for a denial.
+	ErrInterventionDenied = errors.New("action denied")
+)
+
+// suspendedLoop is an agent loop parked on an InterventionError.
+type suspendedLoop struct {
+	state        *loopState
+	intervention *tooling.InterventionError
+}
+
+func (b *Brain) suspend(st *loopState, err error) {
+	var ie *tooling.InterventionError
+	if !errors.As(err, &ie) {
+		return
+	}
+	b.suspendedMu.Lock()
+	defer b.suspendedMu.Unlock()
+	b.suspended = &suspendedLoop{state: st, intervention: ie}
+}
+
+func (b *Brain) takeSuspended() *suspendedLoop {
+	b.suspendedMu.Lock()
+	defer b.suspendedMu.Unlock()
+	s := b.suspended
+	b.suspended = nil
+	return s
+}
+
+// ResumeIntervention answers the pending intervention with an approving
+// choice, runs the approved tool and continues the agent loop with its
+// result as the next observation. Calls the model made in the same turn
+// after the approved one run next. Chunks carry the original request ID.
+// ChoiceDeny is handed to DenyIntervention and returns ErrInterventionDenied.
+func (b *Brain) ResumeIntervention(ctx context.Context, choice string, onChunk func(StreamChunk)) (Response, error) {
+	ctx, span := tracer.Start(ctx, "brain.resume", trace.WithAttributes(attribute.String("approval.choice", choice)))
+	resp, err := b.resumeIntervention(ctx, choice, onChunk)
+	endRequestSpan(span, resp, err)
+	return resp, err
+}
+
+func (b *Brain) resumeIntervention(ctx context.Context, choice string, onChunk func(StreamChunk)) (Response, error) {
+	if choice == tooling.ChoiceDeny {
+		if err := b.DenyIntervention(); err != nil {
+			return Response{}, err
+		}
+		return Response{}, ErrInterventionDenied
+	}
+	s := b.takeSuspended()
+	if s == nil {
+		return Response{}, ErrNoIntervention
+	}
+
+	st := s.state
+	tooling.ReportStatus("▶️", "intervention", fmt.Sprintf("%s: resuming %s", choice, st.call.Tool))
+	res, err := s.intervention.Resume(choice)
+	if err == nil && res != nil && res.Error != nil {
+		err = res.Error
+	}
+
+	ledger := b.ledger(st.sessionID)
+	key := failureKey(st.call.Tool, st.call.Args)
+	var content string
+	if err != nil {
+		ledger.record(key, st.call.Tool, err)
+	} else {
+		if res != nil {
+			content = res.ModelText()
+		}
+		content = b.settle(ledger, key, st.call, content)
+	}
+
+	b.observe(st, st.call, content, err)
+	if err := b.runCalls(ctx, st, st.pending); err != nil {
+		if ctx.Err() != nil {
+			return b.stopLoop(ctx, st)
+		}
+		b.suspend(st, err)
+		return Response{}, err
+	}
+	st.turn++
+	return b.runLoop(ctx, st, onChunk)
+}
+
+// DenyIntervention refuses the pending action and drops its loop. The
+// refusal is still passed to the interceptor so it is audited.
+func (b *Brain) DenyIntervention() error {
+	s := b.takeSuspended()
+	if s == nil {
+		return ErrNoIntervention
+	}
+	s.intervention.Resume(tooling.ChoiceDeny)
+	tooling.ReportStatus("🚫", "intervention", fmt.Sprintf("Denied %s", s.state.call.Tool))
+	return nil
+}
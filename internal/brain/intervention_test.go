@@ -0,0 +1,157 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// scriptedProvider replies with its responses in order, repeating the last.
+type scriptedProvider struct {
+	responses []string
+	prompts   []string
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	i := len(p.prompts) - 1
+	if i >= len(p.responses) {
+		i = len(p.responses) - 1
+	}
+	return p.responses[i], nil
+}
+
+func (p *scriptedProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return model.GenerateAsStream(ctx, p, prompt, out)
+}
+
+func (p *scriptedProvider) GenerateChat(ctx context.Context, messages []model.Message) (string, error) {
+	return model.GenerateChatAsPrompt(ctx, p, messages)
+}
+func (p *scriptedProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	return model.NoNativeTools(ctx, messages, tools)
+}
+func (p *scriptedProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *scriptedProvider) Name() string                                     { return "scripted" }
+
+// gatedTool asks for approval like the Enclave does.
+type gatedTool struct {
+	ran     int
+	choices []string
+}
+
+func (g *gatedTool) Metadata() tooling.ToolMetadata {
+	return tooling.ToolMetadata{Name: "gated_write", Category: tooling.CategoryFileSystem}
+}
+
+func (g *gatedTool) Execute(ctx context.Context, args json.RawMessage) (*tooling.ToolResult, error) {
+	return nil, &tooling.InterventionError{
+		Title:   "Allow action? gated_write",
+		Choices: []string{tooling.ChoiceApproveOnce, tooling.ChoiceDeny},
+		Resume: func(choice string) (*tooling.ToolResult, error) {
+			g.choices = append(g.choices, choice)
+			if choice == tooling.ChoiceDeny {
+				return nil, errors.New("security: user denied gated_write")
+			}
+			g.ran++
+			return &tooling.ToolResult{Status: "success", Content: "wrote notes.md"}, nil
+		},
+	}
+}
+
+func newGatedBrain(t *testing.T) (*Brain, *scriptedProvider, *gatedTool) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	provider := &scriptedProvider{responses: []string{
+		"Writing it.\n```json\n{\"tool\": \"gated_write\", \"parameters\": {\"path\": \"notes.md\"}}\n```",
+		"Done, notes.md is written.",
+	}}
+	tool := &gatedTool{}
+	b.model = model.New(provider)
+	b.tools.Register(tool)
+	return b, provider, tool
+}
+
+func TestResumeIntervention_ContinuesLoop(t *testing.T) {
+	b, provider, tool := newGatedBrain(t)
+
+	_, err := b.Process(context.Background(), Request{ID: "gate-1", Content: "write notes"})
+	var ie *tooling.InterventionError
+	if !errors.As(err, &ie) {
+		t.Fatalf("expected an InterventionError, got %v", err)
+	}
+
+	var chunks []StreamChunk
+	resp, err := b.ResumeIntervention(context.Background(), tooling.ChoiceApproveOnce, func(c StreamChunk) {
+		chunks = append(chunks, c)
+	})
+	if err != nil {
+		t.Fatalf("ResumeIntervention failed: %v", err)
+	}
+	if tool.ran != 1 || resp.Content != "Done, notes.md is written." {
+		t.Fatalf("expected the tool to run and the loop to finish, ran %d, got %q", tool.ran, resp.Content)
+	}
+	if !strings.Contains(provider.prompts[1], "Tool Output: wrote notes.md") {
+		t.Errorf("expected the approved result as the next observation, got:\n%s", provider.prompts[1])
+	}
+	if len(chunks) == 0 || chunks[0].RequestID != "gate-1" || chunks[0].Turn != 1 {
+		t.Errorf("expected resumed chunks under the original request and next turn, got %+v", chunks)
+	}
+
+	if _, err := b.ResumeIntervention(context.Background(), tooling.ChoiceApproveOnce, nil); !errors.Is(err, ErrNoIntervention) {
+		t.Errorf("expected ErrNoIntervention once resumed, got %v", err)
+	}
+}
+
+func TestDenyIntervention_StopsLoop(t *testing.T) {
+	b, provider, tool := newGatedBrain(t)
+
+	if _, err := b.Process(context.Background(), Request{ID: "gate-2", Content: "write notes"}); err == nil {
+		t.Fatalf("expected an intervention")
+	}
+	if _, err := b.ResumeIntervention(context.Background(), tooling.ChoiceDeny, nil); !errors.Is(err, ErrInterventionDenied) {
+		t.Fatalf("expected ErrInterventionDenied, got %v", err)
+	}
+	if tool.ran != 0 || len(tool.choices) != 1 || tool.choices[0] != tooling.ChoiceDeny {
+		t.Errorf("expected the denial to reach the interceptor without running, got ran=%d choices=%v", tool.ran, tool.choices)
+	}
+	if len(provider.prompts) != 1 {
+		t.Errorf("expected no further turns after a denial, got %d prompts", len(provider.prompts))
+	}
+}
+
+func TestResumeIntervention_RecordsToolCalls(t *testing.T) {
+	b, _, _ := newGatedBrain(t)
+
+	if _, err := b.Process(context.Background(), Request{ID: "gate-3", Content: "write notes"}); err == nil {
+		t.Fatalf("expected an intervention")
+	}
+	resp, err := b.ResumeIntervention(context.Background(), tooling.ChoiceApproveOnce, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Tool != "gated_write" || resp.ToolCalls[0].Error != "" {
+		t.Errorf("expected the approved call to be recorded, got %+v", resp.ToolCalls)
+	}
+}
+
+func TestProcess_NoToolsIgnoresCalls(t *testing.T) {
+	b, provider, tool := newGatedBrain(t)
+
+	resp, err := b.Process(context.Background(), Request{ID: "plain-1", Content: "write notes", NoTools: true})
+	if err != nil {
+		t.Fatalf("expected a plain answer, got %v", err)
+	}
+	if !strings.HasPrefix(resp.Content, "Writing it.") || len(resp.ToolCalls) != 0 || len(tool.choices) != 0 {
+		t.Errorf("expected the reply as text with no tool run, got %+v", resp)
+	}
+	if strings.Contains(provider.prompts[0], "sys_read_file") {
+		t.Errorf("expected no tool definitions in the prompt")
+	}
+}
@@ -0,0 +1,207 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// mcpTimeout bounds starting a server and listing its tools.
+const mcpTimeout = 15 * time.Second
+
+// mcpServersDir holds one JSON file per server added with AddMCPServer,
+// each a tooling.MCPConfig.
+const mcpServersDir = "mcp_servers"
+
+// mcpNamePattern is what a server name may look like; it names the file.
+var mcpNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateMCPServerName reports whether name can name an MCP server.
+func ValidateMCPServerName(name string) error {
+	if !mcpNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid MCP server name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// MCPServerStatus is a configured MCP server and what it currently offers.
+type MCPServerStatus struct {
+	Name     string
+	Command  string
+	Args     []string
+	Tools    []string
+	Healthy  bool // Running and answering health checks
+	Restarts int  // Times the health checks restarted it
+	Err      error
+}
+
+func mcpConfig(srv sys.MCPServer) tooling.MCPConfig {
+	return tooling.MCPConfig{Name: srv.Name, Command: srv.Command, Args: srv.Args, Env: srv.Env, MaxRestarts: srv.MaxRestarts}
+}
+
+// startMCPServers registers every configured server with the tool registry.
+// A server that fails to start is remembered and reported by MCPServers;
+// it never blocks the others.
+func (b *Brain) startMCPServers() {
+	defer close(b.mcpUp)
+	for _, srv := range b.mcpServerConfigs() {
+		if _, err := b.registerMCPServer(srv); err != nil {
+			b.mcpMu.Lock()
+			b.mcpErrs[srv.Name] = err
+			b.mcpMu.Unlock()
+		}
+	}
+}
+
+// mcpServerConfigs lists the servers in the config, then those saved in
+// mcp_servers/. A saved server named like one in the config is ignored.
+func (b *Brain) mcpServerConfigs() []sys.MCPServer {
+	servers := append([]sys.MCPServer(nil), b.cfg().MCP.Servers...)
+	b.mcpMu.Lock()
+	defer b.mcpMu.Unlock()
+	for _, srv := range b.mcpFiles {
+		if !hasMCPServer(servers, srv.Name) {
+			servers = append(servers, srv)
+		}
+	}
+	return servers
+}
+
+func hasMCPServer(servers []sys.MCPServer, name string) bool {
+	for _, srv := range servers {
+		if srv.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadMCPServerFiles reads the servers saved in dataDir/mcp_servers,
+// sorted by name. Files that do not parse are reported and skipped.
+func loadMCPServerFiles(dataDir string) []sys.MCPServer {
+	paths, _ := filepath.Glob(filepath.Join(dataDir, mcpServersDir, "*.json"))
+	var servers []sys.MCPServer
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		var cfg tooling.MCPConfig
+		if err == nil {
+			err = json.Unmarshal(data, &cfg)
+		}
+		if err == nil && (cfg.Name == "" || cfg.Command == "") {
+			err = fmt.Errorf("needs a name and a command")
+		}
+		if err != nil {
+			tooling.ReportStatus("⚠️", "mcp", fmt.Sprintf("Skipping %s: %v", filepath.Base(path), err))
+			continue
+		}
+		servers = append(servers, sys.MCPServer{Name: cfg.Name, Command: cfg.Command, Args: cfg.Args, Env: cfg.Env, MaxRestarts: cfg.MaxRestarts})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
+}
+
+// MCPServerFile is where AddMCPServer saves the server called name.
+func (b *Brain) MCPServerFile(name string) string {
+	return filepath.Join(b.dataDir(), mcpServersDir, name+".json")
+}
+
+// saveMCPServerFile writes srv to dataDir/mcp_servers/<name>.json. The
+// file may hold secrets in Env, so only the user can read it.
+func saveMCPServerFile(dataDir string, srv sys.MCPServer) error {
+	dir := filepath.Join(dataDir, mcpServersDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(mcpConfig(srv), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, srv.Name+".json"), append(data, '\n'), 0600)
+}
+
+func (b *Brain) registerMCPServer(srv sys.MCPServer) (int, error) {
+	p := tooling.NewMCPProvider(mcpConfig(srv))
+	ctx, cancel := context.WithTimeout(context.Background(), mcpTimeout)
+	defer cancel()
+
+	loaded, skipped, err := b.tools.SyncProvider(ctx, p)
+	if err != nil {
+		p.Close()
+		return 0, err
+	}
+	for _, name := range skipped {
+		tooling.ReportStatus("⚠️", "mcp", fmt.Sprintf("%s: tool %s shadows an existing tool and was skipped", srv.Name, name))
+	}
+
+	b.mcpMu.Lock()
+	b.mcp[srv.Name] = p
+	delete(b.mcpErrs, srv.Name)
+	b.mcpMu.Unlock()
+	return loaded, nil
+}
+
+// AddMCPServer starts srv, registers its tools with the agent and, once it
+// has answered, saves it to mcp_servers/<name>.json in the data dir so it
+// is started on every launch. It returns the number of tools registered.
+func (b *Brain) AddMCPServer(srv sys.MCPServer) (int, error) {
+	if srv.Name == "" || strings.TrimSpace(srv.Command) == "" {
+		return 0, fmt.Errorf("an MCP server needs a name and a command")
+	}
+	if err := ValidateMCPServerName(srv.Name); err != nil {
+		return 0, err
+	}
+	if hasMCPServer(b.mcpServerConfigs(), srv.Name) {
+		return 0, fmt.Errorf("MCP server %q already exists", srv.Name)
+	}
+
+	n, err := b.registerMCPServer(srv)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mcpMu.Lock()
+	b.mcpFiles = append(b.mcpFiles, srv)
+	b.mcpMu.Unlock()
+	if err := saveMCPServerFile(b.dataDir(), srv); err != nil {
+		return n, fmt.Errorf("saving %s: %w", srv.Name, err)
+	}
+	return n, nil
+}
+
+// MCPServers lists the configured servers with the tools each one reports
+// right now.
+func (b *Brain) MCPServers(ctx context.Context) []MCPServerStatus {
+	var out []MCPServerStatus
+	for _, srv := range b.mcpServerConfigs() {
+		status := MCPServerStatus{Name: srv.Name, Command: srv.Command, Args: srv.Args}
+
+		b.mcpMu.Lock()
+		p, ok := b.mcp[srv.Name]
+		status.Err = b.mcpErrs[srv.Name]
+		b.mcpMu.Unlock()
+
+		if ok {
+			listCtx, cancel := context.WithTimeout(ctx, mcpTimeout)
+			tools, err := p.ListTools(listCtx)
+			cancel()
+			status.Err = err
+			for _, t := range tools {
+				status.Tools = append(status.Tools, t.Name)
+			}
+			status.Healthy, status.Restarts = p.IsHealthy(), p.Restarts()
+		} else if status.Err == nil {
+			status.Err = fmt.Errorf("not started yet")
+		}
+		out = append(out, status)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package brain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// TestMCPHelperServer is not a real test: when MCP_HELPER_SERVER is set the
+// test binary acts as a minimal stdio MCP server with one "echo" tool.
+func TestMCPHelperServer(t *testing.T) {
+	if os.Getenv("MCP_HELPER_SERVER") != "1" {
+		return
+	}
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(in.Bytes(), &req)
+		if req.ID == nil {
+			continue
+		}
+		// A notification before each response, which clients must skip.
+		out.Encode(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/message"})
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			result = map[string]interface{}{"protocolVersion": "2024-11-05", "capabilities": map[string]interface{}{}}
+		case "tools/list":
+			result = map[string]interface{}{"tools": []map[string]interface{}{
+				{"name": "echo", "description": "Echo text", "inputSchema": map[string]interface{}{"type": "object"}},
+			}}
+		case "tools/call":
+			var p struct {
+				Arguments map[string]interface{} `json:"arguments"`
+			}
+			json.Unmarshal(req.Params, &p)
+			result = map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": fmt.Sprint(p.Arguments["text"])}}}
+		}
+		out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+	}
+	os.Exit(0)
+}
+
+func TestAddMCPServer_RegistersTools(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MCP_HELPER_SERVER", "1")
+
+	cm, err := sys.NewConfigManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := cm.Load()
+	b := &Brain{
+		cm:      cm,
+		config:  cfg,
+		tools:   tooling.NewRegistry(),
+		mcp:     make(map[string]*tooling.MCPProvider),
+		mcpErrs: make(map[string]error),
+	}
+
+	srv := sys.MCPServer{Name: "helper", Command: os.Args[0], Args: []string{"-test.run=TestMCPHelperServer"}}
+	n, err := b.AddMCPServer(srv)
+	if err != nil {
+		t.Fatalf("AddMCPServer failed: %v", err)
+	}
+	defer b.mcp["helper"].Close()
+	if n != 1 {
+		t.Fatalf("expected 1 tool, got %d", n)
+	}
+
+	tool, ok := b.tools.Get("echo")
+	if !ok {
+		t.Fatalf("expected the echo tool in the registry")
+	}
+	res, err := tool.Execute(context.Background(), json.RawMessage(`{"text": "hi"}`))
+	if err != nil || res.Content != "hi\n" {
+		t.Fatalf("unexpected call result %+v (%v)", res, err)
+	}
+
+	status := b.MCPServers(context.Background())
+	if len(status) != 1 || status[0].Err != nil || len(status[0].Tools) != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	saved := loadMCPServerFiles(b.dataDir())
+	if len(saved) != 1 || saved[0].Name != "helper" || saved[0].Command != srv.Command || len(saved[0].Args) != 1 {
+		t.Errorf("expected the server to be saved in %s, got %+v", mcpServersDir, saved)
+	}
+	if info, err := os.Stat(filepath.Join(b.dataDir(), mcpServersDir, "helper.json")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected helper.json readable only by the user, got %v (%v)", info, err)
+	}
+
+	if _, err := b.AddMCPServer(srv); err == nil {
+		t.Errorf("expected a duplicate name to be rejected")
+	}
+	if _, err := b.AddMCPServer(sys.MCPServer{Name: "../evil", Command: "true"}); err == nil {
+		t.Errorf("expected a name that is not a file name to be rejected")
+	}
+}
@@ -0,0 +1,96 @@
+package brain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/vault"
+)
+
+// memoryKeySecret is the vault entry holding the memory database key,
+// base64-encoded. While it exists, memory is encrypted at rest.
+const memoryKeySecret = "db_encryption_key"
+
+// loadMemoryKey turns on memory encryption if the vault holds a key. Only
+// a missing key means plain text; a vault that cannot be read is an error,
+// and memory then refuses writes rather than mix plain text in with
+// encrypted values.
+func (b *Brain) loadMemoryKey() error {
+	if b.vault == nil || b.memory == nil {
+		return nil
+	}
+	encoded, err := b.vault.Get(memoryKeySecret)
+	if errors.Is(err, vault.ErrNotFound) {
+		return nil // No key: memory is plain text.
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s from the vault: %w", memoryKeySecret, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("vault entry %s is not valid base64: %w", memoryKeySecret, err)
+	}
+	return b.memory.SetEncryptionKey(key)
+}
+
+// MemoryEncrypted reports whether memory is encrypted at rest.
+func (b *Brain) MemoryEncrypted() bool {
+	return b.memory != nil && b.memory.Encrypted()
+}
+
+// MemoryKeyError is why the memory key could not be loaded at startup, or
+// nil if it loaded or there is none.
+func (b *Brain) MemoryKeyError() error {
+	return b.memoryKeyErr
+}
+
+// EncryptMemory encrypts the memory database in place, creating a key in
+// the vault first if there is none. It returns how many values it
+// encrypted.
+func (b *Brain) EncryptMemory() (int, error) {
+	if b.vault == nil {
+		return 0, fmt.Errorf("vault not initialized")
+	}
+	// Never replace a key that exists but could not be read.
+	if b.memoryKeyErr != nil {
+		return 0, b.memoryKeyErr
+	}
+	if !b.memory.Encrypted() {
+		key, err := vcontext.GenerateKey()
+		if err != nil {
+			return 0, err
+		}
+		// Store the key before using it, so no row is written under a key
+		// that was never saved.
+		if err := b.vault.Set(memoryKeySecret, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return 0, fmt.Errorf("saving the key to the vault: %w", err)
+		}
+		if err := b.memory.SetEncryptionKey(key); err != nil {
+			return 0, err
+		}
+	}
+	return b.memory.Encrypt()
+}
+
+// DecryptMemory rewrites the memory database as plain text and removes the
+// key from the vault. It returns how many values it decrypted.
+func (b *Brain) DecryptMemory() (int, error) {
+	if b.vault == nil {
+		return 0, fmt.Errorf("vault not initialized")
+	}
+	if b.memoryKeyErr != nil {
+		return 0, b.memoryKeyErr
+	}
+	n, err := b.memory.Decrypt()
+	if err != nil {
+		return 0, err
+	}
+	// Only drop the key once nothing depends on it.
+	b.memory.SetEncryptionKey(nil)
+	if err := b.vault.Delete(memoryKeySecret); err != nil {
+		return n, fmt.Errorf("removing the key from the vault: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,32 @@
+package brain
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/vault"
+)
+
+func TestMemoryKeyError_LocksMemory(t *testing.T) {
+	dir := t.TempDir()
+	mem, err := vcontext.OpenMemory(filepath.Join(dir, "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := errors.New("reading db_encryption_key from the vault: " + vault.ErrDecrypt.Error())
+	b := &Brain{vault: &vault.Vault{}, memory: mem, memoryKeyErr: locked}
+
+	// A key that exists but could not be read must never be replaced.
+	if _, err := b.EncryptMemory(); err != locked {
+		t.Errorf("expected EncryptMemory to refuse, got %v", err)
+	}
+	if _, err := b.DecryptMemory(); err != locked {
+		t.Errorf("expected DecryptMemory to refuse, got %v", err)
+	}
+	if c := b.checkMemory(); c.Status != doctor.CheckFail || c.Detail != locked.Error() {
+		t.Errorf("expected the doctor check to report the key, got %+v", c)
+	}
+}
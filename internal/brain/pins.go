@@ -0,0 +1,246 @@
+package brain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// ErrPinBudget is returned when pinning a file would exceed prompt.pin_budget.
+var ErrPinBudget = errors.New("pin budget exceeded")
+
+// OnPinDropped is called when a pinned file disappears and is unpinned.
+// The CLI points this at its notification center.
+var OnPinDropped func(path string)
+
+// defaultPinBudget caps the total bytes of pinned content per prompt.
+const defaultPinBudget = 24 * 1024
+
+// PinInfo describes a pinned file for listing.
+type PinInfo struct {
+	Path    string
+	Size    int64
+	Stale   bool // Changed since it was last placed in a prompt
+	Missing bool
+}
+
+// pinSet tracks pinned paths and the hash of what the model last saw.
+type pinSet struct {
+	mu    sync.Mutex
+	paths []string
+	sent  map[string]string
+}
+
+func newPinSet() *pinSet {
+	return &pinSet{sent: make(map[string]string)}
+}
+
+// PinBudget returns the total bytes pinned files may contribute per prompt.
+func (b *Brain) PinBudget() int64 {
+	if cfg := b.cfg(); cfg != nil && cfg.Prompt.PinBudget > 0 {
+		return int64(cfg.Prompt.PinBudget)
+	}
+	return defaultPinBudget
+}
+
+// Pin adds a file to the pinned set. It refuses the pin, rather than
+// truncating existing ones, when the total would exceed the budget.
+func (b *Brain) Pin(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("cannot pin %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot pin %s: is a directory", path)
+	}
+
+	b.pins.mu.Lock()
+	defer b.pins.mu.Unlock()
+
+	var used int64
+	for _, p := range b.pins.paths {
+		if p == abs {
+			return nil
+		}
+		if fi, err := os.Stat(p); err == nil {
+			used += fi.Size()
+		}
+	}
+
+	budget := b.PinBudget()
+	if used+info.Size() > budget {
+		return fmt.Errorf("%w: %s is %d bytes and pins already use %d of %d; unpin a file or raise prompt.pin_budget",
+			ErrPinBudget, path, info.Size(), used, budget)
+	}
+
+	b.pins.paths = append(b.pins.paths, abs)
+	return nil
+}
+
+// Unpin removes a file from the pinned set.
+func (b *Brain) Unpin(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	b.pins.mu.Lock()
+	defer b.pins.mu.Unlock()
+	if !b.pins.remove(abs) {
+		return fmt.Errorf("%s is not pinned", path)
+	}
+	return nil
+}
+
+// IsPinned reports whether path is pinned.
+func (b *Brain) IsPinned(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	b.pins.mu.Lock()
+	defer b.pins.mu.Unlock()
+	for _, p := range b.pins.paths {
+		if p == abs {
+			return true
+		}
+	}
+	return false
+}
+
+// PinnedPaths returns the pinned paths in pin order, for session persistence.
+func (b *Brain) PinnedPaths() []string {
+	b.pins.mu.Lock()
+	defer b.pins.mu.Unlock()
+	return append([]string(nil), b.pins.paths...)
+}
+
+// RestorePins replaces the pinned set with paths from a saved session.
+// Files that no longer exist are dropped with a warning.
+func (b *Brain) RestorePins(paths []string) {
+	b.pins.mu.Lock()
+	b.pins.paths = nil
+	b.pins.sent = make(map[string]string)
+	var dropped []string
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			dropped = append(dropped, p)
+			continue
+		}
+		b.pins.paths = append(b.pins.paths, p)
+	}
+	b.pins.mu.Unlock()
+
+	for _, p := range dropped {
+		pinDropped(p)
+	}
+}
+
+// Pins lists the pinned files with their current size and staleness.
+func (b *Brain) Pins() []PinInfo {
+	b.pins.mu.Lock()
+	defer b.pins.mu.Unlock()
+
+	var out []PinInfo
+	for _, p := range b.pins.paths {
+		info := PinInfo{Path: p}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			info.Missing = true
+		} else {
+			info.Size = int64(len(data))
+			if last, ok := b.pins.sent[p]; ok {
+				info.Stale = last != hashPin(data)
+			}
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// PinnedContext renders the PINNED FILES prompt section. Files are read
+// fresh and sent in full every turn: the conversation history keeps only
+// what the user typed, so earlier prompts are not there to refer back to.
+func (b *Brain) PinnedContext() string {
+	b.pins.mu.Lock()
+	var sb strings.Builder
+	var dropped []string
+	budget := b.PinBudget()
+	var used int64
+
+	for _, p := range append([]string(nil), b.pins.paths...) {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			b.pins.remove(p)
+			dropped = append(dropped, p)
+			continue
+		}
+
+		hash := hashPin(data)
+
+		// Files can grow after they were pinned; say so instead of cutting silently.
+		if used+int64(len(data)) > budget {
+			keep := budget - used
+			if keep < 0 {
+				keep = 0
+			}
+			fmt.Fprintf(&sb, "--- %s (truncated: file grew past the pin budget) ---\n%s\n", p, data[:keep])
+			used = budget
+			delete(b.pins.sent, p)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "--- %s ---\n%s\n", p, data)
+		used += int64(len(data))
+		b.pins.sent[p] = hash
+	}
+	b.pins.mu.Unlock()
+
+	for _, p := range dropped {
+		pinDropped(p)
+	}
+	return sb.String()
+}
+
+// forgetSent drops what the model was last sent, for a new conversation.
+func (s *pinSet) forgetSent() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.sent = make(map[string]string)
+	s.mu.Unlock()
+}
+
+func (s *pinSet) remove(path string) bool {
+	for i, p := range s.paths {
+		if p == path {
+			s.paths = append(s.paths[:i], s.paths[i+1:]...)
+			delete(s.sent, path)
+			return true
+		}
+	}
+	return false
+}
+
+func pinDropped(path string) {
+	tooling.ReportStatus("📌", "error", fmt.Sprintf("Unpinned %s: file no longer exists", path))
+	if OnPinDropped != nil {
+		OnPinDropped(path)
+	}
+}
+
+func hashPin(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
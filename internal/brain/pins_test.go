@@ -0,0 +1,94 @@
+package brain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func newPinBrain(budget int) *Brain {
+	cfg := &sys.Config{}
+	cfg.Prompt.PinBudget = budget
+	return &Brain{config: cfg, pins: newPinSet(), sessions: make(map[string]*chatSession)}
+}
+
+func TestPinnedContext_ResendsEveryTurn(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	os.WriteFile(path, []byte("first"), 0644)
+
+	b := newPinBrain(1024)
+	if err := b.Pin(path); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+
+	if got := b.PinnedContext(); !strings.Contains(got, "first") {
+		t.Fatalf("first turn should include content, got %q", got)
+	}
+	if got := b.PinnedContext(); !strings.Contains(got, "first") {
+		t.Fatalf("unchanged file should still be sent, got %q", got)
+	}
+
+	os.WriteFile(path, []byte("second"), 0644)
+	if pins := b.Pins(); len(pins) != 1 || !pins[0].Stale {
+		t.Fatalf("expected stale pin, got %+v", pins)
+	}
+	if got := b.PinnedContext(); !strings.Contains(got, "second") {
+		t.Fatalf("changed file should be re-sent, got %q", got)
+	}
+
+	// A new conversation has seen nothing, so nothing is stale.
+	os.WriteFile(path, []byte("third"), 0644)
+	b.ResetConversation("default")
+	if pins := b.Pins(); pins[0].Stale {
+		t.Errorf("expected no stale pins after a reset, got %+v", pins)
+	}
+	b.PinnedContext()
+	os.WriteFile(path, []byte("fourth"), 0644)
+	b.SetSession("other")
+	if pins := b.Pins(); pins[0].Stale {
+		t.Errorf("expected no stale pins after a session switch, got %+v", pins)
+	}
+}
+
+func TestPin_RefusesOverBudget(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	c := filepath.Join(dir, "c.txt")
+	os.WriteFile(a, []byte(strings.Repeat("a", 60)), 0644)
+	os.WriteFile(c, []byte(strings.Repeat("c", 60)), 0644)
+
+	b := newPinBrain(100)
+	if err := b.Pin(a); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if err := b.Pin(c); !errors.Is(err, ErrPinBudget) {
+		t.Fatalf("expected ErrPinBudget, got %v", err)
+	}
+	if got := b.PinnedContext(); !strings.Contains(got, strings.Repeat("a", 60)) {
+		t.Fatal("existing pin must not be truncated")
+	}
+}
+
+func TestPinnedContext_DropsDeleted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gone.txt")
+	os.WriteFile(path, []byte("x"), 0644)
+
+	b := newPinBrain(1024)
+	b.Pin(path)
+	os.Remove(path)
+
+	var dropped string
+	OnPinDropped = func(p string) { dropped = p }
+	defer func() { OnPinDropped = nil }()
+
+	b.PinnedContext()
+	if dropped != path || b.IsPinned(path) {
+		t.Fatalf("deleted file should be unpinned with a warning (dropped=%q)", dropped)
+	}
+}
@@ -0,0 +1,72 @@
+package brain
+
+import (
+	"github.com/nathfavour/vibeauracle/prompt"
+)
+
+// Plan returns a copy of the active plan, or nil.
+func (b *Brain) Plan() *prompt.Plan {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+	return copyPlan(b.plan)
+}
+
+// SetPlan replaces the active plan (session restore, or nil to clear).
+func (b *Brain) SetPlan(p *prompt.Plan) {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+	b.plan = copyPlan(p)
+}
+
+// TogglePlanStep manually checks or unchecks a step.
+func (b *Brain) TogglePlanStep(i int) {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+	if b.plan != nil {
+		b.plan.Toggle(i)
+	}
+}
+
+// PlanContext renders the active plan's progress for the prompt.
+func (b *Brain) PlanContext() string {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+	if b.plan == nil {
+		return ""
+	}
+	return b.plan.Summary()
+}
+
+// trackPlan updates the plan from a model response. A new plan replaces the
+// active one when the request was a PLAN intent or no plan is active;
+// otherwise the response is scanned for completion markers.
+func (b *Brain) trackPlan(resp string, intent prompt.Intent) {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+
+	if p, ok := prompt.ExtractPlan(resp); ok && (intent == prompt.IntentPlan || b.plan == nil || b.plan.Current() == -1) {
+		b.plan = p
+		return
+	}
+	if b.plan != nil {
+		b.plan.MarkFromText(resp)
+	}
+}
+
+// trackPlanTool lets a tool call check off the current step.
+func (b *Brain) trackPlanTool(call toolCall) {
+	b.planMu.Lock()
+	defer b.planMu.Unlock()
+	if b.plan != nil {
+		b.plan.MarkToolCall(call.Tool, string(call.Args))
+	}
+}
+
+func copyPlan(p *prompt.Plan) *prompt.Plan {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Steps = append([]prompt.PlanStep(nil), p.Steps...)
+	return &c
+}
@@ -0,0 +1,134 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/model"
+)
+
+// PullCandidate is an Ollama model offered by /models /pull.
+type PullCandidate struct {
+	Name      string `json:"name"`
+	Params    string `json:"params,omitempty"` // Parameter count, e.g. "8B"
+	Size      string `json:"size,omitempty"`   // Download size, e.g. "4.9GB"
+	Installed bool   `json:"installed"`
+}
+
+// ollamaLibraryURL lists the models on ollama.com, in the same shape as a
+// local /api/tags.
+var ollamaLibraryURL = "https://ollama.com/api/tags"
+
+// pullLibraryTimeout bounds the library query so the selector falls back
+// to popularOllamaModels quickly when offline.
+const pullLibraryTimeout = 4 * time.Second
+
+// popularOllamaModels is offered when the library cannot be reached.
+var popularOllamaModels = []PullCandidate{
+	{Name: "llama3.2", Params: "3B", Size: "2.0GB"},
+	{Name: "llama3.1", Params: "8B", Size: "4.9GB"},
+	{Name: "qwen2.5-coder", Params: "7B", Size: "4.7GB"},
+	{Name: "qwen2.5", Params: "7B", Size: "4.7GB"},
+	{Name: "deepseek-r1", Params: "7B", Size: "4.7GB"},
+	{Name: "deepseek-coder-v2", Params: "16B", Size: "8.9GB"},
+	{Name: "gemma3", Params: "4B", Size: "3.3GB"},
+	{Name: "gemma2", Params: "9B", Size: "5.4GB"},
+	{Name: "mistral", Params: "7B", Size: "4.1GB"},
+	{Name: "phi4", Params: "14B", Size: "9.1GB"},
+	{Name: "phi3", Params: "3.8B", Size: "2.2GB"},
+	{Name: "codellama", Params: "7B", Size: "3.8GB"},
+	{Name: "starcoder2", Params: "3B", Size: "1.7GB"},
+	{Name: "llava", Params: "7B", Size: "4.7GB"},
+	{Name: "nomic-embed-text", Params: "137M", Size: "274MB"},
+}
+
+// PullCandidates lists models that can be pulled into Ollama, from the
+// Ollama library when it answers and the bundled list otherwise, marking
+// the ones already installed. If the local Ollama cannot be listed none
+// are marked.
+func (b *Brain) PullCandidates(ctx context.Context) []PullCandidate {
+	candidates, err := fetchOllamaLibrary(ctx, ollamaLibraryURL)
+	if err != nil || len(candidates) == 0 {
+		candidates = append([]PullCandidate(nil), popularOllamaModels...)
+	}
+
+	p, err := model.GetProvider("ollama", map[string]string{"endpoint": b.ollamaEndpoint()})
+	if err != nil {
+		return candidates
+	}
+	lctx, cancel := context.WithTimeout(ctx, defaultDiscoveryTimeout)
+	defer cancel()
+	local, err := p.ListModels(lctx)
+	if err != nil {
+		return candidates
+	}
+	installed := make(map[string]bool, len(local))
+	for _, name := range local {
+		installed[name] = true
+	}
+	for i, c := range candidates {
+		candidates[i].Installed = installed[withDefaultTag(c.Name)]
+	}
+	return candidates
+}
+
+// withDefaultTag adds ":latest" to an untagged model name, as Ollama does.
+func withDefaultTag(name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return name + ":latest"
+}
+
+func fetchOllamaLibrary(ctx context.Context, url string) ([]PullCandidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, pullLibraryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama library: %s", resp.Status)
+	}
+
+	var body struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Size    int64  `json:"size"`
+			Details struct {
+				ParameterSize string `json:"parameter_size"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("ollama library: %w", err)
+	}
+	out := make([]PullCandidate, 0, len(body.Models))
+	for _, m := range body.Models {
+		if m.Name == "" {
+			continue
+		}
+		c := PullCandidate{Name: strings.TrimSuffix(m.Name, ":latest"), Params: m.Details.ParameterSize}
+		if m.Size > 0 {
+			c.Size = formatModelSize(m.Size)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func formatModelSize(n int64) string {
+	if n >= 1e9 {
+		return fmt.Sprintf("%.1fGB", float64(n)/1e9)
+	}
+	return fmt.Sprintf("%dMB", n/1e6)
+}
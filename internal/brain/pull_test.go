@@ -0,0 +1,59 @@
+package brain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchOllamaLibrary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [
+			{"name": "llama3.1:latest", "size": 4920000000, "details": {"parameter_size": "8B"}},
+			{"name": "nomic-embed-text", "size": 274000000},
+			{"name": ""}
+		]}`))
+	}))
+	defer srv.Close()
+
+	got, err := fetchOllamaLibrary(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PullCandidate{
+		{Name: "llama3.1", Params: "8B", Size: "4.9GB"},
+		{Name: "nomic-embed-text", Size: "274MB"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candidates, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("candidate %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFetchOllamaLibrary_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if _, err := fetchOllamaLibrary(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected an error for a 503")
+	}
+}
+
+func TestWithDefaultTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"llama3.1":    "llama3.1:latest",
+		"qwen2.5:14b": "qwen2.5:14b",
+		"phi4:latest": "phi4:latest",
+	} {
+		if got := withDefaultTag(in); got != want {
+			t.Errorf("withDefaultTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
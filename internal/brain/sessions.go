@@ -0,0 +1,199 @@
+package brain
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+)
+
+// SessionPrefix namespaces chat transcripts in the app_state table; a
+// session named "foo" is stored under "session:foo".
+const SessionPrefix = "session:"
+
+// legacySessionKey is the single state id used before sessions existed. It
+// is migrated into the first session that finds no state of its own.
+const legacySessionKey = "chat_session"
+
+// SessionInfo describes one saved chat session.
+type SessionInfo struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Active    bool      `json:"active"`
+}
+
+// SessionName derives a stable session name from a working directory: the
+// directory's base name plus a short hash of its absolute path, so two
+// checkouts called "api" don't share a transcript.
+func SessionName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	base := strings.Map(func(r rune) rune {
+		if validSessionRune(r) {
+			return r
+		}
+		return '-'
+	}, filepath.Base(dir))
+	if base == "" || base == "." || base == string(filepath.Separator) {
+		base = "root"
+	}
+	sum := sha1.Sum([]byte(dir))
+	return truncate(base, 40) + "-" + hex.EncodeToString(sum[:3])
+}
+
+// ValidateSessionName reports whether name can be used as a session name.
+func ValidateSessionName(name string) error {
+	if name == "" {
+		return errors.New("session name is empty")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("session name %q is longer than 64 characters", name)
+	}
+	for _, r := range name {
+		if !validSessionRune(r) {
+			return fmt.Errorf("session name %q may only contain letters, digits, '.', '_' and '-'", name)
+		}
+	}
+	return nil
+}
+
+func validSessionRune(r rune) bool {
+	return r == '.' || r == '_' || r == '-' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// Session returns the active chat session, defaulting to one derived from
+// the working directory.
+func (b *Brain) Session() string {
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+	if b.session == "" {
+		wd, _ := os.Getwd()
+		b.session = SessionName(wd)
+	}
+	return b.session
+}
+
+// SetSession makes name the active chat session. The caller is responsible
+// for saving the outgoing session first.
+func (b *Brain) SetSession(name string) error {
+	if err := ValidateSessionName(name); err != nil {
+		return err
+	}
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+	b.session = name
+	b.pins.forgetSent()
+	return nil
+}
+
+// SessionKey is the app_state id of the active session.
+func (b *Brain) SessionKey() string {
+	return SessionPrefix + b.Session()
+}
+
+// StoreSession persists state as the active session's transcript.
+func (b *Brain) StoreSession(state interface{}) error {
+	return b.memory.SaveState(b.SessionKey(), state)
+}
+
+// RecallSession loads the active session's transcript into target. A
+// session with no state of its own adopts the pre-session "chat_session"
+// blob, which is then removed so it is only adopted once.
+func (b *Brain) RecallSession(target interface{}) error {
+	key := b.SessionKey()
+	err := b.memory.LoadState(key, target)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if legacyErr := b.memory.LoadState(legacySessionKey, target); legacyErr != nil {
+		return err
+	}
+	if err := b.memory.SaveState(key, target); err != nil {
+		return err
+	}
+	return b.memory.ClearState(legacySessionKey)
+}
+
+// LoadSession loads the saved transcript of session name into target,
+// without making it active.
+func (b *Brain) LoadSession(name string, target interface{}) error {
+	if err := ValidateSessionName(name); err != nil {
+		return err
+	}
+	return b.memory.LoadState(SessionPrefix+name, target)
+}
+
+// ListSessions enumerates saved chat sessions, most recently used first.
+// The active session is included even if it has not been saved yet.
+func (b *Brain) ListSessions() ([]SessionInfo, error) {
+	rows, err := b.memory.ListStates(SessionPrefix)
+	if err != nil {
+		return nil, err
+	}
+	active := b.Session()
+	var out []SessionInfo
+	seen := false
+	for _, r := range rows {
+		name := strings.TrimPrefix(r.ID, SessionPrefix)
+		out = append(out, SessionInfo{Name: name, Size: r.Size, UpdatedAt: r.UpdatedAt, Active: name == active})
+		seen = seen || name == active
+	}
+	if !seen {
+		out = append([]SessionInfo{{Name: active, Active: true}}, out...)
+	}
+	return out, nil
+}
+
+// SessionArchives returns the batches /clear removed from session name,
+// newest first.
+func (b *Brain) SessionArchives(name string) ([]vcontext.Archive, error) {
+	if err := ValidateSessionName(name); err != nil {
+		return nil, err
+	}
+	return b.memory.ListArchives(SessionPrefix + name)
+}
+
+// SessionExists reports whether name has saved state.
+func (b *Brain) SessionExists(name string) (bool, error) {
+	var probe interface{}
+	err := b.memory.LoadState(SessionPrefix+name, &probe)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteSession removes a saved session, its /clear archives and its
+// threads. The active session cannot be deleted; switch away from it first.
+func (b *Brain) DeleteSession(name string) error {
+	if err := ValidateSessionName(name); err != nil {
+		return err
+	}
+	if name == b.Session() {
+		return fmt.Errorf("session %q is active; switch to another session first", name)
+	}
+	exists, err := b.SessionExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no saved session %q", name)
+	}
+	if err := b.memory.ClearState(SessionPrefix + name); err != nil {
+		return err
+	}
+	if err := b.memory.DeleteArchives(SessionPrefix + name); err != nil {
+		return err
+	}
+	return b.memory.DeleteThreads(name)
+}
@@ -0,0 +1,83 @@
+package brain
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+)
+
+func newSessionBrain(t *testing.T) *Brain {
+	t.Helper()
+	mem, err := vcontext.OpenMemory(filepath.Join(t.TempDir(), "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Brain{memory: mem}
+}
+
+func TestSessionName_PerDirectory(t *testing.T) {
+	a, b := SessionName("/work/one/api"), SessionName("/work/two/api")
+	if a == b {
+		t.Fatalf("expected distinct sessions for distinct directories, both %q", a)
+	}
+	if !strings.HasPrefix(a, "api-") || ValidateSessionName(a) != nil {
+		t.Errorf("unexpected session name %q", a)
+	}
+	if SessionName("/work/one/api") != a {
+		t.Errorf("expected a stable name")
+	}
+}
+
+func TestSessions_SeparateTranscripts(t *testing.T) {
+	b := newSessionBrain(t)
+	b.memory.SaveState(legacySessionKey, []string{"old"})
+
+	// The first session adopts the legacy blob.
+	b.SetSession("alpha")
+	var got []string
+	if err := b.RecallSession(&got); err != nil || len(got) != 1 || got[0] != "old" {
+		t.Fatalf("expected legacy transcript, got %v (%v)", got, err)
+	}
+
+	// The second starts empty.
+	b.SetSession("beta")
+	got = nil
+	if err := b.RecallSession(&got); err == nil {
+		t.Fatalf("expected no state for a new session, got %v", got)
+	}
+	b.StoreSession([]string{"beta"})
+	got = nil
+	if err := b.LoadSession("alpha", &got); err != nil || len(got) != 1 || got[0] != "old" {
+		t.Fatalf("expected alpha's transcript from beta, got %v (%v)", got, err)
+	}
+	if ok, err := b.SessionExists("beta"); !ok || err != nil {
+		t.Fatalf("expected beta to exist (%v)", err)
+	}
+	if ok, _ := b.SessionExists("gamma"); ok {
+		t.Fatalf("expected gamma not to exist")
+	}
+
+	sessions, err := b.ListSessions()
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expected two sessions, got %+v (%v)", sessions, err)
+	}
+	if sessions[0].Name != "beta" && sessions[1].Name != "beta" {
+		t.Errorf("expected beta in %+v", sessions)
+	}
+
+	if err := b.DeleteSession("beta"); err == nil {
+		t.Fatalf("expected the active session to be protected")
+	}
+	b.SetSession("alpha")
+	if err := b.DeleteSession("beta"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if err := b.DeleteSession("beta"); err == nil {
+		t.Fatalf("expected an error deleting a missing session")
+	}
+	if sessions, _ := b.ListSessions(); len(sessions) != 1 || sessions[0].Name != "alpha" {
+		t.Errorf("expected only alpha, got %+v", sessions)
+	}
+}
@@ -0,0 +1,195 @@
+package brain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// storageDirs maps file-backed storage categories to data dir subdirectories.
+var storageDirs = []struct{ category, dir string }{
+	{"undo", "undo"},
+	{"trash", "trash"},
+	{"transcripts", "transcripts"},
+	{"recordings", "recordings"},
+	{"crash_logs", "crash_logs"},
+	{"source", "source"},
+}
+
+// StorageReport is a per-category breakdown of the data dir.
+type StorageReport struct {
+	DataDir    string             `json:"data_dir"`
+	Categories []sys.StorageUsage `json:"categories"`
+}
+
+// Total returns the combined size of every category.
+func (r StorageReport) Total() int64 {
+	var n int64
+	for _, c := range r.Categories {
+		n += c.Size
+	}
+	return n
+}
+
+func (b *Brain) dataDir() string {
+	if cfg := b.cfg(); cfg != nil && cfg.DataDir != "" {
+		return cfg.DataDir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".vibeauracle")
+}
+
+// StorageReport scans the data dir and session database.
+func (b *Brain) StorageReport() (StorageReport, error) {
+	dir := b.dataDir()
+	report := StorageReport{DataDir: dir}
+
+	sessions := sys.StorageUsage{Category: "sessions"}
+	if b.memory != nil {
+		rows, err := b.memory.StateUsage()
+		if err != nil {
+			return report, fmt.Errorf("reading session state: %w", err)
+		}
+		for _, r := range rows {
+			if !isSessionRow(r.Table, r.ID) {
+				continue
+			}
+			sessions.Items = append(sessions.Items, sys.StorageItem{
+				Category: "sessions",
+				Path:     r.Table + ":" + r.ID,
+				Size:     r.Size,
+				ModTime:  r.UpdatedAt,
+			})
+			sessions.Size += r.Size
+		}
+		sessions.Count = len(sessions.Items)
+	}
+	report.Categories = append(report.Categories, sessions)
+
+	db := filepath.Join(dir, "vibe.db")
+	report.Categories = append(report.Categories, sys.StorageUsage{
+		Category: "memory_db",
+		Size:     sys.FileSize(db, db+"-wal", db+"-shm"),
+		Count:    1,
+	})
+
+	for _, sd := range storageDirs {
+		usage, err := sys.ScanStorageDir(dir, sd.category, sd.dir)
+		if err != nil {
+			return report, fmt.Errorf("scanning %s: %w", sd.dir, err)
+		}
+		report.Categories = append(report.Categories, usage)
+	}
+	return report, nil
+}
+
+// isSessionRow reports whether a memory row belongs to a chat session:
+// its transcript, the pre-session transcript, or a cleared batch. Other
+// app_state rows, such as the chat input history, are not session data
+// and never collected.
+func isSessionRow(table, id string) bool {
+	return table == "archive" || strings.HasPrefix(id, SessionPrefix) || id == legacySessionKey
+}
+
+func (b *Brain) storagePolicy(category string) sys.StoragePolicy {
+	s := b.cfg().Storage
+	switch category {
+	case "sessions":
+		return s.Sessions
+	case "undo":
+		return s.Undo
+	case "trash":
+		return s.Trash
+	case "transcripts":
+		return s.Transcripts
+	case "recordings":
+		return s.Recordings
+	case "crash_logs":
+		return s.CrashLogs
+	case "source":
+		return s.Source
+	}
+	return sys.StoragePolicy{}
+}
+
+// PlanStorageGC lists exactly what the configured retention policies would
+// delete. The active session, keep_sessions and in-use items are never listed.
+// keep_sessions entries may name either a session or a raw state id.
+func (b *Brain) PlanStorageGC(report StorageReport) []sys.StorageItem {
+	keep := map[string]bool{"app_state:" + b.SessionKey(): true}
+	for _, id := range b.cfg().Storage.KeepSessions {
+		keep["app_state:"+id] = true
+		keep["app_state:"+SessionPrefix+id] = true
+	}
+
+	var plan []sys.StorageItem
+	now := time.Now()
+	for _, usage := range report.Categories {
+		plan = append(plan, sys.PlanGC(usage, b.storagePolicy(usage.Category), now, func(it sys.StorageItem) bool {
+			if keep[it.Path] {
+				return true
+			}
+			if it.Category == "sessions" {
+				table, id, _ := strings.Cut(it.Path, ":")
+				if !isSessionRow(table, id) {
+					return true
+				}
+			}
+			// The undo ledger outlives the backups it lists.
+			if it.Category == "undo" && filepath.Base(it.Path) == "journal.json" {
+				return true
+			}
+			// A source checkout mid-update holds git's index lock.
+			if it.Category == "source" {
+				if _, err := os.Stat(filepath.Join(it.Path, ".git", "index.lock")); err == nil {
+					return true
+				}
+			}
+			return false
+		})...)
+	}
+	return plan
+}
+
+// ApplyStorageGC deletes the planned items under the data dir lock and
+// returns the bytes reclaimed. Files touched since planning are skipped.
+func (b *Brain) ApplyStorageGC(plan []sys.StorageItem) (int64, error) {
+	unlock, err := sys.LockDataDir(b.dataDir())
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var reclaimed int64
+	var errs []error
+	for _, it := range plan {
+		if it.Category == "sessions" {
+			table, id, _ := strings.Cut(it.Path, ":")
+			if err := b.memory.DeleteRow(table, id); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			reclaimed += it.Size
+			continue
+		}
+
+		info, err := os.Stat(it.Path)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) < sys.ActiveWindow {
+			continue
+		}
+		if err := os.RemoveAll(it.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reclaimed += it.Size
+	}
+	return reclaimed, errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package brain
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestStorageGC_KeepsNonSessionState(t *testing.T) {
+	dir := t.TempDir()
+	mem, err := vcontext.OpenMemory(filepath.Join(dir, "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem.SaveState("command_history", []string{"/help", "fix the build"})
+	mem.SaveState(SessionPrefix+"old", []string{"you: hi"})
+
+	cfg := &sys.Config{DataDir: dir}
+	cfg.Storage.Sessions = sys.StoragePolicy{MaxCount: 1}
+	b := &Brain{config: cfg, memory: mem, session: "current"}
+
+	report, err := b.StorageReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range report.Categories[0].Items {
+		if it.Path == "app_state:command_history" {
+			t.Errorf("expected the input history outside the sessions category, got %+v", it)
+		}
+	}
+
+	// Rows written just now are in use; plan against an aged copy.
+	old := time.Now().AddDate(0, 0, -30)
+	aged := StorageReport{Categories: []sys.StorageUsage{{Category: "sessions", Count: 3, Items: []sys.StorageItem{
+		{Category: "sessions", Path: "app_state:command_history", Size: 10, ModTime: old},
+		{Category: "sessions", Path: "app_state:" + SessionPrefix + "old", Size: 10, ModTime: old},
+		{Category: "sessions", Path: "app_state:" + SessionPrefix + "older", Size: 10, ModTime: old.Add(-time.Hour)},
+	}}}}
+	plan := b.PlanStorageGC(aged)
+	for _, it := range plan {
+		if it.Path == "app_state:command_history" {
+			t.Errorf("expected the input history to be kept, got %+v", plan)
+		}
+	}
+	if len(plan) != 2 {
+		t.Errorf("expected both old sessions collected, got %+v", plan)
+	}
+}
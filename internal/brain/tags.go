@@ -0,0 +1,148 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on what #path tags attach to a request.
+const (
+	maxTagFileBytes  = 16 << 10 // Per file
+	maxTagBytes      = 48 << 10 // All tags in one message
+	maxTagDirEntries = 200
+)
+
+// tagPattern finds #path tags: a '#' at the start of the text or after
+// whitespace, up to the next whitespace.
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([^\s#]+)`)
+
+// TaggedFile is one #path tag resolved to a file's contents or, for a
+// directory, a listing of it.
+type TaggedFile struct {
+	Tag       string // The path as written after '#'
+	Dir       bool
+	Content   string
+	Truncated bool // Content was cut to fit maxTagFileBytes or maxTagBytes
+}
+
+// TagContext is what the #path tags in a message resolve to.
+type TagContext struct {
+	Files   []TaggedFile
+	Missing []string // Path-like tags that matched nothing
+}
+
+// Truncated lists the tags whose content was cut short.
+func (t *TagContext) Truncated() []string {
+	var out []string
+	for _, f := range t.Files {
+		if f.Truncated {
+			out = append(out, f.Tag)
+		}
+	}
+	return out
+}
+
+// Block renders the resolved tags as delimited blocks for the prompt.
+func (t *TagContext) Block() string {
+	var sb strings.Builder
+	for _, f := range t.Files {
+		kind := "FILE"
+		if f.Dir {
+			kind = "DIRECTORY"
+		}
+		note := ""
+		if f.Truncated {
+			note = " (truncated)"
+		}
+		fmt.Fprintf(&sb, "--- BEGIN %s %s%s ---\n%s", kind, f.Tag, note, f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "--- END %s %s ---\n", kind, f.Tag)
+	}
+	return sb.String()
+}
+
+// ResolveTags reads the files and directories named by #path tags in text
+// through the brain's filesystem, relative to the working directory. Each
+// file is capped at 16KB and all of them together at 48KB. Tags that match
+// nothing are reported as Missing when they look like paths (contain a '/'
+// or '.'), and otherwise taken to be plain text such as "#123".
+func (b *Brain) ResolveTags(text string) *TagContext {
+	tc := &TagContext{}
+	seen := map[string]bool{}
+	used := 0
+	for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.TrimRight(m[1], ".,;:!?)")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		f, ok := b.readTag(tag)
+		if !ok {
+			if strings.ContainsAny(tag, "/.") {
+				tc.Missing = append(tc.Missing, tag)
+			}
+			continue
+		}
+		if room := maxTagBytes - used; len(f.Content) > room {
+			f.Content = cutAtLine(f.Content, room)
+			f.Truncated = true
+		}
+		used += len(f.Content)
+		tc.Files = append(tc.Files, f)
+	}
+	return tc
+}
+
+// readTag reads tag as a file, or failing that lists it as a directory.
+func (b *Brain) readTag(tag string) (TaggedFile, bool) {
+	if b.fs == nil {
+		return TaggedFile{}, false
+	}
+	f := TaggedFile{Tag: tag}
+	if data, err := b.fs.ReadFile(tag); err == nil {
+		switch {
+		case bytes.IndexByte(data, 0) >= 0:
+			f.Content = fmt.Sprintf("(binary file, %d bytes; content not attached)", len(data))
+		case len(data) > maxTagFileBytes:
+			f.Content = cutAtLine(string(data), maxTagFileBytes)
+			f.Truncated = true
+		default:
+			f.Content = string(data)
+		}
+		return f, true
+	}
+	names, err := b.fs.ListFiles(tag)
+	if err != nil {
+		return TaggedFile{}, false
+	}
+	sort.Strings(names)
+	if len(names) > maxTagDirEntries {
+		names = names[:maxTagDirEntries]
+		f.Truncated = true
+	}
+	f.Dir = true
+	f.Content = strings.Join(names, "\n")
+	return f, true
+}
+
+// cutAtLine shortens s to at most n bytes, at a line break when there is one.
+func cutAtLine(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '\n'); i > 0 {
+		return s[:i+1]
+	}
+	return s
+}
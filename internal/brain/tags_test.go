@@ -0,0 +1,73 @@
+package brain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestResolveTags(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+	os.MkdirAll(filepath.Join(dir, "pkg", "util"), 0o755)
+	os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("package pkg\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "big.txt"), []byte(strings.Repeat("line of text\n", 2000)), 0o644)
+	b := &Brain{fs: sys.NewLocalFS(dir)}
+
+	tc := b.ResolveTags("look at #main.go, #pkg/ and #big.txt but not #nope.go or issue #42 #main.go")
+	if len(tc.Files) != 3 {
+		t.Fatalf("expected 3 attachments, got %+v", tc.Files)
+	}
+	if f := tc.Files[0]; f.Tag != "main.go" || f.Dir || f.Content != "package main\n" {
+		t.Errorf("unexpected file %+v", f)
+	}
+	if f := tc.Files[1]; f.Tag != "pkg/" || !f.Dir || f.Content != "a.go\nutil" {
+		t.Errorf("unexpected directory %+v", f)
+	}
+	if f := tc.Files[2]; !f.Truncated || len(f.Content) > maxTagFileBytes || !strings.HasSuffix(f.Content, "\n") {
+		t.Errorf("expected big.txt cut at a line within the cap, got %d bytes", len(f.Content))
+	}
+	if got := tc.Truncated(); len(got) != 1 || got[0] != "big.txt" {
+		t.Errorf("Truncated() = %v", got)
+	}
+	if len(tc.Missing) != 1 || tc.Missing[0] != "nope.go" {
+		t.Errorf("Missing = %v, want [nope.go] (#42 is not a path)", tc.Missing)
+	}
+
+	block := tc.Block()
+	for _, want := range []string{
+		"--- BEGIN FILE main.go ---\npackage main\n--- END FILE main.go ---\n",
+		"--- BEGIN DIRECTORY pkg/ ---\na.go\nutil\n--- END DIRECTORY pkg/ ---\n",
+		"--- BEGIN FILE big.txt (truncated) ---",
+	} {
+		if !strings.Contains(block, want) {
+			t.Errorf("block is missing %q", want)
+		}
+	}
+}
+
+func TestResolveTags_TotalCap(t *testing.T) {
+	dir := t.TempDir()
+	chunk := []byte(strings.Repeat("x", 80) + "\n")
+	var tags []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		os.WriteFile(filepath.Join(dir, name+".txt"), []byte(strings.Repeat(string(chunk), 160)), 0o644)
+		tags = append(tags, "#"+name+".txt")
+	}
+	b := &Brain{fs: sys.NewLocalFS(dir)}
+
+	tc := b.ResolveTags(strings.Join(tags, " "))
+	total := 0
+	for _, f := range tc.Files {
+		total += len(f.Content)
+	}
+	if total > maxTagBytes {
+		t.Errorf("attached %d bytes, over the %d cap", total, maxTagBytes)
+	}
+	if len(tc.Truncated()) == 0 {
+		t.Error("expected the last attachment to be marked truncated")
+	}
+}
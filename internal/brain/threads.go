@@ -0,0 +1,98 @@
+package brain
+
+import (
+	"fmt"
+	"strings"
+
+	vcontext "github.com/nathfavour/vibeauracle/context"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+const (
+	// restoredThreads is how many of a session's threads are loaded from
+	// memory when the session is first used.
+	restoredThreads = 20
+	// earlierThreadsInPrompt is how many of those are summarised in each
+	// prompt when prompt.include_recent_threads is on.
+	earlierThreadsInPrompt = 3
+)
+
+// restoreThreads loads the session's most recent threads, oldest first.
+// It runs with sessionsMu held.
+func (b *Brain) restoreThreads(s *chatSession) {
+	if b.memory == nil {
+		return
+	}
+	records, err := b.memory.RecentThreads(s.ID, restoredThreads)
+	if err != nil {
+		return
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		s.Threads = append(s.Threads, &tooling.Thread{
+			ID:        r.ThreadID,
+			Prompt:    r.Prompt,
+			Response:  r.Response,
+			Metadata:  r.Metadata,
+			Timestamp: r.CreatedAt.Local(),
+		})
+	}
+	s.restored = len(s.Threads)
+}
+
+// recordThread adds a finished request to its session and persists it, so
+// `vibeaura history` and later runs can see it.
+func (b *Brain) recordThread(st *loopState, t *tooling.Thread) {
+	b.sessionsMu.Lock()
+	st.session.AddThread(t)
+	b.sessionsMu.Unlock()
+	if b.memory == nil {
+		return
+	}
+	_ = b.memory.SaveThread(vcontext.ThreadRecord{
+		SessionID: st.sessionID,
+		ThreadID:  t.ID,
+		Prompt:    t.Prompt,
+		Response:  t.Response,
+		Metadata:  t.Metadata,
+		CreatedAt: t.Timestamp,
+	})
+}
+
+// Threads returns a session's saved threads, newest first, at most limit
+// of them (all if limit is not positive). An empty session means the
+// active one.
+func (b *Brain) Threads(session string, limit int) ([]vcontext.ThreadRecord, error) {
+	if session == "" {
+		session = b.Session()
+	}
+	return b.memory.RecentThreads(session, limit)
+}
+
+// Thread returns the saved thread whose id is, or starts with, id.
+func (b *Brain) Thread(id string) (*vcontext.ThreadRecord, error) {
+	return b.memory.Thread(id)
+}
+
+// ThreadContext summarises the last threads the session recorded in
+// earlier runs, one line each. Threads from this run are already in the
+// conversation.
+func (b *Brain) ThreadContext(sessionID string) string {
+	s := b.chatSession(sessionID)
+	b.sessionsMu.Lock()
+	defer b.sessionsMu.Unlock()
+	earlier := s.Threads[:s.restored]
+	if len(earlier) > earlierThreadsInPrompt {
+		earlier = earlier[len(earlier)-earlierThreadsInPrompt:]
+	}
+	var sb strings.Builder
+	for _, t := range earlier {
+		fmt.Fprintf(&sb, "- [%s] %s → %s\n", t.Timestamp.Format("2006-01-02 15:04"), oneLine(t.Prompt, 160), oneLine(t.Response, 240))
+	}
+	return sb.String()
+}
+
+// oneLine collapses whitespace in s and truncates it to n bytes.
+func oneLine(s string, n int) string {
+	return truncate(strings.Join(strings.Fields(s), " "), n)
+}
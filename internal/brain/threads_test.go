@@ -0,0 +1,41 @@
+package brain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+func TestThreads_RestoredAcrossBrains(t *testing.T) {
+	b := newSessionBrain(t)
+	b.sessions = make(map[string]*chatSession)
+	st := &loopState{sessionID: "proj", session: b.chatSession("proj")}
+	b.recordThread(st, &tooling.Thread{ID: "t-1", Prompt: "add a flag", Response: "Added --verbose."})
+	b.recordThread(st, &tooling.Thread{ID: "t-2", Prompt: "now\ntest it", Response: "Tests pass."})
+	if got := b.ThreadContext("proj"); got != "" {
+		t.Errorf("expected this run's threads left to the conversation, got %q", got)
+	}
+
+	// A new brain on the same memory, as after a restart.
+	next := &Brain{memory: b.memory, sessions: make(map[string]*chatSession)}
+	s := next.chatSession("proj")
+	if len(s.Threads) != 2 || s.Threads[0].ID != "t-1" || s.Threads[1].Prompt != "now\ntest it" {
+		t.Fatalf("expected both threads restored oldest first, got %+v", s.Threads)
+	}
+	ctx := next.ThreadContext("proj")
+	if !strings.Contains(ctx, "now test it → Tests pass.") || strings.Count(ctx, "\n") != 2 {
+		t.Errorf("unexpected thread summary:\n%s", ctx)
+	}
+	if other := next.ThreadContext("elsewhere"); other != "" {
+		t.Errorf("expected nothing for another session, got %q", other)
+	}
+
+	list, err := next.Threads("proj", 1)
+	if err != nil || len(list) != 1 || list[0].ThreadID != "t-2" {
+		t.Errorf("expected the newest thread, got %+v (%v)", list, err)
+	}
+	if th, err := next.Thread("t-1"); err != nil || th.Response != "Added --verbose." {
+		t.Errorf("expected the thread by id, got %+v (%v)", th, err)
+	}
+}
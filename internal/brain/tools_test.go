@@ -0,0 +1,141 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/tooling"
+)
+
+// nativeProvider answers with structured tool calls, like OpenAI.
+type nativeProvider struct {
+	MockProvider
+	replies []*model.ToolResponse
+	prompts []string
+	sent    [][]model.Message
+	offered []model.ToolMetadata
+}
+
+func (p *nativeProvider) GenerateWithTools(ctx context.Context, messages []model.Message, tools []model.ToolMetadata) (*model.ToolResponse, error) {
+	p.prompts = append(p.prompts, model.FlattenMessages(messages))
+	p.sent = append(p.sent, append([]model.Message(nil), messages...))
+	p.offered = tools
+	i := len(p.prompts) - 1
+	if i >= len(p.replies) {
+		i = len(p.replies) - 1
+	}
+	return p.replies[i], nil
+}
+
+// echoTool returns its "text" argument and records each call.
+type echoTool struct {
+	seen []string
+}
+
+func (e *echoTool) Metadata() tooling.ToolMetadata {
+	return tooling.ToolMetadata{Name: "echo", Category: tooling.CategorySystem}
+}
+
+func (e *echoTool) Execute(ctx context.Context, args json.RawMessage) (*tooling.ToolResult, error) {
+	var in struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(args, &in)
+	e.seen = append(e.seen, in.Text)
+	return &tooling.ToolResult{Status: "success", Content: "echo: " + in.Text}, nil
+}
+
+func TestRunLoop_NativeToolCalls(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := New()
+	tool := &echoTool{}
+	b.tools.Register(tool)
+	provider := &nativeProvider{replies: []*model.ToolResponse{
+		{
+			// Prose with a fenced sample that must not be taken as a call.
+			Text: "For reference:\n```json\n{\"tool\": \"made_up\", \"parameters\": {}}\n```\nChecking both now.",
+			Calls: []model.ToolCall{
+				{ID: "call_a", Name: "echo", Arguments: json.RawMessage(`{"text":"first"}`)},
+				{ID: "call_b", Name: "echo", Arguments: json.RawMessage(`{"text":"second"}`)},
+			},
+		},
+		{Text: "Both echoed."},
+	}}
+	b.model = model.New(provider)
+
+	var chunks []StreamChunk
+	resp, err := b.ProcessStream(context.Background(), Request{ID: "native-1", Content: "echo twice"}, func(c StreamChunk) {
+		chunks = append(chunks, c)
+	})
+	if err != nil {
+		t.Fatalf("ProcessStream failed: %v", err)
+	}
+	if resp.Content != "Both echoed." {
+		t.Fatalf("expected the final answer, got %q", resp.Content)
+	}
+	if len(provider.offered) == 0 {
+		t.Error("expected the core tools to be offered natively")
+	}
+	if len(tool.seen) != 2 || tool.seen[0] != "first" || tool.seen[1] != "second" {
+		t.Fatalf("expected both calls to run in order, got %v", tool.seen)
+	}
+
+	sent := provider.sent[1]
+	if len(sent) < 3 {
+		t.Fatalf("expected the calls and their results in the next request, got %+v", sent)
+	}
+	turn, results := sent[len(sent)-3], sent[len(sent)-2:]
+	if turn.Role != model.RoleAssistant || len(turn.ToolCalls) != 2 || turn.ToolCalls[0].ID != "call_a" || turn.ToolCalls[1].Name != "echo" {
+		t.Errorf("expected the assistant turn to carry both native calls, got %+v", turn)
+	}
+	for i, want := range []struct{ id, text string }{{"call_a", "echo: first"}, {"call_b", "echo: second"}} {
+		if r := results[i]; r.Role != model.RoleTool || r.ToolCallID != want.id || r.Content != want.text {
+			t.Errorf("expected result %d as a tool message for %s, got %+v", i, want.id, r)
+		}
+	}
+	next := provider.prompts[1]
+	if strings.Contains(next, "made_up") && strings.Contains(next, "not found") {
+		t.Error("a fenced sample in the prose was executed as a call")
+	}
+	if len(chunks) != 2 || chunks[0].Turn != 0 || chunks[1].Text != "Both echoed." {
+		t.Errorf("expected each turn's text as one chunk, got %+v", chunks)
+	}
+}
+
+func TestRunLoop_NativeCallsResumeAfterIntervention(t *testing.T) {
+	b, _, gated := newGatedBrain(t)
+	echo := &echoTool{}
+	b.tools.Register(echo)
+	provider := &nativeProvider{replies: []*model.ToolResponse{
+		{Calls: []model.ToolCall{
+			{ID: "call_1", Name: "gated_write", Arguments: json.RawMessage(`{"path":"notes.md"}`)},
+			{ID: "call_2", Name: "echo", Arguments: json.RawMessage(`{"text":"after"}`)},
+		}},
+		{Text: "Done."},
+	}}
+	b.model = model.New(provider)
+
+	if _, err := b.Process(context.Background(), Request{ID: "native-2", Content: "write then echo"}); err == nil {
+		t.Fatal("expected an intervention for the gated call")
+	}
+	if len(echo.seen) != 0 {
+		t.Fatalf("expected the later call to wait for approval, got %v", echo.seen)
+	}
+
+	resp, err := b.ResumeIntervention(context.Background(), tooling.ChoiceApproveOnce, nil)
+	if err != nil {
+		t.Fatalf("ResumeIntervention failed: %v", err)
+	}
+	if gated.ran != 1 || len(echo.seen) != 1 || resp.Content != "Done." {
+		t.Fatalf("expected both calls to run before the final turn, gated=%d echo=%v resp=%q", gated.ran, echo.seen, resp.Content)
+	}
+	sent := provider.sent[1]
+	results := sent[len(sent)-2:]
+	if results[0].ToolCallID != "call_1" || results[0].Content != "wrote notes.md" ||
+		results[1].ToolCallID != "call_2" || results[1].Content != "echo: after" {
+		t.Errorf("expected both results in the next request, got %+v", results)
+	}
+}
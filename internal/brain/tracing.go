@@ -0,0 +1,104 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/nathfavour/vibeauracle/internal/doctor"
+	"github.com/nathfavour/vibeauracle/model"
+	"github.com/nathfavour/vibeauracle/tooling"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is opt-in. With OTEL_EXPORTER_OTLP_ENDPOINT set, each request
+// is exported over OTLP/HTTP as a brain.process span with a brain.turn
+// child per loop turn, and brain.generate, brain.parse, brain.execute and
+// brain.observe under each turn. Otherwise the global tracer provider
+// stays OpenTelemetry's no-op and spans cost next to nothing.
+
+var tracer = otel.Tracer("github.com/nathfavour/vibeauracle/brain")
+
+var (
+	tracingOnce     sync.Once
+	tracingProvider *sdktrace.TracerProvider
+)
+
+// setupTracing installs the OTLP exporter, once per process, when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set. The other OTEL_* variables, like
+// OTEL_SERVICE_NAME and OTEL_EXPORTER_OTLP_HEADERS, are honored too.
+func setupTracing() {
+	tracingOnce.Do(func() {
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+			return
+		}
+		ctx := context.Background()
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			doctor.Send("tracing", doctor.SignalWarning, fmt.Sprintf("tracing disabled: %v", err), nil)
+			return
+		}
+		res, err := resource.New(ctx,
+			resource.WithAttributes(attribute.String("service.name", "vibeauracle")),
+			resource.WithFromEnv(),
+			resource.WithTelemetrySDK(),
+		)
+		if err != nil {
+			res = resource.Default()
+		}
+		tracingProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+		otel.SetTracerProvider(tracingProvider)
+	})
+}
+
+// ShutdownTracing exports the spans still buffered. Call it before the
+// process exits; it does nothing when tracing is off.
+func ShutdownTracing(ctx context.Context) error {
+	if tracingProvider == nil {
+		return nil
+	}
+	return tracingProvider.Shutdown(ctx)
+}
+
+// endSpan ends span, marking it failed when err is set. An intervention
+// pauses the loop rather than failing it, so it is only noted.
+func endSpan(span trace.Span, err error) {
+	var ie *tooling.InterventionError
+	switch {
+	case err == nil:
+	case errors.As(err, &ie):
+		span.SetAttributes(attribute.Bool("intervention", true))
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endRequestSpan ends a brain.process or brain.resume span with the
+// request's outcome.
+func endRequestSpan(span trace.Span, resp Response, err error) {
+	span.SetAttributes(
+		attribute.Int("response.length", len(resp.Content)),
+		attribute.Int("tool_calls", len(resp.ToolCalls)),
+	)
+	endSpan(span, err)
+}
+
+// messagesLength is the size of a conversation in bytes, the prompt
+// length recorded on brain.generate spans.
+func messagesLength(messages []model.Message) int {
+	n := 0
+	for _, m := range messages {
+		n += len(m.Content)
+	}
+	return n
+}
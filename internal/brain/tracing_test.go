@@ -0,0 +1,62 @@
+package brain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/tooling"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProcess_Spans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	b, _, _ := newGatedBrain(t)
+	if _, err := b.Process(context.Background(), Request{ID: "trace-1", Content: "write notes"}); err == nil {
+		t.Fatal("expected an intervention")
+	}
+	if _, err := b.ResumeIntervention(context.Background(), tooling.ChoiceApproveOnce, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		spans[s.Name()] = append(spans[s.Name()], s)
+	}
+	attr := func(s sdktrace.ReadOnlySpan, key string) attribute.Value {
+		for _, kv := range s.Attributes() {
+			if string(kv.Key) == key {
+				return kv.Value
+			}
+		}
+		return attribute.Value{}
+	}
+
+	root := spans["brain.process"]
+	if len(root) != 1 || attr(root[0], "prompt.length").AsInt64() != int64(len("write notes")) || !attr(root[0], "intervention").AsBool() {
+		t.Fatalf("expected one brain.process span paused on the intervention, got %+v", root)
+	}
+	if len(spans["brain.resume"]) != 1 || attr(spans["brain.resume"][0], "response.length").AsInt64() == 0 {
+		t.Errorf("expected a brain.resume span with the reply length")
+	}
+	turns := spans["brain.turn"]
+	if len(turns) != 2 || attr(turns[0], "turn").AsInt64() != 1 || attr(turns[1], "turn").AsInt64() != 2 {
+		t.Fatalf("expected turns 1 and 2, got %d turn spans", len(turns))
+	}
+	if turns[0].Parent().SpanID() != root[0].SpanContext().SpanID() {
+		t.Error("expected the first turn under brain.process")
+	}
+	for _, name := range []string{"brain.generate", "brain.parse"} {
+		if len(spans[name]) != 2 || spans[name][0].Parent().SpanID() != turns[0].SpanContext().SpanID() {
+			t.Errorf("expected a %s span under each turn", name)
+		}
+	}
+	exec := spans["brain.execute"]
+	if len(exec) != 1 || attr(exec[0], "tool.name").AsString() != "gated_write" {
+		t.Errorf("expected a brain.execute span for gated_write, got %d", len(exec))
+	}
+}
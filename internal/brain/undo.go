@@ -0,0 +1,26 @@
+package brain
+
+import (
+	"errors"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+var errNoUndo = errors.New("undo journal not initialized")
+
+// UndoWrites lists the agent's file writes that can be reverted, newest
+// first.
+func (b *Brain) UndoWrites() ([]sys.UndoEntry, error) {
+	if b.undo == nil {
+		return nil, errNoUndo
+	}
+	return b.undo.List()
+}
+
+// UndoWrite reverts the write recorded as id.
+func (b *Brain) UndoWrite(id string) (*sys.UndoEntry, error) {
+	if b.undo == nil {
+		return nil, errNoUndo
+	}
+	return b.undo.Restore(id)
+}
@@ -0,0 +1,118 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Archive is a batch of chat messages removed from the live session but kept
+// for search and export.
+type Archive struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	Messages  []string  `json:"messages"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArchiveMessages stores a batch of messages for a session and returns its id.
+func (m *Memory) ArchiveMessages(sessionID string, messages []string) (int64, error) {
+	if m.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := m.seal(string(data))
+	if err != nil {
+		return 0, err
+	}
+	res, err := m.db.Exec("INSERT INTO archive (session_id, messages) VALUES (?, ?)", sessionID, stored)
+	if err != nil {
+		return 0, fmt.Errorf("archiving messages: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ArchiveAndReplace archives the given messages and then replaces the live
+// state. The archive is committed first, so a failure in between leaves the
+// old live state intact alongside the archived copy.
+func (m *Memory) ArchiveAndReplace(sessionID string, archived []string, next interface{}) (int64, error) {
+	id, err := m.ArchiveMessages(sessionID, archived)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.SaveState(sessionID, next); err != nil {
+		return id, fmt.Errorf("replacing live state (archive %d kept): %w", id, err)
+	}
+	return id, nil
+}
+
+// ListArchives returns the archives for a session, newest first. An empty
+// sessionID lists every session.
+func (m *Memory) ListArchives(sessionID string) ([]Archive, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	query := "SELECT id, session_id, messages, created_at FROM archive"
+	var args []interface{}
+	if sessionID != "" {
+		query += " WHERE session_id = ?"
+		args = append(args, sessionID)
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Archive
+	for rows.Next() {
+		var a Archive
+		var data string
+		if err := rows.Scan(&a.ID, &a.SessionID, &data, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if data, err = m.open(data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &a.Messages); err != nil {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// LatestArchive returns the most recent archive for a session.
+func (m *Memory) LatestArchive(sessionID string) (*Archive, error) {
+	list, err := m.ListArchives(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no archive for session %q", sessionID)
+	}
+	return &list[0], nil
+}
+
+// DeleteArchive removes an archive entry.
+func (m *Memory) DeleteArchive(id int64) error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	_, err := m.db.Exec("DELETE FROM archive WHERE id = ?", id)
+	return err
+}
+
+// DeleteArchives removes every archive entry for a session.
+func (m *Memory) DeleteArchives(sessionID string) error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	_, err := m.db.Exec("DELETE FROM archive WHERE session_id = ?", sessionID)
+	return err
+}
@@ -0,0 +1,74 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveAndReplace(t *testing.T) {
+	m, err := OpenMemory(filepath.Join(t.TempDir(), "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := []string{"hello", "world"}
+	if err := m.SaveState("chat_session", old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.ArchiveAndReplace("chat_session", old, []string{}); err != nil {
+		t.Fatalf("ArchiveAndReplace failed: %v", err)
+	}
+
+	var live []string
+	if err := m.LoadState("chat_session", &live); err != nil || len(live) != 0 {
+		t.Fatalf("expected empty live state, got %v (%v)", live, err)
+	}
+
+	a, err := m.LatestArchive("chat_session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Messages) != 2 || a.Messages[0] != "hello" {
+		t.Errorf("unexpected archive contents: %v", a.Messages)
+	}
+}
+
+func TestArchiveAndReplace_FailureKeepsBothCopies(t *testing.T) {
+	m, err := OpenMemory(filepath.Join(t.TempDir(), "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := []string{"a", "b", "c"}
+	if err := m.SaveState("chat_session", old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash between the archive write and the live-state write.
+	_, err = m.db.Exec(`CREATE TRIGGER fail_state BEFORE INSERT ON app_state
+		BEGIN SELECT RAISE(ABORT, 'simulated failure'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.ArchiveAndReplace("chat_session", old, []string{}); err == nil {
+		t.Fatal("expected live-state write to fail")
+	}
+
+	var live []string
+	if err := m.LoadState("chat_session", &live); err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 3 {
+		t.Errorf("live state should be untouched, got %v", live)
+	}
+
+	a, err := m.LatestArchive("chat_session")
+	if err != nil {
+		t.Fatalf("archive should have been committed: %v", err)
+	}
+	if len(a.Messages) != 3 {
+		t.Errorf("unexpected archive contents: %v", a.Messages)
+	}
+}
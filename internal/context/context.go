@@ -1,6 +1,8 @@
 package context
 
 import (
+	gocontext "context"
+	"crypto/cipher"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -22,48 +24,130 @@ type ContextItem struct {
 	Frequency int       `json:"frequency"` // How often this item is requested/referenced
 	LastUsed  time.Time `json:"last_used"`
 	Pinned    bool      `json:"pinned"` // Critical info that never leaves the window
+	Tokens    int       `json:"tokens"` // Estimated token count of Content
 }
 
-// Window manages the rolling context of information.
+// DefaultContextTokens is the window budget when none is configured.
+const DefaultContextTokens = 8192
+
+// truncatedMarker ends a pinned item cut down to fit the budget.
+const truncatedMarker = "\n[truncated]"
+
+// Tokenizer counts the tokens a model would see for a piece of text.
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// EstimateTokens is the default Tokenizer: about four bytes per token.
+type EstimateTokens struct{}
+
+func (EstimateTokens) Count(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Window manages the rolling context of information within a token budget.
 type Window struct {
 	Items     map[string]*ContextItem
-	MaxLength int // Max tokens or items (simplified as item count for now)
+	MaxTokens int // Budget for the sum of item token counts
+	Tokenizer Tokenizer
+	total     int
 	mu        sync.RWMutex
 }
 
-func NewWindow(maxItems int) *Window {
+// NewWindow returns a window holding at most maxTokens estimated tokens.
+func NewWindow(maxTokens int) *Window {
+	if maxTokens <= 0 {
+		maxTokens = DefaultContextTokens
+	}
 	return &Window{
 		Items:     make(map[string]*ContextItem),
-		MaxLength: maxItems,
+		MaxTokens: maxTokens,
+		Tokenizer: EstimateTokens{},
 	}
 }
 
 // Add inserts or updates an item in the context window.
 func (w *Window) Add(id, content, itemType string) {
+	w.add(id, content, itemType, false)
+}
+
+// AddPinned inserts or updates an item that is never pruned. A pinned item
+// larger than half the budget is truncated.
+func (w *Window) AddPinned(id, content, itemType string) {
+	w.add(id, content, itemType, true)
+}
+
+func (w *Window) add(id, content, itemType string, pinned bool) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if item, exists := w.Items[id]; exists {
+	item, exists := w.Items[id]
+	if exists {
 		item.Frequency++
 		item.LastUsed = time.Now()
 		item.Content = content // Update content if it changed
-		return
+		item.Pinned = item.Pinned || pinned
+		w.total -= item.Tokens
+	} else {
+		item = &ContextItem{
+			ID:        id,
+			Content:   content,
+			Type:      itemType,
+			Frequency: 1,
+			LastUsed:  time.Now(),
+			Pinned:    pinned,
+		}
+		w.Items[id] = item
 	}
-
-	w.Items[id] = &ContextItem{
-		ID:        id,
-		Content:   content,
-		Type:      itemType,
-		Frequency: 1,
-		LastUsed:  time.Now(),
+	item.Tokens = w.Tokenizer.Count(item.Content)
+	if item.Pinned {
+		w.fitPinned(item)
 	}
+	w.total += item.Tokens
 
 	w.prune()
 }
 
-// prune enforces the window size by removing ensuring least relevant items are dropped.
+// fitPinned truncates a pinned item to half the budget.
+func (w *Window) fitPinned(item *ContextItem) {
+	limit := w.MaxTokens / 2
+	if item.Tokens <= limit {
+		return
+	}
+	content := item.Content
+	for len(content) > 0 && w.Tokenizer.Count(content+truncatedMarker) > limit {
+		// Shrink in proportion to the overshoot; the tokenizer may not be linear.
+		n := len(content) * limit / w.Tokenizer.Count(content+truncatedMarker)
+		if n >= len(content) {
+			n = len(content) - 1
+		}
+		content = content[:n]
+	}
+	item.Content = content + truncatedMarker
+	item.Tokens = w.Tokenizer.Count(item.Content)
+}
+
+// prune evicts the least relevant unpinned items until the window fits its
+// token budget.
 func (w *Window) prune() {
-	if len(w.Items) <= w.MaxLength {
+	w.pruneTo(w.MaxTokens)
+}
+
+// Prune evicts the least relevant unpinned items until at least tokens
+// estimated tokens are freed or only pinned items are left. It returns the
+// tokens freed.
+func (w *Window) Prune(tokens int) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	before := w.total
+	w.pruneTo(w.total - tokens)
+	return before - w.total
+}
+
+// pruneTo evicts the least relevant unpinned items until the window holds
+// at most limit tokens.
+func (w *Window) pruneTo(limit int) {
+	if w.total <= limit {
 		return
 	}
 
@@ -91,14 +175,22 @@ func (w *Window) prune() {
 	})
 
 	// Remove items until we fit
-	excess := len(w.Items) - w.MaxLength
-	for i := 0; i < excess && i < len(ranked); i++ {
+	for i := 0; w.total > limit && i < len(ranked); i++ {
+		w.total -= w.Items[ranked[i].ID].Tokens
 		delete(w.Items, ranked[i].ID)
 	}
 }
 
-// GetContext returns the formatted context string, sorted by relevance.
-func (w *Window) GetContext() string {
+// Tokens returns the estimated token count of everything in the window.
+func (w *Window) Tokens() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.total
+}
+
+// GetContext returns the formatted context string, sorted by relevance,
+// and its estimated token count.
+func (w *Window) GetContext() (string, int) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
@@ -119,26 +211,42 @@ func (w *Window) GetContext() string {
 	for _, item := range activeItems {
 		sb.WriteString(fmt.Sprintf("[%s] (%s):\n%s\n---\n", item.Type, item.ID, item.Content))
 	}
-	return sb.String()
+	return sb.String(), w.total
 }
 
 // Memory now wraps the Window system + DB persistence
 type Memory struct {
 	db     *sql.DB
 	Window *Window
+	index  vectorIndex
+	aead   cipher.AEAD // Encrypts stored values when set; see crypt.go
+
+	// sealedRows is set while some stored value is encrypted, so writes
+	// without a key are refused rather than mixed in as plain text.
+	sealedRows bool
 }
 
-func NewMemory() *Memory {
-	// ... (DB Init logic remains same) ...
+// NewMemory opens the default database with a context window of
+// contextTokens estimated tokens (DefaultContextTokens if not positive).
+func NewMemory(contextTokens int) *Memory {
 	home, _ := os.UserHomeDir()
 	dbDir := filepath.Join(home, ".vibeauracle")
 	os.MkdirAll(dbDir, 0755)
 
-	dbPath := filepath.Join(dbDir, "vibe.db")
-	db, err := sql.Open("sqlite", dbPath)
+	m, err := OpenMemory(filepath.Join(dbDir, "vibe.db"))
 	if err != nil {
 		fmt.Printf("Error opening database: %v\n", err)
-		return &Memory{Window: NewWindow(50)} // Safe fallback
+		return &Memory{Window: NewWindow(contextTokens)} // Safe fallback
+	}
+	m.Window = NewWindow(contextTokens)
+	return m
+}
+
+// OpenMemory opens (or creates) the memory database at dbPath.
+func OpenMemory(dbPath string) (*Memory, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
 	}
 
 	// Initialize tables (same as before)
@@ -153,15 +261,36 @@ func NewMemory() *Memory {
 			data TEXT,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
+		CREATE TABLE IF NOT EXISTS archive (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT,
+			messages TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS threads (
+			thread_id TEXT PRIMARY KEY,
+			session_id TEXT,
+			prompt TEXT,
+			response TEXT,
+			metadata TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS threads_session ON threads (session_id, created_at);
 	`)
 	if err != nil {
 		fmt.Printf("Error initializing database tables: %v\n", err)
 	}
 
-	return &Memory{
+	m := &Memory{
 		db:     db,
-		Window: NewWindow(50), // Standard context size
+		Window: NewWindow(DefaultContextTokens),
+	}
+	// A database that cannot be scanned still opens, so it can be checked,
+	// but without risking plain text next to encrypted values.
+	if sealed, err := m.hasSealed(); err != nil || sealed {
+		m.sealedRows = true
 	}
+	return m, nil
 }
 
 // AddToWindow pushes content into the short-term rolling context.
@@ -171,43 +300,130 @@ func (m *Memory) AddToWindow(id, content, itemType string) {
 	}
 }
 
+// PinToWindow pushes content into the rolling context as an item that is
+// never pruned.
+func (m *Memory) PinToWindow(id, content, itemType string) {
+	if m.Window != nil {
+		m.Window.AddPinned(id, content, itemType)
+	}
+}
+
+// WindowTokens reports the estimated tokens in the context window and its
+// budget.
+func (m *Memory) WindowTokens() (used, budget int) {
+	if m.Window == nil {
+		return 0, 0
+	}
+	return m.Window.Tokens(), m.Window.MaxTokens
+}
+
+// PruneWindow evicts the least relevant unpinned items from the context
+// window until tokens estimated tokens are freed, and returns how many were.
+func (m *Memory) PruneWindow(tokens int) int {
+	if m.Window == nil {
+		return 0
+	}
+	return m.Window.Prune(tokens)
+}
+
 // Store adds a fact or snippet to the long-term db memory.
 func (m *Memory) Store(key string, value string) error {
 	if m.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
-	_, err := m.db.Exec("INSERT OR REPLACE INTO memory (key, value) VALUES (?, ?)", key, value)
+	stored, err := m.seal(value)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec("INSERT OR REPLACE INTO memory (key, value) VALUES (?, ?)", key, stored)
+	if err == nil {
+		m.index.noteStored(key, value)
+	}
 	return err
 }
 
 // Recall retrieves relevant snippets from both short-term window and long-term DB.
+// Long-term memory is searched by meaning when an embedder is set and by
+// substring otherwise.
 func (m *Memory) Recall(query string) ([]string, error) {
 	var results []string
 
 	// 1. Get highly relevant short-term context
 	if m.Window != nil {
+		window, _ := m.Window.GetContext()
 		results = append(results, "--- Current Context Window ---")
-		results = append(results, m.Window.GetContext())
+		results = append(results, window)
 	}
 
 	// 2. Query long-term memory
 	if m.db != nil {
-		rows, err := m.db.Query("SELECT value FROM memory WHERE value LIKE ? LIMIT 5", "%"+query+"%")
-		if err == nil {
-			defer rows.Close()
+		ctx, cancel := gocontext.WithTimeout(gocontext.Background(), recallTimeout)
+		defer cancel()
+		if snippets, err := m.RecallSemantic(ctx, query); err == nil {
 			results = append(results, "--- Long-Term Memory ---")
-			for rows.Next() {
-				var s string
-				if err := rows.Scan(&s); err == nil {
-					results = append(results, s)
-				}
-			}
+			results = append(results, snippets...)
 		}
 	}
 
 	return results, nil
 }
 
+// recallTimeout bounds the semantic search inside Recall.
+const recallTimeout = 5 * time.Second
+
+// RecallByKeyword returns up to limit long-term memory values containing
+// query as a substring.
+func (m *Memory) RecallByKeyword(query string, limit int) ([]string, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	if m.aead != nil {
+		return m.recallEncrypted(query, limit)
+	}
+	rows, err := m.db.Query("SELECT value FROM memory WHERE value LIKE ? LIMIT ?", "%"+query+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			continue
+		}
+		if s, err = m.open(s); err == nil {
+			results = append(results, s)
+		}
+	}
+	return results, rows.Err()
+}
+
+// recallEncrypted is RecallByKeyword for an encrypted database, where SQL
+// cannot see into values: it decrypts each one and matches like LIKE does,
+// ignoring ASCII case.
+func (m *Memory) recallEncrypted(query string, limit int) ([]string, error) {
+	rows, err := m.db.Query("SELECT value FROM memory")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	needle := strings.ToLower(query)
+	var results []string
+	for rows.Next() && len(results) < limit {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			continue
+		}
+		if s, err = m.open(s); err == nil && strings.Contains(strings.ToLower(s), needle) {
+			results = append(results, s)
+		}
+	}
+	return results, rows.Err()
+}
+
 // SaveState persists arbitrary application state (JSON)
 func (m *Memory) SaveState(id string, state interface{}) error {
 	if m.db == nil {
@@ -217,7 +433,11 @@ func (m *Memory) SaveState(id string, state interface{}) error {
 	if err != nil {
 		return err
 	}
-	_, err = m.db.Exec("INSERT OR REPLACE INTO app_state (id, data) VALUES (?, ?)", id, string(data))
+	stored, err := m.seal(string(data))
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec("INSERT OR REPLACE INTO app_state (id, data) VALUES (?, ?)", id, stored)
 	return err
 }
 
@@ -231,6 +451,9 @@ func (m *Memory) LoadState(id string, target interface{}) error {
 	if err != nil {
 		return err
 	}
+	if data, err = m.open(data); err != nil {
+		return err
+	}
 	return json.Unmarshal([]byte(data), target)
 }
 
@@ -0,0 +1,100 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWindow_PrunesByTokens(t *testing.T) {
+	w := NewWindow(100)
+	w.Add("small-1", strings.Repeat("a", 40), "file") // 10 tokens
+	w.Add("small-1", strings.Repeat("a", 40), "file") // used twice, ranks higher
+	w.Add("big", strings.Repeat("b", 360), "file")    // 90 tokens
+	w.Add("small-2", strings.Repeat("c", 40), "file")
+
+	if w.Tokens() > 100 {
+		t.Fatalf("expected the window within budget, got %d tokens", w.Tokens())
+	}
+	if _, ok := w.Items["small-1"]; !ok {
+		t.Errorf("expected the frequently used item to survive")
+	}
+	ctx, tokens := w.GetContext()
+	if tokens != w.Tokens() || !strings.Contains(ctx, "(small-1)") {
+		t.Errorf("unexpected context (%d tokens):\n%s", tokens, ctx)
+	}
+}
+
+func TestWindow_TruncatesLargePinned(t *testing.T) {
+	w := NewWindow(100)
+	w.AddPinned("spec", strings.Repeat("x", 1000), "file")
+	w.Add("note", strings.Repeat("y", 400), "file") // Alone exceeds what is left
+
+	spec := w.Items["spec"]
+	if spec == nil || !strings.HasSuffix(spec.Content, "[truncated]") || spec.Tokens > 50 {
+		t.Fatalf("expected the pinned item cut to half the budget, got %+v", spec)
+	}
+	if _, ok := w.Items["note"]; ok || w.Tokens() > 100 {
+		t.Errorf("expected the unpinned item evicted to fit, %d tokens", w.Tokens())
+	}
+}
+
+func TestWindow_PruneFreesLeastUsed(t *testing.T) {
+	w := NewWindow(1000)
+	w.AddPinned("spec", strings.Repeat("s", 40), "file") // 10 tokens
+	w.Add("hot", strings.Repeat("h", 40), "file")
+	w.Add("hot", strings.Repeat("h", 40), "file")
+	w.Add("cold-1", strings.Repeat("a", 40), "file")
+	w.Add("cold-2", strings.Repeat("b", 40), "file")
+
+	if freed := w.Prune(15); freed != 20 {
+		t.Fatalf("expected two 10-token items freed, got %d", freed)
+	}
+	if _, ok := w.Items["hot"]; !ok {
+		t.Errorf("expected the frequently used item to survive")
+	}
+	if freed := w.Prune(100); freed != 10 || w.Items["spec"] == nil {
+		t.Errorf("expected only the pinned item left, freed %d, items %v", freed, w.Items)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibe.db")
+	m, err := OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		m.Store(fmt.Sprintf("key-%d", i), strings.Repeat("value ", 50))
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Fatalf("expected a fresh database to pass, got %v", err)
+	}
+	m.db.Close()
+
+	// Scribble over the headers of the table pages, keeping the schema on
+	// page 1 readable.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := f.Stat()
+	for off := int64(4096); off < info.Size(); off += 4096 {
+		f.WriteAt(bytes.Repeat([]byte{0xff}, 16), off)
+	}
+	f.Close()
+
+	m, err = OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CheckIntegrity(); err == nil {
+		t.Error("expected the damaged database to fail the check")
+	}
+	if err := (&Memory{}).CheckIntegrity(); err == nil {
+		t.Error("expected an error without a database")
+	}
+}
@@ -0,0 +1,236 @@
+package context
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a column value encrypted by the memory's key. Values
+// without it are plain text, so a database can be read while it is being
+// migrated either way.
+const sealedPrefix = "vbenc1:"
+
+// KeySize is the length of a memory encryption key: AES-256.
+const KeySize = 32
+
+// ErrNoKey is returned when an encrypted value is read without a key.
+var ErrNoKey = errors.New("memory database is encrypted, but no encryption key is set")
+
+// sealedColumns are the columns holding conversation content, by table and
+// primary key. Keys, ids and timestamps stay in the clear for lookups.
+var sealedColumns = []struct{ table, id, column string }{
+	{"memory", "key", "value"},
+	{"app_state", "id", "data"},
+	{"archive", "id", "messages"},
+	{"threads", "thread_id", "prompt"},
+	{"threads", "thread_id", "response"},
+	{"threads", "thread_id", "metadata"},
+}
+
+// GenerateKey returns a random encryption key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SetEncryptionKey encrypts everything written from now on with key, using
+// AES-256-GCM, and decrypts what is read. A nil key writes plain text again.
+// Call it before the memory is shared.
+func (m *Memory) SetEncryptionKey(key []byte) error {
+	if key == nil {
+		m.aead = nil
+		return nil
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("encryption key is %d bytes, want %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	m.aead = aead
+	return nil
+}
+
+// Encrypted reports whether writes are encrypted.
+func (m *Memory) Encrypted() bool {
+	return m.aead != nil
+}
+
+// seal encrypts a column value when a key is set. Without one it refuses
+// to write plain text into a database holding encrypted values.
+func (m *Memory) seal(plain string) (string, error) {
+	if m.aead == nil {
+		if m.sealedRows {
+			return "", ErrNoKey
+		}
+		return plain, nil
+	}
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := m.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a column value written by seal; plain values pass through.
+func (m *Memory) open(stored string) (string, error) {
+	body, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return stored, nil
+	}
+	if m.aead == nil {
+		return "", ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(body)
+	if err != nil || len(data) < m.aead.NonceSize() {
+		return "", fmt.Errorf("decrypting memory: malformed value")
+	}
+	n := m.aead.NonceSize()
+	plain, err := m.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting memory: wrong key or corrupted value")
+	}
+	return string(plain), nil
+}
+
+// Encrypt rewrites every plain-text value with the memory's key and
+// returns how many it encrypted. Values already encrypted are left alone.
+func (m *Memory) Encrypt() (int, error) {
+	if m.aead == nil {
+		return 0, errors.New("no encryption key is set")
+	}
+	n, err := m.rewrite(func(stored string) (string, bool, error) {
+		if strings.HasPrefix(stored, sealedPrefix) {
+			return "", false, nil
+		}
+		sealed, err := m.seal(stored)
+		return sealed, true, err
+	})
+	if n > 0 {
+		m.sealedRows = true
+	}
+	return n, err
+}
+
+// Decrypt rewrites every encrypted value as plain text and returns how
+// many it decrypted. It needs the key the values were encrypted with.
+func (m *Memory) Decrypt() (int, error) {
+	n, err := m.rewrite(func(stored string) (string, bool, error) {
+		if !strings.HasPrefix(stored, sealedPrefix) {
+			return "", false, nil
+		}
+		plain, err := m.open(stored)
+		return plain, true, err
+	})
+	if err == nil || n > 0 {
+		m.sealedRows = false
+	}
+	return n, err
+}
+
+// hasSealed reports whether any column value in the database is encrypted.
+func (m *Memory) hasSealed() (bool, error) {
+	for _, c := range sealedColumns {
+		var one int
+		err := m.db.QueryRow(fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIKE ? LIMIT 1", c.table, c.column), sealedPrefix+"%").Scan(&one)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// rewrite passes every sealed column value through fn in one transaction,
+// writing back those it changes, then compacts the file. Any error before
+// the commit rolls the whole migration back.
+func (m *Memory) rewrite(fn func(stored string) (next string, changed bool, err error)) (int, error) {
+	if m.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count := 0
+	for _, c := range sealedColumns {
+		rows, err := tx.Query(fmt.Sprintf("SELECT %s, %s FROM %s", c.id, c.column, c.table))
+		if err != nil {
+			return 0, err
+		}
+		type update struct {
+			id    interface{}
+			value string
+		}
+		var updates []update
+		for rows.Next() {
+			var id interface{}
+			var value sql.NullString
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			next, changed, err := fn(value.String)
+			if err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("%s %v: %w", c.table, id, err)
+			}
+			if changed {
+				updates = append(updates, update{id, next})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		for _, u := range updates {
+			// updated_at is left alone: the content did not change.
+			query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", c.table, c.column, c.id)
+			if _, err := tx.Exec(query, u.value, u.id); err != nil {
+				return 0, err
+			}
+		}
+		count += len(updates)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		if err := m.compact(); err != nil {
+			return count, fmt.Errorf("compacting memory after migration: %w", err)
+		}
+	}
+	return count, nil
+}
+
+// compact rebuilds the database file so the old form of rewritten values
+// does not linger in free pages or the write-ahead log.
+func (m *Memory) compact() error {
+	if _, err := m.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return err
+	}
+	if _, err := m.db.Exec("VACUUM"); err != nil {
+		return err
+	}
+	_, err := m.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return err
+}
@@ -0,0 +1,117 @@
+package context
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedMemory_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibe.db")
+	m, err := OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := GenerateKey()
+	if err := m.SetEncryptionKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Store("fact", "the launch code is Swordfish"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SaveState("chat_session", []string{"private chat"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ArchiveMessages("chat_session", []string{"old private chat"}); err != nil {
+		t.Fatal(err)
+	}
+	m.db.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"Swordfish", "private chat"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("%q stored in plain text", secret)
+		}
+	}
+
+	m, _ = OpenMemory(path)
+	var live []string
+	if err := m.LoadState("chat_session", &live); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected ErrNoKey without the key, got %v", err)
+	}
+	if err := m.Store("later", "written without the key"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected a plain write next to encrypted values to be refused, got %v", err)
+	}
+	m.SetEncryptionKey(key)
+	if err := m.LoadState("chat_session", &live); err != nil || len(live) != 1 || live[0] != "private chat" {
+		t.Fatalf("expected the state back, got %v (%v)", live, err)
+	}
+	if a, err := m.LatestArchive("chat_session"); err != nil || a.Messages[0] != "old private chat" {
+		t.Fatalf("expected the archive back, got %+v (%v)", a, err)
+	}
+	if got, err := m.RecallByKeyword("swordfish", 5); err != nil || len(got) != 1 || !strings.Contains(got[0], "Swordfish") {
+		t.Errorf("expected keyword recall to see through encryption, got %v (%v)", got, err)
+	}
+
+	other, _ := GenerateKey()
+	m.SetEncryptionKey(other)
+	if err := m.LoadState("chat_session", &live); err == nil {
+		t.Error("expected the wrong key to fail")
+	}
+}
+
+func TestEncryptedMemory_Migrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibe.db")
+	m, err := OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Store("fact", "plain fact")
+	m.SaveState("chat_session", []string{"plain chat"})
+	m.ArchiveMessages("chat_session", []string{"plain archive"})
+	// Large enough to spill into overflow pages, which are freed, not
+	// overwritten, when the row is rewritten.
+	m.Store("notes", strings.Repeat("secret notes ", 2000))
+
+	if _, err := m.Encrypt(); err == nil {
+		t.Error("expected Encrypt without a key to fail")
+	}
+	key, _ := GenerateKey()
+	m.SetEncryptionKey(key)
+	if n, err := m.Encrypt(); err != nil || n != 4 {
+		t.Fatalf("expected 4 values encrypted, got %d (%v)", n, err)
+	}
+	if n, err := m.Encrypt(); err != nil || n != 0 {
+		t.Errorf("expected a second Encrypt to do nothing, got %d (%v)", n, err)
+	}
+	var stored string
+	m.db.QueryRow("SELECT value FROM memory WHERE key = 'fact'").Scan(&stored)
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		t.Errorf("expected the value encrypted, got %q", stored)
+	}
+	// Nothing of the plain text may survive in freed pages or a journal.
+	for _, f := range []string{path, path + "-wal", path + "-journal"} {
+		if raw, err := os.ReadFile(f); err == nil && (bytes.Contains(raw, []byte("plain")) || bytes.Contains(raw, []byte("secret notes"))) {
+			t.Errorf("expected no plain text left in %s", filepath.Base(f))
+		}
+	}
+
+	if n, err := m.Decrypt(); err != nil || n != 4 {
+		t.Fatalf("expected 4 values decrypted, got %d (%v)", n, err)
+	}
+	m.SetEncryptionKey(nil)
+	if got, _ := m.RecallByKeyword("plain", 5); len(got) != 1 || got[0] != "plain fact" {
+		t.Errorf("expected the plain value back, got %v", got)
+	}
+	var live []string
+	if err := m.LoadState("chat_session", &live); err != nil || live[0] != "plain chat" {
+		t.Errorf("expected the plain state back, got %v (%v)", live, err)
+	}
+}
@@ -0,0 +1,277 @@
+package context
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Embedder turns texts into vectors, one per text, in order.
+type Embedder interface {
+	Embed(ctx gocontext.Context, texts []string) ([][]float32, error)
+}
+
+const (
+	// maxIndexed caps how many of the most recent memory rows are embedded.
+	maxIndexed = 1000
+	// maxEmbedChars truncates long values before embedding.
+	maxEmbedChars = 2000
+	// embedBatch is how many texts are sent per Embed call.
+	embedBatch = 64
+	// embedCooldown is how long semantic recall stays off after the
+	// embedder fails, so an absent model doesn't slow every prompt.
+	embedCooldown = time.Minute
+)
+
+// vectorIndex is the in-process store behind RecallSemantic. It is built
+// from the memory table on first use and kept up to date by Store.
+type vectorIndex struct {
+	mu        sync.Mutex
+	embedder  Embedder
+	threshold float64
+	topK      int
+
+	vectors map[string][]float32 // nil until the index is first loaded
+	values  map[string]string
+	pending map[string]string // Stored since the last search, not yet embedded
+	downAt  time.Time
+}
+
+// SetEmbedder enables semantic recall. Items scoring below threshold
+// (cosine similarity, -1..1) are never returned and at most topK are.
+// A nil embedder turns semantic recall off.
+func (m *Memory) SetEmbedder(e Embedder, threshold float64, topK int) {
+	if topK <= 0 {
+		topK = 5
+	}
+	m.index.mu.Lock()
+	defer m.index.mu.Unlock()
+	m.index.embedder = e
+	m.index.threshold = threshold
+	m.index.topK = topK
+	m.index.vectors = nil
+	m.index.values = nil
+	m.index.pending = nil
+	m.index.downAt = time.Time{}
+}
+
+// noteStored queues a stored value for embedding at the next search.
+func (ix *vectorIndex) noteStored(key, value string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.embedder == nil || ix.vectors == nil {
+		return
+	}
+	if ix.pending == nil {
+		ix.pending = make(map[string]string)
+	}
+	ix.pending[key] = value
+}
+
+// RecallSemantic returns up to topK long-term memory values most similar
+// in meaning to query. Without an embedder, or when it fails, it falls back
+// to RecallByKeyword.
+func (m *Memory) RecallSemantic(ctx gocontext.Context, query string) ([]string, error) {
+	results, err := m.searchVectors(ctx, query)
+	if err != nil {
+		m.index.mu.Lock()
+		topK := m.index.topK
+		m.index.mu.Unlock()
+		return m.RecallByKeyword(query, topK)
+	}
+	return results, nil
+}
+
+func (m *Memory) searchVectors(ctx gocontext.Context, query string) ([]string, error) {
+	ix := &m.index
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.embedder == nil {
+		return nil, fmt.Errorf("no embedder configured")
+	}
+	if !ix.downAt.IsZero() && time.Since(ix.downAt) < embedCooldown {
+		return nil, fmt.Errorf("embedder unavailable")
+	}
+	fail := func(err error) ([]string, error) {
+		ix.downAt = time.Now()
+		return nil, err
+	}
+
+	// Rows are embedded incrementally, so a build interrupted by a timeout
+	// picks up where it left off.
+	if ix.vectors == nil {
+		items, err := m.recentItems(maxIndexed)
+		if err != nil {
+			return nil, err
+		}
+		ix.vectors = make(map[string][]float32, len(items))
+		ix.values = make(map[string]string, len(items))
+		ix.pending = items
+	}
+	if len(ix.pending) > 0 {
+		if err := ix.embedPending(ctx); err != nil {
+			return fail(err)
+		}
+	}
+
+	qv, err := ix.embedder.Embed(ctx, []string{truncateForEmbed(query)})
+	if err != nil || len(qv) != 1 {
+		if err == nil {
+			err = fmt.Errorf("embedder returned %d vectors for 1 text", len(qv))
+		}
+		return fail(err)
+	}
+
+	type scored struct {
+		value string
+		score float64
+	}
+	var hits []scored
+	for key, v := range ix.vectors {
+		if s := cosine(qv[0], v); s >= ix.threshold {
+			hits = append(hits, scored{ix.values[key], s})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	if len(hits) > ix.topK {
+		hits = hits[:ix.topK]
+	}
+	results := make([]string, len(hits))
+	for i, h := range hits {
+		results[i] = h.value
+	}
+	return results, nil
+}
+
+// embedPending embeds queued values in batches. Values that were embedded
+// stay indexed even if a later batch fails.
+func (ix *vectorIndex) embedPending(ctx gocontext.Context) error {
+	keys := make([]string, 0, len(ix.pending))
+	for k := range ix.pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for start := 0; start < len(keys); start += embedBatch {
+		end := start + embedBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		texts := make([]string, 0, end-start)
+		for _, k := range keys[start:end] {
+			texts = append(texts, truncateForEmbed(ix.pending[k]))
+		}
+		vecs, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		if len(vecs) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(texts))
+		}
+		for i, k := range keys[start:end] {
+			ix.vectors[k] = vecs[i]
+			ix.values[k] = ix.pending[k]
+			delete(ix.pending, k)
+		}
+	}
+	return nil
+}
+
+// recentItems loads the newest limit rows of the memory table.
+func (m *Memory) recentItems(limit int) (map[string]string, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rows, err := m.db.Query("SELECT key, value FROM memory ORDER BY updated_at DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		if v, err = m.open(v); err != nil {
+			return nil, err
+		}
+		items[k] = v
+	}
+	return items, rows.Err()
+}
+
+func truncateForEmbed(s string) string {
+	if len(s) > maxEmbedChars {
+		return s[:maxEmbedChars]
+	}
+	return s
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return -1
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return -1
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// OllamaEmbedder embeds text with a local Ollama model such as
+// nomic-embed-text.
+type OllamaEmbedder struct {
+	Endpoint string
+	Model    string
+	Client   *http.Client
+}
+
+// NewOllamaEmbedder returns an embedder for the Ollama server at endpoint.
+func NewOllamaEmbedder(endpoint, model string) *OllamaEmbedder {
+	return &OllamaEmbedder{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Model:    model,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Embed calls Ollama's /api/embed.
+func (e *OllamaEmbedder) Embed(ctx gocontext.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", e.Endpoint+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama embed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama embed: %s", resp.Status)
+	}
+	var data struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding ollama embeddings: %w", err)
+	}
+	return data.Embeddings, nil
+}
@@ -0,0 +1,80 @@
+package context
+
+import (
+	gocontext "context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// topicEmbedder maps texts onto two axes by keyword, so "car" and
+// "automobile" land together without sharing a substring.
+type topicEmbedder struct {
+	calls int
+	err   error
+}
+
+func (e *topicEmbedder) Embed(ctx gocontext.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		t = strings.ToLower(t)
+		v := []float32{0.01, 0.01}
+		if strings.Contains(t, "car") || strings.Contains(t, "automobile") || strings.Contains(t, "vehicle") {
+			v[0] = 1
+		}
+		if strings.Contains(t, "bread") || strings.Contains(t, "bake") {
+			v[1] = 1
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func TestRecallSemantic(t *testing.T) {
+	m, err := OpenMemory(filepath.Join(t.TempDir(), "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Store("a", "the car needs new tyres")
+	m.Store("b", "bake bread at 220C")
+
+	e := &topicEmbedder{}
+	m.SetEmbedder(e, 0.8, 3)
+
+	got, err := m.RecallSemantic(gocontext.Background(), "automobile maintenance")
+	if err != nil || len(got) != 1 || got[0] != "the car needs new tyres" {
+		t.Fatalf("expected the car memory, got %v (%v)", got, err)
+	}
+
+	// Items stored after the index is built are picked up too.
+	m.Store("c", "the vehicle is parked outside")
+	got, _ = m.RecallSemantic(gocontext.Background(), "automobile")
+	if len(got) != 2 {
+		t.Fatalf("expected both vehicle memories, got %v", got)
+	}
+}
+
+func TestRecallSemantic_FallsBackToKeyword(t *testing.T) {
+	m, err := OpenMemory(filepath.Join(t.TempDir(), "vibe.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Store("a", "the car needs new tyres")
+
+	e := &topicEmbedder{err: errors.New("connection refused")}
+	m.SetEmbedder(e, 0.8, 3)
+	for i := 0; i < 2; i++ {
+		got, err := m.RecallSemantic(gocontext.Background(), "tyres")
+		if err != nil || len(got) != 1 {
+			t.Fatalf("expected the keyword match, got %v (%v)", got, err)
+		}
+	}
+	if e.calls != 1 {
+		t.Errorf("expected the failed embedder to be left alone during the cooldown, called %d times", e.calls)
+	}
+}
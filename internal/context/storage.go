@@ -0,0 +1,113 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RowUsage describes one persisted row for storage reporting and GC.
+type RowUsage struct {
+	Table     string    `json:"table"` // "app_state" or "archive"
+	ID        string    `json:"id"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StateUsage lists every app_state blob and archived message batch.
+func (m *Memory) StateUsage() ([]RowUsage, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rows, err := m.db.Query(`
+		SELECT 'app_state', id, length(data), updated_at FROM app_state
+		UNION ALL
+		SELECT 'archive', CAST(id AS TEXT), length(messages), created_at FROM archive`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RowUsage
+	for rows.Next() {
+		var r RowUsage
+		if err := rows.Scan(&r.Table, &r.ID, &r.Size, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// DeleteRow removes a row reported by StateUsage.
+func (m *Memory) DeleteRow(table, id string) error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	switch table {
+	case "app_state":
+		_, err := m.db.Exec("DELETE FROM app_state WHERE id = ?", id)
+		return err
+	case "archive":
+		_, err := m.db.Exec("DELETE FROM archive WHERE id = ?", id)
+		return err
+	}
+	return fmt.Errorf("unknown table %q", table)
+}
+
+// ListStates lists app_state rows whose id starts with prefix, most
+// recently updated first.
+func (m *Memory) ListStates(prefix string) ([]RowUsage, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rows, err := m.db.Query(`
+		SELECT 'app_state', id, length(data), updated_at FROM app_state
+		WHERE substr(id, 1, ?) = ?
+		ORDER BY updated_at DESC`, len(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RowUsage
+	for rows.Next() {
+		var r RowUsage
+		if err := rows.Scan(&r.Table, &r.ID, &r.Size, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// CheckIntegrity runs SQLite's integrity check on the database and returns
+// the problems it reports, if any.
+func (m *Memory) CheckIntegrity() error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	rows, err := m.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
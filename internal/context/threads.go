@@ -0,0 +1,147 @@
+package context
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ThreadRecord is one finished request of a chat session: the prompt, the
+// final answer and what the agent noted about it.
+type ThreadRecord struct {
+	SessionID string                 `json:"session_id"`
+	ThreadID  string                 `json:"thread_id"`
+	Prompt    string                 `json:"prompt"`
+	Response  string                 `json:"response"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ErrAmbiguousThread is returned by Thread when an id prefix matches more
+// than one thread.
+var ErrAmbiguousThread = errors.New("thread id prefix matches more than one thread")
+
+// SaveThread stores a thread, replacing any earlier copy with the same
+// thread id. A zero CreatedAt is stored as now.
+func (m *Memory) SaveThread(t ThreadRecord) error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	meta, err := json.Marshal(t.Metadata)
+	if err != nil {
+		return err
+	}
+	var sealed [3]string
+	for i, v := range []string{t.Prompt, t.Response, string(meta)} {
+		if sealed[i], err = m.seal(v); err != nil {
+			return err
+		}
+	}
+	_, err = m.db.Exec(`INSERT OR REPLACE INTO threads (thread_id, session_id, prompt, response, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, t.ThreadID, t.SessionID, sealed[0], sealed[1], sealed[2], t.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("saving thread: %w", err)
+	}
+	return nil
+}
+
+// RecentThreads returns a session's threads, newest first, at most limit
+// of them (all if limit is not positive).
+func (m *Memory) RecentThreads(sessionID string, limit int) ([]ThreadRecord, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	query := threadColumns + " WHERE session_id = ? ORDER BY created_at DESC, rowid DESC"
+	args := []interface{}{sessionID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ThreadRecord{} // Encodes as [] when there are none
+	for rows.Next() {
+		t, err := m.scanThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// Thread returns the thread whose id is, or starts with, id, from any
+// session.
+func (m *Memory) Thread(id string) (*ThreadRecord, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("no thread id given")
+	}
+	rows, err := m.db.Query(threadColumns+" WHERE thread_id = ? OR substr(thread_id, 1, ?) = ? ORDER BY thread_id = ? DESC LIMIT 2",
+		id, len(id), id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []ThreadRecord
+	for rows.Next() {
+		t, err := m.scanThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("no thread %q", id)
+	case len(found) > 1 && found[0].ThreadID != id:
+		return nil, fmt.Errorf("%w: %s", ErrAmbiguousThread, id)
+	}
+	return &found[0], nil
+}
+
+// DeleteThreads removes every thread of a session.
+func (m *Memory) DeleteThreads(sessionID string) error {
+	if m.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	_, err := m.db.Exec("DELETE FROM threads WHERE session_id = ?", sessionID)
+	return err
+}
+
+const threadColumns = "SELECT thread_id, session_id, prompt, response, metadata, created_at FROM threads"
+
+func (m *Memory) scanThread(rows *sql.Rows) (ThreadRecord, error) {
+	var t ThreadRecord
+	var prompt, response, meta string
+	if err := rows.Scan(&t.ThreadID, &t.SessionID, &prompt, &response, &meta, &t.CreatedAt); err != nil {
+		return t, err
+	}
+	var err error
+	if t.Prompt, err = m.open(prompt); err != nil {
+		return t, err
+	}
+	if t.Response, err = m.open(response); err != nil {
+		return t, err
+	}
+	if meta, err = m.open(meta); err != nil {
+		return t, err
+	}
+	json.Unmarshal([]byte(meta), &t.Metadata)
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package context
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThreads_SurviveReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibe.db")
+	m, err := OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := GenerateKey()
+	m.SetEncryptionKey(key)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"aa11", "aa22", "bb33"} {
+		err := m.SaveThread(ThreadRecord{SessionID: "proj", ThreadID: id, Prompt: "prompt " + id, Response: "answer " + id,
+			Metadata: map[string]interface{}{"tool_calls": i}, CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.SaveThread(ThreadRecord{SessionID: "other", ThreadID: "cc44", Prompt: "elsewhere"})
+
+	var stored string
+	m.db.QueryRow("SELECT response FROM threads WHERE thread_id = 'aa11'").Scan(&stored)
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		t.Errorf("expected the response encrypted, got %q", stored)
+	}
+
+	m, err = OpenMemory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetEncryptionKey(key)
+	recent, err := m.RecentThreads("proj", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 || recent[0].ThreadID != "bb33" || recent[1].ThreadID != "aa22" {
+		t.Fatalf("expected the two newest threads, newest first, got %+v", recent)
+	}
+	if recent[0].Response != "answer bb33" || recent[0].Metadata["tool_calls"] != float64(2) || !recent[0].CreatedAt.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("thread not restored intact: %+v", recent[0])
+	}
+
+	if th, err := m.Thread("bb"); err != nil || th.Prompt != "prompt bb33" {
+		t.Errorf("expected a unique prefix to find the thread, got %+v (%v)", th, err)
+	}
+	if _, err := m.Thread("aa"); !errors.Is(err, ErrAmbiguousThread) {
+		t.Errorf("expected an ambiguous prefix to be refused, got %v", err)
+	}
+	if _, err := m.Thread("zz"); err == nil {
+		t.Error("expected an unknown id to fail")
+	}
+
+	m.DeleteThreads("proj")
+	if left, _ := m.RecentThreads("proj", 0); len(left) != 0 {
+		t.Errorf("expected the session's threads deleted, got %d", len(left))
+	}
+	if left, _ := m.RecentThreads("other", 0); len(left) != 1 {
+		t.Errorf("expected other sessions untouched, got %d", len(left))
+	}
+}
@@ -1,16 +1,33 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 )
 
+// Job is periodic background work run by the daemon.
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
 // Daemon represents the background service
 type Daemon struct {
 	socketPath string
 	server     *grpc.Server
+
+	mu     sync.Mutex
+	jobs   []Job
+	cancel context.CancelFunc
+
+	// OnJobError receives failures from scheduled jobs (optional).
+	OnJobError func(job string, err error)
 }
 
 func New(socketPath string) *Daemon {
@@ -20,6 +37,13 @@ func New(socketPath string) *Daemon {
 	}
 }
 
+// Schedule registers a job to run every interval once the daemon starts.
+func (d *Daemon) Schedule(job Job) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.jobs = append(d.jobs, job)
+}
+
 // Start launches the background service
 func (d *Daemon) Start() error {
 	lis, err := net.Listen("unix", d.socketPath)
@@ -27,12 +51,39 @@ func (d *Daemon) Start() error {
 		return fmt.Errorf("listening on unix socket: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	d.mu.Lock()
+	d.cancel = cancel
+	for _, job := range d.jobs {
+		go d.runJob(ctx, job)
+	}
+	d.mu.Unlock()
+
 	fmt.Printf("Daemon starting on %s\n", d.socketPath)
 	return d.server.Serve(lis)
 }
 
+func (d *Daemon) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Every)
+	defer ticker.Stop()
+	for {
+		if err := job.Run(ctx); err != nil && d.OnJobError != nil {
+			d.OnJobError(job.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Stop shuts down the background service
 func (d *Daemon) Stop() {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.mu.Unlock()
 	d.server.GracefulStop()
 }
-
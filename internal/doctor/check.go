@@ -0,0 +1,23 @@
+package doctor
+
+// CheckStatus is the outcome of one self-diagnosis check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// Check is the result of one self-diagnosis check, as run by
+// `vibeaura doctor check`.
+type Check struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// Pass, Warn and Fail build a Check with that status.
+func Pass(name, detail string) Check { return Check{Name: name, Status: CheckPass, Detail: detail} }
+func Warn(name, detail string) Check { return Check{Name: name, Status: CheckWarn, Detail: detail} }
+func Fail(name, detail string) Check { return Check{Name: name, Status: CheckFail, Detail: detail} }
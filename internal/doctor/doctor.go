@@ -28,6 +28,7 @@ type SignalType string
 
 const (
 	SignalHeartbeat SignalType = "heartbeat"
+	SignalInfo      SignalType = "info"
 	SignalWarning   SignalType = "warning"
 	SignalError     SignalType = "error"
 	SignalPanic     SignalType = "panic"
@@ -50,9 +51,18 @@ var (
 	logCache []Cue
 )
 
-// Start begins the monitoring loop
+// OnCue is invoked for every cue at warning level or above (injected by main).
+var OnCue func(Cue)
+
+// BeforeCrash runs when Recover catches a panic, before the crash report is
+// printed; the TUI uses it to give the terminal back (injected by main).
+var BeforeCrash func()
+
+var startOnce sync.Once
+
+// Start begins the monitoring loop. Calling it again does nothing.
 func Start() {
-	go monitor()
+	startOnce.Do(func() { go monitor() })
 }
 
 func monitor() {
@@ -71,25 +81,42 @@ func monitor() {
 
 // Send emits a cue to the doctor
 func Send(source string, typ SignalType, msg string, extra any) {
-	select {
-	case cues <- Cue{
+	cue := Cue{
 		Source:    source,
 		Type:      typ,
 		Message:   msg,
 		Timestamp: time.Now(),
 		Extra:     extra,
-	}:
+	}
+	if OnCue != nil && typ.Severe() {
+		OnCue(cue)
+	}
+	select {
+	case cues <- cue:
 	default:
 		// Don't block if doctor is overwhelmed (potentially dying anyway)
 	}
 }
 
+// Severe reports whether a signal is at warning level or above.
+func (s SignalType) Severe() bool {
+	switch s {
+	case SignalWarning, SignalError, SignalPanic, SignalCrash:
+		return true
+	}
+	return false
+}
+
 // Recover is a top-level deferred function to catch panics and save crash state
 func Recover() {
 	if r := recover(); r != nil {
 		stack := string(debug.Stack())
 		err := fmt.Errorf("panic: %v", r)
 
+		if BeforeCrash != nil {
+			BeforeCrash()
+		}
+
 		fmt.Println("\n\033[31m!!! CRITICAL SYSTEM FAILURE DETECTED !!!\033[0m")
 		fmt.Printf("Analyzing trauma: %v\n", err)
 
@@ -119,7 +146,7 @@ func Recover() {
 // LogCrash writes a crash report to AppData
 func LogCrash(err error, stack string) (string, error) {
 	cm, _ := sys.NewConfigManager()
-	base := cm.GetDataPath("crash_logs")
+	base := CrashDir()
 	_ = os.MkdirAll(base, 0755)
 
 	filename := fmt.Sprintf("crash_%s.json", time.Now().Format("20060102_150405"))
@@ -129,7 +156,7 @@ func LogCrash(err error, stack string) (string, error) {
 		"error":     err.Error(),
 		"stack":     stack,
 		"timestamp": time.Now(),
-		"cues":      logCache, // Include recent context
+		"cues":      Recent(0), // Include recent context
 		"health":    AnalyzeHealth(),
 	}
 
@@ -0,0 +1,165 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// String names a health score for display.
+func (h HealthScore) String() string {
+	switch h {
+	case HealthGood:
+		return "good"
+	case HealthDegraded:
+		return "degraded"
+	case HealthCritical:
+		return "critical"
+	case HealthCatastrophic:
+		return "catastrophic"
+	}
+	return "unknown"
+}
+
+// CrashReport is a crash log as written by LogCrash.
+type CrashReport struct {
+	Error     string      `json:"error"`
+	Stack     string      `json:"stack"`
+	Timestamp time.Time   `json:"timestamp"`
+	Cues      []Cue       `json:"cues"`
+	Health    HealthScore `json:"health"`
+}
+
+// CrashLog is a crash report file on disk.
+type CrashLog struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Summary is the doctor's view of the installation: health, crash history
+// and what modules reported recently.
+type Summary struct {
+	Health     string      `json:"health"`
+	Score      HealthScore `json:"score"`
+	CrashCount int         `json:"crash_count"`
+	LastCrash  time.Time   `json:"last_crash,omitempty"`
+	Cues       []Cue       `json:"cues"`
+	CrashLogs  []CrashLog  `json:"crash_logs"`
+}
+
+// Recent returns up to n of the latest cues, oldest first.
+func Recent(n int) []Cue {
+	mu.Lock()
+	defer mu.Unlock()
+	if n <= 0 || n > len(logCache) {
+		n = len(logCache)
+	}
+	return append([]Cue(nil), logCache[len(logCache)-n:]...)
+}
+
+// Summarize gathers the health score, crash counters, the last n cues and
+// the crash logs on disk.
+func Summarize(n int) Summary {
+	score := AnalyzeHealth() // Decays a stale crash count, so read it after
+	s := Summary{Health: score.String(), Score: score, Cues: Recent(n)}
+	if cm, err := sys.NewConfigManager(); err == nil {
+		if cfg, err := cm.Load(); err == nil {
+			s.CrashCount = cfg.Health.CrashCount
+			s.LastCrash = cfg.Health.LastCrash
+		}
+	}
+	s.CrashLogs, _ = CrashLogs()
+	return s
+}
+
+// CrashDir is where crash reports are written.
+func CrashDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".vibeauracle", "crash_logs")
+}
+
+// CrashLogs lists the crash reports on disk, newest first.
+func CrashLogs() ([]CrashLog, error) {
+	return listCrashLogs(CrashDir())
+}
+
+func listCrashLogs(dir string) ([]CrashLog, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var logs []CrashLog
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, CrashLog{
+			Name:    e.Name(),
+			Path:    filepath.Join(dir, e.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].ModTime.After(logs[j].ModTime) })
+	return logs, nil
+}
+
+// ReadCrashReport loads a crash report. A bare file name is looked up in
+// CrashDir.
+func ReadCrashReport(path string) (*CrashReport, error) {
+	if filepath.Base(path) == path {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join(CrashDir(), path)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r CrashReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s is not a crash report: %w", path, err)
+	}
+	return &r, nil
+}
+
+// CleanCrashLogs deletes crash reports older than age and returns how
+// many it removed and the bytes freed.
+func CleanCrashLogs(age time.Duration) (int, int64, error) {
+	return cleanCrashLogs(CrashDir(), time.Now().Add(-age))
+}
+
+func cleanCrashLogs(dir string, before time.Time) (int, int64, error) {
+	logs, err := listCrashLogs(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	var removed int
+	var freed int64
+	for _, l := range logs {
+		if !l.ModTime.Before(before) {
+			continue
+		}
+		if err := os.Remove(l.Path); err != nil {
+			return removed, freed, err
+		}
+		removed++
+		freed += l.Size
+	}
+	return removed, freed, nil
+}
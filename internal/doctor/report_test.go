@@ -0,0 +1,67 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCrash(t *testing.T, dir, name string, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(`{"error":"panic: boom","stack":"main.go:1","health":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Now().Add(-age)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCrashLogs_ListAndClean(t *testing.T) {
+	dir := t.TempDir()
+	writeCrash(t, dir, "crash_old.json", 40*24*time.Hour)
+	recent := writeCrash(t, dir, "crash_new.json", time.Hour)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+
+	logs, err := listCrashLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Name != "crash_new.json" {
+		t.Fatalf("expected two reports, newest first, got %+v", logs)
+	}
+
+	removed, freed, err := cleanCrashLogs(dir, time.Now().Add(-30*24*time.Hour))
+	if err != nil || removed != 1 || freed != logs[1].Size {
+		t.Fatalf("expected the old report removed, got %d (%d bytes, %v)", removed, freed, err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected the recent report kept: %v", err)
+	}
+
+	r, err := ReadCrashReport(recent)
+	if err != nil || r.Error != "panic: boom" || r.Health != HealthDegraded {
+		t.Errorf("unexpected report %+v (%v)", r, err)
+	}
+	if _, err := ReadCrashReport(filepath.Join(dir, "notes.txt")); err == nil {
+		t.Error("expected a non-JSON file to be rejected")
+	}
+}
+
+func TestRecent(t *testing.T) {
+	mu.Lock()
+	saved := logCache
+	logCache = []Cue{{Source: "a"}, {Source: "b"}, {Source: "c"}}
+	mu.Unlock()
+	defer func() { mu.Lock(); logCache = saved; mu.Unlock() }()
+
+	if got := Recent(2); len(got) != 2 || got[0].Source != "b" || got[1].Source != "c" {
+		t.Errorf("expected the last two cues, got %+v", got)
+	}
+	if got := Recent(0); len(got) != 3 {
+		t.Errorf("expected every cue, got %d", len(got))
+	}
+}
@@ -0,0 +1,340 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	Register("anthropic", func(config map[string]string) (Provider, error) {
+		return NewAnthropicProvider(config["api_key"], config["model"], config["base_url"])
+	})
+}
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com"
+	// anthropicVersion is the Messages API version sent on every request.
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens is sent as max_tokens, which the Messages API
+	// requires on every request.
+	anthropicMaxTokens = 4096
+)
+
+// anthropicAliases maps undated family names, which the API rejects, to the
+// matching "-latest" alias.
+var anthropicAliases = map[string]string{
+	"claude-3-5-sonnet": "claude-3-5-sonnet-latest",
+	"claude-3-5-haiku":  "claude-3-5-haiku-latest",
+	"claude-3-7-sonnet": "claude-3-7-sonnet-latest",
+	"claude-3-opus":     "claude-3-opus-latest",
+}
+
+// anthropicKnownModels is listed when the endpoint has no /v1/models, as
+// with older gateways and some Messages-compatible proxies.
+var anthropicKnownModels = []string{
+	"claude-opus-4-1",
+	"claude-opus-4-0",
+	"claude-sonnet-4-5",
+	"claude-sonnet-4-0",
+	"claude-haiku-4-5",
+	"claude-3-7-sonnet-latest",
+	"claude-3-5-sonnet-latest",
+	"claude-3-5-haiku-latest",
+}
+
+// AnthropicProvider implements the Provider interface for Anthropic's
+// Messages API.
+type AnthropicProvider struct {
+	apiKey    string
+	model     string
+	baseURL   string
+	maxTokens int
+	client    *http.Client
+}
+
+func (p *AnthropicProvider) Name() string { return "anthropic" }
+
+// NewAnthropicProvider creates a new Anthropic provider
+func NewAnthropicProvider(apiKey string, modelName string, baseURL string) (*AnthropicProvider, error) {
+	if modelName == "" {
+		modelName = "claude-3-5-sonnet-latest"
+	}
+	if alias, ok := anthropicAliases[modelName]; ok {
+		modelName = alias
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if baseURL == "" {
+		baseURL = anthropicBaseURL
+	}
+	// Accept an endpoint given with the version segment.
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+
+	return &AnthropicProvider{
+		apiKey:    apiKey,
+		model:     modelName,
+		baseURL:   baseURL,
+		maxTokens: anthropicMaxTokens,
+		client:    http.DefaultClient,
+	}, nil
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+func (p *AnthropicProvider) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// anthropicConversation splits messages into the Messages API's system
+// field and turns. Consecutive turns with the same role are merged, since
+// the API expects user and assistant to alternate.
+func anthropicConversation(messages []Message) (string, []anthropicMessage) {
+	var system []string
+	var turns []anthropicMessage
+	for _, m := range messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		role := "user"
+		if m.Role == RoleAssistant {
+			role = "assistant"
+		}
+		if n := len(turns); n > 0 && turns[n-1].Role == role {
+			turns[n-1].Content += "\n\n" + m.Content
+			continue
+		}
+		turns = append(turns, anthropicMessage{Role: role, Content: m.Content})
+	}
+	return strings.Join(system, "\n\n"), turns
+}
+
+func (p *AnthropicProvider) send(ctx context.Context, messages []Message, stream bool) (*http.Response, error) {
+	system, turns := anthropicConversation(messages)
+	req, err := p.newRequest(ctx, "POST", "/v1/messages", anthropicRequest{
+		Model:     p.model,
+		MaxTokens: p.maxTokens,
+		System:    system,
+		Messages:  turns,
+		Stream:    stream,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic generate: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, anthropicError("anthropic generate", resp)
+	}
+	return resp, nil
+}
+
+// anthropicError is statusError with the API's own error message appended
+// when the body carries one.
+func anthropicError(what string, resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body) == nil && body.Error.Message != "" {
+		what += " (" + body.Error.Message + ")"
+	}
+	return statusError(what, resp)
+}
+
+// Generate sends a prompt to Anthropic and returns the response
+func (p *AnthropicProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	return p.GenerateChat(ctx, []Message{{Role: RoleUser, Content: prompt}})
+}
+
+// GenerateChat sends a conversation, with system messages in the system
+// field.
+func (p *AnthropicProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	resp, err := p.send(ctx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage anthropicUsage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", fmt.Errorf("decoding anthropic response: %w", err)
+	}
+	reportTokens(ctx, data.Usage.InputTokens, data.Usage.OutputTokens)
+
+	var sb strings.Builder
+	for _, block := range data.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String(), nil
+}
+
+// anthropicUsage is the token usage in a response or stream event.
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// GenerateStream sends a prompt to Anthropic and emits text deltas from the
+// server-sent event stream as they arrive.
+func (p *AnthropicProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return p.GenerateChatStream(ctx, []Message{{Role: RoleUser, Content: prompt}}, out)
+}
+
+// GenerateChatStream is GenerateChat with text deltas sent to out as they
+// arrive.
+func (p *AnthropicProvider) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	resp, err := p.send(ctx, messages, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Message struct {
+				Usage anthropicUsage `json:"usage"`
+			} `json:"message"`
+			Usage anthropicUsage `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case "message_start":
+			reportTokens(ctx, event.Message.Usage.InputTokens, 0)
+		case "message_delta":
+			// Carries the output token count of the whole message.
+			reportTokens(ctx, 0, event.Usage.OutputTokens)
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" {
+				continue
+			}
+			sb.WriteString(event.Delta.Text)
+			if err := sendChunk(ctx, out, event.Delta.Text); err != nil {
+				return sb.String(), err
+			}
+		case "error":
+			return sb.String(), fmt.Errorf("anthropic generate: %s", event.Error.Message)
+		case "message_stop":
+			return sb.String(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sb.String(), fmt.Errorf("anthropic generate: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// GenerateWithTools returns ErrToolsUnsupported; tool calls are parsed
+// from the text instead.
+func (p *AnthropicProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	return NoNativeTools(ctx, messages, tools)
+}
+
+// ListModels returns the chat models available to the API key
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]string, error) {
+	req, err := p.newRequest(ctx, "GET", "/v1/models?limit=1000", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching anthropic models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("anthropic api key is invalid or expired: %w", ErrUnauthorized)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return append([]string(nil), anthropicKnownModels...), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, anthropicError("anthropic models list failed", resp)
+	}
+
+	var data struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding anthropic models: %w", err)
+	}
+
+	// Every Claude model speaks the Messages API; anything else the
+	// endpoint lists (or a compatible proxy adds) is left out.
+	var models []string
+	for _, m := range data.Data {
+		if m.Type != "" && m.Type != "model" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(m.ID), "claude") {
+			models = append(models, m.ID)
+		}
+	}
+	return models, nil
+}
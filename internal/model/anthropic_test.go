@@ -0,0 +1,152 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newAnthropicServer fakes the Messages API, rejecting requests without the
+// key and version headers.
+func newAnthropicServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, body anthropicRequest)) *AnthropicProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-test" || r.Header.Get("anthropic-version") != anthropicVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		var body anthropicRequest
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+		handle(w, r, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := GetProvider("anthropic", map[string]string{"api_key": "sk-ant-test", "model": "claude-3-5-sonnet", "base_url": srv.URL})
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	return p.(*AnthropicProvider)
+}
+
+func TestAnthropicGenerate(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		if r.URL.Path != "/v1/messages" || body.MaxTokens != anthropicMaxTokens || body.Model != "claude-3-5-sonnet-latest" || body.Stream {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, body)
+		}
+		if len(body.Messages) != 1 || body.Messages[0].Role != "user" || body.Messages[0].Content != "hi" {
+			t.Errorf("unexpected messages %+v", body.Messages)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"hello "},{"type":"tool_use"},{"type":"text","text":"there"}]}`))
+	})
+
+	got, err := p.Generate(context.Background(), "hi")
+	if err != nil || got != "hello there" {
+		t.Fatalf("expected %q, got %q (%v)", "hello there", got, err)
+	}
+}
+
+func TestAnthropicGenerateStream(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		if !body.Stream {
+			t.Errorf("expected a streaming request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"type":"message_start"}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"hel"}}`,
+			`{"type":"content_block_delta","delta":{"type":"input_json_delta","partial_json":"{"}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"lo"}}`,
+			`{"type":"message_stop"}`,
+		} {
+			var typ struct{ Type string }
+			json.Unmarshal([]byte(ev), &typ)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typ.Type, ev)
+		}
+	})
+
+	var chunks []string
+	resp, err := New(p).Stream(context.Background(), "hi", func(s string) { chunks = append(chunks, s) })
+	if err != nil || resp != "hello" || strings.Join(chunks, "|") != "hel|lo" {
+		t.Fatalf("expected chunks hel|lo, got %q %q (%v)", resp, chunks, err)
+	}
+}
+
+func TestAnthropicErrors(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	})
+	_, err := p.Generate(context.Background(), "hi")
+	if !errors.Is(err, ErrRateLimited) || !strings.Contains(err.Error(), "slow down") {
+		t.Errorf("expected a rate limit error with the API message, got %v", err)
+	}
+
+	p.apiKey = "wrong"
+	if _, err := p.ListModels(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized for a bad key, got %v", err)
+	}
+}
+
+func TestAnthropicListModels(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-5","type":"model"},{"id":"text-embedding-x","type":"model"}]}`))
+	})
+	models, err := p.ListModels(context.Background())
+	if err != nil || len(models) != 1 || models[0] != "claude-sonnet-4-5" {
+		t.Errorf("expected only the claude model, got %v (%v)", models, err)
+	}
+}
+
+func TestAnthropicListModels_KnownListWithoutEndpoint(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		http.NotFound(w, r)
+	})
+	models, err := p.ListModels(context.Background())
+	if err != nil || len(models) != len(anthropicKnownModels) {
+		t.Fatalf("expected the built-in model list, got %v (%v)", models, err)
+	}
+	for _, m := range models {
+		if !strings.HasPrefix(m, "claude-") {
+			t.Errorf("unexpected model %q", m)
+		}
+	}
+}
+
+func TestAnthropicGenerateChat(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		if body.System != "be brief" {
+			t.Errorf("expected the system message in the system field, got %q", body.System)
+		}
+		want := []anthropicMessage{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+			{Role: "user", Content: "Tool Output: ok\n\nand now?"},
+		}
+		if fmt.Sprint(body.Messages) != fmt.Sprint(want) {
+			t.Errorf("expected alternating turns %+v, got %+v", want, body.Messages)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"done"}]}`))
+	})
+
+	got, err := p.GenerateChat(context.Background(), []Message{
+		{Role: RoleSystem, Content: "be brief"},
+		{Role: RoleUser, Content: "hi"},
+		{Role: RoleAssistant, Content: "hello"},
+		{Role: RoleUser, Content: "Tool Output: ok"},
+		{Role: RoleUser, Content: "and now?"},
+	})
+	if err != nil || got != "done" {
+		t.Fatalf("expected %q, got %q (%v)", "done", got, err)
+	}
+}
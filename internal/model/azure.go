@@ -0,0 +1,188 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI inference API version used
+// when none is configured.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// azureDeploymentsAPIVersion is the last data-plane API version that lists
+// deployments with an API key; newer versions moved listing to the Azure
+// management API, which needs an Entra ID token instead.
+const azureDeploymentsAPIVersion = "2022-12-01"
+
+func init() {
+	Register("azure-openai", func(config map[string]string) (Provider, error) {
+		deployment := config["deployment_name"]
+		if deployment == "" {
+			deployment = config["model"]
+		}
+		var deployments []string
+		for _, d := range strings.Split(config["deployments"], ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				deployments = append(deployments, d)
+			}
+		}
+		return NewAzureProvider(config["endpoint"], config["api_key"], deployment, config["api_version"], deployments)
+	})
+}
+
+// AzureProvider implements the Provider interface for Azure OpenAI. Azure
+// serves each model from a named deployment on the resource's own
+// endpoint, so requests go to deployments/<name>/chat/completions.
+type AzureProvider struct {
+	llm         llms.Model
+	endpoint    string
+	apiKey      string
+	deployment  string
+	deployments []string // Configured list returned by ListModels
+}
+
+func (p *AzureProvider) Name() string { return "azure-openai" }
+
+// NewAzureProvider creates a provider for deployment on the resource at
+// endpoint (https://<resource>.openai.azure.com). deployments, if given,
+// is what ListModels returns instead of asking Azure.
+func NewAzureProvider(endpoint, apiKey, deployment, apiVersion string, deployments []string) (*AzureProvider, error) {
+	endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		return nil, fmt.Errorf("azure openai init: endpoint is required (https://<resource>.openai.azure.com)")
+	}
+	if deployment == "" && len(deployments) > 0 {
+		deployment = deployments[0]
+	}
+	if deployment == "" {
+		return nil, fmt.Errorf("azure openai init: deployment_name is required")
+	}
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+
+	llm, err := openai.New(
+		openai.WithAPIType(openai.APITypeAzure),
+		openai.WithToken(apiKey),
+		openai.WithBaseURL(endpoint),
+		openai.WithModel(deployment),
+		openai.WithAPIVersion(apiVersion),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("azure openai init: %w", err)
+	}
+
+	return &AzureProvider{
+		llm:         llm,
+		endpoint:    endpoint,
+		apiKey:      apiKey,
+		deployment:  deployment,
+		deployments: deployments,
+	}, nil
+}
+
+// Generate sends a prompt to the deployment and returns the response
+func (p *AzureProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := llms.GenerateFromSinglePrompt(ctx, p.llm, prompt)
+	if err != nil {
+		return "", fmt.Errorf("azure openai generate: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GenerateStream sends a prompt to the deployment and emits tokens as they are generated
+func (p *AzureProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	resp, err := llms.GenerateFromSinglePrompt(ctx, p.llm, prompt, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		return sendChunk(ctx, out, string(chunk))
+	}))
+	if err != nil {
+		return resp, fmt.Errorf("azure openai generate: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GenerateChat sends a conversation, keeping each message's role.
+func (p *AzureProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, nil)
+	if err != nil {
+		return "", fmt.Errorf("azure openai generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateChatStream is GenerateChat with tokens sent to out as they are
+// generated.
+func (p *AzureProvider) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, out)
+	if err != nil {
+		return resp, fmt.Errorf("azure openai generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateWithTools sends a conversation with tools attached as OpenAI functions
+// and returns the text and any tool calls in the reply.
+func (p *AzureProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	resp, err := generateWithLangchainTools(ctx, p.llm, messages, tools)
+	if err != nil {
+		return nil, fmt.Errorf("azure openai generate: %w", err)
+	}
+	return resp, nil
+}
+
+// ListModels returns the resource's deployment names: the configured list
+// if there is one, otherwise the deployments Azure reports. A resource
+// that no longer lists deployments by API key yields the configured
+// deployment alone.
+func (p *AzureProvider) ListModels(ctx context.Context) ([]string, error) {
+	if len(p.deployments) > 0 {
+		return append([]string(nil), p.deployments...), nil
+	}
+
+	url := p.endpoint + "/openai/deployments?api-version=" + azureDeploymentsAPIVersion
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("api-key", p.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching azure openai deployments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return []string{p.deployment}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("azure openai deployments list failed", resp)
+	}
+
+	var data struct {
+		Data []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding azure openai deployments: %w", err)
+	}
+
+	var models []string
+	for _, d := range data.Data {
+		if d.Status != "" && d.Status != "succeeded" {
+			continue // Still creating, or failed
+		}
+		models = append(models, d.ID)
+	}
+	return models, nil
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newAzureServer fakes an Azure OpenAI resource, rejecting requests
+// without the api-key header.
+func newAzureServer(t *testing.T, handle http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("api-key") != "az-test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestAzureGenerate_DeploymentPath(t *testing.T) {
+	endpoint := newAzureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/gpt4o-prod/chat/completions" || r.URL.Query().Get("api-version") != "2024-06-01" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`))
+	})
+
+	p, err := GetProvider("azure-openai", map[string]string{
+		"endpoint":        endpoint + "/",
+		"api_key":         "az-test-key",
+		"deployment_name": "gpt4o-prod",
+		"api_version":     "2024-06-01",
+		"model":           "ignored",
+	})
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	got, err := p.Generate(context.Background(), "hi")
+	if err != nil || got != "hello" {
+		t.Fatalf("Generate = %q, %v", got, err)
+	}
+}
+
+func TestAzureListModels(t *testing.T) {
+	endpoint := newAzureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"data":[{"id":"gpt4o-prod","status":"succeeded"},{"id":"new-one","status":"creating"},{"id":"embed"}]}`))
+	})
+
+	p, err := NewAzureProvider(endpoint, "az-test-key", "gpt4o-prod", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.ListModels(context.Background())
+	if err != nil || !reflect.DeepEqual(got, []string{"gpt4o-prod", "embed"}) {
+		t.Errorf("ListModels = %v, %v", got, err)
+	}
+
+	// A configured list is returned without asking Azure.
+	configured, _ := GetProvider("azure-openai", map[string]string{"endpoint": "http://127.0.0.1:1", "api_key": "k", "deployments": "a, b"})
+	got, err = configured.ListModels(context.Background())
+	if err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("configured ListModels = %v, %v", got, err)
+	}
+}
+
+func TestAzureProvider_RequiresEndpointAndDeployment(t *testing.T) {
+	if _, err := NewAzureProvider("", "k", "d", "", nil); err == nil {
+		t.Error("expected an error without an endpoint")
+	}
+	if _, err := NewAzureProvider("https://x.openai.azure.com", "k", "", "", nil); err == nil {
+		t.Error("expected an error without a deployment")
+	}
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+const (
+	// ResponseCacheFile is the cache database's name in the data dir.
+	ResponseCacheFile = "response_cache.db"
+	// DefaultCacheEntries bounds a ResponseCache opened without a limit.
+	DefaultCacheEntries = 1000
+)
+
+// ResponseCache stores model responses in SQLite, keyed by a hash of the
+// request, for reuse while younger than its TTL. The oldest entries are
+// dropped once it holds more than its limit.
+type ResponseCache struct {
+	db   *sql.DB
+	path string
+	ttl  time.Duration
+	max  int
+}
+
+// CacheStats describes a ResponseCache.
+type CacheStats struct {
+	Entries int
+	Bytes   int64 // Size of the database file
+}
+
+// OpenResponseCache opens (or creates) the cache database at path.
+func OpenResponseCache(path string, ttl time.Duration, maxEntries int) (*ResponseCache, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS responses (
+		key TEXT PRIMARY KEY,
+		value TEXT,
+		created_at INTEGER
+	)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing response cache: %w", err)
+	}
+	return &ResponseCache{db: db, path: path, ttl: ttl, max: maxEntries}, nil
+}
+
+// SetTTL changes how long entries stay fresh.
+func (c *ResponseCache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// Close closes the database.
+func (c *ResponseCache) Close() error {
+	return c.db.Close()
+}
+
+func (c *ResponseCache) get(key string) (string, bool) {
+	var value string
+	cutoff := time.Now().Add(-c.ttl).UnixNano()
+	err := c.db.QueryRow(`SELECT value FROM responses WHERE key = ? AND created_at > ?`, key, cutoff).Scan(&value)
+	return value, err == nil
+}
+
+func (c *ResponseCache) put(key, value string) error {
+	if _, err := c.db.Exec(`INSERT OR REPLACE INTO responses (key, value, created_at) VALUES (?, ?, ?)`, key, value, time.Now().UnixNano()); err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-c.ttl).UnixNano()
+	_, err := c.db.Exec(`DELETE FROM responses WHERE created_at <= ? OR key NOT IN (
+		SELECT key FROM responses ORDER BY created_at DESC LIMIT ?
+	)`, cutoff, c.max)
+	return err
+}
+
+// Stats reports the number of entries and the database size.
+func (c *ResponseCache) Stats() (CacheStats, error) {
+	var s CacheStats
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM responses`).Scan(&s.Entries); err != nil {
+		return s, err
+	}
+	if info, err := os.Stat(c.path); err == nil {
+		s.Bytes = info.Size()
+	}
+	return s, nil
+}
+
+// Clear removes every entry, returning how many there were.
+func (c *ResponseCache) Clear() (int, error) {
+	res, err := c.db.Exec(`DELETE FROM responses`)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	_, err = c.db.Exec(`VACUUM`)
+	return int(n), err
+}
+
+// CachingModel is a Provider that answers repeated identical requests from
+// a ResponseCache. Streams are cached too; a hit is sent as one chunk.
+type CachingModel struct {
+	provider Provider
+	cache    *ResponseCache
+	model    string
+	// OnHit, if set, is called whenever a response comes from the cache.
+	OnHit func()
+}
+
+// NewCachingModel wraps p, keying entries by p's name, the model name and
+// the request.
+func NewCachingModel(p Provider, cache *ResponseCache, modelName string) *CachingModel {
+	return &CachingModel{provider: p, cache: cache, model: modelName}
+}
+
+// key hashes the provider, model, kind of call and request.
+func (c *CachingModel) key(kind string, request interface{}) string {
+	data, _ := json.Marshal(request)
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", c.provider.Name(), c.model, kind)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *CachingModel) lookup(key string) (string, bool) {
+	value, ok := c.cache.get(key)
+	if ok && c.OnHit != nil {
+		c.OnHit()
+	}
+	return value, ok
+}
+
+// cached returns the cached response for key, or calls generate and caches
+// a successful, non-empty result.
+func (c *CachingModel) cached(key string, generate func() (string, error)) (string, error) {
+	if value, ok := c.lookup(key); ok {
+		return value, nil
+	}
+	value, err := generate()
+	if err == nil && value != "" {
+		c.cache.put(key, value)
+	}
+	return value, err
+}
+
+// Name is the wrapped provider's name.
+func (c *CachingModel) Name() string {
+	return c.provider.Name()
+}
+
+func (c *CachingModel) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.cached(c.key("generate", prompt), func() (string, error) {
+		return c.provider.Generate(ctx, prompt)
+	})
+}
+
+func (c *CachingModel) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	key := c.key("generate", prompt)
+	if value, ok := c.lookup(key); ok {
+		sendChunk(ctx, out, value)
+		return value, nil
+	}
+	value, err := c.provider.GenerateStream(ctx, prompt, out)
+	if err == nil && value != "" {
+		c.cache.put(key, value)
+	}
+	return value, err
+}
+
+func (c *CachingModel) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return c.cached(c.key("chat", messages), func() (string, error) {
+		return c.provider.GenerateChat(ctx, messages)
+	})
+}
+
+// GenerateChatStream streams the conversation, flattening it for providers
+// that cannot stream one.
+func (c *CachingModel) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	key := c.key("chat", messages)
+	if value, ok := c.lookup(key); ok {
+		sendChunk(ctx, out, value)
+		return value, nil
+	}
+	var value string
+	var err error
+	if cs, ok := c.provider.(ChatStreamer); ok {
+		value, err = cs.GenerateChatStream(ctx, messages, out)
+	} else {
+		value, err = c.provider.GenerateStream(ctx, FlattenMessages(messages), out)
+	}
+	if err == nil && value != "" {
+		c.cache.put(key, value)
+	}
+	return value, err
+}
+
+func (c *CachingModel) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	key := c.key("tools", struct {
+		Messages []Message
+		Tools    []ToolMetadata
+	}{messages, tools})
+	if value, ok := c.lookup(key); ok {
+		var resp ToolResponse
+		if json.Unmarshal([]byte(value), &resp) == nil {
+			return &resp, nil
+		}
+	}
+	resp, err := c.provider.GenerateWithTools(ctx, messages, tools)
+	if err == nil && resp != nil {
+		if data, mErr := json.Marshal(resp); mErr == nil {
+			c.cache.put(key, string(data))
+		}
+	}
+	return resp, err
+}
+
+// ListModels is never cached.
+func (c *CachingModel) ListModels(ctx context.Context) ([]string, error) {
+	return c.provider.ListModels(ctx)
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	MockProvider
+	calls int
+}
+
+func (c *countingProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	return c.Response, c.Err
+}
+
+func openTestCache(t *testing.T, ttl time.Duration, max int) *ResponseCache {
+	t.Helper()
+	c, err := OpenResponseCache(filepath.Join(t.TempDir(), ResponseCacheFile), ttl, max)
+	if err != nil {
+		t.Fatalf("OpenResponseCache: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCachingModel_ReusesIdenticalPrompt(t *testing.T) {
+	p := &countingProvider{MockProvider: MockProvider{Response: "answer"}}
+	cm := NewCachingModel(p, openTestCache(t, time.Hour, 0), "m")
+	hits := 0
+	cm.OnHit = func() { hits++ }
+
+	for i := 0; i < 2; i++ {
+		got, err := cm.Generate(context.Background(), "same prompt")
+		if err != nil || got != "answer" {
+			t.Fatalf("Generate = %q, %v", got, err)
+		}
+	}
+	if p.calls != 1 || hits != 1 {
+		t.Errorf("calls = %d, hits = %d; want 1 and 1", p.calls, hits)
+	}
+
+	cm.Generate(context.Background(), "other prompt")
+	if p.calls != 2 {
+		t.Errorf("a different prompt should miss, calls = %d", p.calls)
+	}
+}
+
+func TestCachingModel_KeysByModel(t *testing.T) {
+	cache := openTestCache(t, time.Hour, 0)
+	p := &countingProvider{MockProvider: MockProvider{Response: "answer"}}
+	NewCachingModel(p, cache, "a").Generate(context.Background(), "prompt")
+	NewCachingModel(p, cache, "b").Generate(context.Background(), "prompt")
+	if p.calls != 2 {
+		t.Errorf("calls = %d, want 2 for different models", p.calls)
+	}
+}
+
+func TestCachingModel_ExpiredEntriesMiss(t *testing.T) {
+	p := &countingProvider{MockProvider: MockProvider{Response: "answer"}}
+	cm := NewCachingModel(p, openTestCache(t, time.Nanosecond, 0), "m")
+	cm.Generate(context.Background(), "prompt")
+	time.Sleep(time.Millisecond)
+	cm.Generate(context.Background(), "prompt")
+	if p.calls != 2 {
+		t.Errorf("calls = %d, want 2 after the TTL", p.calls)
+	}
+}
+
+func TestResponseCache_BoundedAndClear(t *testing.T) {
+	cache := openTestCache(t, time.Hour, 2)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.put(key, "v"); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Entries != 2 {
+		t.Errorf("Entries = %d, want 2", stats.Entries)
+	}
+	if _, ok := cache.get("a"); ok {
+		t.Error("oldest entry should have been dropped")
+	}
+
+	n, err := cache.Clear()
+	if err != nil || n != 2 {
+		t.Fatalf("Clear = %d, %v; want 2", n, err)
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("Entries after Clear = %d", stats.Entries)
+	}
+}
@@ -0,0 +1,168 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Message roles.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool" // The result of a native tool call
+)
+
+// Message is one turn of a conversation. An assistant message carries the
+// native tool calls it made; a RoleTool message answers one of them.
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// ChatStreamer is implemented by providers that can stream a reply to a
+// message list. Model.StreamChat flattens the conversation into a single
+// prompt for the rest.
+type ChatStreamer interface {
+	GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error)
+}
+
+// FlattenMessages renders a conversation as a single prompt for providers
+// that only take one: system text first, then each turn labelled with its
+// role, ending with an open assistant turn.
+func FlattenMessages(messages []Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		switch m.Role {
+		case RoleSystem:
+			sb.WriteString(m.Content)
+		case RoleAssistant:
+			sb.WriteString("Assistant: " + m.Content)
+		case RoleTool:
+			sb.WriteString("Tool result: " + m.Content)
+		default:
+			sb.WriteString("User: " + m.Content)
+		}
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Assistant:")
+	return sb.String()
+}
+
+// GenerateChatAsPrompt implements GenerateChat for providers that only take
+// a single prompt, by flattening the conversation.
+func GenerateChatAsPrompt(ctx context.Context, p interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}, messages []Message) (string, error) {
+	return p.Generate(ctx, FlattenMessages(messages))
+}
+
+// GenerateChat sends a conversation to the configured provider.
+func (m *Model) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	if m.provider == nil {
+		return "", errors.New("no provider configured")
+	}
+	var resp string
+	err := m.withRetry(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		resp, err = m.provider.GenerateChat(ctx, messages)
+		return false, err
+	})
+	return resp, err
+}
+
+// StreamChat is GenerateChat with each chunk passed to onChunk in order.
+// Providers that cannot stream a conversation get it flattened through
+// GenerateStream.
+func (m *Model) StreamChat(ctx context.Context, messages []Message, onChunk func(string)) (string, error) {
+	if m.provider == nil {
+		return "", errors.New("no provider configured")
+	}
+	cs, ok := m.provider.(ChatStreamer)
+	if !ok {
+		return m.Stream(ctx, FlattenMessages(messages), onChunk)
+	}
+	out := make(chan string, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range out {
+			onChunk(chunk)
+		}
+	}()
+	var resp string
+	err := m.withRetry(ctx, func(ctx context.Context) (bool, error) {
+		var sent bool
+		var err error
+		resp, sent, err = relayStream(ctx, out, func(relay chan<- string) (string, error) {
+			return cs.GenerateChatStream(ctx, messages, relay)
+		})
+		return sent, err
+	})
+	close(out)
+	<-done
+	return resp, err
+}
+
+// langchainMessages converts a conversation for langchaingo models. Tool
+// calls go out as function calls and their results as tool messages.
+func langchainMessages(messages []Message) []llms.MessageContent {
+	out := make([]llms.MessageContent, 0, len(messages))
+	for _, m := range messages {
+		role := llms.ChatMessageTypeHuman
+		switch m.Role {
+		case RoleSystem:
+			role = llms.ChatMessageTypeSystem
+		case RoleAssistant:
+			role = llms.ChatMessageTypeAI
+		case RoleTool:
+			out = append(out, llms.MessageContent{
+				Role:  llms.ChatMessageTypeTool,
+				Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: m.ToolCallID, Content: m.Content}},
+			})
+			continue
+		}
+		if len(m.ToolCalls) == 0 {
+			out = append(out, llms.TextParts(role, m.Content))
+			continue
+		}
+		mc := llms.MessageContent{Role: role}
+		if m.Content != "" {
+			mc.Parts = append(mc.Parts, llms.TextContent{Text: m.Content})
+		}
+		for _, c := range m.ToolCalls {
+			mc.Parts = append(mc.Parts, llms.ToolCall{
+				ID:           c.ID,
+				Type:         "function",
+				FunctionCall: &llms.FunctionCall{Name: c.Name, Arguments: string(c.Arguments)},
+			})
+		}
+		out = append(out, mc)
+	}
+	return out
+}
+
+// generateLangchainChat runs a conversation through a langchaingo model,
+// streaming to out when it is non-nil.
+func generateLangchainChat(ctx context.Context, llm llms.Model, messages []Message, out chan<- string) (string, error) {
+	var opts []llms.CallOption
+	if out != nil {
+		opts = append(opts, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			return sendChunk(ctx, out, string(chunk))
+		}))
+	}
+	resp, err := llm.GenerateContent(ctx, langchainMessages(messages), opts...)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil
+	}
+	reportGenerationInfo(ctx, resp.Choices[0].GenerationInfo)
+	return resp.Choices[0].Content, nil
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIGenerateChat_KeepsRoles(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"second answer"}}]}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewOpenAIProvider("sk-test", "gpt-4o", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.GenerateChat(context.Background(), []Message{
+		{Role: RoleSystem, Content: "rules"},
+		{Role: RoleUser, Content: "first"},
+		{Role: RoleAssistant, Content: "first answer"},
+		{Role: RoleUser, Content: "second"},
+	})
+	if err != nil || got != "second answer" {
+		t.Fatalf("expected %q, got %q (%v)", "second answer", got, err)
+	}
+
+	roles := make([]string, len(sent.Messages))
+	for i, m := range sent.Messages {
+		roles[i] = m.Role
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,user" {
+		t.Errorf("expected each message sent with its role, got %v", roles)
+	}
+}
+
+func TestModel_StreamChatFlattensForPromptProviders(t *testing.T) {
+	m := New(&MockProvider{Response: "ok"})
+
+	var chunks []string
+	resp, err := m.StreamChat(context.Background(), []Message{
+		{Role: RoleSystem, Content: "rules"},
+		{Role: RoleUser, Content: "hi"},
+	}, func(s string) { chunks = append(chunks, s) })
+	if err != nil || resp != "ok" || len(chunks) != 1 {
+		t.Fatalf("expected the flattened prompt to stream as one chunk, got %q %q (%v)", resp, chunks, err)
+	}
+
+	flat := FlattenMessages([]Message{{Role: RoleSystem, Content: "rules"}, {Role: RoleUser, Content: "hi"}, {Role: RoleAssistant, Content: "hello"}})
+	if flat != "rules\n\nUser: hi\n\nAssistant: hello\n\nAssistant:" {
+		t.Errorf("unexpected flattened prompt %q", flat)
+	}
+}
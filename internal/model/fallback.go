@@ -0,0 +1,148 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// FallbackModel is a Provider that tries a list of providers in priority
+// order, moving on to the next whenever one fails.
+type FallbackModel struct {
+	providers []Provider
+	// OnFailure, if set, is called with each provider's error before the
+	// next one is tried.
+	OnFailure func(provider string, err error)
+}
+
+// NewFallbackModel wraps providers, the first being the primary.
+func NewFallbackModel(providers ...Provider) *FallbackModel {
+	var ps []Provider
+	for _, p := range providers {
+		if p != nil {
+			ps = append(ps, p)
+		}
+	}
+	return &FallbackModel{providers: ps}
+}
+
+// Name is the primary provider's name.
+func (f *FallbackModel) Name() string {
+	if len(f.providers) == 0 {
+		return "fallback"
+	}
+	return f.providers[0].Name()
+}
+
+// Providers returns the chain in priority order.
+func (f *FallbackModel) Providers() []Provider {
+	return f.providers
+}
+
+// Generate returns the first successful response in the chain.
+func (f *FallbackModel) Generate(ctx context.Context, prompt string) (string, error) {
+	return f.try(ctx, func(p Provider) (string, bool, error) {
+		resp, err := p.Generate(ctx, prompt)
+		return resp, false, err
+	})
+}
+
+// GenerateStream streams from the first provider that succeeds. Once a
+// provider has sent a chunk its error is final: falling back then would
+// repeat output the caller has already shown.
+func (f *FallbackModel) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return f.try(ctx, func(p Provider) (string, bool, error) {
+		return relayStream(ctx, out, func(relay chan<- string) (string, error) {
+			return p.GenerateStream(ctx, prompt, relay)
+		})
+	})
+}
+
+// GenerateChat returns the first successful reply to the conversation.
+func (f *FallbackModel) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return f.try(ctx, func(p Provider) (string, bool, error) {
+		resp, err := p.GenerateChat(ctx, messages)
+		return resp, false, err
+	})
+}
+
+// GenerateChatStream streams the conversation from the first provider that
+// succeeds, flattening it for providers that cannot stream a conversation.
+func (f *FallbackModel) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	return f.try(ctx, func(p Provider) (string, bool, error) {
+		return relayStream(ctx, out, func(relay chan<- string) (string, error) {
+			if cs, ok := p.(ChatStreamer); ok {
+				return cs.GenerateChatStream(ctx, messages, relay)
+			}
+			return p.GenerateStream(ctx, FlattenMessages(messages), relay)
+		})
+	})
+}
+
+// relayStream runs stream with its chunks forwarded to out, reporting
+// whether any were sent.
+func relayStream(ctx context.Context, out chan<- string, stream func(relay chan<- string) (string, error)) (string, bool, error) {
+	relay := make(chan string)
+	sent := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range relay {
+			sent = true
+			sendChunk(ctx, out, chunk)
+		}
+	}()
+	resp, err := stream(relay)
+	close(relay)
+	<-done
+	return resp, sent, err
+}
+
+// GenerateWithTools returns the first successful tool-aware response. A
+// provider without native tool calling ends the chain with
+// ErrToolsUnsupported, so the caller can retry the whole chain as text.
+func (f *FallbackModel) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	var out *ToolResponse
+	_, err := f.try(ctx, func(p Provider) (string, bool, error) {
+		resp, err := p.GenerateWithTools(ctx, messages, tools)
+		if err == nil {
+			out = resp
+		}
+		return "", errors.Is(err, ErrToolsUnsupported), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListModels lists the primary provider's models.
+func (f *FallbackModel) ListModels(ctx context.Context) ([]string, error) {
+	if len(f.providers) == 0 {
+		return nil, fmt.Errorf("no provider configured")
+	}
+	return f.providers[0].ListModels(ctx)
+}
+
+// try runs call against each provider in turn. It stops at the first
+// success, when call reports its failure as final, or when ctx is done.
+func (f *FallbackModel) try(ctx context.Context, call func(Provider) (string, bool, error)) (string, error) {
+	if len(f.providers) == 0 {
+		return "", fmt.Errorf("no provider configured")
+	}
+	var errs []error
+	for i, p := range f.providers {
+		resp, final, err := call(p)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if final || ctx.Err() != nil || i == len(f.providers)-1 {
+			return resp, errors.Join(errs...)
+		}
+		if f.OnFailure != nil {
+			f.OnFailure(p.Name(), err)
+		}
+	}
+	return "", errors.Join(errs...)
+}
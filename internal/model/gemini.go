@@ -0,0 +1,161 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+func init() {
+	Register("gemini", func(config map[string]string) (Provider, error) {
+		return NewGeminiProvider(config["api_key"], config["model"], config["base_url"])
+	})
+}
+
+// GeminiProvider implements the Provider interface for Google's Gemini
+// models through generativelanguage.googleapis.com.
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+func (p *GeminiProvider) Name() string { return "gemini" }
+
+// NewGeminiProvider creates a new Gemini provider. baseURL overrides the
+// API endpoint, for proxies and tests.
+func NewGeminiProvider(apiKey string, modelName string, baseURL string) (*GeminiProvider, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("gemini: no API key (run vibeaura auth gemini <api-key>): %w", ErrUnauthorized)
+	}
+	if modelName == "" {
+		modelName = "gemini-1.5-flash"
+	}
+	opts := []option.ClientOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(baseURL, "/")))
+	}
+
+	client, err := genai.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating gemini client: %w", err)
+	}
+	return &GeminiProvider{
+		client: client,
+		model:  strings.TrimPrefix(modelName, "models/"),
+	}, nil
+}
+
+// Generate sends a prompt to Gemini and returns the response
+func (p *GeminiProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.client.GenerativeModel(p.model).GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", geminiError("gemini generate", err)
+	}
+	return geminiText(resp), nil
+}
+
+// GenerateStream sends a prompt to Gemini and emits each streamed chunk of
+// text as it arrives.
+func (p *GeminiProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	iter := p.client.GenerativeModel(p.model).GenerateContentStream(ctx, genai.Text(prompt))
+
+	var sb strings.Builder
+	for {
+		resp, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return sb.String(), geminiError("gemini generate", err)
+		}
+		if text := geminiText(resp); text != "" {
+			sb.WriteString(text)
+			if err := sendChunk(ctx, out, text); err != nil {
+				return sb.String(), err
+			}
+		}
+	}
+}
+
+// GenerateChat sends the conversation flattened into a single prompt.
+func (p *GeminiProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return GenerateChatAsPrompt(ctx, p, messages)
+}
+
+// GenerateWithTools returns ErrToolsUnsupported; tool calls are parsed
+// from the text instead.
+func (p *GeminiProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	return NoNativeTools(ctx, messages, tools)
+}
+
+// ListModels returns the models that support generateContent, without the
+// "models/" prefix.
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]string, error) {
+	iter := p.client.ListModels(ctx)
+
+	var names []string
+	for {
+		info, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, geminiError("gemini list models", err)
+		}
+		for _, method := range info.SupportedGenerationMethods {
+			if method == "generateContent" {
+				names = append(names, strings.TrimPrefix(info.Name, "models/"))
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// geminiText concatenates the text parts of the first candidate.
+func geminiText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return sb.String()
+}
+
+// geminiError maps the SDK's HTTP errors onto ErrUnauthorized and
+// ErrRateLimited, as statusError does for the other providers.
+func geminiError(what string, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		e := &httpError{msg: fmt.Sprintf("%s: %d %s", what, apiErr.Code, http.StatusText(apiErr.Code)), code: apiErr.Code}
+		switch apiErr.Code {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+			// An invalid key is reported as 400 API_KEY_INVALID.
+			if apiErr.Code != http.StatusBadRequest || strings.Contains(apiErr.Message, "API key") {
+				e.kind = ErrUnauthorized
+			}
+		case http.StatusTooManyRequests:
+			e.kind = ErrRateLimited
+		}
+		if e.kind != nil {
+			e.msg += ": " + e.kind.Error()
+		} else if apiErr.Message != "" {
+			e.msg += " (" + apiErr.Message + ")"
+		}
+		return e
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// newGeminiServer fakes generativelanguage.googleapis.com, rejecting
+// requests without the API key the way the real endpoint does.
+func newGeminiServer(t *testing.T, apiKey string, handle http.HandlerFunc) *GeminiProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "gm-test" && r.URL.Query().Get("key") != "gm-test" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`))
+			return
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := GetProvider("gemini", map[string]string{"api_key": apiKey, "base_url": srv.URL})
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	return p.(*GeminiProvider)
+}
+
+func TestGeminiListModels(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/gemini_models.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newGeminiServer(t, "gm-test", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	})
+
+	got, err := p.ListModels(context.Background())
+	want := []string{"gemini-1.5-flash", "gemini-1.5-pro"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v (%v)", want, got, err)
+	}
+}
+
+func TestGeminiGenerate(t *testing.T) {
+	p := newGeminiServer(t, "gm-test", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-1.5-flash:generateContent" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hello "},{"text":"there"}]},"finishReason":"STOP"}]}`))
+	})
+
+	got, err := p.Generate(context.Background(), "hi")
+	if err != nil || got != "hello there" {
+		t.Fatalf("expected %q, got %q (%v)", "hello there", got, err)
+	}
+}
+
+func TestGeminiInvalidKey(t *testing.T) {
+	p := newGeminiServer(t, "wrong", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request with a bad key reached the handler")
+	})
+	if _, err := p.ListModels(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -62,6 +62,47 @@ func (p *GithubProvider) Generate(ctx context.Context, prompt string) (string, e
 	return resp, nil
 }
 
+// GenerateStream sends a prompt to GitHub Models and emits tokens as they are generated
+func (p *GithubProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	resp, err := llms.GenerateFromSinglePrompt(ctx, p.llm, prompt, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		return sendChunk(ctx, out, string(chunk))
+	}))
+	if err != nil {
+		return resp, fmt.Errorf("github models generate: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GenerateChat sends a conversation, keeping each message's role.
+func (p *GithubProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, nil)
+	if err != nil {
+		return "", fmt.Errorf("github models generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateChatStream is GenerateChat with tokens sent to out as they are
+// generated.
+func (p *GithubProvider) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, out)
+	if err != nil {
+		return resp, fmt.Errorf("github models generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateWithTools sends a conversation with tools attached as OpenAI functions
+// and returns the text and any tool calls in the reply.
+func (p *GithubProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	resp, err := generateWithLangchainTools(ctx, p.llm, messages, tools)
+	if err != nil {
+		return nil, fmt.Errorf("github models generate: %w", err)
+	}
+	return resp, nil
+}
+
 // ListModels returns a list of available models from GitHub Models
 func (p *GithubProvider) ListModels(ctx context.Context) ([]string, error) {
 	// GitHub Models uses the standard OpenAI /models endpoint or its own models API
@@ -81,7 +122,7 @@ func (p *GithubProvider) ListModels(ctx context.Context) ([]string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("github models list failed: %s", resp.Status)
+		return nil, statusError("github models list failed", resp)
 	}
 
 	// GitHub Models API can return either a top-level array or an object with a "data" field (OpenAI style)
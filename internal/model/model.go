@@ -2,12 +2,48 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 )
 
+var (
+	// ErrUnauthorized is wrapped by errors for rejected or missing credentials.
+	ErrUnauthorized = errors.New("credentials rejected")
+	// ErrRateLimited is wrapped by errors for rate-limit and quota responses.
+	ErrRateLimited = errors.New("rate limit or quota exceeded")
+)
+
+// statusError describes a non-OK provider response, wrapping ErrUnauthorized
+// or ErrRateLimited where the status code allows.
+func statusError(what string, resp *http.Response) error {
+	e := &httpError{msg: what + ": " + resp.Status, code: resp.StatusCode}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.kind = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		e.kind = ErrRateLimited
+	}
+	if e.kind != nil {
+		e.msg += ": " + e.kind.Error()
+	}
+	return e
+}
+
 // Provider represents an AI model provider (e.g., Ollama, OpenAI)
 type Provider interface {
 	Generate(ctx context.Context, prompt string) (string, error)
+	// GenerateStream is Generate with each chunk of the response sent to
+	// out as it is produced. It returns the full response and never closes
+	// out; the caller owns the channel.
+	GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error)
+	// GenerateChat replies to a conversation. Providers that only take a
+	// single prompt flatten it (see GenerateChatAsPrompt).
+	GenerateChat(ctx context.Context, messages []Message) (string, error)
+	// GenerateWithTools offers tools to the model and returns its prose
+	// and structured tool calls. Providers without native tool calling
+	// return ErrToolsUnsupported (see NoNativeTools).
+	GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error)
 	ListModels(ctx context.Context) ([]string, error)
 	Name() string
 }
@@ -37,14 +73,19 @@ func GetProvider(name string, config map[string]string) (Provider, error) {
 	return factory(config)
 }
 
-// Model handles AI interactions
+// Model handles AI interactions. Every request goes through its
+// RetryPolicy.
 type Model struct {
 	provider Provider
+	retry    RetryPolicy
+	// OnRetry, if set, is called before each retry with its number, the
+	// most retries allowed and the error being retried.
+	OnRetry func(attempt, max int, err error)
 }
 
-// New creates a new Model with the given provider
+// New creates a new Model with the given provider and DefaultRetryPolicy
 func New(p Provider) *Model {
-	return &Model{provider: p}
+	return &Model{provider: p, retry: DefaultRetryPolicy()}
 }
 
 // Generate uses the configured provider to generate a response
@@ -52,5 +93,67 @@ func (m *Model) Generate(ctx context.Context, prompt string) (string, error) {
 	if m.provider == nil {
 		return "", fmt.Errorf("no provider configured")
 	}
-	return m.provider.Generate(ctx, prompt)
+	var resp string
+	err := m.withRetry(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		resp, err = m.provider.Generate(ctx, prompt)
+		return false, err
+	})
+	return resp, err
+}
+
+// GenerateStream uses the configured provider to stream a response to out
+func (m *Model) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	if m.provider == nil {
+		return "", fmt.Errorf("no provider configured")
+	}
+	var resp string
+	err := m.withRetry(ctx, func(ctx context.Context) (bool, error) {
+		var sent bool
+		var err error
+		resp, sent, err = relayStream(ctx, out, func(relay chan<- string) (string, error) {
+			return m.provider.GenerateStream(ctx, prompt, relay)
+		})
+		return sent, err
+	})
+	return resp, err
+}
+
+// Stream is GenerateStream with each chunk passed to onChunk in order. All
+// chunks have been delivered when Stream returns.
+func (m *Model) Stream(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	out := make(chan string, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range out {
+			onChunk(chunk)
+		}
+	}()
+	resp, err := m.GenerateStream(ctx, prompt, out)
+	close(out)
+	<-done
+	return resp, err
+}
+
+// GenerateAsStream implements GenerateStream for providers that cannot
+// stream: the whole response is sent as a single chunk.
+func GenerateAsStream(ctx context.Context, p interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}, prompt string, out chan<- string) (string, error) {
+	resp, err := p.Generate(ctx, prompt)
+	if err != nil || resp == "" {
+		return resp, err
+	}
+	return resp, sendChunk(ctx, out, resp)
+}
+
+// sendChunk delivers a chunk unless ctx is cancelled first.
+func sendChunk(ctx context.Context, out chan<- string, chunk string) error {
+	select {
+	case out <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -2,6 +2,9 @@ package model
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -14,6 +17,18 @@ func (m *MockProvider) Generate(ctx context.Context, prompt string) (string, err
 	return m.Response, m.Err
 }
 
+func (m *MockProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return GenerateAsStream(ctx, m, prompt, out)
+}
+
+func (m *MockProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return GenerateChatAsPrompt(ctx, m, messages)
+}
+
+func (m *MockProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	return NoNativeTools(ctx, messages, tools)
+}
+
 func (m *MockProvider) ListModels(ctx context.Context) ([]string, error) {
 	return []string{"mock-model"}, nil
 }
@@ -36,3 +51,68 @@ func TestModel_Generate(t *testing.T) {
 	}
 }
 
+
+func TestOpenAIListModels_StatusErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+		}))
+		p, err := NewOpenAIProvider("sk-test", "gpt-4o", srv.URL)
+		if err != nil {
+			t.Fatalf("NewOpenAIProvider: %v", err)
+		}
+		_, err = p.ListModels(context.Background())
+		srv.Close()
+		if !errors.Is(err, c.want) {
+			t.Errorf("status %d: expected %v, got %v", c.status, c.want, err)
+		}
+	}
+}
+
+func TestModel_StreamFallback(t *testing.T) {
+	m := New(&MockProvider{Response: "whole answer"})
+
+	var chunks []string
+	resp, err := m.Stream(context.Background(), "Hello", func(s string) { chunks = append(chunks, s) })
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp != "whole answer" || len(chunks) != 1 || chunks[0] != "whole answer" {
+		t.Errorf("Expected a single chunk with the full response, got %q %q", resp, chunks)
+	}
+}
+
+func TestFallbackModel_TriesInOrder(t *testing.T) {
+	down := &MockProvider{Err: ErrRateLimited}
+	up := &MockProvider{Response: "from the fallback"}
+	fm := NewFallbackModel(down, up)
+	var failed []error
+	fm.OnFailure = func(provider string, err error) { failed = append(failed, err) }
+
+	resp, err := New(fm).Generate(context.Background(), "Hello")
+	if err != nil || resp != "from the fallback" {
+		t.Fatalf("expected the fallback's response, got %q (%v)", resp, err)
+	}
+	if len(failed) != 1 || !errors.Is(failed[0], ErrRateLimited) {
+		t.Errorf("expected the primary's failure to be reported, got %v", failed)
+	}
+
+	var chunks []string
+	resp, err = New(fm).Stream(context.Background(), "Hello", func(s string) { chunks = append(chunks, s) })
+	if err != nil || resp != "from the fallback" || len(chunks) != 1 {
+		t.Fatalf("expected one streamed chunk from the fallback, got %q %q (%v)", resp, chunks, err)
+	}
+
+	_, err = New(NewFallbackModel(down, &MockProvider{Err: ErrUnauthorized})).Generate(context.Background(), "Hello")
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected every failure in the final error, got %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
 
 	"github.com/ollama/ollama/api"
 )
@@ -44,13 +46,18 @@ func (p *OllamaProvider) PullModel(ctx context.Context, name string, progress fu
 }
 
 // NewOllamaProvider creates a new Ollama provider
-// host is the Ollama server URL (e.g., "http://localhost:11434")
+// host is the Ollama server URL (e.g., "http://localhost:11434"); OLLAMA_HOST
+// takes precedence when set
 // modelName is the model to use (e.g., "llama3")
 func NewOllamaProvider(host string, modelName string) (*OllamaProvider, error) {
-	client, err := api.ClientFromEnvironment()
-	if err != nil {
-		// Fallback to manual client creation if env vars are not set
-		client = api.NewClient(&url.URL{Scheme: "http", Host: host}, http.DefaultClient)
+	var client *api.Client
+	if u, err := url.Parse(host); err == nil && u.Host != "" && os.Getenv("OLLAMA_HOST") == "" {
+		client = api.NewClient(u, http.DefaultClient)
+	} else {
+		client, err = api.ClientFromEnvironment()
+		if err != nil {
+			return nil, fmt.Errorf("ollama client: %w", err)
+		}
 	}
 
 	return &OllamaProvider{
@@ -82,6 +89,40 @@ func (p *OllamaProvider) Generate(ctx context.Context, prompt string) (string, e
 	return response, nil
 }
 
+// GenerateStream sends a prompt to Ollama and emits tokens as they are generated
+func (p *OllamaProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	var response strings.Builder
+
+	req := &api.GenerateRequest{
+		Model:  p.model,
+		Prompt: prompt,
+	}
+
+	fn := func(resp api.GenerateResponse) error {
+		if resp.Response == "" {
+			return nil
+		}
+		response.WriteString(resp.Response)
+		return sendChunk(ctx, out, resp.Response)
+	}
+
+	if err := p.client.Generate(ctx, req, fn); err != nil {
+		return response.String(), fmt.Errorf("ollama generate: %w", err)
+	}
+	return response.String(), nil
+}
+
+// GenerateChat sends the conversation flattened into a single prompt.
+func (p *OllamaProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return GenerateChatAsPrompt(ctx, p, messages)
+}
+
+// GenerateWithTools returns ErrToolsUnsupported; tool calls are parsed
+// from the text instead.
+func (p *OllamaProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	return NoNativeTools(ctx, messages, tools)
+}
+
 // ListModels returns a list of available models from Ollama
 func (p *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
 	resp, err := p.client.List(ctx)
@@ -67,6 +67,47 @@ func (p *OpenAIProvider) Generate(ctx context.Context, prompt string) (string, e
 	return resp, nil
 }
 
+// GenerateStream sends a prompt to OpenAI and emits tokens as they are generated
+func (p *OpenAIProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	resp, err := llms.GenerateFromSinglePrompt(ctx, p.llm, prompt, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		return sendChunk(ctx, out, string(chunk))
+	}))
+	if err != nil {
+		return resp, fmt.Errorf("openai generate: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GenerateChat sends a conversation, keeping each message's role.
+func (p *OpenAIProvider) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, nil)
+	if err != nil {
+		return "", fmt.Errorf("openai generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateChatStream is GenerateChat with tokens sent to out as they are
+// generated.
+func (p *OpenAIProvider) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	resp, err := generateLangchainChat(ctx, p.llm, messages, out)
+	if err != nil {
+		return resp, fmt.Errorf("openai generate: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateWithTools sends a conversation with tools attached as OpenAI functions
+// and returns the text and any tool calls in the reply.
+func (p *OpenAIProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	resp, err := generateWithLangchainTools(ctx, p.llm, messages, tools)
+	if err != nil {
+		return nil, fmt.Errorf("openai generate: %w", err)
+	}
+	return resp, nil
+}
+
 // ListModels returns a list of available models from OpenAI
 func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
 	url := p.baseURL + "/models"
@@ -83,11 +124,11 @@ func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("openai api key is invalid or expired")
+		return nil, fmt.Errorf("openai api key is invalid or expired: %w", ErrUnauthorized)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("openai models list failed: %s", resp.Status)
+		return nil, statusError("openai models list failed", resp)
 	}
 
 	var data struct {
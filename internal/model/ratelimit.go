@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRateWait is how long a RateLimiter waits for a free slot
+// before refusing the request instead.
+const DefaultMaxRateWait = 20 * time.Second
+
+// RateLimitedError is returned when a provider's requests-per-minute
+// limit is reached and the next slot is too far away to wait for. It
+// wraps ErrRateLimited.
+type RateLimitedError struct {
+	Provider   string
+	Limit      int           // Requests per minute
+	RetryAfter time.Duration // Until the next request is allowed
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s: limit of %d requests per minute reached, next slot in %s",
+		e.Provider, e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RateLimiter allows a provider a number of requests in any one-minute
+// window. A nil *RateLimiter allows everything.
+type RateLimiter struct {
+	mu       sync.Mutex
+	provider string
+	limit    int
+	sent     []time.Time // Start of each request in the last window, oldest first
+	window   time.Duration
+	// MaxWait is the longest Wait sleeps for a slot (DefaultMaxRateWait).
+	MaxWait time.Duration
+	now     func() time.Time
+}
+
+// NewRateLimiter allows provider perMinute requests a minute.
+func NewRateLimiter(provider string, perMinute int) *RateLimiter {
+	return &RateLimiter{
+		provider: provider,
+		limit:    perMinute,
+		window:   time.Minute,
+		MaxWait:  DefaultMaxRateWait,
+		now:      time.Now,
+	}
+}
+
+// SetLimit changes the requests allowed per minute; 0 or less allows
+// everything. Requests already sent still count.
+func (l *RateLimiter) SetLimit(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = perMinute
+}
+
+// Wait takes a slot for one request, sleeping until one is free if that
+// is at most MaxWait away and within ctx's deadline. Otherwise it returns
+// a *RateLimitedError without waiting.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		wait, limit := l.take()
+		if wait == 0 {
+			return nil
+		}
+		deadline, ok := ctx.Deadline()
+		if wait > l.MaxWait || (ok && l.now().Add(wait).After(deadline)) {
+			return &RateLimitedError{Provider: l.provider, Limit: limit, RetryAfter: wait}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take records a request if the window has room, returning 0, or else how
+// long until it will.
+func (l *RateLimiter) take() (time.Duration, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		return 0, l.limit
+	}
+	now := l.now()
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.sent) && !l.sent[i].After(cutoff) {
+		i++
+	}
+	l.sent = l.sent[i:]
+	if len(l.sent) < l.limit {
+		l.sent = append(l.sent, now)
+		return 0, l.limit
+	}
+	return l.sent[0].Add(l.window).Sub(now), l.limit
+}
+
+// release gives back the slot of the latest request, for one that never
+// reached the provider.
+func (l *RateLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := len(l.sent); n > 0 {
+		l.sent = l.sent[:n-1]
+	}
+}
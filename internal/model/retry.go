@@ -0,0 +1,133 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// ErrRequestTimeout is wrapped, with context.DeadlineExceeded, by errors
+// for a request that got no answer within RetryPolicy.Timeout.
+var ErrRequestTimeout = errors.New("model request timed out")
+
+// Defaults for a RetryPolicy left at zero.
+const (
+	DefaultRequestTimeout = 120 * time.Second
+	DefaultMaxRetries     = 3
+	defaultRetryDelay     = time.Second
+)
+
+// RetryPolicy bounds each request to the provider and retries those that
+// fail transiently: network errors and 5xx responses. 4xx responses,
+// including rate limits, and timeouts are not retried.
+type RetryPolicy struct {
+	Timeout    time.Duration // Per attempt; 0 means no limit
+	MaxRetries int           // Retries after the first attempt
+	BaseDelay  time.Duration // Backoff before the first retry, doubling after; 0 means 1s
+}
+
+// DefaultRetryPolicy is the policy a Model starts with.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Timeout: DefaultRequestTimeout, MaxRetries: DefaultMaxRetries}
+}
+
+// SetRetryPolicy replaces the model's retry policy.
+func (m *Model) SetRetryPolicy(p RetryPolicy) {
+	m.retry = p
+}
+
+// withRetry runs call under the retry policy, passing each attempt its own
+// deadline. A call that has started streaming reports sent and is not
+// retried, since its output has already been shown.
+func (m *Model) withRetry(ctx context.Context, call func(ctx context.Context) (sent bool, err error)) error {
+	delay := m.retry.BaseDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		actx, cancel := ctx, context.CancelFunc(func() {})
+		if m.retry.Timeout > 0 {
+			actx, cancel = context.WithTimeout(ctx, m.retry.Timeout)
+		}
+		sent, err := call(actx)
+		timedOut := err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if timedOut {
+			return fmt.Errorf("%w: no answer within %s: %w", ErrRequestTimeout, m.retry.Timeout, err)
+		}
+		if err == nil || sent || attempt >= m.retry.MaxRetries || ctx.Err() != nil || !IsTransient(err) {
+			return err
+		}
+
+		if m.OnRetry != nil {
+			m.OnRetry(attempt+1, m.retry.MaxRetries, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// langchainStatus matches the status code in langchaingo's HTTP errors.
+var langchainStatus = regexp.MustCompile(`status code: (\d{3})`)
+
+// IsTransient reports whether err is worth retrying: a network failure or a
+// 5xx response. Cancellation, timeouts and 4xx responses are not.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+	if code, ok := httpStatus(err); ok {
+		return code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// httpStatus finds the HTTP status code behind a provider error.
+func httpStatus(err error) (int, bool) {
+	var he *httpError
+	if errors.As(err, &he) {
+		return he.code, true
+	}
+	var oe api.StatusError
+	if errors.As(err, &oe) {
+		return oe.StatusCode, true
+	}
+	var oep *api.StatusError
+	if errors.As(err, &oep) {
+		return oep.StatusCode, true
+	}
+	if m := langchainStatus.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code, true
+	}
+	return 0, false
+}
+
+// httpError is a non-OK provider response (see statusError).
+type httpError struct {
+	msg  string
+	code int
+	kind error // ErrUnauthorized, ErrRateLimited or nil
+}
+
+func (e *httpError) Error() string { return e.msg }
+func (e *httpError) Unwrap() error { return e.kind }
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// flakyProvider fails its first failures calls with err.
+type flakyProvider struct {
+	MockProvider
+	failures int
+	err      error
+	calls    int
+}
+
+func (p *flakyProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return "", p.err
+	}
+	return "ok", nil
+}
+
+func (p *flakyProvider) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return GenerateAsStream(ctx, p, prompt, out)
+}
+
+func fastRetries(m *Model, retries int) *[]int {
+	var attempts []int
+	m.SetRetryPolicy(RetryPolicy{Timeout: time.Second, MaxRetries: retries, BaseDelay: time.Millisecond})
+	m.OnRetry = func(attempt, max int, err error) { attempts = append(attempts, attempt) }
+	return &attempts
+}
+
+func TestModel_RetriesTransientErrors(t *testing.T) {
+	unavailable := &httpError{msg: "generate: 503 Service Unavailable", code: http.StatusServiceUnavailable}
+	p := &flakyProvider{failures: 2, err: unavailable}
+	m := New(p)
+	attempts := fastRetries(m, 3)
+
+	resp, err := m.Generate(context.Background(), "hi")
+	if err != nil || resp != "ok" || p.calls != 3 {
+		t.Fatalf("expected success on the third call, got %q after %d calls (%v)", resp, p.calls, err)
+	}
+	if fmt.Sprint(*attempts) != "[1 2]" {
+		t.Errorf("expected retries 1 and 2 to be reported, got %v", *attempts)
+	}
+
+	p = &flakyProvider{failures: 10, err: unavailable}
+	m = New(p)
+	fastRetries(m, 2)
+	if _, err := m.Generate(context.Background(), "hi"); !errors.Is(err, unavailable) || p.calls != 3 {
+		t.Errorf("expected to give up after 2 retries, %d calls (%v)", p.calls, err)
+	}
+}
+
+func TestModel_DoesNotRetryClientErrors(t *testing.T) {
+	for _, err := range []error{
+		&httpError{msg: "generate: 400 Bad Request", code: http.StatusBadRequest},
+		ErrRateLimited,
+		ErrUnauthorized,
+		errors.New("API returned unexpected status code: 404: model not found"),
+	} {
+		p := &flakyProvider{failures: 1, err: err}
+		m := New(p)
+		fastRetries(m, 3)
+		if _, got := m.Generate(context.Background(), "hi"); got == nil || p.calls != 1 {
+			t.Errorf("%v: expected one call and the error, got %d calls (%v)", err, p.calls, got)
+		}
+	}
+}
+
+func TestModel_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	p, _ := NewOllamaProvider(srv.URL, "llama3")
+	m := New(p)
+	retried := fastRetries(m, 3)
+	m.SetRetryPolicy(RetryPolicy{Timeout: 50 * time.Millisecond, MaxRetries: 3, BaseDelay: time.Millisecond})
+
+	_, err := m.Generate(context.Background(), "hi")
+	if !errors.Is(err, ErrRequestTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected ErrRequestTimeout, got %v", err)
+	}
+	if len(*retried) != 0 {
+		t.Errorf("expected a timeout not to be retried, got %v", *retried)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&httpError{code: 502}, true},
+		{fmt.Errorf("ollama: %w", &httpError{code: 500}), true},
+		{errors.New("API returned unexpected status code: 503"), true},
+		{&httpError{code: 422}, false},
+		{context.Canceled, false},
+		{errors.New("decoding response: invalid character"), false},
+	}
+	for _, c := range cases {
+		if got := IsTransient(c.err); got != c.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+
+	// A refused connection is a network error.
+	_, err := http.Get("http://127.0.0.1:1")
+	if err == nil || !IsTransient(err) {
+		t.Errorf("expected a refused connection to be transient, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrToolsUnsupported is returned by GenerateWithTools when the provider has
+// no native tool calling. Callers fall back to describing the tools in the
+// prompt and parsing calls out of the text.
+var ErrToolsUnsupported = errors.New("native tool calling not supported")
+
+// ToolMetadata describes a tool offered to the model. Parameters is a JSON
+// Schema object; an empty schema means the tool takes no arguments.
+type ToolMetadata struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+// ToolCall is a structured tool invocation returned by the model. ID is
+// the provider's call identifier, used to match results to calls.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// ToolResponse is a reply that may carry prose, tool calls, or both.
+type ToolResponse struct {
+	Text  string
+	Calls []ToolCall
+}
+
+// NoNativeTools implements GenerateWithTools for providers without native
+// tool calling.
+func NoNativeTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	return nil, ErrToolsUnsupported
+}
+
+// GenerateWithTools asks the configured provider for a reply to messages
+// that may call any of tools.
+func (m *Model) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	if m.provider == nil {
+		return nil, errors.New("no provider configured")
+	}
+	var resp *ToolResponse
+	err := m.withRetry(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		resp, err = m.provider.GenerateWithTools(ctx, messages, tools)
+		return false, err
+	})
+	return resp, err
+}
+
+// emptySchema is sent for tools that declare no parameters, since the
+// OpenAI API requires an object schema.
+var emptySchema = json.RawMessage(`{"type":"object","properties":{}}`)
+
+// generateWithLangchainTools runs a conversation through a langchaingo
+// model with tools attached as OpenAI-style functions.
+func generateWithLangchainTools(ctx context.Context, llm llms.Model, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	defs := make([]llms.Tool, len(tools))
+	for i, t := range tools {
+		params := t.Parameters
+		if len(params) == 0 {
+			params = emptySchema
+		}
+		defs[i] = llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  params,
+			},
+		}
+	}
+
+	resp, err := llm.GenerateContent(ctx, langchainMessages(messages), llms.WithTools(defs))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return &ToolResponse{}, nil
+	}
+
+	choice := resp.Choices[0]
+	reportGenerationInfo(ctx, choice.GenerationInfo)
+	out := &ToolResponse{Text: choice.Content}
+	for _, tc := range choice.ToolCalls {
+		if tc.FunctionCall == nil {
+			continue
+		}
+		args := json.RawMessage(tc.FunctionCall.Arguments)
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		out.Calls = append(out.Calls, ToolCall{ID: tc.ID, Name: tc.FunctionCall.Name, Arguments: args})
+	}
+	return out, nil
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const openAIToolReply = `{
+  "id": "chatcmpl-1",
+  "object": "chat.completion",
+  "model": "gpt-4o",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "Here is an example config:\n` + "```json" + `\n{\"tool\": \"not_a_tool\"}\n` + "```" + `\nReading the real one now.",
+      "tool_calls": [
+        {"id": "call_1", "type": "function", "function": {"name": "sys_read_file", "arguments": "{\"path\":\"go.mod\"}"}},
+        {"id": "call_2", "type": "function", "function": {"name": "sys_info", "arguments": ""}}
+      ]
+    }
+  }]
+}`
+
+func TestOpenAIGenerateWithTools_ProseAndCalls(t *testing.T) {
+	var sent struct {
+		Tools []struct {
+			Type     string `json:"type"`
+			Function struct {
+				Name       string          `json:"name"`
+				Parameters json.RawMessage `json:"parameters"`
+			} `json:"function"`
+		} `json:"tools"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(openAIToolReply))
+	}))
+	defer srv.Close()
+
+	p, err := NewOpenAIProvider("sk-test", "gpt-4o", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := p.GenerateWithTools(context.Background(), []Message{{Role: RoleUser, Content: "read go.mod"}}, []ToolMetadata{
+		{Name: "sys_read_file", Description: "Read a file", Parameters: json.RawMessage(`{"type":"object","properties":{"path":{"type":"string"}}}`)},
+		{Name: "sys_info", Description: "System info"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+
+	if len(sent.Tools) != 2 || sent.Tools[0].Type != "function" || sent.Tools[0].Function.Name != "sys_read_file" {
+		t.Fatalf("expected both tools to be sent as functions, got %+v", sent.Tools)
+	}
+	if string(sent.Tools[1].Function.Parameters) != string(emptySchema) {
+		t.Errorf("expected an empty object schema for a tool without parameters, got %s", sent.Tools[1].Function.Parameters)
+	}
+
+	if resp.Text == "" {
+		t.Error("expected the prose to be kept alongside the tool calls")
+	}
+	if len(resp.Calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %+v", resp.Calls)
+	}
+	if c := resp.Calls[0]; c.ID != "call_1" || c.Name != "sys_read_file" || string(c.Arguments) != `{"path":"go.mod"}` {
+		t.Errorf("unexpected first call %+v", c)
+	}
+	if c := resp.Calls[1]; c.ID != "call_2" || string(c.Arguments) != "{}" {
+		t.Errorf("expected empty arguments to become {}, got %+v", c)
+	}
+}
+
+func TestOpenAIGenerateWithTools_SendsCallsAndResults(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Role       string `json:"role"`
+			Content    string `json:"content"`
+			ToolCallID string `json:"tool_call_id"`
+			ToolCalls  []struct {
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"messages"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(openAIToolReply))
+	}))
+	defer srv.Close()
+
+	p, err := NewOpenAIProvider("sk-test", "gpt-4o", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.GenerateWithTools(context.Background(), []Message{
+		{Role: RoleUser, Content: "read go.mod"},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Name: "sys_read_file", Arguments: json.RawMessage(`{"path":"go.mod"}`)}}},
+		{Role: RoleTool, ToolCallID: "call_1", Content: "module x"},
+	}, []ToolMetadata{{Name: "sys_read_file"}})
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+
+	if len(sent.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %+v", sent.Messages)
+	}
+	call := sent.Messages[1]
+	if call.Role != "assistant" || len(call.ToolCalls) != 1 || call.ToolCalls[0].ID != "call_1" ||
+		call.ToolCalls[0].Function.Name != "sys_read_file" || call.ToolCalls[0].Function.Arguments != `{"path":"go.mod"}` {
+		t.Errorf("expected the assistant turn to carry its tool call, got %+v", call)
+	}
+	if res := sent.Messages[2]; res.Role != "tool" || res.ToolCallID != "call_1" || res.Content != "module x" {
+		t.Errorf("expected a tool message answering call_1, got %+v", res)
+	}
+}
+
+func TestFallbackModel_ToolsUnsupported(t *testing.T) {
+	fm := NewFallbackModel(&MockProvider{Response: "text only"})
+	_, err := New(fm).GenerateWithTools(context.Background(), []Message{{Role: RoleUser, Content: "Hello"}}, nil)
+	if !errors.Is(err, ErrToolsUnsupported) {
+		t.Errorf("expected ErrToolsUnsupported, got %v", err)
+	}
+}
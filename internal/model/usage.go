@@ -0,0 +1,304 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// UsageFile is the usage database's name in the data dir.
+const UsageFile = "usage.db"
+
+// usageDay is the layout of the day a request is counted under, in local
+// time.
+const usageDay = "2006-01-02"
+
+// UsageStore counts model requests in SQLite, one row per provider,
+// model and day.
+type UsageStore struct {
+	db *sql.DB
+}
+
+// UsageRecord is one finished request. Token counts are zero when the
+// provider did not report them.
+type UsageRecord struct {
+	Provider         string
+	Model            string
+	At               time.Time
+	PromptChars      int
+	ResponseChars    int
+	PromptTokens     int
+	CompletionTokens int
+	Failed           bool
+}
+
+// UsageSummary totals the requests to one provider and model.
+type UsageSummary struct {
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Requests         int       `json:"requests"`
+	Errors           int       `json:"errors"`
+	PromptChars      int64     `json:"prompt_chars"`
+	ResponseChars    int64     `json:"response_chars"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	First            time.Time `json:"first"`
+	Last             time.Time `json:"last"`
+}
+
+// OpenUsageStore opens (or creates) the usage database at path.
+func OpenUsageStore(path string) (*UsageStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS usage (
+		provider TEXT,
+		model TEXT,
+		day TEXT,
+		requests INTEGER DEFAULT 0,
+		errors INTEGER DEFAULT 0,
+		prompt_chars INTEGER DEFAULT 0,
+		response_chars INTEGER DEFAULT 0,
+		prompt_tokens INTEGER DEFAULT 0,
+		completion_tokens INTEGER DEFAULT 0,
+		first_at INTEGER,
+		last_at INTEGER,
+		PRIMARY KEY (provider, model, day)
+	)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing usage store: %w", err)
+	}
+	return &UsageStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *UsageStore) Close() error {
+	return s.db.Close()
+}
+
+// Record adds r to its provider, model and day.
+func (s *UsageStore) Record(r UsageRecord) error {
+	if r.At.IsZero() {
+		r.At = time.Now()
+	}
+	failed := 0
+	if r.Failed {
+		failed = 1
+	}
+	at := r.At.UnixNano()
+	_, err := s.db.Exec(`INSERT INTO usage (provider, model, day, requests, errors, prompt_chars, response_chars, prompt_tokens, completion_tokens, first_at, last_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider, model, day) DO UPDATE SET
+			requests = requests + 1,
+			errors = errors + excluded.errors,
+			prompt_chars = prompt_chars + excluded.prompt_chars,
+			response_chars = response_chars + excluded.response_chars,
+			prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+			completion_tokens = completion_tokens + excluded.completion_tokens,
+			first_at = MIN(first_at, excluded.first_at),
+			last_at = MAX(last_at, excluded.last_at)`,
+		r.Provider, r.Model, r.At.Local().Format(usageDay), failed,
+		r.PromptChars, r.ResponseChars, r.PromptTokens, r.CompletionTokens, at, at)
+	return err
+}
+
+// Summary totals usage per provider and model over the days from since's
+// onwards, busiest first. A zero since covers everything recorded.
+func (s *UsageStore) Summary(since time.Time) ([]UsageSummary, error) {
+	day := ""
+	if !since.IsZero() {
+		day = since.Local().Format(usageDay)
+	}
+	rows, err := s.db.Query(`SELECT provider, model, SUM(requests), SUM(errors), SUM(prompt_chars), SUM(response_chars),
+			SUM(prompt_tokens), SUM(completion_tokens), MIN(first_at), MAX(last_at)
+		FROM usage WHERE day >= ?
+		GROUP BY provider, model
+		ORDER BY SUM(requests) DESC, provider, model`, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []UsageSummary{} // Encodes as [] when there are none
+	for rows.Next() {
+		var u UsageSummary
+		var first, last int64
+		if err := rows.Scan(&u.Provider, &u.Model, &u.Requests, &u.Errors, &u.PromptChars, &u.ResponseChars,
+			&u.PromptTokens, &u.CompletionTokens, &first, &last); err != nil {
+			return nil, err
+		}
+		u.First = time.Unix(0, first)
+		u.Last = time.Unix(0, last)
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// tokenCountKey carries a *tokenCount for providers to report the token
+// usage the API returned.
+type tokenCountKey struct{}
+
+// tokenCount collects reported token usage. Counts also go to the
+// counter it was nested in, so a UsageModel inside a CountTokens context
+// does not hide its requests from the outer caller.
+type tokenCount struct {
+	prompt, completion int
+	parent             *tokenCount
+}
+
+func withTokenCount(ctx context.Context) (context.Context, *tokenCount) {
+	parent, _ := ctx.Value(tokenCountKey{}).(*tokenCount)
+	tc := &tokenCount{parent: parent}
+	return context.WithValue(ctx, tokenCountKey{}, tc), tc
+}
+
+// CountTokens returns a context that totals the token usage providers
+// report for requests made with it, and a func reading the totals so far.
+// Both are zero when the provider reports no usage.
+func CountTokens(ctx context.Context) (context.Context, func() (prompt, completion int)) {
+	ctx, tc := withTokenCount(ctx)
+	return ctx, func() (int, int) { return tc.prompt, tc.completion }
+}
+
+// reportTokens records the token usage of a response, if a UsageModel is
+// listening.
+func reportTokens(ctx context.Context, prompt, completion int) {
+	tc, _ := ctx.Value(tokenCountKey{}).(*tokenCount)
+	for ; tc != nil; tc = tc.parent {
+		tc.prompt += prompt
+		tc.completion += completion
+	}
+}
+
+// reportGenerationInfo reports the token counts langchaingo puts in a
+// choice's GenerationInfo.
+func reportGenerationInfo(ctx context.Context, info map[string]any) {
+	reportTokens(ctx, infoInt(info["PromptTokens"]), infoInt(info["CompletionTokens"]))
+}
+
+func infoInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// UsageModel is a Provider that records every request in a UsageStore
+// and, with a RateLimiter, keeps to the provider's requests per minute.
+// Either may be nil.
+type UsageModel struct {
+	provider Provider
+	store    *UsageStore
+	limiter  *RateLimiter
+	model    string
+	// OnError, if set, is called when a request cannot be recorded.
+	OnError func(err error)
+}
+
+// NewUsageModel wraps p, recording its requests under p's name and
+// modelName.
+func NewUsageModel(p Provider, store *UsageStore, limiter *RateLimiter, modelName string) *UsageModel {
+	return &UsageModel{provider: p, store: store, limiter: limiter, model: modelName}
+}
+
+// track waits for the rate limiter, runs call and records it. A request
+// the limiter refuses never reaches the provider and is not counted, and
+// neither is one refused with ErrToolsUnsupported.
+func (u *UsageModel) track(ctx context.Context, prompt int, call func(ctx context.Context) (string, error)) (string, error) {
+	if err := u.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	ctx, tc := withTokenCount(ctx)
+	resp, err := call(ctx)
+	if errors.Is(err, ErrToolsUnsupported) {
+		// Answered without asking the API; the caller asks again in prose.
+		u.limiter.release()
+		return resp, err
+	}
+	if u.store != nil {
+		rec := UsageRecord{
+			Provider:         u.provider.Name(),
+			Model:            u.model,
+			At:               time.Now(),
+			PromptChars:      prompt,
+			ResponseChars:    utf8.RuneCountInString(resp),
+			PromptTokens:     tc.prompt,
+			CompletionTokens: tc.completion,
+			Failed:           err != nil,
+		}
+		if rErr := u.store.Record(rec); rErr != nil && u.OnError != nil {
+			u.OnError(rErr)
+		}
+	}
+	return resp, err
+}
+
+func messageChars(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += utf8.RuneCountInString(m.Content)
+	}
+	return n
+}
+
+// Name is the wrapped provider's name.
+func (u *UsageModel) Name() string {
+	return u.provider.Name()
+}
+
+func (u *UsageModel) Generate(ctx context.Context, prompt string) (string, error) {
+	return u.track(ctx, utf8.RuneCountInString(prompt), func(ctx context.Context) (string, error) {
+		return u.provider.Generate(ctx, prompt)
+	})
+}
+
+func (u *UsageModel) GenerateStream(ctx context.Context, prompt string, out chan<- string) (string, error) {
+	return u.track(ctx, utf8.RuneCountInString(prompt), func(ctx context.Context) (string, error) {
+		return u.provider.GenerateStream(ctx, prompt, out)
+	})
+}
+
+func (u *UsageModel) GenerateChat(ctx context.Context, messages []Message) (string, error) {
+	return u.track(ctx, messageChars(messages), func(ctx context.Context) (string, error) {
+		return u.provider.GenerateChat(ctx, messages)
+	})
+}
+
+// GenerateChatStream streams the conversation, flattening it for providers
+// that cannot stream one.
+func (u *UsageModel) GenerateChatStream(ctx context.Context, messages []Message, out chan<- string) (string, error) {
+	return u.track(ctx, messageChars(messages), func(ctx context.Context) (string, error) {
+		if cs, ok := u.provider.(ChatStreamer); ok {
+			return cs.GenerateChatStream(ctx, messages, out)
+		}
+		return u.provider.GenerateStream(ctx, FlattenMessages(messages), out)
+	})
+}
+
+func (u *UsageModel) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolMetadata) (*ToolResponse, error) {
+	var resp *ToolResponse
+	_, err := u.track(ctx, messageChars(messages), func(ctx context.Context) (string, error) {
+		var err error
+		resp, err = u.provider.GenerateWithTools(ctx, messages, tools)
+		if resp == nil {
+			return "", err
+		}
+		return resp.Text, err
+	})
+	return resp, err
+}
+
+// ListModels is not counted.
+func (u *UsageModel) ListModels(ctx context.Context) ([]string, error) {
+	return u.provider.ListModels(ctx)
+}
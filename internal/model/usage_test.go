@@ -0,0 +1,167 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestUsage(t *testing.T) *UsageStore {
+	t.Helper()
+	s, err := OpenUsageStore(filepath.Join(t.TempDir(), UsageFile))
+	if err != nil {
+		t.Fatalf("OpenUsageStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestUsageModel_RecordsRequests(t *testing.T) {
+	store := openTestUsage(t)
+	p := &MockProvider{Response: "héllo"}
+	um := NewUsageModel(p, store, nil, "m1")
+
+	um.Generate(context.Background(), "abc")
+	um.GenerateChat(context.Background(), []Message{{Role: RoleSystem, Content: "sys"}, {Role: RoleUser, Content: "hi"}})
+	p.Err = errors.New("boom")
+	um.Generate(context.Background(), "x")
+
+	got, err := store.Summary(time.Time{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Summary = %v, %v", got, err)
+	}
+	u := got[0]
+	if u.Provider != "mock" || u.Model != "m1" || u.Requests != 3 || u.Errors != 1 {
+		t.Errorf("unexpected totals %+v", u)
+	}
+	if u.PromptChars != 3+5+1 || u.ResponseChars != 5+5+5 {
+		t.Errorf("prompt/response chars = %d/%d, want 9/15", u.PromptChars, u.ResponseChars)
+	}
+	if u.First.IsZero() || u.Last.Before(u.First) {
+		t.Errorf("unexpected timestamps %v %v", u.First, u.Last)
+	}
+}
+
+func TestUsageStore_SummarySince(t *testing.T) {
+	store := openTestUsage(t)
+	now := time.Now()
+	store.Record(UsageRecord{Provider: "openai", Model: "gpt-4o", At: now.AddDate(0, 0, -10), PromptTokens: 100})
+	store.Record(UsageRecord{Provider: "openai", Model: "gpt-4o", At: now, PromptTokens: 7, CompletionTokens: 3})
+	store.Record(UsageRecord{Provider: "ollama", Model: "llama3", At: now})
+	store.Record(UsageRecord{Provider: "ollama", Model: "llama3", At: now})
+
+	got, err := store.Summary(now.AddDate(0, 0, -7))
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Summary = %v, %v", got, err)
+	}
+	if got[0].Provider != "ollama" || got[0].Requests != 2 {
+		t.Errorf("expected the busiest model first, got %+v", got[0])
+	}
+	if got[1].Requests != 1 || got[1].PromptTokens != 7 || got[1].CompletionTokens != 3 {
+		t.Errorf("expected only the recent openai request, got %+v", got[1])
+	}
+
+	all, _ := store.Summary(time.Time{})
+	if len(all) != 2 || all[1].Provider != "openai" || all[1].PromptTokens != 107 {
+		t.Errorf("expected every day without since, got %+v", all)
+	}
+}
+
+func TestUsageModel_RecordsAnthropicTokens(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":12,"output_tokens":4}}`))
+	})
+	store := openTestUsage(t)
+	if _, err := NewUsageModel(p, store, nil, "claude").Generate(context.Background(), "hi"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.Summary(time.Time{})
+	if len(got) != 1 || got[0].PromptTokens != 12 || got[0].CompletionTokens != 4 {
+		t.Errorf("expected the API's token counts, got %+v", got)
+	}
+}
+
+func TestCountTokens_SeesThroughUsageModel(t *testing.T) {
+	p := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request, body anthropicRequest) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":12,"output_tokens":4}}`))
+	})
+	store := openTestUsage(t)
+	ctx, tokens := CountTokens(context.Background())
+	if _, err := NewUsageModel(p, store, nil, "claude").Generate(ctx, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if in, out := tokens(); in != 12 || out != 4 {
+		t.Errorf("expected 12/4 tokens outside the UsageModel, got %d/%d", in, out)
+	}
+	got, _ := store.Summary(time.Time{})
+	if len(got) != 1 || got[0].PromptTokens != 12 {
+		t.Errorf("expected the UsageModel to still count, got %+v", got)
+	}
+}
+
+func TestUsageModel_ToolsUnsupportedNotCounted(t *testing.T) {
+	store := openTestUsage(t)
+	limiter := NewRateLimiter("mock", 1)
+	um := NewUsageModel(&MockProvider{}, store, limiter, "m")
+	if _, err := um.GenerateWithTools(context.Background(), nil, nil); !errors.Is(err, ErrToolsUnsupported) {
+		t.Fatalf("expected ErrToolsUnsupported, got %v", err)
+	}
+	if got, _ := store.Summary(time.Time{}); len(got) != 0 {
+		t.Errorf("expected nothing recorded, got %+v", got)
+	}
+	if _, err := um.Generate(context.Background(), "hi"); err != nil {
+		t.Errorf("expected the slot to be given back, got %v", err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewRateLimiter("openai", 2)
+	l.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+
+	err := l.Wait(ctx)
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected a RateLimitedError, got %v", err)
+	}
+	if rl.Provider != "openai" || rl.Limit != 2 || rl.RetryAfter != time.Minute {
+		t.Errorf("unexpected error %+v", rl)
+	}
+
+	l.SetLimit(0)
+	if err := l.Wait(ctx); err != nil {
+		t.Errorf("expected no limit at 0, got %v", err)
+	}
+}
+
+func TestRateLimiter_WaitsForCloseSlot(t *testing.T) {
+	l := NewRateLimiter("openai", 1)
+	l.window = 20 * time.Millisecond
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if waited := time.Since(start); waited < l.window {
+		t.Errorf("expected the second request to wait for the window, waited %s", waited)
+	}
+
+	// Never beyond the context's deadline.
+	short, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	var rl *RateLimitedError
+	if err := l.Wait(short); !errors.As(err, &rl) {
+		t.Errorf("expected a RateLimitedError within a short deadline, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PlanStep is one item of an extracted plan.
+type PlanStep struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Plan is a step list recovered from a model response.
+type Plan struct {
+	Title string     `json:"title,omitempty"`
+	Steps []PlanStep `json:"steps"`
+}
+
+var (
+	numberedItem  = regexp.MustCompile(`(?i)^\s*(?:step\s+)?(\d+)[.):]\s+(.+)$`)
+	checklistItem = regexp.MustCompile(`^\s*[-*+]\s+\[( |x|X)\]\s+(.+)$`)
+	bulletItem    = regexp.MustCompile(`^\s*[-*+]\s+(.+)$`)
+	stepItem      = regexp.MustCompile(`(?i)^\s*step\s+\d+`)
+	planHeading   = regexp.MustCompile(`(?i)\b(plan|steps|approach|todo|roadmap)\b`)
+
+	// "step 3 done", "completed step 2", "✅ 4", "[x] step 1"
+	stepMarker = regexp.MustCompile(`(?i)(?:step\s*#?(\d+)\s*(?:is\s+|has\s+been\s+)?(?:done|complete|completed|finished)|(?:completed|finished|done with)\s+step\s*#?(\d+)|(?:✅|✓|✔)\s*(?:step\s*)?#?(\d+)\b|\[x\]\s*step\s*#?(\d+))`)
+	doneWords  = regexp.MustCompile(`(?i)(✅|✓|✔|\bdone\b|\bcompleted?\b|\bfinished\b)`)
+	wordRe     = regexp.MustCompile(`[\p{L}\p{N}_./-]+`)
+)
+
+type planCandidate struct {
+	kind   string // numbered, checklist, bullet
+	title  string
+	marked bool // Under a plan heading, or items that say they are steps
+	steps  []PlanStep
+}
+
+// ExtractPlan finds a plan in a response: a list introduced by a plan
+// heading ("## Plan", "Here's the plan:", "**Steps**"), a markdown
+// checklist, or items written as "Step 1: ...". Other lists, such as a
+// numbered explanation, are not plans. Lists need at least two items. Code
+// fences are ignored. The longest candidate wins, preferring checklists and
+// numbered lists.
+func ExtractPlan(text string) (*Plan, bool) {
+	var (
+		cands   []planCandidate
+		cur     *planCandidate
+		heading string
+		inFence bool
+	)
+
+	flush := func() {
+		if cur != nil && len(cur.steps) >= 2 && cur.marked {
+			cands = append(cands, *cur)
+		}
+		cur = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			flush()
+			continue
+		}
+		if inFence {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue // Blank lines may separate items of one list
+		}
+		indented := len(line)-len(strings.TrimLeft(line, " \t")) >= 2
+
+		kind, item, done := "", "", false
+		if m := checklistItem.FindStringSubmatch(line); m != nil {
+			kind, item, done = "checklist", m[2], m[1] != " "
+		} else if m := numberedItem.FindStringSubmatch(line); m != nil {
+			kind, item = "numbered", m[2]
+		} else if m := bulletItem.FindStringSubmatch(line); m != nil {
+			kind, item = "bullet", m[1]
+		}
+
+		switch {
+		case kind == "":
+			flush()
+			heading = trimmed
+		case cur != nil && indented && kind != cur.kind:
+			// Sub-bullet of the current step; not a step of its own.
+		case cur != nil && kind == cur.kind:
+			cur.steps = append(cur.steps, PlanStep{Text: cleanStep(item), Done: done})
+		default:
+			if cur != nil && indented {
+				continue
+			}
+			flush()
+			cur = &planCandidate{kind: kind}
+			if isPlanHeading(heading) {
+				cur.title, cur.marked = cleanTitle(heading), true
+			}
+			if kind == "checklist" || stepItem.MatchString(line) {
+				cur.marked = true
+			}
+			cur.steps = append(cur.steps, PlanStep{Text: cleanStep(item), Done: done})
+		}
+	}
+	flush()
+
+	var best *planCandidate
+	rank := map[string]int{"checklist": 2, "numbered": 2, "bullet": 1}
+	for i := range cands {
+		c := &cands[i]
+		if best == nil || rank[c.kind] > rank[best.kind] ||
+			(rank[c.kind] == rank[best.kind] && len(c.steps) > len(best.steps)) {
+			best = c
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return &Plan{Title: best.title, Steps: best.steps}, true
+}
+
+// isPlanHeading reports whether line introduces a plan: a markdown
+// heading, a bold line or a line ending in a colon that names one.
+func isPlanHeading(line string) bool {
+	s := strings.TrimSpace(line)
+	marked := strings.HasPrefix(s, "#") ||
+		strings.HasSuffix(strings.TrimRight(s, "*_ "), ":") ||
+		(len(s) > 4 && strings.HasPrefix(s, "**") && strings.HasSuffix(s, "**"))
+	return marked && planHeading.MatchString(s)
+}
+
+func cleanStep(s string) string {
+	s = strings.ReplaceAll(s, "**", "")
+	s = strings.ReplaceAll(s, "__", "")
+	return strings.TrimSpace(s)
+}
+
+func cleanTitle(s string) string {
+	s = strings.TrimLeft(s, "# ")
+	s = cleanStep(s)
+	return strings.TrimSuffix(s, ":")
+}
+
+// Current returns the index of the first unfinished step, or -1.
+func (p *Plan) Current() int {
+	for i, s := range p.Steps {
+		if !s.Done {
+			return i
+		}
+	}
+	return -1
+}
+
+// Toggle flips a step's done state.
+func (p *Plan) Toggle(i int) {
+	if i >= 0 && i < len(p.Steps) {
+		p.Steps[i].Done = !p.Steps[i].Done
+	}
+}
+
+// MarkFromText checks off steps referenced by completion markers, either by
+// number ("step 2 done", "✅ 3") or by a line that says done and fuzzily
+// matches a step's text. It returns the indices newly marked.
+func (p *Plan) MarkFromText(text string) []int {
+	var marked []int
+	mark := func(i int) {
+		if i >= 0 && i < len(p.Steps) && !p.Steps[i].Done {
+			p.Steps[i].Done = true
+			marked = append(marked, i)
+		}
+	}
+
+	for _, m := range stepMarker.FindAllStringSubmatch(text, -1) {
+		for _, g := range m[1:] {
+			if n, err := strconv.Atoi(g); err == nil {
+				mark(n - 1)
+			}
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if !doneWords.MatchString(line) {
+			continue
+		}
+		words := significantWords(line)
+		for i, s := range p.Steps {
+			if !s.Done && overlap(significantWords(s.Text), words) >= 0.6 {
+				mark(i)
+			}
+		}
+	}
+	return marked
+}
+
+// MarkToolCall checks off the current step when a tool call touches a path
+// the step mentions (e.g. "Create deployment.yaml" and a write to it).
+func (p *Plan) MarkToolCall(tool string, args string) bool {
+	cur := p.Current()
+	if cur == -1 {
+		return false
+	}
+	argWords := significantWords(args)
+	for w := range significantWords(p.Steps[cur].Text) {
+		if strings.ContainsAny(w, "./") && argWords[w] {
+			p.Steps[cur].Done = true
+			return true
+		}
+	}
+	return false
+}
+
+// Summary is the compact progress block included in prompts.
+func (p *Plan) Summary() string {
+	done := 0
+	for _, s := range p.Steps {
+		if s.Done {
+			done++
+		}
+	}
+	var sb strings.Builder
+	title := p.Title
+	if title == "" {
+		title = "Plan"
+	}
+	fmt.Fprintf(&sb, "%s (%d/%d done). Work on the current step (>) and say \"step N done\" when it is finished.\n", title, done, len(p.Steps))
+	cur := p.Current()
+	for i, s := range p.Steps {
+		mark := "[ ]"
+		switch {
+		case s.Done:
+			mark = "[x]"
+		case i == cur:
+			mark = "[>]"
+		}
+		text := s.Text
+		if len(text) > 80 {
+			text = text[:77] + "..."
+		}
+		fmt.Fprintf(&sb, "%s %d. %s\n", mark, i+1, text)
+	}
+	return sb.String()
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "then": true, "step": true, "done": true, "completed": true, "complete": true, "finished": true,
+}
+
+func significantWords(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range wordRe.FindAllString(strings.ToLower(s), -1) {
+		w = strings.Trim(w, "./-")
+		if len(w) >= 3 && !stopWords[w] {
+			out[w] = true
+		}
+	}
+	return out
+}
+
+// overlap is the fraction of step words present in the candidate line.
+func overlap(step, line map[string]bool) float64 {
+	if len(step) == 0 {
+		return 0
+	}
+	n := 0
+	for w := range step {
+		if line[w] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(step))
+}
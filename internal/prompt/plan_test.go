@@ -0,0 +1,142 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractPlan(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		title string
+		steps []string
+		done  []bool
+	}{
+		{
+			name:  "numbered",
+			in:    "Here's the plan:\n\n1. Create deployment.yaml\n2. Add a **service** definition\n3) Apply with kubectl\n\nLet me start.",
+			title: "Here's the plan",
+			steps: []string{"Create deployment.yaml", "Add a service definition", "Apply with kubectl"},
+		},
+		{
+			name:  "numbered with sub-bullets",
+			in:    "**Plan**\n1. Set up module\n   - run go mod init\n   - add deps\n2. Write handler\n3. Add tests",
+			steps: []string{"Set up module", "Write handler", "Add tests"},
+		},
+		{
+			name:  "step prefix",
+			in:    "Step 1: Read config\nStep 2: Validate keys",
+			steps: []string{"Read config", "Validate keys"},
+		},
+		{
+			name:  "bulleted under plan heading",
+			in:    "## Plan\n- Back up the database\n- Run the migration\n* Verify row counts",
+			title: "Plan",
+			steps: []string{"Back up the database", "Run the migration", "Verify row counts"},
+		},
+		{
+			name:  "markdown checklist",
+			in:    "TODO:\n- [x] Write parser\n- [ ] Write tests\n- [ ] Ship it",
+			title: "TODO",
+			steps: []string{"Write parser", "Write tests", "Ship it"},
+			done:  []bool{true, false, false},
+		},
+		{
+			name:  "numbered beats bullets",
+			in:    "Steps:\n- a note\n- another note\n\nThe plan:\n1. First\n2. Second",
+			steps: []string{"First", "Second"},
+		},
+		{
+			name:  "ignores code fences",
+			in:    "```\n1. not a plan\n2. still code\n```\nDone.",
+			steps: nil,
+		},
+		{
+			name:  "plain bullets are not a plan",
+			in:    "Some facts:\n- Go is fast\n- Rust is safe",
+			steps: nil,
+		},
+		{
+			name:  "numbered list without a plan heading is not a plan",
+			in:    "The handler does three things:\n1. Parses the body\n2. Validates it\n3. Saves it",
+			steps: nil,
+		},
+		{
+			name:  "plan word in prose is not a heading",
+			in:    "I looked at how the plan is stored and found two issues.\n1. The file is not locked\n2. Writes are not atomic",
+			steps: nil,
+		},
+		{
+			name:  "single item is not a plan",
+			in:    "1. Only one",
+			steps: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, ok := ExtractPlan(tt.in)
+			if tt.steps == nil {
+				if ok {
+					t.Fatalf("expected no plan, got %+v", plan)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected a plan")
+			}
+			if tt.title != "" && plan.Title != tt.title {
+				t.Errorf("title = %q, want %q", plan.Title, tt.title)
+			}
+			var got []string
+			for _, s := range plan.Steps {
+				got = append(got, s.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.steps, "|") {
+				t.Fatalf("steps = %q, want %q", got, tt.steps)
+			}
+			for i, d := range tt.done {
+				if plan.Steps[i].Done != d {
+					t.Errorf("step %d done = %v, want %v", i+1, plan.Steps[i].Done, d)
+				}
+			}
+		})
+	}
+}
+
+func TestPlan_Marking(t *testing.T) {
+	plan, _ := ExtractPlan("## Plan\n1. Create deployment.yaml\n2. Add service definition\n3. Apply the manifests with kubectl\n4. Verify pods")
+
+	if got := plan.MarkFromText("Step 1 done, moving on."); len(got) != 1 || got[0] != 0 {
+		t.Fatalf("numbered marker: got %v", got)
+	}
+	if plan.Current() != 1 {
+		t.Fatalf("current = %d, want 1", plan.Current())
+	}
+	if plan.MarkToolCall("sys_write_file", `{"path":"svc.yaml"}`) || plan.Steps[1].Done {
+		t.Fatal("unrelated tool call should not mark a step")
+	}
+	plan.MarkFromText("✅ 2")
+	if got := plan.MarkFromText("Applied the manifests with kubectl — done."); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("fuzzy marker: got %v", got)
+	}
+
+	plan.Toggle(3)
+	if plan.Current() != -1 {
+		t.Fatal("all steps should be done")
+	}
+	if s := plan.Summary(); !strings.Contains(s, "(4/4 done)") || !strings.Contains(s, "[x] 4. Verify pods") {
+		t.Fatalf("unexpected summary:\n%s", s)
+	}
+}
+
+func TestPlan_MarkToolCall(t *testing.T) {
+	plan, _ := ExtractPlan("Plan:\n1. Create deployment.yaml\n2. Apply it")
+	if !plan.MarkToolCall("sys_write_file", `{"path":"deployment.yaml","content":"..."}`) {
+		t.Fatal("tool call touching the step's file should mark it")
+	}
+	if plan.Current() != 1 {
+		t.Fatalf("current = %d, want 1", plan.Current())
+	}
+}
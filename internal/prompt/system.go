@@ -4,19 +4,36 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	vcontext "github.com/nathfavour/vibeauracle/context"
 	"github.com/nathfavour/vibeauracle/sys"
 )
 
+// pruneAtPercent is the share of the model's context window past which
+// Build prunes the memory's context window.
+const pruneAtPercent = 90
+
 // System is the modular prompt engine: classify → layer instructions → build prompt → parse response.
 type System struct {
+	mu          sync.Mutex // Guards cfg, recommender and recoUsed
 	cfg         *sys.Config
-	memory      Memory
 	recommender Recommender
+	memory      Memory
+	pins        PinSource
+	plan        PlanSource
+	failures    FailureSource
+	threads     ThreadSource
+	history     HistorySource
 
 	// Budgeting to avoid unintended spend.
 	recoUsed int
+
+	treeMu      sync.Mutex
+	tree        string // Last rendered project tree
+	treeRoot    string // Directory tree was rendered for; "" when invalid
+	treeWatched string // Directory a watcher keeps tree current for
 }
 
 func New(cfg *sys.Config, memory Memory, recommender Recommender) *System {
@@ -25,15 +42,74 @@ func New(cfg *sys.Config, memory Memory, recommender Recommender) *System {
 
 // SetRecommender updates the active recommender.
 func (s *System) SetRecommender(r Recommender) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.recommender = r
 }
 
+// SetConfig switches to cfg for the next build. cfg is not changed in
+// place after this; a new config is passed instead.
+func (s *System) SetConfig(cfg *sys.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+func (s *System) config() *sys.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// SetPins wires the source of pinned file content.
+func (s *System) SetPins(p PinSource) {
+	s.pins = p
+}
+
+// SetPlan wires the source of the active plan's progress summary.
+func (s *System) SetPlan(p PlanSource) {
+	s.plan = p
+}
+
+// SetFailures wires the source of recent failing calls.
+func (s *System) SetFailures(f FailureSource) {
+	s.failures = f
+}
+
+// SetThreads wires the source of earlier threads, included when
+// prompt.include_recent_threads is on.
+func (s *System) SetThreads(t ThreadSource) {
+	s.threads = t
+}
+
+// SetHistory wires the source of the session's earlier messages, counted
+// in the prompt's usage since they are sent with it.
+func (s *System) SetHistory(h HistorySource) {
+	s.history = h
+}
+
+type sessionKey struct{}
+
+// WithSession tags ctx with the chat session a prompt is built for, so the
+// EARLIER THREADS section comes from that session.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
 // Build produces the prompt envelope for a user input.
 func (s *System) Build(ctx context.Context, userText string, snapshot sys.Snapshot, toolDefs string) (Envelope, []Recommendation, error) {
+	return s.BuildWithAttachments(ctx, userText, "", snapshot, toolDefs)
+}
+
+// BuildWithAttachments is Build with content the user attached to this
+// message, such as files named by #path tags, placed before the prompt.
+// Attachments do not affect intent classification or recall.
+func (s *System) BuildWithAttachments(ctx context.Context, userText string, attachments string, snapshot sys.Snapshot, toolDefs string) (Envelope, []Recommendation, error) {
+	cfg := s.config()
 	intent := ClassifyIntent(userText)
-	if s.cfg != nil && s.cfg.Prompt.Mode != "" {
+	if cfg != nil && cfg.Prompt.Mode != "" {
 		// Config can force a mode. "auto" keeps classification.
-		mode := strings.ToLower(strings.TrimSpace(s.cfg.Prompt.Mode))
+		mode := strings.ToLower(strings.TrimSpace(cfg.Prompt.Mode))
 		switch mode {
 		case "auto":
 			// keep
@@ -54,17 +130,43 @@ func (s *System) Build(ctx context.Context, userText string, snapshot sys.Snapsh
 
 	// Learning layer: cheap recall injection.
 	var recall string
-	if s.cfg != nil && s.cfg.Prompt.LearningEnabled && s.memory != nil {
+	if cfg != nil && cfg.Prompt.LearningEnabled && s.memory != nil {
 		snips, _ := s.memory.Recall(userText)
 		if len(snips) > 0 {
 			recall = strings.Join(snips, "\n")
 		}
 	}
 
-	prompt := s.compose(intent, instructions, recall, snapshot, toolDefs, userText)
+	// Threads from earlier runs carry the session across restarts.
+	if sessionID, _ := ctx.Value(sessionKey{}).(string); sessionID != "" && s.threads != nil && cfg != nil && cfg.Prompt.IncludeRecentThreads {
+		if earlier := s.threads.ThreadContext(sessionID); earlier != "" {
+			recall = strings.TrimSpace("EARLIER THREADS (this session, previous runs):\n" + earlier + "\n" + recall)
+		}
+	}
+
+	// Pinned files are read fresh on every build so edits are always visible.
+	var pinned string
+	if s.pins != nil {
+		pinned = s.pins.PinnedContext()
+	}
+
+	var plan string
+	if s.plan != nil {
+		plan = s.plan.PlanContext()
+	}
+
+	var failures string
+	if s.failures != nil {
+		failures = s.failures.FailureContext(userText)
+	}
+
+	tree := s.projectTree(snapshot.WorkingDir)
+
+	system := s.composeSystem(instructions, snapshot, toolDefs)
+	prompt := s.compose(pinned, attachments, plan, failures, recall, tree, snapshot, userText)
 
 	// Learning write-back: store a compact behavioral signal for future recall.
-	if s.cfg != nil && s.cfg.Prompt.LearningEnabled && s.memory != nil {
+	if cfg != nil && cfg.Prompt.LearningEnabled && s.memory != nil {
 		compact := userText
 		if len(compact) > 160 {
 			compact = compact[:160]
@@ -72,6 +174,21 @@ func (s *System) Build(ctx context.Context, userText string, snapshot sys.Snapsh
 		_ = s.memory.Store(fmt.Sprintf("prompt:%d", time.Now().UnixNano()), fmt.Sprintf("intent=%s text=%s", intent, compact))
 	}
 
+	var tok vcontext.EstimateTokens
+	usage := Usage{Tokens: tok.Count(system) + tok.Count(prompt)}
+	if sessionID, _ := ctx.Value(sessionKey{}).(string); sessionID != "" && s.history != nil {
+		usage.Tokens += s.history.HistoryTokens(sessionID)
+	}
+	if cfg != nil {
+		usage.Limit = cfg.Model.MaxContextTokens
+	}
+	if usage.Percent() > pruneAtPercent {
+		// Free the window of what the prompt is over by, so the next one fits.
+		if p, ok := s.memory.(WindowPruner); ok {
+			p.PruneWindow(usage.Tokens - usage.Limit*pruneAtPercent/100)
+		}
+	}
+
 	recs, err := s.maybeRecommend(ctx, intent, userText, snapshot.WorkingDir)
 	if err != nil {
 		// Recommendations are best-effort and must never fail the main prompt.
@@ -80,8 +197,10 @@ func (s *System) Build(ctx context.Context, userText string, snapshot sys.Snapsh
 
 	return Envelope{
 		Intent:       intent,
+		System:       system,
 		Prompt:       prompt,
 		Instructions: instructions,
+		Usage:        usage,
 		Metadata: map[string]any{
 			"working_dir": snapshot.WorkingDir,
 			"cpu":         snapshot.CPUUsage,
@@ -91,6 +210,7 @@ func (s *System) Build(ctx context.Context, userText string, snapshot sys.Snapsh
 }
 
 func (s *System) layers(intent Intent) []string {
+	cfg := s.config()
 	layers := []string{}
 
 	// Base system layer - ACTION FIRST
@@ -104,9 +224,9 @@ func (s *System) layers(intent Intent) []string {
 	layers = append(layers, "Tools may require explicit permissions; never request sensitive data unless necessary.")
 
 	// Project layer (configurable)
-	if s.cfg != nil {
-		if strings.TrimSpace(s.cfg.Prompt.ProjectInstructions) != "" {
-			layers = append(layers, s.cfg.Prompt.ProjectInstructions)
+	if cfg != nil {
+		if strings.TrimSpace(cfg.Prompt.ProjectInstructions) != "" {
+			layers = append(layers, cfg.Prompt.ProjectInstructions)
 		}
 	}
 
@@ -115,7 +235,7 @@ func (s *System) layers(intent Intent) []string {
 	case IntentAsk:
 		layers = append(layers, "MODE=ASK. Answer clearly and concisely. Keep it brief.")
 	case IntentPlan:
-		layers = append(layers, "MODE=PLAN. Provide a structured plan. No fluff.")
+		layers = append(layers, "MODE=PLAN. Provide a structured plan as a numbered list under a \"## Plan\" heading. No fluff.")
 	case IntentCRUD:
 		layers = append(layers, "MODE=CRUD. Execute file/code changes immediately. No narration.")
 	default:
@@ -125,7 +245,9 @@ func (s *System) layers(intent Intent) []string {
 	return layers
 }
 
-func (s *System) compose(intent Intent, layers []string, recall string, snapshot sys.Snapshot, toolDefs string, userText string) string {
+// composeSystem renders the instruction layers and tool usage rules, sent
+// to the model as the system message.
+func (s *System) composeSystem(layers []string, snapshot sys.Snapshot, toolDefs string) string {
 	b := strings.Builder{}
 	b.WriteString("SYSTEM INSTRUCTIONS:\n")
 	for _, l := range layers {
@@ -134,15 +256,6 @@ func (s *System) compose(intent Intent, layers []string, recall string, snapshot
 		b.WriteString("\n")
 	}
 
-	if strings.TrimSpace(recall) != "" {
-		b.WriteString("\nLEARNING/RECALL (local):\n")
-		b.WriteString(recall)
-		b.WriteString("\n")
-	}
-
-	b.WriteString("\nSYSTEM SNAPSHOT:\n")
-	b.WriteString(fmt.Sprintf("CWD: %s\nCPU: %.2f%%\nMEM: %.2f%%\n", snapshot.WorkingDir, snapshot.CPUUsage, snapshot.MemoryUsage))
-
 	if strings.TrimSpace(toolDefs) != "" {
 		b.WriteString("\nAVAILABLE TOOLS:\n")
 		b.WriteString(toolDefs)
@@ -160,6 +273,11 @@ EXAMPLE - To create a file:
 {"tool": "sys_write_file", "parameters": {"path": "deployment.yaml", "content": "apiVersion: apps/v1\nkind: Deployment..."}}
 ` + "```" + `
 
+EXAMPLE - To change part of an existing file (prefer this over rewriting it; "diff" with a unified diff also works, and sys_patch applies one diff across several files):
+` + "```json" + `
+{"tool": "sys_patch_file", "parameters": {"path": "main.go", "hunks": [{"search": "\tport := 8080\n", "replace": "\tport := cfg.Port\n"}]}}
+` + "```" + `
+
 EXAMPLE - To read a file:
 ` + "```json" + `
 {"tool": "sys_read_file", "parameters": {"path": "README.md"}}
@@ -173,10 +291,53 @@ CRITICAL RULES:
 5. If the user asks you to create/modify/read files, you MUST output a tool call IMMEDIATELY.
 6. After the tool executes, report the result in ONE sentence maximum.
 7. Current working directory is: ` + snapshot.WorkingDir + `
+8. File tools only reach the working directory unless the user allowed more; for a path outside it, call sys_request_access first.
 
 `)
 	}
 
+	return b.String()
+}
+
+// compose renders the per-request context and the user's text, sent as the
+// user message.
+func (s *System) compose(pinned string, attachments string, plan string, failures string, recall string, tree string, snapshot sys.Snapshot, userText string) string {
+	b := strings.Builder{}
+	if strings.TrimSpace(pinned) != "" {
+		b.WriteString("PINNED FILES:\n")
+		b.WriteString(pinned)
+		b.WriteString("\n")
+	}
+
+	if strings.TrimSpace(attachments) != "" {
+		b.WriteString("\nATTACHED BY THE USER (#tags in the prompt):\n")
+		b.WriteString(attachments)
+	}
+
+	if strings.TrimSpace(plan) != "" {
+		b.WriteString("\nPLAN PROGRESS:\n")
+		b.WriteString(plan)
+	}
+
+	if strings.TrimSpace(failures) != "" {
+		b.WriteString("\nRECENT FAILURES (do not repeat without changing approach):\n")
+		b.WriteString(failures)
+	}
+
+	if strings.TrimSpace(recall) != "" {
+		b.WriteString("\nLEARNING/RECALL (local):\n")
+		b.WriteString(recall)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nSYSTEM SNAPSHOT:\n")
+	b.WriteString(fmt.Sprintf("CWD: %s\nCPU: %.2f%%\nMEM: %.2f%%\n", snapshot.WorkingDir, snapshot.CPUUsage, snapshot.MemoryUsage))
+
+	if strings.TrimSpace(tree) != "" {
+		b.WriteString("\nPROJECT TREE (paths relative to CWD):\n")
+		b.WriteString(tree)
+	}
+
 	b.WriteString("\nUSER PROMPT:\n")
 	b.WriteString(userText)
 	b.WriteString("\n")
@@ -185,13 +346,8 @@ CRITICAL RULES:
 }
 
 func (s *System) maybeRecommend(ctx context.Context, intent Intent, userText string, wd string) ([]Recommendation, error) {
-	if s.cfg == nil || !s.cfg.Prompt.RecommendationsEnabled {
-		return nil, nil
-	}
-	if s.recommender == nil {
-		return nil, nil
-	}
-	if s.cfg.Prompt.RecommendationsMaxPerRun > 0 && s.recoUsed >= s.cfg.Prompt.RecommendationsMaxPerRun {
+	cfg := s.config()
+	if cfg == nil || !cfg.Prompt.RecommendationsEnabled {
 		return nil, nil
 	}
 
@@ -201,7 +357,7 @@ func (s *System) maybeRecommend(ctx context.Context, intent Intent, userText str
 	}
 
 	// Sampling: keep this extremely low by default.
-	prob := s.cfg.Prompt.RecommendationsSampleRate
+	prob := cfg.Prompt.RecommendationsSampleRate
 	if prob <= 0 {
 		prob = 0.05
 	}
@@ -210,6 +366,13 @@ func (s *System) maybeRecommend(ctx context.Context, intent Intent, userText str
 		return nil, nil
 	}
 
+	s.mu.Lock()
+	r := s.recommender
+	if r == nil || (cfg.Prompt.RecommendationsMaxPerRun > 0 && s.recoUsed >= cfg.Prompt.RecommendationsMaxPerRun) {
+		s.mu.Unlock()
+		return nil, nil
+	}
 	s.recoUsed++
-	return s.recommender.Recommend(ctx, RecommendInput{Intent: intent, UserText: userText, WorkingDir: wd, Time: time.Now()})
+	s.mu.Unlock()
+	return r.Recommend(ctx, RecommendInput{Intent: intent, UserText: userText, WorkingDir: wd, Time: time.Now()})
 }
@@ -2,8 +2,10 @@ package prompt
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	vcontext "github.com/nathfavour/vibeauracle/context"
 	"github.com/nathfavour/vibeauracle/sys"
 )
 
@@ -42,3 +44,125 @@ func TestParseModelResponse_CodeFence(t *testing.T) {
 		t.Fatalf("expected middle part to be go code")
 	}
 }
+
+type pinStub struct{}
+
+func (pinStub) PinnedContext() string { return "--- main.go ---\npackage main\n" }
+
+func TestBuild_PinnedBeforeRecall(t *testing.T) {
+	cfg := sys.Config{}
+	cfg.Prompt.LearningEnabled = true
+
+	s := New(&cfg, &memStub{}, &NoopRecommender{})
+	s.SetPins(pinStub{})
+	env, _, err := s.Build(context.Background(), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	pinned := strings.Index(env.Prompt, "PINNED FILES:")
+	recall := strings.Index(env.Prompt, "LEARNING/RECALL")
+	if pinned == -1 || recall == -1 || pinned > recall {
+		t.Fatalf("expected PINNED FILES ahead of recall, got:\n%s", env.Prompt)
+	}
+}
+
+type threadStub struct{}
+
+func (threadStub) ThreadContext(sessionID string) string {
+	if sessionID != "proj" {
+		return ""
+	}
+	return "- [2024-05-01 12:00] add a flag → Added --verbose.\n"
+}
+
+func TestBuild_EarlierThreadsInRecall(t *testing.T) {
+	cfg := sys.Config{}
+	cfg.Prompt.LearningEnabled = true
+	cfg.Prompt.IncludeRecentThreads = true
+
+	s := New(&cfg, &memStub{}, &NoopRecommender{})
+	s.SetThreads(threadStub{})
+	env, _, _ := s.Build(WithSession(context.Background(), "proj"), "now test it", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	threads := strings.Index(env.Prompt, "EARLIER THREADS")
+	hint := strings.Index(env.Prompt, "previous hint")
+	if threads == -1 || hint < threads || !strings.Contains(env.Prompt, "add a flag → Added --verbose.") {
+		t.Fatalf("expected the earlier threads in the recall section, got:\n%s", env.Prompt)
+	}
+
+	env, _, _ = s.Build(WithSession(context.Background(), "run-1"), "now test it", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if strings.Contains(env.Prompt, "EARLIER THREADS") {
+		t.Error("expected no section for a session without earlier threads")
+	}
+	cfg.Prompt.IncludeRecentThreads = false
+	env, _, _ = s.Build(WithSession(context.Background(), "proj"), "now test it", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if strings.Contains(env.Prompt, "EARLIER THREADS") {
+		t.Error("expected prompt.include_recent_threads off to leave them out")
+	}
+}
+
+func TestBuild_SystemInstructionsSeparate(t *testing.T) {
+	s := New(&sys.Config{}, &memStub{}, &NoopRecommender{})
+	env, _, err := s.Build(context.Background(), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "## Tool: sys_read_file\n")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(env.System, "SYSTEM INSTRUCTIONS:") || !strings.Contains(env.System, "AVAILABLE TOOLS:") {
+		t.Fatalf("expected instructions and tools in the system message, got:\n%s", env.System)
+	}
+	if strings.Contains(env.Prompt, "SYSTEM INSTRUCTIONS:") || !strings.Contains(env.Prompt, "USER PROMPT:\nfix the bug in main") {
+		t.Fatalf("expected only context and the user's text in the prompt, got:\n%s", env.Prompt)
+	}
+}
+
+type pruningMemStub struct {
+	memStub
+	pruned int
+}
+
+func (m *pruningMemStub) PruneWindow(tokens int) int {
+	m.pruned += tokens
+	return tokens
+}
+
+type historyStub map[string]int
+
+func (h historyStub) HistoryTokens(sessionID string) int { return h[sessionID] }
+
+func TestBuild_UsageCountsSessionHistory(t *testing.T) {
+	s := New(&sys.Config{}, &memStub{}, &NoopRecommender{})
+	s.SetHistory(historyStub{"long": 5000})
+
+	bare, _, _ := s.Build(WithSession(context.Background(), "new"), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	long, _, _ := s.Build(WithSession(context.Background(), "long"), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if long.Usage.Tokens != bare.Usage.Tokens+5000 {
+		t.Errorf("expected the session's history added to the usage, got %d vs %d", long.Usage.Tokens, bare.Usage.Tokens)
+	}
+}
+
+func TestBuild_UsageAgainstContextLimit(t *testing.T) {
+	cfg := sys.Config{}
+	mem := &pruningMemStub{}
+	s := New(&cfg, mem, &NoopRecommender{})
+
+	env, _, err := s.Build(context.Background(), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var tok vcontext.EstimateTokens
+	want := tok.Count(env.System) + tok.Count(env.Prompt)
+	if env.Usage.Tokens != want || env.Usage.Percent() != -1 {
+		t.Fatalf("expected %d tokens and no limit, got %+v", want, env.Usage)
+	}
+
+	cfg.Model.MaxContextTokens = want * 10
+	env, _, _ = s.Build(context.Background(), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if p := env.Usage.Percent(); p < 9 || p > 10 || mem.pruned != 0 {
+		t.Fatalf("expected about 10%% used and nothing pruned, got %d%%, pruned %d", p, mem.pruned)
+	}
+
+	cfg.Model.MaxContextTokens = want
+	env, _, _ = s.Build(context.Background(), "fix the bug in main", sys.Snapshot{WorkingDir: "/tmp"}, "")
+	if env.Usage.Percent() < 90 || mem.pruned == 0 {
+		t.Fatalf("expected the window pruned near the limit, got %d%%, pruned %d", env.Usage.Percent(), mem.pruned)
+	}
+}
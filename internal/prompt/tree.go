@@ -0,0 +1,178 @@
+package prompt
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nathfavour/vibeauracle/watcher"
+)
+
+// Limits on the project tree added to each prompt.
+const (
+	treeDepth          = 3    // Directory levels below the working directory
+	defaultTreeEntries = 150  // Used when prompt.tree_max_entries is unset
+	defaultTreeTokens  = 8192 // Context budget assumed when prompt.context_tokens is unset
+	treeBudgetShare    = 8    // The tree may use 1/treeBudgetShare of the context budget
+)
+
+// AttachWatcher keeps the tree of root cached between builds, dropping it
+// whenever w, which must be watching root, reports a file or directory
+// being created, removed or renamed. Other trees, and all trees without a
+// watcher, are rendered afresh on every Build.
+func (s *System) AttachWatcher(w *watcher.Watcher, root string) {
+	s.treeMu.Lock()
+	s.treeWatched = root
+	s.treeRoot = ""
+	s.treeMu.Unlock()
+	w.SubscribeFunc(func(evt watcher.Event) {
+		switch evt.Type {
+		case watcher.EventCreate, watcher.EventRemove, watcher.EventRename:
+			s.InvalidateTree()
+		}
+	})
+}
+
+// InvalidateTree drops the cached project tree.
+func (s *System) InvalidateTree() {
+	s.treeMu.Lock()
+	defer s.treeMu.Unlock()
+	s.tree = ""
+	s.treeRoot = ""
+}
+
+// projectTree returns the tree of root for this build, from the cache when
+// a watcher keeps it current.
+func (s *System) projectTree(root string) string {
+	cfg := s.config()
+	if cfg == nil || !cfg.Prompt.IncludeTree || root == "" {
+		return ""
+	}
+	s.treeMu.Lock()
+	defer s.treeMu.Unlock()
+	if root == s.treeWatched && root == s.treeRoot {
+		return s.tree
+	}
+
+	maxEntries := cfg.Prompt.TreeMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultTreeEntries
+	}
+	budget := cfg.Prompt.ContextTokens
+	if budget <= 0 {
+		budget = defaultTreeTokens
+	}
+	s.tree = FitTree(root, maxEntries, budget/treeBudgetShare)
+	s.treeRoot = root
+	return s.tree
+}
+
+// FitTree renders the tree of root within roughly budget tokens, at four
+// bytes a token. A tree over budget is rendered again one level shallower,
+// down to the top level, which is cut at a line boundary if it still does
+// not fit.
+func FitTree(root string, maxEntries, budget int) string {
+	maxBytes := budget * 4
+	var tree string
+	for depth := treeDepth; depth >= 1; depth-- {
+		tree = RenderTree(root, depth, maxEntries)
+		if len(tree) <= maxBytes {
+			return tree
+		}
+	}
+	cut := strings.LastIndexByte(tree[:maxBytes], '\n')
+	if cut < 0 {
+		return ""
+	}
+	return tree[:cut+1] + "…\n"
+}
+
+// RenderTree lists root breadth first, depth levels deep and at most
+// maxEntries files and directories, skipping what the watcher ignores.
+// Files are grouped per directory and extension, one line each, as in
+// "cmd/vibeaura/{chat,main,update}.go"; directories left unexpanded at the
+// depth limit end in "/…", and empty ones in "/". A final "…" marks a tree
+// cut short by maxEntries.
+func RenderTree(root string, depth, maxEntries int) string {
+	type dir struct {
+		abs, rel string
+		level    int
+	}
+	var (
+		sb      strings.Builder
+		entries int
+		queue   = []dir{{abs: root}}
+	)
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		list, err := os.ReadDir(d.abs)
+		if err != nil {
+			continue
+		}
+		prefix := ""
+		if d.rel != "" {
+			prefix = d.rel + "/"
+		}
+
+		// Files grouped by extension, then subdirectories.
+		groups := map[string][]string{}
+		var exts []string
+		var subdirs []string
+		for _, e := range list {
+			name := e.Name()
+			if watcher.Ignored(name) {
+				continue
+			}
+			if e.IsDir() {
+				subdirs = append(subdirs, name)
+				continue
+			}
+			ext := path.Ext(name)
+			if _, ok := groups[ext]; !ok {
+				exts = append(exts, ext)
+			}
+			groups[ext] = append(groups[ext], strings.TrimSuffix(name, ext))
+		}
+		if len(exts) == 0 && len(subdirs) == 0 && d.rel != "" {
+			sb.WriteString(prefix + "\n")
+			continue
+		}
+		sort.Strings(exts)
+
+		for _, ext := range exts {
+			names := groups[ext]
+			cut := len(names) > maxEntries-entries
+			if cut {
+				names = names[:maxEntries-entries]
+			}
+			entries += len(names)
+			switch len(names) {
+			case 0:
+			case 1:
+				sb.WriteString(prefix + names[0] + ext + "\n")
+			default:
+				sb.WriteString(prefix + "{" + strings.Join(names, ",") + "}" + ext + "\n")
+			}
+			if cut {
+				sb.WriteString("…\n")
+				return sb.String()
+			}
+		}
+		for _, name := range subdirs {
+			if entries == maxEntries {
+				sb.WriteString("…\n")
+				return sb.String()
+			}
+			entries++
+			if d.level+1 >= depth {
+				sb.WriteString(prefix + name + "/…\n")
+			} else {
+				queue = append(queue, dir{abs: filepath.Join(d.abs, name), rel: prefix + name, level: d.level + 1})
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func writeTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(root, filepath.FromSlash(f))
+		if strings.HasSuffix(f, "/") {
+			os.MkdirAll(p, 0o755)
+			continue
+		}
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestRenderTree_Compact(t *testing.T) {
+	root := writeTree(t,
+		"go.work", "README.md",
+		"cmd/vibeaura/main.go", "cmd/vibeaura/chat.go", "cmd/vibeaura/update.go", "cmd/vibeaura/go.mod",
+		"internal/brain/deep/er/x.go",
+		"docs/",
+		".git/HEAD", "node_modules/x/index.js", "debug.log",
+	)
+	got := RenderTree(root, 3, 100)
+	// Breadth first, so the empty docs/ comes before cmd's subdirectory.
+	want := strings.Join([]string{
+		"README.md",
+		"go.work",
+		"docs/",
+		"cmd/vibeaura/{chat,main,update}.go",
+		"cmd/vibeaura/go.mod",
+		"internal/brain/deep/…",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderTree_EntryLimit(t *testing.T) {
+	root := writeTree(t, "a.go", "b.go", "c.go", "d.txt")
+	got := RenderTree(root, 3, 2)
+	if got != "{a,b}.go\n…\n" {
+		t.Errorf("got %q", got)
+	}
+	if got := RenderTree(root, 3, 4); strings.Contains(got, "…") {
+		t.Errorf("a tree that fits exactly should not be marked as cut: %q", got)
+	}
+}
+
+func TestFitTree_TrimsDepthFirst(t *testing.T) {
+	root := writeTree(t, "top.go", "pkg/one/deep/a.go", "pkg/one/deep/b.go", "pkg/one/c.go")
+	full := RenderTree(root, 3, 100)
+	shallow := RenderTree(root, 1, 100)
+	if got := FitTree(root, 100, len(full)/4+1); got != full {
+		t.Errorf("expected the full tree within budget, got %q", got)
+	}
+	if got := FitTree(root, 100, len(shallow)/4+1); got != shallow {
+		t.Errorf("expected the top level only, got %q (want %q)", got, shallow)
+	}
+}
+
+func TestBuild_IncludesTree(t *testing.T) {
+	root := writeTree(t, "main.go")
+	cfg := sys.Config{}
+	cfg.Prompt.IncludeTree = true
+
+	s := New(&cfg, nil, &NoopRecommender{})
+	env, _, err := s.Build(context.Background(), "fix the build please", sys.Snapshot{WorkingDir: root}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(env.Prompt, "PROJECT TREE") || !strings.Contains(env.Prompt, "main.go") {
+		t.Errorf("expected the project tree in the prompt:\n%s", env.Prompt)
+	}
+
+	cfg.Prompt.IncludeTree = false
+	env, _, _ = s.Build(context.Background(), "fix the build please", sys.Snapshot{WorkingDir: root}, "")
+	if strings.Contains(env.Prompt, "PROJECT TREE") {
+		t.Error("expected no tree with prompt.include_tree off")
+	}
+}
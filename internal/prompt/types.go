@@ -16,12 +16,30 @@ const (
 	IntentChat Intent = "chat" // general conversation
 )
 
-// Envelope is the final payload sent to the model.
+// Envelope is the final payload sent to the model: System goes out as the
+// system message and Prompt as the user message.
 type Envelope struct {
 	Intent       Intent
+	System       string
 	Prompt       string
 	Instructions []string
 	Metadata     map[string]any
+	Usage        Usage // How much of the model's context window the request fills
+}
+
+// Usage is the estimated size of a request against the model's context
+// window: the built prompt plus the session's earlier messages.
+type Usage struct {
+	Tokens int // Estimated tokens of System, Prompt and the history
+	Limit  int // Model.MaxContextTokens; 0 when not configured
+}
+
+// Percent is Tokens as a percentage of Limit, or -1 when there is no limit.
+func (u Usage) Percent() int {
+	if u.Limit <= 0 {
+		return -1
+	}
+	return u.Tokens * 100 / u.Limit
 }
 
 // PartType is a parsed response segment kind.
@@ -78,3 +96,37 @@ type Memory interface {
 	Store(key string, value string) error
 	Recall(query string) ([]string, error)
 }
+
+// WindowPruner is a Memory that keeps a rolling context window. Build has
+// it evict items when a prompt nears the model's context limit.
+type WindowPruner interface {
+	PruneWindow(tokens int) int
+}
+
+// PinSource supplies the PINNED FILES section of a prompt.
+type PinSource interface {
+	PinnedContext() string
+}
+
+// PlanSource supplies the PLAN PROGRESS section of a prompt.
+type PlanSource interface {
+	PlanContext() string
+}
+
+// FailureSource supplies the RECENT FAILURES section of a prompt: past
+// failing calls relevant to the user's text.
+type FailureSource interface {
+	FailureContext(userText string) string
+}
+
+// HistorySource reports the estimated tokens of a session's earlier
+// messages, which are sent ahead of the prompt.
+type HistorySource interface {
+	HistoryTokens(sessionID string) int
+}
+
+// ThreadSource supplies the EARLIER THREADS section of a prompt: summaries
+// of a session's requests from previous runs.
+type ThreadSource interface {
+	ThreadContext(sessionID string) string
+}
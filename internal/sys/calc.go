@@ -0,0 +1,636 @@
+package sys
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Eval evaluates a calculator expression: arithmetic, unit and base
+// conversions ("14 MiB to bytes", "255 to hex") and date arithmetic
+// ("now + 3d"). It never shells out and never panics on bad input.
+func Eval(expr string) (string, error) {
+	return evalAt(expr, time.Now())
+}
+
+// ExpandSubstitutions replaces every $(expr) in text with its evaluated
+// result. Parentheses may nest inside the expression.
+func ExpandSubstitutions(text string) (string, error) {
+	return expandAt(text, time.Now())
+}
+
+func expandAt(text string, now time.Time) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(text, "$(")
+		if start == -1 {
+			sb.WriteString(text)
+			return sb.String(), nil
+		}
+		depth, end := 0, -1
+		for i := start + 1; i < len(text); i++ {
+			if text[i] == '(' {
+				depth++
+			} else if text[i] == ')' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end == -1 {
+			return "", fmt.Errorf("unclosed $( at position %d", start)
+		}
+		result, err := evalAt(text[start+2:end], now)
+		if err != nil {
+			return "", fmt.Errorf("$(%s): %w", text[start+2:end], err)
+		}
+		sb.WriteString(text[:start])
+		sb.WriteString(result)
+		text = text[end+1:]
+	}
+}
+
+func evalAt(expr string, now time.Time) (result string, err error) {
+	// The parser is written to return errors, but a calculator must never
+	// take the TUI down with it.
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = "", fmt.Errorf("internal error: %v", r)
+		}
+	}()
+
+	toks, err := tokenize(expr)
+	if err != nil {
+		return "", err
+	}
+	p := &calcParser{toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return "", fmt.Errorf("empty expression")
+	}
+	v, err := p.parseExpr()
+	if err != nil {
+		return "", err
+	}
+
+	target := ""
+	if t := p.peek(); t.kind == tokIdent && (t.text == "to" || t.text == "in" || t.text == "as") {
+		p.next()
+		t = p.next()
+		if t.kind != tokIdent {
+			return "", fmt.Errorf("expected a unit or base after %q at position %d", "to", t.pos)
+		}
+		target = t.text
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return "", fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return v.format(target)
+}
+
+// --- Values ---
+
+type valueKind int
+
+const (
+	valNumber valueKind = iota
+	valTime
+)
+
+type calcValue struct {
+	kind valueKind
+	num  float64
+	unit *calcUnit // nil for dimensionless numbers
+	t    time.Time
+}
+
+type calcUnit struct {
+	name   string
+	dim    string
+	factor float64 // multiply to reach the base unit of dim
+	offset float64 // for temperatures
+}
+
+func (u *calcUnit) toBase(v float64) float64   { return v*u.factor + u.offset }
+func (u *calcUnit) fromBase(v float64) float64 { return (v - u.offset) / u.factor }
+
+var calcUnits = map[string]*calcUnit{}
+
+func init() {
+	add := func(dim string, factor float64, names ...string) {
+		u := &calcUnit{name: names[0], dim: dim, factor: factor}
+		for _, n := range names {
+			calcUnits[n] = u
+		}
+	}
+	// Data (base: byte). Case matters: b is a bit, B a byte.
+	add("data", 1.0/8, "bit", "bits", "b")
+	add("data", 1, "B", "byte", "bytes")
+	add("data", 1e3, "KB", "kB")
+	add("data", 1e6, "MB")
+	add("data", 1e9, "GB")
+	add("data", 1e12, "TB")
+	add("data", 1<<10, "KiB")
+	add("data", 1<<20, "MiB")
+	add("data", 1<<30, "GiB")
+	add("data", 1<<40, "TiB")
+	// Length (base: metre).
+	add("length", 1e-3, "mm")
+	add("length", 1e-2, "cm")
+	add("length", 1, "m", "meter", "meters", "metre", "metres")
+	add("length", 1e3, "km")
+	add("length", 0.0254, "in", "inch", "inches")
+	add("length", 0.3048, "ft", "foot", "feet")
+	add("length", 0.9144, "yd", "yard", "yards")
+	add("length", 1609.344, "mi", "mile", "miles")
+	// Mass (base: gram).
+	add("mass", 1e-3, "mg")
+	add("mass", 1, "g", "gram", "grams")
+	add("mass", 1e3, "kg")
+	add("mass", 453.59237, "lb", "lbs")
+	add("mass", 28.349523125, "oz")
+	// Time (base: second). "m" is metres; use "min" for minutes.
+	add("time", 1e-3, "ms")
+	add("time", 1, "s", "sec", "secs", "second", "seconds")
+	add("time", 60, "min", "mins", "minute", "minutes")
+	add("time", 3600, "h", "hr", "hrs", "hour", "hours")
+	add("time", 86400, "d", "day", "days")
+	add("time", 7*86400, "w", "wk", "week", "weeks")
+	// Temperature (base: kelvin).
+	calcUnits["K"] = &calcUnit{name: "K", dim: "temperature", factor: 1}
+	calcUnits["C"] = &calcUnit{name: "°C", dim: "temperature", factor: 1, offset: 273.15}
+	calcUnits["F"] = &calcUnit{name: "°F", dim: "temperature", factor: 5.0 / 9, offset: 459.67 * 5 / 9}
+}
+
+func (v calcValue) format(target string) (string, error) {
+	if v.kind == valTime {
+		if target != "" {
+			return "", fmt.Errorf("cannot convert a date to %q", target)
+		}
+		return v.t.Format("2006-01-02 15:04:05 Mon"), nil
+	}
+
+	switch target {
+	case "":
+		if v.unit == nil {
+			return formatCalcNumber(v.num), nil
+		}
+		return formatCalcNumber(v.num) + " " + v.unit.name, nil
+	case "hex", "bin", "oct", "dec":
+		if v.unit != nil {
+			return "", fmt.Errorf("base conversion needs a plain number, got %s", v.unit.name)
+		}
+		if v.num != math.Trunc(v.num) || math.Abs(v.num) > math.MaxInt64/2 {
+			return "", fmt.Errorf("base conversion needs an integer, got %s", formatCalcNumber(v.num))
+		}
+		n := int64(v.num)
+		sign := ""
+		if n < 0 {
+			sign, n = "-", -n
+		}
+		switch target {
+		case "hex":
+			return sign + "0x" + strconv.FormatInt(n, 16), nil
+		case "bin":
+			return sign + "0b" + strconv.FormatInt(n, 2), nil
+		case "oct":
+			return sign + "0o" + strconv.FormatInt(n, 8), nil
+		}
+		return sign + strconv.FormatInt(n, 10), nil
+	}
+
+	to, ok := calcUnits[target]
+	if !ok {
+		return "", fmt.Errorf("unknown unit %q", target)
+	}
+	if v.unit == nil {
+		return "", fmt.Errorf("%s has no unit to convert to %s", formatCalcNumber(v.num), target)
+	}
+	if v.unit.dim != to.dim {
+		return "", fmt.Errorf("cannot convert %s (%s) to %s (%s)", v.unit.name, v.unit.dim, to.name, to.dim)
+	}
+	return formatCalcNumber(to.fromBase(v.unit.toBase(v.num))) + " " + to.name, nil
+}
+
+func formatCalcNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "∞"
+	case math.IsInf(f, -1):
+		return "-∞"
+	case f == math.Trunc(f) && math.Abs(f) < 1e15:
+		return strconv.FormatInt(int64(f), 10)
+	}
+	// 12 significant digits hides binary float noise (37*1.21 = 44.77).
+	return strconv.FormatFloat(f, 'g', 12, 64)
+}
+
+// --- Tokenizer ---
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type calcToken struct {
+	kind tokKind
+	text string
+	num  float64
+	pos  int
+}
+
+func tokenize(s string) ([]calcToken, error) {
+	var toks []calcToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			start := i
+			if r == '0' && i+1 < len(rs) && strings.ContainsRune("xXbBoO", rs[i+1]) &&
+				i+2 < len(rs) && isHexDigit(rs[i+2]) {
+				i += 2
+				for i < len(rs) && (isHexDigit(rs[i]) || rs[i] == '_') {
+					i++
+				}
+				n, err := strconv.ParseInt(string(rs[start:i]), 0, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid number %q at position %d", string(rs[start:i]), start)
+				}
+				toks = append(toks, calcToken{kind: tokNum, text: string(rs[start:i]), num: float64(n), pos: start})
+				continue
+			}
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.' || rs[i] == '_') {
+				i++
+			}
+			// Exponent, but not a unit starting with e.
+			if i+1 < len(rs) && (rs[i] == 'e' || rs[i] == 'E') &&
+				(unicode.IsDigit(rs[i+1]) || ((rs[i+1] == '-' || rs[i+1] == '+') && i+2 < len(rs) && unicode.IsDigit(rs[i+2]))) {
+				i += 2
+				for i < len(rs) && unicode.IsDigit(rs[i]) {
+					i++
+				}
+			}
+			text := strings.ReplaceAll(string(rs[start:i]), "_", "")
+			n, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", string(rs[start:i]), start)
+			}
+			toks = append(toks, calcToken{kind: tokNum, text: text, num: n, pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '°':
+			start := i
+			for i < len(rs) && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_' || rs[i] == '°') {
+				i++
+			}
+			text := strings.TrimPrefix(string(rs[start:i]), "°")
+			toks = append(toks, calcToken{kind: tokIdent, text: text, pos: start})
+		case strings.ContainsRune("+-*/%^(),×÷", r):
+			op := string(r)
+			if r == '*' && i+1 < len(rs) && rs[i+1] == '*' {
+				op = "^"
+				i++
+			}
+			switch r {
+			case '×':
+				op = "*"
+			case '÷':
+				op = "/"
+			}
+			toks = append(toks, calcToken{kind: tokOp, text: op, pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	return append(toks, calcToken{kind: tokEOF, pos: len(rs)}), nil
+}
+
+func isHexDigit(r rune) bool {
+	return unicode.IsDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// --- Parser ---
+
+// calcParser is a recursive-descent parser:
+//
+//	expr   = term { ("+"|"-") term }
+//	term   = unary { ("*"|"/"|"%") unary }
+//	unary  = "-" unary | power
+//	power  = atom [ "^" unary ]
+//	atom   = number [unit] | ident | ident "(" args ")" | "(" expr ")"
+type calcParser struct {
+	toks []calcToken
+	i    int
+	now  time.Time
+}
+
+func (p *calcParser) peek() calcToken { return p.toks[p.i] }
+
+func (p *calcParser) next() calcToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *calcParser) isOp(s string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == s
+}
+
+func (p *calcParser) parseExpr() (calcValue, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return left, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.next().text
+		right, err := p.parseTerm()
+		if err != nil {
+			return left, err
+		}
+		if left, err = addValues(left, right, op == "-"); err != nil {
+			return left, err
+		}
+	}
+	return left, nil
+}
+
+func (p *calcParser) parseTerm() (calcValue, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return left, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return left, err
+		}
+		if left, err = mulValues(left, right, op.text); err != nil {
+			return left, fmt.Errorf("%w (at position %d)", err, op.pos)
+		}
+	}
+	return left, nil
+}
+
+func (p *calcParser) parseUnary() (calcValue, error) {
+	if p.isOp("-") || p.isOp("+") {
+		neg := p.next().text == "-"
+		v, err := p.parseUnary()
+		if err != nil {
+			return v, err
+		}
+		if v.kind == valTime {
+			return v, fmt.Errorf("cannot negate a date")
+		}
+		if neg {
+			v.num = -v.num
+		}
+		return v, nil
+	}
+	return p.parsePower()
+}
+
+func (p *calcParser) parsePower() (calcValue, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return base, err
+	}
+	if !p.isOp("^") {
+		return base, nil
+	}
+	op := p.next()
+	exp, err := p.parseUnary()
+	if err != nil {
+		return base, err
+	}
+	if base.kind != valNumber || base.unit != nil || exp.kind != valNumber || exp.unit != nil {
+		return base, fmt.Errorf("^ needs plain numbers (at position %d)", op.pos)
+	}
+	base.num = math.Pow(base.num, exp.num)
+	return base, nil
+}
+
+func (p *calcParser) parseAtom() (calcValue, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		v := calcValue{num: t.num}
+		if u := p.peek(); u.kind == tokIdent {
+			if unit, ok := calcUnits[u.text]; ok {
+				p.next()
+				v.unit = unit
+			}
+		}
+		return v, nil
+	case tokOp:
+		if t.text != "(" {
+			return calcValue{}, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+		}
+		v, err := p.parseExpr()
+		if err != nil {
+			return v, err
+		}
+		if !p.isOp(")") {
+			return v, fmt.Errorf("missing ) for ( at position %d", t.pos)
+		}
+		p.next()
+		return v, nil
+	case tokIdent:
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		switch strings.ToLower(t.text) {
+		case "pi":
+			return calcValue{num: math.Pi}, nil
+		case "e":
+			return calcValue{num: math.E}, nil
+		case "now":
+			return calcValue{kind: valTime, t: p.now}, nil
+		case "today":
+			y, m, d := p.now.Date()
+			return calcValue{kind: valTime, t: time.Date(y, m, d, 0, 0, 0, 0, p.now.Location())}, nil
+		}
+		// A bare unit means one of it: "MiB to bytes".
+		if unit, ok := calcUnits[t.text]; ok {
+			return calcValue{num: 1, unit: unit}, nil
+		}
+		return calcValue{}, fmt.Errorf("unknown name %q at position %d", t.text, t.pos)
+	}
+	return calcValue{}, fmt.Errorf("unexpected end of expression")
+}
+
+func (p *calcParser) parseCall(name calcToken) (calcValue, error) {
+	p.next() // (
+	var args []calcValue
+	for !p.isOp(")") {
+		if len(args) > 0 {
+			if !p.isOp(",") {
+				return calcValue{}, fmt.Errorf("expected , or ) at position %d", p.peek().pos)
+			}
+			p.next()
+		}
+		v, err := p.parseExpr()
+		if err != nil {
+			return v, err
+		}
+		args = append(args, v)
+	}
+	p.next() // )
+
+	nums := make([]float64, len(args))
+	for i, a := range args {
+		if a.kind != valNumber || a.unit != nil {
+			return calcValue{}, fmt.Errorf("%s() takes plain numbers", name.text)
+		}
+		nums[i] = a.num
+	}
+	arity := func(n int) error {
+		if len(nums) != n {
+			return fmt.Errorf("%s() takes %d argument(s), got %d", name.text, n, len(nums))
+		}
+		return nil
+	}
+
+	unary := map[string]func(float64) float64{
+		"sqrt": math.Sqrt, "abs": math.Abs, "round": math.Round, "floor": math.Floor, "ceil": math.Ceil,
+		"ln": math.Log, "log": math.Log10, "log2": math.Log2, "sin": math.Sin, "cos": math.Cos, "tan": math.Tan,
+	}
+	fn := strings.ToLower(name.text)
+	if f, ok := unary[fn]; ok {
+		if err := arity(1); err != nil {
+			return calcValue{}, err
+		}
+		return calcValue{num: f(nums[0])}, nil
+	}
+
+	switch fn {
+	case "min", "max":
+		if len(nums) == 0 {
+			return calcValue{}, fmt.Errorf("%s() needs at least one argument", fn)
+		}
+		out := nums[0]
+		for _, n := range nums[1:] {
+			if fn == "min" {
+				out = math.Min(out, n)
+			} else {
+				out = math.Max(out, n)
+			}
+		}
+		return calcValue{num: out}, nil
+	case "date":
+		if err := arity(3); err != nil {
+			return calcValue{}, err
+		}
+		return calcValue{kind: valTime, t: time.Date(int(nums[0]), time.Month(int(nums[1])), int(nums[2]), 0, 0, 0, 0, p.now.Location())}, nil
+	}
+	return calcValue{}, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+}
+
+// --- Arithmetic ---
+
+func addValues(a, b calcValue, sub bool) (calcValue, error) {
+	sign := 1.0
+	if sub {
+		sign = -1
+	}
+
+	switch {
+	case a.kind == valTime && b.kind == valTime:
+		if !sub {
+			return a, fmt.Errorf("cannot add two dates")
+		}
+		secs := a.t.Sub(b.t).Seconds()
+		if math.Abs(secs) >= 86400 {
+			return calcValue{num: secs / 86400, unit: calcUnits["d"]}, nil
+		}
+		return calcValue{num: secs / 3600, unit: calcUnits["h"]}, nil
+	case a.kind == valTime:
+		if b.unit == nil || b.unit.dim != "time" {
+			return a, fmt.Errorf("add a duration to a date, e.g. now + 3d")
+		}
+		secs := sign * b.unit.toBase(b.num)
+		// Whole days move the calendar so DST shifts don't skew the clock.
+		if b.unit.factor >= 86400 && secs == math.Trunc(secs/86400)*86400 {
+			a.t = a.t.AddDate(0, 0, int(secs/86400))
+		} else {
+			a.t = a.t.Add(time.Duration(secs * float64(time.Second)))
+		}
+		return a, nil
+	case b.kind == valTime:
+		if sub {
+			return a, fmt.Errorf("cannot subtract a date from a duration")
+		}
+		return addValues(b, a, false)
+	}
+
+	switch {
+	case a.unit == nil && b.unit == nil, b.unit == nil:
+		a.num += sign * b.num
+	case a.unit == nil:
+		a.unit = b.unit
+		a.num += sign * b.num
+	case a.unit.dim != b.unit.dim:
+		return a, fmt.Errorf("cannot combine %s (%s) with %s (%s)", a.unit.name, a.unit.dim, b.unit.name, b.unit.dim)
+	default:
+		// Convert b into a's unit; for temperatures only the scale applies.
+		a.num += sign * b.num * b.unit.factor / a.unit.factor
+	}
+	return a, nil
+}
+
+func mulValues(a, b calcValue, op string) (calcValue, error) {
+	if a.kind == valTime || b.kind == valTime {
+		return a, fmt.Errorf("cannot use %s with a date", op)
+	}
+	if op == "%" {
+		if b.unit != nil {
+			return a, fmt.Errorf("modulo needs a plain number on the right")
+		}
+		if b.num == 0 {
+			return a, fmt.Errorf("modulo by zero")
+		}
+		a.num = math.Mod(a.num, b.num)
+		return a, nil
+	}
+
+	if op == "*" {
+		switch {
+		case a.unit != nil && b.unit != nil:
+			return a, fmt.Errorf("cannot multiply %s by %s", a.unit.name, b.unit.name)
+		case a.unit == nil:
+			a.unit = b.unit
+		}
+		a.num *= b.num
+		return a, nil
+	}
+
+	if b.num == 0 {
+		return a, fmt.Errorf("division by zero")
+	}
+	switch {
+	case b.unit == nil:
+		a.num /= b.num
+	case a.unit == nil:
+		return a, fmt.Errorf("cannot divide a number by %s", b.unit.name)
+	case a.unit.dim != b.unit.dim:
+		return a, fmt.Errorf("cannot divide %s by %s", a.unit.name, b.unit.name)
+	default:
+		a = calcValue{num: a.unit.toBase(a.num) / b.unit.toBase(b.num)}
+	}
+	return a, nil
+}
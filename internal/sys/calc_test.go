@@ -0,0 +1,93 @@
+package sys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEval(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"37*1.21", "44.77"},
+		{"1 + 2 * 3", "7"},
+		{"(1 + 2) * 3", "9"},
+		{"-2^2", "-4"},
+		{"2^10", "1024"},
+		{"2**3", "8"},
+		{"10 % 3", "1"},
+		{"7 / 2", "3.5"},
+		{"sqrt(16) + max(1, 5, 3)", "9"},
+		{"1_000_000 / 4", "250000"},
+		{"1.5e3", "1500"},
+		{"14 MiB to bytes", "14680064 B"},
+		{"1 GB in MB", "1000 MB"},
+		{"MiB to KiB", "1024 KiB"},
+		{"5 km to mi", "3.10685596119 mi"},
+		{"12 in to cm", "30.48 cm"},
+		{"100 F to C", "37.7777777778 °C"},
+		{"0 C to K", "273.15 K"},
+		{"1 h + 30 min to min", "90 min"},
+		{"2 GiB / 512 MiB", "4"},
+		{"255 to hex", "0xff"},
+		{"0xff to dec", "255"},
+		{"0b1010 + 1", "11"},
+		{"10 to bin", "0b1010"},
+		{"-8 to oct", "-0o10"},
+		{"now", "2026-03-10 14:30:00 Tue"},
+		{"now + 3d", "2026-03-13 14:30:00 Fri"},
+		{"today - 1w", "2026-03-03 00:00:00 Tue"},
+		{"now + 90 min", "2026-03-10 16:00:00 Tue"},
+		{"date(2026, 3, 20) - today", "10 d"},
+	}
+
+	for _, tt := range tests {
+		got, err := evalAt(tt.expr, now)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	bad := []string{
+		"", "1 +", "(1 + 2", "1 / 0", "5 % 0", "foo", "nope(1)", "sqrt(1, 2)",
+		"1 km + 1 kg", "3 kg to mi", "1.5 to hex", "now + now", "now * 2", "1 MiB * 1 MiB",
+		")(", "1 2", "$", "0x", "5 to furlongs", "now to hex", "date(1)", ",,,", "max()",
+	}
+	for _, expr := range bad {
+		if got, err := evalAt(expr, time.Now()); err == nil {
+			t.Errorf("%q: expected an error, got %q", expr, got)
+		}
+	}
+}
+
+func TestExpandSubstitutions(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	got, err := expandAt("allocate $(14 MiB to bytes) bytes, due $(now + 2d)", now)
+	if err != nil {
+		t.Fatalf("expand failed: %v", err)
+	}
+	want := "allocate 14680064 B bytes, due 2026-03-12 14:30:00 Thu"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if got, _ := expandAt("cost is $((2 + 3) * 4)", now); got != "cost is 20" {
+		t.Fatalf("nested parens: got %q", got)
+	}
+	if _, err := expandAt("broken $(1 +", now); err == nil {
+		t.Fatal("expected error for unclosed substitution")
+	}
+	if _, err := expandAt("bad $(rm -rf /)", now); err == nil {
+		t.Fatal("shell text must not evaluate")
+	}
+}
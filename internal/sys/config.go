@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -13,10 +14,31 @@ import (
 type Config struct {
 	Model struct {
 		Provider string `mapstructure:"provider"`
-		Endpoint string `mapstructure:"endpoint"`
 		Name     string `mapstructure:"name"`
+		// Fallbacks are tried in order when the provider fails, each as
+		// "provider" or "provider:model".
+		Fallbacks []string `mapstructure:"fallbacks"`
+		// DiscoveryTimeout bounds each provider's model listing.
+		DiscoveryTimeout time.Duration `mapstructure:"discovery_timeout"`
+		// CacheEnabled reuses responses to identical requests for CacheTTL.
+		CacheEnabled bool          `mapstructure:"cache_enabled"`
+		CacheTTL     time.Duration `mapstructure:"cache_ttl"`
+		// RequestTimeout bounds each request to the provider; MaxRetries
+		// is how often one failing with a network error or 5xx is retried.
+		RequestTimeout time.Duration `mapstructure:"request_timeout"`
+		MaxRetries     int           `mapstructure:"max_retries"`
+		// MaxContextTokens is the model's context window, in estimated
+		// tokens. The chat header shows how much of it a prompt fills.
+		MaxContextTokens int `mapstructure:"max_context_tokens"`
+		// RateLimit caps the requests per minute sent to a provider, keyed
+		// by provider name; providers not listed are not limited.
+		RateLimit map[string]int `mapstructure:"rate_limit"`
 	} `mapstructure:"model"`
 
+	// Providers holds per-provider settings keyed by provider name, e.g.
+	// providers.ollama.endpoint or providers.openai.base_url.
+	Providers map[string]ProviderConfig `mapstructure:"providers"`
+
 	Prompt struct {
 		Enabled                   bool    `mapstructure:"enabled"`
 		Mode                      string  `mapstructure:"mode"` // auto|ask|plan|crud
@@ -25,6 +47,11 @@ type Config struct {
 		RecommendationsEnabled    bool    `mapstructure:"recommendations_enabled"`
 		RecommendationsSampleRate float64 `mapstructure:"recommendations_sample_rate"`
 		RecommendationsMaxPerRun  int     `mapstructure:"recommendations_max_per_run"`
+		PinBudget                 int     `mapstructure:"pin_budget"`             // Total bytes of pinned file content per prompt
+		ContextTokens             int     `mapstructure:"context_tokens"`         // Estimated-token budget of the rolling context window
+		IncludeTree               bool    `mapstructure:"include_tree"`           // Add a tree of the working directory to each prompt
+		TreeMaxEntries            int     `mapstructure:"tree_max_entries"`       // Files and directories listed in that tree
+		IncludeRecentThreads      bool    `mapstructure:"include_recent_threads"` // Summarise the session's threads from earlier runs in each prompt
 	} `mapstructure:"prompt"`
 
 	Update struct {
@@ -38,8 +65,46 @@ type Config struct {
 	UI struct {
 		Theme         string `mapstructure:"theme"`
 		ScreenshotDir string `mapstructure:"screenshot_dir"`
+		Plain         bool   `mapstructure:"plain"`            // Accessible mode: no badges/overlays, notices as plain lines
+		Calc          bool   `mapstructure:"calc"`             // "=" calculator prefix and $(...) substitution in chat input
+		Markdown      bool   `mapstructure:"markdown_render"`  // Render AI replies as markdown in the chat view
+		Highlight     bool   `mapstructure:"syntax_highlight"` // Syntax-highlight files opened in the perusal pane
+		ShowFileDiff  bool   `mapstructure:"show_file_diff"`   // Show a diff in the chat when the agent writes a file
 	} `mapstructure:"ui"`
 
+	Storage struct {
+		Sessions        StoragePolicy `mapstructure:"sessions"`
+		Undo            StoragePolicy `mapstructure:"undo"`
+		Trash           StoragePolicy `mapstructure:"trash"`
+		Transcripts     StoragePolicy `mapstructure:"transcripts"`
+		Recordings      StoragePolicy `mapstructure:"recordings"`
+		CrashLogs       StoragePolicy `mapstructure:"crash_logs"`
+		Source          StoragePolicy `mapstructure:"source"`
+		KeepSessions    []string      `mapstructure:"keep_sessions"`     // Never collected, in addition to the active session
+		GCIntervalHours int           `mapstructure:"gc_interval_hours"` // Daemon schedule; 0 disables
+	} `mapstructure:"storage"`
+
+	MCP struct {
+		Servers []MCPServer `mapstructure:"servers"`
+	} `mapstructure:"mcp"`
+
+	Memory struct {
+		EmbedModel        string  `mapstructure:"embed_model"`        // Ollama embedding model for semantic recall; empty disables
+		SemanticThreshold float64 `mapstructure:"semantic_threshold"` // Minimum cosine similarity for a recalled item
+		SemanticTopK      int     `mapstructure:"semantic_top_k"`     // Most items recalled per prompt
+	} `mapstructure:"memory"`
+
+	Security struct {
+		ToolPolicy      map[string]string `mapstructure:"tool_policy"`       // Tool name -> allow|deny|ask
+		EnableToolCache bool              `mapstructure:"enable_tool_cache"` // Reuse identical read/fetch results for a short while
+		AuditMaxMB      int               `mapstructure:"audit_max_mb"`      // Rotate the enclave audit log past this size
+		AuditKeep       int               `mapstructure:"audit_keep"`        // Rotated audit log generations kept
+		FSRoots         []string          `mapstructure:"fs_roots"`          // Directories filesystem tools may touch; empty means the working directory
+		TOTP            struct {
+			Secret string `mapstructure:"secret"` // Base32 TOTP secret required to unlock vibes; the vault entry is used if empty
+		} `mapstructure:"totp"`
+	} `mapstructure:"security"`
+
 	DataDir string `mapstructure:"-"`
 
 	Health struct {
@@ -48,9 +113,118 @@ type Config struct {
 	} `mapstructure:"health"`
 }
 
-// ConfigManager handles loading and saving configuration
+// MCPServer is a Model Context Protocol server launched over stdio.
+type MCPServer struct {
+	Name    string   `mapstructure:"name"`
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+	Env     []string `mapstructure:"env"` // KEY=VALUE pairs added to the server's environment
+
+	// MaxRestarts caps how often a server that dies or stops answering
+	// is restarted; 0 means the default of 3, negative never restarts.
+	MaxRestarts int `mapstructure:"max_restarts"`
+}
+
+// ProviderNames are the built-in providers, each with a providers.<name>
+// block.
+var ProviderNames = []string{"ollama", "openai", "azure-openai", "anthropic", "gemini", "github-models"}
+
+// DefaultOllamaEndpoint is providers.ollama.endpoint unless configured.
+const DefaultOllamaEndpoint = "http://localhost:11434"
+
+// ProviderConfig is one provider's block under providers.<name>.
+type ProviderConfig struct {
+	Endpoint    string   `mapstructure:"endpoint"`    // Server URL of a self-hosted provider (Ollama) or Azure OpenAI resource
+	BaseURL     string   `mapstructure:"base_url"`    // API base URL override, for gateways and proxies
+	Enabled     bool     `mapstructure:"enabled"`     // Disabled providers are skipped by model discovery
+	APIVersion  string   `mapstructure:"api_version"` // Azure OpenAI API version
+	Deployments []string `mapstructure:"deployments"` // Azure OpenAI deployments to offer instead of asking Azure
+}
+
+// Clone returns a deep copy of c. A config shared between goroutines is
+// never changed in place: clone it, change the copy and swap it in.
+func (c *Config) Clone() *Config {
+	out := *c
+	out.Model.Fallbacks = cloneStrings(c.Model.Fallbacks)
+	if c.Model.RateLimit != nil {
+		out.Model.RateLimit = make(map[string]int, len(c.Model.RateLimit))
+		for k, v := range c.Model.RateLimit {
+			out.Model.RateLimit[k] = v
+		}
+	}
+	if c.Providers != nil {
+		out.Providers = make(map[string]ProviderConfig, len(c.Providers))
+		for k, pc := range c.Providers {
+			pc.Deployments = cloneStrings(pc.Deployments)
+			out.Providers[k] = pc
+		}
+	}
+	out.Update.FailedCommits = cloneStrings(c.Update.FailedCommits)
+	out.Storage.KeepSessions = cloneStrings(c.Storage.KeepSessions)
+	if c.MCP.Servers != nil {
+		out.MCP.Servers = make([]MCPServer, len(c.MCP.Servers))
+		for i, srv := range c.MCP.Servers {
+			srv.Args = cloneStrings(srv.Args)
+			srv.Env = cloneStrings(srv.Env)
+			out.MCP.Servers[i] = srv
+		}
+	}
+	if c.Security.ToolPolicy != nil {
+		out.Security.ToolPolicy = make(map[string]string, len(c.Security.ToolPolicy))
+		for k, v := range c.Security.ToolPolicy {
+			out.Security.ToolPolicy[k] = v
+		}
+	}
+	out.Security.FSRoots = cloneStrings(c.Security.FSRoots)
+	return &out
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+// SetProvider replaces the named provider's block.
+func (c *Config) SetProvider(name string, pc ProviderConfig) {
+	if c.Providers == nil {
+		c.Providers = make(map[string]ProviderConfig)
+	}
+	c.Providers[name] = pc
+}
+
+// ConfigManager handles loading and saving configuration. Settings from
+// a project file (.vibeaura.yaml or .vibeaura/config.yaml in the working
+// directory or one of its parents) are merged over the global config.
 type ConfigManager struct {
 	v *viper.Viper
+
+	project     *viper.Viper // nil when no project file was found
+	projectPath string
+	projectDir  string // Where the search started; new project files go here
+}
+
+// ProjectConfigNames are the project config files looked for in each
+// directory, in order of preference.
+var ProjectConfigNames = []string{".vibeaura.yaml", filepath.Join(".vibeaura", "config.yaml")}
+
+// FindProjectConfig returns the nearest project config file at or above
+// dir, or "" if there is none.
+func FindProjectConfig(dir string) string {
+	for {
+		for _, name := range ProjectConfigNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
 }
 
 // NewConfigManager initializes the configuration system
@@ -70,9 +244,25 @@ func NewConfigManager() (*ConfigManager, error) {
 
 	// Default configuration
 	v.SetDefault("model.provider", "ollama")
-	v.SetDefault("model.endpoint", "http://localhost:11434")
 	v.SetDefault("model.name", "llama3")
+	v.SetDefault("model.fallbacks", []string{})
+	v.SetDefault("model.discovery_timeout", "5s")
+	v.SetDefault("model.cache_enabled", false)
+	v.SetDefault("model.cache_ttl", "1h")
+	v.SetDefault("model.request_timeout", "120s")
+	v.SetDefault("model.max_retries", 3)
+	v.SetDefault("model.max_context_tokens", 32768)
+	v.SetDefault("model.rate_limit", map[string]int{})
+	for _, name := range ProviderNames {
+		v.SetDefault("providers."+name+".enabled", true)
+	}
+	v.SetDefault("providers.ollama.endpoint", DefaultOllamaEndpoint)
 	v.SetDefault("ui.theme", "dark")
+	v.SetDefault("ui.plain", false)
+	v.SetDefault("ui.calc", true)
+	v.SetDefault("ui.markdown_render", true)
+	v.SetDefault("ui.syntax_highlight", true)
+	v.SetDefault("ui.show_file_diff", true)
 
 	// Prompt system defaults
 	v.SetDefault("prompt.enabled", true)
@@ -83,6 +273,11 @@ func NewConfigManager() (*ConfigManager, error) {
 	v.SetDefault("prompt.recommendations_enabled", false)
 	v.SetDefault("prompt.recommendations_sample_rate", 0.02)
 	v.SetDefault("prompt.recommendations_max_per_run", 1)
+	v.SetDefault("prompt.pin_budget", 24*1024)
+	v.SetDefault("prompt.include_tree", true)
+	v.SetDefault("prompt.include_recent_threads", true)
+	v.SetDefault("prompt.tree_max_entries", 150)
+	v.SetDefault("prompt.context_tokens", 8192)
 
 	// Platform-specific screenshot directory
 	var defaultShotDir string
@@ -99,6 +294,31 @@ func NewConfigManager() (*ConfigManager, error) {
 	v.SetDefault("update.verbose", false)
 	v.SetDefault("update.failed_commits", []string{})
 
+	// Storage retention (max age in days, max size in MB, max item count;
+	// 0 = no limit)
+	v.SetDefault("storage.sessions.max_age_days", 90)
+	v.SetDefault("storage.undo.max_age_days", 14)
+	v.SetDefault("storage.undo.max_size_mb", 200)
+	v.SetDefault("storage.undo.max_count", 100)
+	v.SetDefault("storage.trash.max_age_days", 30)
+	v.SetDefault("storage.transcripts.max_age_days", 180)
+	v.SetDefault("storage.recordings.max_age_days", 30)
+	v.SetDefault("storage.recordings.max_size_mb", 500)
+	v.SetDefault("storage.crash_logs.max_age_days", 30)
+	v.SetDefault("storage.crash_logs.max_size_mb", 20)
+	v.SetDefault("storage.source.max_age_days", 60)
+	v.SetDefault("storage.keep_sessions", []string{})
+	v.SetDefault("storage.gc_interval_hours", 24)
+	v.SetDefault("security.tool_policy", map[string]string{})
+	v.SetDefault("security.enable_tool_cache", false)
+	v.SetDefault("security.audit_max_mb", 10)
+	v.SetDefault("security.audit_keep", 5)
+	v.SetDefault("security.fs_roots", []string{})
+	v.SetDefault("security.totp.secret", "")
+	v.SetDefault("memory.embed_model", "nomic-embed-text")
+	v.SetDefault("memory.semantic_threshold", 0.5)
+	v.SetDefault("memory.semantic_top_k", 5)
+
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 	v.AddConfigPath(dataDir)
@@ -114,48 +334,349 @@ func NewConfigManager() (*ConfigManager, error) {
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
+	if migrateEndpoint(v) {
+		if err := v.WriteConfig(); err != nil {
+			return nil, fmt.Errorf("writing migrated config: %w", err)
+		}
+	}
 
-	return &ConfigManager{v: v}, nil
+	cm := &ConfigManager{v: v}
+	if wd, err := os.Getwd(); err == nil {
+		if _, err := cm.SetProjectDir(wd); err != nil {
+			return nil, err
+		}
+	}
+	return cm, nil
 }
 
-// Get returns the current configuration
+// SetProjectDir resolves the project config for dir, reporting whether a
+// different project file (or none) now applies. Call Load again after a
+// change.
+func (cm *ConfigManager) SetProjectDir(dir string) (bool, error) {
+	cm.projectDir = dir
+	path := FindProjectConfig(dir)
+	if path == cm.projectPath {
+		return false, nil
+	}
+	if path == "" {
+		cm.project, cm.projectPath = nil, ""
+		return true, nil
+	}
+
+	pv := viper.New()
+	pv.SetConfigFile(path)
+	pv.SetConfigType("yaml")
+	if err := pv.ReadInConfig(); err != nil {
+		return false, fmt.Errorf("reading project config %s: %w", path, err)
+	}
+	cm.project, cm.projectPath = pv, path
+	return true, nil
+}
+
+// ProjectPath is the project config file in effect, or "" if none.
+func (cm *ConfigManager) ProjectPath() string {
+	return cm.projectPath
+}
+
+// FromProject reports whether the project file sets key, overriding the
+// global config.
+func (cm *ConfigManager) FromProject(key string) bool {
+	return cm.project != nil && ProjectScoped(key) && cm.project.InConfig(key)
+}
+
+// GlobalOnlySections are the config sections a project file cannot
+// override, because a checked-out repository should not be able to change
+// them: how vibeaura updates itself, where it keeps and collects data, the
+// commands it launches as MCP servers, the provider endpoints API keys are
+// sent to, and the security settings. A project's security.tool_policy
+// entries still apply when they are stricter than the global ones (see
+// mergeConfigs). Everything else, such as model.*, prompt.*, ui.* and
+// memory.*, may be set per project.
+var GlobalOnlySections = []string{"update", "storage", "health", "mcp", "providers", "security"}
+
+// ProjectScoped reports whether a project file may set key.
+func ProjectScoped(key string) bool {
+	key = strings.ToLower(key)
+	if key == "security.tool_policy" || strings.HasPrefix(key, "security.tool_policy.") {
+		return true
+	}
+	for _, section := range GlobalOnlySections {
+		if key == section || strings.HasPrefix(key, section+".") {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateEndpoint moves the single model.endpoint of older configs into
+// the providers map: Ollama's endpoint when Ollama is the provider or the
+// value is its default, otherwise the active provider's base_url. Blocks
+// already present in the file are left alone. It reports whether anything
+// changed.
+func migrateEndpoint(v *viper.Viper) bool {
+	endpoint := v.GetString("model.endpoint")
+	if !v.InConfig("model.endpoint") || endpoint == "" {
+		return false
+	}
+	provider := v.GetString("model.provider")
+	key := "providers." + provider + ".base_url"
+	if provider == "ollama" || provider == "" || endpoint == DefaultOllamaEndpoint {
+		key = "providers.ollama.endpoint"
+	}
+	if !v.InConfig(key) {
+		v.Set(key, endpoint)
+	}
+	// Viper cannot delete a key; an empty value marks it migrated.
+	v.Set("model.endpoint", "")
+	return true
+}
+
+// Load returns the current configuration, with the project file's
+// settings merged over the global ones.
 func (cm *ConfigManager) Load() (*Config, error) {
 	var cfg Config
 	if err := cm.v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unmarshaling config: %w", err)
 	}
 
+	if cm.project != nil {
+		// Merged key by key first, so that a project file setting a value
+		// to false or zero still overrides the global one. Copied, because
+		// merging writes into nested maps viper shares with its defaults.
+		v := viper.New()
+		if err := v.MergeConfigMap(copySettings(cm.v.AllSettings())); err != nil {
+			return nil, fmt.Errorf("merging config: %w", err)
+		}
+		if err := v.MergeConfigMap(cm.project.AllSettings()); err != nil {
+			return nil, fmt.Errorf("merging project config %s: %w", cm.projectPath, err)
+		}
+		var project Config
+		if err := v.Unmarshal(&project); err != nil {
+			return nil, fmt.Errorf("unmarshaling project config %s: %w", cm.projectPath, err)
+		}
+		cfg = *mergeConfigs(&cfg, &project)
+	}
+
 	home, _ := os.UserHomeDir()
 	cfg.DataDir = filepath.Join(home, ".vibeauracle")
 
 	return &cfg, nil
 }
 
-// Save persists the current configuration
+// mergeConfigs combines the global config with project, the global config
+// with a project file's settings merged over it, taking the project's
+// values except in GlobalOnlySections. Of those, only security.tool_policy
+// entries stricter than the global ones (ask over allow, deny over
+// either) are kept from the project.
+func mergeConfigs(global, project *Config) *Config {
+	out := *project
+	out.Update = global.Update
+	out.Storage = global.Storage
+	out.Health = global.Health
+	out.MCP = global.MCP
+	out.Providers = global.Providers
+
+	policy := global.Security.ToolPolicy
+	out.Security = global.Security
+	out.Security.ToolPolicy = make(map[string]string, len(policy))
+	for tool, p := range policy {
+		out.Security.ToolPolicy[tool] = p
+	}
+	for tool, p := range project.Security.ToolPolicy {
+		if policyRank(p) > policyRank(policy[tool]) {
+			out.Security.ToolPolicy[tool] = p
+		}
+	}
+	return &out
+}
+
+// policyRank orders tool policies from loosest to strictest, with no
+// policy between allow and ask.
+func policyRank(p string) int {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case "allow":
+		return 0
+	case "ask":
+		return 2
+	case "deny":
+		return 3
+	}
+	return 1
+}
+
+// Save persists the current configuration to the global config file.
+// Settings the project file overrides keep their global values there;
+// change those with SaveProject.
 func (cm *ConfigManager) Save(cfg *Config) error {
-	cm.v.Set("model.provider", cfg.Model.Provider)
-	cm.v.Set("model.endpoint", cfg.Model.Endpoint)
-	cm.v.Set("model.name", cfg.Model.Name)
-	cm.v.Set("prompt.enabled", cfg.Prompt.Enabled)
-	cm.v.Set("prompt.mode", cfg.Prompt.Mode)
-	cm.v.Set("prompt.project_instructions", cfg.Prompt.ProjectInstructions)
-	cm.v.Set("prompt.learning_enabled", cfg.Prompt.LearningEnabled)
-	cm.v.Set("prompt.recommendations_enabled", cfg.Prompt.RecommendationsEnabled)
-	cm.v.Set("prompt.recommendations_sample_rate", cfg.Prompt.RecommendationsSampleRate)
-	cm.v.Set("prompt.recommendations_max_per_run", cfg.Prompt.RecommendationsMaxPerRun)
-	cm.v.Set("update.build_from_source", cfg.Update.BuildFromSource)
-	cm.v.Set("update.beta", cfg.Update.Beta)
-	cm.v.Set("update.auto_update", cfg.Update.AutoUpdate)
-	cm.v.Set("update.verbose", cfg.Update.Verbose)
-	cm.v.Set("update.failed_commits", cfg.Update.FailedCommits)
-	cm.v.Set("ui.theme", cfg.UI.Theme)
-	cm.v.Set("ui.screenshot_dir", cfg.UI.ScreenshotDir)
-	cm.v.Set("health.crash_count", cfg.Health.CrashCount)
-	cm.v.Set("health.last_crash", cfg.Health.LastCrash)
+	var keys []string
+	prev := make(map[string]interface{})
+	if cm.project != nil {
+		for _, key := range cm.project.AllKeys() {
+			if ProjectScoped(key) {
+				keys = append(keys, key)
+				prev[key] = cm.v.Get(key)
+			}
+		}
+	}
 
+	setConfig(cm.v, cfg)
+
+	for _, key := range keys {
+		if prev[key] != nil {
+			cm.v.Set(key, prev[key])
+		} else {
+			unsetKey(cm.v, key)
+		}
+	}
 	return cm.v.WriteConfig()
 }
 
+// SaveProject writes the given keys of cfg to the project config file,
+// creating .vibeaura.yaml in the project directory if there is none. Keys
+// in GlobalOnlySections are refused.
+func (cm *ConfigManager) SaveProject(cfg *Config, keys ...string) error {
+	for _, key := range keys {
+		if !ProjectScoped(key) {
+			return fmt.Errorf("%s is a global-only setting and cannot be set per project", key)
+		}
+	}
+	if cm.project == nil {
+		if cm.projectDir == "" {
+			return fmt.Errorf("no project directory to write .vibeaura.yaml to")
+		}
+		cm.project = viper.New()
+		cm.project.SetConfigType("yaml")
+		cm.projectPath = filepath.Join(cm.projectDir, ProjectConfigNames[0])
+		cm.project.SetConfigFile(cm.projectPath)
+	}
+
+	all := viper.New()
+	setConfig(all, cfg)
+	for _, key := range keys {
+		cm.project.Set(key, all.Get(key))
+	}
+	return cm.project.WriteConfigAs(cm.projectPath)
+}
+
+// unsetKey removes a key that has no global value or default, such as a
+// map entry only the project file sets.
+func unsetKey(v *viper.Viper, key string) {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return
+	}
+	parent := copySettings(v.GetStringMap(key[:i]))
+	delete(parent, key[i+1:])
+	v.Set(key[:i], parent)
+}
+
+// copySettings deep-copies a settings map.
+func copySettings(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, val := range m {
+		switch val := val.(type) {
+		case map[string]interface{}:
+			out[k] = copySettings(val)
+		case map[string]string:
+			c := make(map[string]interface{}, len(val))
+			for mk, mv := range val {
+				c[mk] = mv
+			}
+			out[k] = c
+		default:
+			out[k] = val
+		}
+	}
+	return out
+}
+
+// setConfig copies cfg into v.
+func setConfig(v *viper.Viper, cfg *Config) {
+	v.Set("model.provider", cfg.Model.Provider)
+	v.Set("model.name", cfg.Model.Name)
+	v.Set("model.fallbacks", cfg.Model.Fallbacks)
+	v.Set("model.discovery_timeout", cfg.Model.DiscoveryTimeout.String())
+	v.Set("model.cache_enabled", cfg.Model.CacheEnabled)
+	v.Set("model.cache_ttl", cfg.Model.CacheTTL.String())
+	v.Set("model.request_timeout", cfg.Model.RequestTimeout.String())
+	v.Set("model.max_retries", cfg.Model.MaxRetries)
+	v.Set("model.max_context_tokens", cfg.Model.MaxContextTokens)
+	v.Set("model.rate_limit", cfg.Model.RateLimit)
+	for name, pc := range cfg.Providers {
+		v.Set("providers."+name+".endpoint", pc.Endpoint)
+		v.Set("providers."+name+".base_url", pc.BaseURL)
+		v.Set("providers."+name+".enabled", pc.Enabled)
+		// Azure-only settings are left out of every other block.
+		if pc.APIVersion != "" {
+			v.Set("providers."+name+".api_version", pc.APIVersion)
+		}
+		if len(pc.Deployments) > 0 {
+			v.Set("providers."+name+".deployments", pc.Deployments)
+		}
+	}
+	v.Set("prompt.enabled", cfg.Prompt.Enabled)
+	v.Set("prompt.mode", cfg.Prompt.Mode)
+	v.Set("prompt.project_instructions", cfg.Prompt.ProjectInstructions)
+	v.Set("prompt.learning_enabled", cfg.Prompt.LearningEnabled)
+	v.Set("prompt.recommendations_enabled", cfg.Prompt.RecommendationsEnabled)
+	v.Set("prompt.recommendations_sample_rate", cfg.Prompt.RecommendationsSampleRate)
+	v.Set("prompt.recommendations_max_per_run", cfg.Prompt.RecommendationsMaxPerRun)
+	v.Set("prompt.pin_budget", cfg.Prompt.PinBudget)
+	v.Set("prompt.context_tokens", cfg.Prompt.ContextTokens)
+	v.Set("prompt.include_tree", cfg.Prompt.IncludeTree)
+	v.Set("prompt.include_recent_threads", cfg.Prompt.IncludeRecentThreads)
+	v.Set("prompt.tree_max_entries", cfg.Prompt.TreeMaxEntries)
+	v.Set("update.build_from_source", cfg.Update.BuildFromSource)
+	v.Set("update.beta", cfg.Update.Beta)
+	v.Set("update.auto_update", cfg.Update.AutoUpdate)
+	v.Set("update.verbose", cfg.Update.Verbose)
+	v.Set("update.failed_commits", cfg.Update.FailedCommits)
+	v.Set("ui.theme", cfg.UI.Theme)
+	v.Set("ui.screenshot_dir", cfg.UI.ScreenshotDir)
+	v.Set("ui.plain", cfg.UI.Plain)
+	v.Set("ui.calc", cfg.UI.Calc)
+	v.Set("ui.markdown_render", cfg.UI.Markdown)
+	v.Set("ui.syntax_highlight", cfg.UI.Highlight)
+	v.Set("ui.show_file_diff", cfg.UI.ShowFileDiff)
+	for name, p := range map[string]StoragePolicy{
+		"sessions":    cfg.Storage.Sessions,
+		"undo":        cfg.Storage.Undo,
+		"trash":       cfg.Storage.Trash,
+		"transcripts": cfg.Storage.Transcripts,
+		"recordings":  cfg.Storage.Recordings,
+		"crash_logs":  cfg.Storage.CrashLogs,
+		"source":      cfg.Storage.Source,
+	} {
+		v.Set("storage."+name+".max_age_days", p.MaxAgeDays)
+		v.Set("storage."+name+".max_size_mb", p.MaxSizeMB)
+		v.Set("storage."+name+".max_count", p.MaxCount)
+	}
+	v.Set("storage.keep_sessions", cfg.Storage.KeepSessions)
+	v.Set("storage.gc_interval_hours", cfg.Storage.GCIntervalHours)
+	servers := make([]map[string]interface{}, 0, len(cfg.MCP.Servers))
+	for _, srv := range cfg.MCP.Servers {
+		servers = append(servers, map[string]interface{}{
+			"name":         srv.Name,
+			"command":      srv.Command,
+			"args":         srv.Args,
+			"env":          srv.Env,
+			"max_restarts": srv.MaxRestarts,
+		})
+	}
+	v.Set("mcp.servers", servers)
+	v.Set("security.tool_policy", cfg.Security.ToolPolicy)
+	v.Set("security.enable_tool_cache", cfg.Security.EnableToolCache)
+	v.Set("security.audit_max_mb", cfg.Security.AuditMaxMB)
+	v.Set("security.audit_keep", cfg.Security.AuditKeep)
+	v.Set("security.fs_roots", cfg.Security.FSRoots)
+	v.Set("security.totp.secret", cfg.Security.TOTP.Secret)
+	v.Set("memory.embed_model", cfg.Memory.EmbedModel)
+	v.Set("memory.semantic_threshold", cfg.Memory.SemanticThreshold)
+	v.Set("memory.semantic_top_k", cfg.Memory.SemanticTopK)
+	v.Set("health.crash_count", cfg.Health.CrashCount)
+	v.Set("health.last_crash", cfg.Health.LastCrash)
+}
+
 // GetDataPath returns a path inside the .vibeauracle directory
 func (cm *ConfigManager) GetDataPath(subpath string) string {
 	home, _ := os.UserHomeDir()
@@ -3,6 +3,7 @@ package sys
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -50,6 +51,9 @@ func TestConfigManager(t *testing.T) {
 	// Test Save/Update
 	cfg.Model.Name = "custom-model"
 	cfg.Prompt.Mode = "ask"
+	cfg.MCP.Servers = []MCPServer{{Name: "files", Command: "npx", Args: []string{"-y", "server-filesystem", "/tmp"}, Env: []string{"DEBUG=1"}}}
+	cfg.Security.ToolPolicy = map[string]string{"sys_shell_exec": "deny"}
+	cfg.Model.RateLimit = map[string]int{"openai": 20}
 	if err := cm.Save(cfg); err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
@@ -63,5 +67,212 @@ func TestConfigManager(t *testing.T) {
 	if cfg2.Prompt.Mode != "ask" {
 		t.Errorf("got prompt mode %q, want 'ask'", cfg2.Prompt.Mode)
 	}
+	if len(cfg2.MCP.Servers) != 1 || cfg2.MCP.Servers[0].Command != "npx" || len(cfg2.MCP.Servers[0].Args) != 3 || cfg2.MCP.Servers[0].Env[0] != "DEBUG=1" {
+		t.Errorf("MCP servers did not round-trip: %+v", cfg2.MCP.Servers)
+	}
+	if cfg2.Security.ToolPolicy["sys_shell_exec"] != "deny" {
+		t.Errorf("tool policy did not round-trip: %v", cfg2.Security.ToolPolicy)
+	}
+	if cfg2.Model.RateLimit["openai"] != 20 {
+		t.Errorf("rate limit did not round-trip: %v", cfg2.Model.RateLimit)
+	}
 }
 
+func TestConfigManager_MigratesModelEndpoint(t *testing.T) {
+	cases := []struct {
+		provider, endpoint string
+		check              func(cfg *Config) bool
+	}{
+		{"openai", "https://gateway.example/v1", func(cfg *Config) bool {
+			return cfg.Providers["openai"].BaseURL == "https://gateway.example/v1" &&
+				cfg.Providers["ollama"].Endpoint == DefaultOllamaEndpoint
+		}},
+		{"ollama", "http://gpu-box:11434", func(cfg *Config) bool {
+			return cfg.Providers["ollama"].Endpoint == "http://gpu-box:11434" && cfg.Providers["openai"].BaseURL == ""
+		}},
+	}
+	for _, c := range cases {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		dir := filepath.Join(home, ".vibeauracle")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		old := "model:\n  provider: " + c.provider + "\n  endpoint: " + c.endpoint + "\n  name: m\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(old), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ { // The second load reads the migrated file.
+			cm, err := NewConfigManager()
+			if err != nil {
+				t.Fatalf("NewConfigManager: %v", err)
+			}
+			cfg, err := cm.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !c.check(cfg) || !cfg.Providers["github-models"].Enabled {
+				t.Errorf("%s (load %d): unexpected providers %+v", c.provider, i+1, cfg.Providers)
+			}
+		}
+	}
+}
+
+func TestConfigManager_ProjectConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	sub := filepath.Join(project, "pkg", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "model:\n  name: project-model\nprompt:\n  project_instructions: Use tabs.\nsecurity:\n  tool_policy:\n    sys_shell_exec: deny\n"
+	if err := os.WriteFile(filepath.Join(project, ".vibeaura.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.ProjectPath(); filepath.Base(got) != ".vibeaura.yaml" {
+		t.Fatalf("expected the parent's .vibeaura.yaml, got %q", got)
+	}
+	cfg, _ := cm.Load()
+	if cfg.Model.Name != "project-model" || cfg.Prompt.ProjectInstructions != "Use tabs." || cfg.Model.Provider != "ollama" {
+		t.Errorf("project values not merged over global: %+v", cfg.Model)
+	}
+	if !cm.FromProject("model.name") || cm.FromProject("model.provider") {
+		t.Errorf("unexpected origins")
+	}
+
+	// A global save must not copy project values into the global file.
+	cfg.Model.Provider = "openai"
+	if err := cm.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cm.SetProjectDir(t.TempDir())
+	global, _ := cm.Load()
+	if global.Model.Provider != "openai" || global.Model.Name != "llama3" || global.Security.ToolPolicy["sys_shell_exec"] != "" {
+		t.Errorf("project values leaked into the global config: %+v %v", global.Model, global.Security.ToolPolicy)
+	}
+
+	// Project writes land in the project file.
+	if changed, err := cm.SetProjectDir(sub); !changed || err != nil {
+		t.Fatalf("expected the project config back (%v)", err)
+	}
+	cfg, _ = cm.Load()
+	cfg.Prompt.Mode = "plan"
+	if err := cm.SaveProject(cfg, "prompt.mode"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(project, ".vibeaura.yaml"))
+	if !strings.Contains(string(data), "mode: plan") || !strings.Contains(string(data), "project-model") {
+		t.Errorf("unexpected project file:\n%s", data)
+	}
+}
+
+func TestMergeConfigs_Precedence(t *testing.T) {
+	global := &Config{}
+	global.Model.Name = "llama3"
+	global.Update.AutoUpdate = true
+	global.UI.Plain = true
+	global.SetProvider("ollama", ProviderConfig{Endpoint: DefaultOllamaEndpoint, Enabled: true})
+	global.Security.ToolPolicy = map[string]string{"sys_shell_exec": "ask", "sys_git_commit": "deny"}
+
+	// The project view is the global config with the project file merged
+	// over it, so it carries global values for keys the file leaves alone.
+	project := &Config{}
+	project.Model.Name = "qwen2.5-coder"
+	project.Update.AutoUpdate = false
+	project.UI.Plain = false
+	project.SetProvider("ollama", ProviderConfig{Endpoint: "http://attacker.example", Enabled: true})
+	project.Security.ToolPolicy = map[string]string{
+		"sys_shell_exec": "allow", // Looser: ignored
+		"sys_git_commit": "deny",
+		"sys_fetch_url":  "deny", // Stricter than no policy: kept
+	}
+
+	got := mergeConfigs(global, project)
+	if got.Model.Name != "qwen2.5-coder" || got.UI.Plain {
+		t.Errorf("project-scoped values not taken from the project: %+v %+v", got.Model, got.UI)
+	}
+	if !got.Update.AutoUpdate || got.Providers["ollama"].Endpoint != DefaultOllamaEndpoint {
+		t.Errorf("global-only values overridden by the project: %+v %+v", got.Update, got.Providers)
+	}
+	want := map[string]string{"sys_shell_exec": "ask", "sys_git_commit": "deny", "sys_fetch_url": "deny"}
+	for tool, p := range want {
+		if got.Security.ToolPolicy[tool] != p {
+			t.Errorf("tool_policy[%s] = %q, want %q", tool, got.Security.ToolPolicy[tool], p)
+		}
+	}
+	if len(global.Security.ToolPolicy) != 2 {
+		t.Errorf("merge modified the global policy: %v", global.Security.ToolPolicy)
+	}
+}
+
+func TestConfigManager_ProjectGlobalOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	yaml := "update:\n  auto_update: false\nprompt:\n  include_tree: false\n"
+	if err := os.WriteFile(filepath.Join(project, ".vibeaura.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.SetProjectDir(project); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := cm.Load()
+	if !cfg.Update.AutoUpdate || cfg.Prompt.IncludeTree {
+		t.Errorf("auto_update=%v include_tree=%v, want the global auto_update and the project's include_tree", cfg.Update.AutoUpdate, cfg.Prompt.IncludeTree)
+	}
+	if cm.FromProject("update.auto_update") || !cm.FromProject("prompt.include_tree") {
+		t.Errorf("unexpected origins")
+	}
+	if err := cm.SaveProject(cfg, "update.beta"); err == nil {
+		t.Error("expected a global-only key to be refused for the project file")
+	}
+
+	// Global saves still change global-only keys the project file names.
+	cfg.Update.AutoUpdate = false
+	if err := cm.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ = cm.Load(); cfg.Update.AutoUpdate {
+		t.Error("global save of update.auto_update was lost")
+	}
+}
+
+func TestConfigClone_IsDeep(t *testing.T) {
+	c := &Config{}
+	c.Model.Fallbacks = []string{"ollama"}
+	c.Model.RateLimit = map[string]int{"openai": 60}
+	c.SetProvider("azure-openai", ProviderConfig{Deployments: []string{"gpt-4o"}})
+	c.MCP.Servers = []MCPServer{{Name: "fs", Args: []string{"/tmp"}}}
+	c.Security.ToolPolicy = map[string]string{"sys_shell": "ask"}
+
+	d := c.Clone()
+	d.Model.Fallbacks[0] = "gemini"
+	d.Model.RateLimit["openai"] = 1
+	pc := d.Providers["azure-openai"]
+	pc.Deployments[0] = "changed"
+	d.SetProvider("ollama", ProviderConfig{Endpoint: DefaultOllamaEndpoint})
+	d.MCP.Servers[0].Args[0] = "/"
+	d.Security.ToolPolicy["sys_shell"] = "allow"
+
+	if c.Model.Fallbacks[0] != "ollama" || c.Model.RateLimit["openai"] != 60 ||
+		c.Providers["azure-openai"].Deployments[0] != "gpt-4o" || len(c.Providers) != 1 ||
+		c.MCP.Servers[0].Args[0] != "/tmp" || c.Security.ToolPolicy["sys_shell"] != "ask" {
+		t.Errorf("changing the clone changed the original: %+v", c)
+	}
+}
@@ -0,0 +1,253 @@
+package sys
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvCategory groups environment variables by concern.
+type EnvCategory string
+
+const (
+	EnvVibeaura  EnvCategory = "vibeaura"
+	EnvShell     EnvCategory = "shell"
+	EnvEditor    EnvCategory = "editor"
+	EnvGo        EnvCategory = "go"
+	EnvToolchain EnvCategory = "toolchain"
+	EnvProxy     EnvCategory = "proxy"
+	EnvLocale    EnvCategory = "locale"
+)
+
+// envCategoryOrder is the order categories are listed in.
+var envCategoryOrder = []EnvCategory{EnvVibeaura, EnvShell, EnvEditor, EnvGo, EnvToolchain, EnvProxy, EnvLocale}
+
+// EnvEntry is a single, display-safe environment variable.
+type EnvEntry struct {
+	Name     string      `json:"name"`
+	Value    string      `json:"value"`
+	Category EnvCategory `json:"category"`
+	Masked   bool        `json:"masked"`
+}
+
+// BinaryProbe describes whether a binary is reachable on PATH.
+type BinaryProbe struct {
+	Name    string `json:"name"`
+	Found   bool   `json:"found"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// EnvReport is the filtered view of the process environment.
+type EnvReport struct {
+	Entries  []EnvEntry    `json:"entries"`
+	Binaries []BinaryProbe `json:"binaries,omitempty"`
+}
+
+// envAllowlist maps well-known variables to their category. Anything not
+// listed here (and not matching a known prefix) is hidden.
+var envAllowlist = map[string]EnvCategory{
+	"SHELL":        EnvShell,
+	"TERM":         EnvShell,
+	"TERM_PROGRAM": EnvShell,
+	"COLORTERM":    EnvShell,
+	"PATH":         EnvShell,
+	"HOME":         EnvShell,
+	"USER":         EnvShell,
+	"EDITOR":       EnvEditor,
+	"VISUAL":       EnvEditor,
+	"GIT_EDITOR":   EnvEditor,
+	"PAGER":        EnvEditor,
+	"OLLAMA_HOST":  EnvVibeaura,
+	"PWD":          EnvShell,
+	"TMPDIR":       EnvShell,
+	"LANG":         EnvLocale,
+	"LANGUAGE":     EnvLocale,
+	"LC_ALL":       EnvLocale,
+	"LC_CTYPE":     EnvLocale,
+	"TZ":           EnvLocale,
+	"HTTP_PROXY":   EnvProxy,
+	"HTTPS_PROXY":  EnvProxy,
+	"NO_PROXY":     EnvProxy,
+	"ALL_PROXY":    EnvProxy,
+	"http_proxy":   EnvProxy,
+	"https_proxy":  EnvProxy,
+	"no_proxy":     EnvProxy,
+	"all_proxy":    EnvProxy,
+}
+
+// toolchainPrefixes identifies language toolchain variables other than Go's.
+var toolchainPrefixes = []string{"CARGO", "RUST", "NODE", "NPM", "NVM", "PYTHON", "VIRTUAL_ENV", "CONDA", "JAVA", "GRADLE", "MAVEN", "RUBY", "GEM", "DENO", "BUN"}
+
+// envDenyMarkers flag variable names whose values must never be shown.
+var envDenyMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "PASSPHRASE", "KEY", "PRIVATE", "CREDENTIAL", "AUTH", "SESSION"}
+
+// DefaultProbes are the binaries checked when no explicit probe is requested.
+var DefaultProbes = []string{"go", "git", "node", "python3"}
+
+// InspectEnv returns the allowlisted environment, grouped and redacted.
+// Secret-looking variables in a known group are listed with their values
+// masked.
+func InspectEnv() EnvReport {
+	var entries []EnvEntry
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		cat, allowed := classifyEnv(name)
+		if !allowed {
+			continue
+		}
+		entries = append(entries, newEnvEntry(name, value, cat))
+	}
+
+	rank := make(map[EnvCategory]int, len(envCategoryOrder))
+	for i, c := range envCategoryOrder {
+		rank[c] = i
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Category != entries[j].Category {
+			return rank[entries[i].Category] < rank[entries[j].Category]
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return EnvReport{Entries: entries}
+}
+
+// Filter keeps the entries whose name, value or category contains pattern,
+// ignoring case. Masked values are never matched, so a filter cannot be
+// used to probe a secret.
+func (r EnvReport) Filter(pattern string) EnvReport {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return r
+	}
+	out := EnvReport{Binaries: r.Binaries}
+	for _, e := range r.Entries {
+		if strings.Contains(strings.ToLower(e.Name), pattern) ||
+			(!e.Masked && strings.Contains(strings.ToLower(e.Value), pattern)) ||
+			strings.Contains(string(e.Category), pattern) {
+			out.Entries = append(out.Entries, e)
+		}
+	}
+	return out
+}
+
+// QueryEnv is the curated environment narrowed by filter. A filter that
+// matches nothing but names a set variable exactly returns that variable,
+// masked unless it is allowlisted and does not look secret.
+func QueryEnv(filter string) EnvReport {
+	report := InspectEnv().Filter(filter)
+	if len(report.Entries) == 0 && filter != "" {
+		if e, ok := LookupEnv(filter); ok {
+			report.Entries = []EnvEntry{e}
+		}
+	}
+	return report
+}
+
+// LookupEnv returns a single variable. It goes through the same allowlist as
+// InspectEnv: a variable outside it is reported as set but its value is
+// masked, as is any secret-looking value, even when queried explicitly.
+func LookupEnv(name string) (EnvEntry, bool) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return EnvEntry{Name: name}, false
+	}
+	cat, allowed := classifyEnv(name)
+	e := newEnvEntry(name, value, cat)
+	if !allowed && !e.Masked {
+		e.Value = RedactValue(value)
+		e.Masked = true
+	}
+	return e, true
+}
+
+// ProbeBinary resolves a binary on PATH and asks it for a version string.
+func ProbeBinary(ctx context.Context, name string) BinaryProbe {
+	probe := BinaryProbe{Name: name}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return probe
+	}
+	probe.Found = true
+	probe.Path = path
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	flag := "--version"
+	if name == "go" {
+		flag = "version"
+	}
+	out, err := exec.CommandContext(ctx, path, flag).CombinedOutput()
+	if err == nil {
+		line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		probe.Version = line
+	}
+	return probe
+}
+
+// RedactValue masks a secret value, keeping just enough to recognise it.
+func RedactValue(value string) string {
+	if len(value) <= 8 {
+		return "********"
+	}
+	return value[:4] + "…" + strings.Repeat("*", 4)
+}
+
+// TruncateValue shortens long values for display without splitting runes.
+func TruncateValue(value string, max int) string {
+	r := []rune(value)
+	if max <= 0 || len(r) <= max {
+		return value
+	}
+	if max <= 1 {
+		return string(r[:max])
+	}
+	return string(r[:max-1]) + "…"
+}
+
+func newEnvEntry(name, value string, cat EnvCategory) EnvEntry {
+	e := EnvEntry{Name: name, Value: value, Category: cat}
+	if isSecretEnv(name) {
+		e.Value = RedactValue(value)
+		e.Masked = true
+	}
+	return e
+}
+
+func classifyEnv(name string) (EnvCategory, bool) {
+	if cat, ok := envAllowlist[name]; ok {
+		return cat, true
+	}
+	upper := strings.ToUpper(name)
+	switch {
+	case strings.HasPrefix(upper, "VIBEAURA"):
+		return EnvVibeaura, true
+	case strings.HasPrefix(upper, "LC_"):
+		return EnvLocale, true
+	case strings.HasPrefix(upper, "CGO_"), strings.HasPrefix(upper, "GO") && !strings.HasPrefix(upper, "GOOGLE"):
+		return EnvGo, true
+	}
+	for _, p := range toolchainPrefixes {
+		if strings.HasPrefix(upper, p) {
+			return EnvToolchain, true
+		}
+	}
+	return "", false
+}
+
+func isSecretEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range envDenyMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // FS defines the interface for filesystem operations
@@ -12,6 +13,12 @@ type FS interface {
 	ReadFile(path string) ([]byte, error)
 	WriteFile(path string, content []byte) error
 	DeleteFile(path string) error
+	// DeleteDir removes a directory and everything in it
+	DeleteDir(path string) error
+	// MakeDir creates a directory, failing if it already exists
+	MakeDir(path string) error
+	// Rename moves a file or directory, failing if the target exists
+	Rename(oldPath, newPath string) error
 	ListFiles(path string) ([]string, error)
 	// Edit performs a fast search-and-replace on a file
 	Edit(path string, oldStr, newStr string) error
@@ -37,6 +44,8 @@ type BatchOp struct {
 // LocalFS implements FS using the local filesystem
 type LocalFS struct {
 	baseDir string
+	undo    *UndoJournal
+	policy  *PathPolicy
 }
 
 // NewLocalFS creates a new LocalFS with a specific base directory (sandbox)
@@ -47,33 +56,171 @@ func NewLocalFS(baseDir string) *LocalFS {
 	return &LocalFS{baseDir: baseDir}
 }
 
+// SetUndoJournal makes Backup record writes in j.
+func (l *LocalFS) SetUndoJournal(j *UndoJournal) {
+	l.undo = j
+}
+
+// SetPathPolicy confines every operation to p's roots. Without a policy
+// any path is allowed.
+func (l *LocalFS) SetPathPolicy(p *PathPolicy) {
+	l.policy = p
+}
+
+// PathPolicy returns the policy set with SetPathPolicy, or nil.
+func (l *LocalFS) PathPolicy() *PathPolicy {
+	return l.policy
+}
+
+// UndoJournal returns the journal writes are backed up to, or nil.
+func (l *LocalFS) UndoJournal() *UndoJournal {
+	return l.undo
+}
+
+// Backup records a pending write to path in the undo journal, copying the
+// current file. It returns nil when no journal is set.
+func (l *LocalFS) Backup(path string) (*UndoEntry, error) {
+	if l.undo == nil {
+		return nil, nil
+	}
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.undo.Backup(fullPath)
+}
+
+// BaseDir is the directory relative paths resolve against.
+func (l *LocalFS) BaseDir() string {
+	return l.baseDir
+}
+
 // ReadFile reads a file's content
 func (l *LocalFS) ReadFile(path string) ([]byte, error) {
-	fullPath := l.resolvePath(path)
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
 	return os.ReadFile(fullPath)
 }
 
-// WriteFile creates or overwrites a file
+// WriteFile creates or overwrites a file, preserving the existing file's
+// line endings, BOM and final newline and honoring .editorconfig.
 func (l *LocalFS) WriteFile(path string, content []byte) error {
-	fullPath := l.resolvePath(path)
+	return l.WriteFileEncoding(path, content, "")
+}
+
+// WriteFileEncoding writes content with an explicit encoding (utf-8,
+// utf-8-bom, latin1). An empty encoding keeps the file's current one and
+// refuses to overwrite files that are not valid UTF-8.
+func (l *LocalFS) WriteFileEncoding(path string, content []byte, encoding string) error {
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return err
+	}
 
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
 	}
 
-	return os.WriteFile(fullPath, content, 0644)
+	existing, err := os.ReadFile(fullPath)
+	exists := err == nil
+
+	f := ResolveFormat(fullPath, existing, exists)
+	switch encoding {
+	case "":
+		if exists && DetectFormat(existing).Charset == EncodingLatin1 {
+			return fmt.Errorf("%s: %w", path, ErrNonUTF8)
+		}
+	case EncodingUTF8, EncodingUTF8BOM, EncodingLatin1:
+		f.Charset = encoding
+		f.BOM = encoding == EncodingUTF8BOM
+	default:
+		return fmt.Errorf("unsupported encoding %q", encoding)
+	}
+
+	out, err := EncodeText(content, f)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return os.WriteFile(fullPath, out, 0644)
+}
+
+// Format reports how a file is (or would be) written.
+func (l *LocalFS) Format(path string) TextFormat {
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return ResolveFormat(fullPath, nil, false)
+	}
+	existing, err := os.ReadFile(fullPath)
+	return ResolveFormat(fullPath, existing, err == nil)
 }
 
 // DeleteFile removes a file
 func (l *LocalFS) DeleteFile(path string) error {
-	fullPath := l.resolvePath(path)
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return err
+	}
 	return os.Remove(fullPath)
 }
 
+// DeleteDir removes a directory and everything in it
+func (l *LocalFS) DeleteDir(path string) error {
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if fullPath == l.baseDir {
+		return fmt.Errorf("refusing to delete the base directory")
+	}
+	return os.RemoveAll(fullPath)
+}
+
+// MakeDir creates a directory and any missing parents. It fails if path
+// already exists.
+func (l *LocalFS) MakeDir(path string) error {
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(fullPath); err == nil {
+		return fmt.Errorf("%s: %w", path, os.ErrExist)
+	}
+	return os.MkdirAll(fullPath, 0755)
+}
+
+// Rename moves a file or directory. Unlike os.Rename it never replaces an
+// existing target.
+func (l *LocalFS) Rename(oldPath, newPath string) error {
+	from, err := l.Resolve(oldPath)
+	if err != nil {
+		return err
+	}
+	to, err := l.Resolve(newPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(to); err == nil {
+		return fmt.Errorf("%s: %w", newPath, os.ErrExist)
+	}
+	return os.Rename(from, to)
+}
+
 // ListFiles lists files in a directory
 func (l *LocalFS) ListFiles(path string) ([]string, error) {
-	fullPath := l.resolvePath(path)
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
 	entries, err := os.ReadDir(fullPath)
 	if err != nil {
 		return nil, err
@@ -86,26 +233,43 @@ func (l *LocalFS) ListFiles(path string) ([]string, error) {
 	return files, nil
 }
 
-// Edit performs a fast search-and-replace on a file without rewriting if no changes
+// Edit performs a fast search-and-replace on a file without rewriting if no changes.
+// Matching happens on LF-normalised text so edits work on CRLF files, and the
+// file's original line endings and BOM are restored on write.
 func (l *LocalFS) Edit(path string, oldStr, newStr string) error {
-	fullPath := l.resolvePath(path)
+	fullPath, err := l.Resolve(path)
+	if err != nil {
+		return err
+	}
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
 		return err
 	}
 
-	if !bytes.Contains(content, []byte(oldStr)) {
+	f := ResolveFormat(fullPath, content, true)
+	if f.Charset == EncodingLatin1 {
+		return fmt.Errorf("%s: %w", path, ErrNonUTF8)
+	}
+
+	text, _ := DecodeText(content)
+	oldStr = strings.ReplaceAll(oldStr, "\r\n", "\n")
+	newStr = strings.ReplaceAll(newStr, "\r\n", "\n")
+
+	if !strings.Contains(text, oldStr) {
 		return fmt.Errorf("string not found in file")
 	}
 
-	newContent := bytes.ReplaceAll(content, []byte(oldStr), []byte(newStr))
+	out, err := EncodeText([]byte(strings.ReplaceAll(text, oldStr, newStr)), f)
+	if err != nil {
+		return err
+	}
 
 	// Atomic-ish write: only write if something changed
-	if bytes.Equal(content, newContent) {
+	if bytes.Equal(content, out) {
 		return nil
 	}
 
-	return os.WriteFile(fullPath, newContent, 0644)
+	return os.WriteFile(fullPath, out, 0644)
 }
 
 // Batch executes multiple file operations at once for lightning speed
@@ -125,13 +289,25 @@ func (l *LocalFS) Batch(ops []BatchOp) error {
 	return nil
 }
 
+// Resolve returns the absolute path an operation on path touches, or an
+// *OutsideRootsError if the path policy does not allow it.
+func (l *LocalFS) Resolve(path string) (string, error) {
+	fullPath := l.resolvePath(path)
+	if l.policy != nil {
+		if err := l.policy.Check(fullPath); err != nil {
+			return fullPath, err
+		}
+	}
+	return fullPath, nil
+}
+
 // resolvePath ensures paths are handled relative to the base directory and sanitized.
 func (l *LocalFS) resolvePath(path string) string {
 	if path == "" {
 		return l.baseDir
 	}
 	if filepath.IsAbs(path) {
-		return path // User knows what they are doing with absolute paths
+		return filepath.Clean(path) // Checked against the path policy, if any
 	}
 	// Force join with CWD/baseDir
 	abs, err := filepath.Abs(filepath.Join(l.baseDir, path))
@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -82,3 +83,39 @@ func TestLocalFS_Subdir(t *testing.T) {
 	}
 }
 
+func TestLocalFS_DirsAndRename(t *testing.T) {
+	fs := NewLocalFS(t.TempDir())
+
+	if err := fs.MakeDir("a/b"); err != nil {
+		t.Fatalf("MakeDir failed: %v", err)
+	}
+	if err := fs.MakeDir("a"); !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected MakeDir on an existing path to fail, got %v", err)
+	}
+	if err := fs.WriteFile("a/b/x.txt", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("y.txt", []byte("y")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Rename("y.txt", "a/b/x.txt"); !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected Rename onto an existing file to fail, got %v", err)
+	}
+	if err := fs.Rename("a", "c"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if got, err := fs.ReadFile("c/b/x.txt"); err != nil || string(got) != "x" {
+		t.Errorf("expected the renamed directory to keep its files, got %q (%v)", got, err)
+	}
+
+	if err := fs.DeleteDir("y.txt"); err == nil {
+		t.Error("expected DeleteDir on a file to fail")
+	}
+	if err := fs.DeleteDir("c"); err != nil {
+		t.Fatalf("DeleteDir failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(fs.BaseDir(), "c")); !os.IsNotExist(err) {
+		t.Errorf("directory still exists after DeleteDir: %v", err)
+	}
+}
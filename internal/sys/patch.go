@@ -0,0 +1,388 @@
+package sys
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchHunk is one edit: Search is replaced by Replace. Line, when set, is
+// the 1-based line Search is expected to start at (for an insertion with an
+// empty Search, the line to insert after); it picks between repeated
+// matches and locates the context shown when a hunk fails.
+type PatchHunk struct {
+	Search  string `json:"search"`
+	Replace string `json:"replace"`
+	Line    int    `json:"line,omitempty"`
+
+	wholeLines bool // From a unified diff: Search must start at a line start
+	oldNoEOL   bool // "\ No newline at end of file" after the old side
+	newNoEOL   bool // ... and after the new side
+}
+
+// FileDiff is one file's part of a unified diff. The names are as the
+// ---/+++ headers give them, "a/" or "b/" prefix and /dev/null included;
+// both are empty for hunks with no headers.
+type FileDiff struct {
+	OldName string
+	NewName string
+	Hunks   []PatchHunk
+}
+
+// PatchStats counts what a patch changed.
+type PatchStats struct {
+	Hunks   int `json:"hunks"`
+	Added   int `json:"lines_added"`
+	Removed int `json:"lines_removed"`
+}
+
+// HunkError reports a hunk that could not be applied, with the lines of
+// the file around where it was expected so the caller can correct it.
+type HunkError struct {
+	Hunk    int      `json:"hunk"` // 1-based
+	Reason  string   `json:"reason"`
+	Line    int      `json:"line"` // Line number of Context[0]
+	Context []string `json:"context"`
+}
+
+func (e *HunkError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "hunk %d: %s", e.Hunk, e.Reason)
+	if len(e.Context) > 0 {
+		b.WriteString("; the file around that point reads:\n")
+		for i, l := range e.Context {
+			fmt.Fprintf(&b, "%5d| %s\n", e.Line+i, l)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// hunkContextLines is how many lines either side of the expected location
+// a HunkError shows.
+const hunkContextLines = 3
+
+// ParseUnifiedDiff converts the hunks of a single-file unified diff into
+// search/replace hunks. File headers are ignored, but a diff touching more
+// than one file is rejected.
+func ParseUnifiedDiff(diff string) ([]PatchHunk, error) {
+	files, err := ParseMultiFileDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 1 {
+		return nil, fmt.Errorf("diff touches more than one file; send one diff per file")
+	}
+	return files[0].Hunks, nil
+}
+
+// ParseMultiFileDiff splits a unified diff, as git diff or diff -u write
+// it, into files and converts each file's hunks into search/replace hunks.
+func ParseMultiFileDiff(diff string) ([]FileDiff, error) {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	var files []FileDiff
+	var old, new []string
+	var start, oldLeft, newLeft int
+	var oldNoEOL, newNoEOL bool
+	var last byte // Kind of the last hunk line, for a following "\ No newline"
+	inHunk, hunks := false, 0
+
+	for i, l := range lines {
+		if strings.HasPrefix(l, `\`) {
+			// "\ No newline at end of file" applies to the line before it,
+			// which may have closed the hunk.
+			noOld, noNew := last != '+', last != '-'
+			if inHunk {
+				oldNoEOL, newNoEOL = oldNoEOL || noOld, newNoEOL || noNew
+			} else if f := len(files) - 1; f >= 0 && len(files[f].Hunks) > 0 && last != 0 {
+				h := &files[f].Hunks[len(files[f].Hunks)-1]
+				h.oldNoEOL, h.newNoEOL = h.oldNoEOL || noOld, h.newNoEOL || noNew
+			}
+			continue
+		}
+		last = 0
+
+		if !inHunk {
+			switch {
+			case strings.HasPrefix(l, "--- "):
+				files = append(files, FileDiff{OldName: headerPath(l[4:])})
+			case strings.HasPrefix(l, "+++ ") && len(files) > 0 && len(files[len(files)-1].Hunks) == 0:
+				files[len(files)-1].NewName = headerPath(l[4:])
+			case strings.HasPrefix(l, "@@"):
+				var err error
+				if start, oldLeft, newLeft, err = hunkHeader(l); err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				if len(files) == 0 {
+					files = append(files, FileDiff{})
+				}
+				inHunk = true
+			}
+			// Anything else is preamble: "diff --git", "index".
+		} else {
+			switch {
+			case strings.HasPrefix(l, "-"):
+				old = append(old, l[1:])
+				oldLeft--
+			case strings.HasPrefix(l, "+"):
+				new = append(new, l[1:])
+				newLeft--
+			default:
+				// Context; a blank line is context whose space was stripped.
+				if l != "" && !strings.HasPrefix(l, " ") {
+					return nil, fmt.Errorf("line %d: unexpected %q in hunk", i+1, l)
+				}
+				text := strings.TrimPrefix(l, " ")
+				old = append(old, text)
+				new = append(new, text)
+				oldLeft--
+				newLeft--
+			}
+			last = ' '
+			if l != "" {
+				last = l[0]
+			}
+		}
+
+		if inHunk && oldLeft <= 0 && newLeft <= 0 {
+			h := PatchHunk{Line: start, wholeLines: true, oldNoEOL: oldNoEOL, newNoEOL: newNoEOL}
+			if len(old) > 0 {
+				h.Search = strings.Join(old, "\n") + "\n"
+			}
+			if len(new) > 0 {
+				h.Replace = strings.Join(new, "\n") + "\n"
+			}
+			f := &files[len(files)-1]
+			f.Hunks = append(f.Hunks, h)
+			hunks++
+			old, new, inHunk = nil, nil, false
+			oldNoEOL, newNoEOL = false, false
+		}
+	}
+	if inHunk {
+		return nil, fmt.Errorf("diff ends in the middle of a hunk")
+	}
+	if hunks == 0 {
+		return nil, fmt.Errorf("no @@ hunks found in diff")
+	}
+	for _, f := range files {
+		if len(f.Hunks) == 0 {
+			return nil, fmt.Errorf("%s: no @@ hunks in diff", f.OldName)
+		}
+	}
+	return files, nil
+}
+
+// headerPath is the path in a ---/+++ header, without the timestamp diff
+// -u appends.
+func headerPath(name string) string {
+	name, _, _ = strings.Cut(name, "\t")
+	return strings.TrimSpace(name)
+}
+
+// hunkHeader reads "@@ -start,count +start,count @@". A missing count
+// means 1.
+func hunkHeader(header string) (start, oldCount, newCount int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	rng := func(s string) (int, int, error) {
+		first, count, found := strings.Cut(s, ",")
+		n, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, 0, err
+		}
+		c := 1
+		if found {
+			if c, err = strconv.Atoi(count); err != nil {
+				return 0, 0, err
+			}
+		}
+		return n, c, nil
+	}
+	start, oldCount, err1 := rng(fields[1][1:])
+	_, newCount, err2 := rng(fields[2][1:])
+	if err1 != nil || err2 != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	return start, oldCount, newCount, nil
+}
+
+// ApplyPatch applies hunks to text in order. Line numbers are adjusted for
+// the lines earlier hunks added or removed. CRLF text keeps its line
+// endings. On failure the text is returned unchanged with a *HunkError.
+func ApplyPatch(text string, hunks []PatchHunk) (string, PatchStats, error) {
+	orig := text
+	crlf := strings.Contains(text, "\r\n")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	// Diff hunks match whole lines, including the last one.
+	addedNewline := !strings.HasSuffix(text, "\n") && text != ""
+	if addedNewline {
+		text += "\n"
+	}
+	eol := !addedNewline // Whether the result ends in a newline
+
+	var stats PatchStats
+	delta := 0
+	for i, h := range hunks {
+		search := strings.ReplaceAll(h.Search, "\r\n", "\n")
+		replace := strings.ReplaceAll(h.Replace, "\r\n", "\n")
+		line := h.Line
+		if line > 0 {
+			line += delta
+		}
+
+		var at int
+		if search == "" {
+			if !h.wholeLines {
+				return orig, PatchStats{}, &HunkError{Hunk: i + 1, Reason: "search text is empty"}
+			}
+			at = lineOffset(text, line+1)
+		} else {
+			var herr *HunkError
+			at, herr = findHunk(text, search, line, h.wholeLines)
+			if herr != nil {
+				herr.Hunk = i + 1
+				return orig, PatchStats{}, herr
+			}
+		}
+
+		text = text[:at] + replace + text[at+len(search):]
+		if h.oldNoEOL || h.newNoEOL {
+			eol = !h.newNoEOL
+		}
+		added, removed := changedLines(search, replace)
+		stats.Added += added
+		stats.Removed += removed
+		stats.Hunks++
+		delta += strings.Count(replace, "\n") - strings.Count(search, "\n")
+	}
+
+	if !eol {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	if crlf {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text, stats, nil
+}
+
+// findHunk locates search in text. A unique match wins; repeated matches
+// are resolved by the one starting nearest line, or rejected without it.
+func findHunk(text, search string, line int, wholeLines bool) (int, *HunkError) {
+	var matches []int
+	for from := 0; ; {
+		i := strings.Index(text[from:], search)
+		if i < 0 {
+			break
+		}
+		at := from + i
+		if !wholeLines || at == 0 || text[at-1] == '\n' {
+			matches = append(matches, at)
+		}
+		from = at + 1
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1 && line > 0:
+		best := matches[0]
+		for _, m := range matches[1:] {
+			if abs(lineAt(text, m)-line) < abs(lineAt(text, best)-line) {
+				best = m
+			}
+		}
+		return best, nil
+	case len(matches) > 1:
+		return 0, hunkError(text, fmt.Sprintf("search text matches %d places; include more surrounding lines to make it unique", len(matches)), lineAt(text, matches[0]), search)
+	}
+
+	if line <= 0 {
+		line = guessLine(text, search)
+	}
+	return 0, hunkError(text, "search text not found", line, search)
+}
+
+// hunkError builds a HunkError showing the lines around line.
+func hunkError(text, reason string, line int, search string) *HunkError {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if line < 1 {
+		line = 1
+	}
+	from := max(line-hunkContextLines, 1)
+	to := min(line+strings.Count(search, "\n")+hunkContextLines, len(lines))
+	e := &HunkError{Reason: reason, Line: from}
+	if from <= to {
+		e.Context = lines[from-1 : to]
+	}
+	return e
+}
+
+// guessLine finds where a failed search was probably aimed: the first
+// line matching its first non-blank line, ignoring indentation. 0 means
+// no idea.
+func guessLine(text, search string) int {
+	var first string
+	for _, l := range strings.Split(search, "\n") {
+		if first = strings.TrimSpace(l); first != "" {
+			break
+		}
+	}
+	if first == "" {
+		return 0
+	}
+	for i, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) == first {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// changedLines counts the lines that differ between old and new once their
+// common leading and trailing lines are set aside.
+func changedLines(old, new string) (added, removed int) {
+	a := splitLines(old)
+	b := splitLines(new)
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	return len(b), len(a)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineAt is the 1-based line number of byte offset off.
+func lineAt(text string, off int) int {
+	return strings.Count(text[:off], "\n") + 1
+}
+
+// lineOffset is the byte offset where 1-based line n starts, or the end of
+// text when it has fewer lines.
+func lineOffset(text string, n int) int {
+	off := 0
+	for i := 1; i < n; i++ {
+		j := strings.IndexByte(text[off:], '\n')
+		if j < 0 {
+			return len(text)
+		}
+		off += j + 1
+	}
+	return off
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package sys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const patchSource = "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n"
+
+func TestApplyPatch_UnifiedDiff(t *testing.T) {
+	diff := `--- a/main.go
++++ b/main.go
+@@ -3,3 +3,4 @@
+ func a() {
++	log()
+ 	return
+ }
+@@ -7,3 +8,3 @@ func b() {
+ func b() {
+-	return
++	return nil
+ }
+`
+	hunks, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, stats, err := ApplyPatch(patchSource, hunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package main\n\nfunc a() {\n\tlog()\n\treturn\n}\n\nfunc b() {\n\treturn nil\n}\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if stats != (PatchStats{Hunks: 2, Added: 2, Removed: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestApplyPatch_LineEndings(t *testing.T) {
+	for _, c := range []struct {
+		name, original, hunks, want string
+	}{
+		{"insert after line", "a\nb\n", "@@ -1,0 +2 @@\n+x\n", "a\nx\nb\n"},
+		{"no trailing newline", "a\nb", "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n", "a\nc"},
+		{"adds trailing newline", "a", "@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n", "a\n"},
+		{"drops trailing newline", "a\n", "@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n", "a"},
+		{"keeps CRLF", "a\r\nb\r\n", "@@ -1,2 +1,2 @@\n a\n-b\n+c\n", "a\r\nc\r\n"},
+		{"trimmed blank context", "a\n\nb\n", "@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n", "a\n\nc\n"},
+		{"two hunks", "1\n2\n3\n4\n5\n", "@@ -1 +1 @@\n-1\n+one\n@@ -5 +5 @@\n-5\n+five\n", "one\n2\n3\n4\nfive\n"},
+		{"new file", "", "@@ -0,0 +1,2 @@\n+x\n+y\n", "x\ny\n"},
+	} {
+		hunks, err := ParseUnifiedDiff(c.hunks)
+		if err != nil {
+			t.Fatalf("%s: parsing hunks: %v", c.name, err)
+		}
+		got, _, err := ApplyPatch(c.original, hunks)
+		if err != nil || got != c.want {
+			t.Errorf("%s: got %q (%v), want %q", c.name, got, err, c.want)
+		}
+	}
+}
+
+func TestParseMultiFileDiff(t *testing.T) {
+	files, err := ParseMultiFileDiff("diff --git a/x b/x\nindex 1..2\n--- a/x\t2026-01-01\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n--- /dev/null\n+++ b/y\n@@ -0,0 +1 @@\n+--- not a header\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].OldName != "a/x" || files[0].NewName != "b/x" || files[1].OldName != "/dev/null" || files[1].NewName != "b/y" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if h := files[1].Hunks; len(h) != 1 || h[0].Replace != "--- not a header\n" {
+		t.Errorf("unexpected hunks for y: %+v", h)
+	}
+	if _, err := ParseMultiFileDiff("--- a/x\n+++ b/x\n--- a/y\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n"); err == nil {
+		t.Error("expected an error for a file with no hunks")
+	}
+}
+
+func TestApplyPatch_SearchReplace(t *testing.T) {
+	// "\treturn\n}" appears twice; the line hint picks the second.
+	got, stats, err := ApplyPatch(patchSource, []PatchHunk{
+		{Search: "package main", Replace: "package app"},
+		{Search: "\treturn\n}", Replace: "\treturn 2\n}", Line: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "package app\n") || !strings.HasSuffix(got, "func b() {\n\treturn 2\n}\n") {
+		t.Errorf("unexpected result %q", got)
+	}
+	if stats.Hunks != 2 || stats.Added != 2 || stats.Removed != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestApplyPatch_Ambiguous(t *testing.T) {
+	_, _, err := ApplyPatch(patchSource, []PatchHunk{{Search: "\treturn\n", Replace: "\treturn 1\n"}})
+	var herr *HunkError
+	if !errors.As(err, &herr) || !strings.Contains(herr.Reason, "matches 2 places") {
+		t.Fatalf("expected an ambiguity error, got %v", err)
+	}
+}
+
+func TestApplyPatch_NotFoundShowsContext(t *testing.T) {
+	text := "one\ntwo\nthree\n"
+	got, _, err := ApplyPatch(text, []PatchHunk{
+		{Search: "one", Replace: "1"},
+		{Search: "  two\nTHREE", Replace: "x"},
+	})
+	var herr *HunkError
+	if !errors.As(err, &herr) {
+		t.Fatalf("expected a HunkError, got %v", err)
+	}
+	if got != text {
+		t.Errorf("text should be unchanged on failure, got %q", got)
+	}
+	if herr.Hunk != 2 || herr.Line != 1 || len(herr.Context) != 3 {
+		t.Errorf("unexpected error %+v", herr)
+	}
+	if !strings.Contains(err.Error(), "    2| two") {
+		t.Errorf("expected numbered context in %q", err.Error())
+	}
+}
+
+func TestParseUnifiedDiff_Rejects(t *testing.T) {
+	for name, diff := range map[string]string{
+		"no hunks":   "just text\n",
+		"truncated":  "@@ -1,3 +1,3 @@\n one\n-two\n",
+		"two files":  "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n--- a/y\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n",
+		"bad header": "@@ -x +1 @@\n+a\n",
+	} {
+		if _, err := ParseUnifiedDiff(diff); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package sys
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrOutsideRoots is wrapped by every OutsideRootsError.
+var ErrOutsideRoots = errors.New("path outside allowed roots")
+
+// OutsideRootsError is returned for a path that resolves, after following
+// symlinks, outside every allowed root.
+type OutsideRootsError struct {
+	Path     string // As requested
+	Resolved string // Where it really points
+}
+
+func (e *OutsideRootsError) Error() string {
+	msg := fmt.Sprintf("%v: %s", ErrOutsideRoots, e.Path)
+	if e.Resolved != "" && e.Resolved != e.Path {
+		msg += " (resolves to " + e.Resolved + ")"
+	}
+	return msg + "; use sys_request_access to ask the user for it"
+}
+
+func (e *OutsideRootsError) Unwrap() error { return ErrOutsideRoots }
+
+// maxSymlinks bounds how many links are followed resolving one path.
+const maxSymlinks = 255
+
+// PathPolicy confines filesystem access to a set of root directories. A
+// path is allowed when it resolves, symlinks included, to a root or
+// something beneath one. Roots can be added at runtime with Grant.
+type PathPolicy struct {
+	mu    sync.RWMutex
+	roots []string // Absolute and symlink-free
+}
+
+// NewPathPolicy allows the given roots. Each may be absolute, relative to
+// the working directory, or start with "~".
+func NewPathPolicy(roots ...string) *PathPolicy {
+	p := &PathPolicy{}
+	for _, r := range roots {
+		p.Grant(r)
+	}
+	return p
+}
+
+// Grant allows root and everything beneath it.
+func (p *PathPolicy) Grant(root string) error {
+	abs, err := filepath.Abs(ExpandHome(root))
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveSymlinks(abs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range p.roots {
+		if r == resolved {
+			return nil
+		}
+	}
+	p.roots = append(p.roots, resolved)
+	return nil
+}
+
+// Roots returns the allowed roots in the order they were added.
+func (p *PathPolicy) Roots() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.roots...)
+}
+
+// Check returns an *OutsideRootsError unless the absolute path abs
+// resolves inside a root. Paths that do not exist yet are judged by their
+// nearest existing parent.
+func (p *PathPolicy) Check(abs string) error {
+	resolved, err := resolveSymlinks(abs)
+	if err != nil {
+		return &OutsideRootsError{Path: abs}
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, r := range p.roots {
+		if within(r, resolved) {
+			return nil
+		}
+	}
+	return &OutsideRootsError{Path: abs, Resolved: resolved}
+}
+
+// IsHome reports whether root is the user's home directory itself, which
+// allows far more than a project needs.
+func IsHome(root string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(ExpandHome(root))
+	return err == nil && abs == filepath.Clean(home)
+}
+
+// ExpandHome replaces a leading "~" with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// resolveSymlinks follows every symlink in the absolute path abs. Missing
+// components are kept as written, so a file about to be created resolves
+// beneath its real parent directory; a dangling link resolves to where
+// it points.
+func resolveSymlinks(abs string) (string, error) {
+	cur, rest := filepath.Clean(abs), ""
+	for links := 0; ; {
+		if r, err := filepath.EvalSymlinks(cur); err == nil {
+			return filepath.Join(r, rest), nil
+		}
+		if fi, err := os.Lstat(cur); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			if links++; links > maxSymlinks {
+				return "", fmt.Errorf("%s: too many links", abs)
+			}
+			target, err := os.Readlink(cur)
+			if err != nil {
+				return "", err
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(cur), target)
+			}
+			cur = filepath.Clean(target)
+			continue
+		}
+		parent := filepath.Dir(cur)
+		if parent == cur {
+			return filepath.Join(cur, rest), nil
+		}
+		rest = filepath.Join(filepath.Base(cur), rest)
+		cur = parent
+	}
+}
@@ -0,0 +1,106 @@
+package sys
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// jailedFS returns a LocalFS on a fresh root confined to it, and a
+// directory outside that root holding secret.txt.
+func jailedFS(t *testing.T) (*LocalFS, string, string) {
+	t.Helper()
+	tmp, _ := filepath.EvalSymlinks(t.TempDir())
+	root, outside := filepath.Join(tmp, "project"), filepath.Join(tmp, "outside")
+	os.MkdirAll(filepath.Join(root, "src"), 0755)
+	os.MkdirAll(outside, 0755)
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0644)
+
+	l := NewLocalFS(root)
+	l.SetPathPolicy(NewPathPolicy(root))
+	return l, root, outside
+}
+
+func expectOutside(t *testing.T, what string, err error) {
+	t.Helper()
+	var oe *OutsideRootsError
+	if !errors.As(err, &oe) || !errors.Is(err, ErrOutsideRoots) {
+		t.Errorf("%s: expected an OutsideRootsError, got %v", what, err)
+		return
+	}
+	if !strings.Contains(err.Error(), "path outside allowed roots") || !strings.Contains(err.Error(), "sys_request_access") {
+		t.Errorf("%s: unhelpful message %q", what, err)
+	}
+}
+
+func TestPathPolicy_Traversal(t *testing.T) {
+	l, _, _ := jailedFS(t)
+	if _, err := l.ReadFile("src/main.go"); err != nil {
+		t.Fatalf("expected a file in the root to be readable: %v", err)
+	}
+	if _, err := l.ReadFile("src/../src/main.go"); err != nil {
+		t.Errorf("expected .. that stays inside the root to be allowed: %v", err)
+	}
+	_, err := l.ReadFile("../outside/secret.txt")
+	expectOutside(t, "read via ..", err)
+	expectOutside(t, "write via ..", l.WriteFile("src/../../outside/new.txt", []byte("x")))
+	_, err = l.ListFiles("..")
+	expectOutside(t, "list the parent", err)
+}
+
+func TestPathPolicy_AbsolutePaths(t *testing.T) {
+	l, root, outside := jailedFS(t)
+	if _, err := l.ReadFile(filepath.Join(root, "src", "main.go")); err != nil {
+		t.Errorf("expected an absolute path inside the root to be allowed: %v", err)
+	}
+	_, err := l.ReadFile(filepath.Join(outside, "secret.txt"))
+	expectOutside(t, "absolute read", err)
+	expectOutside(t, "absolute delete", l.DeleteFile(filepath.Join(outside, "secret.txt")))
+	expectOutside(t, "rename out", l.Rename("src/main.go", filepath.Join(outside, "main.go")))
+	if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+		t.Errorf("expected secret.txt untouched: %v", err)
+	}
+}
+
+func TestPathPolicy_SymlinkEscape(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+	l, root, outside := jailedFS(t)
+	os.Symlink(outside, filepath.Join(root, "escape"))
+	os.Symlink(filepath.Join(outside, "planted.txt"), filepath.Join(root, "dangling"))
+	os.Symlink("src", filepath.Join(root, "inner"))
+
+	_, err := l.ReadFile("escape/secret.txt")
+	expectOutside(t, "read through a link", err)
+	expectOutside(t, "write through a link", l.WriteFile("escape/new.txt", []byte("x")))
+	expectOutside(t, "write through a dangling link", l.WriteFile("dangling", []byte("x")))
+	if _, err := os.Stat(filepath.Join(outside, "planted.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing created outside the root, got %v", err)
+	}
+	if _, err := l.ReadFile("inner/main.go"); err != nil {
+		t.Errorf("expected a link that stays inside the root to be followed: %v", err)
+	}
+}
+
+func TestPathPolicy_Grant(t *testing.T) {
+	l, _, outside := jailedFS(t)
+	l.PathPolicy().Grant(outside)
+	if got, err := l.ReadFile(filepath.Join(outside, "secret.txt")); err != nil || string(got) != "secret\n" {
+		t.Errorf("expected a granted root to be readable, got %q (%v)", got, err)
+	}
+	if n := len(l.PathPolicy().Roots()); n != 2 {
+		t.Errorf("expected 2 roots, got %d", n)
+	}
+}
+
+func TestIsHome(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if !IsHome("~") || !IsHome("~/") || IsHome("~/src") {
+		t.Error("expected only the home directory itself to count")
+	}
+}
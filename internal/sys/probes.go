@@ -0,0 +1,226 @@
+package sys
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+)
+
+const (
+	// probeCacheTTL is how long battery and network readings are reused;
+	// GetSnapshot runs on every request.
+	probeCacheTTL = 30 * time.Second
+	// probeTimeout bounds the network dial and battery commands.
+	probeTimeout = 500 * time.Millisecond
+	// defaultProbeAddr is a public DNS server, dialled to see whether the
+	// network is reachable.
+	defaultProbeAddr = "1.1.1.1:53"
+)
+
+// DiskUsage describes the filesystem holding the working directory.
+type DiskUsage struct {
+	Path        string  `json:"path"`
+	Total       uint64  `json:"total_bytes"`
+	Used        uint64  `json:"used_bytes"`
+	Free        uint64  `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// BatteryStatus is the charge of the first battery found.
+type BatteryStatus struct {
+	Percent  float64 `json:"percent"`
+	Charging bool    `json:"charging"`
+	State    string  `json:"state"` // e.g. charging, discharging, full
+}
+
+// LoadAverage is the 1, 5 and 15 minute load average.
+type LoadAverage struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// NetworkStatus reports whether the network appears to be reachable.
+type NetworkStatus struct {
+	Online    bool   `json:"online"`
+	Interface string `json:"interface,omitempty"` // Primary interface, when known
+}
+
+func (d *DiskUsage) String() string {
+	return fmt.Sprintf("%.1f%% used, %s free of %s", d.UsedPercent, FormatBytes(int64(d.Free)), FormatBytes(int64(d.Total)))
+}
+
+func (b *BatteryStatus) String() string {
+	if b.State == "" {
+		return fmt.Sprintf("%.0f%%", b.Percent)
+	}
+	return fmt.Sprintf("%.0f%% (%s)", b.Percent, b.State)
+}
+
+func (l *LoadAverage) String() string {
+	return fmt.Sprintf("%.2f %.2f %.2f", l.Load1, l.Load5, l.Load15)
+}
+
+func (n *NetworkStatus) String() string {
+	s := "offline"
+	if n.Online {
+		s = "online"
+	}
+	if n.Interface != "" {
+		s += " via " + n.Interface
+	}
+	return s
+}
+
+func diskUsage(path string) *DiskUsage {
+	if path == "" {
+		return nil
+	}
+	u, err := disk.Usage(path)
+	if err != nil || u.Total == 0 {
+		return nil
+	}
+	return &DiskUsage{Path: u.Path, Total: u.Total, Used: u.Used, Free: u.Free, UsedPercent: u.UsedPercent}
+}
+
+func loadAverage() *LoadAverage {
+	if runtime.GOOS == "windows" {
+		return nil // gopsutil only approximates it there
+	}
+	a, err := load.Avg()
+	if err != nil {
+		return nil
+	}
+	return &LoadAverage{Load1: a.Load1, Load5: a.Load5, Load15: a.Load15}
+}
+
+// readBattery tries the platform's battery source, then the Termux API.
+// It returns nil on machines without a battery.
+func readBattery() *BatteryStatus {
+	var b *BatteryStatus
+	switch runtime.GOOS {
+	case "linux", "android":
+		b = sysfsBattery("/sys/class/power_supply")
+	case "darwin":
+		if out, err := runProbe("pmset", "-g", "batt"); err == nil {
+			b = parsePmset(out)
+		}
+	}
+	if b == nil && os.Getenv("TERMUX_VERSION") != "" {
+		if out, err := runProbe("termux-battery-status"); err == nil {
+			b = parseTermuxBattery(out)
+		}
+	}
+	return b
+}
+
+// sysfsBattery reads the first power supply of type Battery under dir.
+func sysfsBattery(dir string) *BatteryStatus {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	read := func(supply, name string) string {
+		raw, _ := os.ReadFile(filepath.Join(dir, supply, name))
+		return strings.TrimSpace(string(raw))
+	}
+	for _, e := range entries {
+		if read(e.Name(), "type") != "Battery" {
+			continue
+		}
+		percent, err := strconv.ParseFloat(read(e.Name(), "capacity"), 64)
+		if err != nil {
+			continue
+		}
+		state := strings.ToLower(read(e.Name(), "status"))
+		return &BatteryStatus{Percent: percent, Charging: state == "charging", State: state}
+	}
+	return nil
+}
+
+var pmsetBattery = regexp.MustCompile(`(\d+)%;\s*([^;]+);`)
+
+// parsePmset reads `pmset -g batt` output such as
+//
+//	-InternalBattery-0 (id=1234)	87%; discharging; 4:12 remaining present: true
+func parsePmset(out string) *BatteryStatus {
+	m := pmsetBattery.FindStringSubmatch(out)
+	if m == nil {
+		return nil
+	}
+	percent, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil
+	}
+	state := strings.TrimSpace(m[2])
+	return &BatteryStatus{Percent: percent, Charging: state == "charging", State: state}
+}
+
+// parseTermuxBattery reads the JSON printed by termux-battery-status.
+func parseTermuxBattery(out string) *BatteryStatus {
+	var v struct {
+		Percentage *float64 `json:"percentage"`
+		Status     string   `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(out), &v); err != nil || v.Percentage == nil {
+		return nil
+	}
+	state := strings.ToLower(v.Status)
+	return &BatteryStatus{Percent: *v.Percentage, Charging: state == "charging", State: state}
+}
+
+func runProbe(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
+
+// probeNetwork dials addr over TCP. The primary interface is the one the
+// system would route addr through, found with a UDP "dial" that sends
+// nothing.
+func probeNetwork(addr string) *NetworkStatus {
+	status := &NetworkStatus{}
+	if conn, err := net.DialTimeout("udp", addr, probeTimeout); err == nil {
+		if local, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+			status.Interface = interfaceFor(local.IP)
+		}
+		conn.Close()
+	}
+	if conn, err := net.DialTimeout("tcp", addr, probeTimeout); err == nil {
+		status.Online = true
+		conn.Close()
+	}
+	return status
+}
+
+func interfaceFor(ip net.IP) string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+				return iface.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package sys
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrStorageLocked is returned when another GC run holds the data dir lock.
+var ErrStorageLocked = errors.New("storage gc already running")
+
+// StoragePolicy is a retention rule for one storage category. Zero values
+// disable the corresponding limit.
+type StoragePolicy struct {
+	MaxAgeDays int `mapstructure:"max_age_days"`
+	MaxSizeMB  int `mapstructure:"max_size_mb"`
+	MaxCount   int `mapstructure:"max_count"`
+}
+
+// StorageItem is one deletable unit: a file, a directory tree or a DB row.
+type StorageItem struct {
+	Category string    `json:"category"`
+	Path     string    `json:"path"` // File path, or "table:id" for DB rows
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+}
+
+// StorageUsage summarises one category.
+type StorageUsage struct {
+	Category string        `json:"category"`
+	Size     int64         `json:"size"`
+	Count    int           `json:"count"`
+	Items    []StorageItem `json:"-"`
+}
+
+// ActiveWindow is how recently an item must have been touched to count as
+// in use; GC never removes such items.
+const ActiveWindow = 10 * time.Minute
+
+// ScanStorageDir lists the top-level entries of dataDir/subdir as items.
+// Directories are sized recursively and dated by their newest file.
+func ScanStorageDir(dataDir, category, subdir string) (StorageUsage, error) {
+	usage := StorageUsage{Category: category}
+	root := filepath.Join(dataDir, subdir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usage, nil
+		}
+		return usage, err
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(root, e.Name())
+		item := StorageItem{Category: category, Path: path}
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if !d.IsDir() {
+				item.Size += info.Size()
+			}
+			if info.ModTime().After(item.ModTime) {
+				item.ModTime = info.ModTime()
+			}
+			return nil
+		})
+		usage.Items = append(usage.Items, item)
+		usage.Size += item.Size
+	}
+	usage.Count = len(usage.Items)
+	return usage, nil
+}
+
+// PlanGC selects the items a policy would delete: everything older than
+// MaxAgeDays, then the oldest remaining items until the category fits in
+// MaxSizeMB and holds at most MaxCount items. Items touched within ActiveWindow and keep(item) == true are
+// never selected.
+func PlanGC(usage StorageUsage, policy StoragePolicy, now time.Time, keep func(StorageItem) bool) []StorageItem {
+	items := append([]StorageItem(nil), usage.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ModTime.Before(items[j].ModTime) })
+
+	deletable := func(it StorageItem) bool {
+		if now.Sub(it.ModTime) < ActiveWindow {
+			return false
+		}
+		return keep == nil || !keep(it)
+	}
+
+	var plan []StorageItem
+	remaining := usage.Size
+	selected := make(map[int]bool)
+
+	if policy.MaxAgeDays > 0 {
+		cutoff := now.AddDate(0, 0, -policy.MaxAgeDays)
+		for i, it := range items {
+			if it.ModTime.Before(cutoff) && deletable(it) {
+				plan = append(plan, it)
+				selected[i] = true
+				remaining -= it.Size
+			}
+		}
+	}
+
+	if policy.MaxSizeMB > 0 {
+		limit := int64(policy.MaxSizeMB) << 20
+		for i, it := range items {
+			if remaining <= limit {
+				break
+			}
+			if selected[i] || !deletable(it) {
+				continue
+			}
+			plan = append(plan, it)
+			selected[i] = true
+			remaining -= it.Size
+		}
+	}
+
+	if policy.MaxCount > 0 {
+		count := len(items) - len(plan)
+		for i, it := range items {
+			if count <= policy.MaxCount {
+				break
+			}
+			if selected[i] || !deletable(it) {
+				continue
+			}
+			plan = append(plan, it)
+			selected[i] = true
+			count--
+		}
+	}
+	return plan
+}
+
+// LockDataDir takes an exclusive, advisory GC lock in dataDir. A lock older
+// than an hour is treated as left behind by a crashed run.
+func LockDataDir(dataDir string) (func(), error) {
+	path := filepath.Join(dataDir, "gc.lock")
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) < time.Hour {
+			return nil, ErrStorageLocked
+		}
+		os.Remove(path)
+	}
+	return nil, ErrStorageLocked
+}
+
+// FileSize returns the combined size of the given files, ignoring missing ones.
+func FileSize(paths ...string) int64 {
+	var total int64
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// FormatBytes renders a byte count with a binary unit.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,69 @@
+package sys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanGC(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	usage := StorageUsage{
+		Category: "trash",
+		Items: []StorageItem{
+			{Path: "old", Size: 1 << 20, ModTime: now.Add(-40 * day)},
+			{Path: "kept", Size: 1 << 20, ModTime: now.Add(-50 * day)},
+			{Path: "mid", Size: 3 << 20, ModTime: now.Add(-5 * day)},
+			{Path: "new", Size: 3 << 20, ModTime: now.Add(-1 * day)},
+			{Path: "active", Size: 3 << 20, ModTime: now.Add(-time.Minute)},
+		},
+	}
+	for _, it := range usage.Items {
+		usage.Size += it.Size
+	}
+
+	keep := func(it StorageItem) bool { return it.Path == "kept" }
+	plan := PlanGC(usage, StoragePolicy{MaxAgeDays: 30, MaxSizeMB: 6}, now, keep)
+
+	var got []string
+	for _, it := range plan {
+		got = append(got, it.Path)
+	}
+	// Age removes "old"; size then removes the oldest deletable items until
+	// the rest fits in 6 MiB. "kept" and "active" are never touched.
+	want := []string{"old", "mid", "new"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestPlanGC_MaxCount(t *testing.T) {
+	now := time.Now()
+	usage := StorageUsage{Category: "undo"}
+	for i, name := range []string{"a", "b", "c", "d"} {
+		usage.Items = append(usage.Items, StorageItem{Path: name, Size: 1, ModTime: now.Add(-time.Duration(4-i) * time.Hour)})
+	}
+	plan := PlanGC(usage, StoragePolicy{MaxCount: 2}, now, nil)
+	if len(plan) != 2 || plan[0].Path != "a" || plan[1].Path != "b" {
+		t.Fatalf("expected the two oldest items, got %+v", plan)
+	}
+}
+
+func TestLockDataDir(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockDataDir(dir)
+	if err != nil {
+		t.Fatalf("first lock: %v", err)
+	}
+	if _, err := LockDataDir(dir); !errors.Is(err, ErrStorageLocked) {
+		t.Fatalf("expected ErrStorageLocked, got %v", err)
+	}
+	unlock()
+	unlock2, err := LockDataDir(dir)
+	if err != nil {
+		t.Fatalf("relock after unlock: %v", err)
+	}
+	unlock2()
+}
@@ -3,23 +3,75 @@ package sys
 import (
 	"fmt"
 	"os"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
-// Snapshot represents the current system state
+// Snapshot represents the current system state. Disk, Battery, Load and
+// Network are nil when they cannot be read on this system.
 type Snapshot struct {
-	CPUUsage    float64
-	MemoryUsage float64
-	WorkingDir  string
+	CPUUsage    float64        `json:"cpu_usage"`
+	MemoryUsage float64        `json:"memory_usage"`
+	MemoryUsed  uint64         `json:"memory_used"` // Bytes
+	Goroutines  int            `json:"goroutines"`  // In this process
+	WorkingDir  string         `json:"working_dir"`
+	Disk        *DiskUsage     `json:"disk,omitempty"`
+	Battery     *BatteryStatus `json:"battery,omitempty"`
+	Load        *LoadAverage   `json:"load,omitempty"`
+	Network     *NetworkStatus `json:"network,omitempty"`
+	TakenAt     time.Time      `json:"taken_at"`
 }
 
-// Monitor provides system awareness
-type Monitor struct{}
+// SnapshotDelta is how resource usage moved between two snapshots.
+type SnapshotDelta struct {
+	CPU        float64       `json:"cpu"`       // Percentage points
+	MemoryMB   float64       `json:"memory_mb"` // Used memory, in MiB
+	Goroutines int           `json:"goroutines"`
+	Elapsed    time.Duration `json:"elapsed"`
+}
+
+// Since returns the change from prev to s.
+func (s Snapshot) Since(prev Snapshot) SnapshotDelta {
+	return SnapshotDelta{
+		CPU:        s.CPUUsage - prev.CPUUsage,
+		MemoryMB:   (float64(s.MemoryUsed) - float64(prev.MemoryUsed)) / (1 << 20),
+		Goroutines: s.Goroutines - prev.Goroutines,
+		Elapsed:    s.TakenAt.Sub(prev.TakenAt),
+	}
+}
+
+// String formats the delta with explicit signs, e.g.
+// "CPU +3.2%, memory -120 MB, goroutines +4".
+func (d SnapshotDelta) String() string {
+	return fmt.Sprintf("CPU %+.1f%%, memory %+.0f MB, goroutines %+d", d.CPU, d.MemoryMB, d.Goroutines)
+}
+
+// Monitor provides system awareness. The slower probes, battery and
+// network, are cached for probeCacheTTL.
+type Monitor struct {
+	mu        sync.Mutex
+	battery   *BatteryStatus
+	batteryAt time.Time
+	network   *NetworkStatus
+	networkAt time.Time
+
+	probeAddr string // Dialled to see whether the network is up
+	now       func() time.Time
+}
 
 func NewMonitor() *Monitor {
-	return &Monitor{}
+	return &Monitor{probeAddr: defaultProbeAddr, now: time.Now}
+}
+
+func (m *Monitor) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
 }
 
 // GetSnapshot returns a current snapshot of system resources
@@ -39,7 +91,39 @@ func (m *Monitor) GetSnapshot() (Snapshot, error) {
 	return Snapshot{
 		CPUUsage:    c[0],
 		MemoryUsage: vm.UsedPercent,
+		MemoryUsed:  vm.Used,
+		Goroutines:  runtime.NumGoroutine(),
 		WorkingDir:  wd,
+		Disk:        diskUsage(wd),
+		Battery:     m.cachedBattery(),
+		Load:        loadAverage(),
+		Network:     m.cachedNetwork(),
+		TakenAt:     m.clock(),
 	}, nil
 }
 
+func (m *Monitor) cachedBattery() *BatteryStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock()
+	if !m.batteryAt.IsZero() && now.Sub(m.batteryAt) < probeCacheTTL {
+		return m.battery
+	}
+	m.battery, m.batteryAt = readBattery(), now
+	return m.battery
+}
+
+func (m *Monitor) cachedNetwork() *NetworkStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock()
+	if !m.networkAt.IsZero() && now.Sub(m.networkAt) < probeCacheTTL {
+		return m.network
+	}
+	addr := m.probeAddr
+	if addr == "" {
+		addr = defaultProbeAddr
+	}
+	m.network, m.networkAt = probeNetwork(addr), now
+	return m.network
+}
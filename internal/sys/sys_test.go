@@ -1,7 +1,11 @@
 package sys
 
 import (
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestMonitor_GetSnapshot(t *testing.T) {
@@ -18,5 +22,81 @@ func TestMonitor_GetSnapshot(t *testing.T) {
 	if snapshot.MemoryUsage < 0 || snapshot.MemoryUsage > 100 {
 		t.Errorf("Invalid Memory usage: %f", snapshot.MemoryUsage)
 	}
+
+	if snapshot.Goroutines < 1 || snapshot.TakenAt.IsZero() {
+		t.Errorf("expected goroutines and a timestamp, got %d at %v", snapshot.Goroutines, snapshot.TakenAt)
+	}
+}
+
+func TestSnapshot_Since(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prev := Snapshot{CPUUsage: 20, MemoryUsed: 3 << 30, Goroutines: 12, TakenAt: at}
+	cur := Snapshot{CPUUsage: 12.5, MemoryUsed: 3<<30 + 150<<20, Goroutines: 16, TakenAt: at.Add(90 * time.Second)}
+
+	d := cur.Since(prev)
+	if d.Elapsed != 90*time.Second {
+		t.Errorf("elapsed = %s", d.Elapsed)
+	}
+	if got, want := d.String(), "CPU -7.5%, memory +150 MB, goroutines +4"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
 }
 
+func TestSysfsBattery(t *testing.T) {
+	dir := t.TempDir()
+	write := func(supply, name, value string) {
+		os.MkdirAll(filepath.Join(dir, supply), 0755)
+		os.WriteFile(filepath.Join(dir, supply, name), []byte(value+"\n"), 0644)
+	}
+	write("AC", "type", "Mains")
+	write("BAT0", "type", "Battery")
+	write("BAT0", "capacity", "64")
+	write("BAT0", "status", "Charging")
+
+	b := sysfsBattery(dir)
+	if b == nil || b.Percent != 64 || !b.Charging || b.State != "charging" {
+		t.Errorf("unexpected battery %+v", b)
+	}
+	if b := sysfsBattery(filepath.Join(dir, "missing")); b != nil {
+		t.Errorf("expected no battery, got %+v", b)
+	}
+}
+
+func TestParseBattery(t *testing.T) {
+	pmset := "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t87%; discharging; 4:12 remaining present: true\n"
+	if b := parsePmset(pmset); b == nil || b.Percent != 87 || b.Charging || b.State != "discharging" {
+		t.Errorf("unexpected pmset battery %+v", b)
+	}
+	if b := parsePmset("Now drawing from 'AC Power'\n"); b != nil {
+		t.Errorf("expected no battery on a desktop Mac, got %+v", b)
+	}
+
+	termux := `{"health": "GOOD", "percentage": 41, "plugged": "PLUGGED_AC", "status": "CHARGING", "temperature": 30.2}`
+	if b := parseTermuxBattery(termux); b == nil || b.Percent != 41 || !b.Charging {
+		t.Errorf("unexpected termux battery %+v", b)
+	}
+	if b := parseTermuxBattery("not json"); b != nil {
+		t.Errorf("expected nil for bad output, got %+v", b)
+	}
+}
+
+func TestMonitor_NetworkCached(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback listener:", err)
+	}
+	now := time.Unix(1000, 0)
+	m := &Monitor{probeAddr: ln.Addr().String(), now: func() time.Time { return now }}
+
+	if n := m.cachedNetwork(); n == nil || !n.Online {
+		t.Fatalf("expected online, got %+v", n)
+	}
+	ln.Close()
+	if n := m.cachedNetwork(); !n.Online {
+		t.Error("expected the cached reading within the TTL")
+	}
+	now = now.Add(probeCacheTTL)
+	if n := m.cachedNetwork(); n.Online {
+		t.Error("expected a fresh probe after the TTL to find the listener gone")
+	}
+}
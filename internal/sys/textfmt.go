@@ -0,0 +1,327 @@
+package sys
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNonUTF8 is returned when a write would re-encode a file that is not
+// valid UTF-8 and the caller did not name an explicit encoding.
+var ErrNonUTF8 = errors.New("file is not valid UTF-8; pass an explicit encoding (e.g. latin1) to overwrite it")
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Supported explicit encodings.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingLatin1  = "latin1"
+)
+
+// TextFormat captures the on-disk conventions of a text file.
+type TextFormat struct {
+	EOL          string `json:"eol"`           // "\n" or "\r\n"
+	BOM          bool   `json:"bom"`           // UTF-8 byte order mark
+	FinalNewline bool   `json:"final_newline"` // File ends with EOL
+	Charset      string `json:"charset"`       // utf-8, utf-8-bom, latin1
+	IndentStyle  string `json:"indent_style,omitempty"`
+	IndentSize   int    `json:"indent_size,omitempty"`
+
+	// finalNewlineSet is true when FinalNewline should be enforced rather
+	// than left as the caller wrote it (new files without .editorconfig).
+	finalNewlineSet bool
+}
+
+// Hint returns a short human-readable summary for the agent.
+func (f TextFormat) Hint() string {
+	var parts []string
+	if f.EOL == "\r\n" {
+		parts = append(parts, "CRLF line endings")
+	}
+	if f.BOM {
+		parts = append(parts, "UTF-8 BOM")
+	}
+	if f.Charset == EncodingLatin1 {
+		parts = append(parts, "latin1 encoding")
+	}
+	if f.IndentStyle != "" {
+		indent := "indent: " + f.IndentStyle
+		if f.IndentSize > 0 {
+			indent += " (" + strconv.Itoa(f.IndentSize) + ")"
+		}
+		parts = append(parts, indent)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DetectFormat inspects existing file content.
+func DetectFormat(content []byte) TextFormat {
+	f := TextFormat{EOL: "\n", Charset: EncodingUTF8}
+	if bytes.HasPrefix(content, utf8BOM) {
+		f.BOM = true
+		f.Charset = EncodingUTF8BOM
+		content = content[len(utf8BOM):]
+	}
+	if !utf8.Valid(content) {
+		f.Charset = EncodingLatin1
+	}
+	if crlf := bytes.Count(content, []byte("\r\n")); crlf > 0 && crlf*2 >= bytes.Count(content, []byte("\n")) {
+		f.EOL = "\r\n"
+	}
+	f.FinalNewline = bytes.HasSuffix(content, []byte("\n"))
+	f.finalNewlineSet = len(content) > 0
+	return f
+}
+
+// ResolveFormat decides how content should be written to path: detection
+// from the existing file first, then .editorconfig on top.
+func ResolveFormat(path string, existing []byte, exists bool) TextFormat {
+	f := TextFormat{EOL: "\n", Charset: EncodingUTF8}
+	if exists {
+		f = DetectFormat(existing)
+	}
+	LoadEditorConfig(path).apply(&f)
+	return f
+}
+
+// EncodeText converts UTF-8, LF-normalised content into the given format.
+func EncodeText(content []byte, f TextFormat) ([]byte, error) {
+	text := bytes.TrimPrefix(content, utf8BOM)
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+
+	if f.finalNewlineSet && len(text) > 0 {
+		if f.FinalNewline && !bytes.HasSuffix(text, []byte("\n")) {
+			text = append(text, '\n')
+		} else if !f.FinalNewline {
+			text = bytes.TrimRight(text, "\n")
+		}
+	}
+
+	if f.EOL == "\r\n" {
+		text = bytes.ReplaceAll(text, []byte("\n"), []byte("\r\n"))
+	}
+
+	switch f.Charset {
+	case EncodingLatin1:
+		out := make([]byte, 0, len(text))
+		for _, r := range string(text) {
+			if r > 0xFF {
+				return nil, fmt.Errorf("character %q cannot be encoded as latin1", r)
+			}
+			out = append(out, byte(r))
+		}
+		return out, nil
+	case EncodingUTF8BOM:
+		f.BOM = true
+	}
+
+	if f.BOM {
+		return append(append([]byte{}, utf8BOM...), text...), nil
+	}
+	return text, nil
+}
+
+// DecodeText returns UTF-8, LF-normalised text for editing along with the
+// detected format so it can be restored on save.
+func DecodeText(raw []byte) (string, TextFormat) {
+	f := DetectFormat(raw)
+	body := bytes.TrimPrefix(raw, utf8BOM)
+	if f.Charset == EncodingLatin1 {
+		runes := make([]rune, len(body))
+		for i, c := range body {
+			runes[i] = rune(c)
+		}
+		body = []byte(string(runes))
+	}
+	return strings.ReplaceAll(string(body), "\r\n", "\n"), f
+}
+
+// EditorConfig holds the resolved .editorconfig properties for one file.
+type EditorConfig struct {
+	Props map[string]string
+}
+
+// LoadEditorConfig resolves .editorconfig files from the file's directory
+// upwards, stopping at one declaring root = true. Nearer files win.
+func LoadEditorConfig(path string) EditorConfig {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return EditorConfig{}
+	}
+
+	var files []string
+	dir := filepath.Dir(abs)
+	for {
+		cfg := filepath.Join(dir, ".editorconfig")
+		if _, err := os.Stat(cfg); err == nil {
+			files = append(files, cfg)
+			if isRootEditorConfig(cfg) {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := make(map[string]string)
+	for i := len(files) - 1; i >= 0; i-- {
+		parseEditorConfig(files[i], abs, props)
+	}
+	return EditorConfig{Props: props}
+}
+
+func (ec EditorConfig) apply(f *TextFormat) {
+	switch ec.Props["end_of_line"] {
+	case "crlf":
+		f.EOL = "\r\n"
+	case "lf":
+		f.EOL = "\n"
+	}
+	switch ec.Props["insert_final_newline"] {
+	case "true":
+		f.FinalNewline, f.finalNewlineSet = true, true
+	case "false":
+		f.FinalNewline, f.finalNewlineSet = false, true
+	}
+	switch ec.Props["charset"] {
+	case "utf-8":
+		f.Charset, f.BOM = EncodingUTF8, false
+	case "utf-8-bom":
+		f.Charset, f.BOM = EncodingUTF8BOM, true
+	case "latin1":
+		f.Charset, f.BOM = EncodingLatin1, false
+	}
+	if style := ec.Props["indent_style"]; style != "" {
+		f.IndentStyle = style
+	}
+	if size, err := strconv.Atoi(ec.Props["indent_size"]); err == nil {
+		f.IndentSize = size
+	}
+}
+
+func isRootEditorConfig(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			return false
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(strings.ToLower(k)) == "root" {
+			return strings.TrimSpace(strings.ToLower(v)) == "true"
+		}
+	}
+	return false
+}
+
+func parseEditorConfig(cfgPath, target string, props map[string]string) {
+	file, err := os.Open(cfgPath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	rel, err := filepath.Rel(filepath.Dir(cfgPath), target)
+	if err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+
+	matched := false
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			matched = editorConfigMatch(line[1:len(line)-1], rel)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			props[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+		}
+	}
+}
+
+// editorConfigMatch reports whether a section glob matches rel (a slash
+// separated path relative to the .editorconfig directory).
+func editorConfigMatch(glob, rel string) bool {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	glob = strings.TrimPrefix(glob, "/")
+	re, err := regexp.Compile("^" + editorConfigRegex(glob) + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(rel)
+}
+
+func editorConfigRegex(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '{':
+			end := strings.IndexByte(glob[i:], '}')
+			if end == -1 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			var alts []string
+			for _, alt := range strings.Split(glob[i+1:i+end], ",") {
+				alts = append(alts, editorConfigRegex(alt))
+			}
+			sb.WriteString("(?:" + strings.Join(alts, "|") + ")")
+			i += end
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end == -1 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
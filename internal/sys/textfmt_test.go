@@ -0,0 +1,97 @@
+package sys
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFS_PreservesCRLFThroughEdit(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewLocalFS(dir)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\r\ntwo\r\nthree\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Patch-style edit with LF-only search/replace text.
+	if err := fs.Edit("a.txt", "two\nthree", "2\n3\n4"); err != nil {
+		t.Fatalf("Edit failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(dir, "a.txt"))
+	if want := "one\r\n2\r\n3\r\n4\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLocalFS_RetainsBOM(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewLocalFS(dir)
+	path := filepath.Join(dir, "bom.csv")
+	if err := os.WriteFile(path, append([]byte{0xEF, 0xBB, 0xBF}, "a,b\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.WriteFile("bom.csv", []byte("a,b\n1,2\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(got, []byte{0xEF, 0xBB, 0xBF}) {
+		t.Errorf("BOM was dropped: %q", got)
+	}
+	if string(got[3:]) != "a,b\n1,2\n" {
+		t.Errorf("unexpected body: %q", got[3:])
+	}
+}
+
+func TestLocalFS_EditorConfigOverridesDetection(t *testing.T) {
+	dir := t.TempDir()
+	ec := "root = true\n\n[*]\nend_of_line = lf\n\n[*.bat]\nend_of_line = crlf\ninsert_final_newline = true\n"
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(ec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "scripts")
+	os.MkdirAll(sub, 0755)
+	// Existing file uses LF, but .editorconfig asks for CRLF.
+	if err := os.WriteFile(filepath.Join(sub, "run.bat"), []byte("echo hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewLocalFS(dir)
+	if err := fs.WriteFile("scripts/run.bat", []byte("echo hi\necho bye")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(sub, "run.bat"))
+	if want := "echo hi\r\necho bye\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if f := fs.Format("notes.md"); f.EOL != "\n" {
+		t.Errorf("expected lf for *.md, got %q", f.EOL)
+	}
+}
+
+func TestLocalFS_RefusesNonUTF8(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewLocalFS(dir)
+	path := filepath.Join(dir, "latin.txt")
+	if err := os.WriteFile(path, []byte("caf\xe9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.WriteFile("latin.txt", []byte("café au lait\n")); !errors.Is(err, ErrNonUTF8) {
+		t.Fatalf("expected ErrNonUTF8, got %v", err)
+	}
+
+	if err := fs.WriteFileEncoding("latin.txt", []byte("café au lait\n"), EncodingLatin1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "caf\xe9 au lait\n" {
+		t.Errorf("unexpected latin1 bytes: %q", got)
+	}
+}
@@ -0,0 +1,273 @@
+package sys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// undoLedger is the journal file kept beside the backups in <datadir>/undo.
+const undoLedger = "journal.json"
+
+// UndoEntry records one file write that can be reverted.
+type UndoEntry struct {
+	ID        string    `json:"id"`               // Backup directory name, unique per write
+	Path      string    `json:"path"`             // Absolute path that was written
+	Backup    string    `json:"backup,omitempty"` // Copy of the previous content; empty if the write created the file
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UndoJournal backs files up before they are overwritten, into
+// <datadir>/undo/<id>/<relative-path>, and records each write in a JSON
+// ledger so it can be listed and restored. Backups beyond the policy's
+// count, size and age limits are pruned oldest first; the newest is always
+// kept.
+type UndoJournal struct {
+	mu     sync.Mutex
+	dir    string
+	base   string
+	policy StoragePolicy
+}
+
+// NewUndoJournal keeps backups under dataDir/undo. Paths inside base are
+// stored relative to it; others mirror their absolute path.
+func NewUndoJournal(dataDir, base string, policy StoragePolicy) *UndoJournal {
+	return &UndoJournal{dir: filepath.Join(dataDir, "undo"), base: base, policy: policy}
+}
+
+// Backup records a pending write to path, copying the current file if it
+// exists. Call it before writing.
+func (j *UndoJournal) Backup(path string) (*UndoEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	entry := UndoEntry{ID: j.newID(entries), Path: path, CreatedAt: time.Now()}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && !info.Mode().IsRegular():
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	case err == nil:
+		backup := filepath.Join(j.dir, entry.ID, j.relative(path))
+		if err := copyFile(path, backup); err != nil {
+			os.RemoveAll(filepath.Join(j.dir, entry.ID))
+			return nil, fmt.Errorf("backing up %s: %w", path, err)
+		}
+		entry.Backup = backup
+		entry.Size = info.Size()
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	entries = j.prune(append(entries, entry))
+	if err := j.save(entries); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Forget drops an entry and its backup, for a write that did not happen.
+func (j *UndoJournal) Forget(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.load()
+	if err != nil {
+		return err
+	}
+	for i, e := range entries {
+		if e.ID == id {
+			os.RemoveAll(filepath.Join(j.dir, id))
+			return j.save(append(entries[:i], entries[i+1:]...))
+		}
+	}
+	return nil
+}
+
+// List returns the restorable writes, newest first. Entries whose backup
+// has been removed by storage GC are left out.
+func (j *UndoJournal) List() ([]UndoEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	var out []UndoEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e := entries[i]; e.Backup == "" || fileExists(e.Backup) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Restore reverts the write recorded as id: the backup is copied back, or
+// the file is removed if the write created it. The entry is then dropped.
+func (j *UndoJournal) Restore(id string) (*UndoEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	idx := -1
+	for i, e := range entries {
+		if e.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("no undo entry %q", id)
+	}
+
+	entry := entries[idx]
+	if entry.Backup == "" {
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		if !fileExists(entry.Backup) {
+			return nil, fmt.Errorf("the backup of %s has been removed", entry.Path)
+		}
+		if err := copyFile(entry.Backup, entry.Path); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", entry.Path, err)
+		}
+	}
+
+	os.RemoveAll(filepath.Join(j.dir, entry.ID))
+	if err := j.save(append(entries[:idx], entries[idx+1:]...)); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// prune drops the oldest entries until the journal is within policy,
+// deleting their backups.
+func (j *UndoJournal) prune(entries []UndoEntry) []UndoEntry {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	cutoff := time.Time{}
+	if j.policy.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -j.policy.MaxAgeDays)
+	}
+	limit := int64(j.policy.MaxSizeMB) << 20
+
+	drop := 0
+	for drop < len(entries)-1 {
+		e := entries[drop]
+		over := (j.policy.MaxCount > 0 && len(entries)-drop > j.policy.MaxCount) ||
+			(limit > 0 && total > limit) ||
+			e.CreatedAt.Before(cutoff)
+		if !over {
+			break
+		}
+		os.RemoveAll(filepath.Join(j.dir, e.ID))
+		total -= e.Size
+		drop++
+	}
+	return entries[drop:]
+}
+
+// relative places path under a backup directory.
+func (j *UndoJournal) relative(path string) string {
+	if rel, err := filepath.Rel(j.base, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+	// Outside the workspace: mirror the absolute path.
+	return strings.TrimLeft(strings.TrimPrefix(path, filepath.VolumeName(path)), `/\`)
+}
+
+// newID names a backup directory by time, adding a suffix when two writes
+// land in the same instant.
+func (j *UndoJournal) newID(entries []UndoEntry) string {
+	used := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		used[e.ID] = true
+	}
+	base := time.Now().UTC().Format("20060102-150405.000000")
+	id := base
+	for n := 2; used[id] || fileExists(filepath.Join(j.dir, id)); n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+func (j *UndoJournal) load() ([]UndoEntry, error) {
+	data, err := os.ReadFile(filepath.Join(j.dir, undoLedger))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []UndoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("reading undo journal: %w", err)
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].CreatedAt.Before(entries[b].CreatedAt) })
+	return entries, nil
+}
+
+func (j *UndoJournal) save(entries []UndoEntry) error {
+	if err := os.MkdirAll(j.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(j.dir, undoLedger+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(j.dir, undoLedger))
+}
+
+// copyFile copies src to dst, creating dst's directory and keeping src's
+// permissions.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,77 @@
+package sys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoJournal_BackupAndRestore(t *testing.T) {
+	work, data := t.TempDir(), t.TempDir()
+	j := NewUndoJournal(data, work, StoragePolicy{})
+
+	path := filepath.Join(work, "src", "main.go")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("original"), 0644)
+
+	entry, err := j.Backup(path)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if want := filepath.Join(data, "undo", entry.ID, "src", "main.go"); entry.Backup != want {
+		t.Errorf("expected backup at %s, got %s", want, entry.Backup)
+	}
+	os.WriteFile(path, []byte("overwritten"), 0644)
+
+	created := filepath.Join(work, "new.txt")
+	newEntry, err := j.Backup(created)
+	if err != nil || newEntry.Backup != "" {
+		t.Fatalf("expected an entry without backup for a new file, got %+v (%v)", newEntry, err)
+	}
+	os.WriteFile(created, []byte("new"), 0644)
+
+	entries, _ := j.List()
+	if len(entries) != 2 || entries[0].ID != newEntry.ID {
+		t.Fatalf("expected two entries, newest first, got %+v", entries)
+	}
+
+	if _, err := j.Restore(entry.ID); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "original" {
+		t.Errorf("expected the original content back, got %q", got)
+	}
+	if _, err := j.Restore(newEntry.ID); err != nil {
+		t.Fatalf("Restore of a created file failed: %v", err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected the created file to be removed, got %v", err)
+	}
+	if entries, _ := j.List(); len(entries) != 0 {
+		t.Errorf("expected restored entries to be dropped, got %+v", entries)
+	}
+}
+
+func TestUndoJournal_PrunesOldest(t *testing.T) {
+	work, data := t.TempDir(), t.TempDir()
+	j := NewUndoJournal(data, work, StoragePolicy{MaxCount: 2})
+
+	path := filepath.Join(work, "f.txt")
+	var ids []string
+	for _, content := range []string{"one", "two", "three"} {
+		os.WriteFile(path, []byte(content), 0644)
+		e, err := j.Backup(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	entries, _ := j.List()
+	if len(entries) != 2 || entries[1].ID != ids[1] {
+		t.Fatalf("expected the two newest entries, got %+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(data, "undo", ids[0])); !os.IsNotExist(err) {
+		t.Errorf("expected the pruned backup to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// pathPolicer is implemented by filesystems confined by a sys.PathPolicy.
+type pathPolicer interface {
+	PathPolicy() *sys.PathPolicy
+}
+
+// resolver is implemented by filesystems that check a path against their
+// policy before touching it.
+type resolver interface {
+	Resolve(path string) (string, error)
+}
+
+// checkedPath returns the path a tool working on f outside the FS
+// interface (os.Stat, ripgrep) may use, or the policy's refusal.
+func checkedPath(f sys.FS, path string) (string, error) {
+	if r, ok := f.(resolver); ok {
+		return r.Resolve(path)
+	}
+	return path, nil
+}
+
+// policyCheck returns a func refusing paths outside f's policy, or nil if
+// f is not confined.
+func policyCheck(f sys.FS) func(path string) error {
+	if _, ok := f.(resolver); !ok {
+		return nil
+	}
+	return func(path string) error {
+		_, err := checkedPath(f, path)
+		return err
+	}
+}
+
+// RequestAccessTool asks the user to let the filesystem tools reach a
+// path outside the allowed roots (security.fs_roots). It needs
+// PermElevated, so every call goes through the Enclave; once approved the
+// path is allowed for the rest of the session.
+type RequestAccessTool struct {
+	fs sys.FS
+}
+
+func NewRequestAccessTool(f sys.FS) *RequestAccessTool {
+	return &RequestAccessTool{fs: f}
+}
+
+func (t *RequestAccessTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_request_access",
+		Description: "Ask the user to allow file tools to reach a file or directory outside the allowed roots (the working directory by default). Call it when a file tool fails with \"path outside allowed roots\"; explain why in reason.",
+		Source:      "system",
+		Category:    CategoryFileSystem,
+		Roles:       []AgentRole{RoleAll},
+		Complexity:  2,
+		Permissions: []Permission{PermElevated},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "File or directory to allow, absolute or starting with ~; everything beneath a directory is allowed"},
+				"reason": {"type": "string", "description": "Why the task needs it, shown to the user"}
+			},
+			"required": ["path", "reason"]
+		}`),
+		Examples: []string{
+			`{"tool": "sys_request_access", "parameters": {"path": "~/.config/nvim", "reason": "The user asked to fix their editor config"}}`,
+		},
+	}
+}
+
+// accessInput is the sys_request_access call, also read by the Enclave.
+type accessInput struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// accessPath is the absolute path a sys_request_access call is for.
+func accessPath(f sys.FS, path string) (string, error) {
+	path = sys.ExpandHome(strings.TrimSpace(path))
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	if !filepath.IsAbs(path) {
+		base := ""
+		if b, ok := f.(interface{ BaseDir() string }); ok {
+			base = b.BaseDir()
+		}
+		path = filepath.Join(base, path)
+	}
+	return filepath.Abs(path)
+}
+
+func (t *RequestAccessTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input accessInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	path, err := accessPath(t.fs, input.Path)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	p, ok := t.fs.(pathPolicer)
+	if !ok || p.PathPolicy() == nil {
+		return &ToolResult{Status: "success", Content: "File tools are not confined; " + path + " is already allowed."}, nil
+	}
+	if err := p.PathPolicy().Grant(path); err != nil {
+		err = fmt.Errorf("allowing %s: %w", path, err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if sys.IsHome(path) {
+		ReportStatus("⚠️", "security", "File tools may now reach your whole home directory for this session")
+	}
+	ReportStatus("🔓", "security", "Allowed file access to "+path)
+	return &ToolResult{
+		Status:  "success",
+		Content: fmt.Sprintf("Access to %s allowed for this session; retry the file operation.", path),
+		Data:    map[string]string{"path": path},
+	}, nil
+}
@@ -0,0 +1,117 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestRequestAccessTool_GrantsAfterApproval(t *testing.T) {
+	tmp, _ := filepath.EvalSymlinks(t.TempDir())
+	root, outside := filepath.Join(tmp, "project"), filepath.Join(tmp, "notes")
+	os.MkdirAll(root, 0755)
+	os.MkdirAll(outside, 0755)
+	secret := filepath.Join(outside, "todo.txt")
+	os.WriteFile(secret, []byte("buy milk\n"), 0644)
+
+	lfs := sys.NewLocalFS(root)
+	lfs.SetPathPolicy(sys.NewPathPolicy(root))
+	enclave, err := NewEnclave(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard := NewSecurityGuard()
+	guard.SetContextInterceptor(enclave.InterceptorContext)
+	read := WrapWithSecurity(NewReadFileTool(lfs), guard)
+	stat := WrapWithSecurity(NewFileStatsTool(lfs), guard)
+	request := WrapWithSecurity(NewRequestAccessTool(lfs), guard)
+
+	readArgs, _ := json.Marshal(map[string]string{"path": secret})
+	if _, err := read.Execute(context.Background(), readArgs); !errors.Is(err, sys.ErrOutsideRoots) || !strings.Contains(err.Error(), "sys_request_access") {
+		t.Fatalf("expected the read to be refused with a pointer to sys_request_access, got %v", err)
+	}
+	if _, err := stat.Execute(context.Background(), readArgs); !errors.Is(err, sys.ErrOutsideRoots) {
+		t.Errorf("expected fs_stat to be refused too, got %v", err)
+	}
+
+	// Reads are auto-approved, but asking for access always goes to the user.
+	reqArgs, _ := json.Marshal(map[string]string{"path": outside, "reason": "the user keeps notes there"})
+	_, err = request.Execute(context.Background(), reqArgs)
+	var ie *InterventionError
+	if !errors.As(err, &ie) || ie.Risk != "high" || !strings.Contains(ie.Title, outside) {
+		t.Fatalf("expected the Enclave to ask, got %v", err)
+	}
+	if _, err := read.Execute(context.Background(), readArgs); err == nil {
+		t.Fatal("expected no access before the user answers")
+	}
+
+	if _, err := ie.Resume(ChoiceApproveOnce); err != nil {
+		t.Fatalf("approved request failed: %v", err)
+	}
+	res, err := read.Execute(context.Background(), readArgs)
+	if err != nil || res.Content != "buy milk\n" {
+		t.Fatalf("expected the granted path to be readable, got %+v (%v)", res, err)
+	}
+}
+
+func TestTraversalTool_StaysInRoots(t *testing.T) {
+	tmp, _ := filepath.EvalSymlinks(t.TempDir())
+	root := filepath.Join(tmp, "project")
+	os.MkdirAll(root, 0755)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(tmp, "secret.txt"), []byte("hunter2\n"), 0644)
+
+	lfs := sys.NewLocalFS(root)
+	lfs.SetPathPolicy(sys.NewPathPolicy(root))
+	tool := NewTraversalTool(lfs)
+
+	res, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := res.Data.([]string); len(files) != 1 || files[0] != "main.go" {
+		t.Errorf("expected only main.go, got %v", files)
+	}
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"path": "../"}`))
+	var outside *sys.OutsideRootsError
+	if !errors.As(err, &outside) {
+		t.Errorf("expected an OutsideRootsError for ../, got %v", err)
+	}
+}
+
+func TestGrepWalk_SkipsSymlinkEscape(t *testing.T) {
+	tmp, _ := filepath.EvalSymlinks(t.TempDir())
+	root := filepath.Join(tmp, "project")
+	os.MkdirAll(root, 0755)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("password = \"dummy\"\n"), 0644)
+	secret := filepath.Join(tmp, "secret.txt")
+	os.WriteFile(secret, []byte("password = \"hunter2\"\n"), 0644)
+	if err := os.Symlink(secret, filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	lfs := sys.NewLocalFS(root)
+	lfs.SetPathPolicy(sys.NewPathPolicy(root))
+
+	re := regexp.MustCompile("password")
+	matches, _, err := grepWalk(context.Background(), re, root, ".", grepOptions{limit: 10, allow: policyCheck(lfs)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matches {
+		if strings.Contains(m.Match, "hunter2") {
+			t.Errorf("symlinked file outside the root was read: %+v", m)
+		}
+	}
+	if len(matches) != 1 || matches[0].File != "main.go" {
+		t.Errorf("expected only the match in main.go, got %+v", matches)
+	}
+}
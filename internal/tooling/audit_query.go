@@ -0,0 +1,132 @@
+package tooling
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AuditFilter selects audit entries. Empty fields match everything; text
+// fields match case-insensitively, Decision by prefix so "denied" matches
+// "Denied (User)".
+type AuditFilter struct {
+	Tool     string
+	Decision string
+	Risk     string
+	Since    time.Time
+	Until    time.Time
+	Limit    int // Keep only the newest Limit matches, if positive
+}
+
+func (f AuditFilter) match(e AuditEntry) bool {
+	if f.Tool != "" && !strings.EqualFold(e.Tool, f.Tool) {
+		return false
+	}
+	if f.Decision != "" && !strings.HasPrefix(strings.ToLower(e.Decision), strings.ToLower(f.Decision)) {
+		return false
+	}
+	if f.Risk != "" && !strings.EqualFold(e.Risk, f.Risk) {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && ts.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && ts.After(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryAudit reads the audit log under appDataDir, rotated segments
+// included, oldest first, and returns the entries matching f. Lines that do
+// not parse are skipped and counted rather than failing the read.
+func QueryAudit(appDataDir string, f AuditFilter) (entries []AuditEntry, corrupt int, err error) {
+	_, path := enclavePaths(appDataDir)
+	return queryAuditFile(path, f)
+}
+
+// Query is QueryAudit over this logger's file.
+func (l *AuditLogger) Query(f AuditFilter) ([]AuditEntry, int, error) {
+	return queryAuditFile(l.path, f)
+}
+
+func queryAuditFile(path string, f AuditFilter) ([]AuditEntry, int, error) {
+	var entries []AuditEntry
+	corrupt := 0
+	for _, seg := range auditSegments(path) {
+		file, err := os.Open(seg)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // Rotated away while listing
+			}
+			return nil, corrupt, err
+		}
+		sc := bufio.NewScanner(file)
+		sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			var e AuditEntry
+			if json.Unmarshal([]byte(line), &e) != nil {
+				corrupt++
+				continue
+			}
+			if f.match(e) {
+				entries = append(entries, e)
+			}
+		}
+		err = sc.Err()
+		file.Close()
+		if err != nil {
+			return nil, corrupt, fmt.Errorf("reading %s: %w", seg, err)
+		}
+	}
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[len(entries)-f.Limit:]
+	}
+	return entries, corrupt, nil
+}
+
+// Default audit log rotation, used until SetRotation is called.
+const (
+	DefaultAuditMaxBytes = 10 << 20
+	DefaultAuditKeep     = 5
+)
+
+// SetRotation rotates the log to audit.log.1 once it would grow past
+// maxBytes, keeping keep generations. A maxBytes of zero disables rotation.
+func (l *AuditLogger) SetRotation(maxBytes int64, keep int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxBytes = maxBytes
+	l.keep = max(keep, 1)
+}
+
+// rotateLocked shifts audit.log.N-1 to audit.log.N and so on, dropping the
+// oldest, if appending n bytes would take the log past its limit. The hash
+// chain carries on in the fresh file. l.mu must be held.
+func (l *AuditLogger) rotateLocked(n int) error {
+	if l.maxBytes <= 0 {
+		return nil
+	}
+	info, err := os.Stat(l.path)
+	if err != nil || info.Size() == 0 || info.Size()+int64(n) <= l.maxBytes {
+		return nil
+	}
+	os.Remove(fmt.Sprintf("%s.%d", l.path, l.keep))
+	for i := l.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	return os.Rename(l.path, l.path+".1")
+}
@@ -0,0 +1,138 @@
+package tooling
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/watcher"
+)
+
+// tailPoll is how often a tail rereads the log when no watcher event has
+// arrived, covering events the watcher debounced away or could not see.
+const tailPoll = 2 * time.Second
+
+// TailChan streams the last n audit entries, then every entry appended to
+// the log afterwards, by this or any other process. The channel is closed
+// by StopTail.
+func (l *AuditLogger) TailChan(n int) <-chan AuditEntry {
+	if n < 0 {
+		n = 0
+	}
+	out := make(chan AuditEntry, n+64)
+	stop := make(chan struct{})
+
+	l.mu.Lock()
+	l.tails = append(l.tails, stop)
+	l.mu.Unlock()
+
+	go l.tail(n, out, stop)
+	return out
+}
+
+// StopTail ends every running TailChan and closes its channel.
+func (l *AuditLogger) StopTail() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, stop := range l.tails {
+		close(stop)
+	}
+	l.tails = nil
+}
+
+func (l *AuditLogger) tail(n int, out chan<- AuditEntry, stop <-chan struct{}) {
+	defer close(out)
+
+	send := func(entries []AuditEntry) bool {
+		for _, e := range entries {
+			select {
+			case out <- e:
+			case <-stop:
+				return false
+			}
+		}
+		return true
+	}
+
+	entries, offset := readAuditFrom(l.path, 0)
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	if !send(entries) {
+		return
+	}
+
+	changed := make(chan struct{}, 1)
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		if w, err := watcher.New(); err == nil {
+			if err := w.AddRoot(dir); err == nil {
+				w.SubscribeFunc(func(evt watcher.Event) {
+					if evt.Path != l.path {
+						return
+					}
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				})
+				w.Start()
+			}
+			defer w.Stop()
+		}
+	}
+
+	ticker := time.NewTicker(tailPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-changed:
+		case <-ticker.C:
+		}
+		if info, err := os.Stat(l.path); err == nil && info.Size() < offset {
+			offset = 0 // Rotated or truncated; start over on the new file
+		}
+		var fresh []AuditEntry
+		fresh, offset = readAuditFrom(l.path, offset)
+		if !send(fresh) {
+			return
+		}
+	}
+}
+
+// readAuditFrom parses the complete lines of the log after offset and
+// returns them with the offset just past the last one. A line still being
+// written is left for the next read.
+func readAuditFrom(path string, offset int64) ([]AuditEntry, int64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset
+	}
+
+	var entries []AuditEntry
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		var e AuditEntry
+		if len(line) == 0 || json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, offset + int64(end) + 1
+}
@@ -0,0 +1,86 @@
+package tooling
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogger_TailChan(t *testing.T) {
+	l := NewAuditLogger(filepath.Join(t.TempDir(), "audit.log"))
+	for _, tool := range []string{"one", "two", "three"} {
+		l.Log(tool, nil, "low", "Approved", "Local")
+	}
+
+	ch := l.TailChan(2)
+	defer l.StopTail()
+
+	next := func() AuditEntry {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out waiting for an audit entry")
+			return AuditEntry{}
+		}
+	}
+	if a, b := next(), next(); a.Tool != "two" || b.Tool != "three" {
+		t.Fatalf("expected the last two entries, got %q and %q", a.Tool, b.Tool)
+	}
+
+	l.Log("four", nil, "high", "Denied", "System")
+	if e := next(); e.Tool != "four" || e.Decision != "Denied" {
+		t.Fatalf("expected the appended entry, got %+v", e)
+	}
+}
+
+func TestAuditLogger_RotationAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := NewAuditLogger(path)
+	l.SetRotation(400, 2)
+	for i := 0; i < 12; i++ {
+		decision := "Approved (Once)"
+		if i%3 == 0 {
+			decision = "Denied (User)"
+		}
+		l.Log("sys_shell_exec", nil, "high", decision, "Local")
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected a rotated segment: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Fatal("expected at most two rotated generations")
+	}
+
+	// A corrupt line is skipped, not fatal.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString("{not json\n")
+	f.Close()
+	l.Log("sys_read_file", nil, "low", "Denied (Persisted)", "Local")
+
+	entries, corrupt, err := l.Query(AuditFilter{Decision: "denied"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrupt != 1 {
+		t.Errorf("corrupt = %d, want 1", corrupt)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Tool != "sys_read_file" {
+		t.Fatalf("expected denied entries ending with the newest, got %+v", entries)
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Decision, "Denied") {
+			t.Errorf("filter let through %q", e.Decision)
+		}
+	}
+
+	entries, _, _ = l.Query(AuditFilter{Tool: "SYS_SHELL_EXEC", Limit: 2})
+	if len(entries) != 2 || entries[1].Seq != 12 {
+		t.Errorf("expected the newest two shell entries, got %+v", entries)
+	}
+	if entries, _, _ := l.Query(AuditFilter{Since: time.Now().Add(time.Hour)}); len(entries) != 0 {
+		t.Errorf("expected nothing after a future --since, got %d", len(entries))
+	}
+}
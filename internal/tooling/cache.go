@@ -0,0 +1,193 @@
+package tooling
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Default cache lifetimes by kind of tool.
+const (
+	ReadCacheTTL    = 60 * time.Second
+	NetworkCacheTTL = 30 * time.Second
+
+	defaultCacheEntries = 64
+)
+
+// ToolCache is a small LRU of successful tool results keyed by tool name
+// and arguments.
+type ToolCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // Front is most recently used
+	entries map[uint64]*list.Element
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key    uint64
+	result ToolResult
+	stored time.Time
+}
+
+// NewToolCache holds up to max results; max <= 0 uses 64.
+func NewToolCache(max int) *ToolCache {
+	if max <= 0 {
+		max = defaultCacheEntries
+	}
+	return &ToolCache{max: max, order: list.New(), entries: make(map[uint64]*list.Element), now: time.Now}
+}
+
+func (c *ToolCache) get(key uint64, ttl time.Duration) (*ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if c.now().Sub(e.stored) >= ttl {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	result := e.result
+	return &result, true
+}
+
+func (c *ToolCache) put(key uint64, result ToolResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = &cacheEntry{key: key, result: result, stored: c.now()}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, stored: c.now()})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Clear drops every cached result.
+func (c *ToolCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[uint64]*list.Element)
+}
+
+// CacheTTL is how long a tool's results may be reused: reads for
+// ReadCacheTTL, network fetches for NetworkCacheTTL. Tools that write or
+// execute, and system probes whose answers change by the second, are not
+// cached.
+func CacheTTL(m ToolMetadata) time.Duration {
+	if mutates(m) {
+		return 0
+	}
+	for _, p := range m.Permissions {
+		if p == PermNetwork {
+			return NetworkCacheTTL
+		}
+	}
+	if m.Category == CategorySystem {
+		return 0
+	}
+	for _, p := range m.Permissions {
+		if p == PermRead {
+			return ReadCacheTTL
+		}
+	}
+	return 0
+}
+
+// mutates reports whether a tool can change what other tools would read.
+func mutates(m ToolMetadata) bool {
+	for _, p := range m.Permissions {
+		if p == PermWrite || p == PermExecute {
+			return true
+		}
+	}
+	return false
+}
+
+// CachingTool serves repeated calls with identical arguments from a
+// ToolCache. Calls to tools that write or execute clear the cache, so a
+// read after a write always sees the new state.
+type CachingTool struct {
+	Tool
+	cache *ToolCache
+	ttl   time.Duration
+}
+
+// callCacheable is implemented by tools that are cached for some calls
+// only, like http_request, whose GETs may be reused but whose POSTs may not.
+type callCacheable interface {
+	Cacheable(args json.RawMessage) bool
+}
+
+// WrapWithCache returns t serving its results from c.
+func WrapWithCache(t Tool, c *ToolCache) Tool {
+	return &CachingTool{Tool: t, cache: c, ttl: CacheTTL(t.Metadata())}
+}
+
+// Metadata delegates to the underlying tool.
+func (ct *CachingTool) Metadata() ToolMetadata {
+	return ct.Tool.Metadata()
+}
+
+// Execute returns a fresh cached result when there is one, and otherwise
+// runs the tool, caching a successful result.
+func (ct *CachingTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	if ct.ttl <= 0 {
+		result, err := ct.Tool.Execute(ctx, args)
+		if mutates(ct.Tool.Metadata()) {
+			ct.cache.Clear()
+		}
+		return result, err
+	}
+
+	if c, ok := ct.Tool.(callCacheable); ok && !c.Cacheable(args) {
+		return ct.Tool.Execute(ctx, args)
+	}
+
+	key := cacheKey(ct.Tool.Metadata().Name, args)
+	if result, ok := ct.cache.get(key, ct.ttl); ok {
+		meta := make(map[string]interface{}, len(result.Meta)+1)
+		for k, v := range result.Meta {
+			meta[k] = v
+		}
+		meta["cached"] = true
+		result.Meta = meta
+		return result, nil
+	}
+
+	result, err := ct.Tool.Execute(ctx, args)
+	if err == nil && result != nil && result.Status == "success" {
+		ct.cache.put(key, *result)
+	}
+	return result, err
+}
+
+// cacheKey hashes the tool name and its arguments, compacted so that
+// whitespace differences still hit.
+func cacheKey(name string, args json.RawMessage) uint64 {
+	h := xxhash.New()
+	h.WriteString(name)
+	h.Write([]byte{0})
+	var buf bytes.Buffer
+	if json.Compact(&buf, args) == nil {
+		h.Write(buf.Bytes())
+	} else {
+		h.Write(args)
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,78 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type countingTool struct {
+	stubTool
+	calls int
+}
+
+func (c *countingTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	c.calls++
+	return &ToolResult{Status: "success", Content: fmt.Sprintf("call %d", c.calls)}, nil
+}
+
+func TestCachingTool(t *testing.T) {
+	cache := NewToolCache(2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	read := &countingTool{stubTool: stubTool{"sys_read_file", []Permission{PermRead}}}
+	write := &countingTool{stubTool: stubTool{"sys_write_file", []Permission{PermWrite}}}
+	cachedRead, cachedWrite := WrapWithCache(read, cache), WrapWithCache(write, cache)
+	ctx := context.Background()
+
+	first, _ := cachedRead.Execute(ctx, json.RawMessage(`{"path": "a"}`))
+	second, _ := cachedRead.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	if read.calls != 1 || second.Content != first.Content || second.Meta["cached"] != true {
+		t.Fatalf("expected a cache hit, got %d calls and %+v", read.calls, second)
+	}
+
+	// A write invalidates earlier reads.
+	cachedWrite.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	cachedWrite.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	if write.calls != 2 {
+		t.Errorf("writes must not be cached, got %d calls", write.calls)
+	}
+	cachedRead.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	if read.calls != 2 {
+		t.Errorf("expected a miss after a write, got %d calls", read.calls)
+	}
+
+	// Entries expire after the TTL.
+	now = now.Add(ReadCacheTTL)
+	cachedRead.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	if read.calls != 3 {
+		t.Errorf("expected a miss after the TTL, got %d calls", read.calls)
+	}
+
+	// The least recently used entry is evicted.
+	cachedRead.Execute(ctx, json.RawMessage(`{"path":"b"}`))
+	cachedRead.Execute(ctx, json.RawMessage(`{"path":"c"}`))
+	cachedRead.Execute(ctx, json.RawMessage(`{"path":"a"}`))
+	if read.calls != 6 {
+		t.Errorf("expected a to be evicted, got %d calls", read.calls)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	for _, tc := range []struct {
+		meta ToolMetadata
+		want time.Duration
+	}{
+		{ToolMetadata{Permissions: []Permission{PermRead}, Category: CategoryFileSystem}, ReadCacheTTL},
+		{ToolMetadata{Permissions: []Permission{PermNetwork}}, NetworkCacheTTL},
+		{ToolMetadata{Permissions: []Permission{PermRead}, Category: CategorySystem}, 0},
+		{ToolMetadata{Permissions: []Permission{PermNetwork, PermRead, PermWrite}}, 0},
+	} {
+		if got := CacheTTL(tc.meta); got != tc.want {
+			t.Errorf("CacheTTL(%v) = %v, want %v", tc.meta.Permissions, got, tc.want)
+		}
+	}
+}
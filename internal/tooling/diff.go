@@ -0,0 +1,128 @@
+package tooling
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// hideFileDiff turns off the diff sys_write_file appends to its result.
+var hideFileDiff atomic.Bool
+
+// SetShowFileDiff sets whether sys_write_file reports a diff of its
+// changes, as configured by ui.show_file_diff. It is on by default.
+func SetShowFileDiff(show bool) {
+	hideFileDiff.Store(!show)
+}
+
+// Limits on the diff sys_write_file reports.
+const (
+	diffContext  = 3
+	maxDiffLines = 200
+)
+
+// UnifiedDiff renders the line changes from before to after as a unified
+// diff with three lines of context, cut off after maxDiffLines lines. It
+// returns "" when nothing changed.
+func UnifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	// Flatten to one op per line.
+	type lineOp struct {
+		op   diffmatchpatch.Operation
+		text string
+	}
+	var ops []lineOp
+	for _, d := range diffs {
+		text := strings.TrimSuffix(d.Text, "\n")
+		for _, l := range strings.Split(text, "\n") {
+			ops = append(ops, lineOp{d.Type, l})
+		}
+	}
+
+	from := "a/" + path
+	if before == "" {
+		from = "/dev/null"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ b/%s\n", from, path)
+	written := 0
+
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].op == diffmatchpatch.DiffEqual {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+		// A hunk starts diffContext lines before the change and runs until
+		// two changes are more than 2*diffContext equal lines apart.
+		start := max(i-diffContext, 0)
+		for start < i && ops[start].op != diffmatchpatch.DiffEqual {
+			start++
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].op != diffmatchpatch.DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].op == diffmatchpatch.DiffEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = run
+		}
+
+		lead := i - start
+		oldStart, newStart := oldLine-lead, newLine-lead
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, o := range ops[start:end] {
+			switch o.op {
+			case diffmatchpatch.DiffEqual:
+				body.WriteString(" " + o.text + "\n")
+				oldCount++
+				newCount++
+			case diffmatchpatch.DiffDelete:
+				body.WriteString("-" + o.text + "\n")
+				oldCount++
+			case diffmatchpatch.DiffInsert:
+				body.WriteString("+" + o.text + "\n")
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range strings.SplitAfter(strings.TrimSuffix(body.String(), "\n"), "\n") {
+			if written == maxDiffLines {
+				fmt.Fprintf(&sb, "… diff truncated after %d lines\n", maxDiffLines)
+				return sb.String()
+			}
+			sb.WriteString(strings.TrimSuffix(l, "\n") + "\n")
+			written++
+		}
+
+		oldLine += oldCount - lead
+		newLine += newCount - lead
+		i = end
+	}
+	return sb.String()
+}
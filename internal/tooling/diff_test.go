@@ -0,0 +1,65 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	want := `--- a/x.txt
++++ b/x.txt
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if got := UnifiedDiff("x.txt", before, after); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("x.txt", before, before); got != "" {
+		t.Errorf("expected no diff for identical content, got %q", got)
+	}
+	if got := UnifiedDiff("new.txt", "", "one\n"); !strings.HasPrefix(got, "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1,1 @@\n+one\n") {
+		t.Errorf("unexpected diff for a new file:\n%s", got)
+	}
+}
+
+func TestWriteFileTool_ReportsDiff(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewWriteFileTool(sys.NewLocalFS(dir))
+	write := func(content string) string {
+		t.Helper()
+		args, _ := json.Marshal(map[string]string{"path": filepath.Join(dir, "notes.md"), "content": content})
+		res, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Content
+	}
+
+	write("one\ntwo\n")
+	if got := write("one\n2\n"); !strings.Contains(got, "-two\n+2\n") {
+		t.Errorf("expected the change in the result, got %q", got)
+	}
+
+	SetShowFileDiff(false)
+	defer SetShowFileDiff(true)
+	if got := write("one\n"); strings.Contains(got, "@@") {
+		t.Errorf("expected no diff with show_file_diff off, got %q", got)
+	}
+}
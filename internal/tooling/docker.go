@@ -0,0 +1,276 @@
+package tooling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// dockerAPI is the part of the Docker client sys_docker uses.
+type dockerAPI interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+	ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, config container.ExecAttachOptions) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+	ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error
+	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
+}
+
+// Limits for sys_docker.
+const (
+	defaultDockerTail = 100
+	maxDockerTail     = 2000
+	maxDockerOutput   = 64 << 10 // Bytes of logs or exec output kept, from the end
+	dockerStopTimeout = 10       // Seconds before stop kills the container
+)
+
+// containerRef matches a container ID (full or short hex) or name.
+var containerRef = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// DockerTool inspects and controls local containers through the Docker
+// Engine API. The client is created on first use from the DOCKER_HOST
+// environment, so machines without Docker only fail when it is called.
+type DockerTool struct {
+	mu  sync.Mutex
+	api dockerAPI
+}
+
+type dockerInput struct {
+	Op          string   `json:"op"`
+	ContainerID string   `json:"container_id"`
+	Tail        int      `json:"tail"`
+	Command     []string `json:"command"`
+	All         bool     `json:"all"`
+}
+
+func (t *DockerTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_docker",
+		Description: "Manage local Docker containers: ps lists them, logs shows the last lines, exec runs a command inside one, stop and start control it. container_id is a name from ps or an ID (the 12-character short form is fine). Every operation needs the user's approval the first time.",
+		Source:      "system",
+		Category:    CategoryDevOps,
+		Roles:       []AgentRole{RoleEngineer},
+		Complexity:  6,
+		Permissions: []Permission{PermExecute, PermNetwork},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"op": {"type": "string", "enum": ["ps", "logs", "exec", "stop", "start"], "description": "Operation to run"},
+				"container_id": {"type": "string", "description": "Container name or ID, as listed by ps; required except for ps"},
+				"tail": {"type": "integer", "description": "logs: lines from the end (default 100, at most 2000)"},
+				"command": {"type": "array", "items": {"type": "string"}, "description": "exec: command and arguments, not run through a shell"},
+				"all": {"type": "boolean", "description": "ps: include stopped containers"}
+			},
+			"required": ["op"]
+		}`),
+		Examples: []string{
+			`{"tool": "sys_docker", "parameters": {"op": "ps"}}`,
+			`{"tool": "sys_docker", "parameters": {"op": "logs", "container_id": "3f4e1a2b9c8d", "tail": 50}}`,
+			`{"tool": "sys_docker", "parameters": {"op": "exec", "container_id": "web-1", "command": ["cat", "/etc/os-release"]}}`,
+			`{"tool": "sys_docker", "parameters": {"op": "stop", "container_id": "web-1"}}`,
+		},
+	}
+}
+
+func (t *DockerTool) client() (dockerAPI, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.api == nil {
+		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			return nil, fmt.Errorf("connecting to docker: %w", err)
+		}
+		t.api = cli
+	}
+	return t.api, nil
+}
+
+func (t *DockerTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input dockerInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(input.Op)
+	switch op {
+	case "ps", "logs", "exec", "stop", "start":
+	default:
+		err := fmt.Errorf("unknown op %q (use ps, logs, exec, stop or start)", input.Op)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if op != "ps" && !containerRef.MatchString(input.ContainerID) {
+		err := fmt.Errorf("docker %s needs a container_id (a name or ID from ps), got %q", op, input.ContainerID)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	api, err := t.client()
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	var res *ToolResult
+	switch op {
+	case "ps":
+		res, err = dockerPS(ctx, api, input.All)
+	case "logs":
+		res, err = dockerLogs(ctx, api, input.ContainerID, input.Tail)
+	case "exec":
+		res, err = dockerExec(ctx, api, input.ContainerID, input.Command)
+	case "stop":
+		timeout := dockerStopTimeout
+		ReportStatus("🐳", "exec", "Stopping container "+input.ContainerID)
+		if err = api.ContainerStop(ctx, input.ContainerID, container.StopOptions{Timeout: &timeout}); err == nil {
+			res = &ToolResult{Status: "success", Content: "Stopped " + input.ContainerID}
+		}
+	case "start":
+		ReportStatus("🐳", "exec", "Starting container "+input.ContainerID)
+		if err = api.ContainerStart(ctx, input.ContainerID, container.StartOptions{}); err == nil {
+			res = &ToolResult{Status: "success", Content: "Started " + input.ContainerID}
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("docker %s: %w", op, err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	return res, nil
+}
+
+func dockerPS(ctx context.Context, api dockerAPI, all bool) (*ToolResult, error) {
+	list, err := api.ContainerList(ctx, container.ListOptions{All: all})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return &ToolResult{Status: "success", Content: "No containers running.", Data: list}, nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-12s  %-24s  %-28s  %-22s  %s\n", "CONTAINER ID", "NAME", "IMAGE", "STATUS", "PORTS")
+	for _, c := range list {
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		name := ""
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		fmt.Fprintf(&sb, "%-12s  %-24s  %-28s  %-22s  %s\n", id, name, c.Image, c.Status, formatPorts(c.Ports))
+	}
+	return &ToolResult{Status: "success", Content: sb.String(), Data: list}, nil
+}
+
+func formatPorts(ports []container.Port) string {
+	var out []string
+	for _, p := range ports {
+		if p.PublicPort == 0 {
+			out = append(out, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			continue
+		}
+		ip := p.IP
+		if ip == "" {
+			ip = "0.0.0.0"
+		}
+		out = append(out, fmt.Sprintf("%s:%d->%d/%s", ip, p.PublicPort, p.PrivatePort, p.Type))
+	}
+	return strings.Join(out, ", ")
+}
+
+func dockerLogs(ctx context.Context, api dockerAPI, id string, tail int) (*ToolResult, error) {
+	if tail <= 0 {
+		tail = defaultDockerTail
+	}
+	tail = min(tail, maxDockerTail)
+
+	info, err := api.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := api.ContainerLogs(ctx, id, container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: strconv.Itoa(tail)})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// Without a TTY, stdout and stderr arrive multiplexed.
+	var out bytes.Buffer
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(&out, rc)
+	} else {
+		_, err = stdcopy.StdCopy(&out, &out, rc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ToolResult{Status: "success", Content: lastBytes(out.String(), maxDockerOutput)}, nil
+}
+
+func dockerExec(ctx context.Context, api dockerAPI, id string, command []string) (*ToolResult, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("command is required")
+	}
+	ReportStatus("🐳", "exec", fmt.Sprintf("Running in %s: %s", id, strings.Join(command, " ")))
+
+	created, err := api.ContainerExecCreate(ctx, id, container.ExecOptions{Cmd: command, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return nil, err
+	}
+	attached, err := api.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer attached.Close()
+
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, attached.Reader); err != nil {
+		return nil, err
+	}
+
+	// The exit code is set once the process is gone, which can lag the
+	// end of its output slightly.
+	var inspect container.ExecInspect
+	for i := 0; i < 10; i++ {
+		if inspect, err = api.ContainerExecInspect(ctx, created.ID); err != nil || !inspect.Running {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ToolResult{
+		Status:  "success",
+		Content: lastBytes(out.String(), maxDockerOutput),
+		Meta:    map[string]interface{}{"exit_code": inspect.ExitCode},
+	}
+	if inspect.ExitCode != 0 {
+		res.Status = "error"
+		res.Error = fmt.Errorf("command exited with status %d", inspect.ExitCode)
+	}
+	return res, nil
+}
+
+// lastBytes keeps the end of s, where the interesting output usually is.
+func lastBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return "… (earlier output cut)\n" + s
+}
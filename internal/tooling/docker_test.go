@@ -0,0 +1,107 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// fakeDocker answers ps, stop and start; everything else fails.
+type fakeDocker struct {
+	dockerAPI
+	containers []container.Summary
+	stopped    string
+}
+
+func (f *fakeDocker) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	return f.containers, nil
+}
+
+func (f *fakeDocker) ContainerStop(ctx context.Context, id string, options container.StopOptions) error {
+	f.stopped = id
+	return nil
+}
+
+func (f *fakeDocker) ContainerStart(ctx context.Context, id string, options container.StartOptions) error {
+	return errors.New("no such container")
+}
+
+func TestDockerTool_PS(t *testing.T) {
+	fake := &fakeDocker{containers: []container.Summary{{
+		ID:     "3f4e1a2b9c8d7e6f",
+		Names:  []string{"/web-1"},
+		Image:  "nginx:1.27",
+		Status: "Up 2 hours",
+		Ports:  []container.Port{{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"}, {PrivatePort: 443, Type: "tcp"}},
+	}}}
+	tool := &DockerTool{api: fake}
+
+	res, err := tool.Execute(context.Background(), json.RawMessage(`{"op": "ps"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"3f4e1a2b9c8d ", "web-1", "nginx:1.27", "Up 2 hours", "0.0.0.0:8080->80/tcp, 443/tcp"} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("ps output missing %q:\n%s", want, res.Content)
+		}
+	}
+}
+
+func TestDockerTool_ContainerOps(t *testing.T) {
+	fake := &fakeDocker{}
+	tool := &DockerTool{api: fake}
+	run := func(args string) error {
+		_, err := tool.Execute(context.Background(), json.RawMessage(args))
+		return err
+	}
+
+	if err := run(`{"op": "stop", "container_id": "web-1"}`); err != nil || fake.stopped != "web-1" {
+		t.Errorf("stop: stopped=%q (%v)", fake.stopped, err)
+	}
+	if err := run(`{"op": "start", "container_id": "web-1"}`); err == nil || !strings.Contains(err.Error(), "docker start") {
+		t.Errorf("expected the start failure to be reported, got %v", err)
+	}
+	if err := run(`{"op": "logs"}`); err == nil {
+		t.Error("expected logs without a container_id to be refused")
+	}
+	if err := run(`{"op": "stop", "container_id": "--all"}`); err == nil {
+		t.Error("expected a malformed container_id to be refused")
+	}
+	if err := run(`{"op": "rm", "container_id": "web-1"}`); err == nil {
+		t.Error("expected an unknown op to be refused")
+	}
+}
+
+func TestDockerTool_ApprovalKeys(t *testing.T) {
+	tool := &DockerTool{}
+	key := func(args string) (string, ApprovalRequest) {
+		k, req, risk, err := buildApprovalRequest(tool, json.RawMessage(args))
+		if err != nil || risk != "high" {
+			t.Fatalf("buildApprovalRequest(%s): risk=%s (%v)", args, risk, err)
+		}
+		return k, req
+	}
+
+	if a, _ := key(`{"op": "ps"}`); a != "sys_docker:ps" {
+		t.Errorf("ps key = %q", a)
+	}
+	a, req := key(`{"op": "exec", "container_id": "web-1", "command": ["ls", "/"]}`)
+	b, _ := key(`{"op": "exec", "container_id": "web-1", "command": ["rm", "-rf", "/data"]}`)
+	if a == b {
+		t.Error("exec approvals should be per command")
+	}
+	if req.Summary != "docker exec web-1: ls /" {
+		t.Errorf("summary = %q", req.Summary)
+	}
+	if _, summary := describeApprovalKey(a); summary != "docker exec web-1 ls /" {
+		t.Errorf("described key = %q", summary)
+	}
+
+	if defs := (&Registry{tools: map[string]Tool{"sys_docker": tool}}).GetPromptDefinitions(nil); !strings.Contains(defs, `"container_id": "3f4e1a2b9c8d"`) {
+		t.Errorf("expected JSON examples in the prompt definition:\n%s", defs)
+	}
+}
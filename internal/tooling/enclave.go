@@ -3,23 +3,43 @@ package tooling
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/nathfavour/vibeauracle/sys"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer records approval decisions. It is a no-op unless the process
+// installs an OpenTelemetry tracer provider.
+var tracer = otel.Tracer("github.com/nathfavour/vibeauracle/tooling")
+
 // InterventionError is returned when a tool needs user selection/approval.
 // The UI should render the choices and then call Resume(selectedOption).
 type InterventionError struct {
 	Title   string
 	Choices []string
+	Risk    string // low|medium|high, as in ApprovalRequest
 	Resume  func(choice string) (*ToolResult, error)
 }
 
+// Intervention choices offered by the Enclave.
+const (
+	ChoiceApproveOnce    = "Approve Once"
+	ChoiceApproveSession = "Approve Session"
+	ChoiceApproveForever = "Approve Forever"
+	ChoiceDeny           = "Deny"
+)
+
 func (e *InterventionError) Error() string {
 	return "intervention required: " + e.Title
 }
@@ -36,8 +56,7 @@ type Enclave struct {
 }
 
 func NewEnclave(appDataDir string) (*Enclave, error) {
-	storePath := filepath.Join(appDataDir, "enclave", "approvals.json")
-	auditPath := filepath.Join(appDataDir, "enclave", "audit.log")
+	storePath, auditPath := enclavePaths(appDataDir)
 
 	// Ensure dir exists
 	os.MkdirAll(filepath.Dir(storePath), 0755)
@@ -80,9 +99,68 @@ func (e *Enclave) DenyForever(key string) error {
 	return e.store.Set(key, decisionDeny)
 }
 
+// ListApprovals returns the persisted decisions, oldest first.
+func (e *Enclave) ListApprovals() []Approval {
+	return e.store.List()
+}
+
+// Revoke forgets the persisted decision for key, so the next matching
+// request asks again.
+func (e *Enclave) Revoke(key string) error {
+	e.mu.Lock()
+	delete(e.sessionAllow, key)
+	delete(e.sessionDeny, key)
+	e.mu.Unlock()
+	return e.store.Delete(key)
+}
+
+// ClearApprovals forgets every persisted decision.
+func (e *Enclave) ClearApprovals() error {
+	e.mu.Lock()
+	e.sessionAllow = map[string]bool{}
+	e.sessionDeny = map[string]bool{}
+	e.mu.Unlock()
+	return e.store.Clear()
+}
+
+// Audit returns the Enclave's audit ledger.
+func (e *Enclave) Audit() *AuditLogger {
+	return e.audit
+}
+
 // Interceptor is meant to be installed into SecurityGuard.SetInterceptor.
 // It returns true if approved; otherwise returns a NeedsApprovalError.
 func (e *Enclave) Interceptor(tool Tool, args json.RawMessage) (bool, error) {
+	return e.InterceptorContext(context.Background(), tool, args)
+}
+
+// InterceptorContext is Interceptor for SecurityGuard.SetContextInterceptor.
+// Each decision is recorded as a span under the tool call's trace.
+func (e *Enclave) InterceptorContext(ctx context.Context, tool Tool, args json.RawMessage) (bool, error) {
+	ctx, span := tracer.Start(ctx, "enclave.approval", trace.WithAttributes(
+		attribute.String("tool.name", tool.Metadata().Name),
+	))
+	defer span.End()
+
+	approved, err := e.intercept(ctx, tool, args)
+	span.SetAttributes(attribute.String("approval.decision", approvalOutcome(approved, err)))
+	return approved, err
+}
+
+// approvalOutcome names an interceptor result for tracing.
+func approvalOutcome(approved bool, err error) string {
+	var ie *InterventionError
+	switch {
+	case approved:
+		return "approved"
+	case errors.As(err, &ie):
+		return "asked"
+	default:
+		return "denied"
+	}
+}
+
+func (e *Enclave) intercept(ctx context.Context, tool Tool, args json.RawMessage) (bool, error) {
 	// Normalize and build a stable key.
 	key, req, risk, err := buildApprovalRequest(tool, args)
 	if err != nil {
@@ -90,6 +168,7 @@ func (e *Enclave) Interceptor(tool Tool, args json.RawMessage) (bool, error) {
 	}
 	req.Key = key
 	req.Risk = risk
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("approval.risk", risk))
 
 	// Hard-block rules
 	if risk == "blocked" {
@@ -128,14 +207,14 @@ func (e *Enclave) Interceptor(tool Tool, args json.RawMessage) (bool, error) {
 	// Create resumption closure
 	resumeFunc := func(choice string) (*ToolResult, error) {
 		switch choice {
-		case "Approve Once":
+		case ChoiceApproveOnce:
 			e.audit.Log(req.ToolName, args, risk, "Approved (Once)", scope)
 			return tool.Execute(context.TODO(), args) // Execute directly
-		case "Approve Session":
+		case ChoiceApproveSession:
 			e.ApproveSession(key)
 			e.audit.Log(req.ToolName, args, risk, "Approved (Session)", scope)
 			return tool.Execute(context.TODO(), args)
-		case "Approve Forever":
+		case ChoiceApproveForever:
 			e.ApproveForever(key)
 			e.audit.Log(req.ToolName, args, risk, "Approved (Forever)", scope)
 			return tool.Execute(context.TODO(), args)
@@ -147,7 +226,8 @@ func (e *Enclave) Interceptor(tool Tool, args json.RawMessage) (bool, error) {
 
 	return false, &InterventionError{
 		Title:   fmt.Sprintf("Allow action? %s", req.Summary),
-		Choices: []string{"Approve Once", "Approve Session", "Approve Forever", "Deny"},
+		Choices: []string{ChoiceApproveOnce, ChoiceApproveSession, ChoiceApproveForever, ChoiceDeny},
+		Risk:    risk,
 		Resume:  resumeFunc,
 	}
 }
@@ -160,7 +240,7 @@ func buildApprovalRequest(tool Tool, args json.RawMessage) (string, ApprovalRequ
 
 	// Default risk based on permissions
 	risk := "medium"
-	for _, p := range m.Permissions {
+	for _, p := range requestPermissions(tool, args) {
 		switch p {
 		case PermRead:
 			// keep low unless mixed with others
@@ -168,8 +248,10 @@ func buildApprovalRequest(tool Tool, args json.RawMessage) (string, ApprovalRequ
 				risk = "low"
 			}
 		case PermNetwork:
-			risk = "medium"
-		case PermWrite, PermExecute, PermSensitive:
+			if risk != "high" {
+				risk = "medium"
+			}
+		case PermWrite, PermExecute, PermSensitive, PermElevated:
 			risk = "high"
 		}
 	}
@@ -202,6 +284,104 @@ func buildApprovalRequest(tool Tool, args json.RawMessage) (string, ApprovalRequ
 		}
 	}
 
+	if name == "sys_proc" {
+		var input procInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		if strings.EqualFold(input.Op, "kill") {
+			summary = fmt.Sprintf("kill process %d", input.PID)
+			preview = summary
+			key = fmt.Sprintf("sys_proc:kill:%d", input.PID)
+		}
+	}
+
+	if name == "sys_docker" {
+		var input dockerInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		// One approval covers an operation on a container; exec also
+		// covers the command, and ps any listing.
+		op := strings.ToLower(input.Op)
+		summary = strings.TrimSpace("docker " + op + " " + input.ContainerID)
+		key = "sys_docker:" + op
+		if op != "ps" {
+			key += ":" + input.ContainerID
+		}
+		if op == "exec" {
+			summary += ": " + strings.Join(input.Command, " ")
+			key += "\u0000" + strings.Join(input.Command, "\u0000")
+		}
+		preview = summary
+	}
+
+	if name == "sys_git_commit" {
+		var input gitCommitInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		summary = gitCommitSummary(input)
+		preview = summary
+	}
+
+	if name == "sys_git" {
+		var input struct {
+			Op string `json:"git_op"`
+			gitCommitInput
+		}
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		if input.Op == "commit" {
+			summary = gitCommitSummary(input.gitCommitInput)
+			preview = summary
+		}
+	}
+
+	if name == "http_request" {
+		var input restInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		// One approval covers a method on a server.
+		method := restMethod(input.Method)
+		summary = method + " " + input.URL
+		if u, err := url.Parse(input.URL); err == nil {
+			key = "http_request:" + method + ":" + u.Host
+		}
+	}
+
+	if name == "ws_request" {
+		var input webSocketInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		// One approval covers a server, whatever is sent to it.
+		summary = "websocket " + input.URL
+		if u, err := url.Parse(input.URL); err == nil {
+			key = "ws_request:" + u.Host
+		}
+	}
+
+	if name == "sys_request_access" {
+		var input accessInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return "", ApprovalRequest{}, "", err
+		}
+		// One approval covers a path, whatever the reason given.
+		path := filepath.Clean(sys.ExpandHome(strings.TrimSpace(input.Path)))
+		summary = "file access outside the allowed roots: " + path
+		if input.Reason != "" {
+			summary += " (" + input.Reason + ")"
+		}
+		if sys.IsHome(path) {
+			summary += "; this is your whole home directory"
+		}
+		preview = summary
+		key = "sys_request_access:" + path
+	}
+
 	req.Summary = summary
 	req.ArgsPreview = preview
 	return key, req, risk, nil
@@ -301,16 +481,32 @@ type AuditEntry struct {
 	Risk      string `json:"risk"`
 	Decision  string `json:"decision"` // Approved, Denied
 	Scope     string `json:"scope"`    // Local, System
+
+	// Hash chain: each entry commits to the one before it, so edits and
+	// deletions are detectable. Entries written before chaining have no Hash.
+	Seq      int64  `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
 }
 
 // AuditLogger maintains a secure ledger of all agent actions
 type AuditLogger struct {
 	path string
 	mu   sync.Mutex
+
+	// Chain head, loaded lazily from disk on first append.
+	loaded   bool
+	lastSeq  int64
+	lastHash string
+
+	tails []chan struct{} // Stop channels of running TailChan readers
+
+	maxBytes int64 // Rotate past this size; 0 never rotates
+	keep     int   // Rotated generations kept
 }
 
 func NewAuditLogger(path string) *AuditLogger {
-	return &AuditLogger{path: path}
+	return &AuditLogger{path: path, maxBytes: DefaultAuditMaxBytes, keep: DefaultAuditKeep}
 }
 
 func (l *AuditLogger) Log(tool string, args json.RawMessage, risk, decision, scope string) {
@@ -326,10 +522,21 @@ func (l *AuditLogger) Log(tool string, args json.RawMessage, risk, decision, sco
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	if !l.loaded {
+		l.lastSeq, l.lastHash = auditChainHead(l.path)
+		l.loaded = true
+	}
+	entry.Seq = l.lastSeq + 1
+	entry.PrevHash = l.lastHash
+	entry.Hash = entry.chainHash()
+
+	bytes, _ := json.Marshal(entry)
+	l.rotateLocked(len(bytes) + 1)
 	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // 0600 = Secure
 	if err == nil {
-		bytes, _ := json.Marshal(entry)
-		f.WriteString(string(bytes) + "\n")
+		if _, err := f.WriteString(string(bytes) + "\n"); err == nil {
+			l.lastSeq, l.lastHash = entry.Seq, entry.Hash
+		}
 		f.Close()
 	}
 }
@@ -0,0 +1,227 @@
+package tooling
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditHMACSecret is the vault key holding the export signing secret.
+const AuditHMACSecret = "enclave_audit_hmac"
+
+// enclavePaths returns the approval store and audit log locations.
+func enclavePaths(appDataDir string) (store, audit string) {
+	dir := filepath.Join(appDataDir, "enclave")
+	return filepath.Join(dir, "approvals.json"), filepath.Join(dir, "audit.log")
+}
+
+// chainHash commits to the entry's content and its predecessor's hash.
+func (e AuditEntry) chainHash() string {
+	e.Hash = ""
+	body, _ := json.Marshal(e)
+	sum := sha256.Sum256(append([]byte(e.PrevHash+"\n"), body...))
+	return hex.EncodeToString(sum[:])
+}
+
+// auditSegments lists the audit log and its rotated segments oldest first.
+// Rotated segments are named audit.log.1 (newest) to audit.log.N (oldest).
+func auditSegments(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	type segment struct {
+		path string
+		n    int
+	}
+	var rotated []segment
+	for _, m := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(m, path+"."))
+		if err == nil {
+			rotated = append(rotated, segment{m, n})
+		}
+	}
+	sort.Slice(rotated, func(i, j int) bool { return rotated[i].n > rotated[j].n })
+
+	var out []string
+	for _, s := range rotated {
+		out = append(out, s.path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		out = append(out, path)
+	}
+	return out
+}
+
+// readAuditEntries reads every entry across all segments in order.
+func readAuditEntries(path string) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	for _, seg := range auditSegments(path) {
+		f, err := os.Open(seg)
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			var e AuditEntry
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s: malformed audit entry: %w", seg, err)
+			}
+			entries = append(entries, e)
+		}
+		f.Close()
+	}
+	return entries, nil
+}
+
+// auditChainHead finds the last chained entry so appends continue the
+// chain, including across rotation when the active file is empty.
+func auditChainHead(path string) (int64, string) {
+	entries, _ := readAuditEntries(path)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Hash != "" {
+			return entries[i].Seq, entries[i].Hash
+		}
+	}
+	return 0, ""
+}
+
+// ApprovalExport is a persisted approval rule as it appears in an export.
+type ApprovalExport struct {
+	Key       string    `json:"key"`
+	Decision  string    `json:"decision"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Count     int       `json:"count"`
+}
+
+// AuditExport is a self-contained, signed snapshot of the Enclave state.
+type AuditExport struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Since      *time.Time       `json:"since,omitempty"`
+	Until      *time.Time       `json:"until,omitempty"`
+	Approvals  []ApprovalExport `json:"approvals"`
+	Entries    []AuditEntry     `json:"entries"`
+	Unchained  int              `json:"unchained,omitempty"` // Legacy entries predating the chain, not exported
+	HMAC       string           `json:"hmac"`
+}
+
+// AuditProblem is a verification failure and the time range it affects.
+type AuditProblem struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// ExportAudit bundles approvals and chained audit entries within
+// [since, until] (zero times are unbounded) and signs the result.
+func ExportAudit(appDataDir string, key []byte, since, until time.Time) (*AuditExport, error) {
+	storePath, auditPath := enclavePaths(appDataDir)
+
+	store, err := NewApprovalStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readAuditEntries(auditPath)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &AuditExport{Version: 1, ExportedAt: time.Now().UTC()}
+	if !since.IsZero() {
+		doc.Since = &since
+	}
+	if !until.IsZero() {
+		doc.Until = &until
+	}
+
+	store.mu.Lock()
+	for k, rec := range store.m {
+		doc.Approvals = append(doc.Approvals, ApprovalExport{Key: k, Decision: string(rec.Decision), UpdatedAt: rec.UpdatedAt, Count: rec.Count})
+	}
+	store.mu.Unlock()
+	sort.Slice(doc.Approvals, func(i, j int) bool { return doc.Approvals[i].Key < doc.Approvals[j].Key })
+
+	for _, e := range entries {
+		if e.Hash == "" {
+			doc.Unchained++
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err == nil {
+			if !since.IsZero() && ts.Before(since) {
+				continue
+			}
+			if !until.IsZero() && ts.After(until) {
+				continue
+			}
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	doc.HMAC = doc.sign(key)
+	return doc, nil
+}
+
+func (d AuditExport) sign(key []byte) string {
+	d.HMAC = ""
+	body, _ := json.Marshal(d)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAuditExport checks the signature and the hash chain. A windowed
+// export verifies on its own: the first entry anchors the chain.
+func VerifyAuditExport(doc *AuditExport, key []byte) []AuditProblem {
+	var problems []AuditProblem
+
+	if !hmac.Equal([]byte(doc.sign(key)), []byte(doc.HMAC)) {
+		p := AuditProblem{Reason: "signature mismatch: the document was modified or signed with a different key"}
+		if n := len(doc.Entries); n > 0 {
+			p.From, p.To = doc.Entries[0].Timestamp, doc.Entries[n-1].Timestamp
+		}
+		problems = append(problems, p)
+	}
+
+	for i, e := range doc.Entries {
+		if e.chainHash() != e.Hash {
+			problems = append(problems, AuditProblem{
+				From:   e.Timestamp,
+				To:     e.Timestamp,
+				Reason: fmt.Sprintf("entry #%d was modified", e.Seq),
+			})
+		}
+		if i == 0 {
+			continue
+		}
+		prev := doc.Entries[i-1]
+		switch {
+		case e.Seq != prev.Seq+1:
+			problems = append(problems, AuditProblem{
+				From:   prev.Timestamp,
+				To:     e.Timestamp,
+				Reason: fmt.Sprintf("gap: %d entries missing between #%d and #%d", e.Seq-prev.Seq-1, prev.Seq, e.Seq),
+			})
+		case e.PrevHash != prev.Hash:
+			problems = append(problems, AuditProblem{
+				From:   prev.Timestamp,
+				To:     e.Timestamp,
+				Reason: fmt.Sprintf("chain broken between #%d and #%d", prev.Seq, e.Seq),
+			})
+		}
+	}
+	return problems
+}
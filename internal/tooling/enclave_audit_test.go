@@ -0,0 +1,164 @@
+package tooling
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var auditTestKey = []byte("audit-test-key")
+
+// writeAuditChain writes n chained entries an hour apart from start, as
+// NewAuditLogger would, and returns the data dir and the log path.
+func writeAuditChain(t *testing.T, n int, start time.Time) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	_, path := enclavePaths(dir)
+	os.MkdirAll(filepath.Dir(path), 0755)
+
+	var sb strings.Builder
+	prev := ""
+	for i := 0; i < n; i++ {
+		e := AuditEntry{
+			Timestamp: start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+			Tool:      "sys_write_file",
+			Args:      `{"path":"main.go"}`,
+			Risk:      "low",
+			Decision:  "Approved",
+			Scope:     "Local",
+			Seq:       int64(i + 1),
+			PrevHash:  prev,
+		}
+		e.Hash = e.chainHash()
+		prev = e.Hash
+		line, _ := json.Marshal(e)
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, path
+}
+
+// rewriteAuditLog applies edit to the log's lines.
+func rewriteAuditLog(t *testing.T, path string, edit func(lines []string) []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := edit(strings.Split(strings.TrimSpace(string(data)), "\n"))
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+func verifyExport(t *testing.T, dir string, since, until time.Time) (*AuditExport, []AuditProblem) {
+	t.Helper()
+	doc, err := ExportAudit(dir, auditTestKey, since, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc, VerifyAuditExport(doc, auditTestKey)
+}
+
+func hasProblem(problems []AuditProblem, reason string) bool {
+	for _, p := range problems {
+		if strings.Contains(p.Reason, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVerifyAuditExport_Intact(t *testing.T) {
+	dir, _ := writeAuditChain(t, 5, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	doc, problems := verifyExport(t, dir, time.Time{}, time.Time{})
+	if len(doc.Entries) != 5 || len(problems) != 0 {
+		t.Fatalf("expected 5 entries and no problems, got %d and %+v", len(doc.Entries), problems)
+	}
+	if problems := VerifyAuditExport(doc, []byte("other key")); !hasProblem(problems, "signature mismatch") {
+		t.Errorf("expected a different key to fail the signature, got %+v", problems)
+	}
+}
+
+func TestVerifyAuditExport_EditedEntry(t *testing.T) {
+	dir, path := writeAuditChain(t, 5, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	rewriteAuditLog(t, path, func(lines []string) []string {
+		lines[2] = strings.Replace(lines[2], `"Approved"`, `"Denied"`, 1)
+		return lines
+	})
+
+	_, problems := verifyExport(t, dir, time.Time{}, time.Time{})
+	if !hasProblem(problems, "entry #3 was modified") {
+		t.Errorf("expected the edited entry to be reported, got %+v", problems)
+	}
+	if hasProblem(problems, "signature mismatch") {
+		t.Errorf("expected a fresh export of the edited log to be signed correctly, got %+v", problems)
+	}
+}
+
+func TestVerifyAuditExport_DeletedEntry(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir, path := writeAuditChain(t, 5, start)
+	rewriteAuditLog(t, path, func(lines []string) []string {
+		return append(lines[:2], lines[3:]...)
+	})
+
+	_, problems := verifyExport(t, dir, time.Time{}, time.Time{})
+	if len(problems) != 1 || !strings.Contains(problems[0].Reason, "gap: 1 entries missing between #2 and #4") {
+		t.Fatalf("expected one gap, got %+v", problems)
+	}
+	if want := start.Add(time.Hour).Format(time.RFC3339); problems[0].From != want {
+		t.Errorf("expected the gap to start at %s, got %s", want, problems[0].From)
+	}
+}
+
+func TestVerifyAuditExport_TruncatedWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir, _ := writeAuditChain(t, 10, start)
+
+	// A window verifies on its own: its first entry anchors the chain.
+	doc, problems := verifyExport(t, dir, start.Add(3*time.Hour), start.Add(6*time.Hour))
+	if len(doc.Entries) != 4 || doc.Entries[0].Seq != 4 || len(problems) != 0 {
+		t.Fatalf("expected entries #4-#7 and no problems, got %d and %+v", len(doc.Entries), problems)
+	}
+
+	// Dropping the window's tail is caught by the signature.
+	doc.Entries = doc.Entries[:2]
+	if problems := VerifyAuditExport(doc, auditTestKey); !hasProblem(problems, "signature mismatch") {
+		t.Errorf("expected a truncated export to fail the signature, got %+v", problems)
+	}
+}
+
+func TestVerifyAuditExport_AcrossRotation(t *testing.T) {
+	dir := t.TempDir()
+	_, path := enclavePaths(dir)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	l := NewAuditLogger(path)
+	l.SetRotation(600, 10)
+	for i := 0; i < 12; i++ {
+		l.Log("sys_read_file", json.RawMessage(`{"path": "main.go"}`), "low", "Approved", "Local")
+	}
+	if segs := auditSegments(path); len(segs) < 3 {
+		t.Fatalf("expected the log to rotate into several segments, got %v", segs)
+	}
+
+	doc, problems := verifyExport(t, dir, time.Time{}, time.Time{})
+	if len(doc.Entries) != 12 || len(problems) != 0 {
+		t.Fatalf("expected 12 chained entries across segments, got %d and %+v", len(doc.Entries), problems)
+	}
+	for i, e := range doc.Entries {
+		if e.Seq != int64(i+1) {
+			t.Fatalf("expected seq %d at %d, got %d", i+1, i, e.Seq)
+		}
+	}
+
+	// A fresh logger picks the chain up from the rotated segments.
+	NewAuditLogger(path).Log("sys_list_files", nil, "low", "Approved", "Local")
+	if _, problems := verifyExport(t, dir, time.Time{}, time.Time{}); len(problems) != 0 {
+		t.Errorf("expected the chain to continue after reopening, got %+v", problems)
+	}
+}
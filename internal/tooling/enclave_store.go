@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,9 +19,39 @@ const (
 )
 
 type approvalRecord struct {
-	Decision  approvalDecision `json:"decision"`
-	UpdatedAt time.Time        `json:"updated_at"`
-	Count     int              `json:"count"`
+	Decision   approvalDecision `json:"decision"`
+	RecordedAt time.Time        `json:"recorded_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	Count      int              `json:"count"`
+}
+
+// UnmarshalJSON accepts records written before recorded_at existed, taking
+// their last update as the recording date, and the bare "allow"/"deny"
+// strings of the earliest stores.
+func (r *approvalRecord) UnmarshalJSON(b []byte) error {
+	var decision approvalDecision
+	if json.Unmarshal(b, &decision) == nil {
+		*r = approvalRecord{Decision: decision, Count: 1}
+		return nil
+	}
+	type plain approvalRecord
+	if err := json.Unmarshal(b, (*plain)(r)); err != nil {
+		return err
+	}
+	if r.RecordedAt.IsZero() {
+		r.RecordedAt = r.UpdatedAt
+	}
+	return nil
+}
+
+// Approval is a persisted decision as shown to the user.
+type Approval struct {
+	Key        string    `json:"key"`
+	Decision   string    `json:"decision"`
+	Tool       string    `json:"tool"`
+	Summary    string    `json:"summary"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Count      int       `json:"count"`
 }
 
 // ApprovalStore persists allow/deny rules across runs.
@@ -78,7 +110,76 @@ func (s *ApprovalStore) Set(key string, decision approvalDecision) error {
 	rec := s.m[key]
 	rec.Decision = decision
 	rec.UpdatedAt = time.Now()
+	if rec.RecordedAt.IsZero() {
+		rec.RecordedAt = rec.UpdatedAt
+	}
 	rec.Count++
 	s.m[key] = rec
 	return s.save()
 }
+
+// List returns the stored decisions, oldest first, so positions stay put
+// as new ones are added.
+func (s *ApprovalStore) List() []Approval {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Approval, 0, len(s.m))
+	for key, rec := range s.m {
+		tool, summary := describeApprovalKey(key)
+		out = append(out, Approval{
+			Key:        key,
+			Decision:   string(rec.Decision),
+			Tool:       tool,
+			Summary:    summary,
+			RecordedAt: rec.RecordedAt,
+			Count:      rec.Count,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].RecordedAt.Equal(out[j].RecordedAt) {
+			return out[i].RecordedAt.Before(out[j].RecordedAt)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// Delete removes the decision for key.
+func (s *ApprovalStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[key]; !ok {
+		return fmt.Errorf("no stored approval for %q", key)
+	}
+	delete(s.m, key)
+	return s.save()
+}
+
+// Clear removes every decision.
+func (s *ApprovalStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m = map[string]approvalRecord{}
+	return s.save()
+}
+
+// describeApprovalKey splits a key built by buildApprovalRequest into the
+// tool name and a readable account of the arguments.
+func describeApprovalKey(key string) (tool, summary string) {
+	tool, rest, _ := strings.Cut(key, ":")
+	switch tool {
+	case "sys_shell_exec":
+		summary = "exec: " + strings.ReplaceAll(rest, "\u0000", " ")
+	case "sys_proc":
+		summary = "kill process " + strings.TrimPrefix(rest, "kill:")
+	case "sys_docker":
+		op, target, _ := strings.Cut(rest, ":")
+		summary = strings.TrimSpace("docker " + op + " " + strings.ReplaceAll(target, "\u0000", " "))
+	default:
+		summary = rest
+	}
+	if len(summary) > 120 {
+		summary = summary[:120] + "…"
+	}
+	return tool, summary
+}
@@ -0,0 +1,107 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestApprovalStore_LegacyRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals.json")
+	legacy := `{
+		"sys_shell_exec:go\u0000test": {"decision": "allow", "updated_at": "2024-05-01T10:00:00Z", "count": 2},
+		"sys_fetch_url:{\"url\":\"https://example.com\"}": "deny"
+	}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewApprovalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := s.List()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 approvals, got %+v", list)
+	}
+	// The record without a date sorts first.
+	if list[0].Decision != "deny" || list[0].Tool != "sys_fetch_url" || !list[0].RecordedAt.IsZero() {
+		t.Errorf("unexpected bare-string record %+v", list[0])
+	}
+	if list[1].Summary != "exec: go test" || list[1].RecordedAt.IsZero() {
+		t.Errorf("expected recorded_at to fall back to updated_at, got %+v", list[1])
+	}
+}
+
+func TestEnclave_RevokeAndClear(t *testing.T) {
+	e, err := NewEnclave(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := stubTool{"sys_shell_exec", []Permission{PermExecute}}
+	args := json.RawMessage(`{"command": "make", "args": ["build"]}`)
+	key, _, _, err := buildApprovalRequest(tool, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ApproveForever(key)
+	e.ApproveForever("sys_proc:kill:42")
+
+	if ok, err := e.Interceptor(tool, args); !ok || err != nil {
+		t.Fatalf("expected the persisted approval to apply, got %v (%v)", ok, err)
+	}
+	if err := e.Revoke(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Interceptor(tool, args); err == nil {
+		t.Fatal("expected a revoked approval to ask again")
+	}
+	if err := e.Revoke(key); err == nil {
+		t.Error("expected revoking twice to fail")
+	}
+
+	list := e.ListApprovals()
+	if len(list) != 1 || list[0].Summary != "kill process 42" {
+		t.Fatalf("unexpected approvals after revoke: %+v", list)
+	}
+	if err := e.ClearApprovals(); err != nil || len(e.ListApprovals()) != 0 {
+		t.Errorf("expected clear to remove everything (%v)", err)
+	}
+}
+
+func TestEnclave_ApprovalSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	e, err := NewEnclave(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, parent := tracer.Start(context.Background(), "tool call")
+	tool := stubTool{"sys_shell_exec", []Permission{PermExecute}}
+	if _, err := e.InterceptorContext(ctx, tool, json.RawMessage(`{"command": "make"}`)); err == nil {
+		t.Fatal("expected the call to ask for approval")
+	}
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 || spans[0].Name() != "enclave.approval" {
+		t.Fatalf("expected an enclave.approval span, got %d spans", len(spans))
+	}
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the approval span under the tool call")
+	}
+	got := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		got[string(kv.Key)] = kv.Value.Emit()
+	}
+	if got["tool.name"] != "sys_shell_exec" || got["approval.risk"] != "high" || got["approval.decision"] != "asked" {
+		t.Errorf("unexpected attributes %v", got)
+	}
+}
@@ -61,35 +61,6 @@ func (t *ListDirTool) Execute(ctx context.Context, args json.RawMessage) (*ToolR
 	}, nil
 }
 
-// GrepTool searches for patterns inside files.
-type GrepTool struct{}
-
-func (t *GrepTool) Metadata() ToolMetadata {
-	return ToolMetadata{
-		Name:        "fs_grep",
-		Description: "Search for regex patterns in files within a directory.",
-		Source:      "system",
-		Category:    CategoryAnalysis,
-		Roles:       []AgentRole{RoleResearcher, RoleEngineer},
-		Complexity:  5,
-		Permissions: []Permission{PermRead},
-		Parameters: json.RawMessage(`{
-			"type": "object",
-			"properties": {
-				"path": {"type": "string", "description": "Directory to search"},
-				"pattern": {"type": "string", "description": "Regex pattern"},
-				"recursive": {"type": "boolean", "description": "Search recursively"}
-			},
-			"required": ["path", "pattern"]
-		}`),
-	}
-}
-
-func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
-	// Implementation placeholder for granular capability
-	return &ToolResult{Status: "error", Content: "Not implemented yet"}, nil
-}
-
 // FileStatsTool provides detailed inode information.
 type FileStatsTool struct {
 	fs sys.FS
@@ -126,7 +97,11 @@ func (t *FileStatsTool) Execute(ctx context.Context, args json.RawMessage) (*Too
 		return nil, err
 	}
 
-	info, err := os.Stat(input.Path)
+	path, err := checkedPath(t.fs, input.Path)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	info, err := os.Stat(path)
 	if err != nil {
 		return &ToolResult{Status: "error", Error: err}, err
 	}
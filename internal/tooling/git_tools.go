@@ -0,0 +1,428 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitTool exposes common git operations through a single tool, dispatched on
+// the "git_op" parameter. Status, diff and commit are the same operations as
+// sys_git_status, sys_git_diff and sys_git_commit, refusals included.
+type GitTool struct {
+	dir string // Repository to run in; the working directory when empty
+}
+
+func (t *GitTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_git",
+		Description: "Run a git operation in the working directory: status, diff, log, add, commit or checkout.",
+		Source:      "system",
+		Category:    CategoryDevOps,
+		Roles:       []AgentRole{RoleEngineer, RoleCoder},
+		Complexity:  6,
+		Permissions: []Permission{PermExecute},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"git_op": {"type": "string", "enum": ["status", "diff", "log", "add", "commit", "checkout"], "description": "The git operation to run"},
+				"path": {"type": "string", "description": "Limit diff to this path"},
+				"staged": {"type": "boolean", "description": "Diff the staged changes against HEAD instead"},
+				"n": {"type": "integer", "description": "Number of commits to show for log (default 10)"},
+				"paths": {"type": "array", "items": {"type": "string"}, "description": "Paths to stage for add (default: all changes) or before commit (default: only what is already staged)"},
+				"message": {"type": "string", "description": "Commit message (required for commit)"},
+				"ref": {"type": "string", "description": "Branch, tag or commit for checkout"},
+				"create": {"type": "boolean", "description": "Create the branch on checkout"}
+			},
+			"required": ["git_op"]
+		}`),
+	}
+}
+
+func (t *GitTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Op      string   `json:"git_op"`
+		Path    string   `json:"path"`
+		Staged  bool     `json:"staged"`
+		N       int      `json:"n"`
+		Paths   []string `json:"paths"`
+		Message string   `json:"message"`
+		Ref     string   `json:"ref"`
+		Create  bool     `json:"create"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+
+	switch input.Op {
+	case "status":
+		return gitStatus(ctx, t.dir)
+	case "diff":
+		return gitDiff(ctx, t.dir, input.Path, input.Staged)
+	case "commit":
+		return gitCommit(ctx, t.dir, gitCommitInput{Paths: input.Paths, Message: input.Message})
+	}
+
+	gitArgs, err := gitArgs(input.Op, input.N, input.Paths, input.Ref, input.Create)
+	if err != nil {
+		return nil, err
+	}
+
+	ReportStatus("🌿", "git", fmt.Sprintf("git %s", strings.Join(gitArgs, " ")))
+
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
+	cmd.Dir = t.dir
+	output, err := cmd.CombinedOutput()
+	status := "success"
+	if err != nil {
+		status = "error"
+		err = fmt.Errorf("git %s: %w", input.Op, err)
+		ReportStatus("❌", "git", err.Error())
+	}
+
+	content := string(output)
+	if err == nil && strings.TrimSpace(content) == "" {
+		content = fmt.Sprintf("git %s: no output", input.Op)
+	}
+
+	return &ToolResult{
+		Status:  status,
+		Content: content,
+		Meta:    map[string]interface{}{"git_op": input.Op},
+		Error:   err,
+	}, nil
+}
+
+// gitArgs builds the git command line for the operations sys_git runs
+// itself, validating the arguments each one needs.
+func gitArgs(op string, n int, paths []string, ref string, create bool) ([]string, error) {
+	switch op {
+	case "log":
+		if n <= 0 {
+			n = 10
+		}
+		return []string{"log", "--oneline", "--decorate", "-n", strconv.Itoa(n)}, nil
+	case "add":
+		if len(paths) == 0 {
+			return []string{"add", "-A"}, nil
+		}
+		return append([]string{"add", "--"}, paths...), nil
+	case "checkout":
+		if ref == "" {
+			return nil, fmt.Errorf("git checkout requires a ref")
+		}
+		if strings.HasPrefix(ref, "-") {
+			return nil, fmt.Errorf("invalid ref %q", ref)
+		}
+		if create {
+			return []string{"checkout", "-b", ref}, nil
+		}
+		return []string{"checkout", ref}, nil
+	case "":
+		return nil, fmt.Errorf("git_op is required")
+	}
+	return nil, fmt.Errorf("unsupported git_op %q (want status, diff, log, add, commit or checkout)", op)
+}
+
+// maxGitDiffOutput caps the diff sys_git_diff returns, from the start.
+const maxGitDiffOutput = 64 << 10
+
+// GitFileStatus is one changed path as reported by sys_git_status. Index
+// and Worktree are git's two porcelain status letters ('M', 'A', 'D', 'R',
+// '?' and so on, or ' ' for unchanged).
+type GitFileStatus struct {
+	Path      string `json:"path"`
+	OrigPath  string `json:"orig_path,omitempty"` // Source of a rename or copy
+	Index     string `json:"index"`
+	Worktree  string `json:"worktree"`
+	Staged    bool   `json:"staged"`
+	Modified  bool   `json:"modified"` // Changed in the working tree but not staged
+	Untracked bool   `json:"untracked"`
+}
+
+// GitCommitError is the structured refusal sys_git_commit returns when it
+// will not commit.
+type GitCommitError struct {
+	Reason  string `json:"reason"` // "nothing_to_commit" or "identity_unset"
+	Message string `json:"message"`
+}
+
+func (e *GitCommitError) Error() string { return "git commit: " + e.Message }
+
+// GitStatusTool reports the working tree's staged, modified and untracked
+// files as structured data.
+type GitStatusTool struct {
+	dir string // Repository to run in; the working directory when empty
+}
+
+func (t *GitStatusTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_git_status",
+		Description: "List the repository's staged, modified and untracked files.",
+		Source:      "system",
+		Category:    CategoryDevOps,
+		Roles:       []AgentRole{RoleEngineer},
+		Complexity:  2,
+		Permissions: []Permission{PermRead},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {}
+		}`),
+	}
+}
+
+func (t *GitStatusTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	return gitStatus(ctx, t.dir)
+}
+
+func gitStatus(ctx context.Context, dir string) (*ToolResult, error) {
+	out, err := runGit(ctx, dir, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	files := parseGitStatus(out)
+	if len(files) == 0 {
+		return &ToolResult{Status: "success", Content: "Working tree clean.", Data: files}, nil
+	}
+
+	var staged, modified, untracked []string
+	for _, f := range files {
+		switch {
+		case f.Untracked:
+			untracked = append(untracked, f.Path)
+			continue
+		case f.Staged:
+			staged = append(staged, f.Index+" "+f.Path)
+		}
+		if f.Modified {
+			modified = append(modified, f.Worktree+" "+f.Path)
+		}
+	}
+	var sb strings.Builder
+	for _, group := range []struct {
+		title string
+		paths []string
+	}{{"Staged", staged}, {"Modified", modified}, {"Untracked", untracked}} {
+		if len(group.paths) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s (%d):\n", group.title, len(group.paths))
+		for _, p := range group.paths {
+			sb.WriteString("  " + p + "\n")
+		}
+	}
+	return &ToolResult{Status: "success", Content: sb.String(), Data: files}, nil
+}
+
+// parseGitStatus reads `git status --porcelain=v1 -z` output, where a
+// rename or copy is followed by its source path as a separate entry.
+func parseGitStatus(out string) []GitFileStatus {
+	files := []GitFileStatus{}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		f := GitFileStatus{Index: e[0:1], Worktree: e[1:2], Path: e[3:]}
+		if f.Index == "?" {
+			f.Untracked = true
+		} else {
+			f.Staged = f.Index != " "
+			f.Modified = f.Worktree != " "
+		}
+		if (f.Index == "R" || f.Index == "C") && i+1 < len(entries) {
+			i++
+			f.OrigPath = entries[i]
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// GitDiffTool returns the diff of the working tree, or of what is staged,
+// for one path or the whole tree.
+type GitDiffTool struct {
+	dir string
+}
+
+func (t *GitDiffTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_git_diff",
+		Description: "Show the unstaged (or, with staged, the staged) diff for a path or the whole tree, capped at 64KB.",
+		Source:      "system",
+		Category:    CategoryDevOps,
+		Roles:       []AgentRole{RoleEngineer},
+		Complexity:  3,
+		Permissions: []Permission{PermRead},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Limit the diff to this file or directory"},
+				"staged": {"type": "boolean", "description": "Diff the staged changes against HEAD instead"}
+			}
+		}`),
+	}
+}
+
+func (t *GitDiffTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Path   string `json:"path"`
+		Staged bool   `json:"staged"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	return gitDiff(ctx, t.dir, input.Path, input.Staged)
+}
+
+func gitDiff(ctx context.Context, dir, path string, staged bool) (*ToolResult, error) {
+	gitArgs := []string{"diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		gitArgs = append(gitArgs, "--cached")
+	}
+	if path != "" {
+		gitArgs = append(gitArgs, "--", path)
+	}
+	out, err := runGit(ctx, dir, gitArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return &ToolResult{Status: "success", Content: "No differences.", Meta: map[string]interface{}{"truncated": false}}, nil
+	}
+
+	truncated := len(out) > maxGitDiffOutput
+	if truncated {
+		out = out[:maxGitDiffOutput]
+		if i := strings.LastIndexByte(out, '\n'); i > 0 {
+			out = out[:i+1]
+		}
+		out += fmt.Sprintf("… diff truncated at %dKB; pass a path to see the rest\n", maxGitDiffOutput>>10)
+	}
+	return &ToolResult{Status: "success", Content: out, Meta: map[string]interface{}{"truncated": truncated}}, nil
+}
+
+// GitCommitTool stages paths and commits them. It refuses, with a
+// GitCommitError, when nothing would be committed or no author identity is
+// configured, rather than leaving git to fail or guess.
+type GitCommitTool struct {
+	dir string
+}
+
+func (t *GitCommitTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_git_commit",
+		Description: "Stage the given paths and commit them, with anything already staged, under message. Refuses if there is nothing to commit or user.name/user.email are unset.",
+		Source:      "system",
+		Category:    CategoryDevOps,
+		Roles:       []AgentRole{RoleEngineer},
+		Complexity:  8,
+		Permissions: []Permission{PermWrite},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"paths": {"type": "array", "items": {"type": "string"}, "description": "Paths to stage before committing (default: commit only what is already staged)"},
+				"message": {"type": "string", "description": "Commit message"}
+			},
+			"required": ["message"]
+		}`),
+		Examples: []string{
+			`{"tool": "sys_git_commit", "parameters": {"paths": ["internal/auth/token.go"], "message": "Refresh tokens before they expire"}}`,
+		},
+	}
+}
+
+type gitCommitInput struct {
+	Paths   []string `json:"paths"`
+	Message string   `json:"message"`
+}
+
+func (t *GitCommitTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input gitCommitInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	return gitCommit(ctx, t.dir, input)
+}
+
+// gitCommit stages input.Paths and commits, unless it has to refuse.
+func gitCommit(ctx context.Context, dir string, input gitCommitInput) (*ToolResult, error) {
+	if strings.TrimSpace(input.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+
+	for _, key := range []string{"user.name", "user.email"} {
+		if v, err := runGit(ctx, dir, "config", "--get", key); err != nil || strings.TrimSpace(v) == "" {
+			return gitCommitRefusal("identity_unset", key+" is not set; configure it with git config before committing"), nil
+		}
+	}
+
+	if len(input.Paths) > 0 {
+		ReportStatus("🌿", "git", fmt.Sprintf("Staging %d path(s)", len(input.Paths)))
+		if _, err := runGit(ctx, dir, append([]string{"add", "--"}, input.Paths...)...); err != nil {
+			return nil, err
+		}
+	}
+	staged, err := runGit(ctx, dir, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	files := strings.Split(strings.TrimSuffix(staged, "\x00"), "\x00")
+	if staged == "" {
+		return gitCommitRefusal("nothing_to_commit", "no changes are staged"), nil
+	}
+
+	ReportStatus("🌿", "git", fmt.Sprintf("Committing %d file(s)", len(files)))
+	if _, err := runGit(ctx, dir, "commit", "--quiet", "-m", input.Message); err != nil {
+		return nil, err
+	}
+	hash, err := runGit(ctx, dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	hash = strings.TrimSpace(hash)
+
+	return &ToolResult{
+		Status:    "success",
+		Content:   fmt.Sprintf("Committed %d file(s) as %s.", len(files), hash),
+		Data:      map[string]interface{}{"commit": hash, "files": files},
+		Artifacts: files,
+	}, nil
+}
+
+// gitCommitSummary describes a commit for approval.
+func gitCommitSummary(input gitCommitInput) string {
+	subject, _, _ := strings.Cut(input.Message, "\n")
+	summary := fmt.Sprintf("git commit %q", subject)
+	if len(input.Paths) > 0 {
+		summary += " with " + strings.Join(input.Paths, ", ")
+	}
+	return summary
+}
+
+func gitCommitRefusal(reason, message string) *ToolResult {
+	err := &GitCommitError{Reason: reason, Message: message}
+	ReportStatus("❌", "git", err.Error())
+	return &ToolResult{Status: "error", Content: err.Error(), Data: err, Error: err}
+}
+
+// runGit runs git in dir and returns its standard output, or an error
+// carrying its standard error.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,237 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newGitRepo creates an empty repository with no author identity, isolated
+// from the user's own git configuration.
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "--quiet")
+	return dir
+}
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if _, err := runGit(context.Background(), dir, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeRepoFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commitRefusal(t *testing.T, res *ToolResult) string {
+	t.Helper()
+	var cerr *GitCommitError
+	if res == nil || res.Status != "error" || !errors.As(res.Error, &cerr) {
+		t.Fatalf("expected a GitCommitError result, got %+v", res)
+	}
+	return cerr.Reason
+}
+
+func TestGitTools_StatusDiffCommit(t *testing.T) {
+	dir := newGitRepo(t)
+	ctx := context.Background()
+	status := &GitStatusTool{dir: dir}
+	diff := &GitDiffTool{dir: dir}
+	commit := &GitCommitTool{dir: dir}
+
+	writeRepoFile(t, dir, "a.txt", "one\n")
+	args, _ := json.Marshal(map[string]interface{}{"paths": []string{"a.txt"}, "message": "Add a"})
+
+	res, err := commit.Execute(ctx, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := commitRefusal(t, res); reason != "identity_unset" {
+		t.Fatalf("reason = %q, want identity_unset", reason)
+	}
+
+	gitIn(t, dir, "config", "user.name", "Test")
+	gitIn(t, dir, "config", "user.email", "test@example.com")
+
+	res, err = (&GitCommitTool{dir: dir}).Execute(ctx, json.RawMessage(`{"message": "Empty"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := commitRefusal(t, res); reason != "nothing_to_commit" {
+		t.Fatalf("reason = %q, want nothing_to_commit", reason)
+	}
+
+	res, err = commit.Execute(ctx, args)
+	if err != nil || res.Status != "success" {
+		t.Fatalf("commit failed: %+v (%v)", res, err)
+	}
+
+	writeRepoFile(t, dir, "a.txt", "one\ntwo\n")
+	writeRepoFile(t, dir, "b.txt", "new\n")
+	writeRepoFile(t, dir, "c.txt", "staged\n")
+	gitIn(t, dir, "add", "c.txt")
+
+	res, err = status.Execute(ctx, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]GitFileStatus{}
+	for _, f := range res.Data.([]GitFileStatus) {
+		got[f.Path] = f
+	}
+	if f := got["a.txt"]; !f.Modified || f.Staged || f.Untracked {
+		t.Errorf("a.txt = %+v, want modified", f)
+	}
+	if f := got["b.txt"]; !f.Untracked {
+		t.Errorf("b.txt = %+v, want untracked", f)
+	}
+	if f := got["c.txt"]; !f.Staged || f.Index != "A" || f.Modified {
+		t.Errorf("c.txt = %+v, want staged as added", f)
+	}
+
+	res, err = diff.Execute(ctx, json.RawMessage(`{"path": "a.txt"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, "+two") || strings.Contains(res.Content, "c.txt") {
+		t.Errorf("unexpected diff of a.txt:\n%s", res.Content)
+	}
+	res, err = diff.Execute(ctx, json.RawMessage(`{"staged": true}`))
+	if err != nil || !strings.Contains(res.Content, "+staged") {
+		t.Errorf("unexpected staged diff: %+v (%v)", res, err)
+	}
+}
+
+func TestGitTool_CommitSharesRefusals(t *testing.T) {
+	dir := newGitRepo(t)
+	ctx := context.Background()
+	git := &GitTool{dir: dir}
+	writeRepoFile(t, dir, "a.txt", "one\n")
+	args := json.RawMessage(`{"git_op": "commit", "paths": ["a.txt"], "message": "Add a\n\nBody"}`)
+
+	res, err := git.Execute(ctx, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := commitRefusal(t, res); reason != "identity_unset" {
+		t.Fatalf("reason = %q, want identity_unset", reason)
+	}
+
+	gitIn(t, dir, "config", "user.name", "Test")
+	gitIn(t, dir, "config", "user.email", "test@example.com")
+	res, err = git.Execute(ctx, json.RawMessage(`{"git_op": "commit", "message": "Empty"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := commitRefusal(t, res); reason != "nothing_to_commit" {
+		t.Fatalf("reason = %q, want nothing_to_commit", reason)
+	}
+
+	_, req, risk, err := buildApprovalRequest(git, args)
+	if err != nil || risk != "high" || req.Summary != `git commit "Add a" with a.txt` {
+		t.Errorf("expected the sys_git_commit summary at high risk, got %q, %s (%v)", req.Summary, risk, err)
+	}
+}
+
+func TestGitDiffTool_CapsOutput(t *testing.T) {
+	dir := newGitRepo(t)
+	gitIn(t, dir, "config", "user.name", "Test")
+	gitIn(t, dir, "config", "user.email", "test@example.com")
+	writeRepoFile(t, dir, "big.txt", "")
+	gitIn(t, dir, "add", "big.txt")
+	gitIn(t, dir, "commit", "--quiet", "-m", "Add big")
+	writeRepoFile(t, dir, "big.txt", strings.Repeat("a line of text that changed\n", 5000))
+
+	res, err := (&GitDiffTool{dir: dir}).Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Content) > maxGitDiffOutput+100 || res.Meta["truncated"] != true {
+		t.Errorf("diff of %d bytes not capped (truncated=%v)", len(res.Content), res.Meta["truncated"])
+	}
+}
+
+func TestGitArgs(t *testing.T) {
+	for _, tc := range []struct {
+		op      string
+		n       int
+		paths   []string
+		ref     string
+		create  bool
+		want    string
+		wantErr bool
+	}{
+		{op: "log", want: "log --oneline --decorate -n 10"},
+		{op: "log", n: 3, want: "log --oneline --decorate -n 3"},
+		{op: "add", want: "add -A"},
+		{op: "add", paths: []string{"-rf", "a.go"}, want: "add -- -rf a.go"},
+		{op: "checkout", ref: "main", want: "checkout main"},
+		{op: "checkout", ref: "feature", create: true, want: "checkout -b feature"},
+		{op: "checkout", wantErr: true},
+		{op: "checkout", ref: "-f", wantErr: true},
+		{op: "checkout", ref: "--orphan=x", create: true, wantErr: true},
+		{op: "push", wantErr: true},
+		{op: "", wantErr: true},
+	} {
+		args, err := gitArgs(tc.op, tc.n, tc.paths, tc.ref, tc.create)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("gitArgs(%q, ref %q) = %q, want an error", tc.op, tc.ref, args)
+			}
+			continue
+		}
+		if err != nil || strings.Join(args, " ") != tc.want {
+			t.Errorf("gitArgs(%q, %d, %q, %q, %v) = %q (%v), want %q", tc.op, tc.n, tc.paths, tc.ref, tc.create, args, err, tc.want)
+		}
+	}
+}
+
+func TestGitTool_RejectsBadInput(t *testing.T) {
+	dir := newGitRepo(t)
+	git := &GitTool{dir: dir}
+	for _, args := range []string{
+		`{"git_op": "rebase"}`,
+		`{}`,
+		`{"git_op": "checkout", "ref": "--detach"}`,
+		`{"git_op": "commit"}`,
+		`{"git_op": "commit", "message": "   "}`,
+	} {
+		if res, err := git.Execute(context.Background(), json.RawMessage(args)); err == nil {
+			t.Errorf("%s: expected an error, got %+v", args, res)
+		}
+	}
+
+	// A path that looks like a flag is still a path.
+	res, err := git.Execute(context.Background(), json.RawMessage(`{"git_op": "diff", "path": "--output=leak"}`))
+	if err != nil || res.Content != "No differences." {
+		t.Errorf("expected an empty diff for the path, got %+v (%v)", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "leak")); err == nil {
+		t.Error("expected --output to be taken as a path, not an option")
+	}
+}
+
+func TestParseGitStatus_Rename(t *testing.T) {
+	files := parseGitStatus("R  new.go\x00old.go\x00?? x\x00")
+	if len(files) != 2 || files[0].Path != "new.go" || files[0].OrigPath != "old.go" || !files[0].Staged || !files[1].Untracked {
+		t.Errorf("unexpected parse %+v", files)
+	}
+}
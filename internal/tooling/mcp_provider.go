@@ -3,23 +3,30 @@ package tooling
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/internal/doctor"
 )
 
 // MCPProvider connects to an external Model Context Protocol server.
 type MCPProvider struct {
 	config MCPConfig
 	client *MCPClient
+	mu     sync.Mutex
 }
 
 type MCPConfig struct {
-	Name    string   `json:"name"`
-	Command string   `json:"command"`
-	Args    []string `json:"args"`
-	Env     []string `json:"env"`
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Args        []string `json:"args"`
+	Env         []string `json:"env"`
+	MaxRestarts int      `json:"max_restarts,omitempty"` // 0 uses DefaultMCPMaxRestarts; negative never restarts
 }
 
 func NewMCPProvider(cfg MCPConfig) *MCPProvider {
@@ -30,23 +37,68 @@ func NewMCPProvider(cfg MCPConfig) *MCPProvider {
 
 func (p *MCPProvider) Name() string { return "mcp:" + p.config.Name }
 
-func (p *MCPProvider) Provide(ctx context.Context) ([]Tool, error) {
+// Config returns the server definition the provider was created with.
+func (p *MCPProvider) Config() MCPConfig { return p.config }
+
+// ListTools starts the server if needed and asks it for its tools.
+func (p *MCPProvider) ListTools(ctx context.Context) ([]MCPTool, error) {
+	p.mu.Lock()
 	if p.client == nil {
-		p.client = NewMCPClient(p.config)
-		if err := p.client.Start(); err != nil {
+		client := NewMCPClient(p.config)
+		if err := client.Start(); err != nil {
+			p.mu.Unlock()
 			return nil, err
 		}
+		p.client = client
+	}
+	client := p.client
+	p.mu.Unlock()
+	return client.ListTools(ctx)
+}
+
+// IsHealthy reports whether the server is running and answering. It is
+// false before the server is first started and while it is restarting.
+func (p *MCPProvider) IsHealthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.client != nil && p.client.Healthy()
+}
+
+// Restarts is how many times the health checks have restarted the server.
+func (p *MCPProvider) Restarts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		return 0
+	}
+	return p.client.Restarts()
+}
+
+// Close stops the server process, if it was started.
+func (p *MCPProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		return nil
 	}
+	err := p.client.Close()
+	p.client = nil
+	return err
+}
 
-	mcpTools, err := p.client.ListTools(ctx)
+func (p *MCPProvider) Provide(ctx context.Context) ([]Tool, error) {
+	mcpTools, err := p.ListTools(ctx)
 	if err != nil {
 		return nil, err
 	}
+	p.mu.Lock()
+	client := p.client
+	p.mu.Unlock()
 
 	var tools []Tool
 	for _, mt := range mcpTools {
 		tools = append(tools, &ExternalMCPTool{
-			client: p.client,
+			client: client,
 			meta: ToolMetadata{
 				Name:        mt.Name,
 				Description: mt.Description,
@@ -72,132 +124,392 @@ func (t *ExternalMCPTool) Execute(ctx context.Context, args json.RawMessage) (*T
 	return t.client.CallTool(ctx, t.meta.Name, args)
 }
 
-// MCPClient handles the low-level communication with an MCP server via stdio.
+// MCPClient handles the low-level communication with an MCP server via
+// stdio. Once started it pings the server every mcpHealthInterval and, if
+// the server has exited or stops answering, restarts it with exponential
+// backoff, up to MCPConfig.MaxRestarts times.
 type MCPClient struct {
 	config MCPConfig
 	cmd    *exec.Cmd
+	exited chan struct{} // Closed once cmd has exited
 	stdin  *json.Encoder
 	stdout *json.Decoder
 	mu     sync.Mutex
 	id     int
+	dead   error
+
+	healthy  atomic.Bool
+	restarts atomic.Int32
+	stop     chan struct{}
+	stopOnce sync.Once
+	wake     chan struct{} // Nudges the health loop when the server exits
+
+	healthInterval time.Duration
+	restartBackoff time.Duration
 }
 
 func NewMCPClient(cfg MCPConfig) *MCPClient {
-	return &MCPClient{config: cfg}
+	return &MCPClient{
+		config:         cfg,
+		stop:           make(chan struct{}),
+		wake:           make(chan struct{}, 1),
+		healthInterval: mcpHealthInterval,
+		restartBackoff: mcpRestartBackoff,
+	}
 }
 
+// Start launches the server, performs the initialize handshake and starts
+// watching its health.
 func (c *MCPClient) Start() error {
-	c.cmd = exec.Command(c.config.Command, c.config.Args...)
-	c.cmd.Env = append(os.Environ(), c.config.Env...)
+	if err := c.launch(); err != nil {
+		c.Close()
+		return err
+	}
+	go c.monitor()
+	return nil
+}
+
+// launch starts a server process in place of any previous one and
+// performs the initialize handshake.
+func (c *MCPClient) launch() error {
+	cmd := exec.Command(c.config.Command, c.config.Args...)
+	cmd.Env = append(os.Environ(), c.config.Env...)
 
-	in, err := c.cmd.StdinPipe()
+	in, err := cmd.StdinPipe()
 	if err != nil {
 		return err
 	}
-	out, err := c.cmd.StdoutPipe()
+	out, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
 	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting mcp server %s: %w", c.config.Name, err)
+	}
 
+	exited := make(chan struct{})
+	c.mu.Lock()
+	c.cmd, c.exited, c.dead = cmd, exited, nil
 	c.stdin = json.NewEncoder(in)
 	c.stdout = json.NewDecoder(out)
+	c.mu.Unlock()
+	go c.watch(cmd, exited)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpHandshakeTimeout)
+	defer cancel()
+	params := map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "vibeauracle", "version": "1"},
+	}
+	if err := c.call(ctx, "initialize", params, nil); err != nil {
+		c.mu.Lock()
+		c.fail(err)
+		c.mu.Unlock()
+		return fmt.Errorf("initializing mcp server %s: %w", c.config.Name, err)
+	}
 
-	return c.cmd.Start()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.stdin.Encode(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/initialized"}); err != nil {
+		return c.fail(err)
+	}
+	c.healthy.Store(true)
+	return nil
 }
 
-func (c *MCPClient) ListTools(ctx context.Context) ([]MCPTool, error) {
+// watch waits for cmd to exit and marks the client dead if cmd is still
+// its server, so calls fail at once instead of waiting on a reply.
+func (c *MCPClient) watch(cmd *exec.Cmd, exited chan struct{}) {
+	err := cmd.Wait()
+	close(exited)
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	current := c.cmd == cmd
+	if current {
+		c.fail(fmt.Errorf("mcp server %s exited: %v", c.config.Name, err))
+	}
+	c.mu.Unlock()
+	if current {
+		select {
+		case c.wake <- struct{}{}:
+		default:
+		}
+	}
+}
 
-	c.id++
-	req := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      c.id,
-		"method":  "tools/list",
-		"params":  map[string]interface{}{},
+// fail records err as the reason the server is down, unless one is
+// already recorded, and kills the process. c.mu must be held.
+func (c *MCPClient) fail(err error) error {
+	if c.dead == nil {
+		c.dead = err
+	}
+	c.healthy.Store(false)
+	if c.cmd != nil && c.cmd.Process != nil {
+		c.cmd.Process.Kill()
 	}
+	return c.dead
+}
 
-	if err := c.stdin.Encode(req); err != nil {
-		return nil, err
+// Healthy reports whether the server is running and answering.
+func (c *MCPClient) Healthy() bool { return c.healthy.Load() }
+
+// Restarts is how many times the server has been restarted.
+func (c *MCPClient) Restarts() int { return int(c.restarts.Load()) }
+
+func (c *MCPClient) maxRestarts() int {
+	switch {
+	case c.config.MaxRestarts < 0:
+		return 0
+	case c.config.MaxRestarts == 0:
+		return DefaultMCPMaxRestarts
+	}
+	return c.config.MaxRestarts
+}
+
+// monitor pings the server until the client is closed, restarting it when
+// it is down.
+func (c *MCPClient) monitor() {
+	ticker := time.NewTicker(c.healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		case <-c.wake:
+		}
+		err := c.ping()
+		if err == nil {
+			continue
+		}
+		doctor.Send("mcp", doctor.SignalWarning, fmt.Sprintf("%s is down: %v", c.config.Name, err), nil)
+		if !c.restart() {
+			return
+		}
 	}
+}
 
-	var resp struct {
-		Result struct {
-			Tools []MCPTool `json:"tools"`
-		} `json:"result"`
-		Error interface{} `json:"error"`
+// ping checks the server answers. Any reply counts, even an error from a
+// server that does not implement ping. A request already in flight is
+// taken as proof enough; if the server dies under it, watch notices.
+func (c *MCPClient) ping() error {
+	if !c.mu.TryLock() {
+		return nil
+	}
+	dead := c.dead
+	c.mu.Unlock()
+	if dead != nil {
+		return dead
 	}
 
-	if err := c.stdout.Decode(&resp); err != nil {
-		return nil, err
+	ctx, cancel := context.WithTimeout(context.Background(), mcpPingTimeout)
+	defer cancel()
+	err := c.call(ctx, "ping", map[string]interface{}{}, nil)
+	var rpcErr *mcpRPCError
+	if errors.As(err, &rpcErr) {
+		return nil
 	}
+	if err != nil {
+		c.mu.Lock()
+		err = c.fail(err)
+		c.mu.Unlock()
+	}
+	return err
+}
 
-	if resp.Error != nil {
-		return nil, fmt.Errorf("mcp error: %v", resp.Error)
+// restart relaunches the server, waiting restartBackoff before the first
+// attempt and doubling the wait each time. It reports false once the
+// client is closed or out of restarts.
+func (c *MCPClient) restart() bool {
+	for c.Restarts() < c.maxRestarts() {
+		n := c.restarts.Add(1)
+		select {
+		case <-c.stop:
+			return false
+		case <-time.After(c.restartBackoff << (n - 1)):
+		}
+		err := c.launch()
+		select {
+		case <-c.stop:
+			c.mu.Lock()
+			c.fail(fmt.Errorf("mcp server %s stopped", c.config.Name))
+			c.mu.Unlock()
+			return false
+		default:
+		}
+		if err != nil {
+			doctor.Send("mcp", doctor.SignalWarning, fmt.Sprintf("restarting %s (%d/%d) failed: %v", c.config.Name, n, c.maxRestarts(), err), nil)
+			continue
+		}
+		doctor.Send("mcp", doctor.SignalInfo, fmt.Sprintf("restarted %s (%d/%d)", c.config.Name, n, c.maxRestarts()), nil)
+		return true
 	}
 
-	return resp.Result.Tools, nil
+	c.mu.Lock()
+	c.dead = fmt.Errorf("mcp server %s is down; gave up after %d restart(s): %v", c.config.Name, c.Restarts(), c.dead)
+	msg := c.dead.Error()
+	c.mu.Unlock()
+	doctor.Send("mcp", doctor.SignalError, msg, nil)
+	return false
 }
 
-func (c *MCPClient) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolResult, error) {
+// Close stops the server process and its health checks.
+func (c *MCPClient) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.fail(fmt.Errorf("mcp server %s stopped", c.config.Name))
+	exited := c.exited
+	c.mu.Unlock()
+	if exited != nil {
+		<-exited
+	}
+	return nil
+}
 
-	c.id++
-	var params map[string]interface{}
-	if err := json.Unmarshal(args, &params); err != nil {
-		return nil, err
+const (
+	mcpProtocolVersion  = "2024-11-05"
+	mcpHandshakeTimeout = 10 * time.Second
+	mcpHealthInterval   = 15 * time.Second
+	mcpPingTimeout      = 5 * time.Second
+	mcpRestartBackoff   = time.Second
+
+	// DefaultMCPMaxRestarts applies when MCPConfig.MaxRestarts is 0.
+	DefaultMCPMaxRestarts = 3
+)
+
+type mcpResponse struct {
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *mcpRPCError    `json:"error"`
+}
+
+// mcpRPCError is an error the server replied with.
+type mcpRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *mcpRPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// mcpStreamError is a failure to read the server's output; the stream can
+// no longer be trusted.
+type mcpStreamError struct{ err error }
+
+func (e *mcpStreamError) Error() string { return "reading mcp reply: " + e.err.Error() }
+func (e *mcpStreamError) Unwrap() error { return e.err }
+
+// call sends a JSON-RPC request and decodes the matching response into
+// result, skipping server notifications in between. If ctx expires or the
+// server exits first the server is marked dead (and killed), since its
+// stdout can no longer be read in step.
+func (c *MCPClient) call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dead != nil {
+		return c.dead
 	}
 
+	c.id++
+	id := c.id
 	req := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"id":      c.id,
-		"method":  "tools/call",
-		"params": map[string]interface{}{
-			"name":      name,
-			"arguments": params,
-		},
+		"id":      id,
+		"method":  method,
+		"params":  params,
 	}
-
 	if err := c.stdin.Encode(req); err != nil {
-		return nil, err
+		return c.fail(err)
 	}
 
-	var resp struct {
-		Result struct {
-			Content []struct {
-				Type string `json:"type"`
-				Text string `json:"text"`
-			} `json:"content"`
-			IsError bool `json:"isError"`
-		} `json:"result"`
-		Error interface{} `json:"error"`
+	dec := c.stdout
+	done := make(chan error, 1)
+	go func() {
+		for {
+			var resp mcpResponse
+			if err := dec.Decode(&resp); err != nil {
+				done <- &mcpStreamError{err}
+				return
+			}
+			if resp.ID == nil || *resp.ID != id {
+				continue
+			}
+			if resp.Error != nil {
+				done <- resp.Error
+				return
+			}
+			if result != nil {
+				done <- json.Unmarshal(resp.Result, result)
+				return
+			}
+			done <- nil
+			return
+		}
+	}()
+
+	select {
+	case err := <-done:
+		var streamErr *mcpStreamError
+		if errors.As(err, &streamErr) {
+			return c.fail(fmt.Errorf("mcp server %s: %w", c.config.Name, err))
+		}
+		return err
+	case <-c.exited:
+		return c.fail(fmt.Errorf("mcp server %s exited", c.config.Name))
+	case <-ctx.Done():
+		return c.fail(fmt.Errorf("mcp server %s stopped responding: %w", c.config.Name, ctx.Err()))
 	}
+}
+
+func (c *MCPClient) ListTools(ctx context.Context) ([]MCPTool, error) {
+	var result struct {
+		Tools []MCPTool `json:"tools"`
+	}
+	if err := c.call(ctx, "tools/list", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
 
-	if err := c.stdout.Decode(&resp); err != nil {
+func (c *MCPClient) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolResult, error) {
+	var params map[string]interface{}
+	if err := json.Unmarshal(args, &params); err != nil {
 		return nil, err
 	}
 
-	if resp.Error != nil {
-		return &ToolResult{Status: "error", Error: fmt.Errorf("%v", resp.Error)}, fmt.Errorf("mcp error: %v", resp.Error)
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	err := c.call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": params,
+	}, &result)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
 	}
 
 	// Concatenate text content
 	var content string
-	for _, part := range resp.Result.Content {
+	for _, part := range result.Content {
 		if part.Type == "text" {
 			content += part.Text + "\n"
 		}
 	}
 
 	status := "success"
-	if resp.Result.IsError {
+	if result.IsError {
 		status = "error"
 	}
 
 	return &ToolResult{
 		Status:  status,
 		Content: content,
-		Data:    resp.Result,
+		Data:    result,
 	}, nil
 }
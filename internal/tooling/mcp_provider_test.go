@@ -0,0 +1,103 @@
+package tooling
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMCPHealthHelper is not a real test: when MCP_HEALTH_HELPER is set the
+// test binary acts as a stdio MCP server whose "crash" tool exits it.
+func TestMCPHealthHelper(t *testing.T) {
+	if os.Getenv("MCP_HEALTH_HELPER") != "1" {
+		return
+	}
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req struct {
+			ID     *int   `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		json.Unmarshal(in.Bytes(), &req)
+		if req.ID == nil {
+			continue
+		}
+		var result interface{} = map[string]interface{}{}
+		switch req.Method {
+		case "tools/list":
+			result = map[string]interface{}{"tools": []map[string]interface{}{{"name": "crash"}, {"name": "ok"}}}
+		case "tools/call":
+			if req.Params.Name == "crash" {
+				os.Exit(1)
+			}
+			result = map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": "fine"}}}
+		}
+		out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+	}
+	os.Exit(0)
+}
+
+func startHealthHelper(t *testing.T, maxRestarts int) *MCPClient {
+	t.Helper()
+	t.Setenv("MCP_HEALTH_HELPER", "1")
+	c := NewMCPClient(MCPConfig{Name: "helper", Command: os.Args[0], Args: []string{"-test.run=TestMCPHealthHelper"}, MaxRestarts: maxRestarts})
+	c.healthInterval = 20 * time.Millisecond
+	c.restartBackoff = 10 * time.Millisecond
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMCPClient_RestartsDeadServer(t *testing.T) {
+	c := startHealthHelper(t, 0)
+	if !c.Healthy() {
+		t.Fatal("expected a started server to be healthy")
+	}
+
+	// The server dies mid-call; the call must fail rather than hang.
+	if _, err := c.CallTool(context.Background(), "crash", json.RawMessage(`{}`)); err == nil {
+		t.Fatal("expected the call to fail when the server exits")
+	}
+	waitFor(t, "the restart", func() bool { return c.Healthy() && c.Restarts() == 1 })
+
+	res, err := c.CallTool(context.Background(), "ok", json.RawMessage(`{}`))
+	if err != nil || res.Content != "fine\n" {
+		t.Fatalf("expected the restarted server to answer, got %+v (%v)", res, err)
+	}
+}
+
+func TestMCPClient_GivesUpAfterMaxRestarts(t *testing.T) {
+	c := startHealthHelper(t, 1)
+	c.CallTool(context.Background(), "crash", json.RawMessage(`{}`))
+	waitFor(t, "the restart", func() bool { return c.Healthy() && c.Restarts() == 1 })
+
+	c.CallTool(context.Background(), "crash", json.RawMessage(`{}`))
+	waitFor(t, "the health loop to give up", func() bool {
+		_, err := c.ListTools(context.Background())
+		return err != nil && !c.Healthy() && strings.Contains(err.Error(), "gave up")
+	})
+	if c.Restarts() != 1 {
+		t.Errorf("expected no restart beyond the limit, got %d", c.Restarts())
+	}
+}
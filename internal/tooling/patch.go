@@ -0,0 +1,241 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+// PatchTool applies a unified diff that may touch several files, as
+// produced by `git diff` or `diff -u`. Hunks are applied as sys_patch_file
+// applies them; if one does not match, no file is changed.
+type PatchTool struct {
+	fs sys.FS
+}
+
+func NewPatchTool(f sys.FS) *PatchTool {
+	return &PatchTool{fs: f}
+}
+
+func (t *PatchTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_patch",
+		Description: "Apply a unified diff (git diff or diff -u format) that may create, edit or delete several files. Context lines must match exactly; if any hunk fails, no file is changed.",
+		Source:      "system",
+		Category:    CategoryFileSystem,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer},
+		Complexity:  6,
+		Permissions: []Permission{PermWrite},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"patch": {"type": "string", "description": "Unified diff with ---/+++ file headers and @@ hunks; a/ and b/ prefixes are stripped"},
+				"root": {"type": "string", "description": "Directory the diff's paths are relative to (default: the working directory)"}
+			},
+			"required": ["patch"]
+		}`),
+		Examples: []string{
+			`{"tool": "sys_patch", "parameters": {"patch": "--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n package main\n \n-// old\n+// new\n"}}`,
+		},
+	}
+}
+
+// patchedFile is one file of a diff with its new content worked out.
+type patchedFile struct {
+	path     string
+	content  []byte
+	delete   bool
+	original []byte // nil for a file the diff creates
+	stats    sys.PatchStats
+}
+
+// PatchFileResult describes one file changed by sys_patch.
+type PatchFileResult struct {
+	Path    string `json:"path"`
+	Action  string `json:"action"` // created, modified or deleted
+	Added   int    `json:"lines_added"`
+	Removed int    `json:"lines_removed"`
+}
+
+func (t *PatchTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Patch string `json:"patch"`
+		Root  string `json:"root"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.Patch) == "" {
+		err := fmt.Errorf("nothing to apply: patch is empty")
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	fileDiffs, err := sys.ParseMultiFileDiff(input.Patch)
+	if err != nil {
+		err = fmt.Errorf("invalid diff: %w", err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	ReportStatus("🩹", "exec", fmt.Sprintf("Applying patch to %d file(s)", len(fileDiffs)))
+
+	// Work out every file before writing any, so a hunk that does not
+	// match leaves the tree untouched.
+	files := make([]patchedFile, 0, len(fileDiffs))
+	seen := make(map[string]bool, len(fileDiffs))
+	for _, fd := range fileDiffs {
+		pf, err := t.prepare(input.Root, fd)
+		if err == nil && seen[pf.path] {
+			err = fmt.Errorf("%s is patched more than once", pf.path)
+		}
+		if err != nil {
+			ReportStatus("❌", "exec", fmt.Sprintf("Patch did not apply: %v", firstLine(err.Error())))
+			result := &ToolResult{Status: "error", Error: err}
+			var herr *sys.HunkError
+			if errors.As(err, &herr) {
+				result.Data = herr
+			}
+			return result, err
+		}
+		seen[pf.path] = true
+		files = append(files, pf)
+	}
+
+	var done []bulkWrite
+	for _, pf := range files {
+		if err := ctx.Err(); err == nil {
+			err = t.write(pf, &done)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", pf.path, err)
+			ReportStatus("❌", "exec", fmt.Sprintf("Patch failed at %s; rolling back %d file(s)", pf.path, len(done)))
+			if rbErr := rollbackWrites(t.fs, done); rbErr != nil {
+				err = fmt.Errorf("%w (rollback incomplete: %v)", err, rbErr)
+			}
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+
+	results := make([]PatchFileResult, 0, len(files))
+	var lines []string
+	var undoIDs []string
+	for i, pf := range files {
+		r := PatchFileResult{Path: pf.path, Action: "modified", Added: pf.stats.Added, Removed: pf.stats.Removed}
+		switch {
+		case pf.delete:
+			r.Action = "deleted"
+		case pf.original == nil:
+			r.Action = "created"
+		}
+		results = append(results, r)
+		lines = append(lines, fmt.Sprintf("%s %s: +%d -%d", r.Action, r.Path, r.Added, r.Removed))
+		if done[i].undo != nil {
+			undoIDs = append(undoIDs, done[i].undo.ID)
+		}
+	}
+	summary := fmt.Sprintf("Patched %d file(s)", len(files))
+	ReportStatus("✅", "exec", summary)
+	result := &ToolResult{
+		Status:  "success",
+		Content: summary + "\n" + strings.Join(lines, "\n"),
+		Data:    results,
+	}
+	for _, r := range results {
+		result.Artifacts = append(result.Artifacts, r.Path)
+	}
+	if len(undoIDs) > 0 {
+		result.Content += fmt.Sprintf("\n(undo ids %s)", strings.Join(undoIDs, ", "))
+		result.Meta = map[string]interface{}{"undo_ids": undoIDs}
+	}
+	return result, nil
+}
+
+// prepare reads the file a FileDiff targets and applies its hunks in
+// memory.
+func (t *PatchTool) prepare(root string, fd sys.FileDiff) (patchedFile, error) {
+	oldName, newName := patchPath(fd.OldName), patchPath(fd.NewName)
+	name := newName
+	if name == "" {
+		name = oldName
+	}
+	if name == "" {
+		return patchedFile{}, fmt.Errorf("a file in the diff has no ---/+++ headers")
+	}
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return patchedFile{}, fmt.Errorf("%s: diff paths must stay inside the root", name)
+	}
+	if oldName != "" && newName != "" && oldName != newName {
+		return patchedFile{}, fmt.Errorf("%s: renames are not supported; delete and create the file instead", oldName)
+	}
+	pf := patchedFile{path: filepath.Join(root, filepath.FromSlash(name)), delete: newName == ""}
+
+	if oldName != "" {
+		raw, err := t.fs.ReadFile(pf.path)
+		if err != nil {
+			return pf, fmt.Errorf("%s: %w", pf.path, err)
+		}
+		pf.original = raw
+	} else if _, err := t.fs.ReadFile(pf.path); err == nil {
+		return pf, fmt.Errorf("%s: the diff creates it, but it already exists", pf.path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return pf, fmt.Errorf("%s: %w", pf.path, err)
+	}
+
+	content, stats, err := sys.ApplyPatch(string(pf.original), fd.Hunks)
+	if err != nil {
+		return pf, fmt.Errorf("%s: %w", pf.path, err)
+	}
+	if pf.delete && len(content) > 0 {
+		return pf, fmt.Errorf("%s: the diff deletes it but leaves %d bytes", pf.path, len(content))
+	}
+	pf.content, pf.stats = []byte(content), stats
+	return pf, nil
+}
+
+// write applies one prepared file, backing it up first so it can be
+// rolled back or undone with sys_undo_write.
+func (t *PatchTool) write(pf patchedFile, done *[]bulkWrite) error {
+	w := bulkWrite{path: pf.path, original: pf.original}
+	if b, ok := t.fs.(backuper); ok {
+		var err error
+		if w.undo, err = b.Backup(pf.path); err != nil {
+			return err
+		}
+	}
+	var err error
+	if pf.delete {
+		err = t.fs.DeleteFile(pf.path)
+	} else {
+		err = t.fs.WriteFile(pf.path, pf.content)
+	}
+	if err != nil {
+		if w.undo != nil {
+			t.fs.(backuper).UndoJournal().Forget(w.undo.ID)
+		}
+		return err
+	}
+	*done = append(*done, w)
+	return nil
+}
+
+// patchPath strips the a/ or b/ prefix git puts on diff paths. /dev/null,
+// the other side of a created or deleted file, becomes "".
+func patchPath(name string) string {
+	if name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+// firstLine is s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,135 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func runPatch(t *testing.T, tool *PatchTool, patch, root string) (*ToolResult, error) {
+	t.Helper()
+	args, _ := json.Marshal(map[string]string{"patch": patch, "root": root})
+	return tool.Execute(context.Background(), args)
+}
+
+func TestPatchTool_MultiFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(dir, "pkg", "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pkg", "old.txt"), []byte("bye\n"), 0644)
+
+	patch := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -2,4 +2,5 @@
+
+ func main() {
+-	println("hi")
++	println("hello")
++	println("world")
+ }
+--- /dev/null
++++ b/notes/new.txt
+@@ -0,0 +1,2 @@
++first
++second
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	res, err := runPatch(t, NewPatchTool(sys.NewLocalFS(dir)), patch, "pkg")
+	if err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "pkg", "main.go"))
+	if string(got) != "package main\n\nfunc main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n" {
+		t.Errorf("unexpected main.go:\n%s", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "pkg", "notes", "new.txt")); string(got) != "first\nsecond\n" {
+		t.Errorf("expected the new file to be created, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pkg", "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected old.txt to be deleted, got %v", err)
+	}
+	files := res.Data.([]PatchFileResult)
+	if len(files) != 3 || files[0].Action != "modified" || files[0].Added != 2 || files[0].Removed != 1 ||
+		files[1].Action != "created" || files[2].Action != "deleted" {
+		t.Errorf("unexpected results %+v", files)
+	}
+}
+
+func TestPatchTool_MismatchChangesNothing(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("alpha\nbeta\ngamma\n"), 0644)
+
+	patch := `--- a/a.txt
++++ b/a.txt
+@@ -1,2 +1,2 @@
+ one
+-two
++TWO
+--- a/b.txt
++++ b/b.txt
+@@ -1,3 +1,3 @@
+ alpha
+-BETA
++delta
+ gamma
+`
+	res, err := runPatch(t, NewPatchTool(sys.NewLocalFS(dir)), patch, "")
+	var herr *sys.HunkError
+	if !errors.As(err, &herr) || res.Status != "error" {
+		t.Fatalf("expected a hunk error, got %v", err)
+	}
+	if herr.Hunk != 1 || herr.Reason != "search text not found" || herr.Context[1] != "beta" {
+		t.Errorf("unexpected hunk error %+v", herr)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(got) != "one\ntwo\n" {
+		t.Errorf("expected a.txt untouched, got %q", got)
+	}
+}
+
+func TestPatchTool_RollsBackFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "blocker"), []byte("file\n"), 0644)
+	lfs := sys.NewLocalFS(dir)
+	journal := sys.NewUndoJournal(t.TempDir(), dir, sys.StoragePolicy{})
+	lfs.SetUndoJournal(journal)
+
+	// blocker is a file, so nothing can be created beneath it.
+	patch := `--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-one
++ONE
+--- /dev/null
++++ b/blocker/new.txt
+@@ -0,0 +1 @@
++x
+`
+	if _, err := runPatch(t, NewPatchTool(lfs), patch, ""); err == nil {
+		t.Fatal("expected the write beneath a file to fail")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(got) != "one\n" {
+		t.Errorf("expected a.txt to be restored, got %q", got)
+	}
+	if entries, _ := journal.List(); len(entries) != 0 {
+		t.Errorf("expected no undo entries after rollback, got %+v", entries)
+	}
+}
+
+func TestPatchTool_RejectsEscapingPaths(t *testing.T) {
+	patch := "--- a/../x.txt\n+++ b/../x.txt\n@@ -1 +1 @@\n-a\n+b\n"
+	if _, err := runPatch(t, NewPatchTool(sys.NewLocalFS(t.TempDir())), patch, ""); err == nil || !strings.Contains(err.Error(), "inside the root") {
+		t.Errorf("expected a path outside the root to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProcessInfo is one running process as reported by sys_proc.
+type ProcessInfo struct {
+	PID  int     `json:"pid"`
+	Name string  `json:"name"`
+	CPU  float64 `json:"cpu"` // Percent of one core, averaged over the process lifetime
+	Mem  uint64  `json:"mem"` // Resident memory in bytes, 0 where unavailable
+}
+
+// Defaults for sys_proc list.
+const (
+	defaultProcLimit = 30
+	maxProcLimit     = 500
+)
+
+// ProcessTool lists running processes and kills them by PID, so the agent
+// can find a runaway build without platform-specific ps flags.
+type ProcessTool struct{}
+
+type procInput struct {
+	Op     string `json:"op"`
+	PID    int    `json:"pid"`
+	Filter string `json:"filter"`
+	Limit  int    `json:"limit"`
+}
+
+func (t *ProcessTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_proc",
+		Description: "List running processes (busiest first) or kill one by PID. Killing always needs the user's approval.",
+		Source:      "system",
+		Category:    CategorySystem,
+		Roles:       []AgentRole{RoleEngineer},
+		Complexity:  4,
+		Permissions: []Permission{PermExecute, PermSensitive},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"op": {"type": "string", "enum": ["list", "kill"], "description": "list (default) or kill"},
+				"pid": {"type": "integer", "description": "Process to kill"},
+				"filter": {"type": "string", "description": "list: only processes whose name contains this"},
+				"limit": {"type": "integer", "description": "list: most processes to return (default 30, at most 500)"}
+			}
+		}`),
+	}
+}
+
+// PermissionsFor narrows the declared permissions to the operation asked
+// for: listing only reads, while kill goes through the enclave.
+func (t *ProcessTool) PermissionsFor(args json.RawMessage) []Permission {
+	var input procInput
+	json.Unmarshal(args, &input)
+	if strings.EqualFold(input.Op, "kill") {
+		return []Permission{PermExecute}
+	}
+	return []Permission{PermRead}
+}
+
+func (t *ProcessTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input procInput
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, err
+		}
+	}
+
+	switch strings.ToLower(input.Op) {
+	case "", "list":
+		return t.list(input)
+	case "kill":
+		return t.kill(input.PID)
+	default:
+		err := fmt.Errorf("unknown op %q (use list or kill)", input.Op)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+}
+
+func (t *ProcessTool) list(input procInput) (*ToolResult, error) {
+	procs, err := listProcesses()
+	if err != nil {
+		err = fmt.Errorf("listing processes: %w", err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	if f := strings.ToLower(input.Filter); f != "" {
+		kept := procs[:0]
+		for _, p := range procs {
+			if strings.Contains(strings.ToLower(p.Name), f) {
+				kept = append(kept, p)
+			}
+		}
+		procs = kept
+	}
+	sort.Slice(procs, func(i, j int) bool {
+		if procs[i].CPU != procs[j].CPU {
+			return procs[i].CPU > procs[j].CPU
+		}
+		return procs[i].Mem > procs[j].Mem
+	})
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultProcLimit
+	}
+	limit = min(limit, maxProcLimit)
+	total := len(procs)
+	if total > limit {
+		procs = procs[:limit]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-8s %6s %9s  %s\n", "PID", "CPU%", "MEM", "NAME")
+	for _, p := range procs {
+		fmt.Fprintf(&sb, "%-8d %6.1f %9s  %s\n", p.PID, p.CPU, formatMem(p.Mem), p.Name)
+	}
+	if total > len(procs) {
+		fmt.Fprintf(&sb, "... %d more not shown\n", total-len(procs))
+	}
+	return &ToolResult{
+		Status:  "success",
+		Content: sb.String(),
+		Data:    procs,
+		Meta:    map[string]interface{}{"total": total},
+	}, nil
+}
+
+func (t *ProcessTool) kill(pid int) (*ToolResult, error) {
+	if pid <= 1 {
+		err := fmt.Errorf("refusing to kill pid %d", pid)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if pid == os.Getpid() {
+		err := fmt.Errorf("refusing to kill vibeaura itself (pid %d)", pid)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	ReportStatus("🛑", "exec", fmt.Sprintf("Killing process %d", pid))
+	if err := killProcess(pid); err != nil {
+		err = fmt.Errorf("killing %d: %w", pid, err)
+		ReportStatus("❌", "exec", err.Error())
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	msg := fmt.Sprintf("Sent termination to process %d", pid)
+	ReportStatus("✅", "exec", msg)
+	return &ToolResult{Status: "success", Content: msg, Meta: map[string]interface{}{"pid": pid}}, nil
+}
+
+func formatMem(b uint64) string {
+	switch {
+	case b == 0:
+		return "-"
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(b)/(1<<20))
+	default:
+		return fmt.Sprintf("%dK", b>>10)
+	}
+}
@@ -0,0 +1,81 @@
+package tooling
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// clockTicks is USER_HZ, which is 100 on every Linux architecture Go runs on.
+const clockTicks = 100
+
+// listProcesses reads /proc. Processes that exit mid-scan, or whose stat
+// cannot be read, are left out.
+func listProcesses() ([]ProcessInfo, error) {
+	uptime, err := readUptime()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	pageSize := uint64(os.Getpagesize())
+
+	var procs []ProcessInfo
+	for _, d := range dirs {
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil || !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", d.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		p, ok := parseProcStat(string(data), uptime, pageSize)
+		if !ok {
+			continue
+		}
+		p.PID = pid
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// parseProcStat reads name, lifetime CPU share and RSS from a
+// /proc/<pid>/stat line. The name is in parentheses and may itself contain
+// spaces or parentheses, so fields are counted from the last ')'.
+func parseProcStat(stat string, uptime float64, pageSize uint64) (ProcessInfo, bool) {
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return ProcessInfo{}, false
+	}
+	// fields[0] is the state, field 3 of proc(5).
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return ProcessInfo{}, false
+	}
+	utime, _ := strconv.ParseFloat(fields[11], 64)
+	stime, _ := strconv.ParseFloat(fields[12], 64)
+	start, _ := strconv.ParseFloat(fields[19], 64)
+	rss, _ := strconv.ParseUint(fields[21], 10, 64)
+
+	p := ProcessInfo{Name: stat[open+1 : end], Mem: rss * pageSize}
+	if elapsed := uptime - start/clockTicks; elapsed > 0 {
+		p.CPU = (utime + stime) / clockTicks / elapsed * 100
+	}
+	return p, true
+}
+
+func readUptime() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, os.ErrInvalid
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
@@ -0,0 +1,21 @@
+package tooling
+
+import "testing"
+
+func TestParseProcStat(t *testing.T) {
+	// A name with spaces and parentheses, 10s of CPU over 20s of life.
+	stat := "123 (my (odd) proc) S 1 123 123 0 -1 4194560 100 0 0 0 600 400 0 0 20 0 1 0 1000 1000000 50 18446744073709551615"
+	p, ok := parseProcStat(stat, 30, 4096)
+	if !ok {
+		t.Fatal("parseProcStat failed")
+	}
+	if p.Name != "my (odd) proc" {
+		t.Errorf("name = %q", p.Name)
+	}
+	if p.Mem != 50*4096 {
+		t.Errorf("mem = %d", p.Mem)
+	}
+	if p.CPU < 49.9 || p.CPU > 50.1 {
+		t.Errorf("cpu = %.2f, want 50", p.CPU)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !linux && !windows
+
+package tooling
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listProcesses asks ps, since macOS and the BSDs have no /proc to read.
+func listProcesses() ([]ProcessInfo, error) {
+	out, err := exec.Command("ps", "-axo", "pid=,pcpu=,rss=,comm=").Output()
+	if err != nil {
+		return nil, err
+	}
+	var procs []ProcessInfo
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[1], 64)
+		rss, _ := strconv.ParseUint(fields[2], 10, 64)
+		procs = append(procs, ProcessInfo{
+			PID:  pid,
+			Name: strings.Join(fields[3:], " "),
+			CPU:  cpu,
+			Mem:  rss << 10, // ps reports KiB
+		})
+	}
+	return procs, nil
+}
@@ -0,0 +1,57 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestProcessTool_ListFindsSelf(t *testing.T) {
+	res, err := (&ProcessTool{}).Execute(context.Background(), json.RawMessage(`{"limit": 500}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	procs, _ := res.Data.([]ProcessInfo)
+	for _, p := range procs {
+		if p.PID == os.Getpid() {
+			if p.Name == "" {
+				t.Error("own process listed without a name")
+			}
+			return
+		}
+	}
+	t.Errorf("pid %d not among %d listed processes", os.Getpid(), len(procs))
+}
+
+func TestProcessTool_KillRefusesSelfAndInit(t *testing.T) {
+	tool := &ProcessTool{}
+	for _, pid := range []int{0, 1, os.Getpid()} {
+		args, _ := json.Marshal(map[string]interface{}{"op": "kill", "pid": pid})
+		if _, err := tool.Execute(context.Background(), args); err == nil {
+			t.Errorf("expected kill of pid %d to be refused", pid)
+		}
+	}
+}
+
+func TestProcessTool_KillNeedsApproval(t *testing.T) {
+	guard := NewSecurityGuard()
+	asked := 0
+	guard.SetInterceptor(func(tool Tool, args json.RawMessage) (bool, error) {
+		asked++
+		return false, nil
+	})
+	secured := WrapWithSecurity(&ProcessTool{}, guard)
+
+	if _, err := secured.Execute(context.Background(), json.RawMessage(`{"op": "list", "limit": 1}`)); err != nil || asked != 0 {
+		t.Fatalf("expected list to run without asking, asked=%d (%v)", asked, err)
+	}
+	if _, err := secured.Execute(context.Background(), json.RawMessage(`{"op": "kill", "pid": 999999}`)); err == nil || asked != 1 {
+		t.Fatalf("expected kill to go to the interceptor and be declined, asked=%d (%v)", asked, err)
+	}
+
+	_, req, risk, err := buildApprovalRequest(&ProcessTool{}, json.RawMessage(`{"op": "kill", "pid": 42}`))
+	if err != nil || risk != "high" || req.Summary != "kill process 42" {
+		t.Errorf("unexpected approval request %+v risk=%s (%v)", req, risk, err)
+	}
+}
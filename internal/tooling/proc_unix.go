@@ -0,0 +1,10 @@
+//go:build !windows
+
+package tooling
+
+import "syscall"
+
+// killProcess sends SIGTERM, giving the process a chance to clean up.
+func killProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,70 @@
+package tooling
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// listProcesses walks a Toolhelp snapshot. CPU comes from GetProcessTimes
+// where the process can be opened; memory is not reported.
+func listProcesses() ([]ProcessInfo, error) {
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snap)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	if err := windows.Process32First(snap, &entry); err != nil {
+		return nil, err
+	}
+	var procs []ProcessInfo
+	for {
+		procs = append(procs, ProcessInfo{
+			PID:  int(entry.ProcessID),
+			Name: windows.UTF16ToString(entry.ExeFile[:]),
+			CPU:  processCPU(entry.ProcessID),
+		})
+		if err := windows.Process32Next(snap, &entry); err != nil {
+			break // ERROR_NO_MORE_FILES
+		}
+	}
+	return procs, nil
+}
+
+func processCPU(pid uint32) float64 {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return 0
+	}
+	defer windows.CloseHandle(h)
+
+	var created, exited, kernel, user windows.Filetime
+	if windows.GetProcessTimes(h, &created, &exited, &kernel, &user) != nil {
+		return 0
+	}
+	elapsed := time.Since(time.Unix(0, created.Nanoseconds()))
+	if elapsed <= 0 {
+		return 0
+	}
+	// Kernel and user times are in 100ns units.
+	busy := time.Duration((filetimeTicks(kernel) + filetimeTicks(user)) * 100)
+	return float64(busy) / float64(elapsed) * 100
+}
+
+func filetimeTicks(ft windows.Filetime) int64 {
+	return int64(ft.HighDateTime)<<32 | int64(ft.LowDateTime)
+}
+
+// killProcess terminates the process outright; Windows has no SIGTERM.
+func killProcess(pid int) error {
+	h, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	return windows.TerminateProcess(h, 1)
+}
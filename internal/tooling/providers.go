@@ -11,10 +11,11 @@ type SystemProvider struct {
 	fs      sys.FS
 	monitor *sys.Monitor
 	guard   *SecurityGuard
+	cache   *ToolCache // nil disables result caching
 }
 
-func NewSystemProvider(f sys.FS, m *sys.Monitor, guard *SecurityGuard) *SystemProvider {
-	return &SystemProvider{fs: f, monitor: m, guard: guard}
+func NewSystemProvider(f sys.FS, m *sys.Monitor, guard *SecurityGuard, cache *ToolCache) *SystemProvider {
+	return &SystemProvider{fs: f, monitor: m, guard: guard, cache: cache}
 }
 
 func (p *SystemProvider) Name() string { return "system" }
@@ -23,18 +24,36 @@ func (p *SystemProvider) Provide(ctx context.Context) ([]Tool, error) {
 	tools := []Tool{
 		NewReadFileTool(p.fs),
 		NewWriteFileTool(p.fs),
+		NewBulkWriteTool(p.fs),
+		NewPatchFileTool(p.fs),
+		NewPatchTool(p.fs),
+		NewUndoWriteTool(p.fs),
 		NewListFilesTool(p.fs),
 		NewListDirTool(p.fs),
 		NewFileStatsTool(p.fs),
+		NewRequestAccessTool(p.fs),
 		NewTraversalTool(p.fs),
 		&ShellExecTool{},
-		&GrepTool{},
-		NewSystemInfoTool(p.monitor),
+		&ProcessTool{},
+		&DockerTool{},
+		NewGrepTool(p.fs),
+		NewSearchFilesTool(p.fs),
+		NewStatefulSystemInfoTool(p.monitor),
+		&EnvTool{},
 		&FetchURLTool{},
+		&RestTool{},
+		&WebSocketTool{},
+		&GitTool{},
+		&GitStatusTool{},
+		&GitDiffTool{},
+		&GitCommitTool{},
 	}
 
 	var secured []Tool
 	for _, t := range tools {
+		if p.cache != nil {
+			t = WrapWithCache(t, p.cache)
+		}
 		if p.guard != nil {
 			secured = append(secured, WrapWithSecurity(t, p.guard))
 		} else {
@@ -61,12 +80,13 @@ func (p *VibeProvider) Provide(ctx context.Context) ([]Tool, error) {
 	return []Tool{}, nil
 }
 
-// Global Registry Setup
-func Setup(f sys.FS, m *sys.Monitor, guard *SecurityGuard) *Registry {
+// Global Registry Setup. A non-nil cache reuses identical read and fetch
+// results (see CachingTool).
+func Setup(f sys.FS, m *sys.Monitor, guard *SecurityGuard, cache *ToolCache) *Registry {
 	r := NewRegistry()
 
 	// Register Providers
-	r.RegisterProvider(NewSystemProvider(f, m, guard))
+	r.RegisterProvider(NewSystemProvider(f, m, guard, cache))
 	r.RegisterProvider(NewVibeProvider())
 
 	// Explicitly Register the Wand (Discovery Tool) which needs the registry itself
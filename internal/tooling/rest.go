@@ -0,0 +1,229 @@
+package tooling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Limits for http_request. The timeout covers the whole exchange, body
+// included, so it can be raised for large uploads without touching the
+// shared HTTP client.
+const (
+	defaultRestTimeout = 30 * time.Second
+	maxRestTimeout     = 10 * time.Minute
+	maxRestResponse    = 4 << 20   // Bytes of the response read
+	maxRestOutput      = 128 << 10 // Bytes of the body returned to the model
+)
+
+// restMethods are the methods http_request accepts.
+var restMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// restClient has no timeout of its own; each call sets one on its context.
+var restClient = &http.Client{}
+
+// RestTool sends one HTTP request with any method, headers and body, for
+// REST APIs that http_fetch, which only GETs, cannot drive.
+type RestTool struct{}
+
+type restInput struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	Timeout float64           `json:"timeout"`
+}
+
+// RestResponse is the Data of an http_request result. Pretty and JSON are
+// set when the server answered with JSON.
+type RestResponse struct {
+	StatusCode int               `json:"status_code"`
+	Status     string            `json:"status"`
+	Headers    map[string]string `json:"headers"`
+	Raw        string            `json:"raw"`
+	Pretty     string            `json:"pretty,omitempty"`
+	JSON       interface{}       `json:"json,omitempty"`
+}
+
+func (t *RestTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "http_request",
+		Description: "Send an HTTP request (GET, POST, PUT, PATCH or DELETE) with optional headers and body, and return the status and response. JSON responses are returned pretty-printed. Methods other than GET need the user's approval.",
+		Source:      "system",
+		Category:    CategoryNetwork,
+		Roles:       []AgentRole{RoleEngineer, RoleArchitect},
+		Complexity:  5,
+		Permissions: []Permission{PermNetwork},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"method": {"type": "string", "enum": ["GET", "POST", "PUT", "PATCH", "DELETE"], "description": "HTTP method (default GET)"},
+				"url": {"type": "string", "description": "http:// or https:// URL"},
+				"headers": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Request headers"},
+				"body": {"type": "string", "description": "Request body, sent as is"},
+				"timeout": {"type": "number", "description": "Seconds to allow for the whole request (default 30, at most 600)"}
+			},
+			"required": ["url"]
+		}`),
+		Examples: []string{
+			`{"tool": "http_request", "parameters": {"method": "POST", "url": "https://httpbin.org/post", "headers": {"Content-Type": "application/json"}, "body": "{\"name\": \"vibe\"}"}}`,
+			`{"tool": "http_request", "parameters": {"method": "DELETE", "url": "http://localhost:8080/items/42", "headers": {"Authorization": "Bearer $TOKEN"}}}`,
+		},
+	}
+}
+
+// Cacheable reports whether a call may be answered from the tool cache:
+// only GETs, since the other methods change something on the server.
+func (t *RestTool) Cacheable(args json.RawMessage) bool {
+	var input restInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return false
+	}
+	return restMethod(input.Method) == http.MethodGet
+}
+
+// PermissionsFor escalates methods that change something on the server:
+// GET and HEAD only use the network, anything else goes through the enclave.
+func (t *RestTool) PermissionsFor(args json.RawMessage) []Permission {
+	var input restInput
+	json.Unmarshal(args, &input)
+	if m := restMethod(input.Method); m == http.MethodGet || m == http.MethodHead {
+		return []Permission{PermNetwork}
+	}
+	return []Permission{PermNetwork, PermExecute}
+}
+
+func (t *RestTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input restInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	method := restMethod(input.Method)
+	if !validRestMethod(method) {
+		err := fmt.Errorf("http_request method must be one of %s, got %q", strings.Join(restMethods, ", "), input.Method)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if u, err := url.Parse(input.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err := fmt.Errorf("http_request needs an http:// or https:// url, got %q", input.URL)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	timeout := defaultRestTimeout
+	if input.Timeout > 0 {
+		timeout = time.Duration(input.Timeout * float64(time.Second))
+	}
+	if timeout > maxRestTimeout {
+		timeout = maxRestTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if input.Body != "" {
+		body = strings.NewReader(input.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, input.URL, body)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	for k, v := range input.Headers {
+		req.Header.Set(k, v)
+	}
+
+	ReportStatus("🌐", "exec", fmt.Sprintf("%s %s", method, input.URL))
+	resp, err := restClient.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%s %s did not finish within %s", method, input.URL, timeout)
+		} else {
+			err = fmt.Errorf("%s %s: %w", method, input.URL, err)
+		}
+		ReportStatus("❌", "exec", err.Error())
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxRestResponse+1))
+	if err != nil {
+		err = fmt.Errorf("reading the response to %s %s: %w", method, input.URL, err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	meta := map[string]interface{}{"status_code": resp.StatusCode, "bytes": len(raw)}
+	if len(raw) > maxRestResponse {
+		raw = raw[:maxRestResponse]
+		meta["truncated"] = true
+	}
+
+	data := RestResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    flattenHeaders(resp.Header),
+		Raw:        string(raw),
+	}
+	text := data.Raw
+	if isJSONContent(resp.Header.Get("Content-Type")) {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, raw, "", "  ") == nil {
+			data.Pretty = pretty.String()
+			_ = json.Unmarshal(raw, &data.JSON)
+			text = data.Pretty
+		}
+	}
+	if len(text) > maxRestOutput {
+		text = text[:maxRestOutput]
+		meta["truncated"] = true
+	}
+
+	ReportStatus("✅", "exec", fmt.Sprintf("%s (%d bytes)", resp.Status, len(raw)))
+	return &ToolResult{
+		Status:  "success",
+		Content: fmt.Sprintf("HTTP %s\n\n%s", resp.Status, text),
+		Data:    data,
+		Meta:    meta,
+	}, nil
+}
+
+// restMethod normalises a requested method, GET when none is given.
+func restMethod(m string) string {
+	m = strings.ToUpper(strings.TrimSpace(m))
+	if m == "" {
+		return http.MethodGet
+	}
+	return m
+}
+
+func validRestMethod(m string) bool {
+	for _, ok := range restMethods {
+		if m == ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONContent reports whether a Content-Type is JSON, including
+// vendor types like application/problem+json.
+func isJSONContent(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// flattenHeaders joins repeated response headers with commas.
+func flattenHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
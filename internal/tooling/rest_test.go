@@ -0,0 +1,142 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runRest(t *testing.T, tool Tool, args string) (*ToolResult, error) {
+	t.Helper()
+	return tool.Execute(context.Background(), json.RawMessage(args))
+}
+
+func TestRestTool_SendsMethodHeadersAndBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"token":  r.Header.Get("X-Token"),
+			"body":   string(body),
+		})
+	}))
+	defer srv.Close()
+
+	res, err := runRest(t, &RestTool{}, `{"method": "post", "url": "`+srv.URL+`", "headers": {"X-Token": "abc"}, "body": "hi"}`)
+	if err != nil {
+		t.Fatalf("http_request failed: %v", err)
+	}
+	data := res.Data.(RestResponse)
+	if data.StatusCode != http.StatusCreated || !strings.HasPrefix(res.Content, "HTTP 201 Created\n\n{\n  ") {
+		t.Errorf("expected a pretty-printed 201, got %q", res.Content)
+	}
+	if data.Raw != `{"body":"hi","method":"POST","token":"abc"}`+"\n" {
+		t.Errorf("expected the raw body kept, got %q", data.Raw)
+	}
+	if got := data.JSON.(map[string]interface{}); got["method"] != "POST" || got["token"] != "abc" || got["body"] != "hi" {
+		t.Errorf("expected the request echoed back, got %v", got)
+	}
+}
+
+func TestRestTool_PlainResponseAndErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such item", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	res, err := runRest(t, &RestTool{}, `{"method": "DELETE", "url": "`+srv.URL+`/items/42"}`)
+	if err != nil {
+		t.Fatalf("a 404 is an answer, not a failure: %v", err)
+	}
+	if res.Content != "HTTP 404 Not Found\n\nno such item\n" || res.Data.(RestResponse).Pretty != "" {
+		t.Errorf("expected the plain body as is, got %q", res.Content)
+	}
+}
+
+func TestRestTool_RejectsBadInput(t *testing.T) {
+	for _, args := range []string{
+		`{"method": "TRACE", "url": "http://localhost"}`,
+		`{"url": "ftp://example.com"}`,
+		`{"url": "localhost:8080"}`,
+	} {
+		if res, err := runRest(t, &RestTool{}, args); err == nil || res.Status != "error" {
+			t.Errorf("expected %s to be rejected", args)
+		}
+	}
+}
+
+func TestRestTool_TimesOut(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	_, err := runRest(t, &RestTool{}, `{"url": "`+srv.URL+`", "timeout": 0.1}`)
+	if err == nil || !strings.Contains(err.Error(), "did not finish within 100ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestRestTool_CachesOnlyGets(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+	tool := WrapWithCache(&RestTool{}, NewToolCache(16))
+
+	for i := 0; i < 2; i++ {
+		runRest(t, tool, `{"url": "`+srv.URL+`"}`)
+	}
+	if hits != 1 {
+		t.Errorf("expected the second GET from the cache, server saw %d", hits)
+	}
+	for i := 0; i < 2; i++ {
+		runRest(t, tool, `{"method": "POST", "url": "`+srv.URL+`"}`)
+	}
+	if hits != 3 {
+		t.Errorf("expected every POST to reach the server, server saw %d", hits)
+	}
+}
+
+func TestRestTool_MutatingMethodsNeedApproval(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+	guard := NewSecurityGuard()
+	asked := 0
+	guard.SetInterceptor(func(tool Tool, args json.RawMessage) (bool, error) {
+		asked++
+		return false, nil
+	})
+	secured := WrapWithSecurity(&RestTool{}, guard)
+
+	if _, err := runRest(t, secured, `{"url": "`+srv.URL+`"}`); err != nil || asked != 0 {
+		t.Fatalf("expected GET to run without asking, asked=%d (%v)", asked, err)
+	}
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		if _, err := runRest(t, secured, `{"method": "`+method+`", "url": "`+srv.URL+`"}`); err == nil {
+			t.Errorf("expected %s to be declined", method)
+		}
+	}
+	if asked != 4 || hits != 1 {
+		t.Errorf("expected every mutating method to be asked about and none sent, asked=%d hits=%d", asked, hits)
+	}
+
+	_, _, risk, _ := buildApprovalRequest(&RestTool{}, json.RawMessage(`{"method": "DELETE", "url": "`+srv.URL+`"}`))
+	if risk != "high" {
+		t.Errorf("expected DELETE to be high risk, got %s", risk)
+	}
+}
@@ -14,6 +14,18 @@ var (
 	ErrBlockedAccess = errors.New("security: access to this resource is blocked by default")
 )
 
+// Per-tool policies, as set in security.tool_policy.
+const (
+	PolicyAllow = "allow" // Run without asking
+	PolicyDeny  = "deny"  // Never run
+	PolicyAsk   = "ask"   // Always go through the interceptor
+)
+
+// ValidToolPolicy reports whether v is a recognised per-tool policy.
+func ValidToolPolicy(v string) bool {
+	return v == PolicyAllow || v == PolicyDeny || v == PolicyAsk
+}
+
 // SecurityGuard manages access policies for tools.
 type SecurityGuard struct {
 	blockedPaths    []string
@@ -24,7 +36,11 @@ type SecurityGuard struct {
 	allowedPermissions map[Permission]bool
 	deniedPermissions  map[Permission]bool
 
-	interceptor func(tool Tool, args json.RawMessage) (bool, error)
+	// Per-tool overrides keyed by tool name; see SetToolPolicy.
+	toolPolicy map[string]string
+	audit      *AuditLogger
+
+	interceptor func(ctx context.Context, tool Tool, args json.RawMessage) (bool, error)
 	mu          sync.RWMutex
 }
 
@@ -51,11 +67,47 @@ func (s *SecurityGuard) SetAllowEnv(allow bool) {
 // SetInterceptor installs a manual authorization hook.
 // The interceptor can return (false, *NeedsApprovalError) to request user input.
 func (s *SecurityGuard) SetInterceptor(fn func(tool Tool, args json.RawMessage) (bool, error)) {
+	s.SetContextInterceptor(func(_ context.Context, tool Tool, args json.RawMessage) (bool, error) {
+		return fn(tool, args)
+	})
+}
+
+// SetContextInterceptor is SetInterceptor for hooks that want the context
+// of the tool call, such as the Enclave's, which traces its decisions.
+func (s *SecurityGuard) SetContextInterceptor(fn func(ctx context.Context, tool Tool, args json.RawMessage) (bool, error)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.interceptor = fn
 }
 
+// SetToolPolicy replaces the per-tool policies. A tool with a policy skips
+// the permission-based decision: "allow" runs it without the interceptor,
+// "deny" refuses it and "ask" always sends it to the interceptor. Unknown
+// values are ignored.
+func (s *SecurityGuard) SetToolPolicy(policy map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolPolicy = make(map[string]string, len(policy))
+	for name, v := range policy {
+		if v = strings.ToLower(strings.TrimSpace(v)); ValidToolPolicy(v) {
+			s.toolPolicy[name] = v
+		}
+	}
+}
+
+// SetAuditLogger records policy decisions in l.
+func (s *SecurityGuard) SetAuditLogger(l *AuditLogger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audit = l
+}
+
+func (s *SecurityGuard) logPolicy(tool string, args json.RawMessage, decision string) {
+	if s.audit != nil {
+		s.audit.Log(tool, args, "policy", decision, resolveScope(args))
+	}
+}
+
 // SetPermissionPolicy sets whether a specific permission is globally allowed or denied.
 func (s *SecurityGuard) SetPermissionPolicy(p Permission, allowed bool) {
 	s.mu.Lock()
@@ -71,13 +123,31 @@ func (s *SecurityGuard) SetPermissionPolicy(p Permission, allowed bool) {
 
 // ValidateRequest checks if a tool execution is allowed based on its permissions and arguments.
 func (s *SecurityGuard) ValidateRequest(t Tool, args json.RawMessage) error {
+	return s.ValidateRequestContext(context.Background(), t, args)
+}
+
+// ValidateRequestContext is ValidateRequest for a call made under ctx,
+// which is passed on to the interceptor.
+func (s *SecurityGuard) ValidateRequestContext(ctx context.Context, t Tool, args json.RawMessage) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	m := t.Metadata()
-	perms := m.Permissions
+	perms := requestPermissions(t, args)
 	requiresManualApproval := false
 
+	switch s.toolPolicy[m.Name] {
+	case PolicyDeny:
+		s.logPolicy(m.Name, args, "Denied (Policy)")
+		return fmt.Errorf("%w: %s is denied by security.tool_policy", ErrBlockedAccess, m.Name)
+	case PolicyAllow:
+		s.logPolicy(m.Name, args, "Approved (Policy)")
+		return nil
+	case PolicyAsk:
+		// Denied permissions and sensitive data are still refused below.
+		requiresManualApproval = true
+	}
+
 	for _, p := range perms {
 		// 1. Check if explicitly denied
 		if s.deniedPermissions[p] {
@@ -102,7 +172,7 @@ func (s *SecurityGuard) ValidateRequest(t Tool, args json.RawMessage) error {
 
 	// If we need manual approval and have an interceptor, use it
 	if s.interceptor != nil {
-		approved, err := s.interceptor(t, args)
+		approved, err := s.interceptor(ctx, t, args)
 		if err != nil {
 			// Check if it's already an InterventionError from the Enclave
 			return err
@@ -116,6 +186,29 @@ func (s *SecurityGuard) ValidateRequest(t Tool, args json.RawMessage) error {
 	return nil
 }
 
+// argPermissioner is implemented by tools whose permissions depend on
+// the call, like sys_proc, which only reads to list but executes to kill.
+type argPermissioner interface {
+	PermissionsFor(args json.RawMessage) []Permission
+}
+
+// requestPermissions returns what a call to t with args needs, looking
+// through the security and cache wrappers for an argPermissioner.
+func requestPermissions(t Tool, args json.RawMessage) []Permission {
+	for {
+		switch w := t.(type) {
+		case argPermissioner:
+			return w.PermissionsFor(args)
+		case *SecureTool:
+			t = w.Tool
+		case *CachingTool:
+			t = w.Tool
+		default:
+			return t.Metadata().Permissions
+		}
+	}
+}
+
 // CheckPath verifies if a path is safe to access (remains for compatibility or internal checks).
 func (s *SecurityGuard) CheckPath(path string) error {
 	s.mu.RLock()
@@ -152,7 +245,7 @@ func (st *SecureTool) Metadata() ToolMetadata {
 
 // Execute performs security validation before delegating to the underlying Tool.
 func (st *SecureTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
-	if err := st.guard.ValidateRequest(st.Tool, args); err != nil {
+	if err := st.guard.ValidateRequestContext(ctx, st.Tool, args); err != nil {
 		return &ToolResult{Status: "error", Error: err}, err
 	}
 	return st.Tool.Execute(ctx, args)
@@ -0,0 +1,67 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type stubTool struct {
+	name  string
+	perms []Permission
+}
+
+func (s stubTool) Metadata() ToolMetadata {
+	return ToolMetadata{Name: s.name, Permissions: s.perms}
+}
+
+func (s stubTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	return &ToolResult{Status: "success"}, nil
+}
+
+func TestSecurityGuard_ToolPolicy(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	guard := NewSecurityGuard()
+	guard.SetAuditLogger(NewAuditLogger(auditPath))
+	asked := 0
+	guard.SetInterceptor(func(tool Tool, args json.RawMessage) (bool, error) {
+		asked++
+		return true, nil
+	})
+	guard.SetToolPolicy(map[string]string{
+		"sys_read_file":  "ask",
+		"sys_shell_exec": "allow",
+		"sys_write_file": "DENY",
+		"sys_list_files": "sometimes",
+	})
+	args := json.RawMessage(`{"path":"notes.md"}`)
+
+	// "ask" goes to the interceptor even though reads are auto-approved.
+	if err := guard.ValidateRequest(stubTool{"sys_read_file", []Permission{PermRead}}, args); err != nil || asked != 1 {
+		t.Fatalf("expected the interceptor to be asked, asked=%d (%v)", asked, err)
+	}
+	// "allow" skips the interceptor an execute permission would need.
+	if err := guard.ValidateRequest(stubTool{"sys_shell_exec", []Permission{PermExecute}}, args); err != nil || asked != 1 {
+		t.Fatalf("expected the policy to allow without asking, asked=%d (%v)", asked, err)
+	}
+	if err := guard.ValidateRequest(stubTool{"sys_write_file", []Permission{PermWrite}}, args); !errors.Is(err, ErrBlockedAccess) {
+		t.Fatalf("expected the policy to deny, got %v", err)
+	}
+	// Unknown policies fall back to permissions.
+	if err := guard.ValidateRequest(stubTool{"sys_list_files", []Permission{PermRead}}, args); err != nil || asked != 1 {
+		t.Fatalf("expected the permission decision, asked=%d (%v)", asked, err)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if !strings.Contains(log, "Approved (Policy)") || !strings.Contains(log, "Denied (Policy)") {
+		t.Errorf("expected policy decisions in the audit log, got:\n%s", log)
+	}
+}
@@ -40,7 +40,11 @@ func NewSession(id string) *Session {
 	}
 }
 
+// AddThread appends t, stamping it with the current time if it has none.
 func (s *Session) AddThread(t *Thread) {
+	if t.Timestamp.IsZero() {
+		t.Timestamp = time.Now()
+	}
 	s.Threads = append(s.Threads, t)
 	s.UpdatedAt = time.Now()
 }
@@ -1,12 +1,22 @@
 package tooling
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nathfavour/vibeauracle/sys"
 )
@@ -65,11 +75,37 @@ func (t *ReadFileTool) Execute(ctx context.Context, args json.RawMessage) (*Tool
 	}
 
 	ReportStatus("✅", "exec", fmt.Sprintf("Read %d bytes from %s", len(content), input.Path))
-	return &ToolResult{
+	result := &ToolResult{
 		Status:  "success",
 		Content: string(content),
 		Data:    map[string]interface{}{"size": len(content)},
-	}, nil
+	}
+
+	// Surface line-ending/indent conventions so the agent writes matching content.
+	if f, ok := t.fs.(formatter); ok {
+		format := f.Format(input.Path)
+		result.Meta = map[string]interface{}{"format": format}
+		if hint := format.Hint(); hint != "" {
+			result.Meta[MetaNote] = fmt.Sprintf("file format note: %s — preserved automatically on write", hint)
+		}
+	}
+	return result, nil
+}
+
+// formatter is implemented by filesystems that know a file's text conventions.
+type formatter interface {
+	Format(path string) sys.TextFormat
+}
+
+// encodingWriter is implemented by filesystems that accept an explicit encoding.
+type encodingWriter interface {
+	WriteFileEncoding(path string, content []byte, encoding string) error
+}
+
+// backuper is implemented by filesystems that keep an undo journal.
+type backuper interface {
+	Backup(path string) (*sys.UndoEntry, error)
+	UndoJournal() *sys.UndoJournal
 }
 
 // WriteFileTool creates or overwrites a file.
@@ -94,7 +130,8 @@ func (t *WriteFileTool) Metadata() ToolMetadata {
 			"type": "object",
 			"properties": {
 				"path": {"type": "string", "description": "Path to the file to write"},
-				"content": {"type": "string", "description": "Content to write to the file"}
+				"content": {"type": "string", "description": "Content to write to the file"},
+				"encoding": {"type": "string", "enum": ["utf-8", "utf-8-bom", "latin1"], "description": "Only needed to overwrite a non-UTF-8 file; line endings and BOM are otherwise preserved"}
 			},
 			"required": ["path", "content"]
 		}`),
@@ -103,8 +140,9 @@ func (t *WriteFileTool) Metadata() ToolMetadata {
 
 func (t *WriteFileTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
 	var input struct {
-		Path    string `json:"path"`
-		Content string `json:"content"`
+		Path     string `json:"path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
 	}
 	if err := json.Unmarshal(args, &input); err != nil {
 		return nil, err
@@ -112,18 +150,419 @@ func (t *WriteFileTool) Execute(ctx context.Context, args json.RawMessage) (*Too
 
 	ReportStatus("💾", "exec", fmt.Sprintf("Writing to file: %s", input.Path))
 
-	err := t.fs.WriteFile(input.Path, []byte(input.Content))
+	// Read what is there now for the diff; a missing file diffs from empty.
+	var before []byte
+	showDiff := !hideFileDiff.Load()
+	if showDiff {
+		before, _ = t.fs.ReadFile(input.Path)
+	}
+
+	// Write-ahead backup so sys_undo_write can revert this.
+	var undo *sys.UndoEntry
+	if b, ok := t.fs.(backuper); ok {
+		var err error
+		if undo, err = b.Backup(input.Path); err != nil {
+			ReportStatus("❌", "exec", fmt.Sprintf("Failed to back up %s: %v", input.Path, err))
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+
+	var err error
+	if ew, ok := t.fs.(encodingWriter); ok && input.Encoding != "" {
+		err = ew.WriteFileEncoding(input.Path, []byte(input.Content), input.Encoding)
+	} else {
+		err = t.fs.WriteFile(input.Path, []byte(input.Content))
+	}
 	if err != nil {
+		if undo != nil {
+			t.fs.(backuper).UndoJournal().Forget(undo.ID)
+		}
 		ReportStatus("❌", "exec", fmt.Sprintf("Failed to write %s: %v", input.Path, err))
 		return &ToolResult{Status: "error", Error: err}, err
 	}
 
 	ReportStatus("✅", "exec", fmt.Sprintf("Successfully wrote to %s", input.Path))
-	return &ToolResult{
+	result := &ToolResult{
 		Status:    "success",
 		Content:   "File written successfully",
 		Artifacts: []string{input.Path},
-	}, nil
+	}
+	if undo != nil {
+		result.Content += fmt.Sprintf(" (undo id %s)", undo.ID)
+		result.Meta = map[string]interface{}{"undo_id": undo.ID}
+		if undo.Backup != "" {
+			result.Artifacts = append(result.Artifacts, undo.Backup)
+			result.Meta["backup"] = undo.Backup
+		}
+	}
+	// Binary content is left out; a diff of it would only be noise.
+	if showDiff && bytes.IndexByte(before, 0) < 0 && strings.IndexByte(input.Content, 0) < 0 {
+		if diff := UnifiedDiff(input.Path, string(before), input.Content); diff != "" {
+			result.Content += "\n" + diff
+			ReportStatus("📝", "diff", diff)
+		}
+	}
+	return result, nil
+}
+
+// BulkWriteTool writes several files in one call. The writes succeed or
+// fail together: on the first failure, the files already written get their
+// previous content back and files it created are removed.
+type BulkWriteTool struct {
+	fs sys.FS
+}
+
+func NewBulkWriteTool(f sys.FS) *BulkWriteTool {
+	return &BulkWriteTool{fs: f}
+}
+
+func (t *BulkWriteTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_bulk_write",
+		Description: "Create or overwrite several files in one call. All files are written or none are: if one write fails, the others are rolled back.",
+		Source:      "system",
+		Category:    CategoryFileSystem,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer},
+		Complexity:  7,
+		Permissions: []Permission{PermWrite},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"files": {
+					"type": "array",
+					"description": "Files to write, in order",
+					"items": {
+						"type": "object",
+						"properties": {
+							"path": {"type": "string", "description": "Path to the file to write"},
+							"content": {"type": "string", "description": "Content to write to the file"}
+						},
+						"required": ["path", "content"]
+					}
+				}
+			},
+			"required": ["files"]
+		}`),
+		Examples: []string{
+			`{"tool": "sys_bulk_write", "parameters": {"files": [{"path": "cmd/main.go", "content": "package main\n..."}, {"path": "cmd/main_test.go", "content": "package main\n..."}]}}`,
+		},
+	}
+}
+
+// bulkWrite is one completed write, kept until the batch is done so it can
+// be rolled back.
+type bulkWrite struct {
+	path     string
+	original []byte // nil if the write created the file
+	undo     *sys.UndoEntry
+}
+
+func (t *BulkWriteTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Files []struct {
+			Path    string `json:"path"`
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	if len(input.Files) == 0 {
+		err := fmt.Errorf("no files to write")
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	seen := make(map[string]bool, len(input.Files))
+	for _, f := range input.Files {
+		if f.Path == "" {
+			err := fmt.Errorf("every file needs a path")
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+		if seen[f.Path] {
+			err := fmt.Errorf("%s is listed more than once", f.Path)
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+		seen[f.Path] = true
+	}
+
+	ReportStatus("💾", "exec", fmt.Sprintf("Writing %d files", len(input.Files)))
+
+	var done []bulkWrite
+	fail := func(path string, err error) (*ToolResult, error) {
+		err = fmt.Errorf("%s: %w", path, err)
+		ReportStatus("❌", "exec", fmt.Sprintf("Bulk write failed at %s; rolling back %d file(s)", path, len(done)))
+		if rbErr := rollbackWrites(t.fs, done); rbErr != nil {
+			err = fmt.Errorf("%w (rollback incomplete: %v)", err, rbErr)
+		}
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	for _, f := range input.Files {
+		if err := ctx.Err(); err != nil {
+			return fail(f.Path, err)
+		}
+		w := bulkWrite{path: f.Path}
+		original, err := t.fs.ReadFile(f.Path)
+		switch {
+		case err == nil:
+			w.original = original
+		case !errors.Is(err, fs.ErrNotExist):
+			return fail(f.Path, err)
+		}
+
+		// Write-ahead backup so sys_undo_write can revert this later.
+		if b, ok := t.fs.(backuper); ok {
+			if w.undo, err = b.Backup(f.Path); err != nil {
+				return fail(f.Path, err)
+			}
+		}
+		if err := t.fs.WriteFile(f.Path, []byte(f.Content)); err != nil {
+			if w.undo != nil {
+				t.fs.(backuper).UndoJournal().Forget(w.undo.ID)
+			}
+			return fail(f.Path, err)
+		}
+		done = append(done, w)
+	}
+
+	summary := fmt.Sprintf("Wrote %d files", len(done))
+	ReportStatus("✅", "exec", summary)
+	result := &ToolResult{Status: "success", Content: summary}
+	var undoIDs []string
+	for _, w := range done {
+		result.Artifacts = append(result.Artifacts, w.path)
+		if w.undo != nil {
+			undoIDs = append(undoIDs, w.undo.ID)
+		}
+	}
+	if len(undoIDs) > 0 {
+		result.Content += fmt.Sprintf(" (undo ids %s)", strings.Join(undoIDs, ", "))
+		result.Meta = map[string]interface{}{"undo_ids": undoIDs}
+	}
+	return result, nil
+}
+
+// rollbackWrites reverts completed writes, newest first. With an undo
+// journal the backup is copied back byte for byte; otherwise the content
+// read before the write is written again.
+func rollbackWrites(f sys.FS, done []bulkWrite) error {
+	var errs []error
+	for i := len(done) - 1; i >= 0; i-- {
+		w := done[i]
+		var err error
+		switch {
+		case w.undo != nil:
+			_, err = f.(backuper).UndoJournal().Restore(w.undo.ID)
+		case w.original == nil:
+			err = f.DeleteFile(w.path)
+		default:
+			err = f.WriteFile(w.path, w.original)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", w.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PatchFileTool edits part of a file from a unified diff or search/replace
+// hunks, instead of rewriting the whole file.
+type PatchFileTool struct {
+	fs sys.FS
+}
+
+func NewPatchFileTool(f sys.FS) *PatchFileTool {
+	return &PatchFileTool{fs: f}
+}
+
+func (t *PatchFileTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_patch_file",
+		Description: "Edit part of an existing file with a unified diff or a list of search/replace hunks. All hunks apply or none do; a hunk that does not match reports the file's surrounding lines.",
+		Source:      "system",
+		Category:    CategoryFileSystem,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer},
+		Complexity:  5,
+		Permissions: []Permission{PermWrite},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Path to the file to edit"},
+				"diff": {"type": "string", "description": "Unified diff for this one file (@@ -start,count +start,count @@ hunks)"},
+				"hunks": {
+					"type": "array",
+					"description": "Search/replace edits applied in order; use instead of diff",
+					"items": {
+						"type": "object",
+						"properties": {
+							"search": {"type": "string", "description": "Exact text to replace, with enough lines to be unique"},
+							"replace": {"type": "string", "description": "Replacement text"},
+							"line": {"type": "integer", "description": "Optional line the search text starts at, to choose between repeats"}
+						},
+						"required": ["search", "replace"]
+					}
+				}
+			},
+			"required": ["path"]
+		}`),
+	}
+}
+
+func (t *PatchFileTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Path  string          `json:"path"`
+		Diff  string          `json:"diff"`
+		Hunks []sys.PatchHunk `json:"hunks"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+
+	hunks := input.Hunks
+	switch {
+	case input.Diff != "" && len(hunks) > 0:
+		err := fmt.Errorf("pass either diff or hunks, not both")
+		return &ToolResult{Status: "error", Error: err}, err
+	case input.Diff != "":
+		var err error
+		if hunks, err = sys.ParseUnifiedDiff(input.Diff); err != nil {
+			err = fmt.Errorf("invalid diff: %w", err)
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	case len(hunks) == 0:
+		err := fmt.Errorf("nothing to apply: pass a diff or hunks")
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	ReportStatus("🩹", "exec", fmt.Sprintf("Patching file: %s", input.Path))
+
+	raw, err := t.fs.ReadFile(input.Path)
+	if err != nil {
+		ReportStatus("❌", "exec", fmt.Sprintf("Failed to read %s: %v", input.Path, err))
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	text, _ := sys.DecodeText(raw)
+	patched, stats, err := sys.ApplyPatch(text, hunks)
+	if err != nil {
+		ReportStatus("❌", "exec", fmt.Sprintf("Patch did not apply to %s", input.Path))
+		err = fmt.Errorf("%s: %w", input.Path, err)
+		result := &ToolResult{Status: "error", Error: err}
+		var herr *sys.HunkError
+		if errors.As(err, &herr) {
+			result.Data = herr
+		}
+		return result, err
+	}
+
+	// Write-ahead backup so sys_undo_write can revert this.
+	var undo *sys.UndoEntry
+	if b, ok := t.fs.(backuper); ok {
+		if undo, err = b.Backup(input.Path); err != nil {
+			ReportStatus("❌", "exec", fmt.Sprintf("Failed to back up %s: %v", input.Path, err))
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+	if err := t.fs.WriteFile(input.Path, []byte(patched)); err != nil {
+		if undo != nil {
+			t.fs.(backuper).UndoJournal().Forget(undo.ID)
+		}
+		ReportStatus("❌", "exec", fmt.Sprintf("Failed to write %s: %v", input.Path, err))
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	summary := fmt.Sprintf("Applied %d hunk(s) to %s: +%d -%d lines", stats.Hunks, input.Path, stats.Added, stats.Removed)
+	ReportStatus("✅", "exec", summary)
+	result := &ToolResult{
+		Status:    "success",
+		Content:   summary,
+		Data:      stats,
+		Artifacts: []string{input.Path},
+	}
+	if undo != nil {
+		result.Content += fmt.Sprintf(" (undo id %s)", undo.ID)
+		result.Meta = map[string]interface{}{"undo_id": undo.ID}
+		if undo.Backup != "" {
+			result.Artifacts = append(result.Artifacts, undo.Backup)
+			result.Meta["backup"] = undo.Backup
+		}
+	}
+	return result, nil
+}
+
+// UndoWriteTool lists and reverts earlier sys_write_file writes.
+type UndoWriteTool struct {
+	fs sys.FS
+}
+
+func NewUndoWriteTool(f sys.FS) *UndoWriteTool {
+	return &UndoWriteTool{fs: f}
+}
+
+func (t *UndoWriteTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_undo_write",
+		Description: "Revert a file written by sys_write_file or sys_patch_file to its previous contents. Without an id, lists the recent writes that can be undone.",
+		Source:      "system",
+		Category:    CategoryFileSystem,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer},
+		Complexity:  4,
+		Permissions: []Permission{PermWrite},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "description": "Undo id reported by sys_write_file or sys_patch_file; omit to list recent writes"}
+			}
+		}`),
+	}
+}
+
+func (t *UndoWriteTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		ID string `json:"id"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, err
+		}
+	}
+
+	b, ok := t.fs.(backuper)
+	if !ok || b.UndoJournal() == nil {
+		err := fmt.Errorf("undo is not available for this filesystem")
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	journal := b.UndoJournal()
+
+	if input.ID == "" {
+		entries, err := journal.List()
+		if err != nil {
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+		var sb strings.Builder
+		for _, e := range entries {
+			action := "overwrote"
+			if e.Backup == "" {
+				action = "created"
+			}
+			fmt.Fprintf(&sb, "%s  %s %s (%s)\n", e.ID, action, e.Path, e.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("No writes to undo.")
+		}
+		return &ToolResult{Status: "success", Content: sb.String(), Data: entries}, nil
+	}
+
+	ReportStatus("↩️", "exec", fmt.Sprintf("Undoing write %s", input.ID))
+	entry, err := journal.Restore(input.ID)
+	if err != nil {
+		ReportStatus("❌", "exec", fmt.Sprintf("Undo failed: %v", err))
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	msg := "Restored " + entry.Path
+	if entry.Backup == "" {
+		msg = "Removed " + entry.Path + ", which the write had created"
+	}
+	ReportStatus("✅", "exec", msg)
+	return &ToolResult{Status: "success", Content: msg, Artifacts: []string{entry.Path}}, nil
 }
 
 // ShellExecTool runs a shell command.
@@ -190,7 +629,7 @@ func NewSystemInfoTool(m *sys.Monitor) *SystemInfoTool {
 func (t *SystemInfoTool) Metadata() ToolMetadata {
 	return ToolMetadata{
 		Name:        "sys_info",
-		Description: "Get a snapshot of current system resource usage.",
+		Description: "Get a snapshot of current system resource usage: CPU, memory, disk, load average, battery and whether the network is up.",
 		Source:      "system",
 		Category:    CategorySystem,
 		Roles:       []AgentRole{RoleAll},
@@ -207,11 +646,160 @@ func (t *SystemInfoTool) Execute(ctx context.Context, args json.RawMessage) (*To
 	}
 	return &ToolResult{
 		Status:  "success",
-		Content: fmt.Sprintf("CPU: %.1f%%, RAM: %.1f%%, CWD: %s", snap.CPUUsage, snap.MemoryUsage, snap.WorkingDir),
+		Content: snapshotSummary(snap),
 		Data:    snap,
 	}, nil
 }
 
+// snapshotSummary is a snapshot on one line, as sys_info reports it.
+func snapshotSummary(snap sys.Snapshot) string {
+	parts := []string{fmt.Sprintf("CPU: %.1f%%, RAM: %.1f%%, CWD: %s", snap.CPUUsage, snap.MemoryUsage, snap.WorkingDir)}
+	if snap.Disk != nil {
+		parts = append(parts, "Disk: "+snap.Disk.String())
+	}
+	if snap.Load != nil {
+		parts = append(parts, "Load: "+snap.Load.String())
+	}
+	if snap.Battery != nil {
+		parts = append(parts, "Battery: "+snap.Battery.String())
+	}
+	if snap.Network != nil {
+		parts = append(parts, "Network: "+snap.Network.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StatefulSystemInfoTool is sys_info with a memory: it keeps the snapshot
+// from its previous call and reports how CPU, memory and goroutines moved
+// since then, so a leak shows up as a trend within one session.
+type StatefulSystemInfoTool struct {
+	*SystemInfoTool
+
+	mu   sync.Mutex
+	prev *sys.Snapshot
+}
+
+func NewStatefulSystemInfoTool(m *sys.Monitor) *StatefulSystemInfoTool {
+	return &StatefulSystemInfoTool{SystemInfoTool: NewSystemInfoTool(m)}
+}
+
+func (t *StatefulSystemInfoTool) Metadata() ToolMetadata {
+	meta := t.SystemInfoTool.Metadata()
+	meta.Description += " After the first call it also reports the change since the previous call (CPU, used memory, goroutines), to spot leaks and runaway processes."
+	return meta
+}
+
+// SystemInfoResult is the Data of a stateful sys_info call. Delta is nil
+// on the first call.
+type SystemInfoResult struct {
+	sys.Snapshot
+	Delta *sys.SnapshotDelta `json:"delta,omitempty"`
+}
+
+func (t *StatefulSystemInfoTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	snap, err := t.monitor.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	prev := t.prev
+	t.prev = &snap
+	t.mu.Unlock()
+
+	result := SystemInfoResult{Snapshot: snap}
+	content := snapshotSummary(snap)
+	if prev != nil {
+		d := snap.Since(*prev)
+		result.Delta = &d
+		content += fmt.Sprintf("\nSince the previous sys_info call %s ago: %s", d.Elapsed.Round(time.Second), d)
+		ReportStatus("📈", "sys_delta", d.String())
+	}
+	return &ToolResult{
+		Status:  "success",
+		Content: content,
+		Data:    result,
+	}, nil
+}
+
+// EnvTool exposes a filtered, redacted view of the environment.
+type EnvTool struct{}
+
+func (t *EnvTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_env",
+		Description: "Inspect the environment (shell, toolchains, proxy, locale). Secrets are masked. Can query a single variable or check whether binaries exist on PATH.",
+		Source:      "system",
+		Category:    CategorySystem,
+		Roles:       []AgentRole{RoleAll},
+		Complexity:  1,
+		Permissions: []Permission{PermRead},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"var": {"type": "string", "description": "Return only this variable"},
+				"probe": {"type": "array", "items": {"type": "string"}, "description": "Binaries to look up on PATH, e.g. [\"go\", \"git\"]"}
+			}
+		}`),
+	}
+}
+
+func (t *EnvTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Var   string   `json:"var"`
+		Probe []string `json:"probe"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Var != "" {
+		entry, ok := sys.LookupEnv(input.Var)
+		if !ok {
+			return &ToolResult{
+				Status:  "success",
+				Content: fmt.Sprintf("%s is not set", input.Var),
+				Data:    entry,
+			}, nil
+		}
+		return &ToolResult{
+			Status:  "success",
+			Content: fmt.Sprintf("%s=%s", entry.Name, entry.Value),
+			Data:    entry,
+		}, nil
+	}
+
+	ReportStatus("🌱", "exec", "Inspecting environment")
+
+	report := sys.InspectEnv()
+	probes := input.Probe
+	if len(probes) == 0 {
+		probes = sys.DefaultProbes
+	}
+	for _, name := range probes {
+		report.Binaries = append(report.Binaries, sys.ProbeBinary(ctx, name))
+	}
+
+	var sb strings.Builder
+	for _, e := range report.Entries {
+		fmt.Fprintf(&sb, "[%s] %s=%s\n", e.Category, e.Name, sys.TruncateValue(e.Value, 120))
+	}
+	for _, b := range report.Binaries {
+		if b.Found {
+			fmt.Fprintf(&sb, "[bin] %s -> %s (%s)\n", b.Name, b.Path, b.Version)
+		} else {
+			fmt.Fprintf(&sb, "[bin] %s not found\n", b.Name)
+		}
+	}
+
+	return &ToolResult{
+		Status:  "success",
+		Content: sb.String(),
+		Data:    report,
+	}, nil
+}
+
 // ListFilesTool lists files in a directory.
 type ListFilesTool struct {
 	fs sys.FS
@@ -261,6 +849,434 @@ func (t *ListFilesTool) Execute(ctx context.Context, args json.RawMessage) (*Too
 	}, nil
 }
 
+// GrepTool searches file contents with ripgrep when it is installed, or a
+// directory walk otherwise.
+type GrepTool struct {
+	fs sys.FS
+}
+
+func NewGrepTool(f sys.FS) *GrepTool {
+	return &GrepTool{fs: f}
+}
+
+// GrepMatch is one matching line, with the lines around it when context
+// was asked for.
+type GrepMatch struct {
+	File   string   `json:"file"`
+	Line   int      `json:"line"`
+	Match  string   `json:"match"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// size estimates the bytes m takes once rendered.
+func (m GrepMatch) size() int {
+	n := len(m.File) + len(m.Match) + 16
+	for _, l := range m.Before {
+		n += len(m.File) + len(l) + 16
+	}
+	for _, l := range m.After {
+		n += len(m.File) + len(l) + 16
+	}
+	return n
+}
+
+// grepOptions bound a directory walk search.
+type grepOptions struct {
+	include  string // Glob on file names
+	limit    int    // Most matches
+	context  int    // Lines kept before and after each match
+	maxBytes int    // Stop once the rendered matches pass this, if set
+
+	// allow refuses a file the path policy does not cover, if set. The walk
+	// does not descend into linked directories, so only symlinked files
+	// are checked.
+	allow func(path string) error
+}
+
+const (
+	defaultGrepResults = 100
+	maxGrepResults     = 1000
+	maxGrepLine        = 300 // Longer matching lines are cut
+)
+
+func (t *GrepTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_grep",
+		Description: "Search file contents for a regular expression under a directory, returning file, line number and matching text. Use this instead of reading files one by one.",
+		Source:      "system",
+		Category:    CategoryAnalysis,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer, RoleResearcher},
+		Complexity:  3,
+		Permissions: []Permission{PermRead},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"pattern": {"type": "string", "description": "Regular expression to search for"},
+				"path": {"type": "string", "description": "Directory (or file) to search; defaults to the working directory"},
+				"include": {"type": "string", "description": "Only search files whose name matches this glob, e.g. *.go"},
+				"max_results": {"type": "integer", "description": "Most matches to return (default 100, at most 1000)"}
+			},
+			"required": ["pattern"]
+		}`),
+	}
+}
+
+func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Pattern    string `json:"pattern"`
+		Path       string `json:"path"`
+		Include    string `json:"include"`
+		MaxResults int    `json:"max_results"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(input.Pattern)
+	if err != nil {
+		err = fmt.Errorf("invalid pattern: %w", err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if input.Include != "" {
+		if _, err := filepath.Match(input.Include, ""); err != nil {
+			err = fmt.Errorf("invalid include glob %q: %w", input.Include, err)
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+	if input.Path == "" {
+		input.Path = "."
+	}
+	limit := input.MaxResults
+	if limit <= 0 {
+		limit = defaultGrepResults
+	}
+	limit = min(limit, maxGrepResults)
+
+	base := ""
+	if b, ok := t.fs.(interface{ BaseDir() string }); ok {
+		base = b.BaseDir()
+	}
+	if _, err := checkedPath(t.fs, input.Path); err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	ReportStatus("🔎", "exec", fmt.Sprintf("Searching %s for /%s/", input.Path, input.Pattern))
+
+	engine := "ripgrep"
+	var matches []GrepMatch
+	var truncated bool
+	if rg, lookErr := exec.LookPath("rg"); lookErr == nil {
+		matches, truncated, err = grepRipgrep(ctx, rg, base, input.Pattern, input.Path, input.Include, limit)
+	} else {
+		engine = "walk"
+		matches, truncated, err = grepWalk(ctx, re, base, input.Path, grepOptions{include: input.Include, limit: limit, allow: policyCheck(t.fs)})
+	}
+	if err != nil {
+		ReportStatus("❌", "exec", fmt.Sprintf("Search failed: %v", err))
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	files := make(map[string]bool)
+	var sb strings.Builder
+	for _, m := range matches {
+		files[m.File] = true
+	}
+	fmt.Fprintf(&sb, "Found %d match(es) in %d file(s) for /%s/", len(matches), len(files), input.Pattern)
+	if truncated {
+		fmt.Fprintf(&sb, " (stopped at %d; narrow the pattern, path or include to see more)", limit)
+	}
+	sb.WriteString("\n")
+	for _, m := range matches {
+		fmt.Fprintf(&sb, "%s:%d: %s\n", m.File, m.Line, m.Match)
+	}
+
+	ReportStatus("✅", "exec", fmt.Sprintf("Found %d matches in %d files", len(matches), len(files)))
+	return &ToolResult{
+		Status:  "success",
+		Content: sb.String(),
+		Data:    matches,
+		Meta:    map[string]interface{}{"engine": engine, "truncated": truncated},
+	}, nil
+}
+
+// grepRipgrep runs rg from base and reads its JSON output, stopping once
+// limit matches are in.
+func grepRipgrep(ctx context.Context, rg, base, pattern, root, include string, limit int) ([]GrepMatch, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	args := []string{"--json", "--no-messages"}
+	if include != "" {
+		args = append(args, "--glob", include)
+	}
+	args = append(args, "-e", pattern, "--", root)
+	cmd := exec.CommandContext(ctx, rg, args...)
+	cmd.Dir = base
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, false, err
+	}
+
+	var matches []GrepMatch
+	truncated := false
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var event struct {
+			Type string `json:"type"`
+			Data struct {
+				Path       struct{ Text string } `json:"path"`
+				Lines      struct{ Text string } `json:"lines"`
+				LineNumber int                   `json:"line_number"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil || event.Type != "match" {
+			continue
+		}
+		if len(matches) == limit {
+			truncated = true
+			cancel()
+			break
+		}
+		matches = append(matches, GrepMatch{
+			File:  event.Data.Path.Text,
+			Line:  event.Data.LineNumber,
+			Match: grepLine(event.Data.Lines.Text),
+		})
+	}
+	err = cmd.Wait()
+
+	// rg exits 1 when nothing matched; being stopped early is expected.
+	var exitErr *exec.ExitError
+	if truncated || (errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		err = nil
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("rg: %s", msg)
+		}
+		return nil, false, err
+	}
+	return matches, truncated, nil
+}
+
+// grepWalk scans files under root for re. Hidden directories, vendor and
+// node_modules are skipped, as are files that look binary.
+func grepWalk(ctx context.Context, re *regexp.Regexp, base, root string, opts grepOptions) ([]GrepMatch, bool, error) {
+	start := root
+	if !filepath.IsAbs(start) {
+		start = filepath.Join(base, start)
+	}
+
+	var matches []GrepMatch
+	truncated := false
+	size := 0
+	errLimit := errors.New("limit reached")
+	err := filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == start {
+				return err
+			}
+			return nil // Unreadable entries are skipped, like rg does
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			if path != start && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if opts.include != "" {
+			if ok, _ := filepath.Match(opts.include, d.Name()); !ok {
+				return nil
+			}
+		}
+
+		if opts.allow != nil && d.Type()&fs.ModeSymlink != 0 && opts.allow(path) != nil {
+			return nil // Links out of the roots are skipped, not followed
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+			return nil
+		}
+		name := path
+		if rel, err := filepath.Rel(base, path); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		lines := strings.Split(string(data), "\n")
+		for i, line := range lines {
+			if !re.MatchString(line) {
+				continue
+			}
+			if len(matches) == opts.limit {
+				truncated = true
+				return errLimit
+			}
+			m := GrepMatch{File: name, Line: i + 1, Match: grepLine(line)}
+			if opts.context > 0 {
+				for _, l := range lines[max(0, i-opts.context):i] {
+					m.Before = append(m.Before, grepLine(l))
+				}
+				for _, l := range lines[i+1 : min(len(lines), i+1+opts.context)] {
+					m.After = append(m.After, grepLine(l))
+				}
+			}
+			if opts.maxBytes > 0 && size+m.size() > opts.maxBytes {
+				truncated = true
+				return errLimit
+			}
+			size += m.size()
+			matches = append(matches, m)
+		}
+		return nil
+	})
+	if err != nil && err != errLimit {
+		return nil, false, err
+	}
+	return matches, truncated, nil
+}
+
+// grepLine trims a matching line for display.
+func grepLine(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) > maxGrepLine {
+		line = line[:maxGrepLine] + "…"
+	}
+	return line
+}
+
+// SearchFilesTool finds a pattern under the working directory and returns
+// each match with the lines around it.
+type SearchFilesTool struct {
+	fs sys.FS
+}
+
+func NewSearchFilesTool(f sys.FS) *SearchFilesTool {
+	return &SearchFilesTool{fs: f}
+}
+
+const (
+	defaultSearchResults = 50
+	maxSearchResults     = 200
+	maxSearchBytes       = 2 << 20
+	searchContextLines   = 2
+)
+
+func (t *SearchFilesTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "sys_search_files",
+		Description: "Find where a symbol or text appears in the project. Returns each match with its file, line number and two lines of context either side. Skips .git, node_modules and vendor.",
+		Source:      "system",
+		Category:    CategoryAnalysis,
+		Roles:       []AgentRole{RoleCoder, RoleEngineer, RoleResearcher},
+		Complexity:  3,
+		Permissions: []Permission{PermRead},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"pattern": {"type": "string", "description": "Text or regular expression to find"},
+				"literal": {"type": "boolean", "description": "Match pattern as plain text instead of a regular expression"},
+				"glob": {"type": "string", "description": "Only search files whose name matches this glob, e.g. *.go"},
+				"max_results": {"type": "integer", "description": "Most matches to return (default 50, at most 200)"}
+			},
+			"required": ["pattern"]
+		}`),
+	}
+}
+
+func (t *SearchFilesTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input struct {
+		Pattern    string `json:"pattern"`
+		Literal    bool   `json:"literal"`
+		Glob       string `json:"glob"`
+		MaxResults int    `json:"max_results"`
+	}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	if input.Pattern == "" {
+		err := errors.New("pattern is required")
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	expr := input.Pattern
+	if input.Literal {
+		expr = regexp.QuoteMeta(expr)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		err = fmt.Errorf("invalid pattern (set literal to search plain text): %w", err)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if input.Glob != "" {
+		if _, err := filepath.Match(input.Glob, ""); err != nil {
+			err = fmt.Errorf("invalid glob %q: %w", input.Glob, err)
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+	limit := input.MaxResults
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	limit = min(limit, maxSearchResults)
+
+	base := ""
+	if b, ok := t.fs.(interface{ BaseDir() string }); ok {
+		base = b.BaseDir()
+	}
+
+	ReportStatus("🔎", "exec", fmt.Sprintf("Searching for %q", input.Pattern))
+	matches, truncated, err := grepWalk(ctx, re, base, ".", grepOptions{
+		include:  input.Glob,
+		limit:    limit,
+		context:  searchContextLines,
+		maxBytes: maxSearchBytes,
+		allow:    policyCheck(t.fs),
+	})
+	if err != nil {
+		ReportStatus("❌", "exec", fmt.Sprintf("Search failed: %v", err))
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	files := make(map[string]bool)
+	for _, m := range matches {
+		files[m.File] = true
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Found %d match(es) in %d file(s) for %q", len(matches), len(files), input.Pattern)
+	if truncated {
+		sb.WriteString(" (stopped early; narrow the pattern or glob to see more)")
+	}
+	sb.WriteString("\n")
+	for i, m := range matches {
+		if i > 0 {
+			sb.WriteString("--\n")
+		}
+		for j, l := range m.Before {
+			fmt.Fprintf(&sb, "%s-%d- %s\n", m.File, m.Line-len(m.Before)+j, l)
+		}
+		fmt.Fprintf(&sb, "%s:%d: %s\n", m.File, m.Line, m.Match)
+		for j, l := range m.After {
+			fmt.Fprintf(&sb, "%s-%d- %s\n", m.File, m.Line+1+j, l)
+		}
+	}
+
+	ReportStatus("✅", "exec", fmt.Sprintf("Found %d matches in %d files", len(matches), len(files)))
+	return &ToolResult{
+		Status:  "success",
+		Content: sb.String(),
+		Data:    matches,
+		Meta:    map[string]interface{}{"truncated": truncated},
+	}, nil
+}
+
 // FetchURLTool fetches content from a URL.
 type FetchURLTool struct{}
 
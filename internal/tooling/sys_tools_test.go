@@ -0,0 +1,191 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/vibeauracle/sys"
+)
+
+func TestGrepWalk(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n\nfunc main() {\n\tserve()\n}\n",
+		"server/serve.go":      "package server\n\nfunc serve() {}\n",
+		"server/README.md":     "call serve() to start\n",
+		".git/config":          "serve = true\n",
+		"node_modules/x.go":    "func serve() {}\n",
+		"assets/logo.bin":      "serve\x00\x01",
+		"server/serve_test.go": "func TestServe() { serve() }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	re := regexp.MustCompile(`serve\(\)`)
+	matches, truncated, err := grepWalk(context.Background(), re, dir, ".", grepOptions{include: "*.go", limit: 10})
+	if err != nil || truncated {
+		t.Fatalf("grepWalk: %v (truncated %v)", err, truncated)
+	}
+	want := map[string]int{"main.go": 4, filepath.Join("server", "serve.go"): 3, filepath.Join("server", "serve_test.go"): 1}
+	if len(matches) != len(want) {
+		t.Fatalf("expected %d matches, got %+v", len(want), matches)
+	}
+	for _, m := range matches {
+		if want[m.File] != m.Line {
+			t.Errorf("unexpected match %+v", m)
+		}
+	}
+
+	matches, truncated, _ = grepWalk(context.Background(), re, dir, "server", grepOptions{limit: 1})
+	if len(matches) != 1 || !truncated {
+		t.Errorf("expected one match and truncation, got %+v (%v)", matches, truncated)
+	}
+}
+
+func TestGrepWalk_ContextAndByteCap(t *testing.T) {
+	dir := t.TempDir()
+	content := "one\ntwo\nthree\nfour()\nfive\nsix\n"
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644)
+	os.MkdirAll(filepath.Join(dir, "vendor"), 0755)
+	os.WriteFile(filepath.Join(dir, "vendor", "b.txt"), []byte(content), 0644)
+
+	re := regexp.MustCompile(regexp.QuoteMeta("four()"))
+	matches, truncated, err := grepWalk(context.Background(), re, dir, ".", grepOptions{limit: 10, context: 2})
+	if err != nil || truncated {
+		t.Fatalf("grepWalk: %v (truncated %v)", err, truncated)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected vendor to be skipped, got %+v", matches)
+	}
+	m := matches[0]
+	if m.Line != 4 || strings.Join(m.Before, ",") != "two,three" || strings.Join(m.After, ",") != "five,six" {
+		t.Errorf("unexpected context %+v", m)
+	}
+
+	matches, truncated, _ = grepWalk(context.Background(), re, dir, ".", grepOptions{limit: 10, context: 2, maxBytes: 10})
+	if len(matches) != 0 || !truncated {
+		t.Errorf("expected the byte cap to stop the walk, got %+v (%v)", matches, truncated)
+	}
+}
+
+func TestBulkWriteTool(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("original\n"), 0644)
+	tool := NewBulkWriteTool(sys.NewLocalFS(dir))
+	write := func(files ...[2]string) (*ToolResult, error) {
+		var list []map[string]string
+		for _, f := range files {
+			list = append(list, map[string]string{"path": f[0], "content": f[1]})
+		}
+		args, _ := json.Marshal(map[string]interface{}{"files": list})
+		return tool.Execute(context.Background(), args)
+	}
+
+	// keep.txt is a file, so nothing can be written beneath it; the writes
+	// before that one are undone.
+	_, err := write([2]string{"keep.txt", "changed\n"}, [2]string{"new/a.txt", "a\n"}, [2]string{"keep.txt/b.txt", "b\n"})
+	if err == nil || !strings.Contains(err.Error(), "keep.txt/b.txt") {
+		t.Fatalf("expected the third write to fail, got %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "keep.txt")); string(got) != "original\n" {
+		t.Errorf("expected keep.txt to be restored, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new", "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the created file to be removed, got %v", err)
+	}
+
+	res, err := write([2]string{"keep.txt", "changed\n"}, [2]string{"new/a.txt", "a\n"})
+	if err != nil {
+		t.Fatalf("bulk write failed: %v", err)
+	}
+	if strings.Join(res.Artifacts, ",") != "keep.txt,new/a.txt" {
+		t.Errorf("expected both paths as artifacts, got %v", res.Artifacts)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "new", "a.txt")); string(got) != "a\n" {
+		t.Errorf("expected new/a.txt to be written, got %q", got)
+	}
+
+	if _, err := write([2]string{"x.txt", "1"}, [2]string{"x.txt", "2"}); err == nil {
+		t.Error("expected a path listed twice to be refused")
+	}
+
+	// With an undo journal, rollback restores from the backups and leaves
+	// no entries behind.
+	lfs := sys.NewLocalFS(dir)
+	journal := sys.NewUndoJournal(t.TempDir(), dir, sys.StoragePolicy{})
+	lfs.SetUndoJournal(journal)
+	tool = NewBulkWriteTool(lfs)
+	if _, err := write([2]string{"keep.txt", "again\n"}, [2]string{"keep.txt/c.txt", "c\n"}); err == nil {
+		t.Fatal("expected the write beneath a file to fail")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "keep.txt")); string(got) != "changed\n" {
+		t.Errorf("expected keep.txt to be restored from its backup, got %q", got)
+	}
+	if entries, _ := journal.List(); len(entries) != 0 {
+		t.Errorf("expected no undo entries after rollback, got %+v", entries)
+	}
+}
+
+func TestStatefulSystemInfoTool_ReportsDelta(t *testing.T) {
+	var reported []string
+	StatusReporter = func(icon, step, msg string) {
+		if step == "sys_delta" {
+			reported = append(reported, msg)
+		}
+	}
+	defer func() { StatusReporter = nil }()
+
+	tool := NewStatefulSystemInfoTool(sys.NewMonitor())
+	first, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := first.Data.(SystemInfoResult); r.Delta != nil || strings.Contains(first.Content, "Since the previous") {
+		t.Errorf("expected no delta on the first call, got %+v", first.Content)
+	}
+
+	second, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := second.Data.(SystemInfoResult)
+	if r.Delta == nil || !strings.Contains(second.Content, "Since the previous sys_info call") || !strings.Contains(second.Content, "goroutines ") {
+		t.Fatalf("expected the change since the first call, got %q", second.Content)
+	}
+	if len(reported) != 1 || reported[0] != r.Delta.String() {
+		t.Errorf("expected the delta reported once to the UI, got %q", reported)
+	}
+}
+
+func TestReadFileTool_FormatNoteOutsideContent(t *testing.T) {
+	dir := t.TempDir()
+	data := "\xef\xbb\xbfline one\r\nline two\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "win.txt"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewReadFileTool(sys.NewLocalFS(dir))
+	res, err := tool.Execute(context.Background(), json.RawMessage(`{"path": "win.txt"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Content != data {
+		t.Errorf("expected the exact file data, got %q", res.Content)
+	}
+	note, _ := res.Meta[MetaNote].(string)
+	if !strings.Contains(note, "CRLF") || !strings.Contains(note, "BOM") {
+		t.Errorf("expected the line endings and BOM in the note, got %q", note)
+	}
+	if got := res.ModelText(); !strings.HasPrefix(got, data) || !strings.Contains(got, note) {
+		t.Errorf("expected the model to see the content then the note, got %q", got)
+	}
+}
@@ -55,6 +55,9 @@ type ToolMetadata struct {
 	Category   ToolCategory `json:"category"`
 	Roles      []AgentRole  `json:"roles"`      // Which agent personas should see this?
 	Complexity int          `json:"complexity"` // 1-10 estimation of cognitive load
+
+	// Examples are complete tool calls shown to the model with the schema.
+	Examples []string `json:"examples,omitempty"`
 }
 
 // ToolResult is a structured response enabling agentic reflection.
@@ -67,6 +70,20 @@ type ToolResult struct {
 	Meta      map[string]interface{} `json:"meta,omitempty"`      // Extra context (latency, confidence)
 }
 
+// MetaNote is the Meta key for a remark to the model about a result, such
+// as a file's line endings, kept out of Content so Content stays exact.
+const MetaNote = "note"
+
+// ModelText is the result as the model sees it: Content, then the note in
+// Meta, if any.
+func (r *ToolResult) ModelText() string {
+	note, _ := r.Meta[MetaNote].(string)
+	if note == "" {
+		return r.Content
+	}
+	return r.Content + "\n\n[" + note + "]"
+}
+
 // Permission represents a capability required by a tool.
 type Permission string
 
@@ -76,6 +93,7 @@ const (
 	PermExecute   Permission = "execute"
 	PermNetwork   Permission = "network"
 	PermSensitive Permission = "sensitive" // Access to passwords, keys, etc.
+	PermElevated  Permission = "elevated"  // Files outside the allowed roots; always asks
 )
 
 // ToolProvider is an interface for sources that provide a set of tools.
@@ -120,6 +138,30 @@ func (r *Registry) Sync(ctx context.Context) error {
 	return nil
 }
 
+// SyncProvider registers p and loads its tools alongside the existing ones,
+// without re-querying other providers. Tools whose names are already taken
+// by another source are skipped and returned.
+func (r *Registry) SyncProvider(ctx context.Context, p ToolProvider) (loaded int, skipped []string, err error) {
+	tools, err := p.Provide(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("provider %s failed: %w", p.Name(), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers = append(r.providers, p)
+	for _, t := range tools {
+		name := t.Metadata().Name
+		if existing, ok := r.tools[name]; ok && existing.Metadata().Source != t.Metadata().Source {
+			skipped = append(skipped, name)
+			continue
+		}
+		r.tools[name] = t
+		loaded++
+	}
+	return loaded, skipped, nil
+}
+
 func (r *Registry) Register(t Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -167,11 +209,25 @@ func DefaultRegistry(f sys.FS, m *sys.Monitor, guard *SecurityGuard) *Registry {
 	tools := []Tool{
 		NewReadFileTool(f),
 		NewWriteFileTool(f),
+		NewBulkWriteTool(f),
+		NewPatchFileTool(f),
+		NewPatchTool(f),
 		NewListFilesTool(f),
+		NewRequestAccessTool(f),
+		NewGrepTool(f),
+		NewSearchFilesTool(f),
 		NewTraversalTool(f),
 		&ShellExecTool{},
-		NewSystemInfoTool(m),
+		&ProcessTool{},
+		&DockerTool{},
+		NewStatefulSystemInfoTool(m),
+		&EnvTool{},
 		&FetchURLTool{},
+		&RestTool{},
+		&WebSocketTool{},
+		&GitStatusTool{},
+		&GitDiffTool{},
+		&GitCommitTool{},
 	}
 
 	for _, t := range tools {
@@ -241,6 +297,13 @@ func (r *Registry) GetPromptDefinitions(subset []string) string {
 			sb += fmt.Sprintf("Parameters (JSON Schema): %s\n", string(m.Parameters))
 		}
 
+		if len(m.Examples) > 0 {
+			sb += "Examples:\n"
+			for _, ex := range m.Examples {
+				sb += "  " + ex + "\n"
+			}
+		}
+
 		// Permission Warning
 		if len(m.Permissions) > 0 {
 			sb += fmt.Sprintf("Required Permissions: %v\n", m.Permissions)
@@ -256,8 +319,16 @@ func CoreTools() []string {
 	return []string{
 		"sys_read_file",
 		"sys_write_file",
-		"sys_shell_exec", // Engineers need this
-		"sys_tool_wand",  // The Handshake
-		"sys_info",       // Situational awareness
+		"sys_patch_file",     // Targeted edits
+		"sys_bulk_write",     // Several files in one call
+		"sys_patch",          // Multi-file diffs
+		"sys_grep",           // Find code by content
+		"sys_search_files",   // Find usages, with context
+		"sys_shell_exec",     // Engineers need this
+		"sys_tool_wand",      // The Handshake
+		"sys_info",           // Situational awareness
+		"sys_env",            // Know the shell before suggesting commands
+		"sys_git",            // Inspect and record changes
+		"sys_request_access", // Files outside the working directory
 	}
 }
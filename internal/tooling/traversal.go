@@ -46,13 +46,17 @@ func (t *TraversalTool) Execute(ctx context.Context, args json.RawMessage) (*Too
 		return nil, err
 	}
 
-	root, _ := os.Getwd()
-	if input.Path != "" {
-		root = filepath.Join(root, input.Path)
+	root, err := checkedPath(t.fs, input.Path)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	if !filepath.IsAbs(root) {
+		cwd, _ := os.Getwd()
+		root = filepath.Join(cwd, root)
 	}
 
 	var results []string
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,145 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// Limits for ws_request.
+const (
+	defaultWebSocketTimeout = 10 * time.Second
+	maxWebSocketTimeout     = 60 * time.Second
+	maxWebSocketFrame       = 1 << 20  // Larger frames fail the read
+	maxWebSocketOutput      = 64 << 10 // Bytes of the frame returned
+)
+
+// WebSocketTool opens a WebSocket, optionally sends one message and
+// returns the first frame the server sends back, for real-time APIs that
+// http_fetch cannot reach.
+type WebSocketTool struct{}
+
+type webSocketInput struct {
+	URL     string          `json:"url"`
+	Message json.RawMessage `json:"message"`
+	Timeout float64         `json:"timeout"`
+}
+
+func (t *WebSocketTool) Metadata() ToolMetadata {
+	return ToolMetadata{
+		Name:        "ws_request",
+		Description: "Connect to a WebSocket (ws:// or wss://), send one JSON message and return the first frame the server answers with. Leave out message for feeds that push data as soon as you connect.",
+		Source:      "system",
+		Category:    CategoryNetwork,
+		Roles:       []AgentRole{RoleEngineer, RoleArchitect},
+		Complexity:  5,
+		Permissions: []Permission{PermNetwork},
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"url": {"type": "string", "description": "ws:// or wss:// URL to connect to"},
+				"message": {"description": "JSON value to send once connected; a string is sent as is"},
+				"timeout": {"type": "number", "description": "Seconds to wait for the first frame (default 10, at most 60)"}
+			},
+			"required": ["url"]
+		}`),
+		Examples: []string{
+			`{"tool": "ws_request", "parameters": {"url": "wss://stream.binance.com:9443/ws/btcusdt@trade"}}`,
+			`{"tool": "ws_request", "parameters": {"url": "ws://localhost:8080/rpc", "message": {"id": 1, "method": "status"}, "timeout": 5}}`,
+		},
+	}
+}
+
+func (t *WebSocketTool) Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error) {
+	var input webSocketInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(input.URL); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		err := fmt.Errorf("ws_request needs a ws:// or wss:// url, got %q", input.URL)
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	msg, err := webSocketMessage(input.Message)
+	if err != nil {
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	timeout := defaultWebSocketTimeout
+	if input.Timeout > 0 {
+		timeout = time.Duration(input.Timeout * float64(time.Second))
+	}
+	if timeout > maxWebSocketTimeout {
+		timeout = maxWebSocketTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ReportStatus("🔌", "exec", "Connecting to "+input.URL)
+	conn, _, err := websocket.Dial(ctx, input.URL, nil)
+	if err != nil {
+		err = fmt.Errorf("connecting to %s: %w", input.URL, err)
+		ReportStatus("❌", "exec", err.Error())
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	conn.SetReadLimit(maxWebSocketFrame)
+
+	if msg != nil {
+		if err := conn.Write(ctx, websocket.MessageText, msg); err != nil {
+			err = fmt.Errorf("sending to %s: %w", input.URL, err)
+			return &ToolResult{Status: "error", Error: err}, err
+		}
+	}
+
+	typ, data, err := conn.Read(ctx)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("no frame from %s within %s", input.URL, timeout)
+		} else {
+			err = fmt.Errorf("reading from %s: %w", input.URL, err)
+		}
+		ReportStatus("❌", "exec", err.Error())
+		return &ToolResult{Status: "error", Error: err}, err
+	}
+
+	ReportStatus("✅", "exec", fmt.Sprintf("Received %d bytes", len(data)))
+	meta := map[string]interface{}{"bytes": len(data)}
+	if typ == websocket.MessageBinary {
+		meta["binary"] = true
+		return &ToolResult{
+			Status:  "success",
+			Content: fmt.Sprintf("(binary frame, %d bytes)", len(data)),
+			Meta:    meta,
+		}, nil
+	}
+	content := string(data)
+	if len(content) > maxWebSocketOutput {
+		content = content[:maxWebSocketOutput]
+		meta["truncated"] = true
+	}
+	return &ToolResult{Status: "success", Content: content, Meta: meta}, nil
+}
+
+// webSocketMessage is the frame to send for message: a JSON string is sent
+// as its text, anything else as JSON. A missing or null message sends
+// nothing.
+func webSocketMessage(raw json.RawMessage) ([]byte, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []byte(s), nil
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("ws_request message is not valid JSON")
+	}
+	return []byte(trimmed), nil
+}
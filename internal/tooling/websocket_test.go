@@ -0,0 +1,87 @@
+package tooling
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nhooyr.io/websocket"
+)
+
+// wsServer serves handle on a WebSocket and returns its ws:// URL.
+func wsServer(t *testing.T, handle func(ctx context.Context, c *websocket.Conn)) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.CloseNow()
+		handle(r.Context(), c)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func runWebSocket(t *testing.T, args string) (*ToolResult, error) {
+	t.Helper()
+	return (&WebSocketTool{}).Execute(context.Background(), json.RawMessage(args))
+}
+
+func TestWebSocketTool_SendsAndReadsFirstFrame(t *testing.T) {
+	url := wsServer(t, func(ctx context.Context, c *websocket.Conn) {
+		_, data, err := c.Read(ctx)
+		if err != nil {
+			return
+		}
+		c.Write(ctx, websocket.MessageText, append([]byte("echo:"), data...))
+		c.Write(ctx, websocket.MessageText, []byte("second"))
+	})
+
+	res, err := runWebSocket(t, `{"url": "`+url+`", "message": {"op": "ping"}}`)
+	if err != nil {
+		t.Fatalf("ws_request failed: %v", err)
+	}
+	if res.Content != `echo:{"op": "ping"}` {
+		t.Errorf("expected the first frame only, got %q", res.Content)
+	}
+
+	res, err = runWebSocket(t, `{"url": "`+url+`", "message": "hello"}`)
+	if err != nil || res.Content != "echo:hello" {
+		t.Errorf("expected a string message sent as text, got %q (%v)", res.Content, err)
+	}
+}
+
+func TestWebSocketTool_ReadsPushedFrameWithoutMessage(t *testing.T) {
+	url := wsServer(t, func(ctx context.Context, c *websocket.Conn) {
+		c.Write(ctx, websocket.MessageText, []byte(`{"price": "1.00"}`))
+		c.Read(ctx)
+	})
+
+	res, err := runWebSocket(t, `{"url": "`+url+`"}`)
+	if err != nil || res.Content != `{"price": "1.00"}` {
+		t.Errorf("expected the pushed frame, got %q (%v)", res.Content, err)
+	}
+}
+
+func TestWebSocketTool_TimesOut(t *testing.T) {
+	url := wsServer(t, func(ctx context.Context, c *websocket.Conn) {
+		c.Read(ctx)
+	})
+
+	res, err := runWebSocket(t, `{"url": "`+url+`", "timeout": 0.1}`)
+	if err == nil || res.Status != "error" || !strings.Contains(err.Error(), "no frame") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestWebSocketTool_RejectsNonWebSocketURL(t *testing.T) {
+	for _, u := range []string{"http://example.com", "ws://", "example.com"} {
+		if _, err := runWebSocket(t, `{"url": "`+u+`"}`); err == nil {
+			t.Errorf("%s: expected an error", u)
+		}
+	}
+}
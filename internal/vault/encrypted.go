@@ -0,0 +1,315 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// ErrDecrypt is returned when the vault file cannot be opened with the
+// derived key: a wrong passphrase, a different machine, or a damaged file.
+var ErrDecrypt = errors.New("vault: cannot decrypt (wrong passphrase, different machine, or corrupted file)")
+
+// ErrNotFound is returned by Get for a key in neither the keyring nor the
+// encrypted file.
+var ErrNotFound = errors.New("secret not found in vault or fallback")
+
+// ErrLocked is returned by a passphrase func that will not ask, for a
+// vault that was not unlocked in time.
+var ErrLocked = errors.New("vault: locked; set " + PassphraseEnv + " or unlock it before starting")
+
+// PassphraseEnv, if set, supplies the passphrase for a passphrase-protected
+// vault without prompting.
+const PassphraseEnv = "VIBEAURA_VAULT_PASSPHRASE"
+
+// Key derivation sources recorded in the vault file.
+const (
+	kdfMachine    = "machine"
+	kdfPassphrase = "passphrase"
+)
+
+// encryptedFile is the on-disk vault.json: the secrets map as JSON, sealed
+// with AES-256-GCM under a scrypt-derived key.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileStore is the encrypted fallback used when no OS keyring is available.
+type fileStore struct {
+	path       string // vault.json
+	keyPath    string // random per-install half of the machine secret
+	legacyPath string // plaintext secrets.json from older releases
+
+	passphrase func() (string, error)
+
+	// Derived key cache for the current file.
+	kdf  string
+	salt []byte
+	key  []byte
+}
+
+func newFileStore(dataDir string) *fileStore {
+	return &fileStore{
+		path:       filepath.Join(dataDir, "vault.json"),
+		keyPath:    filepath.Join(dataDir, "vault.key"),
+		legacyPath: filepath.Join(dataDir, "secrets.json"),
+		passphrase: promptPassphrase,
+	}
+}
+
+// load returns the decrypted secrets, first folding in a plaintext
+// secrets.json if one is left over so it never stays on disk.
+func (s *fileStore) load() (map[string]string, error) {
+	secrets, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.migrateLegacy(secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// read decrypts vault.json. A missing file is an empty vault.
+func (s *fileStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f encryptedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("vault: parsing %s: %w", s.path, err)
+	}
+	key, err := s.deriveKey(f.KDF, f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	// Only a key that opened the file is remembered, so a mistyped
+	// passphrase is asked for again next time.
+	s.kdf, s.salt, s.key = f.KDF, f.Salt, key
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("vault: decoding secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts secrets into vault.json with a fresh nonce, keeping the
+// current key source. A new vault uses the machine secret.
+func (s *fileStore) save(secrets map[string]string) error {
+	kdf, salt := s.kdf, s.salt
+	if kdf == "" {
+		kdf = kdfMachine
+	}
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+	key, err := s.deriveKey(kdf, salt)
+	if err != nil {
+		return err
+	}
+	return s.write(secrets, kdf, salt, key)
+}
+
+func (s *fileStore) write(secrets map[string]string, kdf string, salt, key []byte) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("marshaling secrets: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    1,
+		KDF:        kdf,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so a crash never leaves half a vault.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.kdf, s.salt, s.key = kdf, salt, key
+	return nil
+}
+
+// rekey re-encrypts secrets under a new salt, with passphrase as the key
+// source, or the machine secret when passphrase is empty.
+func (s *fileStore) rekey(secrets map[string]string, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	kdf, secret := kdfMachine, []byte(nil)
+	if passphrase != "" {
+		kdf, secret = kdfPassphrase, []byte(passphrase)
+	} else {
+		var err error
+		if secret, err = s.machineSecret(); err != nil {
+			return err
+		}
+	}
+	key, err := scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return err
+	}
+	return s.write(secrets, kdf, salt, key)
+}
+
+// migrateLegacy merges a plaintext secrets.json into secrets, saves the
+// result encrypted and deletes the plaintext file. Existing encrypted
+// entries win. It reports how many secrets were moved.
+func (s *fileStore) migrateLegacy(secrets map[string]string) (int, error) {
+	data, err := os.ReadFile(s.legacyPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	legacy := make(map[string]string)
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return 0, fmt.Errorf("vault: parsing %s: %w", s.legacyPath, err)
+	}
+	moved := 0
+	for k, v := range legacy {
+		if _, ok := secrets[k]; !ok {
+			secrets[k] = v
+			moved++
+		}
+	}
+	if err := s.save(secrets); err != nil {
+		return 0, err
+	}
+	return moved, os.Remove(s.legacyPath)
+}
+
+func (s *fileStore) deriveKey(kdf string, salt []byte) ([]byte, error) {
+	if s.key != nil && kdf == s.kdf && string(salt) == string(s.salt) {
+		return s.key, nil
+	}
+	var secret []byte
+	switch kdf {
+	case kdfMachine:
+		var err error
+		if secret, err = s.machineSecret(); err != nil {
+			return nil, err
+		}
+	case kdfPassphrase:
+		if s.passphrase == nil {
+			return nil, errors.New("vault: passphrase required but none available")
+		}
+		p, err := s.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		secret = []byte(p)
+	default:
+		return nil, fmt.Errorf("vault: unknown key source %q", kdf)
+	}
+	return scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+}
+
+// machineSecret combines a random per-install key file with the OS machine
+// id where there is one, so a copied data directory does not open on
+// another machine.
+func (s *fileStore) machineSecret() ([]byte, error) {
+	local, err := os.ReadFile(s.keyPath)
+	if os.IsNotExist(err) {
+		local = make([]byte, 32)
+		if _, err := rand.Read(local); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(s.keyPath, local, 0600); err != nil {
+			return nil, fmt.Errorf("vault: writing key file: %w", err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return append(local, machineID()...), nil
+}
+
+func machineID() []byte {
+	for _, p := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if id, err := os.ReadFile(p); err == nil {
+			return []byte(strings.TrimSpace(string(id)))
+		}
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// promptPassphrase reads the passphrase from PassphraseEnv, or asks on the
+// terminal.
+func promptPassphrase() (string, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("vault: passphrase required; set %s", PassphraseEnv)
+	}
+	return ReadPassphrase(os.Stderr, "Vault passphrase: ")
+}
+
+// ReadPassphrase prompts on w and reads a line from the terminal without
+// echoing it.
+func ReadPassphrase(w io.Writer, prompt string) (string, error) {
+	fmt.Fprint(w, prompt)
+	p, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(w)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	return string(p), nil
+}
@@ -2,7 +2,11 @@ module github.com/nathfavour/vibeauracle/vault
 
 go 1.21
 
-require github.com/99designs/keyring v1.2.2
+require (
+	github.com/99designs/keyring v1.2.2
+	golang.org/x/crypto v0.46.0
+	golang.org/x/term v0.38.0
+)
 
 require (
 	github.com/99designs/go-keychain v0.0.0-20191008050251-8e49817e8af4 // indirect
@@ -17,6 +21,5 @@ require (
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
 	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/term v0.38.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
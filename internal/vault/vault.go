@@ -1,25 +1,23 @@
 package vault
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
 
 	"github.com/99designs/keyring"
 )
 
-// Vault handles secure credential storage
+// Vault handles secure credential storage. Secrets live in the OS keyring
+// when one is available and otherwise in vault.json in the data directory,
+// encrypted with AES-GCM.
 type Vault struct {
-	ring         keyring.Keyring
-	fallbackPath string
-	mu           sync.RWMutex
+	ring keyring.Keyring
+	file *fileStore
+	mu   sync.RWMutex
 }
 
 func New(serviceName string, dataDir string) (*Vault, error) {
 	v := &Vault{
-		fallbackPath: filepath.Join(dataDir, "secrets.json"),
+		file: newFileStore(dataDir),
 	}
 
 	ring, err := keyring.Open(keyring.Config{
@@ -28,11 +26,30 @@ func New(serviceName string, dataDir string) (*Vault, error) {
 	if err == nil {
 		v.ring = ring
 	}
-	// If keyring fails, we just don't set v.ring and use fallbackPath
+	// If keyring fails, we just don't set v.ring and use the encrypted file
 	return v, nil
 }
 
-// Set stores a secret in the OS keyring or fallback file
+// SetPassphraseFunc replaces how the passphrase for a passphrase-protected
+// vault file is obtained. The default reads VIBEAURA_VAULT_PASSPHRASE or
+// prompts on the terminal.
+func (v *Vault) SetPassphraseFunc(fn func() (string, error)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.file.passphrase = fn
+}
+
+// Unlock opens the vault file now, asking for its passphrase if it has one,
+// so the key is cached before anything else takes over the terminal. A
+// vault with no file, or keyed to the machine, unlocks without asking.
+func (v *Vault) Unlock() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, err := v.file.load()
+	return err
+}
+
+// Set stores a secret in the OS keyring or encrypted fallback file
 func (v *Vault) Set(key, value string) error {
 	if v.ring != nil {
 		err := v.ring.Set(keyring.Item{
@@ -48,21 +65,16 @@ func (v *Vault) Set(key, value string) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	secrets := make(map[string]string)
-	if data, err := os.ReadFile(v.fallbackPath); err == nil {
-		json.Unmarshal(data, &secrets)
-	}
-
-	secrets[key] = value
-	data, err := json.MarshalIndent(secrets, "", "  ")
+	// A vault that cannot be opened is never overwritten.
+	secrets, err := v.file.load()
 	if err != nil {
-		return fmt.Errorf("marshaling secrets: %w", err)
+		return err
 	}
-
-	return os.WriteFile(v.fallbackPath, data, 0600)
+	secrets[key] = value
+	return v.file.save(secrets)
 }
 
-// Get retrieves a secret from the OS keyring or fallback file
+// Get retrieves a secret from the OS keyring or encrypted fallback file
 func (v *Vault) Get(key string) (string, error) {
 	if v.ring != nil {
 		item, err := v.ring.Get(key)
@@ -72,18 +84,60 @@ func (v *Vault) Get(key string) (string, error) {
 		// If keyring get fails (e.g. not found), check fallback
 	}
 
-	v.mu.RLock()
-	defer v.mu.RUnlock()
+	// Loading may migrate a plaintext file, so take the write lock.
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-	if data, err := os.ReadFile(v.fallbackPath); err == nil {
-		secrets := make(map[string]string)
-		if err := json.Unmarshal(data, &secrets); err == nil {
-			if val, ok := secrets[key]; ok {
-				return val, nil
-			}
-		}
+	secrets, err := v.file.load()
+	if err != nil {
+		return "", err
+	}
+	if val, ok := secrets[key]; ok {
+		return val, nil
 	}
 
-	return "", fmt.Errorf("secret not found in vault or fallback")
+	return "", ErrNotFound
 }
 
+// Migrate moves a plaintext secrets.json into the encrypted file, deleting
+// it, and re-encrypts the file under a fresh salt: with passphrase if one is
+// given, otherwise with the machine secret. It returns how many secrets
+// were moved and how many the file now holds.
+func (v *Vault) Migrate(passphrase string) (moved, total int, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets, err := v.file.read()
+	if err != nil {
+		return 0, 0, err
+	}
+	if moved, err = v.file.migrateLegacy(secrets); err != nil {
+		return 0, 0, err
+	}
+	if err := v.file.rekey(secrets, passphrase); err != nil {
+		return moved, 0, err
+	}
+	return moved, len(secrets), nil
+}
+
+// Delete removes a secret from the OS keyring and the fallback file.
+// Deleting a secret that is not stored is not an error.
+func (v *Vault) Delete(key string) error {
+	if v.ring != nil {
+		// The secret may only be in the file, so a keyring miss is fine.
+		_ = v.ring.Remove(key)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets, err := v.file.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return v.file.save(secrets)
+}
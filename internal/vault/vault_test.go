@@ -0,0 +1,121 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFileVault is a Vault without a keyring, so everything goes to disk.
+func newFileVault(dir string) *Vault {
+	v := &Vault{file: newFileStore(dir)}
+	v.file.passphrase = nil
+	return v
+}
+
+func TestVault_EncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	v := newFileVault(dir)
+	if err := v.Set("openai_api_key", "sk-very-secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "vault.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-very-secret") {
+		t.Fatalf("secret stored in plaintext:\n%s", data)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "vault.json")); info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600, got %v", info.Mode().Perm())
+	}
+
+	// A fresh Vault on the same machine reads it back.
+	if got, err := newFileVault(dir).Get("openai_api_key"); err != nil || got != "sk-very-secret" {
+		t.Fatalf("expected the secret back, got %q (%v)", got, err)
+	}
+}
+
+func TestVault_MigratesPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "secrets.json")
+	os.WriteFile(legacy, []byte(`{"github_models_pat":"ghp_old"}`), 0600)
+
+	v := newFileVault(dir)
+	if got, err := v.Get("github_models_pat"); err != nil || got != "ghp_old" {
+		t.Fatalf("expected the legacy secret, got %q (%v)", got, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("expected the plaintext file to be deleted, got %v", err)
+	}
+}
+
+func TestVault_MigratePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	v := newFileVault(dir)
+	v.Set("anthropic_api_key", "sk-ant-1")
+	os.WriteFile(filepath.Join(dir, "secrets.json"), []byte(`{"openai_api_key":"sk-2"}`), 0600)
+
+	moved, total, err := v.Migrate("correct horse")
+	if err != nil || moved != 1 || total != 2 {
+		t.Fatalf("expected 1 moved of 2, got %d/%d (%v)", moved, total, err)
+	}
+
+	wrong := newFileVault(dir)
+	wrong.SetPassphraseFunc(func() (string, error) { return "battery staple", nil })
+	if _, err := wrong.Get("openai_api_key"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for a wrong passphrase, got %v", err)
+	}
+	if err := wrong.Set("x", "y"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected Set to refuse to overwrite an unreadable vault, got %v", err)
+	}
+
+	right := newFileVault(dir)
+	right.SetPassphraseFunc(func() (string, error) { return "correct horse", nil })
+	if got, err := right.Get("openai_api_key"); err != nil || got != "sk-2" {
+		t.Fatalf("expected the secret with the passphrase, got %q (%v)", got, err)
+	}
+}
+
+func TestVault_Delete(t *testing.T) {
+	v := newFileVault(t.TempDir())
+	if err := v.Set("db_encryption_key", "k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Delete("db_encryption_key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Get("db_encryption_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound once deleted, got %v", err)
+	}
+	if err := v.Delete("db_encryption_key"); err != nil {
+		t.Errorf("expected deleting a missing secret to succeed, got %v", err)
+	}
+}
+
+func TestVault_UnlockCachesPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := newFileVault(dir).Migrate("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	locked := func() (string, error) { return "", ErrLocked }
+
+	v := newFileVault(dir)
+	v.SetPassphraseFunc(func() (string, error) { return "correct horse", nil })
+	if err := v.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	v.SetPassphraseFunc(locked)
+	if err := v.Set("openai_api_key", "sk-1"); err != nil {
+		t.Fatalf("expected the unlocked vault to keep working, got %v", err)
+	}
+
+	never := newFileVault(dir)
+	never.SetPassphraseFunc(locked)
+	if _, err := never.Get("openai_api_key"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked without an unlock, got %v", err)
+	}
+}
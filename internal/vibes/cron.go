@@ -0,0 +1,83 @@
+package vibes
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser reads vibe schedules: the standard five fields (minute, hour,
+// day of month, month, day of week) with steps, ranges and lists, an
+// optional leading seconds field, and the @hourly, @daily, @weekly style
+// shorthands.
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// starBit marks a field written as "*" in a parsed cron.SpecSchedule.
+const starBit = 1 << 63
+
+// ParseCron parses a vibe schedule expression. Schedules that pin the hour
+// follow wall-clock time across DST transitions: a time skipped by the
+// clock going forward fires shifted by the jump (02:30 becomes 03:30), and
+// a time repeated by the clock going back fires once. Schedules with a "*" hour run on
+// elapsed time, so an hourly job still fires in a repeated hour.
+func ParseCron(expr string) (cron.Schedule, error) {
+	sched, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := sched.(*cron.SpecSchedule); ok && spec.Hour&starBit == 0 {
+		return wallClockSchedule{spec}, nil
+	}
+	return sched, nil
+}
+
+// NextCronRun returns the first time after from that expr fires.
+func NextCronRun(expr string, from time.Time) (time.Time, error) {
+	sched, err := ParseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(from), nil
+}
+
+// wallClockSchedule matches a spec against local wall-clock readings
+// rather than instants, the way classic cron treats fixed-time jobs.
+type wallClockSchedule struct {
+	spec *cron.SpecSchedule
+}
+
+func (w wallClockSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	if w.spec.Location != time.Local {
+		loc = w.spec.Location
+	}
+	// Searching in UTC sees every wall-clock reading exactly once; time.Date
+	// then picks the first of two repeated readings.
+	spec := *w.spec
+	spec.Location = time.UTC
+	wall := asWallClock(t.In(loc))
+	for {
+		wall = spec.Next(wall)
+		if wall.IsZero() {
+			return wall
+		}
+		next := time.Date(wall.Year(), wall.Month(), wall.Day(),
+			wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+		if got := asWallClock(next); !got.Equal(wall) {
+			// The reading fell in a spring-forward gap and time.Date
+			// moved it back; move it forward by the jump instead.
+			next = next.Add(wall.Sub(got))
+		}
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// asWallClock returns t's wall-clock reading as the same reading in UTC.
+func asWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
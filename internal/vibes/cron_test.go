@@ -0,0 +1,131 @@
+package vibes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_NextRuns(t *testing.T) {
+	// A Thursday.
+	ref := time.Date(2026, 1, 15, 10, 17, 0, 0, time.UTC)
+	at := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		want [3]time.Time
+	}{
+		{"*/5 * * * *", [3]time.Time{at(2026, 1, 15, 10, 20), at(2026, 1, 15, 10, 25), at(2026, 1, 15, 10, 30)}},
+		{"0 0 * * *", [3]time.Time{at(2026, 1, 16, 0, 0), at(2026, 1, 17, 0, 0), at(2026, 1, 18, 0, 0)}},
+		{"@hourly", [3]time.Time{at(2026, 1, 15, 11, 0), at(2026, 1, 15, 12, 0), at(2026, 1, 15, 13, 0)}},
+		{"@daily", [3]time.Time{at(2026, 1, 16, 0, 0), at(2026, 1, 17, 0, 0), at(2026, 1, 18, 0, 0)}},
+		{"@weekly", [3]time.Time{at(2026, 1, 18, 0, 0), at(2026, 1, 25, 0, 0), at(2026, 2, 1, 0, 0)}},
+		{"30 9 * * 1-5", [3]time.Time{at(2026, 1, 16, 9, 30), at(2026, 1, 19, 9, 30), at(2026, 1, 20, 9, 30)}},
+		{"0 8,12,18 * * *", [3]time.Time{at(2026, 1, 15, 12, 0), at(2026, 1, 15, 18, 0), at(2026, 1, 16, 8, 0)}},
+		{"15 14 1 * *", [3]time.Time{at(2026, 2, 1, 14, 15), at(2026, 3, 1, 14, 15), at(2026, 4, 1, 14, 15)}},
+		{"0 0 1 1 *", [3]time.Time{at(2027, 1, 1, 0, 0), at(2028, 1, 1, 0, 0), at(2029, 1, 1, 0, 0)}},
+		{"0 */6 * * *", [3]time.Time{at(2026, 1, 15, 12, 0), at(2026, 1, 15, 18, 0), at(2026, 1, 16, 0, 0)}},
+		{"10-20/5 10 * * *", [3]time.Time{at(2026, 1, 15, 10, 20), at(2026, 1, 16, 10, 10), at(2026, 1, 16, 10, 15)}},
+		{"0 12 * * SAT,SUN", [3]time.Time{at(2026, 1, 17, 12, 0), at(2026, 1, 18, 12, 0), at(2026, 1, 24, 12, 0)}},
+		{"0 0 29 2 *", [3]time.Time{at(2028, 2, 29, 0, 0), at(2032, 2, 29, 0, 0), at(2036, 2, 29, 0, 0)}},
+		{"0 30 9 * * *", [3]time.Time{at(2026, 1, 16, 9, 30), at(2026, 1, 17, 9, 30), at(2026, 1, 18, 9, 30)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			assertNextRuns(t, tt.expr, ref, tt.want)
+		})
+	}
+}
+
+func TestParseCron_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tz database: %v", err)
+	}
+	at := func(m time.Month, d, h, min int, zone string) time.Time {
+		got := time.Date(2026, m, d, h, min, 0, 0, ny)
+		if name, _ := got.Zone(); name != zone {
+			// The repeated hour on fall-back: step into the second pass.
+			got = got.Add(time.Hour)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want [3]time.Time
+	}{
+		{
+			// 02:30 does not exist on 8 March; it runs once the clock jumps.
+			name: "spring forward skips the time",
+			expr: "30 2 * * *",
+			from: at(time.March, 7, 12, 0, "EST"),
+			want: [3]time.Time{at(time.March, 8, 3, 30, "EDT"), at(time.March, 9, 2, 30, "EDT"), at(time.March, 10, 2, 30, "EDT")},
+		},
+		{
+			// 01:30 happens twice on 1 November; it runs only the first time.
+			name: "fall back repeats the time",
+			expr: "30 1 * * *",
+			from: at(time.October, 31, 12, 0, "EDT"),
+			want: [3]time.Time{at(time.November, 1, 1, 30, "EDT"), at(time.November, 2, 1, 30, "EST"), at(time.November, 3, 1, 30, "EST")},
+		},
+		{
+			name: "hourly runs in both repeated hours",
+			expr: "0 * * * *",
+			from: at(time.November, 1, 0, 30, "EDT"),
+			want: [3]time.Time{at(time.November, 1, 1, 0, "EDT"), at(time.November, 1, 1, 0, "EST"), at(time.November, 1, 2, 0, "EST")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertNextRuns(t, tt.expr, tt.from, tt.want)
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "61 * * * *", "0 25 * * *", "@fortnightly", "a b c d e"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestScheduler_ScheduleReturnsNextRun(t *testing.T) {
+	s := NewScheduler()
+	next, err := s.Schedule("greeter", "@hourly", func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if !next.After(now) || next.Sub(now) > time.Hour || next.Minute() != 0 || next.Second() != 0 {
+		t.Errorf("expected the top of the next hour, got %v", next)
+	}
+	if got := s.NextRun("greeter"); got == nil || !got.Equal(next) {
+		t.Errorf("NextRun = %v, want %v", got, next)
+	}
+
+	if _, err := s.Schedule("greeter", "every tuesday", func() {}); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+}
+
+func assertNextRuns(t *testing.T, expr string, from time.Time, want [3]time.Time) {
+	t.Helper()
+	sched, err := ParseCron(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := from
+	for i, w := range want {
+		next = sched.Next(next)
+		if !next.Equal(w) {
+			t.Fatalf("run %d of %q = %v, want %v", i+1, expr, next, w)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 // Dependency represents a Vibe dependency.
@@ -31,11 +33,12 @@ type ResolutionResult struct {
 	Resolved  []*Vibe
 	Missing   []string
 	Conflicts []string
+	Errors    []ValidationError // Dependencies whose version does not satisfy the constraint
 	LoadOrder []string
 }
 
 func (rr *ResolutionResult) IsValid() bool {
-	return len(rr.Missing) == 0 && len(rr.Conflicts) == 0
+	return len(rr.Missing) == 0 && len(rr.Conflicts) == 0 && len(rr.Errors) == 0
 }
 
 // DependencyResolver handles Vibe dependency resolution.
@@ -54,6 +57,7 @@ func (dr *DependencyResolver) Resolve(names []string) (*ResolutionResult, error)
 		Resolved:  make([]*Vibe, 0),
 		Missing:   make([]string, 0),
 		Conflicts: make([]string, 0),
+		Errors:    make([]ValidationError, 0),
 		LoadOrder: make([]string, 0),
 	}
 
@@ -77,12 +81,19 @@ func (dr *DependencyResolver) Resolve(names []string) (*ResolutionResult, error)
 	for name, vibe := range vibeMap {
 		deps := extractDependencies(vibe)
 		for _, dep := range deps {
-			if _, ok := vibeMap[dep.Name]; !ok {
+			other, ok := vibeMap[dep.Name]
+			if !ok {
 				if !dep.Optional {
 					result.Missing = append(result.Missing, fmt.Sprintf("%s (required by %s)", dep.Name, name))
 				}
 				continue
 			}
+			if err := checkVersion(dep, other); err != nil {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("%s.depends.%s", name, dep.Name),
+					Message: err.Error(),
+				})
+			}
 			graph[dep.Name] = append(graph[dep.Name], name)
 			inDegree[name]++
 		}
@@ -185,14 +196,45 @@ func (dr *DependencyResolver) GetMissingDependencies(vibeName string) []string {
 		}
 		if !other.Enabled && !dep.Optional {
 			missing = append(missing, fmt.Sprintf("%s (disabled)", dep.Name))
+			continue
+		}
+		if err := checkVersion(dep, other); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%v)", dep.Name, err))
 		}
 	}
 
 	return missing
 }
 
+// checkVersion reports whether other's version satisfies dep's constraint,
+// e.g. ">=1.2.0 <2.0.0", "~1.4" or "^2". A dependency without a constraint
+// accepts any version; a vibe without a version counts as 1.0.0, as
+// Validate warns.
+func checkVersion(dep Dependency, other *Vibe) error {
+	if dep.Version == "" {
+		return nil
+	}
+	c, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return fmt.Errorf("invalid version constraint %q: %v", dep.Version, err)
+	}
+	installed := other.Spec.Version
+	if installed == "" {
+		installed = "1.0.0"
+	}
+	v, err := semver.NewVersion(installed)
+	if err != nil {
+		return fmt.Errorf("version %q of %s is not semver", installed, dep.Name)
+	}
+	if !c.Check(v) {
+		return fmt.Errorf("requires %s, found %s", dep.Version, installed)
+	}
+	return nil
+}
+
 // extractDependencies parses dependencies from a Vibe's instructions.
-// In a real implementation, this would be in the YAML spec.
+// In a real implementation, this would be in the YAML spec. A version
+// constraint may follow the name: "@depends: git-helper >=1.2.0 <2.0.0".
 func extractDependencies(vibe *Vibe) []Dependency {
 	// Check if instructions contain dependency markers
 	var deps []Dependency
@@ -200,17 +242,23 @@ func extractDependencies(vibe *Vibe) []Dependency {
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "@depends:") {
-			depName := strings.TrimSpace(strings.TrimPrefix(line, "@depends:"))
-			deps = append(deps, Dependency{Name: depName})
+			deps = append(deps, parseDependency(strings.TrimPrefix(line, "@depends:")))
 		}
 		if strings.HasPrefix(line, "@optional-depends:") {
-			depName := strings.TrimSpace(strings.TrimPrefix(line, "@optional-depends:"))
-			deps = append(deps, Dependency{Name: depName, Optional: true})
+			dep := parseDependency(strings.TrimPrefix(line, "@optional-depends:"))
+			dep.Optional = true
+			deps = append(deps, dep)
 		}
 	}
 	return deps
 }
 
+// parseDependency splits "name [constraint]".
+func parseDependency(s string) Dependency {
+	name, constraint, _ := strings.Cut(strings.TrimSpace(s), " ")
+	return Dependency{Name: name, Version: strings.TrimSpace(constraint)}
+}
+
 // extractConflicts parses conflicts from a Vibe's instructions.
 func extractConflicts(vibe *Vibe) []Conflict {
 	var conflicts []Conflict
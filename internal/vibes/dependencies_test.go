@@ -0,0 +1,81 @@
+package vibes
+
+import (
+	"strings"
+	"testing"
+)
+
+func depRegistry(vibes ...*Vibe) *Registry {
+	r := NewRegistry()
+	for _, v := range vibes {
+		v.Enabled = true
+		r.vibes[v.Spec.Name] = v
+	}
+	return r
+}
+
+func TestResolve_VersionConstraints(t *testing.T) {
+	cases := []struct {
+		constraint string
+		installed  string
+		ok         bool
+	}{
+		{">=1.2.0", "1.2.0", true},
+		{">=1.2.0", "1.1.9", false},
+		{"<=2.0.0", "2.0.0", true},
+		{"<=2.0.0", "2.0.1", false},
+		{">=1.2.0 <2.0.0", "1.9.3", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{"~1.4.0", "1.4.7", true},
+		{"~1.4.0", "1.5.0", false},
+		{"^1.4.0", "1.9.0", true},
+		{"^1.4.0", "2.0.0", false},
+		{"^0.3.0", "0.3.5", true},
+		{"^0.3.0", "0.4.0", false},
+		{"1.3.0", "1.3.0", true},
+		{"=1.3.0", "1.3.1", false},
+		{">=1.0.0", "", true}, // No version counts as 1.0.0
+		{">=1.1.0", "", false},
+		{"", "0.0.1", true},
+	}
+	for _, c := range cases {
+		app := &Vibe{Spec: Spec{Name: "app", Version: "1.0.0"}, Instructions: strings.TrimSpace("@depends: lib " + c.constraint)}
+		lib := &Vibe{Spec: Spec{Name: "lib", Version: c.installed}}
+		dr := NewDependencyResolver(depRegistry(app, lib))
+
+		result, err := dr.Resolve([]string{"app", "lib"})
+		if err != nil {
+			t.Fatalf("%q against %q: %v", c.constraint, c.installed, err)
+		}
+		if result.IsValid() != c.ok {
+			t.Errorf("%q against %q: valid = %v, want %v (errors %v)", c.constraint, c.installed, result.IsValid(), c.ok, result.Errors)
+		}
+		if strings.Join(result.LoadOrder, ",") != "lib,app" {
+			t.Errorf("%q against %q: load order %v", c.constraint, c.installed, result.LoadOrder)
+		}
+		if missing := dr.GetMissingDependencies("app"); (len(missing) == 0) != c.ok {
+			t.Errorf("%q against %q: missing = %v", c.constraint, c.installed, missing)
+		}
+	}
+}
+
+func TestResolve_BadVersions(t *testing.T) {
+	app := &Vibe{Spec: Spec{Name: "app"}, Instructions: "@depends: lib >=banana\n@optional-depends: extra ^2.0.0"}
+	lib := &Vibe{Spec: Spec{Name: "lib", Version: "1.0.0"}}
+	extra := &Vibe{Spec: Spec{Name: "extra", Version: "latest"}}
+	result, err := NewDependencyResolver(depRegistry(app, lib, extra)).Resolve([]string{"app", "lib", "extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 2 {
+		t.Fatalf("expected the bad constraint and the bad version reported, got %v", result.Errors)
+	}
+	for _, e := range result.Errors {
+		if !strings.HasPrefix(e.Field, "app.depends.") {
+			t.Errorf("expected the error on app's dependency, got %v", e)
+		}
+	}
+	if !strings.Contains(result.Errors[0].Error()+result.Errors[1].Error(), `invalid version constraint ">=banana"`) {
+		t.Errorf("expected the constraint named, got %v", result.Errors)
+	}
+}
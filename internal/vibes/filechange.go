@@ -0,0 +1,122 @@
+package vibes
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/watcher"
+)
+
+// fileChangeDebounce is how long a vibe's file events are collected
+// before HookOnFileChange fires once for the whole burst.
+const fileChangeDebounce = 250 * time.Millisecond
+
+// fileBatch holds the events waiting to be delivered to one vibe.
+type fileBatch struct {
+	timer  *time.Timer
+	events []map[string]interface{}
+	index  map[string]int // path -> position in events, so repeats collapse
+	latest map[string]interface{}
+}
+
+// AttachWatcher dispatches HookOnFileChange to enabled vibes for every
+// event w reports. Events are debounced per vibe; the payload holds the
+// latest event's "path", "type" and "timestamp", plus every distinct path
+// in the burst under "events". Vibes without system.fs only see paths
+// inside ProjectRoot, given relative to it.
+func (r *Runtime) AttachWatcher(w *watcher.Watcher) {
+	w.SubscribeFunc(r.onFileEvent)
+}
+
+func (r *Runtime) onFileEvent(evt watcher.Event) {
+	for _, vibe := range r.Registry.ByHook(HookOnFileChange) {
+		path, ok := r.visiblePath(vibe, evt.Path)
+		if !ok {
+			continue
+		}
+		r.queueFileEvent(vibe.Spec.Name, map[string]interface{}{
+			"path":      path,
+			"type":      evt.Type.String(),
+			"timestamp": evt.Timestamp,
+		})
+	}
+}
+
+// visiblePath returns the path as vibe may see it, or false if the vibe
+// is not allowed to learn about it.
+func (r *Runtime) visiblePath(vibe *Vibe, path string) (string, bool) {
+	if vibe.HasPermission(PermSystemFS) {
+		return path, true
+	}
+	if r.ProjectRoot == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(r.ProjectRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
+func (r *Runtime) queueFileEvent(vibeName string, event map[string]interface{}) {
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	if r.fileStopped {
+		return
+	}
+
+	b := r.fileBatches[vibeName]
+	if b == nil {
+		b = &fileBatch{index: make(map[string]int)}
+		r.fileBatches[vibeName] = b
+		b.timer = time.AfterFunc(fileChangeDebounce, func() { r.flushFileEvents(vibeName) })
+	} else {
+		b.timer.Reset(fileChangeDebounce)
+	}
+
+	b.latest = event
+	path := event["path"].(string)
+	if i, ok := b.index[path]; ok {
+		b.events[i] = event
+	} else {
+		b.index[path] = len(b.events)
+		b.events = append(b.events, event)
+	}
+}
+
+func (r *Runtime) flushFileEvents(vibeName string) {
+	r.fileMu.Lock()
+	b := r.fileBatches[vibeName]
+	delete(r.fileBatches, vibeName)
+	stopped := r.fileStopped
+	r.fileMu.Unlock()
+	if b == nil || len(b.events) == 0 || stopped {
+		return
+	}
+
+	// The vibe may have been disabled or removed while events queued.
+	vibe, ok := r.Registry.Get(vibeName)
+	if !ok || !vibe.Enabled {
+		return
+	}
+
+	latest := b.latest
+	r.Dispatcher.DispatchTo(HookOnFileChange, vibe, map[string]interface{}{
+		"path":      latest["path"],
+		"type":      latest["type"],
+		"timestamp": latest["timestamp"],
+		"events":    b.events,
+	})
+}
+
+// stopFileEvents drops pending file events and ignores any that follow.
+func (r *Runtime) stopFileEvents() {
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	r.fileStopped = true
+	for name, b := range r.fileBatches {
+		b.timer.Stop()
+		delete(r.fileBatches, name)
+	}
+}
@@ -0,0 +1,66 @@
+package vibes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nathfavour/vibeauracle/watcher"
+)
+
+func TestRuntime_FileChangeHook(t *testing.T) {
+	dataDir := t.TempDir()
+	project := t.TempDir()
+	rt, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt.ProjectRoot = project
+	os.WriteFile(filepath.Join(dataDir, "vibes", "lint.vibe.md"), []byte("---\nname: lint\nversion: 1.0.0\nhooks: [on_file_change]\n---\nLint on save.\n"), 0644)
+	if err := rt.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := watcher.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := w.AddRoot(project); err != nil {
+		t.Fatal(err)
+	}
+	rt.AttachWatcher(w)
+	w.Start()
+
+	got := make(chan map[string]interface{}, 10)
+	rt.Dispatcher.RegisterHandler(HookOnFileChange, func(ctx *HookContext) {
+		got <- ctx.Data
+	})
+
+	// A burst of writes fires the hook once.
+	target := filepath.Join(project, "main.go")
+	for i := 0; i < 5; i++ {
+		os.WriteFile(target, []byte("package main\n"), 0644)
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Outside the project root, a vibe without system.fs sees nothing.
+	os.WriteFile(filepath.Join(dataDir, "elsewhere.txt"), []byte("x"), 0644)
+
+	select {
+	case data := <-got:
+		if data["path"] != "main.go" {
+			t.Errorf("expected the path relative to the project, got %v", data["path"])
+		}
+		if events := data["events"].([]map[string]interface{}); len(events) != 1 {
+			t.Errorf("expected the burst collapsed to one path, got %v", events)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for HookOnFileChange")
+	}
+	select {
+	case data := <-got:
+		t.Fatalf("expected a single dispatch, got another: %v", data)
+	case <-time.After(2 * fileChangeDebounce):
+	}
+}
@@ -3,6 +3,17 @@ module github.com/nathfavour/vibeauracle/vibes
 go 1.21
 
 require (
+	github.com/Masterminds/semver/v3 v3.3.1
+	github.com/nathfavour/vibeauracle/watcher v0.0.0
+	github.com/pquerna/otp v1.5.0
 	github.com/robfig/cron/v3 v3.0.1
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require (
+	github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
+	github.com/fsnotify/fsnotify v1.8.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
+
+replace github.com/nathfavour/vibeauracle/watcher => ../watcher
@@ -85,6 +85,29 @@ func (hd *HookDispatcher) Dispatch(hook Hook, data map[string]interface{}) bool
 	return cancelled
 }
 
+// DispatchTo runs the handlers for a hook on behalf of a single Vibe, for
+// events whose payload differs per Vibe. Global handlers are not run.
+// Returns true if any handler set Cancel to true.
+func (hd *HookDispatcher) DispatchTo(hook Hook, vibe *Vibe, data map[string]interface{}) bool {
+	hd.mu.RLock()
+	handlers := hd.handlers[hook]
+	hd.mu.RUnlock()
+
+	cancelled := false
+	for _, handler := range handlers {
+		ctx := &HookContext{
+			Hook: hook,
+			Vibe: vibe,
+			Data: data,
+		}
+		handler(ctx)
+		if ctx.Cancel {
+			cancelled = true
+		}
+	}
+	return cancelled
+}
+
 // DispatchAsync triggers handlers asynchronously.
 func (hd *HookDispatcher) DispatchAsync(hook Hook, data map[string]interface{}) {
 	go hd.Dispatch(hook, data)
@@ -1,9 +1,12 @@
 package vibes
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -44,14 +47,22 @@ type LogEntry struct {
 	Error     string         `json:"error,omitempty"`
 }
 
+// maxLogFileSize caps the persisted log. Past it the file moves to a ".1"
+// backup, replacing the previous one, and a new file is started.
+const maxLogFileSize = 1 << 20
+
 // Logger handles logging for Vibe execution.
 type Logger struct {
-	mu       sync.RWMutex
-	entries  []LogEntry
-	maxSize  int
-	writers  []func(LogEntry)
-	minLevel LogLevel
-	dataDir  string
+	mu          sync.RWMutex
+	entries     []LogEntry
+	maxSize     int
+	writers     []func(LogEntry)
+	minLevel    LogLevel
+	dataDir     string
+	file        *os.File // Append-only JSON lines log, if persisted
+	path        string
+	fileSize    int64
+	maxFileSize int64
 }
 
 // NewLogger creates a new Vibe logger.
@@ -62,7 +73,104 @@ func NewLogger(dataDir string, maxSize int) *Logger {
 		writers:  make([]func(LogEntry), 0),
 		minLevel: LogInfo,
 		dataDir:  dataDir,
+
+		maxFileSize: maxLogFileSize,
+	}
+}
+
+// Persist loads earlier entries from path, a JSON lines file, and appends
+// every new entry to it so logs survive across processes. The file is kept
+// under maxLogFileSize by rotation.
+func (l *Logger) Persist(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loaded, err := readLogTail(path, l.maxFileSize)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l.entries = append(loaded, l.entries...)
+	if len(l.entries) > l.maxSize {
+		l.entries = l.entries[len(l.entries)-l.maxSize:]
+	}
+
+	l.path = path
+	return l.openLocked(false)
+}
+
+// readLogTail parses the entries in the last max bytes of path.
+func readLogTail(path string, max int64) ([]LogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	partial := info.Size() > max
+	if partial {
+		if _, err := f.Seek(-max, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if partial {
+		lines = lines[1:] // Cut mid-line
+	}
+	var loaded []LogEntry
+	for _, line := range lines {
+		var entry LogEntry
+		if line == "" || json.Unmarshal([]byte(line), &entry) != nil {
+			continue
+		}
+		loaded = append(loaded, entry)
+	}
+	return loaded, nil
+}
+
+// openLocked (re)opens the log file for appending, first rotating it if
+// rotate is set or it is already over the cap.
+func (l *Logger) openLocked(rotate bool) error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	if info, err := os.Stat(l.path); err == nil && (rotate || info.Size() >= l.maxFileSize) {
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
 	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file, l.fileSize = f, info.Size()
+	return nil
+}
+
+// Close stops persisting entries.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
 }
 
 // SetMinLevel sets the minimum log level.
@@ -129,6 +237,19 @@ func (l *Logger) log(entry LogEntry) {
 		l.entries = l.entries[len(l.entries)-l.maxSize:]
 	}
 
+	if l.file != nil {
+		if line, err := json.Marshal(entry); err == nil {
+			line = append(line, '\n')
+			if l.fileSize > 0 && l.fileSize+int64(len(line)) > l.maxFileSize {
+				l.openLocked(true)
+			}
+			if l.file != nil {
+				n, _ := l.file.Write(line)
+				l.fileSize += int64(n)
+			}
+		}
+	}
+
 	// Notify writers
 	for _, w := range l.writers {
 		go w(entry)
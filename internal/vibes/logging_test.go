@@ -0,0 +1,57 @@
+package vibes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_RotatesPersistedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibes.log")
+	l := NewLogger("", 100)
+	l.maxFileSize = 2048
+	if err := l.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		l.Log(LogInfo, "greeter", fmt.Sprintf("run %d", i))
+	}
+	l.Close()
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > l.maxFileSize {
+		t.Fatalf("expected the log kept under %d bytes, got %v (%v)", l.maxFileSize, info, err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected a rotated backup: %v", err)
+	}
+
+	again := NewLogger("", 100)
+	if err := again.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	entries := again.Entries(0)
+	if len(entries) == 0 || entries[len(entries)-1].Message != "run 99" {
+		t.Errorf("expected the newest entries reloaded, got %+v", entries)
+	}
+}
+
+func TestReadLogTail_SkipsCutLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibes.log")
+	var sb strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&sb, `{"vibe_name":"greeter","message":"run %d"}`+"\n", i)
+	}
+	os.WriteFile(path, []byte(sb.String()), 0644)
+
+	entries, err := readLogTail(path, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) > 5 || entries[len(entries)-1].Message != "run 49" {
+		t.Errorf("expected only the last few whole entries, got %+v", entries)
+	}
+}
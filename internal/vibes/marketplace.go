@@ -0,0 +1,110 @@
+package vibes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultRegistryURL is the curated list of community vibes.
+const DefaultRegistryURL = "https://raw.githubusercontent.com/nathfavour/vibeauracle/main/registry/vibes.json"
+
+// RegistryEntry describes a vibe listed in the marketplace registry.
+type RegistryEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Version     string `json:"version"`
+	URL         string `json:"url"`
+	// Checksum is the SHA-256 of the .vibe.md file, hex encoded and
+	// optionally prefixed with "sha256:".
+	Checksum string `json:"checksum"`
+}
+
+// Marketplace is the registry document.
+type Marketplace struct {
+	Vibes []RegistryEntry `json:"vibes"`
+}
+
+// ParseMarketplace decodes a registry document. Entries without a name or
+// an https:// URL are rejected.
+func ParseMarketplace(data []byte) (*Marketplace, error) {
+	var m Marketplace
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing vibe registry: %w", err)
+	}
+	for i, e := range m.Vibes {
+		if e.Name == "" {
+			return nil, fmt.Errorf("vibe registry entry %d has no name", i)
+		}
+		if !strings.HasPrefix(e.URL, "https://") {
+			return nil, fmt.Errorf("vibe registry entry %s: url must be https://", e.Name)
+		}
+	}
+	return &m, nil
+}
+
+// Search returns the entries whose name or description contains query,
+// ignoring case, sorted by name. An empty query matches everything.
+func (m *Marketplace) Search(query string) []RegistryEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var out []RegistryEntry
+	for _, e := range m.Vibes {
+		if query == "" ||
+			strings.Contains(strings.ToLower(e.Name), query) ||
+			strings.Contains(strings.ToLower(e.Description), query) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Lookup finds an entry by exact name.
+func (m *Marketplace) Lookup(name string) (RegistryEntry, bool) {
+	for _, e := range m.Vibes {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return RegistryEntry{}, false
+}
+
+// VerifyChecksum checks data against the entry's checksum. Entries without
+// one are refused so a registry cannot vouch for an unpinned file.
+func (e RegistryEntry) VerifyChecksum(data []byte) error {
+	want := strings.ToLower(strings.TrimPrefix(e.Checksum, "sha256:"))
+	if want == "" {
+		return fmt.Errorf("vibe %s has no checksum in the registry", e.Name)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", e.Name, want, got)
+	}
+	return nil
+}
+
+// SensitivePermissionsOf lists the permissions vibe declares that need
+// explicit approval.
+func SensitivePermissionsOf(vibe *Vibe) []Permission {
+	var out []Permission
+	for _, p := range vibe.Spec.Permissions {
+		if isSensitive(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Approve grants vibe its sensitive permissions and records the approval
+// in its persisted state.
+func (r *Runtime) Approve(vibe *Vibe) error {
+	for _, p := range SensitivePermissionsOf(vibe) {
+		r.Security.ApprovePermission(vibe.Spec.Name, p)
+	}
+	r.State.RecordApproval(vibe.Spec.Name)
+	return r.State.Save()
+}
@@ -0,0 +1,49 @@
+package vibes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+const testRegistry = `{"vibes": [
+	{"name": "git-watch", "description": "Notify on uncommitted changes", "author": "a", "version": "1.0.0", "url": "https://example.com/git-watch.vibe.md", "checksum": "sha256:00"},
+	{"name": "dark-mode", "description": "A darker theme", "author": "b", "version": "0.2.0", "url": "https://example.com/dark-mode.vibe.md"}
+]}`
+
+func TestMarketplace_Search(t *testing.T) {
+	m, err := ParseMarketplace([]byte(testRegistry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Search("THEME"); len(got) != 1 || got[0].Name != "dark-mode" {
+		t.Errorf("Search by description = %+v", got)
+	}
+	if got := m.Search(""); len(got) != 2 || got[0].Name != "dark-mode" {
+		t.Errorf("empty query should list everything sorted by name, got %+v", got)
+	}
+	if _, ok := m.Lookup("git-watch"); !ok {
+		t.Error("Lookup missed git-watch")
+	}
+}
+
+func TestParseMarketplace_RejectsPlainHTTP(t *testing.T) {
+	if _, err := ParseMarketplace([]byte(`{"vibes": [{"name": "x", "url": "http://example.com/x.vibe.md"}]}`)); err == nil {
+		t.Fatal("expected an http:// url to be rejected")
+	}
+}
+
+func TestRegistryEntry_VerifyChecksum(t *testing.T) {
+	data := []byte("---\nname: x\n---\n")
+	sum := sha256.Sum256(data)
+	e := RegistryEntry{Name: "x", Checksum: "sha256:" + hex.EncodeToString(sum[:])}
+	if err := e.VerifyChecksum(data); err != nil {
+		t.Errorf("VerifyChecksum: %v", err)
+	}
+	if err := e.VerifyChecksum([]byte("tampered")); err == nil {
+		t.Error("expected a mismatch for different data")
+	}
+	if err := (RegistryEntry{Name: "x"}).VerifyChecksum(data); err == nil {
+		t.Error("expected an entry without a checksum to be refused")
+	}
+}
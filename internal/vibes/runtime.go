@@ -1,9 +1,14 @@
 package vibes
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/nathfavour/vibeauracle/watcher"
 )
 
 // Runtime is the central orchestrator for the Vibes extension system.
@@ -13,9 +18,27 @@ type Runtime struct {
 	Scheduler  *Scheduler
 	Dispatcher *HookDispatcher
 	Security   *SecurityManager
+	Executor   *Executor
+	State      *StateManager
+	Logger     *Logger
 	DataDir    string
+
+	// ProjectRoot bounds the paths HookOnFileChange reports to vibes
+	// without system.fs. Defaults to the working directory.
+	ProjectRoot string
+
+	reloadMu    sync.Mutex
+	watcher     *watcher.Watcher
+	reloadTimer *time.Timer
+
+	fileMu      sync.Mutex
+	fileBatches map[string]*fileBatch // Pending HookOnFileChange events per vibe
+	fileStopped bool
 }
 
+// maxLogEntries bounds the vibe log kept in memory.
+const maxLogEntries = 1000
+
 // NewRuntime creates a fully initialized Vibes runtime.
 func NewRuntime(dataDir string) (*Runtime, error) {
 	vibesDir := filepath.Join(dataDir, "vibes")
@@ -26,52 +49,114 @@ func NewRuntime(dataDir string) (*Runtime, error) {
 	registry := NewRegistry()
 	registry.AddDirectory(vibesDir)
 
+	logger := NewLogger(dataDir, maxLogEntries)
+	if err := logger.Persist(filepath.Join(dataDir, "vibes.log")); err != nil {
+		return nil, err
+	}
+
+	cwd, _ := os.Getwd()
+	security := NewSecurityManager()
+
 	runtime := &Runtime{
 		Registry:   registry,
 		Scheduler:  NewScheduler(),
 		Dispatcher: NewHookDispatcher(registry),
-		Security:   NewSecurityManager(),
+		Security:   security,
+		Executor:   NewExecutor(logger, NewTelemetry(), security),
+		State:      NewStateManager(dataDir),
+		Logger:     logger,
 		DataDir:    dataDir,
+
+		ProjectRoot: cwd,
+		fileBatches: make(map[string]*fileBatch),
 	}
 
 	return runtime, nil
 }
 
+// Scan loads the vibes on disk and applies the enabled state saved by
+// earlier enable and disable calls.
+func (r *Runtime) Scan() error {
+	if err := r.Registry.Scan(); err != nil {
+		return err
+	}
+	for _, vibe := range r.Registry.List() {
+		if st := r.State.Get(vibe.Spec.Name); st != nil && !st.Enabled {
+			r.Registry.Disable(vibe.Spec.Name)
+		}
+	}
+	return nil
+}
+
+// SetEnabled enables or disables a vibe and saves the choice.
+func (r *Runtime) SetEnabled(name string, enabled bool) error {
+	var err error
+	if enabled {
+		err = r.Registry.Enable(name)
+	} else {
+		err = r.Registry.Disable(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.State.SetEnabled(name, enabled)
+	if err := r.State.Save(); err != nil {
+		return fmt.Errorf("saving vibe state: %w", err)
+	}
+	if enabled {
+		r.Logger.Log(LogInfo, name, "enabled")
+	} else {
+		r.Logger.Log(LogInfo, name, "disabled")
+	}
+	return nil
+}
+
+// RunTool runs a tool defined by an enabled vibe. Parameters left out take
+// their defaults. A sensitive permission the user has not approved fails
+// with a *PermissionError.
+func (r *Runtime) RunTool(vibeName, toolName string, params map[string]string) (string, error) {
+	vibe, ok := r.Registry.Get(vibeName)
+	if !ok {
+		return "", fmt.Errorf("vibe not found: %s", vibeName)
+	}
+	if !vibe.Enabled {
+		return "", fmt.Errorf("vibe %s is disabled", vibeName)
+	}
+	for _, tool := range vibe.Spec.Tools {
+		if tool.Name != toolName {
+			continue
+		}
+		args := make(map[string]string, len(tool.Parameters))
+		for name, p := range tool.Parameters {
+			if v, ok := params[name]; ok {
+				args[name] = v
+			} else if p.Default != "" {
+				args[name] = p.Default
+			} else if p.Required {
+				return "", fmt.Errorf("tool %s of vibe %s needs parameter %s", toolName, vibeName, name)
+			}
+		}
+		return r.Executor.ExecuteTool(vibe, tool, args)
+	}
+	return "", fmt.Errorf("vibe %s has no tool %s", vibeName, toolName)
+}
+
 // Start initializes the runtime and activates all Vibes.
 func (r *Runtime) Start() error {
 	// Scan for vibes
-	if err := r.Registry.Scan(); err != nil {
+	if err := r.Scan(); err != nil {
 		return err
 	}
 
 	// Start the scheduler
 	r.Scheduler.Start()
+	r.scheduleAll()
 
-	// Schedule vibes with cron expressions
-	for _, vibe := range r.Registry.List() {
-		if vibe.Spec.Schedule != "" {
-			v := vibe // Capture for closure
-			_, err := r.Scheduler.Schedule(v.Spec.Name, v.Spec.Schedule, func() {
-				r.Dispatcher.Dispatch(HookOnSchedule, map[string]interface{}{
-					"vibe": v,
-				})
-			})
-			if err != nil {
-				// Log but don't fail
-			}
-		}
-
-		if vibe.Spec.ScheduleOnce != "" {
-			v := vibe
-			t, err := time.Parse(time.RFC3339, v.Spec.ScheduleOnce)
-			if err == nil {
-				r.Scheduler.ScheduleOnce(v.Spec.Name, t, func() {
-					r.Dispatcher.Dispatch(HookOnSchedule, map[string]interface{}{
-						"vibe": v,
-					})
-				})
-			}
-		}
+	// Hot-reload on edits; a runtime without a watcher still works, it just
+	// needs an explicit Reload.
+	if err := r.watch(); err != nil {
+		r.Logger.Log(LogWarn, "", "vibe hot-reload disabled: "+err.Error())
 	}
 
 	// Dispatch startup hook
@@ -83,51 +168,155 @@ func (r *Runtime) Start() error {
 // Stop gracefully shuts down the runtime.
 func (r *Runtime) Stop() {
 	r.Dispatcher.Dispatch(HookOnShutdown, nil)
+	r.reloadMu.Lock()
+	if r.watcher != nil {
+		r.watcher.Stop()
+		r.watcher = nil
+	}
+	if r.reloadTimer != nil {
+		r.reloadTimer.Stop()
+	}
+	r.reloadMu.Unlock()
+	r.stopFileEvents()
 	r.Scheduler.Stop()
 }
 
 // Reload rescans vibes and reapplies configuration.
 func (r *Runtime) Reload() error {
-	// Cancel all scheduled tasks
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	// Cancel all scheduled tasks, including those of vibes that were removed
 	for _, vibe := range r.Registry.List() {
 		r.Scheduler.Cancel(vibe.Spec.Name)
 	}
 
 	// Rescan
-	if err := r.Registry.Scan(); err != nil {
+	if err := r.Scan(); err != nil {
 		return err
 	}
 
 	// Reschedule
+	r.scheduleAll()
+
+	r.Dispatcher.Dispatch(HookOnConfigChange, map[string]interface{}{
+		"source": "vibes",
+	})
+	return nil
+}
+
+// scheduleAll registers the cron and one-shot schedules of enabled vibes.
+func (r *Runtime) scheduleAll() {
 	for _, vibe := range r.Registry.List() {
-		if vibe.Spec.Schedule != "" {
-			v := vibe
-			r.Scheduler.Schedule(v.Spec.Name, v.Spec.Schedule, func() {
-				r.Dispatcher.Dispatch(HookOnSchedule, map[string]interface{}{
-					"vibe": v,
-				})
+		if !vibe.Enabled {
+			continue
+		}
+		v := vibe // Capture for closure
+		fire := func() {
+			r.Dispatcher.Dispatch(HookOnSchedule, map[string]interface{}{
+				"vibe": v,
 			})
 		}
+
+		if v.Spec.Schedule != "" {
+			if _, err := r.Scheduler.Schedule(v.Spec.Name, v.Spec.Schedule, fire); err != nil {
+				// Log but don't fail
+				r.Logger.LogError(v.Spec.Name, HookOnSchedule, err)
+			}
+		}
+
+		if v.Spec.ScheduleOnce != "" {
+			t, err := time.Parse(time.RFC3339, v.Spec.ScheduleOnce)
+			if err == nil {
+				r.Scheduler.ScheduleOnce(v.Spec.Name, t, fire)
+			}
+		}
+	}
+}
+
+// reloadDelay is how long the vibes directory must be quiet before a
+// change is reloaded, so an editor's burst of writes reloads once.
+const reloadDelay = 200 * time.Millisecond
+
+// watch starts watching the vibes directory and reloads shortly after any
+// .vibe.md file is written, created or removed.
+func (r *Runtime) watch() error {
+	w, err := watcher.New()
+	if err != nil {
+		return err
 	}
+	if err := w.AddRoot(filepath.Join(r.DataDir, "vibes")); err != nil {
+		w.Stop()
+		return err
+	}
+	w.SubscribeFunc(func(evt watcher.Event) {
+		if !strings.HasSuffix(evt.Path, ".vibe.md") {
+			return
+		}
+		switch evt.Type {
+		case watcher.EventWrite, watcher.EventCreate, watcher.EventRemove, watcher.EventRename:
+			r.scheduleReload()
+		}
+	})
+	w.Start()
 
+	r.reloadMu.Lock()
+	r.watcher = w
+	r.reloadMu.Unlock()
 	return nil
 }
 
-// InstallVibe copies a vibe file to the vibes directory.
-func (r *Runtime) InstallVibe(sourcePath string) error {
-	filename := filepath.Base(sourcePath)
-	destPath := filepath.Join(r.DataDir, "vibes", filename)
+// scheduleReload reloads after reloadDelay, restarting the delay if
+// another change arrives first.
+func (r *Runtime) scheduleReload() {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	if r.watcher == nil {
+		return // Stopped
+	}
+	if r.reloadTimer != nil {
+		r.reloadTimer.Stop()
+	}
+	r.reloadTimer = time.AfterFunc(reloadDelay, func() {
+		if err := r.Reload(); err != nil {
+			r.Logger.Log(LogError, "", "vibe reload failed: "+err.Error())
+		}
+	})
+}
 
+// InstallVibe copies a vibe file to the vibes directory.
+func (r *Runtime) InstallVibe(sourcePath string) (*Vibe, *ValidationResult, error) {
 	data, err := os.ReadFile(sourcePath)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	return r.InstallVibeData(filepath.Base(sourcePath), data)
+}
+
+// InstallVibeData validates the contents of a .vibe.md file and writes it
+// to the vibes directory. Nothing is written if the file does not parse or
+// has validation errors; the result carries any warnings either way.
+func (r *Runtime) InstallVibeData(filename string, data []byte) (*Vibe, *ValidationResult, error) {
+	vibe, err := ParseBytes(data, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := Validate(vibe)
+	if !result.IsValid() {
+		return vibe, result, fmt.Errorf("vibe %q failed validation: %v", vibe.Spec.Name, result.Errors[0])
 	}
 
+	if !strings.HasSuffix(filename, ".vibe.md") {
+		filename = vibe.Spec.Name + ".vibe.md"
+	}
+	destPath := filepath.Join(r.DataDir, "vibes", filename)
 	if err := os.WriteFile(destPath, data, 0644); err != nil {
-		return err
+		return vibe, result, err
 	}
+	vibe.FilePath = destPath
+	r.Logger.Log(LogInfo, vibe.Spec.Name, "installed from "+filename)
 
-	return r.Reload()
+	return vibe, result, r.Reload()
 }
 
 // UninstallVibe removes a vibe file.
@@ -0,0 +1,134 @@
+package vibes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testVibe = `---
+name: greeter
+version: %s
+hooks: [on_startup]
+---
+Say hello.
+`
+
+func TestRuntime_HotReload(t *testing.T) {
+	dataDir := t.TempDir()
+	rt, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dataDir, "vibes", "greeter.vibe.md")
+	writeVibe(t, path, "1.0.0")
+
+	if err := rt.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	changed := make(chan struct{}, 1)
+	rt.Dispatcher.RegisterHandler(HookOnConfigChange, func(ctx *HookContext) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	writeVibe(t, path, "1.1.0")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if v, ok := rt.Registry.Get("greeter"); ok && v.Spec.Version == "1.1.0" {
+			select {
+			case <-changed:
+			case <-time.After(100 * time.Millisecond):
+				t.Fatal("expected HookOnConfigChange after the reload")
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	v, _ := rt.Registry.Get("greeter")
+	t.Fatalf("expected the edited spec within a second, got %+v", v)
+}
+
+func TestRuntime_SetEnabledSurvivesScan(t *testing.T) {
+	dataDir := t.TempDir()
+	rt, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Logger.Close()
+	writeVibe(t, filepath.Join(dataDir, "vibes", "greeter.vibe.md"), "1.0.0")
+	if err := rt.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := rt.Registry.Get("greeter"); !ok || !v.Enabled {
+		t.Fatalf("expected greeter loaded and enabled, got %+v", v)
+	}
+
+	if err := rt.SetEnabled("greeter", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.SetEnabled("missing", false); err == nil {
+		t.Error("expected an error for a vibe that is not installed")
+	}
+
+	again, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Logger.Close()
+	if err := again.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := again.Registry.Get("greeter"); !ok || v.Enabled {
+		t.Fatalf("expected greeter to stay disabled in a new runtime, got %+v", v)
+	}
+	if len(again.Logger.EntriesForVibe("greeter", 10)) == 0 {
+		t.Error("expected the disable to be in the persisted log")
+	}
+}
+
+func TestRuntime_InstallVibeData(t *testing.T) {
+	dataDir := t.TempDir()
+	rt, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Logger.Close()
+
+	bad := []byte("---\nname: Bad Name\nhooks: [on_startup]\n---\nNope.\n")
+	if _, result, err := rt.InstallVibeData("bad.vibe.md", bad); err == nil || result == nil || result.IsValid() {
+		t.Fatalf("expected a validation failure, got %+v (%v)", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "vibes", "bad.vibe.md")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written for an invalid vibe, got %v", err)
+	}
+
+	vibe, result, err := rt.InstallVibeData("download", []byte(fmt.Sprintf(testVibe, "2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("expected a warning for the non-semver version")
+	}
+	if want := filepath.Join(dataDir, "vibes", "greeter.vibe.md"); vibe.FilePath != want {
+		t.Errorf("expected the file named after the vibe, got %s", vibe.FilePath)
+	}
+	if _, ok := rt.Registry.Get("greeter"); !ok {
+		t.Error("expected the installed vibe in the registry")
+	}
+}
+
+func writeVibe(t *testing.T, path, version string) {
+	t.Helper()
+	data := []byte(fmt.Sprintf(testVibe, version))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
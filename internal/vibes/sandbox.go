@@ -3,6 +3,7 @@ package vibes
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
@@ -115,9 +116,7 @@ func (s *Sandbox) filteredEnv() []string {
 }
 
 func getEnv(key string) string {
-	// This would normally use os.Getenv
-	// Simplified for now
-	return ""
+	return os.Getenv(key)
 }
 
 // Executor manages sandboxed execution across all Vibes.
@@ -169,6 +168,15 @@ func (e *Executor) ExecuteAction(vibe *Vibe, action string) (string, error) {
 		return "", fmt.Errorf("agent is locked")
 	}
 
+	// Shell access is sensitive: the user must have approved it.
+	for _, perm := range []Permission{PermSandboxEscape, PermSystemShell} {
+		if vibe.HasPermission(perm) {
+			if err := e.security.CheckPermission(vibe, perm); err != nil {
+				return "", err
+			}
+		}
+	}
+
 	// Record activity
 	e.security.RecordActivity()
 
@@ -26,7 +26,7 @@ type Scheduler struct {
 // NewScheduler creates a new task scheduler.
 func NewScheduler() *Scheduler {
 	return &Scheduler{
-		cron:     cron.New(cron.WithSeconds()),
+		cron:     cron.New(cron.WithParser(cronParser)),
 		tasks:    make(map[string][]ScheduledTask),
 		oneshots: make(map[string]*time.Timer),
 	}
@@ -50,12 +50,14 @@ func (s *Scheduler) Stop() {
 	s.oneshots = make(map[string]*time.Timer)
 }
 
-// Schedule adds a recurring task based on a cron expression.
-func (s *Scheduler) Schedule(vibeName, cronExpr string, action func()) (cron.EntryID, error) {
-	entryID, err := s.cron.AddFunc(cronExpr, action)
+// Schedule adds a recurring task based on a cron expression and returns
+// the time it will first run.
+func (s *Scheduler) Schedule(vibeName, cronExpr string, action func()) (time.Time, error) {
+	sched, err := ParseCron(cronExpr)
 	if err != nil {
-		return 0, err
+		return time.Time{}, err
 	}
+	entryID := s.cron.Schedule(sched, cron.FuncJob(action))
 
 	task := ScheduledTask{
 		ID:       entryID,
@@ -68,7 +70,7 @@ func (s *Scheduler) Schedule(vibeName, cronExpr string, action func()) (cron.Ent
 	s.tasks[vibeName] = append(s.tasks[vibeName], task)
 	s.mu.Unlock()
 
-	return entryID, nil
+	return sched.Next(time.Now()), nil
 }
 
 // ScheduleOnce adds a one-shot task at a specific time.
@@ -150,9 +152,15 @@ func (s *Scheduler) NextRun(vibeName string) *time.Time {
 
 	if tasks, ok := s.tasks[vibeName]; ok && len(tasks) > 0 {
 		entry := s.cron.Entry(tasks[0].ID)
-		if !entry.Next.IsZero() {
-			return &entry.Next
+		if !entry.Valid() {
+			return nil
 		}
+		next := entry.Next
+		if next.IsZero() {
+			// Not started yet, so cron has not computed it.
+			next = entry.Schedule.Next(time.Now())
+		}
+		return &next
 	}
 	return nil
 }
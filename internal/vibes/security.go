@@ -4,8 +4,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/pquerna/otp/totp"
 )
 
 // SecurityManager handles authentication, permissions, and sandboxing.
@@ -13,9 +16,11 @@ type SecurityManager struct {
 	mu            sync.RWMutex
 	locked        bool
 	passwordHash  string
+	totpSecret    string // Base32; when set, Unlock also needs the current code
 	lockAfter     time.Duration
 	lastActivity  time.Time
 	approvedPerms map[string]map[Permission]bool // vibe name -> approved permissions
+	onceApproved  map[string]map[Permission]bool // Approvals used up by the next check
 	lockTimer     *time.Timer
 }
 
@@ -23,6 +28,7 @@ type SecurityManager struct {
 func NewSecurityManager() *SecurityManager {
 	return &SecurityManager{
 		approvedPerms: make(map[string]map[Permission]bool),
+		onceApproved:  make(map[string]map[Permission]bool),
 		lastActivity:  time.Now(),
 	}
 }
@@ -48,7 +54,7 @@ func (sm *SecurityManager) resetLockTimer() {
 		sm.lockTimer.Stop()
 	}
 
-	if sm.lockAfter > 0 && sm.passwordHash != "" {
+	if sm.lockAfter > 0 && sm.hasCredential() {
 		sm.lockTimer = time.AfterFunc(sm.lockAfter, func() {
 			sm.Lock()
 		})
@@ -63,26 +69,71 @@ func (sm *SecurityManager) RecordActivity() {
 	sm.resetLockTimer()
 }
 
+// SetTOTPSecret requires a TOTP code, from an authenticator app holding
+// the base32 secret, on every unlock. An empty secret turns it off.
+func (sm *SecurityManager) SetTOTPSecret(secret string) error {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if secret != "" {
+		if _, err := totp.GenerateCode(secret, time.Now()); err != nil {
+			return fmt.Errorf("invalid TOTP secret: %w", err)
+		}
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.totpSecret = secret
+	return nil
+}
+
+// RequiresTOTP returns whether unlocking needs a TOTP code.
+func (sm *SecurityManager) RequiresTOTP() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.totpSecret != ""
+}
+
+// hasCredential reports whether anything can unlock the agent, so that
+// locking it makes sense. Callers hold sm.mu.
+func (sm *SecurityManager) hasCredential() bool {
+	return sm.passwordHash != "" || sm.totpSecret != ""
+}
+
 // Lock locks the agent.
 func (sm *SecurityManager) Lock() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	if sm.passwordHash != "" {
+	if sm.hasCredential() {
 		sm.locked = true
 	}
 }
 
-// Unlock unlocks the agent with the correct password.
+// totpDigits is the length of the TOTP codes Unlock accepts.
+const totpDigits = 6
+
+// Unlock unlocks the agent with the correct password. When TOTP is set up,
+// the current code follows the password directly, as in "hunter2123456".
 func (sm *SecurityManager) Unlock(password string) error {
+	code := ""
+	if sm.RequiresTOTP() && len(password) >= totpDigits {
+		password, code = password[:len(password)-totpDigits], password[len(password)-totpDigits:]
+	}
+	return sm.UnlockWithCode(password, code)
+}
+
+// UnlockWithCode unlocks the agent with the password and, when TOTP is set
+// up, the current code.
+func (sm *SecurityManager) UnlockWithCode(password, code string) error {
 	hash := sha256.Sum256([]byte(password))
 	attempt := "sha256:" + hex.EncodeToString(hash[:])
 
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	if sm.passwordHash != attempt {
+	if sm.passwordHash != "" && sm.passwordHash != attempt {
 		return fmt.Errorf("incorrect password")
 	}
+	if sm.totpSecret != "" && !totp.Validate(strings.TrimSpace(code), sm.totpSecret) {
+		return fmt.Errorf("incorrect or expired TOTP code")
+	}
 
 	sm.locked = false
 	sm.lastActivity = time.Now()
@@ -115,6 +166,40 @@ func (sm *SecurityManager) ApprovePermission(vibeName string, perm Permission) {
 	sm.approvedPerms[vibeName][perm] = true
 }
 
+// ApproveOnce grants a permission to a Vibe for the next check only.
+func (sm *SecurityManager) ApproveOnce(vibeName string, perm Permission) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.onceApproved[vibeName] == nil {
+		sm.onceApproved[vibeName] = make(map[Permission]bool)
+	}
+	sm.onceApproved[vibeName][perm] = true
+}
+
+// takeOnce uses up a one-time approval, reporting whether there was one.
+func (sm *SecurityManager) takeOnce(vibeName string, perm Permission) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.onceApproved[vibeName][perm] {
+		return false
+	}
+	delete(sm.onceApproved[vibeName], perm)
+	return true
+}
+
+// PermissionError is returned by CheckPermission for a sensitive
+// permission the user has not approved for the Vibe.
+type PermissionError struct {
+	Vibe       string
+	Permission Permission
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission %s requires approval for vibe %s", e.Permission, e.Vibe)
+}
+
 // RevokePermission removes a permission from a Vibe.
 func (sm *SecurityManager) RevokePermission(vibeName string, perm Permission) {
 	sm.mu.Lock()
@@ -137,15 +222,16 @@ func (sm *SecurityManager) IsApproved(vibeName string, perm Permission) bool {
 }
 
 // CheckPermission validates that a Vibe can use a permission.
-// Returns an error if the permission requires approval and hasn't been granted.
+// Returns a *PermissionError if the permission requires approval and hasn't
+// been granted; a one-time approval is used up by the check.
 func (sm *SecurityManager) CheckPermission(vibe *Vibe, perm Permission) error {
 	if !vibe.HasPermission(perm) {
 		return fmt.Errorf("vibe %s does not declare permission %s", vibe.Spec.Name, perm)
 	}
 
 	// Sensitive permissions require explicit approval
-	if isSensitive(perm) && !sm.IsApproved(vibe.Spec.Name, perm) {
-		return fmt.Errorf("permission %s requires approval for vibe %s", perm, vibe.Spec.Name)
+	if isSensitive(perm) && !sm.IsApproved(vibe.Spec.Name, perm) && !sm.takeOnce(vibe.Spec.Name, perm) {
+		return &PermissionError{Vibe: vibe.Spec.Name, Permission: perm}
 	}
 
 	return nil
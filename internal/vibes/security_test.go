@@ -0,0 +1,113 @@
+package vibes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+const shellVibe = `---
+name: shouter
+version: 1.0.0
+permissions: [system.shell]
+tools:
+  - name: say
+    description: Echo a word
+    parameters:
+      word: {type: string, default: hi}
+    action: echo ${word}
+---
+Shout.
+`
+
+func newShellRuntime(t *testing.T) *Runtime {
+	t.Helper()
+	dataDir := t.TempDir()
+	rt, err := NewRuntime(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "vibes", "shouter.vibe.md"), []byte(shellVibe), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	return rt
+}
+
+func TestRunTool_NeedsShellApproval(t *testing.T) {
+	rt := newShellRuntime(t)
+
+	_, err := rt.RunTool("shouter", "say", nil)
+	var pe *PermissionError
+	if !errors.As(err, &pe) || pe.Vibe != "shouter" || pe.Permission != PermSystemShell {
+		t.Fatalf("expected a PermissionError for system.shell, got %v", err)
+	}
+
+	rt.Security.ApproveOnce("shouter", PermSystemShell)
+	out, err := rt.RunTool("shouter", "say", map[string]string{"word": "hello"})
+	if err != nil || strings.TrimSpace(out) != "hello" {
+		t.Fatalf("expected the approved run to echo, got %q (%v)", out, err)
+	}
+	if _, err := rt.RunTool("shouter", "say", nil); !errors.As(err, &pe) {
+		t.Errorf("expected a one-time approval to be used up, got %v", err)
+	}
+
+	rt.Security.ApprovePermission("shouter", PermSystemShell)
+	for i := 0; i < 2; i++ {
+		if out, err := rt.RunTool("shouter", "say", nil); err != nil || strings.TrimSpace(out) != "hi" {
+			t.Errorf("run %d: expected the default word, got %q (%v)", i, out, err)
+		}
+	}
+}
+
+func TestRunTool_UnknownTool(t *testing.T) {
+	rt := newShellRuntime(t)
+	if _, err := rt.RunTool("shouter", "whisper", nil); err == nil {
+		t.Error("expected an error for a tool the vibe does not define")
+	}
+	if _, err := rt.RunTool("nobody", "say", nil); err == nil {
+		t.Error("expected an error for an unknown vibe")
+	}
+}
+
+func TestUnlock_TOTP(t *testing.T) {
+	const secret = "JBSWY3DPEHPK3PXP"
+	sm := NewSecurityManager()
+	sm.SetPassword("hunter2")
+	if err := sm.SetTOTPSecret("not base32!"); err == nil {
+		t.Error("expected an invalid secret to be rejected")
+	}
+	if err := sm.SetTOTPSecret(strings.ToLower(secret)); err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm.Lock()
+	if err := sm.Unlock("hunter2"); err == nil {
+		t.Error("expected the password alone to be refused")
+	}
+	if err := sm.Unlock("wrong" + code); err == nil {
+		t.Error("expected a wrong password to be refused with a valid code")
+	}
+	if err := sm.UnlockWithCode("hunter2", "000000"); err == nil && code != "000000" {
+		t.Error("expected a wrong code to be refused")
+	}
+	if err := sm.Unlock("hunter2" + code); err != nil || sm.IsLocked() {
+		t.Fatalf("expected password+code to unlock, got %v", err)
+	}
+
+	sm.Lock()
+	if err := sm.UnlockWithCode("hunter2", code); err != nil || sm.IsLocked() {
+		t.Errorf("expected a separate code to unlock, got %v", err)
+	}
+}
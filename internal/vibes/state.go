@@ -86,20 +86,23 @@ func (sm *StateManager) saveIfDirty() {
 		sm.mu.Unlock()
 		return
 	}
-	sm.dirty = false
-	stateCopy := make(map[string]*State)
-	for k, v := range sm.states {
-		stateCopy[k] = v
-	}
 	sm.mu.Unlock()
+	sm.Save()
+}
 
-	data, err := json.MarshalIndent(stateCopy, "", "  ")
+// Save writes state to disk now and waits for it, for short-lived
+// callers such as CLI commands that exit before the save loop runs.
+func (sm *StateManager) Save() error {
+	sm.mu.Lock()
+	sm.dirty = false
+	data, err := json.MarshalIndent(sm.states, "", "  ")
+	sm.mu.Unlock()
 	if err != nil {
-		return
+		return err
 	}
 
 	statePath := filepath.Join(sm.dataDir, "vibes_state.json")
-	os.WriteFile(statePath, data, 0644)
+	return os.WriteFile(statePath, data, 0644)
 }
 
 // ForceSave immediately saves state to disk.
@@ -68,8 +68,8 @@ func Validate(vibe *Vibe) *ValidationResult {
 
 	// Schedule validation
 	if vibe.Spec.Schedule != "" {
-		if !isValidCron(vibe.Spec.Schedule) {
-			result.AddError("schedule", "invalid cron expression")
+		if _, err := ParseCron(vibe.Spec.Schedule); err != nil {
+			result.AddError("schedule", "invalid cron expression: "+err.Error())
 		}
 	}
 
@@ -142,12 +142,6 @@ func isValidPermission(perm Permission) bool {
 	return false
 }
 
-func isValidCron(expr string) bool {
-	// Basic cron validation (5 or 6 fields)
-	fields := strings.Fields(expr)
-	return len(fields) >= 5 && len(fields) <= 6
-}
-
 func isValidColor(color string) bool {
 	matched, _ := regexp.MatchString(`^#[0-9A-Fa-f]{6}$`, color)
 	return matched
@@ -133,7 +133,12 @@ func Parse(path string) (*Vibe, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading vibe file: %w", err)
 	}
+	return ParseBytes(data, path)
+}
 
+// ParseBytes parses the contents of a .vibe.md file that has not been
+// written to disk yet, such as a download. path is recorded as FilePath.
+func ParseBytes(data []byte, path string) (*Vibe, error) {
 	// Split front matter from body
 	frontMatter, body, err := splitFrontMatter(data)
 	if err != nil {
@@ -196,16 +201,18 @@ func splitFrontMatter(data []byte) ([]byte, []byte, error) {
 
 // Registry manages all loaded Vibes.
 type Registry struct {
-	mu    sync.RWMutex
-	vibes map[string]*Vibe
-	dirs  []string
+	mu       sync.RWMutex
+	vibes    map[string]*Vibe
+	dirs     []string
+	failures map[string]error // Files that failed to parse in the last Scan
 }
 
 // NewRegistry creates a new Vibe registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		vibes: make(map[string]*Vibe),
-		dirs:  make([]string, 0),
+		vibes:    make(map[string]*Vibe),
+		dirs:     make([]string, 0),
+		failures: make(map[string]error),
 	}
 }
 
@@ -216,11 +223,16 @@ func (r *Registry) AddDirectory(dir string) {
 	r.dirs = append(r.dirs, dir)
 }
 
-// Scan discovers and loads all Vibes from registered directories.
+// Scan discovers and loads all Vibes from registered directories,
+// replacing whatever was loaded before so removed files disappear.
+// Files that fail to parse are skipped and reported by Failures.
 func (r *Registry) Scan() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.vibes = make(map[string]*Vibe)
+	r.failures = make(map[string]error)
+
 	for _, dir := range r.dirs {
 		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
@@ -234,8 +246,8 @@ func (r *Registry) Scan() error {
 			if strings.HasSuffix(path, ".vibe.md") {
 				vibe, err := Parse(path)
 				if err != nil {
-					// Log but don't fail entire scan
-					fmt.Fprintf(os.Stderr, "Warning: failed to parse vibe %s: %v\n", path, err)
+					// Record but don't fail entire scan
+					r.failures[path] = err
 					return nil
 				}
 				r.vibes[vibe.Spec.Name] = vibe
@@ -252,6 +264,19 @@ func (r *Registry) Scan() error {
 	return nil
 }
 
+// Failures returns the files that failed to parse in the last Scan,
+// keyed by path.
+func (r *Registry) Failures() map[string]error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]error, len(r.failures))
+	for k, v := range r.failures {
+		result[k] = v
+	}
+	return result
+}
+
 // Get retrieves a Vibe by name.
 func (r *Registry) Get(name string) (*Vibe, bool) {
 	r.mu.RLock()
@@ -142,22 +142,34 @@ func (w *Watcher) addRecursive(root string) error {
 			return nil
 		}
 
-		// Skip ignored patterns
-		base := filepath.Base(path)
-		for _, pattern := range w.ignorePatterns {
-			if matched, _ := filepath.Match(pattern, base); matched {
-				return filepath.SkipDir
-			}
-			if strings.HasPrefix(base, ".") && pattern == ".git" {
-				// Skip all hidden dirs for performance
-				return filepath.SkipDir
-			}
+		if ignored(filepath.Base(path), w.ignorePatterns) {
+			return filepath.SkipDir
 		}
 
 		return w.watcher.Add(path)
 	})
 }
 
+// Ignored reports whether a file or directory called name is skipped by
+// default: build artifacts, editor droppings and hidden directories. Other
+// packages use it to agree with the watcher on what is part of a project.
+func Ignored(name string) bool {
+	return ignored(name, defaultIgnorePatterns())
+}
+
+func ignored(base string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+		if strings.HasPrefix(base, ".") && pattern == ".git" {
+			// Skip all hidden dirs for performance
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveRoot removes a directory from watch.
 func (w *Watcher) RemoveRoot(path string) error {
 	absPath, err := filepath.Abs(path)